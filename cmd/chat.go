@@ -16,6 +16,8 @@ var (
 	chatOnlySlack      bool
 	chatExportEval     bool
 	chatExportEvalPath string
+	chatEfSearch       int
+	chatIndexName      string
 )
 
 var chatCmd = &cobra.Command{
@@ -31,6 +33,7 @@ retrieved information. OpenSearch configuration is required.
 Examples:
   kiberag chat                           # Start interactive chat
   kiberag chat --context-size 10        # Use more context documents
+  kiberag chat --index-name docs,runbooks^0.5  # Search several indexes at once
   kiberag chat --system "You are a helpful assistant specialized in documentation."
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -45,6 +48,8 @@ Examples:
 			ExportEval:     chatExportEval,
 			ExportEvalPath: chatExportEvalPath,
 			MCPConfigPath:  mcpClientConfigPath,
+			EfSearch:       chatEfSearch,
+			IndexName:      chatIndexName,
 		})
 	},
 }
@@ -59,6 +64,8 @@ func init() {
 	chatCmd.Flags().Float64VarP(&chatVectorWeight, "vector-weight", "v", 0.5, "Weight for vector scoring in hybrid search (0-1)")
 	chatCmd.Flags().BoolVar(&chatUseJapaneseNLP, "use-japanese-nlp", true, "Use Japanese NLP optimization for OpenSearch")
 	chatCmd.Flags().BoolVar(&chatOnlySlack, "only-slack", false, "Search only Slack conversations (skip OpenSearch)")
+	chatCmd.Flags().StringVar(&chatIndexName, "index-name", "", "OpenSearch index, or comma-separated indexes/patterns with optional ^weight for federated search (defaults to config)")
+	chatCmd.Flags().IntVar(&chatEfSearch, "ef-search", 0, "HNSW ef_search for the vector query (0 uses OPENSEARCH_KNN_EF_SEARCH or 2*k)")
 	chatCmd.Flags().BoolVar(&chatExportEval, "export-eval", false, "Enable evaluation data export")
 	chatCmd.Flags().StringVar(&chatExportEvalPath, "export-eval-path", "./evaluation/exports/", "Output directory for JSONL evaluation data")
 }
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/ca-srg/ragent/internal/ingestion"
+)
+
+var (
+	docIndex         string
+	docContent       bool
+	docCategory      string
+	docDryRun        bool
+	docCSVConfigPath string
+	docOCRPromptFile string
+)
+
+var docCmd = &cobra.Command{
+	Use:   "doc",
+	Short: "Inspect, delete or re-index a single document",
+	Long: `
+The doc command acts on one document across the vector store, OpenSearch and the hash store.
+A document is selected by its ID, by the ID of one of its chunks or by the path of its
+source file, as shown in search results (e.g. docs/guide.md, s3://bucket/key.md,
+github://owner/repo/README.md). The path of a CSV file selects all of its rows.
+`,
+}
+
+var docGetCmd = &cobra.Command{
+	Use:   "get <id|path>",
+	Short: "Show the chunks, metadata and embedding stats of a document",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunDocGet(cmd, ingestion.DocGetOptions{
+			Target:  args[0],
+			Index:   docIndex,
+			Content: docContent,
+		})
+	},
+}
+
+var docDeleteCmd = &cobra.Command{
+	Use:   "delete <id|path> | --category <category>",
+	Short: "Delete documents from the vector store, OpenSearch and the hash store",
+	Long: `
+Delete a document, every document of a source file or every document of a category from
+the vector store and OpenSearch, and drop the hash store records of the deleted files.
+Remove the file from its source as well, or the next vectorize run indexes it again.
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target := ""
+		if len(args) > 0 {
+			target = args[0]
+		}
+		return ingestion.RunDocDelete(cmd, ingestion.DocDeleteOptions{
+			Target:   target,
+			Category: docCategory,
+			Index:    docIndex,
+			DryRun:   docDryRun,
+		})
+	},
+}
+
+var docReindexCmd = &cobra.Command{
+	Use:   "reindex <path>",
+	Short: "Re-read a source file and vectorize it again",
+	Long: `
+Read the source file from the local filesystem, S3 (s3://bucket/key) or GitHub
+(github://owner/repo/path) and vectorize it again regardless of the hash store. Chunks
+of the previous version that were not written again are deleted afterwards; if the
+run fails, the previous chunks are kept.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunDocReindex(cmd, ingestion.DocReindexOptions{
+			Path:          args[0],
+			CSVConfigPath: docCSVConfigPath,
+			OCRPromptFile: docOCRPromptFile,
+		})
+	},
+}
+
+func init() {
+	docGetCmd.Flags().StringVar(&docIndex, "index", "", "OpenSearch index or alias (default: OPENSEARCH_INDEX)")
+	docGetCmd.Flags().BoolVar(&docContent, "content", false, "Print the full content of every chunk")
+
+	docDeleteCmd.Flags().StringVar(&docIndex, "index", "", "OpenSearch index or alias (default: OPENSEARCH_INDEX)")
+	docDeleteCmd.Flags().StringVar(&docCategory, "category", "", "Delete every document of this category")
+	docDeleteCmd.Flags().BoolVar(&docDryRun, "dry-run", false, "List the chunks and hash records that would be deleted")
+
+	docReindexCmd.Flags().StringVar(&docCSVConfigPath, "csv-config", "", "Path to CSV configuration YAML file (for column mapping)")
+	docReindexCmd.Flags().StringVar(&docOCRPromptFile, "ocr-prompt-file", "", "Path to custom OCR prompt file (content is appended to base prompt)")
+
+	docCmd.AddCommand(docGetCmd)
+	docCmd.AddCommand(docDeleteCmd)
+	docCmd.AddCommand(docReindexCmd)
+	rootCmd.AddCommand(docCmd)
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/ca-srg/ragent/internal/ingestion"
+)
+
+var (
+	indexAlias              string
+	indexKeep               int
+	indexMinDocRatio        float64
+	indexReplaceLegacyIndex bool
+	indexRollbackTo         string
+
+	indexMigrateProvider   string
+	indexMigrateModel      string
+	indexMigrateDimension  int
+	indexMigrateQueries    string
+	indexMigrateK          int
+	indexMigrateSample     int
+	indexMigrateMinOverlap float64
+)
+
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Manage alias-based OpenSearch index generations",
+	Long: `
+The index command manages OpenSearch indexes behind an alias (OPENSEARCH_INDEX by default).
+Each rebuild creates a new <alias>-<timestamp> generation, so searches keep using the
+current generation until the alias is swapped.
+`,
+}
+
+var indexRebuildCmd = &cobra.Command{
+	Use:   "rebuild",
+	Short: "Rebuild the index into a new generation and swap the alias without downtime",
+	Long: `
+Create <alias>-<timestamp> with the current mapping, vectorize every source into it,
+validate its document count against the live index and atomically swap the alias.
+The previous generations are kept for rollback (see --keep).
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunIndexRebuild(cmd, ingestion.IndexRebuildOptions{
+			Alias:              indexAlias,
+			Keep:               indexKeep,
+			MinDocRatio:        indexMinDocRatio,
+			ReplaceLegacyIndex: indexReplaceLegacyIndex,
+			Vectorize:          indexSourceOptions(),
+		})
+	},
+}
+
+var indexRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Point the alias back at a previous index generation",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunIndexRollback(cmd, ingestion.IndexRollbackOptions{
+			Alias: indexAlias,
+			To:    indexRollbackTo,
+		})
+	},
+}
+
+var indexListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the index generations of the alias",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunIndexList(cmd, ingestion.IndexListOptions{Alias: indexAlias})
+	},
+}
+
+var indexCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Report drift between the live index and the k-NN index profile",
+	Long: `
+Compare the shard, replica and knn_vector settings of the index behind the alias with
+the profile configured by OPENSEARCH_KNN_* and OPENSEARCH_NUMBER_OF_SHARDS/REPLICAS.
+Exits with an error when any value differs.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunIndexCheck(cmd, ingestion.IndexCheckOptions{Alias: indexAlias})
+	},
+}
+
+var indexMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate the index to a different embedding model",
+	Long: `
+Migrate the alias to another embedding model (e.g. Titan v1 to v2 or to Gemini) without
+wiping the index. "start" builds a candidate generation with the target model and makes
+vectorize runs write into both indexes, "compare" measures the top-k overlap of both
+models on queries from eval JSONL exports, and "cutover" swaps the alias only when the
+overlap meets --min-overlap.
+`,
+}
+
+var indexMigrateStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Build a candidate index with the target embedding model and start dual-writing",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunModelMigrateStart(cmd, ingestion.ModelMigrateStartOptions{
+			Alias:     indexAlias,
+			Provider:  indexMigrateProvider,
+			Model:     indexMigrateModel,
+			Dimension: indexMigrateDimension,
+			Vectorize: indexSourceOptions(),
+		})
+	},
+}
+
+var indexMigrateCompareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "Compare the top-k results of the live and target models on a query sample",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunModelMigrateCompare(cmd, indexMigrateCompareOptions())
+	},
+}
+
+var indexMigrateCutoverCmd = &cobra.Command{
+	Use:   "cutover",
+	Short: "Swap the alias to the candidate index if it meets the overlap threshold",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunModelMigrateCutover(cmd, indexMigrateCompareOptions())
+	},
+}
+
+var indexMigrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the model migration in progress",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunModelMigrateStatus(cmd, ingestion.ModelMigrateOptions{Alias: indexAlias})
+	},
+}
+
+var indexMigrateAbortCmd = &cobra.Command{
+	Use:   "abort",
+	Short: "Delete the candidate index and stop dual-writing",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunModelMigrateAbort(cmd, ingestion.ModelMigrateOptions{Alias: indexAlias})
+	},
+}
+
+// indexSourceOptions returns the vectorize source flags shared by the index subcommands
+func indexSourceOptions() ingestion.VectorizeOptions {
+	return ingestion.VectorizeOptions{
+		Directory:      directory,
+		Concurrency:    concurrency,
+		CSVConfigPath:  csvConfigPath,
+		EnableS3:       enableS3,
+		S3Bucket:       s3Bucket,
+		S3Prefix:       s3Prefix,
+		S3VectorRegion: s3VectorRegion,
+		S3SourceRegion: s3SourceRegion,
+		GitHubRepos:    githubRepos,
+		OCRPromptFile:  ocrPromptFile,
+	}
+}
+
+func indexMigrateCompareOptions() ingestion.ModelMigrateCompareOptions {
+	return ingestion.ModelMigrateCompareOptions{
+		Alias:       indexAlias,
+		Queries:     indexMigrateQueries,
+		K:           indexMigrateK,
+		Sample:      indexMigrateSample,
+		MinOverlap:  indexMigrateMinOverlap,
+		MinDocRatio: indexMinDocRatio,
+	}
+}
+
+// addIndexSourceFlags registers the vectorize source flags on an index subcommand
+func addIndexSourceFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&directory, "directory", "d", "./source", "Directory containing source files to process (markdown and CSV)")
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "c", 0, "Number of concurrent operations (0 = use config default)")
+	cmd.Flags().StringVar(&csvConfigPath, "csv-config", "", "Path to CSV configuration YAML file (for column mapping)")
+	cmd.Flags().BoolVar(&enableS3, "enable-s3", false, "Enable S3 source file fetching")
+	cmd.Flags().StringVar(&s3Bucket, "s3-bucket", "", "S3 bucket name for source files (required when --enable-s3 is set)")
+	cmd.Flags().StringVar(&s3Prefix, "s3-prefix", "", "S3 prefix (directory) to scan (optional, defaults to bucket root)")
+	cmd.Flags().StringVar(&s3VectorRegion, "s3-vector-region", "", "AWS region for S3 Vector bucket (overrides S3_VECTOR_REGION, default: us-east-1)")
+	cmd.Flags().StringVar(&s3SourceRegion, "s3-source-region", "", "AWS region for source S3 bucket (overrides S3_SOURCE_REGION, default: us-east-1)")
+	cmd.Flags().StringVar(&githubRepos, "github-repos", "", "Comma-separated list of GitHub repositories to clone and vectorize (format: owner/repo)")
+	cmd.Flags().StringVar(&ocrPromptFile, "ocr-prompt-file", "", "Path to custom OCR prompt file (content is appended to base prompt)")
+}
+
+// addIndexCompareFlags registers the shadow comparison flags on a migrate subcommand
+func addIndexCompareFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&indexMigrateQueries, "queries", "", "Eval JSONL export file or directory to sample queries from (required)")
+	cmd.Flags().IntVar(&indexMigrateK, "k", ingestion.DefaultMigrationTopK, "Number of top results compared per query")
+	cmd.Flags().IntVar(&indexMigrateSample, "sample", ingestion.DefaultMigrationSample, "Maximum number of distinct queries to compare")
+	cmd.Flags().Float64Var(&indexMigrateMinOverlap, "min-overlap", ingestion.DefaultMinOverlap, "Minimum mean top-k overlap required for cutover")
+}
+
+func init() {
+	indexCmd.PersistentFlags().StringVar(&indexAlias, "alias", "", "Alias that queries target (default: OPENSEARCH_INDEX)")
+
+	indexRebuildCmd.Flags().IntVar(&indexKeep, "keep", ingestion.DefaultKeepGenerations, "Number of previous generations to keep for rollback")
+	indexRebuildCmd.Flags().Float64Var(&indexMinDocRatio, "min-doc-ratio", ingestion.DefaultMinDocRatio, "Minimum document count of the new generation relative to the live index (0 disables the check)")
+	indexRebuildCmd.Flags().BoolVar(&indexReplaceLegacyIndex, "replace-legacy-index", false, "Replace a concrete index named like the alias with the alias after validation")
+	addIndexSourceFlags(indexRebuildCmd)
+
+	indexRollbackCmd.Flags().StringVar(&indexRollbackTo, "to", "", "Generation to roll back to (default: the one before the live generation)")
+
+	indexCmd.AddCommand(indexRebuildCmd)
+	indexCmd.AddCommand(indexRollbackCmd)
+	indexCmd.AddCommand(indexListCmd)
+	indexCmd.AddCommand(indexCheckCmd)
+
+	indexMigrateStartCmd.Flags().StringVar(&indexMigrateProvider, "to-provider", "", "Target EMBEDDING_PROVIDER (bedrock or gemini, default: current provider)")
+	indexMigrateStartCmd.Flags().StringVar(&indexMigrateModel, "to-model", "", "Target EMBEDDING_MODEL (required)")
+	indexMigrateStartCmd.Flags().IntVar(&indexMigrateDimension, "to-dimension", 0, "Target EMBEDDING_DIMENSION (0 = model default)")
+	addIndexSourceFlags(indexMigrateStartCmd)
+	addIndexCompareFlags(indexMigrateCompareCmd)
+	addIndexCompareFlags(indexMigrateCutoverCmd)
+	indexMigrateCutoverCmd.Flags().Float64Var(&indexMinDocRatio, "min-doc-ratio", ingestion.DefaultMinDocRatio, "Minimum document count of the candidate index relative to the live index (0 disables the check)")
+
+	indexMigrateCmd.AddCommand(indexMigrateStartCmd)
+	indexMigrateCmd.AddCommand(indexMigrateCompareCmd)
+	indexMigrateCmd.AddCommand(indexMigrateCutoverCmd)
+	indexMigrateCmd.AddCommand(indexMigrateStatusCmd)
+	indexMigrateCmd.AddCommand(indexMigrateAbortCmd)
+	indexCmd.AddCommand(indexMigrateCmd)
+	rootCmd.AddCommand(indexCmd)
+}
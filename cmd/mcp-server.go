@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -15,6 +18,7 @@ import (
 
 var (
 	// Command line flags for MCP server
+	mcpTransport           string
 	mcpServerHost          string
 	mcpServerPort          int
 	mcpAllowedIPs          []string
@@ -46,6 +50,13 @@ var (
 	mcpExportEval         bool
 	mcpExportEvalPath     string
 	mcpDashboardDirectory string
+
+	// API key generation flags
+	apiKeyName         string
+	apiKeyTools        []string
+	apiKeyScopes       []string
+	apiKeySecretAccess bool
+	apiKeyExpiresIn    time.Duration
 )
 
 var mcpServerCmd = &cobra.Command{
@@ -65,9 +76,12 @@ Examples:
   ragent mcp-server --port 9000                       # Use custom port
   ragent mcp-server --host 0.0.0.0 --disable-ip-auth # Allow all IPs (not recommended)
   ragent mcp-server --allowed-ips "192.168.1.0/24"   # Allow specific IP range
+  ragent mcp-server --transport stdio                 # Serve over stdin/stdout (Claude Desktop)
+  ragent mcp-server --auth-method apikey              # Require keys from MCP_API_KEYS
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		opts := mcpserver.MCPServerOptions{
+			Transport:         mcpTransport,
 			AuthMethod:        mcpAuthMethod,
 			AuthEnableLogging: mcpAuthEnableLogging,
 			OIDCIssuer:        oidcIssuer,
@@ -89,6 +103,18 @@ Examples:
 			MCPConfigPath:     mcpClientConfigPath,
 		}
 
+		// ingest_document/update_document share the vectorize pipeline (MCP_INGEST_ENABLED)
+		writer, err := ingestion.OpenDocumentWriter()
+		if err != nil {
+			return fmt.Errorf("failed to create document writer: %w", err)
+		}
+		opts.DocumentWriter = writer
+
+		// The dashboard is served over HTTP, so it has nothing to attach to in stdio mode
+		if strings.EqualFold(strings.TrimSpace(mcpTransport), mcpserver.TransportStdio) {
+			return mcpserver.RunMCPServer(context.Background(), cmd, opts)
+		}
+
 		dashboardDir := mcpDashboardDirectory
 		if dashboardDir == "" {
 			dashboardDir = "./source"
@@ -99,33 +125,104 @@ Examples:
 			return fmt.Errorf("failed to build dashboard dependencies: %w", err)
 		}
 
+		ledger, closeLedger, err := ingestion.OpenFailureLedger()
+		if err != nil {
+			log.Printf("Warning: failure ledger unavailable, dashboard shows in-memory errors only: %v", err)
+			ledger, closeLedger = nil, func() {}
+		}
+
+		reports, closeReports, err := ingestion.OpenConsistencyReports()
+		if err != nil {
+			log.Printf("Warning: consistency reports unavailable, dashboard hides the consistency check: %v", err)
+			reports, closeReports = nil, func() {}
+		}
+
 		handler, cleanup, err := webui.SetupDashboard(
 			&webui.ServerConfig{Directory: dashboardDir, BasePath: "/dashboard"},
-			&webui.Dependencies{FileScanner: fs, Vectorizer: vec},
+			&webui.Dependencies{FileScanner: fs, Vectorizer: vec, FailureLedger: ledger, ConsistencyReports: reports},
 			log.New(os.Stdout, "[dashboard] ", log.LstdFlags),
 		)
 		if err != nil {
+			closeLedger()
+			closeReports()
 			return fmt.Errorf("failed to setup dashboard: %w", err)
 		}
 		opts.DashboardHandler = handler
-		opts.DashboardCleanup = cleanup
+		opts.DashboardCleanup = func() {
+			cleanup()
+			closeLedger()
+			closeReports()
+		}
 		opts.DashboardBasePath = "/dashboard"
 
 		return mcpserver.RunMCPServer(context.Background(), cmd, opts)
 	},
 }
 
+var mcpGenerateAPIKeyCmd = &cobra.Command{
+	Use:   "generate-api-key",
+	Short: "Generate an API key and the hashed entry to add to MCP_API_KEYS",
+	Long: `
+Generate a random API key for CI jobs and services that call the MCP server.
+The key is printed once; only the hashed entry belongs in the MCP_API_KEYS file
+or secret. To rotate a key, add a new entry under the same name, switch the
+callers over, then remove or expire the old entry.
+
+Examples:
+  ragent mcp-server generate-api-key --name ci-docs --tools hybrid_search,ask
+  ragent mcp-server generate-api-key --name indexer --scopes ragent:write --expires-in 2160h
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if strings.TrimSpace(apiKeyName) == "" {
+			return fmt.Errorf("--name is required")
+		}
+
+		key, hash, err := mcpserver.GenerateAPIKey()
+		if err != nil {
+			return err
+		}
+		entry := mcpserver.APIKey{
+			Name:         strings.TrimSpace(apiKeyName),
+			Hash:         hash,
+			Tools:        apiKeyTools,
+			SecretAccess: apiKeySecretAccess,
+			Scopes:       apiKeyScopes,
+		}
+		if apiKeyExpiresIn > 0 {
+			expiresAt := time.Now().Add(apiKeyExpiresIn).UTC().Truncate(time.Second)
+			entry.ExpiresAt = &expiresAt
+		}
+
+		data, err := json.MarshalIndent(entry, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode API key entry: %w", err)
+		}
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "API key (shown only once): %s\n\n", key)
+		fmt.Fprintf(out, "Add this entry to the \"keys\" array of MCP_API_KEYS:\n%s\n", data)
+		return nil
+	},
+}
+
 func init() {
 	addMCPClientConfigFlag(mcpServerCmd)
 
+	mcpServerCmd.AddCommand(mcpGenerateAPIKeyCmd)
+	mcpGenerateAPIKeyCmd.Flags().StringVar(&apiKeyName, "name", "", "Name of the service or job using the key (required)")
+	mcpGenerateAPIKeyCmd.Flags().StringSliceVar(&apiKeyTools, "tools", []string{}, "Tools the key may call (default: all tools)")
+	mcpGenerateAPIKeyCmd.Flags().StringSliceVar(&apiKeyScopes, "scopes", []string{}, "Scopes granted to the key, e.g. ragent:write")
+	mcpGenerateAPIKeyCmd.Flags().BoolVar(&apiKeySecretAccess, "secret-access", false, "Allow the key to search secret documents")
+	mcpGenerateAPIKeyCmd.Flags().DurationVar(&apiKeyExpiresIn, "expires-in", 0, "Key lifetime, e.g. 2160h (default: no expiry)")
+
 	// Server configuration flags
+	mcpServerCmd.Flags().StringVar(&mcpTransport, "transport", mcpserver.TransportHTTP, "Transport: http (Streamable HTTP/SSE server) or stdio (stdin/stdout, no HTTP auth)")
 	mcpServerCmd.Flags().StringVar(&mcpServerHost, "host", "localhost", "Server host address")
 	mcpServerCmd.Flags().IntVar(&mcpServerPort, "port", 8080, "Server port")
 	mcpServerCmd.Flags().StringSliceVar(&mcpAllowedIPs, "allowed-ips", []string{"127.0.0.1", "::1"}, "Comma-separated list of allowed IP addresses/ranges")
 	mcpServerCmd.Flags().BoolVar(&mcpEnableIPAuth, "enable-ip-auth", true, "Enable IP-based authentication")
 
 	// Authentication (unified) flags
-	mcpServerCmd.Flags().StringVar(&mcpAuthMethod, "auth-method", "ip", "Authentication method: ip, oidc, both, either")
+	mcpServerCmd.Flags().StringVar(&mcpAuthMethod, "auth-method", "ip", "Authentication method: ip, oidc, both, either, apikey")
 	mcpServerCmd.Flags().BoolVar(&mcpAuthEnableLogging, "auth-enable-logging", true, "Enable detailed auth logging")
 
 	// OIDC flags (used when auth-method is oidc/both/either)
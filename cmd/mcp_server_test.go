@@ -21,7 +21,9 @@ func TestMCPServerCmdFlags(t *testing.T) {
 	hostFlag := mcpServerCmd.Flags().Lookup("host")
 	portFlag := mcpServerCmd.Flags().Lookup("port")
 	defaultIndexFlag := mcpServerCmd.Flags().Lookup("default-index")
+	transportFlag := mcpServerCmd.Flags().Lookup("transport")
 
+	require.NotNil(t, transportFlag)
 	require.NotNil(t, hostFlag)
 	require.NotNil(t, portFlag)
 	require.NotNil(t, defaultIndexFlag)
@@ -29,4 +31,5 @@ func TestMCPServerCmdFlags(t *testing.T) {
 	assert.Equal(t, "localhost", hostFlag.DefValue)
 	assert.Equal(t, "8080", portFlag.DefValue)
 	assert.Equal(t, "ragent-docs", defaultIndexFlag.DefValue)
+	assert.Equal(t, "http", transportFlag.DefValue)
 }
@@ -28,6 +28,7 @@ var (
 	slackChannels  []string
 	exportEval     bool
 	exportEvalPath string
+	queryEfSearch  int
 )
 
 var queryCmd = &cobra.Command{
@@ -51,6 +52,9 @@ Examples:
   
   # Custom fusion method
   kiberag query -q "search algorithms" --fusion-method weighted_sum --top-k 10
+
+  # Federated search over several indexes with per-index weights
+  kiberag query -q "deploy rollback" --index-name docs,runbooks^2,tickets-*^0.5
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return queryimpl.RunQuery(cmd, queryimpl.QueryOptions{
@@ -70,6 +74,7 @@ Examples:
 			ExportEval:     exportEval,
 			ExportEvalPath: exportEvalPath,
 			MCPConfigPath:  mcpClientConfigPath,
+			EfSearch:       queryEfSearch,
 		})
 	},
 }
@@ -84,12 +89,13 @@ func init() {
 
 	// Hybrid search flags
 	queryCmd.Flags().StringVar(&searchMode, "search-mode", "hybrid", "Search mode: hybrid|opensearch")
-	queryCmd.Flags().StringVar(&indexName, "index-name", "", "OpenSearch index name (optional, defaults to config)")
+	queryCmd.Flags().StringVar(&indexName, "index-name", "", "OpenSearch index, or comma-separated indexes/patterns with optional ^weight for federated search (defaults to config)")
 	queryCmd.Flags().Float64Var(&bm25Weight, "bm25-weight", 0.5, "BM25 search weight in hybrid mode (0.0-1.0)")
 	queryCmd.Flags().Float64Var(&vectorWeight, "vector-weight", 0.5, "Vector search weight in hybrid mode (0.0-1.0)")
 	queryCmd.Flags().StringVar(&fusionMethod, "fusion-method", "rrf", "Result fusion method: rrf|weighted_sum|max_score")
 	queryCmd.Flags().BoolVar(&useJapaneseNLP, "japanese-nlp", false, "Enable Japanese text processing and analysis")
 	queryCmd.Flags().IntVar(&timeout, "timeout", 30, "Request timeout in seconds")
+	queryCmd.Flags().IntVar(&queryEfSearch, "ef-search", 0, "HNSW ef_search for the vector query (0 uses OPENSEARCH_KNN_EF_SEARCH or 2*k)")
 	queryCmd.Flags().BoolVar(&queryOnlySlack, "only-slack", false, "Search only Slack conversations (skip OpenSearch)")
 	queryCmd.Flags().StringSliceVar(&slackChannels, "slack-channels", nil, "Limit Slack search to specific channel names (omit leading #)")
 	queryCmd.Flags().BoolVar(&exportEval, "export-eval", false, "Enable evaluation data export")
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/ca-srg/ragent/internal/ingestion"
+	"github.com/ca-srg/ragent/internal/ingestion/transfer"
+)
+
+var (
+	transferFrom           string
+	transferTo             string
+	transferOutput         string
+	transferInput          string
+	transferBatchSize      int
+	transferResume         bool
+	transferSqlitePath     string
+	transferIndex          string
+	transferS3VectorRegion string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export vectors to a portable JSONL file",
+	Long: `
+Export every vector of S3 Vectors, sqlite-vec or an OpenSearch index to a JSONL file
+with one {"id", "vector", "content", "metadata", "created_at"} object per line.
+The file can be loaded into any backend with the import command.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunExport(cmd, ingestion.ExportOptions{
+			From:      transferFrom,
+			Output:    transferOutput,
+			BatchSize: transferBatchSize,
+			Resume:    transferResume,
+			Endpoint:  transferEndpointOptions(),
+		})
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import vectors from a JSONL export into a backend",
+	Long: `
+Import a JSONL file written by the export command into S3 Vectors, sqlite-vec or an
+OpenSearch index. OpenSearch indexes that do not exist yet are created with the
+dimension of the imported vectors.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunImport(cmd, ingestion.ImportOptions{
+			Input:     transferInput,
+			To:        transferTo,
+			BatchSize: transferBatchSize,
+			Resume:    transferResume,
+			Endpoint:  transferEndpointOptions(),
+		})
+	},
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Copy vectors from one backend to another",
+	Long: `
+Copy every vector between S3 Vectors, sqlite-vec and OpenSearch without an
+intermediate file, e.g. "ragent migrate --from s3 --to sqlite". Vectors are streamed
+in batches and the progress is checkpointed, so an interrupted run continues with --resume.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunMigrate(cmd, ingestion.MigrateOptions{
+			From:      transferFrom,
+			To:        transferTo,
+			BatchSize: transferBatchSize,
+			Resume:    transferResume,
+			Endpoint:  transferEndpointOptions(),
+		})
+	},
+}
+
+func transferEndpointOptions() ingestion.TransferEndpointOptions {
+	return ingestion.TransferEndpointOptions{
+		SqlitePath:     transferSqlitePath,
+		Index:          transferIndex,
+		S3VectorRegion: transferS3VectorRegion,
+	}
+}
+
+// addTransferFlags registers the flags shared by export, import and migrate
+func addTransferFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&transferBatchSize, "batch-size", transfer.DefaultBatchSize, "Number of vectors read and written per batch")
+	cmd.Flags().BoolVar(&transferResume, "resume", false, "Continue an interrupted transfer from its last checkpoint")
+	cmd.Flags().StringVar(&transferSqlitePath, "sqlite-path", "", "sqlite-vec database path (overrides SQLITE_VEC_DB_PATH)")
+	cmd.Flags().StringVar(&transferIndex, "index", "", "OpenSearch index (overrides OPENSEARCH_INDEX)")
+	cmd.Flags().StringVar(&transferS3VectorRegion, "s3-vector-region", "", "AWS region for S3 Vector bucket (overrides S3_VECTOR_REGION, default: us-east-1)")
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&transferFrom, "from", "", "Backend to export: s3, sqlite or opensearch (default: VECTOR_DB_BACKEND)")
+	exportCmd.Flags().StringVarP(&transferOutput, "output", "o", "", "JSONL file to write (required)")
+	addTransferFlags(exportCmd)
+
+	importCmd.Flags().StringVarP(&transferInput, "input", "i", "", "JSONL file written by export (required)")
+	importCmd.Flags().StringVar(&transferTo, "to", "", "Backend to import into: s3, sqlite or opensearch (default: VECTOR_DB_BACKEND)")
+	addTransferFlags(importCmd)
+
+	migrateCmd.Flags().StringVar(&transferFrom, "from", "", "Backend to copy from: s3, sqlite or opensearch (required)")
+	migrateCmd.Flags().StringVar(&transferTo, "to", "", "Backend to copy into: s3, sqlite or opensearch (required)")
+	addTransferFlags(migrateCmd)
+
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
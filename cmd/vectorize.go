@@ -13,10 +13,15 @@ var (
 	clearVectors          bool
 	followMode            bool
 	followInterval        string
+	verifyInterval        string
+	watchMode             bool
+	watchDebounce         string
 	spreadsheetConfigPath string
 	csvConfigPath         string
 	forceProcess          bool
 	pruneDeleted          bool
+	resumeRun             bool
+	resumeRunID           string
 	enableS3              bool
 	s3Bucket              string
 	s3Prefix              string
@@ -24,6 +29,13 @@ var (
 	s3SourceRegion        string
 	githubRepos           string
 	ocrPromptFile         string
+	priceTablePath        string
+	estimateFormat        string
+	vectorizeTenant       string
+
+	failuresErrorType string
+	failuresLimit     int
+	retryErrorType    string
 )
 
 var vectorizeCmd = &cobra.Command{
@@ -49,10 +61,15 @@ and data files for RAG (Retrieval Augmented Generation) applications.
 			ClearVectors:          clearVectors,
 			FollowMode:            followMode,
 			FollowInterval:        followInterval,
+			VerifyInterval:        verifyInterval,
+			WatchMode:             watchMode,
+			WatchDebounce:         watchDebounce,
 			SpreadsheetConfigPath: spreadsheetConfigPath,
 			CSVConfigPath:         csvConfigPath,
 			ForceProcess:          forceProcess,
 			PruneDeleted:          pruneDeleted,
+			Resume:                resumeRun,
+			ResumeRunID:           resumeRunID,
 			EnableS3:              enableS3,
 			S3Bucket:              s3Bucket,
 			S3Prefix:              s3Prefix,
@@ -60,10 +77,75 @@ and data files for RAG (Retrieval Augmented Generation) applications.
 			S3SourceRegion:        s3SourceRegion,
 			GitHubRepos:           githubRepos,
 			OCRPromptFile:         ocrPromptFile,
+			PriceTablePath:        priceTablePath,
+			EstimateFormat:        estimateFormat,
+			Tenant:                vectorizeTenant,
+		})
+	},
+}
+
+var vectorizeFailuresCmd = &cobra.Command{
+	Use:   "failures",
+	Short: "Inspect documents that failed to vectorize",
+}
+
+var vectorizeFailuresListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List documents recorded in the failure ledger",
+	Long: `
+List documents that failed to vectorize, most recent first.
+
+Every vectorize run records failed documents in ~/.ragent/stats.db together
+with the error type, the backend that failed (S3 Vector or OpenSearch) and
+the number of attempts. Entries are removed once the document is vectorized
+successfully by a later run or by "ragent vectorize retry".
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunVectorizeFailuresList(cmd, ingestion.FailuresListOptions{
+			ErrorType: failuresErrorType,
+			Limit:     failuresLimit,
+		})
+	},
+}
+
+var vectorizeRetryCmd = &cobra.Command{
+	Use:   "retry",
+	Short: "Re-vectorize documents recorded in the failure ledger",
+	Long: `
+Re-read the source files of failed documents from their local directory,
+S3 bucket or GitHub repository and vectorize them again.
+
+Whole source files are retried: a CSV file or PDF with one failed row or page
+is processed again in full. Use --type to retry only one kind of failure,
+for example --type rate_limit after a throttled run.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunVectorizeRetry(cmd, ingestion.RetryOptions{
+			ErrorType:     retryErrorType,
+			Concurrency:   concurrency,
+			CSVConfigPath: csvConfigPath,
+			OCRPromptFile: ocrPromptFile,
 		})
 	},
 }
 
+var vectorizeCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Apply the configured sqlite-vec layout and rebuild its ANN index",
+	Long: `
+Re-encode the sqlite-vec database with SQLITE_VEC_QUANTIZATION, drop the
+float32 embeddings when SQLITE_VEC_STORAGE=quantized, reclaim the freed space
+and rebuild the ANN index when SQLITE_VEC_ANN_LISTS is set.
+
+Opening the database never rewrites it: after changing these settings the
+store keeps its current encoding until this command runs. Stop other
+processes using the database first, as the rewrite holds it locked.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunVectorStoreCompact(cmd)
+	},
+}
+
 func init() {
 	vectorizeCmd.Flags().StringVarP(&directory, "directory", "d", "./source", "Directory containing source files to process (markdown and CSV)")
 	vectorizeCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be processed without making API calls")
@@ -71,12 +153,17 @@ func init() {
 	vectorizeCmd.Flags().BoolVar(&clearVectors, "clear", false, "Delete all existing vectors before processing new ones")
 	vectorizeCmd.Flags().BoolVar(&followMode, "follow", false, "Continuously vectorize at a fixed interval")
 	vectorizeCmd.Flags().StringVar(&followInterval, "interval", ingestion.DefaultFollowInterval, "Interval between vectorization runs in follow mode (e.g. 30m, 1h)")
+	vectorizeCmd.Flags().StringVar(&verifyInterval, "verify-interval", "", "Run a consistency check after follow mode cycles at this interval (e.g. 6h; default: off)")
+	vectorizeCmd.Flags().BoolVar(&watchMode, "watch", false, "Watch the local directory and vectorize files as they change")
+	vectorizeCmd.Flags().StringVar(&watchDebounce, "debounce", ingestion.DefaultWatchDebounce, "Quiet period before changed files are vectorized in watch mode (e.g. 2s, 500ms)")
 	vectorizeCmd.Flags().StringVar(&spreadsheetConfigPath, "spreadsheet-config", "", "Path to spreadsheet configuration YAML file (enables spreadsheet mode)")
 	vectorizeCmd.Flags().StringVar(&csvConfigPath, "csv-config", "", "Path to CSV configuration YAML file (for column mapping)")
 
 	// Incremental processing options
 	vectorizeCmd.Flags().BoolVarP(&forceProcess, "force", "f", false, "Force re-vectorization of all files, ignoring hash cache")
 	vectorizeCmd.Flags().BoolVar(&pruneDeleted, "prune", false, "Remove vectors for files that no longer exist")
+	vectorizeCmd.Flags().BoolVar(&resumeRun, "resume", false, "Resume the latest interrupted run, skipping documents it already completed")
+	vectorizeCmd.Flags().StringVar(&resumeRunID, "resume-run", "", "Resume the interrupted run with the given run ID")
 
 	// S3 source options
 	vectorizeCmd.Flags().BoolVar(&enableS3, "enable-s3", false, "Enable S3 source file fetching")
@@ -91,4 +178,23 @@ func init() {
 	vectorizeCmd.Flags().StringVar(&githubRepos, "github-repos", "", "Comma-separated list of GitHub repositories to clone and vectorize (format: owner/repo)")
 
 	vectorizeCmd.Flags().StringVar(&ocrPromptFile, "ocr-prompt-file", "", "Path to custom OCR prompt file (content is appended to base prompt)")
+
+	// Dry-run cost estimate options
+	vectorizeCmd.Flags().StringVar(&priceTablePath, "price-table", "", "Path to price table YAML file used for the --dry-run cost estimate")
+	vectorizeCmd.Flags().StringVar(&estimateFormat, "estimate-format", "text", "Output format of the --dry-run cost estimate (text or json)")
+	vectorizeCmd.Flags().StringVar(&vectorizeTenant, "tenant", "", "Tenant the vectorized documents belong to (overrides TENANT_ID)")
+
+	// Failure ledger subcommands
+	vectorizeFailuresListCmd.Flags().StringVar(&failuresErrorType, "type", "", "Only list failures of this error type (e.g. rate_limit, embedding_generation, opensearch_indexing)")
+	vectorizeFailuresListCmd.Flags().IntVarP(&failuresLimit, "limit", "n", 0, "Maximum number of failures to list (0 = all)")
+	vectorizeFailuresCmd.AddCommand(vectorizeFailuresListCmd)
+
+	vectorizeRetryCmd.Flags().StringVar(&retryErrorType, "type", "", "Only retry failures of this error type (e.g. rate_limit)")
+	vectorizeRetryCmd.Flags().IntVarP(&concurrency, "concurrency", "c", 0, "Number of concurrent operations (0 = use config default)")
+	vectorizeRetryCmd.Flags().StringVar(&csvConfigPath, "csv-config", "", "Path to CSV configuration YAML file (for column mapping)")
+	vectorizeRetryCmd.Flags().StringVar(&ocrPromptFile, "ocr-prompt-file", "", "Path to custom OCR prompt file (content is appended to base prompt)")
+
+	vectorizeCmd.AddCommand(vectorizeFailuresCmd)
+	vectorizeCmd.AddCommand(vectorizeRetryCmd)
+	vectorizeCmd.AddCommand(vectorizeCompactCmd)
 }
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/ca-srg/ragent/internal/ingestion"
+)
+
+var (
+	verifyIndex  string
+	verifyRepair string
+	verifyLimit  int
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the vector store, OpenSearch and the hash store for inconsistencies",
+	Long: `
+Compare the IDs of the vector store (VECTOR_DB_BACKEND), the OpenSearch index and the
+hash store records, and report vectors or documents present in only one backend, chunks
+missing from both, embedding dimension mismatches and hash records of files without
+indexed documents. The report is also shown on the web UI dashboard.
+
+--repair reindex drops the hash records of the affected source files so the next
+vectorize run re-processes them; --repair delete removes the entries that exist in only
+one backend and the stale hash records.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ingestion.RunVerify(cmd, ingestion.VerifyOptions{
+			Index:  verifyIndex,
+			Repair: verifyRepair,
+			Limit:  verifyLimit,
+		})
+	},
+}
+
+func init() {
+	verifyCmd.Flags().StringVar(&verifyIndex, "index", "", "OpenSearch index or alias to check (default: OPENSEARCH_INDEX)")
+	verifyCmd.Flags().StringVar(&verifyRepair, "repair", "", "Repair the issues: reindex or delete")
+	verifyCmd.Flags().IntVar(&verifyLimit, "limit", ingestion.DefaultVerifyLimit, "Maximum number of issues to print (0 prints all)")
+
+	rootCmd.AddCommand(verifyCmd)
+}
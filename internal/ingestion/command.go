@@ -25,6 +25,7 @@ import (
 	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
 	"github.com/ca-srg/ragent/internal/pkg/embedding"
 	"github.com/ca-srg/ragent/internal/pkg/ipc"
+	"github.com/ca-srg/ragent/internal/pkg/opensearch"
 )
 
 // DefaultFollowInterval is the default interval for follow mode.
@@ -44,6 +45,12 @@ var (
 	followInterval         string
 	followIntervalDuration time.Duration
 	followModeProcessing   atomic.Bool
+	verifyInterval         string
+	verifyIntervalDuration time.Duration
+
+	watchMode             bool
+	watchDebounce         string
+	watchDebounceDuration time.Duration
 
 	// Spreadsheet mode
 	spreadsheetConfigPath string
@@ -66,6 +73,17 @@ var (
 	// Incremental processing options
 	forceProcess bool // Force re-vectorization of all files
 	pruneDeleted bool // Remove vectors for deleted files
+
+	// Resume options
+	resumeRun   bool   // Resume the latest interrupted run
+	resumeRunID string // Resume a specific run by ID
+
+	// Dry-run estimate options
+	priceTablePath string // YAML price table used to project costs
+	estimateFormat string // Output format of the cost estimate (text or json)
+
+	// Tenant options
+	tenantID string // Tenant the run's documents belong to (overrides TENANT_ID)
 )
 
 // ProgressCallback is called when processing progress is updated
@@ -84,6 +102,9 @@ type VectorizeOptions struct {
 	ClearVectors          bool
 	FollowMode            bool
 	FollowInterval        string
+	VerifyInterval        string
+	WatchMode             bool
+	WatchDebounce         string
 	SpreadsheetConfigPath string
 	CSVConfigPath         string
 	EnableS3              bool
@@ -94,17 +115,31 @@ type VectorizeOptions struct {
 	GitHubRepos           string
 	ForceProcess          bool
 	PruneDeleted          bool
+	Resume                bool
+	ResumeRunID           string
 	OCRPromptFile         string
+	PriceTablePath        string
+	EstimateFormat        string
+	Tenant                string
 }
 
 // RunVectorize is the exported entry point called from cmd/vectorize.go.
 func RunVectorize(cmd *cobra.Command, opts VectorizeOptions) error {
+	applyVectorizeOptions(opts)
+	return runVectorize(cmd, nil)
+}
+
+// applyVectorizeOptions copies the options into the package-level flag state
+func applyVectorizeOptions(opts VectorizeOptions) {
 	directory = opts.Directory
 	dryRun = opts.DryRun
 	concurrency = opts.Concurrency
 	clearVectors = opts.ClearVectors
 	followMode = opts.FollowMode
 	followInterval = opts.FollowInterval
+	verifyInterval = opts.VerifyInterval
+	watchMode = opts.WatchMode
+	watchDebounce = opts.WatchDebounce
 	spreadsheetConfigPath = opts.SpreadsheetConfigPath
 	csvConfigPath = opts.CSVConfigPath
 	enableS3 = opts.EnableS3
@@ -115,8 +150,12 @@ func RunVectorize(cmd *cobra.Command, opts VectorizeOptions) error {
 	githubRepos = opts.GitHubRepos
 	forceProcess = opts.ForceProcess
 	pruneDeleted = opts.PruneDeleted
+	resumeRun = opts.Resume
+	resumeRunID = opts.ResumeRunID
 	ocrPromptFile = opts.OCRPromptFile
-	return runVectorize(cmd, nil)
+	priceTablePath = opts.PriceTablePath
+	estimateFormat = opts.EstimateFormat
+	tenantID = opts.Tenant
 }
 
 func runVectorize(cmd *cobra.Command, args []string) error {
@@ -126,6 +165,18 @@ func runVectorize(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("follow mode flag validation failed: %w", err)
 	}
 
+	if err := validateWatchModeFlags(cmd); err != nil {
+		return fmt.Errorf("watch mode flag validation failed: %w", err)
+	}
+
+	if err := validateResumeFlags(); err != nil {
+		return fmt.Errorf("resume flag validation failed: %w", err)
+	}
+
+	if err := validateEstimateFlags(); err != nil {
+		return fmt.Errorf("estimate flag validation failed: %w", err)
+	}
+
 	// Load config first so that LoadSecretsIntoEnv() injects Secrets Manager
 	// values before we validate environment variables like OPENSEARCH_ENDPOINT.
 	cfg, err := appconfig.Load()
@@ -141,6 +192,10 @@ func runVectorize(cmd *cobra.Command, args []string) error {
 		cfg.Concurrency = concurrency
 	}
 
+	if err := applyTenantScope(cfg, tenantID); err != nil {
+		return err
+	}
+
 	ctx := cmd.Context()
 	if ctx == nil {
 		ctx = context.Background()
@@ -155,12 +210,16 @@ func runVectorize(cmd *cobra.Command, args []string) error {
 		return runFollowMode(ctx, cfg)
 	}
 
+	if watchMode {
+		return runWatchMode(ctx, cfg)
+	}
+
 	result, err := vectorizationRunner(ctx, cfg)
 	if err != nil {
 		return err
 	}
 
-	if result != nil {
+	if result != nil && !estimateAsJSON() {
 		printResults(result, dryRun)
 	}
 
@@ -172,6 +231,18 @@ func executeVectorizationOnce(ctx context.Context, cfg *appconfig.Config) (*pkgd
 }
 
 func executeVectorizationOnceWithProgress(ctx context.Context, cfg *appconfig.Config, progressCallback ProgressCallback) (*pkgdomain.ProcessingResult, error) {
+	return executeVectorization(ctx, cfg, progressCallback, nil)
+}
+
+// executeVectorization runs a single vectorize pass over the configured sources. With a
+// backfill migration every document is embedded with the target model and written only to
+// its candidate index; failures go to the migration's failure list instead of the ledger.
+func executeVectorization(
+	ctx context.Context,
+	cfg *appconfig.Config,
+	progressCallback ProgressCallback,
+	backfill *hashstore.ModelMigrationRecord,
+) (*pkgdomain.ProcessingResult, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -271,33 +342,17 @@ func executeVectorizationOnceWithProgress(ctx context.Context, cfg *appconfig.Co
 		return nil, nil
 	}
 
-	// Load CSV configuration if provided
-	var csvCfg *csv.Config
-	if csvConfigPath != "" {
-		log.Printf("Loading CSV configuration: %s", csvConfigPath)
-		var err error
-		csvCfg, err = csv.LoadConfig(csvConfigPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load CSV config: %w", err)
-		}
-		log.Println("CSV configuration loaded successfully")
-	}
-
-	var customPrompt string
-	if ocrPromptFile != "" {
+	var priceTable *vectorizer.PriceTable
+	if dryRun {
 		var err error
-		customPrompt, err = pdf.LoadOCRPromptFile(ocrPromptFile)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load OCR prompt file: %w", err)
-		}
-		if customPrompt != "" {
-			log.Printf("Custom OCR prompt loaded: %s (%d bytes)", ocrPromptFile, len(customPrompt))
+		if priceTable, err = loadPriceTable(); err != nil {
+			return nil, err
 		}
 	}
 
-	service, err := createVectorizerServiceWithCSVConfig(cfg, csvCfg, customPrompt)
+	service, csvCfg, err := createVectorizerServiceFromFlags(cfg, backfill)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create vectorizer service: %w", err)
+		return nil, err
 	}
 
 	// Set progress callback if provided
@@ -318,6 +373,31 @@ func executeVectorizationOnceWithProgress(ctx context.Context, cfg *appconfig.Co
 		log.Println("Configuration validation successful")
 	}
 
+	// Open the hash store used for change detection, run checkpoints and the failure
+	// ledger. It is opened before scanning so GitHub sources can diff against the last
+	// indexed commit. Dry runs only read it to break out cached files.
+	hashStore, err := hashstore.NewHashStore()
+	if err != nil {
+		if backfill != nil {
+			return nil, fmt.Errorf("failed to open hash store for the backfill failure list: %w", err)
+		}
+		log.Printf("Warning: Failed to initialize hash store, processing all files: %v", err)
+		hashStore = nil
+	} else {
+		defer func() { _ = hashStore.Close() }()
+	}
+
+	// --force ignores the hash cache; the failure ledger is still written. Resumed runs
+	// need the run state even when forced.
+	runStore := hashStore
+	if forceProcess && !resumeRun && resumeRunID == "" {
+		runStore = nil
+	}
+	if runStore != nil {
+		service.SetOCRCache(runStore)
+	}
+	useChangeDetection := hashStore != nil && !forceProcess
+
 	// Collect files from all sources
 	var allFiles []*pkgdomain.FileInfo
 
@@ -354,39 +434,36 @@ func executeVectorizationOnceWithProgress(ctx context.Context, cfg *appconfig.Co
 		}
 		log.Printf("Found %d files in S3 bucket", len(s3Files))
 
-		// Download content for S3 files and compute hash if needed
-		// For CSV files, download even in dry-run mode to show configuration preview
+		// Download content for S3 files and compute hash if needed.
+		// Dry runs download too so cached files can be detected and costs estimated.
 		for _, f := range s3Files {
-			shouldDownload := !dryRun || f.IsCSV
-			if shouldDownload {
-				if f.IsPDF {
-					// PDF files: download as binary bytes to avoid corruption
-					data, err := s3Scanner.DownloadFileBytes(ctx, f.Path)
-					if err != nil {
-						log.Printf("Warning: Failed to download S3 PDF file %s: %v", f.Path, err)
-						if !dryRun {
-							continue
-						}
-					} else {
-						f.RawBytes = data
-						if f.ContentHash == "" {
-							f.ContentHash = scanner.ComputeMD5Hash(string(data))
-						}
+			if f.IsPDF {
+				// PDF files: download as binary bytes to avoid corruption
+				data, err := s3Scanner.DownloadFileBytes(ctx, f.Path)
+				if err != nil {
+					log.Printf("Warning: Failed to download S3 PDF file %s: %v", f.Path, err)
+					if !dryRun {
+						continue
 					}
 				} else {
-					content, hash, err := s3Scanner.DownloadFileWithHash(ctx, f.Path)
-					if err != nil {
-						log.Printf("Warning: Failed to download S3 file %s: %v", f.Path, err)
-						if !dryRun {
-							continue
-						}
-						// In dry-run, still add file but without content
-					} else {
-						f.Content = content
-						// Use computed hash if ETag was not available (multipart upload)
-						if f.ContentHash == "" {
-							f.ContentHash = hash
-						}
+					f.RawBytes = data
+					if f.ContentHash == "" {
+						f.ContentHash = scanner.ComputeMD5Hash(string(data))
+					}
+				}
+			} else {
+				content, hash, err := s3Scanner.DownloadFileWithHash(ctx, f.Path)
+				if err != nil {
+					log.Printf("Warning: Failed to download S3 file %s: %v", f.Path, err)
+					if !dryRun {
+						continue
+					}
+					// In dry-run, still add file but without content
+				} else {
+					f.Content = content
+					// Use computed hash if ETag was not available (multipart upload)
+					if f.ContentHash == "" {
+						f.ContentHash = hash
 					}
 				}
 			}
@@ -395,6 +472,7 @@ func executeVectorizationOnceWithProgress(ctx context.Context, cfg *appconfig.Co
 	}
 
 	// 3. Scan GitHub repositories if specified
+	var githubResults []*scanner.GitHubScanResult
 	if hasGitHubSource {
 		log.Printf("Scanning GitHub repositories: %s", githubRepos)
 		repos, err := scanner.ParseGitHubRepos(githubRepos)
@@ -402,37 +480,41 @@ func executeVectorizationOnceWithProgress(ctx context.Context, cfg *appconfig.Co
 			return nil, fmt.Errorf("failed to parse GitHub repos: %w", err)
 		}
 
-		githubScanner := scanner.NewGitHubScanner(repos, cfg.GitHubToken)
+		cacheDir, err := resolveGitHubCacheDir(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve GitHub cache directory: %w", err)
+		}
+
+		githubScanner := scanner.NewGitHubScannerWithCache(repos, cfg.GitHubToken, cacheDir)
 		defer githubScanner.Cleanup()
 
-		githubFiles, err := githubScanner.ScanAllRepositories(ctx)
+		var lookup scanner.CommitLookup
+		if useChangeDetection {
+			lookup = func(ctx context.Context, repo scanner.GitHubRepo, branch string) (string, error) {
+				record, err := hashStore.GetLastIndexedCommit(ctx, repo.FullName(), branch)
+				if err != nil || record == nil {
+					return "", err
+				}
+				return record.CommitSHA, nil
+			}
+		}
+
+		githubResults, err = githubScanner.ScanRepositories(ctx, lookup)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan GitHub repositories: %w", err)
 		}
-		log.Printf("Found %d files in GitHub repositories", len(githubFiles))
 
-		metadataExtractor := metadata.NewMetadataExtractor()
-		for _, f := range githubFiles {
-			if f.IsPDF {
-				// PDF files: move binary Content to RawBytes to avoid corruption
-				f.RawBytes = []byte(f.Content)
-				f.Content = ""
-				continue
-			}
-			parts := parseGitHubPath(f.Path)
-			if parts != nil {
-				meta, err := metadataExtractor.ExtractGitHubMetadata(parts.owner, parts.repo, parts.relativePath, f.Content)
-				if err != nil {
-					log.Printf("Warning: Failed to extract metadata for %s: %v", f.Path, err)
-					continue
-				}
-				f.Metadata = *meta
-			}
+		var githubFiles []*pkgdomain.FileInfo
+		for _, r := range githubResults {
+			githubFiles = append(githubFiles, r.Files...)
 		}
+		log.Printf("Found %d files in GitHub repositories", len(githubFiles))
+
+		prepareGitHubFiles(githubFiles)
 		allFiles = append(allFiles, githubFiles...)
 	}
 
-	if len(allFiles) == 0 {
+	if len(allFiles) == 0 && !hasIncrementalGitHubResult(githubResults) {
 		log.Println("No supported files found")
 		return &pkgdomain.ProcessingResult{
 			ProcessedFiles: 0,
@@ -445,36 +527,29 @@ func executeVectorizationOnceWithProgress(ctx context.Context, cfg *appconfig.Co
 
 	// Change detection using hash store (unless --force is specified)
 	var changeResult *hashstore.ChangeDetectionResult
-	var hashStore *hashstore.HashStore
 	var filesToProcess []*pkgdomain.FileInfo
 
-	if !forceProcess && !dryRun {
+	if useChangeDetection {
+		var sourceTypes []string
+		if hasLocalSource {
+			sourceTypes = append(sourceTypes, "local")
+		}
+		if hasS3Source {
+			sourceTypes = append(sourceTypes, "s3")
+		}
+		if hasGitHubSource {
+			sourceTypes = append(sourceTypes, "github")
+		}
+
+		detector := hashstore.NewChangeDetector(hashStore)
 		var err error
-		hashStore, err = hashstore.NewHashStore()
+		filesToProcess, changeResult, err = detector.FilterFilesToProcess(ctx, sourceTypes, allFiles)
 		if err != nil {
-			log.Printf("Warning: Failed to initialize hash store, processing all files: %v", err)
+			log.Printf("Warning: Change detection failed, processing all files: %v", err)
 			filesToProcess = allFiles
+			changeResult = nil
 		} else {
-			defer func() { _ = hashStore.Close() }()
-
-			var sourceTypes []string
-			if hasLocalSource {
-				sourceTypes = append(sourceTypes, "local")
-			}
-			if hasS3Source {
-				sourceTypes = append(sourceTypes, "s3")
-			}
-			if hasGitHubSource {
-				sourceTypes = append(sourceTypes, "github")
-			}
-
-			detector := hashstore.NewChangeDetector(hashStore)
-			filesToProcess, changeResult, err = detector.FilterFilesToProcess(ctx, sourceTypes, allFiles)
-			if err != nil {
-				log.Printf("Warning: Change detection failed, processing all files: %v", err)
-				filesToProcess = allFiles
-				changeResult = nil
-			}
+			applyGitHubDiffs(changeResult, githubResults)
 		}
 	} else {
 		filesToProcess = allFiles
@@ -484,12 +559,14 @@ func executeVectorizationOnceWithProgress(ctx context.Context, cfg *appconfig.Co
 	}
 
 	// Print change detection summary
-	if changeResult != nil {
+	if changeResult != nil && !estimateAsJSON() {
 		printChangeDetectionSummary(changeResult)
 	}
 
 	if len(filesToProcess) == 0 && !dryRun {
 		log.Println("No files need processing (all files are unchanged)")
+		pruneDeletedFiles(ctx, runStore, changeResult, newSourceRemover(cfg, openSearchIndexName))
+		recordGitHubCommits(ctx, runStore, githubResults, nil)
 		return &pkgdomain.ProcessingResult{
 			ProcessedFiles: 0,
 			SuccessCount:   0,
@@ -500,42 +577,89 @@ func executeVectorizationOnceWithProgress(ctx context.Context, cfg *appconfig.Co
 	log.Printf("Files to process: %d", len(filesToProcess))
 
 	// Print CSV configuration info in dry-run mode
-	if dryRun {
+	if dryRun && !estimateAsJSON() {
 		printCSVConfigInfo(allFiles, csvCfg)
 	}
 
+	// Checkpoint each document as its writes succeed so an interrupted run can be resumed
+	var checkpointer *runCheckpointer
+	if runStore != nil && !dryRun {
+		checkpointer, err = startVectorizeRun(ctx, runStore, describeSources())
+		if err != nil {
+			return nil, fmt.Errorf("failed to start vectorize run: %w", err)
+		}
+		service.SetCheckpointer(checkpointer)
+	}
+
 	// Use VectorizeFiles for combined processing
 	result, err := service.VectorizeFiles(ctx, filesToProcess, dryRun)
+	finishVectorizeRun(ctx, checkpointer, result, err)
 	if err != nil {
 		return nil, fmt.Errorf("vectorization failed: %w", err)
 	}
 
+	// Project tokens and cost of the real run, breaking out files the hash store would skip
+	if dryRun {
+		estimate := service.EstimateCost(filesToProcess, cachedFiles(allFiles, changeResult), priceTable)
+		if err := printCostEstimate(os.Stdout, estimate, estimateFormat); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update hash store for successfully processed files
-	if hashStore != nil && result != nil && result.SuccessCount > 0 && !dryRun {
-		updateHashStoreForSuccessfulFiles(ctx, hashStore, filesToProcess, result)
+	if runStore != nil && result != nil && result.SuccessCount > 0 && !dryRun {
+		updateHashStoreForSuccessfulFiles(ctx, runStore, filesToProcess, result)
+	}
+
+	// Keep failed documents in the failure ledger until a later run or retry succeeds.
+	// Backfill failures block the migration cutover instead.
+	if !dryRun && backfill != nil {
+		recordMigrationFailures(ctx, hashStore, backfill, filesToProcess, result, nil)
+	} else if !dryRun {
+		recordFailures(ctx, hashStore, filesToProcess, result)
 	}
 
 	// Handle pruning of deleted files
-	if pruneDeleted && hashStore != nil && changeResult != nil && len(changeResult.Deleted) > 0 && !dryRun {
-		log.Printf("Pruning %d deleted files from hash store...", len(changeResult.Deleted))
-		for _, deletedPath := range changeResult.Deleted {
-			sourceType := "local"
-			if strings.HasPrefix(deletedPath, "s3://") {
-				sourceType = "s3"
-			} else if strings.HasPrefix(deletedPath, "github://") {
-				sourceType = "github"
-			}
-			if err := hashStore.DeleteFileHash(ctx, sourceType, deletedPath); err != nil {
-				log.Printf("Warning: Failed to delete hash for %s: %v", deletedPath, err)
-			}
-		}
-		// TODO: Also delete vectors from S3 Vectors and OpenSearch
-		log.Printf("Note: Vector deletion from backends not yet implemented")
+	if !dryRun {
+		pruneDeletedFiles(ctx, runStore, changeResult, newSourceRemover(cfg, openSearchIndexName))
+		recordGitHubCommits(ctx, runStore, githubResults, result)
+	}
+
+	// Keep the candidate index of an embedding model migration in step with the live index
+	if !dryRun && backfill == nil && result != nil && result.SuccessCount > 0 {
+		dualWriteMigrationCandidate(ctx, cfg, hashStore, csvCfg, openSearchIndexName, filesToProcess)
 	}
 
 	return result, nil
 }
 
+// pruneDeletedFiles deletes the documents of deleted files from the backends and then their
+// hash records when --prune is set. A failed deletion keeps the records so the next run retries.
+func pruneDeletedFiles(ctx context.Context, hashStore *hashstore.HashStore, changeResult *hashstore.ChangeDetectionResult, remove sourceRemover) {
+	if !pruneDeleted || hashStore == nil || changeResult == nil || len(changeResult.Deleted) == 0 {
+		return
+	}
+
+	log.Printf("Pruning %d deleted files...", len(changeResult.Deleted))
+	if remove != nil {
+		if err := remove(ctx, changeResult.Deleted); err != nil {
+			log.Printf("Warning: Failed to delete documents of deleted files, keeping their hash records: %v", err)
+			return
+		}
+	}
+	for _, deletedPath := range changeResult.Deleted {
+		sourceType := "local"
+		if strings.HasPrefix(deletedPath, "s3://") {
+			sourceType = "s3"
+		} else if strings.HasPrefix(deletedPath, "github://") {
+			sourceType = "github"
+		}
+		if err := hashStore.DeleteFileHash(ctx, sourceType, deletedPath); err != nil {
+			log.Printf("Warning: Failed to delete hash for %s: %v", deletedPath, err)
+		}
+	}
+}
+
 // scanLocalDirectoryWithHash scans a local directory and computes MD5 hash for each file
 func scanLocalDirectoryWithHash(dirPath string) ([]*pkgdomain.FileInfo, error) {
 	fileScanner := scanner.NewFileScanner()
@@ -562,6 +686,23 @@ func scanLocalDirectoryWithHash(dirPath string) ([]*pkgdomain.FileInfo, error) {
 	return files, nil
 }
 
+// loadLocalFile scans a single local file and loads its content and hash
+func loadLocalFile(fileScanner *scanner.FileScanner, path string) (*pkgdomain.FileInfo, error) {
+	f, err := fileScanner.ScanFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if f.IsPDF {
+		err = fileScanner.LoadPDFFileWithHash(f)
+	} else {
+		err = fileScanner.LoadFileWithContentAndHash(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load file %s: %w", path, err)
+	}
+	return f, nil
+}
+
 // printChangeDetectionSummary prints a summary of detected changes
 func printChangeDetectionSummary(result *hashstore.ChangeDetectionResult) {
 	fmt.Println("\n" + strings.Repeat("-", 40))
@@ -586,6 +727,9 @@ func updateHashStoreForSuccessfulFiles(
 	failedPaths := make(map[string]bool)
 	for _, procErr := range result.Errors {
 		failedPaths[procErr.FilePath] = true
+		if procErr.SourcePath != "" {
+			failedPaths[procErr.SourcePath] = true
+		}
 	}
 
 	successCount := 0
@@ -602,20 +746,7 @@ func updateHashStoreForSuccessfulFiles(
 			continue
 		}
 
-		sourceType := f.SourceType
-		if sourceType == "" {
-			sourceType = "local"
-		}
-
-		record := &hashstore.FileHashRecord{
-			SourceType:   sourceType,
-			FilePath:     f.Path,
-			ContentHash:  f.ContentHash,
-			FileSize:     f.Size,
-			VectorizedAt: time.Now(),
-		}
-
-		if err := store.UpsertFileHash(ctx, record); err != nil {
+		if err := store.UpsertFileHash(ctx, newFileHashRecord(f)); err != nil {
 			log.Printf("Warning: Failed to update hash for %s: %v", f.Path, err)
 		} else {
 			successCount++
@@ -672,31 +803,30 @@ func runFollowMode(ctx context.Context, cfg *appconfig.Config) error {
 	}
 
 	// Start IPC server for cross-process status sharing
-	ipcLogger := log.New(os.Stdout, "[ipc] ", log.LstdFlags)
-	ipcServer, err := ipc.NewServer(ipc.ServerConfig{}, ipcLogger)
+	ipcServer, stopIPC, err := startIPCServer(followCtx, "[Follow Mode]")
 	if err != nil {
-		if err == ipc.ErrAnotherInstanceRunning {
-			return fmt.Errorf("another vectorize process is already running")
-		}
-		log.Printf("[Follow Mode] Warning: Failed to start IPC server: %v", err)
-		// Continue without IPC - degraded mode
-	} else {
-		if err := ipcServer.Start(followCtx); err != nil {
-			log.Printf("[Follow Mode] Warning: Failed to start IPC server: %v", err)
-		} else {
-			defer func() { _ = ipcServer.Shutdown(context.Background()) }()
-			log.Println("[Follow Mode] IPC server started for status sharing")
-		}
+		return err
 	}
+	defer stopIPC()
 
 	log.Printf("Follow mode enabled. Interval: %s. Press Ctrl+C to stop.", interval)
 
+	// Consistency checks run after a cycle once --verify-interval has elapsed
+	var lastVerify time.Time
+	verifyIfDue := func() {
+		if verifyIntervalDuration > 0 && time.Since(lastVerify) >= verifyIntervalDuration {
+			runFollowVerify(followCtx, cfg)
+			lastVerify = time.Now()
+		}
+	}
+
 	result, err := runFollowCycleWithIPC(followCtx, cfg, ipcServer)
 	if err != nil {
 		log.Printf("[Follow Mode] Vectorization cycle failed: %v", err)
 	} else if result != nil {
 		nextRun := time.Now().Add(interval)
 		log.Printf("[Follow Mode] Completed. Processed %d files. Next run at: %s", result.ProcessedFiles, nextRun.Format(time.RFC3339))
+		verifyIfDue()
 	}
 
 	ticker := time.NewTicker(interval)
@@ -725,15 +855,44 @@ func runFollowMode(ctx context.Context, cfg *appconfig.Config) error {
 			if result != nil {
 				nextRun := time.Now().Add(interval)
 				log.Printf("[Follow Mode] Completed. Processed %d files. Next run at: %s", result.ProcessedFiles, nextRun.Format(time.RFC3339))
+				verifyIfDue()
 			}
 		}
 	}
 }
 
+// startIPCServer starts the IPC server used to share status with other processes.
+// A nil server is returned when IPC is unavailable; the returned stop function is always safe to call.
+func startIPCServer(ctx context.Context, logPrefix string) (*ipc.Server, func(), error) {
+	ipcLogger := log.New(os.Stdout, "[ipc] ", log.LstdFlags)
+	ipcServer, err := ipc.NewServer(ipc.ServerConfig{}, ipcLogger)
+	if err != nil {
+		if err == ipc.ErrAnotherInstanceRunning {
+			return nil, func() {}, fmt.Errorf("another vectorize process is already running")
+		}
+		log.Printf("%s Warning: Failed to start IPC server: %v", logPrefix, err)
+		// Continue without IPC - degraded mode
+		return nil, func() {}, nil
+	}
+
+	if err := ipcServer.Start(ctx); err != nil {
+		log.Printf("%s Warning: Failed to start IPC server: %v", logPrefix, err)
+		return ipcServer, func() {}, nil
+	}
+
+	log.Printf("%s IPC server started for status sharing", logPrefix)
+	return ipcServer, func() { _ = ipcServer.Shutdown(context.Background()) }, nil
+}
+
 // runFollowCycleWithIPC runs a single vectorization cycle with IPC status updates
 func runFollowCycleWithIPC(ctx context.Context, cfg *appconfig.Config, ipcServer *ipc.Server) (*pkgdomain.ProcessingResult, error) {
+	return runCycleWithIPC(ctx, cfg, ipcServer, "[Follow Mode]")
+}
+
+// runCycleWithIPC runs vectorizationRunner once, reporting status and progress over IPC
+func runCycleWithIPC(ctx context.Context, cfg *appconfig.Config, ipcServer *ipc.Server, logPrefix string) (*pkgdomain.ProcessingResult, error) {
 	if !startFollowProcessing() {
-		log.Println("[Follow Mode] Previous vectorization still running. Skipping this cycle.")
+		log.Printf("%s Previous vectorization still running. Skipping this cycle.", logPrefix)
 		return nil, nil
 	}
 
@@ -745,7 +904,7 @@ func runFollowCycleWithIPC(ctx context.Context, cfg *appconfig.Config, ipcServer
 		ipcServer.SetStateWithTime(ipc.StateRunning, startTime)
 	}
 
-	log.Println("[Follow Mode] Starting vectorization cycle...")
+	log.Printf("%s Starting vectorization cycle...", logPrefix)
 
 	// Set progress callback for IPC updates
 	if ipcServer != nil {
@@ -801,6 +960,51 @@ func runFollowCycleWithIPC(ctx context.Context, cfg *appconfig.Config, ipcServer
 	return result, nil
 }
 
+// createVectorizerServiceFromFlags loads the CSV configuration and custom OCR prompt
+// given on the command line and creates a vectorizer service using them. With a migration
+// the service writes only to its candidate index, embedding with the target model.
+func createVectorizerServiceFromFlags(
+	cfg *appconfig.Config,
+	migration *hashstore.ModelMigrationRecord,
+) (*vectorizer.VectorizerService, *csv.Config, error) {
+	// Load CSV configuration if provided
+	var csvCfg *csv.Config
+	if csvConfigPath != "" {
+		log.Printf("Loading CSV configuration: %s", csvConfigPath)
+		var err error
+		csvCfg, err = csv.LoadConfig(csvConfigPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load CSV config: %w", err)
+		}
+		log.Println("CSV configuration loaded successfully")
+	}
+
+	var customPrompt string
+	if ocrPromptFile != "" {
+		var err error
+		customPrompt, err = pdf.LoadOCRPromptFile(ocrPromptFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load OCR prompt file: %w", err)
+		}
+		if customPrompt != "" {
+			log.Printf("Custom OCR prompt loaded: %s (%d bytes)", ocrPromptFile, len(customPrompt))
+		}
+	}
+
+	var service *vectorizer.VectorizerService
+	var err error
+	if migration != nil {
+		service, err = createCandidateVectorizerService(cfg, csvCfg, customPrompt, migration)
+	} else {
+		service, err = createVectorizerServiceWithCSVConfig(cfg, csvCfg, customPrompt)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create vectorizer service: %w", err)
+	}
+
+	return service, csvCfg, nil
+}
+
 // createVectorizerService creates a vectorizer service with concrete implementations
 func createVectorizerService(cfg *appconfig.Config) (*vectorizer.VectorizerService, error) {
 	return createVectorizerServiceWithCSVConfig(cfg, nil, "")
@@ -812,13 +1016,17 @@ func createVectorizerServiceWithCSVConfig(
 	csvCfg *csv.Config,
 	customPrompt string,
 ) (*vectorizer.VectorizerService, error) {
+	cfg.S3VectorRegion = resolveS3VectorRegion(cfg)
+
+	// Embed like the generation the index points to, which may differ from EMBEDDING_MODEL
+	// after a model migration cutover
+	cfg = opensearch.ResolveActiveEmbeddingConfig(context.Background(), cfg, openSearchIndexName)
 	embeddingClient, err := embedding.NewEmbeddingClient(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create embedding client: %w", err)
 	}
 
 	// Create vector store client via factory
-	cfg.S3VectorRegion = resolveS3VectorRegion(cfg)
 	serviceFactory := vectorizer.NewServiceFactory(cfg)
 	vectorStore, err := serviceFactory.CreateVectorStore()
 	if err != nil {
@@ -1000,7 +1208,11 @@ func validateFollowModeFlags(cmd *cobra.Command) error {
 		if flag != nil && flag.Changed {
 			return fmt.Errorf("--interval flag requires --follow")
 		}
+		if verifyInterval != "" {
+			return fmt.Errorf("--verify-interval flag requires --follow")
+		}
 		followIntervalDuration = 0
+		verifyIntervalDuration = 0
 		return nil
 	}
 
@@ -1027,6 +1239,39 @@ func validateFollowModeFlags(cmd *cobra.Command) error {
 	}
 
 	followIntervalDuration = duration
+
+	verifyIntervalDuration = 0
+	if verifyInterval != "" {
+		verifyDuration, err := time.ParseDuration(verifyInterval)
+		if err != nil {
+			return fmt.Errorf("invalid verify interval: %w", err)
+		}
+		if verifyDuration <= 0 {
+			return fmt.Errorf("verify interval must be positive")
+		}
+		verifyIntervalDuration = verifyDuration
+	}
+	return nil
+}
+
+// validateResumeFlags ensures --resume and --resume-run are used with valid combinations.
+func validateResumeFlags() error {
+	if !resumeRun && resumeRunID == "" {
+		return nil
+	}
+
+	if dryRun {
+		return fmt.Errorf("--resume cannot be used with --dry-run")
+	}
+
+	if clearVectors {
+		return fmt.Errorf("--resume cannot be used with --clear")
+	}
+
+	if spreadsheetConfigPath != "" {
+		return fmt.Errorf("--resume cannot be used with --spreadsheet-config")
+	}
+
 	return nil
 }
 
@@ -1385,6 +1630,28 @@ func formatHeadersDisplay(headers []string, maxLen int) string {
 	return result
 }
 
+// prepareGitHubFiles extracts GitHub metadata for files read from a repository clone
+func prepareGitHubFiles(files []*pkgdomain.FileInfo) {
+	metadataExtractor := metadata.NewMetadataExtractor()
+	for _, f := range files {
+		if f.IsPDF {
+			// PDF files: move binary Content to RawBytes to avoid corruption
+			f.RawBytes = []byte(f.Content)
+			f.Content = ""
+			continue
+		}
+		parts := parseGitHubPath(f.Path)
+		if parts != nil {
+			meta, err := metadataExtractor.ExtractGitHubMetadata(parts.owner, parts.repo, parts.relativePath, f.Content)
+			if err != nil {
+				log.Printf("Warning: Failed to extract metadata for %s: %v", f.Path, err)
+				continue
+			}
+			f.Metadata = *meta
+		}
+	}
+}
+
 type githubPathParts struct {
 	owner        string
 	repo         string
@@ -1421,3 +1688,25 @@ func resolveS3SourceRegion(cfg *appconfig.Config) string {
 	}
 	return cfg.S3SourceRegion
 }
+
+// applyTenantScope assigns the run to a tenant and, with TENANT_INDEX_PER_TENANT,
+// routes it to the tenant's OpenSearch and S3 Vectors indexes
+func applyTenantScope(cfg *appconfig.Config, tenant string) error {
+	if tenant != "" {
+		if err := appconfig.ValidateTenantName(tenant); err != nil {
+			return fmt.Errorf("--tenant: %w", err)
+		}
+		cfg.TenantID = tenant
+	}
+	if !cfg.TenantIndexPerTenant {
+		return nil
+	}
+	if cfg.TenantID == "" {
+		return fmt.Errorf("TENANT_INDEX_PER_TENANT requires --tenant or TENANT_ID")
+	}
+	cfg.OpenSearchIndex = cfg.TenantIndexName(cfg.OpenSearchIndex, cfg.TenantID)
+	cfg.AWSS3VectorIndex = cfg.TenantIndexName(cfg.AWSS3VectorIndex, cfg.TenantID)
+	log.Printf("Vectorizing into tenant %s indexes (OpenSearch: %s, S3 Vectors: %s)",
+		cfg.TenantID, cfg.OpenSearchIndex, cfg.AWSS3VectorIndex)
+	return nil
+}
@@ -32,6 +32,8 @@ func resetFollowModeState() {
 	followMode = false
 	followInterval = DefaultFollowInterval
 	followIntervalDuration = 0
+	verifyInterval = ""
+	verifyIntervalDuration = 0
 	dryRun = false
 	clearVectors = false
 	followModeProcessing.Store(false)
@@ -117,6 +119,23 @@ func TestValidateFollowModeFlags(t *testing.T) {
 			wantErr:     true,
 			errContains: "--interval flag requires --follow",
 		},
+		{
+			name: "verify interval without follow",
+			setup: func(cmd *cobra.Command) {
+				verifyInterval = "6h"
+			},
+			wantErr:     true,
+			errContains: "--verify-interval flag requires --follow",
+		},
+		{
+			name: "invalid verify interval",
+			setup: func(cmd *cobra.Command) {
+				followMode = true
+				verifyInterval = "daily"
+			},
+			wantErr:     true,
+			errContains: "invalid verify interval",
+		},
 		{
 			name: "follow disabled resets duration",
 			setup: func(cmd *cobra.Command) {
@@ -324,3 +343,17 @@ func TestScanDirectoryDetectsPDFFiles(t *testing.T) {
 	assert.True(t, pdfFile.IsPDF)
 	assert.Equal(t, pdfPath, pdfFile.Path)
 }
+
+func TestValidateFollowModeFlags_VerifyInterval(t *testing.T) {
+	resetFollowModeState()
+	t.Cleanup(resetFollowModeState)
+
+	followMode = true
+	verifyInterval = "6h"
+	require.NoError(t, validateFollowModeFlags(newTestVectorizeCmd()))
+	assert.Equal(t, 6*time.Hour, verifyIntervalDuration)
+
+	verifyInterval = ""
+	require.NoError(t, validateFollowModeFlags(newTestVectorizeCmd()))
+	assert.Zero(t, verifyIntervalDuration, "consistency checks are off unless requested")
+}
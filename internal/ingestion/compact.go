@@ -0,0 +1,56 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ca-srg/ragent/internal/ingestion/sqlitevec"
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+)
+
+// RunVectorStoreCompact applies the configured quantization and storage layout to the
+// sqlite-vec database and rebuilds its ANN index
+func RunVectorStoreCompact(cmd *cobra.Command) error {
+	cfg, err := appconfig.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cfg.VectorDBBackend != "sqlite" {
+		return fmt.Errorf("vectorize compact requires VECTOR_DB_BACKEND=sqlite (current: %q)", cfg.VectorDBBackend)
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	store, err := sqlitevec.NewSqliteVecStoreWithOptions(cfg.SqliteVecDBPath, sqlitevec.Options{
+		Quantization:  cfg.SqliteVecQuantization,
+		Storage:       cfg.SqliteVecStorage,
+		RescoreFactor: cfg.SqliteVecRescoreFactor,
+		ANNLists:      cfg.SqliteVecANNLists,
+		ANNProbes:     cfg.SqliteVecANNProbes,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open SQLite vector store: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.MigrateLayout(ctx); err != nil {
+		return fmt.Errorf("failed to compact %s: %w", cfg.SqliteVecDBPath, err)
+	}
+
+	info, err := store.GetBackendInfo(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Compacted %s\n", cfg.SqliteVecDBPath)
+	for _, key := range []string{"vector_count", "quantization", "storage", "file_size_bytes", "ann_lists"} {
+		if value, ok := info[key]; ok {
+			fmt.Printf("  %-16s %v\n", key+":", value)
+		}
+	}
+	return nil
+}
@@ -166,6 +166,7 @@ func (r *Reader) rowToFileInfo(
 		CSVRowIndex: rowIndex,
 		Content:     content,
 		Metadata:    metadata,
+		SourcePath:  sourcePath,
 	}
 }
 
@@ -1,9 +1,11 @@
 package ingestion
 
 import (
+	"context"
 	"fmt"
 	"log"
 
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
 	"github.com/ca-srg/ragent/internal/ingestion/metadata"
 	"github.com/ca-srg/ragent/internal/ingestion/pdf"
 	"github.com/ca-srg/ragent/internal/ingestion/scanner"
@@ -11,6 +13,7 @@ import (
 	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
 	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
 	"github.com/ca-srg/ragent/internal/pkg/embedding"
+	"github.com/ca-srg/ragent/internal/pkg/opensearch"
 )
 
 // BuildDashboardDependencies constructs a FileScanner and Vectorizer for the
@@ -22,15 +25,25 @@ func BuildDashboardDependencies() (pkgdomain.FileScanner, pkgdomain.Vectorizer,
 		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	embeddingClient, err := embedding.NewEmbeddingClient(appCfg)
+	vec, err := newServerVectorizer(appCfg)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create embedding client: %w", err)
+		return nil, nil, err
+	}
+	return scanner.NewFileScanner(), vec, nil
+}
+
+// newServerVectorizer creates the vectorizer used by long-running servers. It indexes
+// into OPENSEARCH_INDEX instead of the --opensearch-index flag of the CLI commands.
+func newServerVectorizer(appCfg *appconfig.Config) (*vectorizer.VectorizerService, error) {
+	embeddingClient, err := newServerEmbeddingClient(appCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding client: %w", err)
 	}
 
 	sf := vectorizer.NewServiceFactory(appCfg)
 	vectorStoreClient, err := sf.CreateVectorStore()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create vector store client: %w", err)
+		return nil, fmt.Errorf("failed to create vector store client: %w", err)
 	}
 
 	metadataExtractor := metadata.NewMetadataExtractor()
@@ -66,8 +79,78 @@ func BuildDashboardDependencies() (pkgdomain.FileScanner, pkgdomain.Vectorizer,
 
 	vec, err := vectorizer.NewVectorizerService(serviceConfig)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create vectorizer service: %w", err)
+		return nil, fmt.Errorf("failed to create vectorizer service: %w", err)
+	}
+	return vec, nil
+}
+
+// newServerEmbeddingClient follows the embedding model of OPENSEARCH_INDEX, so a running
+// server keeps embedding like the served index across model migration cutovers
+func newServerEmbeddingClient(appCfg *appconfig.Config) (embedding.EmbeddingClient, error) {
+	if appCfg.OpenSearchEndpoint == "" {
+		return embedding.NewEmbeddingClient(appCfg)
+	}
+	client, err := opensearch.NewClient(vectorizer.NewIndexerFactory(appCfg).GetOpenSearchConfiguration())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpenSearch client: %w", err)
+	}
+	return opensearch.NewActiveEmbeddingClient(context.Background(), client, appCfg, appCfg.OpenSearchIndex)
+}
+
+// OpenFailureLedger opens the failure ledger kept in the hash store so the
+// dashboard can show failures of CLI vectorize runs. Call the returned
+// function to close it.
+func OpenFailureLedger() (pkgdomain.FailureLedger, func(), error) {
+	store, err := hashstore.NewHashStore()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open hash store: %w", err)
+	}
+	return &failureLedger{store: store}, func() { _ = store.Close() }, nil
+}
+
+// failureLedger adapts the hash store failure records to domain.FailureLedger
+type failureLedger struct {
+	store *hashstore.HashStore
+}
+
+func (l *failureLedger) ListRecentFailures(ctx context.Context, limit int) ([]pkgdomain.ProcessingError, error) {
+	records, err := l.store.ListFailures(ctx, hashstore.FailureFilter{Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+
+	failures := make([]pkgdomain.ProcessingError, 0, len(records))
+	for _, r := range records {
+		failures = append(failures, pkgdomain.ProcessingError{
+			Type:       appconfig.ErrorType(r.ErrorType),
+			Message:    r.Message,
+			FilePath:   r.FilePath,
+			Timestamp:  r.LastFailedAt,
+			Retryable:  r.Retryable,
+			RetryCount: r.Attempts - 1,
+			SourcePath: r.SourcePath,
+			Backend:    r.Backend,
+		})
+	}
+	return failures, nil
+}
+
+// OpenConsistencyReports opens the consistency reports kept in the hash store so
+// the dashboard can show the result of the latest `verify` run. Call the returned
+// function to close it.
+func OpenConsistencyReports() (pkgdomain.ConsistencyReports, func(), error) {
+	store, err := hashstore.NewHashStore()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open hash store: %w", err)
 	}
+	return &consistencyReports{store: store}, func() { _ = store.Close() }, nil
+}
+
+// consistencyReports adapts the hash store consistency reports to domain.ConsistencyReports
+type consistencyReports struct {
+	store *hashstore.HashStore
+}
 
-	return fileScanner, vec, nil
+func (r *consistencyReports) LatestConsistencyReport(ctx context.Context) (*pkgdomain.ConsistencyReport, error) {
+	return r.store.GetLatestConsistencyReport(ctx)
 }
@@ -0,0 +1,746 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	"github.com/ca-srg/ragent/internal/ingestion/vectorizer"
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+	"github.com/ca-srg/ragent/internal/pkg/opensearch"
+)
+
+// DocGetOptions holds the flags of `doc get`
+type DocGetOptions struct {
+	Target  string // Document ID, chunk ID or source file path
+	Index   string
+	Content bool // Print the full content of every chunk
+}
+
+// DocDeleteOptions holds the flags of `doc delete`
+type DocDeleteOptions struct {
+	Target   string
+	Category string
+	Index    string
+	DryRun   bool
+}
+
+// DocReindexOptions holds the flags of `doc reindex`
+type DocReindexOptions struct {
+	Path          string
+	CSVConfigPath string
+	OCRPromptFile string
+}
+
+// docSelector selects the documents acted on by the doc commands. A target matches a
+// document or chunk ID (every chunk of the document is selected) or a source file path
+// (every document read from the file, including the rows of a CSV file).
+type docSelector struct {
+	target   string
+	category string
+	paths    map[string]bool // File paths of documents matched by ID
+	sources  map[string]bool // Source files whose documents are all selected, for removed files
+
+	// access holds the secret, tenant and group filters of the caller, nil for none
+	access *opensearch.DocumentFilter
+}
+
+// docChunk is a chunk of a selected document as stored in the vector store and OpenSearch
+type docChunk struct {
+	ID            string
+	FilePath      string
+	Title         string
+	Category      string
+	Content       string
+	ChunkIndex    *int
+	TotalChunks   *int
+	InVectorStore bool
+	InOpenSearch  bool
+	VectorDim     int
+	DocumentDim   int
+	VectorNorm    float64
+	IndexedAt     string
+	VectorContent string
+
+	// Access fields of the chunk, read from OpenSearch when it is indexed there
+	Secret        bool
+	Tenant        string
+	AllowedGroups []string
+}
+
+// openSearchChunk holds the fields of an OpenSearch document shown by `doc get`
+type openSearchChunk struct {
+	Title       string    `json:"title"`
+	Category    string    `json:"category"`
+	Content     string    `json:"content"`
+	FilePath    string    `json:"file_path"`
+	IndexedAt   string    `json:"indexed_at"`
+	Embedding   []float64 `json:"embedding"`
+	ChunkIndex  *int      `json:"chunk_index"`
+	TotalChunks *int      `json:"total_chunks"`
+
+	Secret        bool     `json:"secret"`
+	Tenant        string   `json:"tenant"`
+	AllowedGroups []string `json:"allowed_groups"`
+}
+
+func newDocSelector(target, category string) (*docSelector, error) {
+	target = strings.TrimSpace(target)
+	category = strings.TrimSpace(category)
+	if target == "" && category == "" {
+		return nil, fmt.Errorf("specify a document ID or path, or --category")
+	}
+	if target != "" && category != "" {
+		return nil, fmt.Errorf("a document ID or path cannot be combined with --category")
+	}
+	return &docSelector{target: target, category: category, paths: make(map[string]bool)}, nil
+}
+
+// newSourceSelector selects every document read from the source files, including the
+// rows of CSV files and the pages of PDF files
+func newSourceSelector(sources []string) *docSelector {
+	sel := &docSelector{paths: make(map[string]bool), sources: make(map[string]bool, len(sources))}
+	for _, source := range sources {
+		sel.sources[source] = true
+	}
+	return sel
+}
+
+// pdfPagePattern matches the file path of a PDF page document
+var pdfPagePattern = regexp.MustCompile(`^pdf://(.+)/page/\d+$`)
+
+// sourceFileOf returns the source file of a document, unwrapping CSV rows and PDF pages
+func sourceFileOf(filePath string) string {
+	if match := pdfPagePattern.FindStringSubmatch(filePath); match != nil {
+		return match[1]
+	}
+	return sourcePathOf(filePath)
+}
+
+// query returns the OpenSearch query clause of the selected documents
+func (s *docSelector) query() map[string]any {
+	if s.category != "" {
+		return map[string]any{"term": map[string]any{"category": s.category}}
+	}
+	if len(s.sources) > 0 {
+		sources := make([]string, 0, len(s.sources))
+		for source := range s.sources {
+			sources = append(sources, source)
+		}
+		sort.Strings(sources)
+		should := []any{map[string]any{"terms": map[string]any{"file_path": sources}}}
+		for _, source := range sources {
+			should = append(should,
+				map[string]any{"prefix": map[string]any{"file_path": "csv://" + source + "/row/"}},
+				map[string]any{"prefix": map[string]any{"file_path": "pdf://" + source + "/page/"}},
+			)
+		}
+		return map[string]any{"bool": map[string]any{"should": should, "minimum_should_match": 1}}
+	}
+	should := []any{
+		map[string]any{"ids": map[string]any{"values": []string{s.target, s.target + "_chunk_0"}}},
+		map[string]any{"term": map[string]any{"file_path": s.target}},
+		map[string]any{"prefix": map[string]any{"file_path": "csv://" + s.target + "/row/"}},
+	}
+	if len(s.paths) > 0 {
+		should = append(should, map[string]any{"terms": map[string]any{"file_path": s.sortedPaths()}})
+	}
+	return map[string]any{"bool": map[string]any{"should": should, "minimum_should_match": 1}}
+}
+
+// matches reports whether a stored vector or document belongs to the selection
+func (s *docSelector) matches(id, filePath, category string) bool {
+	if s.category != "" {
+		return category == s.category
+	}
+	if len(s.sources) > 0 {
+		return filePath != "" && s.sources[sourceFileOf(filePath)]
+	}
+	return s.matchesID(id) || s.matchesPath(filePath) || s.paths[filePath]
+}
+
+func (s *docSelector) matchesID(id string) bool {
+	return id == s.target || documentKeyOf(id) == documentKeyOf(s.target)
+}
+
+func (s *docSelector) matchesPath(filePath string) bool {
+	return filePath != "" && (filePath == s.target || sourcePathOf(filePath) == s.target)
+}
+
+// allows reports whether the caller may read a document with the given access fields
+func (s *docSelector) allows(secret bool, tenant string, allowedGroups []string) bool {
+	return s.access == nil || s.access.Allows(secret, tenant, allowedGroups)
+}
+
+func (s *docSelector) sortedPaths() []string {
+	paths := make([]string, 0, len(s.paths))
+	for path := range s.paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// documentKeyOf strips the _chunk_<n> suffix of a chunk ID
+func documentKeyOf(id string) string {
+	if match := chunkIDPattern.FindStringSubmatch(id); match != nil {
+		return match[1]
+	}
+	return id
+}
+
+// docTargets are the stores read and written by the doc commands
+type docTargets struct {
+	*consistencyTargets
+	exporter vectorizer.VectorExporter
+}
+
+func openDocTargets(cfg *appconfig.Config, index string) (*docTargets, func(), error) {
+	targets, closeTargets, err := openConsistencyTargets(cfg, index)
+	if err != nil {
+		return nil, nil, err
+	}
+	exporter, ok := targets.vectors.(vectorizer.VectorExporter)
+	if !ok {
+		closeTargets()
+		return nil, nil, fmt.Errorf("the vector store does not support listing vectors with their embeddings")
+	}
+	return &docTargets{consistencyTargets: targets, exporter: exporter}, closeTargets, nil
+}
+
+// collect reads the chunks of the selected documents from OpenSearch and the vector store
+func (t *docTargets) collect(ctx context.Context, sel *docSelector) ([]*docChunk, error) {
+	chunks := make(map[string]*docChunk)
+	denied := make(map[string]bool) // Chunks the caller may not read
+	chunkOf := func(id string) *docChunk {
+		chunk, ok := chunks[id]
+		if !ok {
+			chunk = &docChunk{ID: id}
+			chunks[id] = chunk
+		}
+		return chunk
+	}
+
+	scan := func() error {
+		after := ""
+		for {
+			docs, err := t.documents.ScanMatchingDocuments(ctx, t.index, sel.query(), after, verifyPageSize)
+			if err != nil {
+				return fmt.Errorf("failed to search documents of %s: %w", t.index, err)
+			}
+			for _, doc := range docs {
+				var source openSearchChunk
+				if err := json.Unmarshal(doc.Source, &source); err != nil {
+					return fmt.Errorf("failed to decode document %s: %w", doc.ID, err)
+				}
+				if !sel.allows(source.Secret, source.Tenant, source.AllowedGroups) {
+					denied[doc.ID] = true
+					continue
+				}
+				chunk := chunkOf(doc.ID)
+				chunk.InOpenSearch = true
+				chunk.FilePath = source.FilePath
+				chunk.Title = source.Title
+				chunk.Category = source.Category
+				chunk.Content = source.Content
+				chunk.ChunkIndex = source.ChunkIndex
+				chunk.TotalChunks = source.TotalChunks
+				chunk.DocumentDim = len(source.Embedding)
+				chunk.IndexedAt = source.IndexedAt
+				chunk.Secret = source.Secret
+				chunk.Tenant = source.Tenant
+				chunk.AllowedGroups = source.AllowedGroups
+				if sel.category == "" && sel.matchesID(doc.ID) && source.FilePath != "" {
+					sel.paths[source.FilePath] = true
+				}
+			}
+			if len(docs) < verifyPageSize {
+				return nil
+			}
+			after = docs[len(docs)-1].ID
+		}
+	}
+
+	log.Printf("Searching OpenSearch index %s...", t.index)
+	if err := scan(); err != nil {
+		return nil, err
+	}
+	// Chunks of a document matched by ID share its file path
+	if len(sel.paths) > 0 {
+		if err := scan(); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Println("Reading vectors from the vector store...")
+	cursor := ""
+	for {
+		vectors, next, err := t.exporter.ExportVectors(ctx, cursor, verifyPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list vectors: %w", err)
+		}
+		for _, v := range vectors {
+			if !sel.matches(v.ID, v.Metadata.FilePath, v.Metadata.Category) {
+				continue
+			}
+			// The vector store may lack the tenant or groups, so chunks hidden in
+			// OpenSearch stay hidden
+			if denied[v.ID] || !sel.allows(v.Metadata.Secret, v.Metadata.Tenant, v.Metadata.AllowedGroups) {
+				continue
+			}
+			chunk := chunkOf(v.ID)
+			chunk.InVectorStore = true
+			chunk.VectorDim = len(v.Embedding)
+			chunk.VectorNorm = vectorNorm(v.Embedding)
+			chunk.VectorContent = v.Content
+			if chunk.FilePath == "" {
+				chunk.FilePath = v.Metadata.FilePath
+				chunk.Title = v.Metadata.Title
+				chunk.Category = v.Metadata.Category
+			}
+			if !chunk.InOpenSearch {
+				chunk.Secret = v.Metadata.Secret
+				chunk.Tenant = v.Metadata.Tenant
+				chunk.AllowedGroups = v.Metadata.AllowedGroups
+			}
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	result := make([]*docChunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		result = append(result, chunk)
+	}
+	sortDocChunks(result)
+	return result, nil
+}
+
+// sortDocChunks orders chunks by file path and chunk index
+func sortDocChunks(chunks []*docChunk) {
+	sort.Slice(chunks, func(i, j int) bool {
+		a, b := chunks[i], chunks[j]
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		ai, bi := chunkIndexOf(a), chunkIndexOf(b)
+		if ai != bi {
+			return ai < bi
+		}
+		return a.ID < b.ID
+	})
+}
+
+func chunkIndexOf(chunk *docChunk) int {
+	if chunk.ChunkIndex != nil {
+		return *chunk.ChunkIndex
+	}
+	if match := chunkIDPattern.FindStringSubmatch(chunk.ID); match != nil {
+		var index int
+		_, _ = fmt.Sscanf(match[2], "%d", &index)
+		return index
+	}
+	return 0
+}
+
+func vectorNorm(embedding []float64) float64 {
+	sum := 0.0
+	for _, v := range embedding {
+		sum += v * v
+	}
+	return math.Sqrt(sum)
+}
+
+// RunDocGet prints every chunk of a document with its metadata and embedding stats
+// across the vector store, OpenSearch and the hash store
+func RunDocGet(cmd *cobra.Command, opts DocGetOptions) error {
+	sel, err := newDocSelector(opts.Target, "")
+	if err != nil {
+		return err
+	}
+	ctx, cfg, index, err := docCommandSetup(cmd, opts.Index)
+	if err != nil {
+		return err
+	}
+	access := cliAccessFilter(cfg)
+	sel.access = &access
+
+	targets, closeTargets, err := openDocTargets(cfg, index)
+	if err != nil {
+		return err
+	}
+	defer closeTargets()
+
+	chunks, err := targets.collect(ctx, sel)
+	if err != nil {
+		return err
+	}
+	if len(chunks) == 0 {
+		return fmt.Errorf("no document matches %q in the vector store or %s", opts.Target, index)
+	}
+
+	records, err := targets.hashes.GetAllFileHashesForSourceTypes(ctx, hashSourceTypes)
+	if err != nil {
+		return err
+	}
+	printDocChunks(chunks, records, opts.Content)
+	return nil
+}
+
+// printDocChunks prints the chunks grouped by file path
+func printDocChunks(chunks []*docChunk, records map[string]*hashstore.FileHashRecord, full bool) {
+	current := ""
+	for i, chunk := range chunks {
+		if i == 0 || chunk.FilePath != current {
+			current = chunk.FilePath
+			fmt.Printf("\n%s\n", displayPath(current))
+			fmt.Printf("  Title:     %s\n", chunk.Title)
+			fmt.Printf("  Category:  %s\n", chunk.Category)
+			if record, ok := records[sourcePathOf(current)]; ok {
+				fmt.Printf("  Hash:      %s (%s, vectorized %s)\n", record.ContentHash, record.SourceType,
+					record.VectorizedAt.Format(time.DateTime))
+			} else {
+				fmt.Println("  Hash:      (no hash store record)")
+			}
+		}
+
+		position := ""
+		if chunk.ChunkIndex != nil && chunk.TotalChunks != nil {
+			position = fmt.Sprintf(" (chunk %d/%d)", *chunk.ChunkIndex+1, *chunk.TotalChunks)
+		}
+		fmt.Printf("\n  %s%s\n", chunk.ID, position)
+		if chunk.InVectorStore {
+			fmt.Printf("    Vector store:  %d dimensions, norm %.4f\n", chunk.VectorDim, chunk.VectorNorm)
+		} else {
+			fmt.Println("    Vector store:  missing")
+		}
+		if chunk.InOpenSearch {
+			fmt.Printf("    OpenSearch:    %d dimensions, indexed %s\n", chunk.DocumentDim, chunk.IndexedAt)
+		} else {
+			fmt.Println("    OpenSearch:    missing")
+		}
+
+		content := chunk.Content
+		if content == "" {
+			content = chunk.VectorContent
+		}
+		if !full {
+			content = truncateString(strings.Join(strings.Fields(content), " "), 200)
+		}
+		fmt.Printf("    Content (%d chars): %s\n", len([]rune(chunk.Content)), content)
+	}
+}
+
+func displayPath(filePath string) string {
+	if filePath == "" {
+		return "(no file path)"
+	}
+	return filePath
+}
+
+// RunDocDelete removes the selected documents from the vector store, OpenSearch and the hash store
+func RunDocDelete(cmd *cobra.Command, opts DocDeleteOptions) error {
+	sel, err := newDocSelector(opts.Target, opts.Category)
+	if err != nil {
+		return err
+	}
+	ctx, cfg, index, err := docCommandSetup(cmd, opts.Index)
+	if err != nil {
+		return err
+	}
+
+	targets, closeTargets, err := openDocTargets(cfg, index)
+	if err != nil {
+		return err
+	}
+	defer closeTargets()
+
+	chunks, err := targets.collect(ctx, sel)
+	if err != nil {
+		return err
+	}
+	if len(chunks) == 0 {
+		fmt.Println("No matching documents found")
+		return nil
+	}
+	records, err := targets.hashes.GetAllFileHashesForSourceTypes(ctx, hashSourceTypes)
+	if err != nil {
+		return err
+	}
+
+	if opts.DryRun {
+		fmt.Printf("\nWould delete %d chunk(s):\n", len(chunks))
+		for _, chunk := range chunks {
+			fmt.Printf("  %s  %s\n", chunk.ID, displayPath(chunk.FilePath))
+		}
+		for _, path := range docHashRecordPaths(sel, chunks, records) {
+			fmt.Printf("  hash record: %s\n", path)
+		}
+		return nil
+	}
+
+	result := deleteDocChunks(ctx, sel, chunks, records, targets.vectors, targets.documents, index, targets.hashes)
+	fmt.Printf("\nDeleted %d vector(s), %d document(s) and %d hash record(s)\n",
+		result.vectors, result.documents, result.hashRecords)
+	if result.failed > 0 {
+		return fmt.Errorf("%d deletion(s) failed; run the command again to retry them", result.failed)
+	}
+	return nil
+}
+
+// docDeleteResult counts the entries removed by deleteDocChunks
+type docDeleteResult struct {
+	vectors     int
+	documents   int
+	hashRecords int
+	failed      int
+}
+
+// deleteDocChunks deletes the chunks from the backends they are stored in and drops the hash
+// records of source files whose documents are all deleted, so that verify does not report them
+func deleteDocChunks(
+	ctx context.Context,
+	sel *docSelector,
+	chunks []*docChunk,
+	records map[string]*hashstore.FileHashRecord,
+	vectors vectorDeleter,
+	documents documentDeleter,
+	index string,
+	hashes hashRecordDeleter,
+) docDeleteResult {
+	var result docDeleteResult
+	for _, chunk := range chunks {
+		if chunk.InVectorStore {
+			if err := vectors.DeleteVector(ctx, chunk.ID); err != nil {
+				log.Printf("Warning: failed to delete vector %s: %v", chunk.ID, err)
+				result.failed++
+			} else {
+				result.vectors++
+			}
+		}
+		if chunk.InOpenSearch {
+			if err := documents.DeleteDocument(ctx, index, chunk.ID); err != nil {
+				log.Printf("Warning: failed to delete document %s from %s: %v", chunk.ID, index, err)
+				result.failed++
+			} else {
+				result.documents++
+			}
+		}
+	}
+
+	for _, path := range docHashRecordPaths(sel, chunks, records) {
+		if err := hashes.DeleteFileHash(ctx, records[path].SourceType, path); err != nil {
+			log.Printf("Warning: failed to delete hash record of %s: %v", path, err)
+			result.failed++
+			continue
+		}
+		result.hashRecords++
+	}
+	return result
+}
+
+// sourceRemover deletes the documents of source files that no longer exist
+type sourceRemover func(ctx context.Context, sources []string) error
+
+// newSourceRemover returns a sourceRemover deleting from the vector store and the
+// OpenSearch index. Hash records are left to the caller.
+func newSourceRemover(cfg *appconfig.Config, index string) sourceRemover {
+	return func(ctx context.Context, sources []string) error {
+		if len(sources) == 0 {
+			return nil
+		}
+		targets, closeTargets, err := openDocTargets(cfg, index)
+		if err != nil {
+			return err
+		}
+		defer closeTargets()
+
+		sel := newSourceSelector(sources)
+		chunks, err := targets.collect(ctx, sel)
+		if err != nil {
+			return err
+		}
+		result := deleteDocChunks(ctx, sel, chunks, nil, targets.vectors, targets.documents, index, targets.hashes)
+		log.Printf("Deleted %d vector(s) and %d document(s) of %d removed file(s)", result.vectors, result.documents, len(sources))
+		if result.failed > 0 {
+			return fmt.Errorf("%d deletion(s) failed", result.failed)
+		}
+		return nil
+	}
+}
+
+// docHashRecordPaths returns the hash records dropped together with the chunks. Deleting
+// single CSV rows keeps the record of their file unless the file itself is selected.
+func docHashRecordPaths(sel *docSelector, chunks []*docChunk, records map[string]*hashstore.FileHashRecord) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, chunk := range chunks {
+		source := sourcePathOf(chunk.FilePath)
+		if seen[source] {
+			continue
+		}
+		if source != chunk.FilePath && (sel.category != "" || source != sel.target) {
+			continue
+		}
+		if _, ok := records[source]; !ok {
+			continue
+		}
+		seen[source] = true
+		paths = append(paths, source)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// RunDocReindex vectorizes a source file again and then deletes the chunks of its previous
+// version that were not written again, so the document stays searchable throughout
+func RunDocReindex(cmd *cobra.Command, opts DocReindexOptions) error {
+	path := strings.TrimSpace(opts.Path)
+	if path == "" {
+		return fmt.Errorf("specify the path of the source file to re-index")
+	}
+	csvConfigPath = opts.CSVConfigPath
+	ocrPromptFile = opts.OCRPromptFile
+
+	ctx, cfg, index, err := docCommandSetup(cmd, "")
+	if err != nil {
+		return err
+	}
+
+	var files []*pkgdomain.FileInfo
+	switch failureSourceType(nil, path) {
+	case "s3":
+		files = loadSourceFiles(ctx, cfg, nil, []string{path}, nil)
+	case "github":
+		files = loadSourceFiles(ctx, cfg, nil, nil, []string{path})
+	default:
+		files = loadSourceFiles(ctx, cfg, []string{path}, nil, nil)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("failed to read %s from its source", path)
+	}
+
+	service, csvCfg, err := createVectorizerServiceFromFlags(cfg, nil)
+	if err != nil {
+		return err
+	}
+	log.Println("Validating configuration and service connections...")
+	validationCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := service.ValidateConfiguration(validationCtx); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	targets, closeTargets, err := openDocTargets(cfg, index)
+	if err != nil {
+		return err
+	}
+	defer closeTargets()
+	service.SetOCRCache(targets.hashes)
+
+	sel, _ := newDocSelector(path, "")
+	previous, err := targets.collect(ctx, sel)
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+	result, err := service.VectorizeFiles(ctx, files, false)
+	if err != nil {
+		return fmt.Errorf("vectorization failed: %w", err)
+	}
+	if result.SuccessCount > 0 {
+		updateHashStoreForSuccessfulFiles(ctx, targets.hashes, files, result)
+	}
+	recordFailures(ctx, targets.hashes, files, result)
+	if result.SuccessCount > 0 {
+		dualWriteMigrationCandidate(ctx, cfg, targets.hashes, csvCfg, index, files)
+	}
+	printResults(result, false)
+
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("re-indexing %s failed; its previous chunks were kept", path)
+	}
+
+	// Chunks of the previous version would otherwise remain when the document shrinks
+	rescan, _ := newDocSelector(path, "")
+	current, err := targets.collect(ctx, rescan)
+	if err != nil {
+		return err
+	}
+	if stale := staleDocChunks(previous, current, started); len(stale) > 0 {
+		removed := deleteDocChunks(ctx, sel, stale, nil, targets.vectors, targets.documents, index, targets.hashes)
+		log.Printf("Deleted %d stale vector(s) and %d stale document(s) of %s", removed.vectors, removed.documents, path)
+		if removed.failed > 0 {
+			return fmt.Errorf("failed to delete %d stale entries of %s", removed.failed, path)
+		}
+	}
+	return nil
+}
+
+// staleDocChunks returns the chunks of a previous version that a vectorize run started at
+// started did not write again. Chunk IDs are derived from the document and chunk index, so
+// rewritten chunks keep their ID and carry a newer indexed_at.
+func staleDocChunks(previous, current []*docChunk, started time.Time) []*docChunk {
+	started = started.Truncate(time.Second) // indexed_at has second precision
+	written := make(map[string]bool, len(current))
+	for _, chunk := range current {
+		indexedAt, err := time.Parse(time.RFC3339, chunk.IndexedAt)
+		if err == nil && !indexedAt.Before(started) {
+			written[chunk.ID] = true
+		}
+	}
+
+	var stale []*docChunk
+	for _, chunk := range previous {
+		if !written[chunk.ID] {
+			stale = append(stale, chunk)
+		}
+	}
+	return stale
+}
+
+// cliAccessFilter returns the filters `ragent query` applies to the local CLI identity:
+// secret documents are hidden, TENANT_ID scopes the tenant and ACL_LOCAL_GROUPS the groups
+func cliAccessFilter(cfg *appconfig.Config) opensearch.DocumentFilter {
+	filter := opensearch.DocumentFilter{
+		ExcludeSecret:   true,
+		Groups:          cfg.LocalGroups(),
+		EnforceGroupACL: true,
+	}
+	if cfg.TenantID != "" {
+		filter.Tenants = []string{cfg.TenantID}
+	}
+	return filter
+}
+
+// docCommandSetup loads the configuration and resolves the OpenSearch index of a doc command
+func docCommandSetup(cmd *cobra.Command, index string) (context.Context, *appconfig.Config, string, error) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cfg, err := appconfig.Load()
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := validateOpenSearchFlags(); err != nil {
+		return nil, nil, "", fmt.Errorf("flag validation failed: %w", err)
+	}
+	if index == "" {
+		index = openSearchIndexName
+	}
+	return ctx, cfg, index, nil
+}
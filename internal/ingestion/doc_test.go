@@ -0,0 +1,184 @@
+package ingestion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+func TestNewDocSelector(t *testing.T) {
+	_, err := newDocSelector("", "")
+	assert.Error(t, err)
+	_, err = newDocSelector("docs/a.md", "secret")
+	assert.Error(t, err)
+
+	sel, err := newDocSelector(" docs/a.md ", "")
+	require.NoError(t, err)
+	assert.Equal(t, "docs/a.md", sel.target)
+}
+
+func TestDocSelectorMatches(t *testing.T) {
+	byID, err := newDocSelector("abc_chunk_2", "")
+	require.NoError(t, err)
+	assert.True(t, byID.matches("abc_chunk_0", "", ""), "every chunk of the document is selected")
+	assert.True(t, byID.matches("abc", "", ""))
+	assert.False(t, byID.matches("abcd_chunk_0", "", ""))
+
+	byKey, err := newDocSelector("abc", "")
+	require.NoError(t, err)
+	assert.True(t, byKey.matches("abc_chunk_5", "", ""))
+
+	byPath, err := newDocSelector("data/rows.csv", "")
+	require.NoError(t, err)
+	assert.True(t, byPath.matches("r1", "csv://data/rows.csv/row/1", ""), "the path of a CSV file selects its rows")
+	assert.True(t, byPath.matches("x", "data/rows.csv", ""))
+	assert.False(t, byPath.matches("y", "data/other.csv", ""))
+
+	byPath.paths["docs/b.md"] = true
+	assert.True(t, byPath.matches("b_chunk_1", "docs/b.md", ""), "file paths found by ID are selected")
+
+	byCategory, err := newDocSelector("", "secret")
+	require.NoError(t, err)
+	assert.True(t, byCategory.matches("any", "docs/a.md", "secret"))
+	assert.False(t, byCategory.matches("any", "secret", "public"))
+}
+
+func TestDocSelectorQuery(t *testing.T) {
+	sel, err := newDocSelector("docs/a.md", "")
+	require.NoError(t, err)
+	should := sel.query()["bool"].(map[string]any)["should"].([]any)
+	assert.Len(t, should, 3)
+
+	sel.paths["docs/a.md"] = true
+	should = sel.query()["bool"].(map[string]any)["should"].([]any)
+	assert.Equal(t, map[string]any{"terms": map[string]any{"file_path": []string{"docs/a.md"}}}, should[3])
+
+	byCategory, err := newDocSelector("", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"term": map[string]any{"category": "secret"}}, byCategory.query())
+}
+
+func TestSourceSelector(t *testing.T) {
+	sel := newSourceSelector([]string{"github://org/repo/old.md", "data/rows.csv", "docs/manual.pdf"})
+	assert.True(t, sel.matches("o_chunk_0", "github://org/repo/old.md", ""))
+	assert.True(t, sel.matches("r1", "csv://data/rows.csv/row/1", ""), "rows of a removed CSV file are selected")
+	assert.True(t, sel.matches("p3", "pdf://docs/manual.pdf/page/3", ""), "pages of a removed PDF file are selected")
+	assert.False(t, sel.matches("n_chunk_0", "github://org/repo/new.md", ""))
+	assert.False(t, sel.matches("old.md", "", ""), "IDs alone never select documents of removed files")
+
+	should := sel.query()["bool"].(map[string]any)["should"].([]any)
+	require.Len(t, should, 7)
+	assert.Equal(t, map[string]any{"terms": map[string]any{"file_path": []string{
+		"data/rows.csv", "docs/manual.pdf", "github://org/repo/old.md",
+	}}}, should[0])
+	assert.Equal(t, map[string]any{"prefix": map[string]any{"file_path": "csv://data/rows.csv/row/"}}, should[1])
+	assert.Equal(t, map[string]any{"prefix": map[string]any{"file_path": "pdf://data/rows.csv/page/"}}, should[2])
+}
+
+func TestDeleteDocChunks(t *testing.T) {
+	records := map[string]*hashstore.FileHashRecord{
+		"docs/a.md":     {SourceType: "local", FilePath: "docs/a.md"},
+		"data/rows.csv": {SourceType: "s3", FilePath: "data/rows.csv"},
+	}
+	chunks := []*docChunk{
+		{ID: "a_chunk_0", FilePath: "docs/a.md", InVectorStore: true, InOpenSearch: true},
+		{ID: "a_chunk_1", FilePath: "docs/a.md", InOpenSearch: true},
+		{ID: "r_1", FilePath: "csv://data/rows.csv/row/1", InVectorStore: true, InOpenSearch: true},
+	}
+
+	sel, err := newDocSelector("", "secret")
+	require.NoError(t, err)
+	deleter := &fakeConsistencyDeleter{}
+	result := deleteDocChunks(context.Background(), sel, chunks, records, deleter, deleter, "docs", deleter)
+	assert.Equal(t, docDeleteResult{vectors: 2, documents: 3, hashRecords: 1}, result)
+	assert.Equal(t, []string{"a_chunk_0", "r_1"}, deleter.vectors)
+	assert.Equal(t, []string{"docs/a_chunk_0", "docs/a_chunk_1", "docs/r_1"}, deleter.documents)
+	assert.Equal(t, []string{"local:docs/a.md"}, deleter.hashes, "deleting CSV rows keeps the record of the file")
+
+	byFile, err := newDocSelector("data/rows.csv", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"data/rows.csv"}, docHashRecordPaths(byFile, chunks[2:], records))
+
+	failing := &fakeConsistencyDeleter{fail: "a_chunk_0"}
+	result = deleteDocChunks(context.Background(), sel, chunks[:1], nil, failing, failing, "docs", failing)
+	assert.Equal(t, 1, result.failed)
+	assert.Equal(t, 1, result.documents)
+	assert.Empty(t, failing.hashes)
+}
+
+func TestSortDocChunks(t *testing.T) {
+	two, ten := 2, 10
+	chunks := []*docChunk{
+		{ID: "a_chunk_10", FilePath: "docs/a.md", ChunkIndex: &ten},
+		{ID: "b_chunk_0", FilePath: "docs/b.md"},
+		{ID: "a_chunk_2", FilePath: "docs/a.md", ChunkIndex: &two},
+		{ID: "a_chunk_3", FilePath: "docs/a.md"},
+	}
+	sortDocChunks(chunks)
+
+	ids := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		ids[i] = chunk.ID
+	}
+	assert.Equal(t, []string{"a_chunk_2", "a_chunk_3", "a_chunk_10", "b_chunk_0"}, ids)
+}
+
+func TestStaleDocChunks(t *testing.T) {
+	started := time.Date(2026, 5, 1, 10, 0, 0, 500, time.UTC)
+	previous := []*docChunk{
+		{ID: "a_chunk_0", IndexedAt: "2026-04-01T00:00:00Z"},
+		{ID: "a_chunk_1", IndexedAt: "2026-04-01T00:00:00Z"},
+		{ID: "a_chunk_2", IndexedAt: "2026-04-01T00:00:00Z"},
+	}
+	current := []*docChunk{
+		{ID: "a_chunk_0", IndexedAt: "2026-05-01T10:00:00Z"},
+		{ID: "a_chunk_1", IndexedAt: "2026-05-01T10:00:03Z"},
+		{ID: "a_chunk_2", IndexedAt: "2026-04-01T00:00:00Z"},
+	}
+
+	stale := staleDocChunks(previous, current, started)
+	require.Len(t, stale, 1)
+	assert.Equal(t, "a_chunk_2", stale[0].ID, "chunks the reindex did not write again are stale")
+
+	assert.Len(t, staleDocChunks(previous, nil, started), 3)
+	assert.Empty(t, staleDocChunks(nil, current, started))
+}
+
+func TestDocSelectorAllows(t *testing.T) {
+	sel, err := newDocSelector("docs/a.md", "")
+	require.NoError(t, err)
+	assert.True(t, sel.allows(true, "other", []string{"sre"}), "no access filter selects every document")
+
+	access := cliAccessFilter(&appconfig.Config{TenantID: "acme", ACLLocalGroups: []string{"eng"}})
+	sel.access = &access
+	assert.True(t, sel.allows(false, "acme", nil))
+	assert.True(t, sel.allows(false, "acme", []string{"eng", "sre"}))
+	assert.False(t, sel.allows(true, "acme", nil), "secret documents are hidden")
+	assert.False(t, sel.allows(false, "other", nil), "documents of other tenants are hidden")
+	assert.False(t, sel.allows(false, "", nil), "documents without a tenant are hidden under TENANT_ID")
+	assert.False(t, sel.allows(false, "acme", []string{"sre"}), "documents of other groups are hidden")
+}
+
+func TestFilterListItems(t *testing.T) {
+	items := []pkgdomain.VectorListItem{
+		{Key: "public", RawMetadata: map[string]interface{}{"secret": false}},
+		{Key: "secret", RawMetadata: map[string]interface{}{"secret": true}},
+		{Key: "eng", RawMetadata: map[string]interface{}{"allowed_groups": []interface{}{"eng"}}},
+		{Key: "sre", RawMetadata: map[string]interface{}{"allowed_groups": []interface{}{"sre"}}},
+		{Key: "no-metadata"},
+	}
+	allowed := filterListItems(items, cliAccessFilter(&appconfig.Config{ACLLocalGroups: []string{"eng"}}))
+
+	keys := make([]string, len(allowed))
+	for i, item := range allowed {
+		keys[i] = item.Key
+	}
+	assert.Equal(t, []string{"public", "eng", "no-metadata"}, keys)
+}
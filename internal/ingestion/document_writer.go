@@ -0,0 +1,304 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ca-srg/ragent/internal/ingestion/metadata"
+	"github.com/ca-srg/ragent/internal/ingestion/vectorizer"
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+	"github.com/ca-srg/ragent/internal/pkg/opensearch"
+)
+
+const defaultIngestDirectory = "./source/mcp"
+
+// documentWriter saves documents submitted over MCP to the ingest directory and runs
+// them through the same metadata extraction, chunking and dual-backend indexing as
+// `vectorize`.
+type documentWriter struct {
+	cfg    *appconfig.Config
+	dir    string
+	scopes map[string]*writeScope // By tenant, "" for documents without a tenant
+	mu     sync.Mutex             // Serializes writes so an update never races an ingest of the same file
+}
+
+// writeScope holds the configuration, OpenSearch index and vectorizer of one tenant
+type writeScope struct {
+	cfg     *appconfig.Config
+	index   string
+	service *vectorizer.VectorizerService
+}
+
+// OpenDocumentWriter creates the writer behind the MCP ingest_document and
+// update_document tools. Returns a nil writer when MCP_INGEST_ENABLED is false.
+func OpenDocumentWriter() (pkgdomain.DocumentWriter, error) {
+	appCfg, err := appconfig.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if !appCfg.MCPIngestEnabled {
+		return nil, nil
+	}
+
+	dir := strings.TrimSpace(appCfg.MCPIngestDirectory)
+	if dir == "" {
+		dir = defaultIngestDirectory
+	}
+	w := &documentWriter{cfg: appCfg, dir: dir, scopes: make(map[string]*writeScope)}
+	// Per-tenant indexes have no default scope without TENANT_ID; writes then name a tenant
+	if !appCfg.TenantIndexPerTenant || appCfg.TenantID != "" {
+		if _, err := w.scope(appCfg.TenantID); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// scope returns the write scope of a tenant. Documents of a tenant go to its indexes with
+// TENANT_INDEX_PER_TENANT, like `vectorize --tenant`.
+func (w *documentWriter) scope(tenant string) (*writeScope, error) {
+	if scope, ok := w.scopes[tenant]; ok {
+		return scope, nil
+	}
+
+	cfg := *w.cfg
+	if err := applyTenantScope(&cfg, tenant); err != nil {
+		return nil, fmt.Errorf("cannot write documents of tenant %q: %w", tenant, err)
+	}
+	service, err := newServerVectorizer(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	scope := &writeScope{cfg: &cfg, index: cfg.OpenSearchIndex, service: service}
+	w.scopes[tenant] = scope
+	return scope, nil
+}
+
+func (w *documentWriter) WriteDocument(ctx context.Context, doc *pkgdomain.DocumentSubmission, replace bool) (*pkgdomain.DocumentWriteResult, error) {
+	if doc == nil {
+		return nil, fmt.Errorf("document is required")
+	}
+	tenant := doc.Tenant
+	if tenant == "" && w.cfg != nil {
+		tenant = w.cfg.TenantID
+	}
+	if tenant != "" {
+		if err := appconfig.ValidateTenantName(tenant); err != nil {
+			return nil, err
+		}
+	}
+	filePath, err := ingestFilePath(tenantIngestDirectory(w.dir, tenant), doc.Path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := renderSubmission(doc)
+	if err != nil {
+		return nil, err
+	}
+	access := writerAccess(doc.Access)
+	if err := checkSharedGroups(filePath, content, access); err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, statErr := os.Stat(filePath)
+	if statErr != nil && !os.IsNotExist(statErr) {
+		return nil, fmt.Errorf("failed to check %s: %w", filePath, statErr)
+	}
+	exists := statErr == nil
+	if replace && !exists {
+		return nil, fmt.Errorf("document %s does not exist", doc.Path)
+	}
+	if !replace && exists {
+		return nil, fmt.Errorf("document %s already exists", doc.Path)
+	}
+
+	scope, err := w.scope(tenant)
+	if err != nil {
+		return nil, err
+	}
+	targets, closeTargets, err := openDocTargets(scope.cfg, scope.index)
+	if err != nil {
+		return nil, err
+	}
+	defer closeTargets()
+
+	sel, _ := newDocSelector(filePath, "")
+	var previous []*docChunk
+	if replace {
+		previous, err = targets.collect(ctx, sel)
+		if err != nil {
+			return nil, err
+		}
+		// Documents the caller cannot read are reported like missing ones
+		for _, chunk := range previous {
+			if access != nil && !access.Allows(chunk.Secret, chunk.Tenant, chunk.AllowedGroups) {
+				return nil, fmt.Errorf("document %s does not exist", doc.Path)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %w", filePath, err)
+	}
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+
+	result := &pkgdomain.DocumentWriteResult{FilePath: filePath, Replaced: replace}
+
+	files := loadSourceFiles(ctx, scope.cfg, []string{filePath}, nil, nil)
+	if len(files) == 0 {
+		return result, fmt.Errorf("failed to read %s", filePath)
+	}
+	started := time.Now()
+	processing, err := scope.service.VectorizeFiles(ctx, files, false)
+	if err != nil {
+		return result, fmt.Errorf("vectorization failed: %w", err)
+	}
+	result.Processing = processing
+	if processing.SuccessCount > 0 {
+		updateHashStoreForSuccessfulFiles(ctx, targets.hashes, files, processing)
+	}
+	recordFailures(ctx, targets.hashes, files, processing)
+	if processing.SuccessCount > 0 {
+		dualWriteMigrationCandidate(ctx, scope.cfg, targets.hashes, nil, scope.index, files)
+	}
+
+	if len(processing.Errors) > 0 {
+		return result, fmt.Errorf("failed to index %s: %s", filePath, processing.Errors[0].Message)
+	}
+
+	// Chunks of the previous version would otherwise remain when the document shrinks.
+	// They are only deleted once the new version is indexed, so a failed update keeps them.
+	if len(previous) > 0 {
+		rescan, _ := newDocSelector(filePath, "")
+		current, err := targets.collect(ctx, rescan)
+		if err != nil {
+			return result, err
+		}
+		if stale := staleDocChunks(previous, current, started); len(stale) > 0 {
+			removed := deleteDocChunks(ctx, sel, stale, nil, targets.vectors, targets.documents, scope.index, targets.hashes)
+			result.RemovedVectors = removed.vectors
+			result.RemovedDocuments = removed.documents
+			if removed.failed > 0 {
+				return result, fmt.Errorf("failed to delete %d stale entries of %s", removed.failed, filePath)
+			}
+		}
+	}
+	return result, nil
+}
+
+// tenantIngestDirectory returns the directory holding the documents of a tenant, so that
+// tenants writing the same path never overwrite each other's files
+func tenantIngestDirectory(dir, tenant string) string {
+	if tenant == "" {
+		return dir
+	}
+	return filepath.Join(dir, tenant)
+}
+
+// writerAccess converts the caller's access into the filter applied to the documents it
+// replaces, or nil for a caller that may read every document
+func writerAccess(access *pkgdomain.DocumentAccess) *opensearch.DocumentFilter {
+	if access == nil {
+		return nil
+	}
+	return &opensearch.DocumentFilter{
+		ExcludeSecret:   access.ExcludeSecret,
+		Tenants:         access.Tenants,
+		Groups:          access.Groups,
+		EnforceGroupACL: true,
+	}
+}
+
+// checkSharedGroups rejects allowed_groups naming a group the caller is not a member of,
+// which would share the document with readers the caller does not speak for
+func checkSharedGroups(filePath, content string, access *opensearch.DocumentFilter) error {
+	meta, err := metadata.NewMetadataExtractor().ExtractMetadata(filePath, content)
+	if err != nil {
+		return err
+	}
+	if access == nil {
+		return nil
+	}
+	for _, group := range meta.AllowedGroups {
+		if !slices.Contains(access.Groups, group) {
+			return fmt.Errorf("allowed_groups may only list groups of the caller; %s is not one of them", group)
+		}
+	}
+	return nil
+}
+
+// ingestFilePath resolves a submitted path inside the ingest directory. Paths escaping
+// the directory are rejected and a missing extension defaults to .md.
+func ingestFilePath(dir, rel string) (string, error) {
+	rel = strings.TrimSpace(rel)
+	if rel == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	if path.IsAbs(rel) || filepath.IsAbs(rel) || strings.Contains(rel, `\`) {
+		return "", fmt.Errorf("path %s must be relative to the ingest directory", rel)
+	}
+	cleaned := path.Clean(rel)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("path %s must stay inside the ingest directory", rel)
+	}
+	switch strings.ToLower(path.Ext(cleaned)) {
+	case "":
+		cleaned += ".md"
+	case ".md", ".markdown":
+	default:
+		return "", fmt.Errorf("only markdown documents can be written, got %s", rel)
+	}
+	return filepath.Join(dir, filepath.FromSlash(cleaned)), nil
+}
+
+// renderSubmission merges the submitted category, tags and secret flag into the front
+// matter of the content so the metadata extractor picks them up like any other file.
+func renderSubmission(doc *pkgdomain.DocumentSubmission) (string, error) {
+	if strings.TrimSpace(doc.Content) == "" {
+		return "", fmt.Errorf("content is required")
+	}
+	frontMatter, body, err := metadata.NewMetadataExtractor().ParseFrontMatter(doc.Content)
+	if err != nil {
+		return "", err
+	}
+
+	if category := strings.TrimSpace(doc.Category); category != "" {
+		frontMatter["category"] = category
+	}
+	var tags []string
+	for _, tag := range doc.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > 0 {
+		frontMatter["tags"] = tags
+	}
+	if doc.Secret {
+		frontMatter["secret"] = true
+	}
+	if len(frontMatter) == 0 {
+		return body, nil
+	}
+
+	data, err := yaml.Marshal(frontMatter)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode front matter: %w", err)
+	}
+	return "---\n" + string(data) + "---\n" + body, nil
+}
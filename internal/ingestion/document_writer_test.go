@@ -0,0 +1,106 @@
+package ingestion
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ca-srg/ragent/internal/ingestion/metadata"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+func TestIngestFilePath(t *testing.T) {
+	p, err := ingestFilePath("source/mcp", "runbooks/db failover")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("source", "mcp", "runbooks", "db failover.md"), p)
+
+	p, err = ingestFilePath("source/mcp", "./notes/../guide.markdown")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("source", "mcp", "guide.markdown"), p)
+
+	for _, rel := range []string{"", "/etc/passwd.md", "../outside.md", "a/../../b.md", `..\b.md`, "data.csv"} {
+		_, err := ingestFilePath("source/mcp", rel)
+		assert.Error(t, err, rel)
+	}
+}
+
+func TestRenderSubmissionMergesFrontMatter(t *testing.T) {
+	content, err := renderSubmission(&pkgdomain.DocumentSubmission{
+		Content:  "---\ntitle: Failover\ncategory: Draft\n---\n# Failover\n\nSteps.",
+		Category: "Runbook",
+		Tags:     []string{"db", " "},
+		Secret:   true,
+	})
+	require.NoError(t, err)
+
+	meta, err := metadata.NewMetadataExtractor().ExtractMetadata("source/mcp/failover.md", content)
+	require.NoError(t, err)
+	assert.Equal(t, "Failover", meta.Title)
+	assert.Equal(t, "Runbook", meta.Category)
+	assert.Equal(t, []string{"db"}, meta.Tags)
+	assert.True(t, meta.Secret)
+
+	plain, err := renderSubmission(&pkgdomain.DocumentSubmission{Content: "# Notes"})
+	require.NoError(t, err)
+	assert.Equal(t, "# Notes", plain)
+
+	_, err = renderSubmission(&pkgdomain.DocumentSubmission{Content: "  "})
+	assert.Error(t, err)
+}
+
+func TestWriteDocumentChecksExistence(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "existing.md"), []byte("# Existing"), 0644))
+	w := &documentWriter{dir: dir}
+
+	_, err := w.WriteDocument(context.Background(), &pkgdomain.DocumentSubmission{Path: "existing.md", Content: "# New"}, false)
+	assert.ErrorContains(t, err, "already exists")
+
+	_, err = w.WriteDocument(context.Background(), &pkgdomain.DocumentSubmission{Path: "missing.md", Content: "# New"}, true)
+	assert.ErrorContains(t, err, "does not exist")
+}
+
+func TestWriteDocumentSeparatesTenants(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "acme"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "acme", "existing.md"), []byte("# Existing"), 0644))
+	w := &documentWriter{dir: dir}
+
+	_, err := w.WriteDocument(context.Background(), &pkgdomain.DocumentSubmission{Path: "existing.md", Content: "# New", Tenant: "acme"}, false)
+	assert.ErrorContains(t, err, "already exists")
+
+	_, err = w.WriteDocument(context.Background(), &pkgdomain.DocumentSubmission{Path: "existing.md", Content: "# New", Tenant: "beta"}, true)
+	assert.ErrorContains(t, err, "does not exist", "another tenant's file is not visible")
+
+	_, err = w.WriteDocument(context.Background(), &pkgdomain.DocumentSubmission{Path: "existing.md", Content: "# New", Tenant: "../acme"}, true)
+	assert.ErrorContains(t, err, "invalid tenant")
+}
+
+func TestCheckSharedGroups(t *testing.T) {
+	content := "---\nallowed_groups: [SRE, eng]\n---\n# Runbook"
+	access := writerAccess(&pkgdomain.DocumentAccess{Groups: []string{"eng", "sre"}})
+	assert.NoError(t, checkSharedGroups("runbook.md", content, access))
+	assert.NoError(t, checkSharedGroups("runbook.md", content, nil), "callers without restrictions may share with any group")
+
+	err := checkSharedGroups("runbook.md", content, writerAccess(&pkgdomain.DocumentAccess{Groups: []string{"eng"}}))
+	assert.ErrorContains(t, err, "sre is not one of them")
+	err = checkSharedGroups("runbook.md", content, writerAccess(&pkgdomain.DocumentAccess{}))
+	assert.Error(t, err, "callers without groups cannot restrict documents to groups")
+
+	err = checkSharedGroups("runbook.md", "---\nallowed_groups: []\n---\n# Runbook", nil)
+	assert.Error(t, err, "malformed allowed_groups fail before the file is written")
+}
+
+func TestWriterAccessMatchesReaders(t *testing.T) {
+	assert.Nil(t, writerAccess(nil))
+
+	access := writerAccess(&pkgdomain.DocumentAccess{ExcludeSecret: true, Tenants: []string{"acme"}, Groups: []string{"eng"}})
+	assert.True(t, access.Allows(false, "acme", []string{"eng"}))
+	assert.False(t, access.Allows(true, "acme", nil), "secret documents stay hidden from callers that cannot read them")
+	assert.False(t, access.Allows(false, "beta", nil))
+	assert.False(t, access.Allows(false, "acme", []string{"sre"}))
+}
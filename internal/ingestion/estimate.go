@@ -0,0 +1,143 @@
+package ingestion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	"github.com/ca-srg/ragent/internal/ingestion/vectorizer"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+// validateEstimateFlags validates the dry-run cost estimate flags
+func validateEstimateFlags() error {
+	switch estimateFormat {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("--estimate-format must be text or json, got %q", estimateFormat)
+	}
+
+	if dryRun {
+		return nil
+	}
+	if priceTablePath != "" {
+		return fmt.Errorf("--price-table requires --dry-run")
+	}
+	if estimateFormat == "json" {
+		return fmt.Errorf("--estimate-format json requires --dry-run")
+	}
+	return nil
+}
+
+// estimateAsJSON reports whether stdout is reserved for the JSON cost estimate
+func estimateAsJSON() bool {
+	return dryRun && estimateFormat == "json"
+}
+
+// loadPriceTable returns the price table given by --price-table, or the defaults
+func loadPriceTable() (*vectorizer.PriceTable, error) {
+	if priceTablePath == "" {
+		return vectorizer.DefaultPriceTable(), nil
+	}
+	table, err := vectorizer.LoadPriceTable(priceTablePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load price table: %w", err)
+	}
+	return table, nil
+}
+
+// cachedFiles returns the scanned files that change detection found unchanged
+func cachedFiles(allFiles []*pkgdomain.FileInfo, changeResult *hashstore.ChangeDetectionResult) []*pkgdomain.FileInfo {
+	if changeResult == nil || len(changeResult.Unchanged) == 0 {
+		return nil
+	}
+
+	unchanged := make(map[string]bool, len(changeResult.Unchanged))
+	for _, path := range changeResult.Unchanged {
+		unchanged[path] = true
+	}
+
+	var cached []*pkgdomain.FileInfo
+	for _, f := range allFiles {
+		if unchanged[f.Path] {
+			cached = append(cached, f)
+		}
+	}
+	return cached
+}
+
+// printCostEstimate writes the dry-run cost estimate as text or JSON
+func printCostEstimate(w io.Writer, estimate *vectorizer.CostEstimate, format string) error {
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(estimate); err != nil {
+			return fmt.Errorf("failed to encode cost estimate: %w", err)
+		}
+		return nil
+	}
+
+	_, _ = fmt.Fprintln(w, "\n"+strings.Repeat("=", 60))
+	_, _ = fmt.Fprintln(w, "DRY RUN COST ESTIMATE")
+	_, _ = fmt.Fprintln(w, strings.Repeat("=", 60))
+
+	_, _ = fmt.Fprintf(w, "Embedding Model:     %s (%s)\n", estimate.EmbeddingModel,
+		formatUnitPrice(estimate.EmbeddingPrice, estimate.Currency, "1K tokens"))
+	if estimate.OCRModel != "" {
+		_, _ = fmt.Fprintf(w, "OCR Model:           %s (%s)\n", estimate.OCRModel,
+			formatUnitPrice(estimate.OCRPrice, estimate.Currency, "page"))
+	}
+	_, _ = fmt.Fprintf(w, "Chunking:            %d tokens max, %d overlap\n",
+		estimate.ChunkMaxTokens, estimate.ChunkOverlapTokens)
+
+	_, _ = fmt.Fprintln(w, "\nTo Process:")
+	_, _ = fmt.Fprintln(w, strings.Repeat("-", 30))
+	printEstimateTotals(w, estimate.ToProcess, estimate.Currency)
+
+	_, _ = fmt.Fprintln(w, "\nCached (unchanged, skipped):")
+	_, _ = fmt.Fprintln(w, strings.Repeat("-", 30))
+	printEstimateTotals(w, estimate.Cached, estimate.Currency)
+
+	var skipped []vectorizer.FileEstimate
+	for _, fe := range estimate.Files {
+		if fe.Skipped != "" {
+			skipped = append(skipped, fe)
+		}
+	}
+	if len(skipped) > 0 {
+		_, _ = fmt.Fprintln(w, "\nNot estimated:")
+		for _, fe := range skipped {
+			_, _ = fmt.Fprintf(w, "  %s: %s\n", fe.Path, fe.Skipped)
+		}
+	}
+
+	if len(estimate.MissingPrices) > 0 {
+		_, _ = fmt.Fprintf(w, "\n⚠️  No price configured for %s; their cost is not included. Use --price-table to set it.\n",
+			strings.Join(estimate.MissingPrices, ", "))
+	}
+
+	_, _ = fmt.Fprintln(w, strings.Repeat("=", 60))
+	return nil
+}
+
+// printEstimateTotals writes one block of estimate totals
+func printEstimateTotals(w io.Writer, totals vectorizer.EstimateTotals, currency string) {
+	_, _ = fmt.Fprintf(w, "Files:               %d (%d PDF)\n", totals.Files, totals.PDFFiles)
+	_, _ = fmt.Fprintf(w, "Documents:           %d\n", totals.Documents)
+	_, _ = fmt.Fprintf(w, "Chunks:              %d\n", totals.Chunks)
+	_, _ = fmt.Fprintf(w, "Embedding Tokens:    %d\n", totals.EmbeddingTokens)
+	_, _ = fmt.Fprintf(w, "OCR Pages:           %d\n", totals.OCRPages)
+	_, _ = fmt.Fprintf(w, "Embedding Cost:      %s %.4f\n", currency, totals.EmbeddingCost)
+	_, _ = fmt.Fprintf(w, "OCR Cost:            %s %.4f\n", currency, totals.OCRCost)
+	_, _ = fmt.Fprintf(w, "Total Cost:          %s %.4f\n", currency, totals.TotalCost)
+}
+
+// formatUnitPrice formats a unit price, or notes that it is not configured
+func formatUnitPrice(price *float64, currency, unit string) string {
+	if price == nil {
+		return "no price configured"
+	}
+	return fmt.Sprintf("%s %g / %s", currency, *price, unit)
+}
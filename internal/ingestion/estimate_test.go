@@ -0,0 +1,91 @@
+package ingestion
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	"github.com/ca-srg/ragent/internal/ingestion/vectorizer"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+func TestValidateEstimateFlags(t *testing.T) {
+	t.Cleanup(func() {
+		dryRun = false
+		priceTablePath = ""
+		estimateFormat = ""
+	})
+
+	tests := []struct {
+		name    string
+		dryRun  bool
+		prices  string
+		format  string
+		wantErr bool
+	}{
+		{name: "defaults", format: "text"},
+		{name: "dry run json", dryRun: true, format: "json", prices: "prices.yaml"},
+		{name: "unknown format", dryRun: true, format: "csv", wantErr: true},
+		{name: "price table without dry run", prices: "prices.yaml", format: "text", wantErr: true},
+		{name: "json without dry run", format: "json", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dryRun = tt.dryRun
+			priceTablePath = tt.prices
+			estimateFormat = tt.format
+			err := validateEstimateFlags()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCachedFiles(t *testing.T) {
+	a := &pkgdomain.FileInfo{Path: "docs/a.md"}
+	b := &pkgdomain.FileInfo{Path: "docs/b.md"}
+
+	assert.Nil(t, cachedFiles([]*pkgdomain.FileInfo{a, b}, nil))
+	assert.Equal(t, []*pkgdomain.FileInfo{b}, cachedFiles([]*pkgdomain.FileInfo{a, b},
+		&hashstore.ChangeDetectionResult{Unchanged: []string{"docs/b.md"}}))
+}
+
+func TestPrintCostEstimate(t *testing.T) {
+	price := 0.00002
+	estimate := &vectorizer.CostEstimate{
+		Currency:       "USD",
+		EmbeddingModel: "amazon.titan-embed-text-v2:0",
+		EmbeddingPrice: &price,
+		ToProcess:      vectorizer.EstimateTotals{Files: 2, Documents: 2, Chunks: 3, EmbeddingTokens: 5000, EmbeddingCost: 0.0001, TotalCost: 0.0001},
+		Cached:         vectorizer.EstimateTotals{Files: 1, Documents: 1, Chunks: 1, EmbeddingTokens: 100},
+		Files: []vectorizer.FileEstimate{
+			{Path: "docs/a.md", Documents: 1, Chunks: 2},
+			{Path: "docs/rows.csv", Skipped: "no matching csv-config pattern"},
+		},
+		MissingPrices: []string{"ocr:test-ocr"},
+	}
+
+	var text bytes.Buffer
+	require.NoError(t, printCostEstimate(&text, estimate, "text"))
+	assert.Contains(t, text.String(), "DRY RUN COST ESTIMATE")
+	assert.Contains(t, text.String(), "Cached (unchanged, skipped):")
+	assert.Contains(t, text.String(), "Embedding Tokens:    5000")
+	assert.Contains(t, text.String(), "docs/rows.csv: no matching csv-config pattern")
+	assert.Contains(t, text.String(), "ocr:test-ocr")
+
+	var out bytes.Buffer
+	require.NoError(t, printCostEstimate(&out, estimate, "json"))
+	var decoded vectorizer.CostEstimate
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, estimate.ToProcess, decoded.ToProcess)
+	assert.Equal(t, estimate.Cached, decoded.Cached)
+	assert.Len(t, decoded.Files, 2)
+}
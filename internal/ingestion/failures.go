@@ -0,0 +1,381 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	"github.com/ca-srg/ragent/internal/ingestion/scanner"
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+// FailuresListOptions holds the flags of `vectorize failures list`
+type FailuresListOptions struct {
+	ErrorType string
+	Limit     int
+}
+
+// RetryOptions holds the flags of `vectorize retry`
+type RetryOptions struct {
+	ErrorType     string
+	Concurrency   int
+	CSVConfigPath string
+	OCRPromptFile string
+}
+
+// RunVectorizeFailuresList prints the failure ledger
+func RunVectorizeFailuresList(cmd *cobra.Command, opts FailuresListOptions) error {
+	store, err := hashstore.NewHashStore()
+	if err != nil {
+		return fmt.Errorf("failed to open hash store: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	failures, err := store.ListFailures(ctx, hashstore.FailureFilter{ErrorType: opts.ErrorType, Limit: opts.Limit})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nFound %d failed documents", len(failures))
+	if opts.ErrorType != "" {
+		fmt.Printf(" (type: %s)", opts.ErrorType)
+	}
+	fmt.Println(":")
+	if len(failures) == 0 {
+		fmt.Println("  (no failures recorded)")
+		return nil
+	}
+
+	for i, f := range failures {
+		backend := f.Backend
+		if backend == "" {
+			backend = "-"
+		}
+		fmt.Printf("  %d. %s\n", i+1, f.FilePath)
+		if f.SourcePath != f.FilePath {
+			fmt.Printf("     Source:    %s\n", f.SourcePath)
+		}
+		fmt.Printf("     Type:      %s (backend: %s, retryable: %t)\n", f.ErrorType, backend, f.Retryable)
+		fmt.Printf("     Attempts:  %d (first: %s, last: %s)\n", f.Attempts,
+			f.FirstFailedAt.Format(time.DateTime), f.LastFailedAt.Format(time.DateTime))
+		fmt.Printf("     Error:     %s\n", truncateString(f.Message, 200))
+	}
+	fmt.Println("\nRetry them with: ragent vectorize retry [--type <error type>]")
+
+	return nil
+}
+
+// RunVectorizeRetry re-vectorizes the source files of the failures in the ledger.
+// Whole source files are retried, so every row of a CSV file or page of a PDF is processed again.
+func RunVectorizeRetry(cmd *cobra.Command, opts RetryOptions) error {
+	cfg, err := appconfig.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := validateOpenSearchFlags(); err != nil {
+		return fmt.Errorf("flag validation failed: %w", err)
+	}
+	if opts.Concurrency > 0 {
+		cfg.Concurrency = opts.Concurrency
+	}
+	csvConfigPath = opts.CSVConfigPath
+	ocrPromptFile = opts.OCRPromptFile
+	if err := applyTenantScope(cfg, ""); err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	store, err := hashstore.NewHashStore()
+	if err != nil {
+		return fmt.Errorf("failed to open hash store: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	failures, err := store.ListFailures(ctx, hashstore.FailureFilter{ErrorType: opts.ErrorType})
+	if err != nil {
+		return err
+	}
+	if len(failures) == 0 {
+		fmt.Println("No failures to retry")
+		return nil
+	}
+
+	files := loadFailedSourceFiles(ctx, cfg, failures)
+	if len(files) == 0 {
+		return fmt.Errorf("none of the %d failed documents could be loaded from their sources", len(failures))
+	}
+	log.Printf("Retrying %d source file(s) for %d failed document(s)", len(files), len(failures))
+
+	service, csvCfg, err := createVectorizerServiceFromFlags(cfg, nil)
+	if err != nil {
+		return err
+	}
+	service.SetOCRCache(store)
+
+	log.Println("Validating configuration and service connections...")
+	validationCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := service.ValidateConfiguration(validationCtx); err != nil {
+		return fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	result, err := service.VectorizeFiles(ctx, files, false)
+	if err != nil {
+		return fmt.Errorf("vectorization failed: %w", err)
+	}
+	if result.SuccessCount > 0 {
+		updateHashStoreForSuccessfulFiles(ctx, store, files, result)
+	}
+	recordFailures(ctx, store, files, result)
+	if result.SuccessCount > 0 {
+		dualWriteMigrationCandidate(ctx, cfg, store, csvCfg, openSearchIndexName, files)
+	}
+
+	printResults(result, false)
+	return nil
+}
+
+// loadFailedSourceFiles reads the source files of failed documents from their local, S3 or GitHub source.
+// Sources that can no longer be read are logged and left in the ledger.
+func loadFailedSourceFiles(ctx context.Context, cfg *appconfig.Config, failures []*hashstore.FailureRecord) []*pkgdomain.FileInfo {
+	seen := make(map[string]bool)
+	var localPaths, s3Paths, githubPaths []string
+	for _, f := range failures {
+		if seen[f.SourcePath] {
+			continue
+		}
+		seen[f.SourcePath] = true
+		switch f.SourceType {
+		case "local":
+			localPaths = append(localPaths, f.SourcePath)
+		case "s3":
+			s3Paths = append(s3Paths, f.SourcePath)
+		case "github":
+			githubPaths = append(githubPaths, f.SourcePath)
+		default:
+			log.Printf("Warning: Cannot retry %s: unsupported source type %q", f.SourcePath, f.SourceType)
+		}
+	}
+
+	return loadSourceFiles(ctx, cfg, localPaths, s3Paths, githubPaths)
+}
+
+// loadSourceFiles reads source files from the local filesystem, S3 and GitHub.
+// Files that cannot be read are logged and left out.
+func loadSourceFiles(ctx context.Context, cfg *appconfig.Config, localPaths, s3Paths, githubPaths []string) []*pkgdomain.FileInfo {
+	var files []*pkgdomain.FileInfo
+
+	fileScanner := scanner.NewFileScanner()
+	for _, path := range localPaths {
+		f, err := loadLocalFile(fileScanner, path)
+		if err != nil {
+			log.Printf("Warning: Cannot retry %s: %v", path, err)
+			continue
+		}
+		files = append(files, f)
+	}
+
+	files = append(files, loadS3Files(ctx, resolveS3SourceRegion(cfg), s3Paths)...)
+	files = append(files, loadGitHubFiles(ctx, cfg, githubPaths)...)
+	return files
+}
+
+// loadS3Files downloads s3://bucket/key paths
+func loadS3Files(ctx context.Context, region string, paths []string) []*pkgdomain.FileInfo {
+	var files []*pkgdomain.FileInfo
+	scanners := make(map[string]*scanner.S3Scanner)
+	for _, path := range paths {
+		bucket, key, ok := strings.Cut(strings.TrimPrefix(path, "s3://"), "/")
+		if !ok || key == "" {
+			log.Printf("Warning: Cannot retry %s: invalid S3 path", path)
+			continue
+		}
+
+		s3Scanner, ok := scanners[bucket]
+		if !ok {
+			var err error
+			s3Scanner, err = scanner.NewS3Scanner(bucket, "", region)
+			if err != nil {
+				log.Printf("Warning: Cannot retry %s: failed to create S3 scanner: %v", path, err)
+				continue
+			}
+			scanners[bucket] = s3Scanner
+		}
+
+		data, err := s3Scanner.DownloadFileBytes(ctx, path)
+		if err != nil {
+			log.Printf("Warning: Cannot retry %s: %v", path, err)
+			continue
+		}
+
+		f := &pkgdomain.FileInfo{
+			Path:        path,
+			Name:        key[strings.LastIndex(key, "/")+1:],
+			Size:        int64(len(data)),
+			ModTime:     time.Now(),
+			IsMarkdown:  s3Scanner.IsMarkdownFile(key),
+			IsCSV:       s3Scanner.IsCSVFile(key),
+			IsPDF:       s3Scanner.IsPDFFile(key),
+			ContentHash: scanner.ComputeMD5Hash(string(data)),
+			SourceType:  "s3",
+		}
+		if f.IsPDF {
+			f.RawBytes = data
+		} else {
+			f.Content = string(data)
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// loadGitHubFiles reads github://owner/repo/path files from an up-to-date clone of their repository
+func loadGitHubFiles(ctx context.Context, cfg *appconfig.Config, paths []string) []*pkgdomain.FileInfo {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	changes := make(map[scanner.GitHubRepo][]scanner.GitFileChange)
+	var repos []scanner.GitHubRepo
+	for _, path := range paths {
+		parts := parseGitHubPath(path)
+		if parts == nil {
+			log.Printf("Warning: Cannot retry %s: invalid GitHub path", path)
+			continue
+		}
+		repo := scanner.GitHubRepo{Owner: parts.owner, Name: parts.repo}
+		if _, ok := changes[repo]; !ok {
+			repos = append(repos, repo)
+		}
+		changes[repo] = append(changes[repo], scanner.GitFileChange{
+			Status: scanner.GitStatusModified,
+			Path:   parts.relativePath,
+		})
+	}
+
+	cacheDir, err := resolveGitHubCacheDir(cfg)
+	if err != nil {
+		log.Printf("Warning: Failed to resolve GitHub cache directory, using temporary clones: %v", err)
+		cacheDir = ""
+	}
+	githubScanner := scanner.NewGitHubScannerWithCache(repos, cfg.GitHubToken, cacheDir)
+	defer githubScanner.Cleanup()
+
+	var files []*pkgdomain.FileInfo
+	for _, repo := range repos {
+		repoDir, err := githubScanner.SyncRepository(ctx, repo)
+		if err != nil {
+			log.Printf("Warning: Cannot retry files of %s: %v", repo.FullName(), err)
+			continue
+		}
+		repoFiles, _ := githubScanner.ScanChanges(repo, repoDir, changes[repo])
+		files = append(files, repoFiles...)
+	}
+
+	prepareGitHubFiles(files)
+	return files
+}
+
+// recordFailures writes the failed documents of a run to the failure ledger and
+// clears the entries of source files that were vectorized successfully this time.
+// Interrupted runs only record failures, since unprocessed files did not succeed.
+func recordFailures(ctx context.Context, store *hashstore.HashStore, files []*pkgdomain.FileInfo, result *pkgdomain.ProcessingResult) {
+	if store == nil || result == nil {
+		return
+	}
+	interrupted := ctx.Err() != nil
+
+	// The run context may already be cancelled, so write the ledger independently of it
+	ledgerCtx := context.Background()
+
+	// Only sources already in the ledger need clearing, which avoids a delete per file
+	ledger, err := store.ListFailures(ledgerCtx, hashstore.FailureFilter{})
+	if err != nil {
+		log.Printf("Warning: Failed to read failure ledger: %v", err)
+	}
+	previouslyFailed := make(map[string]bool, len(ledger))
+	for _, f := range ledger {
+		previouslyFailed[f.SourcePath] = true
+	}
+
+	sources := make(map[string]*pkgdomain.FileInfo, len(files))
+	for _, f := range files {
+		sources[f.Path] = f
+	}
+
+	failedSources := make(map[string]bool)
+	for _, procErr := range result.Errors {
+		sourcePath := procErr.SourcePath
+		if sourcePath == "" {
+			sourcePath = procErr.FilePath
+		}
+		failedSources[sourcePath] = true
+
+		record := &hashstore.FailureRecord{
+			SourceType:   failureSourceType(sources[sourcePath], sourcePath),
+			FilePath:     procErr.FilePath,
+			SourcePath:   sourcePath,
+			ErrorType:    string(procErr.Type),
+			Backend:      procErr.Backend,
+			Message:      procErr.Message,
+			Retryable:    procErr.Retryable,
+			LastFailedAt: procErr.Timestamp,
+		}
+		if err := store.RecordFailure(ledgerCtx, record); err != nil {
+			log.Printf("Warning: Failed to record failure of %s: %v", procErr.FilePath, err)
+		}
+	}
+	if len(result.Errors) > 0 {
+		log.Printf("Recorded %d failure(s); inspect them with: ragent vectorize failures list", len(result.Errors))
+	}
+
+	if interrupted {
+		return
+	}
+
+	cleared := int64(0)
+	for _, f := range files {
+		if failedSources[f.Path] || !previouslyFailed[f.Path] {
+			continue
+		}
+		n, err := store.ClearFailures(ledgerCtx, f.Path)
+		if err != nil {
+			log.Printf("Warning: Failed to clear failures of %s: %v", f.Path, err)
+			continue
+		}
+		cleared += n
+	}
+	if cleared > 0 {
+		log.Printf("Cleared %d resolved failure(s) from the ledger", cleared)
+	}
+}
+
+// failureSourceType returns the source type of a failed document's source file
+func failureSourceType(source *pkgdomain.FileInfo, sourcePath string) string {
+	if source != nil && source.SourceType != "" {
+		return source.SourceType
+	}
+	switch {
+	case scanner.IsS3Path(sourcePath):
+		return "s3"
+	case scanner.IsGitHubPath(sourcePath):
+		return "github"
+	default:
+		return "local"
+	}
+}
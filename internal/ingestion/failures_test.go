@@ -0,0 +1,90 @@
+package ingestion
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	pkgconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+func TestRecordFailures_RecordsAndClears(t *testing.T) {
+	ctx := context.Background()
+	store := newTestHashStore(t)
+
+	csvFile := &pkgdomain.FileInfo{Path: "data/rows.csv", IsCSV: true, SourceType: "local"}
+	s3File := &pkgdomain.FileInfo{Path: "s3://bucket/doc.md", SourceType: "s3"}
+	files := []*pkgdomain.FileInfo{csvFile, s3File}
+
+	recordFailures(ctx, store, files, &pkgdomain.ProcessingResult{
+		FailureCount: 2,
+		Errors: []pkgdomain.ProcessingError{
+			{
+				Type:       pkgconfig.ErrorTypeRateLimit,
+				Message:    "throttled",
+				FilePath:   "csv://data/rows.csv/row2",
+				SourcePath: "data/rows.csv",
+				Backend:    "OpenSearch",
+				Retryable:  true,
+			},
+			{
+				Type:     pkgconfig.ErrorTypeEmbedding,
+				Message:  "model error",
+				FilePath: "s3://bucket/doc.md",
+			},
+		},
+	})
+
+	failures, err := store.ListFailures(ctx, hashstore.FailureFilter{})
+	require.NoError(t, err)
+	require.Len(t, failures, 2)
+	bySource := map[string]*hashstore.FailureRecord{}
+	for _, f := range failures {
+		bySource[f.SourcePath] = f
+	}
+	require.Contains(t, bySource, "data/rows.csv")
+	assert.Equal(t, "local", bySource["data/rows.csv"].SourceType)
+	assert.Equal(t, "OpenSearch", bySource["data/rows.csv"].Backend)
+	assert.Equal(t, "rate_limit", bySource["data/rows.csv"].ErrorType)
+	require.Contains(t, bySource, "s3://bucket/doc.md")
+	assert.Equal(t, "s3", bySource["s3://bucket/doc.md"].SourceType)
+
+	// The CSV file succeeds on the next run while the S3 document fails again
+	recordFailures(ctx, store, files, &pkgdomain.ProcessingResult{
+		FailureCount: 1,
+		Errors: []pkgdomain.ProcessingError{
+			{Type: pkgconfig.ErrorTypeEmbedding, Message: "model error", FilePath: "s3://bucket/doc.md"},
+		},
+	})
+
+	failures, err = store.ListFailures(ctx, hashstore.FailureFilter{})
+	require.NoError(t, err)
+	require.Len(t, failures, 1)
+	assert.Equal(t, "s3://bucket/doc.md", failures[0].FilePath)
+	assert.Equal(t, 2, failures[0].Attempts)
+}
+
+func TestRecordFailures_InterruptedRunKeepsLedger(t *testing.T) {
+	store := newTestHashStore(t)
+	file := &pkgdomain.FileInfo{Path: "docs/a.md"}
+
+	recordFailures(context.Background(), store, []*pkgdomain.FileInfo{file}, &pkgdomain.ProcessingResult{
+		Errors: []pkgdomain.ProcessingError{
+			{Type: pkgconfig.ErrorTypeTimeout, Message: "timeout", FilePath: "docs/a.md"},
+		},
+	})
+
+	// An interrupted run did not process the file, so the failure must stay
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recordFailures(ctx, store, []*pkgdomain.FileInfo{file}, &pkgdomain.ProcessingResult{})
+
+	failures, err := store.ListFailures(context.Background(), hashstore.FailureFilter{})
+	require.NoError(t, err)
+	require.Len(t, failures, 1)
+	assert.Equal(t, "local", failures[0].SourceType)
+}
@@ -0,0 +1,135 @@
+package ingestion
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	"github.com/ca-srg/ragent/internal/ingestion/scanner"
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+// resolveGitHubCacheDir returns the clone cache directory with priority: GITHUB_CACHE_DIR > ~/.ragent/github
+func resolveGitHubCacheDir(cfg *appconfig.Config) (string, error) {
+	if cfg.GitHubCacheDir == "" {
+		return scanner.DefaultGitHubCacheDir()
+	}
+	if strings.HasPrefix(cfg.GitHubCacheDir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, cfg.GitHubCacheDir[2:]), nil
+	}
+	return cfg.GitHubCacheDir, nil
+}
+
+func hasIncrementalGitHubResult(results []*scanner.GitHubScanResult) bool {
+	for _, r := range results {
+		if r.Incremental() {
+			return true
+		}
+	}
+	return false
+}
+
+// applyGitHubDiffs corrects a change detection result for repositories that were
+// scanned incrementally. Their unchanged files were never read, so the detector
+// reports them as deleted; only the deletions and rename sources reported by the
+// git diff are kept, and the rest are counted as unchanged.
+func applyGitHubDiffs(changeResult *hashstore.ChangeDetectionResult, results []*scanner.GitHubScanResult) {
+	if changeResult == nil {
+		return
+	}
+
+	var prefixes []string
+	gitDeleted := make(map[string]bool)
+	for _, r := range results {
+		if !r.Incremental() {
+			continue
+		}
+		prefixes = append(prefixes, r.Repo.PathPrefix())
+		for _, path := range r.Deleted {
+			gitDeleted[path] = true
+		}
+	}
+	if len(prefixes) == 0 {
+		return
+	}
+
+	deleted := make([]string, 0, len(changeResult.Deleted))
+	for _, path := range changeResult.Deleted {
+		if gitDeleted[path] || !hasAnyPrefix(path, prefixes) {
+			deleted = append(deleted, path)
+			continue
+		}
+		changeResult.Unchanged = append(changeResult.Unchanged, path)
+		changeResult.UnchangeCount++
+	}
+	changeResult.Deleted = deleted
+	changeResult.DeleteCount = len(deleted)
+}
+
+// recordGitHubCommits stores the HEAD commit of each scanned repository so the next
+// run can diff against it. A repository is skipped when any of its files failed,
+// so those files are picked up again on the next run.
+func recordGitHubCommits(
+	ctx context.Context,
+	store *hashstore.HashStore,
+	results []*scanner.GitHubScanResult,
+	result *pkgdomain.ProcessingResult,
+) {
+	if store == nil || len(results) == 0 {
+		return
+	}
+
+	var failedPaths []string
+	if result != nil {
+		for _, procErr := range result.Errors {
+			failedPaths = append(failedPaths, procErr.FilePath)
+		}
+	}
+
+	for _, r := range results {
+		if r.HeadCommit == "" {
+			continue
+		}
+		if hasPathUnder(failedPaths, r.Repo.PathPrefix()) {
+			log.Printf("Not advancing indexed commit for %s: some files failed", r.Repo.FullName())
+			continue
+		}
+
+		record := &hashstore.RepositoryCommitRecord{
+			Repository: r.Repo.FullName(),
+			Branch:     r.Branch,
+			CommitSHA:  r.HeadCommit,
+			IndexedAt:  time.Now(),
+		}
+		if err := store.UpsertLastIndexedCommit(ctx, record); err != nil {
+			log.Printf("Warning: Failed to record indexed commit for %s: %v", r.Repo.FullName(), err)
+		}
+	}
+}
+
+func hasAnyPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPathUnder(paths []string, prefix string) bool {
+	for _, path := range paths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
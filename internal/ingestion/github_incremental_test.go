@@ -0,0 +1,105 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	"github.com/ca-srg/ragent/internal/ingestion/scanner"
+)
+
+func TestApplyGitHubDiffs(t *testing.T) {
+	changeResult := &hashstore.ChangeDetectionResult{
+		Deleted: []string{
+			"/local/removed.md",
+			"github://org/full/removed.md",
+			"github://org/inc/unchanged.md",
+			"github://org/inc/removed.md",
+		},
+		DeleteCount: 4,
+	}
+
+	results := []*scanner.GitHubScanResult{
+		{Repo: scanner.GitHubRepo{Owner: "org", Name: "full"}},
+		{
+			Repo:       scanner.GitHubRepo{Owner: "org", Name: "inc"},
+			BaseCommit: "abc123",
+			Deleted:    []string{"github://org/inc/removed.md"},
+		},
+	}
+
+	applyGitHubDiffs(changeResult, results)
+
+	assert.ElementsMatch(t, []string{
+		"/local/removed.md",
+		"github://org/full/removed.md",
+		"github://org/inc/removed.md",
+	}, changeResult.Deleted)
+	assert.Equal(t, 3, changeResult.DeleteCount)
+	assert.Equal(t, []string{"github://org/inc/unchanged.md"}, changeResult.Unchanged)
+	assert.Equal(t, 1, changeResult.UnchangeCount)
+}
+
+func TestApplyGitHubDiffs_NoIncrementalResults(t *testing.T) {
+	changeResult := &hashstore.ChangeDetectionResult{
+		Deleted:     []string{"github://org/full/removed.md"},
+		DeleteCount: 1,
+	}
+
+	applyGitHubDiffs(changeResult, []*scanner.GitHubScanResult{
+		{Repo: scanner.GitHubRepo{Owner: "org", Name: "full"}},
+	})
+
+	assert.Equal(t, []string{"github://org/full/removed.md"}, changeResult.Deleted)
+	assert.Equal(t, 1, changeResult.DeleteCount)
+}
+
+func TestPruneDeletedFilesRemovesDocumentsOfRenamedAndDeletedFiles(t *testing.T) {
+	ctx := context.Background()
+	prev := pruneDeleted
+	pruneDeleted = true
+	t.Cleanup(func() { pruneDeleted = prev })
+
+	store := newTestHashStore(t)
+	for _, path := range []string{"github://org/inc/old-name.md", "github://org/inc/removed.md", "github://org/inc/kept.md"} {
+		require.NoError(t, store.UpsertFileHash(ctx, &hashstore.FileHashRecord{SourceType: "github", FilePath: path, ContentHash: "h"}))
+	}
+
+	// old-name.md was renamed to new-name.md and removed.md was deleted in the commit diff
+	newResult := func() *hashstore.ChangeDetectionResult {
+		changeResult := &hashstore.ChangeDetectionResult{
+			Deleted: []string{"github://org/inc/old-name.md", "github://org/inc/removed.md", "github://org/inc/kept.md"},
+		}
+		applyGitHubDiffs(changeResult, []*scanner.GitHubScanResult{{
+			Repo:       scanner.GitHubRepo{Owner: "org", Name: "inc"},
+			BaseCommit: "abc123",
+			Deleted:    []string{"github://org/inc/old-name.md", "github://org/inc/removed.md"},
+		}})
+		return changeResult
+	}
+
+	failing := func(ctx context.Context, sources []string) error { return errors.New("vector store unavailable") }
+	pruneDeletedFiles(ctx, store, newResult(), failing)
+	record, err := store.GetFileHash(ctx, "github", "github://org/inc/removed.md")
+	require.NoError(t, err)
+	assert.NotNil(t, record, "hash records are kept when the documents could not be deleted, so the next run retries")
+
+	var removed []string
+	pruneDeletedFiles(ctx, store, newResult(), func(ctx context.Context, sources []string) error {
+		removed = append(removed, sources...)
+		return nil
+	})
+	assert.ElementsMatch(t, []string{"github://org/inc/old-name.md", "github://org/inc/removed.md"}, removed)
+	for _, path := range removed {
+		record, err := store.GetFileHash(ctx, "github", path)
+		require.NoError(t, err)
+		assert.Nil(t, record)
+	}
+	record, err = store.GetFileHash(ctx, "github", "github://org/inc/kept.md")
+	require.NoError(t, err)
+	assert.NotNil(t, record, "files outside the commit diff are not pruned")
+}
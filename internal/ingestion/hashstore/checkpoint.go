@@ -0,0 +1,268 @@
+package hashstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migrateCheckpoints creates the tables used for resumable runs and the OCR result cache
+func (s *HashStore) migrateCheckpoints() error {
+	createRunsTableSQL := `
+		CREATE TABLE IF NOT EXISTS vectorize_runs (
+			run_id TEXT PRIMARY KEY,
+			sources TEXT NOT NULL,
+			status TEXT NOT NULL,
+			started_at DATETIME NOT NULL,
+			finished_at DATETIME
+		);
+	`
+	if _, err := s.db.Exec(createRunsTableSQL); err != nil {
+		return fmt.Errorf("failed to create vectorize_runs table: %w", err)
+	}
+
+	createCheckpointsTableSQL := `
+		CREATE TABLE IF NOT EXISTS run_checkpoints (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id TEXT NOT NULL,
+			document_path TEXT NOT NULL,
+			content_hash TEXT NOT NULL DEFAULT '',
+			completed_at DATETIME NOT NULL,
+			UNIQUE(run_id, document_path)
+		);
+	`
+	if _, err := s.db.Exec(createCheckpointsTableSQL); err != nil {
+		return fmt.Errorf("failed to create run_checkpoints table: %w", err)
+	}
+
+	// Checkpoints written before content hashes were recorded get an empty hash, so the
+	// documents they cover are processed again instead of being trusted blindly
+	var hasContentHash int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('run_checkpoints') WHERE name = 'content_hash'`).Scan(&hasContentHash); err != nil {
+		return fmt.Errorf("failed to inspect run_checkpoints table: %w", err)
+	}
+	if hasContentHash == 0 {
+		if _, err := s.db.Exec(`ALTER TABLE run_checkpoints ADD COLUMN content_hash TEXT NOT NULL DEFAULT ''`); err != nil {
+			return fmt.Errorf("failed to add content_hash to run_checkpoints: %w", err)
+		}
+	}
+
+	createOCRCacheTableSQL := `
+		CREATE TABLE IF NOT EXISTS ocr_cache (
+			cache_key TEXT PRIMARY KEY,
+			result BLOB NOT NULL,
+			created_at DATETIME NOT NULL
+		);
+	`
+	if _, err := s.db.Exec(createOCRCacheTableSQL); err != nil {
+		return fmt.Errorf("failed to create ocr_cache table: %w", err)
+	}
+
+	return nil
+}
+
+// CreateRun records the start of a new vectorize run
+func (s *HashStore) CreateRun(ctx context.Context, runID, sources string) error {
+	insertSQL := `
+		INSERT INTO vectorize_runs (run_id, sources, status, started_at)
+		VALUES (?, ?, ?, ?)
+	`
+	startedAt := time.Now().Format("2006-01-02 15:04:05")
+	if _, err := s.db.ExecContext(ctx, insertSQL, runID, sources, string(RunStatusRunning), startedAt); err != nil {
+		return fmt.Errorf("failed to create run: %w", err)
+	}
+	return nil
+}
+
+// GetRun retrieves a run by ID. Returns nil if the run does not exist.
+func (s *HashStore) GetRun(ctx context.Context, runID string) (*VectorizeRunRecord, error) {
+	query := `
+		SELECT run_id, sources, status, started_at, finished_at
+		FROM vectorize_runs
+		WHERE run_id = ?
+	`
+	return s.scanRun(s.db.QueryRowContext(ctx, query, runID))
+}
+
+// FindResumableRun returns the most recent unfinished run for the given sources.
+// Returns nil if there is nothing to resume.
+func (s *HashStore) FindResumableRun(ctx context.Context, sources string) (*VectorizeRunRecord, error) {
+	query := `
+		SELECT run_id, sources, status, started_at, finished_at
+		FROM vectorize_runs
+		WHERE sources = ? AND status IN (?, ?, ?)
+		ORDER BY started_at DESC, rowid DESC
+		LIMIT 1
+	`
+	return s.scanRun(s.db.QueryRowContext(ctx, query, sources,
+		string(RunStatusRunning), string(RunStatusFailed), string(RunStatusInterrupted)))
+}
+
+// ResumeRun marks an unfinished run as running again
+func (s *HashStore) ResumeRun(ctx context.Context, runID string) error {
+	updateSQL := `UPDATE vectorize_runs SET status = ?, finished_at = NULL WHERE run_id = ?`
+	if _, err := s.db.ExecContext(ctx, updateSQL, string(RunStatusRunning), runID); err != nil {
+		return fmt.Errorf("failed to resume run: %w", err)
+	}
+	return nil
+}
+
+// SupersedeRuns marks every unfinished run for the given sources, other than exceptRunID,
+// as superseded and discards their checkpoints
+func (s *HashStore) SupersedeRuns(ctx context.Context, sources, exceptRunID string) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	deleteSQL := `
+		DELETE FROM run_checkpoints WHERE run_id IN (
+			SELECT run_id FROM vectorize_runs
+			WHERE sources = ? AND run_id != ? AND status IN (?, ?, ?)
+		)
+	`
+	if _, err := tx.ExecContext(ctx, deleteSQL, sources, exceptRunID,
+		string(RunStatusRunning), string(RunStatusFailed), string(RunStatusInterrupted)); err != nil {
+		return 0, fmt.Errorf("failed to delete superseded checkpoints: %w", err)
+	}
+
+	updateSQL := `
+		UPDATE vectorize_runs SET status = ?, finished_at = ?
+		WHERE sources = ? AND run_id != ? AND status IN (?, ?, ?)
+	`
+	result, err := tx.ExecContext(ctx, updateSQL,
+		string(RunStatusSuperseded), time.Now().Format("2006-01-02 15:04:05"),
+		sources, exceptRunID,
+		string(RunStatusRunning), string(RunStatusFailed), string(RunStatusInterrupted))
+	if err != nil {
+		return 0, fmt.Errorf("failed to supersede runs: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// FinishRun records the final status of a run.
+// Checkpoints of completed runs are discarded since there is nothing left to resume.
+func (s *HashStore) FinishRun(ctx context.Context, runID string, status RunStatus) error {
+	updateSQL := `UPDATE vectorize_runs SET status = ?, finished_at = ? WHERE run_id = ?`
+	finishedAt := time.Now().Format("2006-01-02 15:04:05")
+	if _, err := s.db.ExecContext(ctx, updateSQL, string(status), finishedAt, runID); err != nil {
+		return fmt.Errorf("failed to finish run: %w", err)
+	}
+
+	if status == RunStatusCompleted {
+		deleteSQL := `DELETE FROM run_checkpoints WHERE run_id = ?`
+		if _, err := s.db.ExecContext(ctx, deleteSQL, runID); err != nil {
+			return fmt.Errorf("failed to delete run checkpoints: %w", err)
+		}
+	}
+	return nil
+}
+
+// MarkDocumentCompleted checkpoints a document whose backend writes all succeeded, together
+// with the content hash of its source file at the time it was processed
+func (s *HashStore) MarkDocumentCompleted(ctx context.Context, runID, documentPath, contentHash string) error {
+	insertSQL := `
+		INSERT INTO run_checkpoints (run_id, document_path, content_hash, completed_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(run_id, document_path) DO UPDATE SET
+			content_hash = excluded.content_hash,
+			completed_at = excluded.completed_at;
+	`
+	completedAt := time.Now().Format("2006-01-02 15:04:05")
+	if _, err := s.db.ExecContext(ctx, insertSQL, runID, documentPath, contentHash, completedAt); err != nil {
+		return fmt.Errorf("failed to mark document completed: %w", err)
+	}
+	return nil
+}
+
+// GetCompletedDocuments returns the document paths checkpointed for a run with the content
+// hashes they were processed at
+func (s *HashStore) GetCompletedDocuments(ctx context.Context, runID string) (map[string]string, error) {
+	query := `SELECT document_path, content_hash FROM run_checkpoints WHERE run_id = ?`
+	rows, err := s.db.QueryContext(ctx, query, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query run checkpoints: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	result := make(map[string]string)
+	for rows.Next() {
+		var path, contentHash string
+		if err := rows.Scan(&path, &contentHash); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		result[path] = contentHash
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetOCRResult returns a cached OCR result by key
+func (s *HashStore) GetOCRResult(ctx context.Context, key string) ([]byte, bool, error) {
+	query := `SELECT result FROM ocr_cache WHERE cache_key = ?`
+	var data []byte
+	if err := s.db.QueryRowContext(ctx, query, key).Scan(&data); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to get OCR result: %w", err)
+	}
+	return data, true, nil
+}
+
+// PutOCRResult caches an OCR result by key
+func (s *HashStore) PutOCRResult(ctx context.Context, key string, data []byte) error {
+	upsertSQL := `
+		INSERT INTO ocr_cache (cache_key, result, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(cache_key) DO UPDATE SET
+			result = excluded.result,
+			created_at = excluded.created_at;
+	`
+	createdAt := time.Now().Format("2006-01-02 15:04:05")
+	if _, err := s.db.ExecContext(ctx, upsertSQL, key, data, createdAt); err != nil {
+		return fmt.Errorf("failed to put OCR result: %w", err)
+	}
+	return nil
+}
+
+// scanRun scans a vectorize_runs row. Returns nil if there are no rows.
+func (s *HashStore) scanRun(row *sql.Row) (*VectorizeRunRecord, error) {
+	var record VectorizeRunRecord
+	var status, startedAt string
+	var finishedAt sql.NullString
+	err := row.Scan(&record.RunID, &record.Sources, &status, &startedAt, &finishedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not found
+		}
+		return nil, fmt.Errorf("failed to get run: %w", err)
+	}
+
+	record.Status = RunStatus(status)
+	record.StartedAt = parseStoredTime(startedAt)
+	if finishedAt.Valid {
+		record.FinishedAt = parseStoredTime(finishedAt.String)
+	}
+
+	return &record, nil
+}
+
+// parseStoredTime parses a timestamp written by this store
+func parseStoredTime(value string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04:05", value)
+	if err != nil {
+		t, _ = time.Parse(time.RFC3339, value)
+	}
+	return t
+}
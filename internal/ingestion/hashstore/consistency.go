@@ -0,0 +1,73 @@
+package hashstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+// maxConsistencyReports is the number of consistency reports kept in the hash store
+const maxConsistencyReports = 50
+
+// migrateConsistencyReports creates the consistency_reports table that stores `verify` results
+func (s *HashStore) migrateConsistencyReports() error {
+	createTableSQL := `
+		CREATE TABLE IF NOT EXISTS consistency_reports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			index_name TEXT NOT NULL,
+			issue_count INTEGER NOT NULL,
+			report TEXT NOT NULL,
+			checked_at DATETIME NOT NULL
+		);
+	`
+	if _, err := s.db.Exec(createTableSQL); err != nil {
+		return fmt.Errorf("failed to create consistency_reports table: %w", err)
+	}
+	return nil
+}
+
+// SaveConsistencyReport stores the result of a consistency check and drops the oldest reports
+func (s *HashStore) SaveConsistencyReport(ctx context.Context, report *pkgdomain.ConsistencyReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal consistency report: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO consistency_reports (index_name, issue_count, report, checked_at)
+		VALUES (?, ?, ?, ?)
+	`, report.Index, len(report.Issues), string(data), report.CheckedAt.Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return fmt.Errorf("failed to save consistency report: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		DELETE FROM consistency_reports
+		WHERE id NOT IN (SELECT id FROM consistency_reports ORDER BY id DESC LIMIT ?)
+	`, maxConsistencyReports)
+	if err != nil {
+		return fmt.Errorf("failed to prune consistency reports: %w", err)
+	}
+	return nil
+}
+
+// GetLatestConsistencyReport returns the most recent consistency report. Returns nil if there is none.
+func (s *HashStore) GetLatestConsistencyReport(ctx context.Context) (*pkgdomain.ConsistencyReport, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, `SELECT report FROM consistency_reports ORDER BY id DESC LIMIT 1`).Scan(&data)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not found
+		}
+		return nil, fmt.Errorf("failed to get consistency report: %w", err)
+	}
+
+	var report pkgdomain.ConsistencyReport
+	if err := json.Unmarshal([]byte(data), &report); err != nil {
+		return nil, fmt.Errorf("failed to decode consistency report: %w", err)
+	}
+	return &report, nil
+}
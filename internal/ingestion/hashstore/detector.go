@@ -114,3 +114,31 @@ func (d *ChangeDetector) FilterFilesToProcess(
 
 	return filtered, changes, nil
 }
+
+// FilterChangedFiles returns the files whose content differs from the stored hash.
+// Unlike FilterFilesToProcess it only looks at the given files, so it does not
+// report deletions; this suits partial scans such as file system watch events.
+func (d *ChangeDetector) FilterChangedFiles(
+	ctx context.Context,
+	files []*pkgdomain.FileInfo,
+) ([]*pkgdomain.FileInfo, error) {
+	filtered := make([]*pkgdomain.FileInfo, 0, len(files))
+
+	for _, file := range files {
+		sourceType := file.SourceType
+		if sourceType == "" {
+			sourceType = "local"
+		}
+
+		existingRecord, err := d.store.GetFileHash(ctx, sourceType, file.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		if existingRecord == nil || existingRecord.ContentHash != file.ContentHash {
+			filtered = append(filtered, file)
+		}
+	}
+
+	return filtered, nil
+}
@@ -301,3 +301,36 @@ func TestChangeDetector_FilterFilesToProcess_MixedSourceTypes(t *testing.T) {
 	assert.Equal(t, 2, changes.NewCount)
 	assert.Equal(t, 2, changes.UnchangeCount)
 }
+
+func TestChangeDetector_FilterChangedFiles(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	existingRecords := []*FileHashRecord{
+		{SourceType: "local", FilePath: "/path/unchanged.md", ContentHash: "hash1", FileSize: 100, VectorizedAt: time.Now()},
+		{SourceType: "local", FilePath: "/path/modified.md", ContentHash: "oldHash", FileSize: 200, VectorizedAt: time.Now()},
+		{SourceType: "local", FilePath: "/path/not-in-batch.md", ContentHash: "hash3", FileSize: 300, VectorizedAt: time.Now()},
+	}
+	for _, r := range existingRecords {
+		err := store.UpsertFileHash(ctx, r)
+		require.NoError(t, err)
+	}
+
+	detector := NewChangeDetector(store)
+
+	files := []*pkgdomain.FileInfo{
+		{Path: "/path/unchanged.md", ContentHash: "hash1"},
+		{Path: "/path/modified.md", ContentHash: "newHash"},
+		{Path: "/path/new.md", ContentHash: "hashNew"},
+	}
+
+	filtered, err := detector.FilterChangedFiles(ctx, files)
+	require.NoError(t, err)
+
+	// Files outside the batch are neither processed nor treated as deleted
+	require.Len(t, filtered, 2)
+	assert.Equal(t, "/path/modified.md", filtered[0].Path)
+	assert.Equal(t, "/path/new.md", filtered[1].Path)
+}
@@ -0,0 +1,154 @@
+package hashstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// migrateFailures creates the failed_files table used as a dead-letter ledger
+func (s *HashStore) migrateFailures() error {
+	createTableSQL := `
+		CREATE TABLE IF NOT EXISTS failed_files (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			source_type TEXT NOT NULL,
+			file_path TEXT NOT NULL,
+			source_path TEXT NOT NULL,
+			error_type TEXT NOT NULL,
+			backend TEXT NOT NULL,
+			message TEXT NOT NULL,
+			retryable INTEGER NOT NULL,
+			attempts INTEGER NOT NULL,
+			first_failed_at DATETIME NOT NULL,
+			last_failed_at DATETIME NOT NULL,
+			UNIQUE(file_path, backend)
+		);
+	`
+	if _, err := s.db.Exec(createTableSQL); err != nil {
+		return fmt.Errorf("failed to create failed_files table: %w", err)
+	}
+
+	createIndexSQL := `
+		CREATE INDEX IF NOT EXISTS idx_failed_files_source_path
+		ON failed_files(source_path);
+	`
+	if _, err := s.db.Exec(createIndexSQL); err != nil {
+		return fmt.Errorf("failed to create failed_files index: %w", err)
+	}
+
+	return nil
+}
+
+// RecordFailure adds a failure to the ledger.
+// Repeated failures of the same document and backend increment the attempt count.
+func (s *HashStore) RecordFailure(ctx context.Context, record *FailureRecord) error {
+	upsertSQL := `
+		INSERT INTO failed_files (
+			source_type, file_path, source_path, error_type, backend, message,
+			retryable, attempts, first_failed_at, last_failed_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 1, ?, ?)
+		ON CONFLICT(file_path, backend) DO UPDATE SET
+			source_type = excluded.source_type,
+			source_path = excluded.source_path,
+			error_type = excluded.error_type,
+			message = excluded.message,
+			retryable = excluded.retryable,
+			attempts = failed_files.attempts + 1,
+			last_failed_at = excluded.last_failed_at;
+	`
+	failedAt := record.LastFailedAt
+	if failedAt.IsZero() {
+		failedAt = time.Now()
+	}
+	timestamp := failedAt.Format("2006-01-02 15:04:05")
+
+	sourcePath := record.SourcePath
+	if sourcePath == "" {
+		sourcePath = record.FilePath
+	}
+
+	_, err := s.db.ExecContext(ctx, upsertSQL,
+		record.SourceType,
+		record.FilePath,
+		sourcePath,
+		record.ErrorType,
+		record.Backend,
+		record.Message,
+		record.Retryable,
+		timestamp,
+		timestamp,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record failure: %w", err)
+	}
+
+	return nil
+}
+
+// ListFailures returns failures ordered by the most recent failure first
+func (s *HashStore) ListFailures(ctx context.Context, filter FailureFilter) ([]*FailureRecord, error) {
+	query := `
+		SELECT id, source_type, file_path, source_path, error_type, backend, message,
+			retryable, attempts, first_failed_at, last_failed_at
+		FROM failed_files
+	`
+	var args []any
+	if filter.ErrorType != "" {
+		query += ` WHERE error_type = ?`
+		args = append(args, filter.ErrorType)
+	}
+	query += ` ORDER BY last_failed_at DESC, id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query failures: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var result []*FailureRecord
+	for rows.Next() {
+		var record FailureRecord
+		var firstFailedAt, lastFailedAt string
+		err := rows.Scan(
+			&record.ID,
+			&record.SourceType,
+			&record.FilePath,
+			&record.SourcePath,
+			&record.ErrorType,
+			&record.Backend,
+			&record.Message,
+			&record.Retryable,
+			&record.Attempts,
+			&firstFailedAt,
+			&lastFailedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		record.FirstFailedAt = parseStoredTime(firstFailedAt)
+		record.LastFailedAt = parseStoredTime(lastFailedAt)
+		result = append(result, &record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return result, nil
+}
+
+// ClearFailures removes all failures recorded for documents read from sourcePath
+func (s *HashStore) ClearFailures(ctx context.Context, sourcePath string) (int64, error) {
+	deleteSQL := `DELETE FROM failed_files WHERE source_path = ? OR file_path = ?`
+	result, err := s.db.ExecContext(ctx, deleteSQL, sourcePath, sourcePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear failures: %w", err)
+	}
+	return result.RowsAffected()
+}
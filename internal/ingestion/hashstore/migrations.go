@@ -0,0 +1,166 @@
+package hashstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migrateModelMigrations creates the model_migrations table tracking embedding model migrations
+func (s *HashStore) migrateModelMigrations() error {
+	createTableSQL := `
+		CREATE TABLE IF NOT EXISTS model_migrations (
+			alias TEXT PRIMARY KEY,
+			candidate_index TEXT NOT NULL,
+			provider TEXT NOT NULL,
+			model TEXT NOT NULL,
+			dimension INTEGER NOT NULL,
+			started_at DATETIME NOT NULL
+		);
+	`
+	if _, err := s.db.Exec(createTableSQL); err != nil {
+		return fmt.Errorf("failed to create model_migrations table: %w", err)
+	}
+
+	createFailuresTableSQL := `
+		CREATE TABLE IF NOT EXISTS model_migration_failures (
+			alias TEXT NOT NULL,
+			source_path TEXT NOT NULL,
+			error_message TEXT NOT NULL,
+			failed_at DATETIME NOT NULL,
+			PRIMARY KEY (alias, source_path)
+		);
+	`
+	if _, err := s.db.Exec(createFailuresTableSQL); err != nil {
+		return fmt.Errorf("failed to create model_migration_failures table: %w", err)
+	}
+	return nil
+}
+
+// StartModelMigration records a migration for an alias, replacing any previous record
+func (s *HashStore) StartModelMigration(ctx context.Context, record *ModelMigrationRecord) error {
+	upsertSQL := `
+		INSERT INTO model_migrations (alias, candidate_index, provider, model, dimension, started_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(alias) DO UPDATE SET
+			candidate_index = excluded.candidate_index,
+			provider = excluded.provider,
+			model = excluded.model,
+			dimension = excluded.dimension,
+			started_at = excluded.started_at;
+	`
+	startedAt := record.StartedAt
+	if startedAt.IsZero() {
+		startedAt = time.Now()
+	}
+
+	_, err := s.db.ExecContext(ctx, upsertSQL,
+		record.Alias,
+		record.CandidateIndex,
+		record.Provider,
+		record.Model,
+		record.Dimension,
+		startedAt.Format("2006-01-02 15:04:05"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record model migration: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM model_migration_failures WHERE alias = ?`, record.Alias); err != nil {
+		return fmt.Errorf("failed to reset model migration failures: %w", err)
+	}
+	return nil
+}
+
+// GetModelMigration returns the migration in progress for an alias.
+// Returns nil if there is none.
+func (s *HashStore) GetModelMigration(ctx context.Context, alias string) (*ModelMigrationRecord, error) {
+	query := `
+		SELECT alias, candidate_index, provider, model, dimension, started_at
+		FROM model_migrations
+		WHERE alias = ?
+	`
+	var record ModelMigrationRecord
+	var startedAt string
+	err := s.db.QueryRowContext(ctx, query, alias).Scan(
+		&record.Alias,
+		&record.CandidateIndex,
+		&record.Provider,
+		&record.Model,
+		&record.Dimension,
+		&startedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not found
+		}
+		return nil, fmt.Errorf("failed to get model migration: %w", err)
+	}
+	record.StartedAt = parseStoredTime(startedAt)
+	return &record, nil
+}
+
+// FinishModelMigration removes the migration record of an alias and its failures
+func (s *HashStore) FinishModelMigration(ctx context.Context, alias string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM model_migrations WHERE alias = ?`, alias); err != nil {
+		return fmt.Errorf("failed to finish model migration: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM model_migration_failures WHERE alias = ?`, alias); err != nil {
+		return fmt.Errorf("failed to clear model migration failures: %w", err)
+	}
+	return nil
+}
+
+// RecordModelMigrationFailure records a source document that could not be written into the
+// candidate index of a migration
+func (s *HashStore) RecordModelMigrationFailure(ctx context.Context, alias, sourcePath, message string) error {
+	upsertSQL := `
+		INSERT INTO model_migration_failures (alias, source_path, error_message, failed_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(alias, source_path) DO UPDATE SET
+			error_message = excluded.error_message,
+			failed_at = excluded.failed_at;
+	`
+	_, err := s.db.ExecContext(ctx, upsertSQL, alias, sourcePath, message, time.Now().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return fmt.Errorf("failed to record model migration failure: %w", err)
+	}
+	return nil
+}
+
+// ClearModelMigrationFailure removes the failure of a source document once it was written
+// into the candidate index
+func (s *HashStore) ClearModelMigrationFailure(ctx context.Context, alias, sourcePath string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM model_migration_failures WHERE alias = ? AND source_path = ?`, alias, sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to clear model migration failure: %w", err)
+	}
+	return nil
+}
+
+// ListModelMigrationFailures returns the documents missing from the candidate index of a migration
+func (s *HashStore) ListModelMigrationFailures(ctx context.Context, alias string) ([]*ModelMigrationFailure, error) {
+	query := `
+		SELECT alias, source_path, error_message, failed_at
+		FROM model_migration_failures
+		WHERE alias = ?
+		ORDER BY source_path
+	`
+	rows, err := s.db.QueryContext(ctx, query, alias)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list model migration failures: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var failures []*ModelMigrationFailure
+	for rows.Next() {
+		var failure ModelMigrationFailure
+		var failedAt string
+		if err := rows.Scan(&failure.Alias, &failure.SourcePath, &failure.Error, &failedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan model migration failure: %w", err)
+		}
+		failure.FailedAt = parseStoredTime(failedAt)
+		failures = append(failures, &failure)
+	}
+	return failures, rows.Err()
+}
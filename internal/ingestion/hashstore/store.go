@@ -60,7 +60,7 @@ func NewHashStoreWithDB(db *sql.DB) (*HashStore, error) {
 	return store, nil
 }
 
-// migrate creates the file_hashes table if it doesn't exist
+// migrate creates the file_hashes, repository_commits, checkpoint, failure, model migration, transfer and consistency report tables if they don't exist
 func (s *HashStore) migrate() error {
 	createTableSQL := `
 		CREATE TABLE IF NOT EXISTS file_hashes (
@@ -86,7 +86,38 @@ func (s *HashStore) migrate() error {
 		return fmt.Errorf("failed to create index: %w", err)
 	}
 
-	return nil
+	createCommitsTableSQL := `
+		CREATE TABLE IF NOT EXISTS repository_commits (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			repository TEXT NOT NULL,
+			branch TEXT NOT NULL,
+			commit_sha TEXT NOT NULL,
+			indexed_at DATETIME NOT NULL,
+			UNIQUE(repository, branch)
+		);
+	`
+
+	if _, err := s.db.Exec(createCommitsTableSQL); err != nil {
+		return fmt.Errorf("failed to create repository_commits table: %w", err)
+	}
+
+	if err := s.migrateCheckpoints(); err != nil {
+		return err
+	}
+
+	if err := s.migrateFailures(); err != nil {
+		return err
+	}
+
+	if err := s.migrateModelMigrations(); err != nil {
+		return err
+	}
+
+	if err := s.migrateTransfers(); err != nil {
+		return err
+	}
+
+	return s.migrateConsistencyReports()
 }
 
 // GetFileHash retrieves a file hash record by source type and file path
@@ -280,6 +311,66 @@ func (s *HashStore) DeleteFileHashesBySourceType(ctx context.Context, sourceType
 	return result.RowsAffected()
 }
 
+// GetLastIndexedCommit returns the last indexed commit for a repository branch.
+// Returns nil if the branch has not been indexed yet.
+func (s *HashStore) GetLastIndexedCommit(ctx context.Context, repository, branch string) (*RepositoryCommitRecord, error) {
+	query := `
+		SELECT id, repository, branch, commit_sha, indexed_at
+		FROM repository_commits
+		WHERE repository = ? AND branch = ?
+	`
+
+	row := s.db.QueryRowContext(ctx, query, repository, branch)
+
+	var record RepositoryCommitRecord
+	var indexedAt string
+	err := row.Scan(
+		&record.ID,
+		&record.Repository,
+		&record.Branch,
+		&record.CommitSHA,
+		&indexedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not found
+		}
+		return nil, fmt.Errorf("failed to get repository commit: %w", err)
+	}
+
+	record.IndexedAt, err = time.Parse("2006-01-02 15:04:05", indexedAt)
+	if err != nil {
+		record.IndexedAt, _ = time.Parse(time.RFC3339, indexedAt)
+	}
+
+	return &record, nil
+}
+
+// UpsertLastIndexedCommit records the commit a repository branch was last indexed at
+func (s *HashStore) UpsertLastIndexedCommit(ctx context.Context, record *RepositoryCommitRecord) error {
+	upsertSQL := `
+		INSERT INTO repository_commits (repository, branch, commit_sha, indexed_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(repository, branch) DO UPDATE SET
+			commit_sha = excluded.commit_sha,
+			indexed_at = excluded.indexed_at;
+	`
+
+	indexedAt := record.IndexedAt.Format("2006-01-02 15:04:05")
+
+	_, err := s.db.ExecContext(ctx, upsertSQL,
+		record.Repository,
+		record.Branch,
+		record.CommitSHA,
+		indexedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert repository commit: %w", err)
+	}
+
+	return nil
+}
+
 // Close closes the database connection
 func (s *HashStore) Close() error {
 	if s.db != nil {
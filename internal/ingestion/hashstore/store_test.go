@@ -2,6 +2,7 @@ package hashstore
 
 import (
 	"context"
+	"database/sql"
 	"os"
 	"path/filepath"
 	"testing"
@@ -9,6 +10,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
 )
 
 func TestNewHashStore(t *testing.T) {
@@ -230,3 +233,356 @@ func TestHashStore_GetAllFileHashesForSourceTypes(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, emptyHashes, 0)
 }
+
+func TestHashStore_LastIndexedCommit(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "hashstore_test")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	dbPath := filepath.Join(tmpDir, "test.db")
+	store, err := NewHashStoreWithPath(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+
+	// Unknown branch returns nil
+	record, err := store.GetLastIndexedCommit(ctx, "owner/repo", "main")
+	require.NoError(t, err)
+	assert.Nil(t, record)
+
+	err = store.UpsertLastIndexedCommit(ctx, &RepositoryCommitRecord{
+		Repository: "owner/repo",
+		Branch:     "main",
+		CommitSHA:  "abc123",
+		IndexedAt:  time.Now(),
+	})
+	require.NoError(t, err)
+
+	record, err = store.GetLastIndexedCommit(ctx, "owner/repo", "main")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "abc123", record.CommitSHA)
+
+	// Update moves the branch forward
+	err = store.UpsertLastIndexedCommit(ctx, &RepositoryCommitRecord{
+		Repository: "owner/repo",
+		Branch:     "main",
+		CommitSHA:  "def456",
+		IndexedAt:  time.Now(),
+	})
+	require.NoError(t, err)
+
+	record, err = store.GetLastIndexedCommit(ctx, "owner/repo", "main")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "def456", record.CommitSHA)
+
+	// Branches are tracked independently
+	record, err = store.GetLastIndexedCommit(ctx, "owner/repo", "develop")
+	require.NoError(t, err)
+	assert.Nil(t, record)
+}
+
+func TestHashStore_RunCheckpoints(t *testing.T) {
+	store, err := NewHashStoreWithPath(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+
+	require.NoError(t, store.CreateRun(ctx, "run-1", "local:/docs"))
+	require.NoError(t, store.MarkDocumentCompleted(ctx, "run-1", "/docs/a.md", "hash-a1"))
+	require.NoError(t, store.MarkDocumentCompleted(ctx, "run-1", "/docs/a.md", "hash-a2"))
+	require.NoError(t, store.MarkDocumentCompleted(ctx, "run-1", "pdf:///docs/b.pdf/page/1", "hash-b"))
+
+	run, err := store.FindResumableRun(ctx, "local:/docs")
+	require.NoError(t, err)
+	require.NotNil(t, run)
+	assert.Equal(t, "run-1", run.RunID)
+	assert.Equal(t, RunStatusRunning, run.Status)
+	assert.True(t, run.FinishedAt.IsZero())
+
+	completed, err := store.GetCompletedDocuments(ctx, "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"/docs/a.md": "hash-a2", "pdf:///docs/b.pdf/page/1": "hash-b"}, completed)
+
+	// Runs for other sources are not resumed
+	other, err := store.FindResumableRun(ctx, "s3:bucket/")
+	require.NoError(t, err)
+	assert.Nil(t, other)
+
+	// Failed runs stay resumable and keep their checkpoints
+	require.NoError(t, store.FinishRun(ctx, "run-1", RunStatusFailed))
+	run, err = store.FindResumableRun(ctx, "local:/docs")
+	require.NoError(t, err)
+	require.NotNil(t, run)
+	assert.Equal(t, RunStatusFailed, run.Status)
+	assert.False(t, run.FinishedAt.IsZero())
+
+	require.NoError(t, store.ResumeRun(ctx, "run-1"))
+	require.NoError(t, store.FinishRun(ctx, "run-1", RunStatusCompleted))
+
+	run, err = store.FindResumableRun(ctx, "local:/docs")
+	require.NoError(t, err)
+	assert.Nil(t, run)
+
+	completed, err = store.GetCompletedDocuments(ctx, "run-1")
+	require.NoError(t, err)
+	assert.Empty(t, completed)
+
+	missing, err := store.GetRun(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestHashStore_SupersedeRuns(t *testing.T) {
+	store, err := NewHashStoreWithPath(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+
+	require.NoError(t, store.CreateRun(ctx, "old", "local:/docs"))
+	require.NoError(t, store.MarkDocumentCompleted(ctx, "old", "/docs/a.md", "hash-a"))
+	require.NoError(t, store.CreateRun(ctx, "other", "s3:bucket/"))
+	require.NoError(t, store.CreateRun(ctx, "new", "local:/docs"))
+
+	superseded, err := store.SupersedeRuns(ctx, "local:/docs", "new")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), superseded)
+
+	old, err := store.GetRun(ctx, "old")
+	require.NoError(t, err)
+	assert.Equal(t, RunStatusSuperseded, old.Status)
+	assert.False(t, old.Status.Resumable())
+
+	completed, err := store.GetCompletedDocuments(ctx, "old")
+	require.NoError(t, err)
+	assert.Empty(t, completed)
+
+	other, err := store.GetRun(ctx, "other")
+	require.NoError(t, err)
+	assert.Equal(t, RunStatusRunning, other.Status)
+}
+
+func TestHashStore_OCRCache(t *testing.T) {
+	store, err := NewHashStoreWithPath(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+
+	_, found, err := store.GetOCRResult(ctx, "key")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, store.PutOCRResult(ctx, "key", []byte(`[{"page_index":1}]`)))
+	require.NoError(t, store.PutOCRResult(ctx, "key", []byte(`[{"page_index":2}]`)))
+
+	data, found, err := store.GetOCRResult(ctx, "key")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, `[{"page_index":2}]`, string(data))
+}
+
+func TestHashStore_FailureLedger(t *testing.T) {
+	store, err := NewHashStoreWithPath(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	first := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	require.NoError(t, store.RecordFailure(ctx, &FailureRecord{
+		SourceType:   "local",
+		FilePath:     "csv://data/rows.csv/row1",
+		SourcePath:   "data/rows.csv",
+		ErrorType:    "rate_limit",
+		Backend:      "OpenSearch",
+		Message:      "too many requests",
+		Retryable:    true,
+		LastFailedAt: first,
+	}))
+	require.NoError(t, store.RecordFailure(ctx, &FailureRecord{
+		SourceType:   "local",
+		FilePath:     "csv://data/rows.csv/row1",
+		SourcePath:   "data/rows.csv",
+		ErrorType:    "rate_limit",
+		Backend:      "OpenSearch",
+		Message:      "too many requests again",
+		Retryable:    true,
+		LastFailedAt: first.Add(time.Hour),
+	}))
+	require.NoError(t, store.RecordFailure(ctx, &FailureRecord{
+		SourceType:   "s3",
+		FilePath:     "s3://bucket/doc.md",
+		ErrorType:    "embedding_generation",
+		Message:      "model error",
+		LastFailedAt: first.Add(2 * time.Hour),
+	}))
+
+	failures, err := store.ListFailures(ctx, FailureFilter{})
+	require.NoError(t, err)
+	require.Len(t, failures, 2)
+	assert.Equal(t, "s3://bucket/doc.md", failures[0].FilePath)
+	assert.Equal(t, "s3://bucket/doc.md", failures[0].SourcePath, "source path defaults to the file path")
+
+	rateLimited, err := store.ListFailures(ctx, FailureFilter{ErrorType: "rate_limit"})
+	require.NoError(t, err)
+	require.Len(t, rateLimited, 1)
+	assert.Equal(t, 2, rateLimited[0].Attempts)
+	assert.Equal(t, "too many requests again", rateLimited[0].Message)
+	assert.True(t, rateLimited[0].Retryable)
+	assert.Equal(t, first, rateLimited[0].FirstFailedAt.UTC())
+	assert.Equal(t, first.Add(time.Hour), rateLimited[0].LastFailedAt.UTC())
+
+	limited, err := store.ListFailures(ctx, FailureFilter{Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, limited, 1)
+
+	cleared, err := store.ClearFailures(ctx, "data/rows.csv")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cleared)
+
+	failures, err = store.ListFailures(ctx, FailureFilter{})
+	require.NoError(t, err)
+	require.Len(t, failures, 1)
+	assert.Equal(t, "s3://bucket/doc.md", failures[0].FilePath)
+}
+
+func TestHashStore_ModelMigrations(t *testing.T) {
+	store, err := NewHashStoreWithPath(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+
+	missing, err := store.GetModelMigration(ctx, "docs")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	started := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, store.StartModelMigration(ctx, &ModelMigrationRecord{
+		Alias:          "docs",
+		CandidateIndex: "docs-20260501090000",
+		Provider:       "gemini",
+		Model:          "gemini-embedding-001",
+		Dimension:      1536,
+		StartedAt:      started,
+	}))
+
+	migration, err := store.GetModelMigration(ctx, "docs")
+	require.NoError(t, err)
+	require.NotNil(t, migration)
+	assert.Equal(t, "docs-20260501090000", migration.CandidateIndex)
+	assert.Equal(t, "gemini", migration.Provider)
+	assert.Equal(t, "gemini-embedding-001", migration.Model)
+	assert.Equal(t, 1536, migration.Dimension)
+	assert.Equal(t, started, migration.StartedAt.UTC())
+
+	require.NoError(t, store.RecordModelMigrationFailure(ctx, "docs", "docs/a.md", "throttled"))
+	require.NoError(t, store.RecordModelMigrationFailure(ctx, "docs", "docs/b.md", "throttled"))
+	require.NoError(t, store.RecordModelMigrationFailure(ctx, "docs", "docs/a.md", "model error"))
+	require.NoError(t, store.ClearModelMigrationFailure(ctx, "docs", "docs/b.md"))
+	failures, err := store.ListModelMigrationFailures(ctx, "docs")
+	require.NoError(t, err)
+	require.Len(t, failures, 1)
+	assert.Equal(t, "docs/a.md", failures[0].SourcePath)
+	assert.Equal(t, "model error", failures[0].Error)
+
+	require.NoError(t, store.FinishModelMigration(ctx, "docs"))
+	migration, err = store.GetModelMigration(ctx, "docs")
+	require.NoError(t, err)
+	assert.Nil(t, migration)
+	failures, err = store.ListModelMigrationFailures(ctx, "docs")
+	require.NoError(t, err)
+	assert.Empty(t, failures, "finishing a migration clears its failures")
+}
+
+func TestHashStore_TransferCheckpoints(t *testing.T) {
+	store, err := NewHashStoreWithPath(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	name := "export:sqlite:/tmp/vectors.db->file:/tmp/out.jsonl"
+
+	missing, err := store.GetTransferCheckpoint(ctx, name)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	require.NoError(t, store.SaveTransferCheckpoint(ctx, &TransferCheckpoint{Name: name, Cursor: "doc-100", Offset: 4096, Transferred: 100}))
+	require.NoError(t, store.SaveTransferCheckpoint(ctx, &TransferCheckpoint{Name: name, Cursor: "doc-200", Offset: 8192, Transferred: 200}))
+
+	checkpoint, err := store.GetTransferCheckpoint(ctx, name)
+	require.NoError(t, err)
+	require.NotNil(t, checkpoint)
+	assert.Equal(t, "doc-200", checkpoint.Cursor)
+	assert.Equal(t, int64(8192), checkpoint.Offset)
+	assert.Equal(t, int64(200), checkpoint.Transferred)
+	assert.False(t, checkpoint.UpdatedAt.IsZero())
+
+	require.NoError(t, store.DeleteTransferCheckpoint(ctx, name))
+	checkpoint, err = store.GetTransferCheckpoint(ctx, name)
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint)
+}
+
+func TestHashStore_ConsistencyReports(t *testing.T) {
+	store, err := NewHashStoreWithPath(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	latest, err := store.GetLatestConsistencyReport(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	for i := 0; i < maxConsistencyReports+2; i++ {
+		require.NoError(t, store.SaveConsistencyReport(ctx, &pkgdomain.ConsistencyReport{
+			CheckedAt:   time.Date(2026, 5, 1, 0, 0, i, 0, time.UTC),
+			Index:       "docs",
+			VectorCount: i,
+			Issues:      []pkgdomain.ConsistencyIssue{{Kind: pkgdomain.IssueMissingInOpenSearch, ID: "a_chunk_0"}},
+		}))
+	}
+
+	latest, err = store.GetLatestConsistencyReport(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, maxConsistencyReports+1, latest.VectorCount)
+	assert.Equal(t, "docs", latest.Index)
+	require.Len(t, latest.Issues, 1)
+	assert.Equal(t, "a_chunk_0", latest.Issues[0].ID)
+
+	var count int
+	require.NoError(t, store.db.QueryRow(`SELECT COUNT(*) FROM consistency_reports`).Scan(&count))
+	assert.Equal(t, maxConsistencyReports, count)
+}
+
+func TestHashStore_RunCheckpointsWithoutContentHash(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", dbPath)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		CREATE TABLE run_checkpoints (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id TEXT NOT NULL,
+			document_path TEXT NOT NULL,
+			completed_at DATETIME NOT NULL,
+			UNIQUE(run_id, document_path)
+		);
+		INSERT INTO run_checkpoints (run_id, document_path, completed_at) VALUES ('run-1', '/docs/a.md', '2024-01-01 00:00:00');
+	`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	store, err := NewHashStoreWithPath(dbPath)
+	require.NoError(t, err)
+	defer func() { _ = store.Close() }()
+
+	completed, err := store.GetCompletedDocuments(context.Background(), "run-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"/docs/a.md": ""}, completed, "checkpoints of older versions have no content hash")
+}
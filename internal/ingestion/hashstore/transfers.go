@@ -0,0 +1,88 @@
+package hashstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migrateTransfers creates the transfer_checkpoints table used to resume vector transfers
+func (s *HashStore) migrateTransfers() error {
+	createTableSQL := `
+		CREATE TABLE IF NOT EXISTS transfer_checkpoints (
+			name TEXT PRIMARY KEY,
+			cursor TEXT NOT NULL,
+			file_offset INTEGER NOT NULL,
+			transferred INTEGER NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+	`
+	if _, err := s.db.Exec(createTableSQL); err != nil {
+		return fmt.Errorf("failed to create transfer_checkpoints table: %w", err)
+	}
+	return nil
+}
+
+// SaveTransferCheckpoint records the progress of a transfer, replacing the previous checkpoint
+func (s *HashStore) SaveTransferCheckpoint(ctx context.Context, checkpoint *TransferCheckpoint) error {
+	upsertSQL := `
+		INSERT INTO transfer_checkpoints (name, cursor, file_offset, transferred, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			cursor = excluded.cursor,
+			file_offset = excluded.file_offset,
+			transferred = excluded.transferred,
+			updated_at = excluded.updated_at;
+	`
+	updatedAt := checkpoint.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
+
+	_, err := s.db.ExecContext(ctx, upsertSQL,
+		checkpoint.Name,
+		checkpoint.Cursor,
+		checkpoint.Offset,
+		checkpoint.Transferred,
+		updatedAt.Format("2006-01-02 15:04:05"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save transfer checkpoint: %w", err)
+	}
+	return nil
+}
+
+// GetTransferCheckpoint returns the checkpoint of a transfer. Returns nil if there is none.
+func (s *HashStore) GetTransferCheckpoint(ctx context.Context, name string) (*TransferCheckpoint, error) {
+	query := `
+		SELECT name, cursor, file_offset, transferred, updated_at
+		FROM transfer_checkpoints
+		WHERE name = ?
+	`
+	var checkpoint TransferCheckpoint
+	var updatedAt string
+	err := s.db.QueryRowContext(ctx, query, name).Scan(
+		&checkpoint.Name,
+		&checkpoint.Cursor,
+		&checkpoint.Offset,
+		&checkpoint.Transferred,
+		&updatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Not found
+		}
+		return nil, fmt.Errorf("failed to get transfer checkpoint: %w", err)
+	}
+	checkpoint.UpdatedAt = parseStoredTime(updatedAt)
+	return &checkpoint, nil
+}
+
+// DeleteTransferCheckpoint discards the checkpoint of a finished transfer
+func (s *HashStore) DeleteTransferCheckpoint(ctx context.Context, name string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM transfer_checkpoints WHERE name = ?`, name); err != nil {
+		return fmt.Errorf("failed to delete transfer checkpoint: %w", err)
+	}
+	return nil
+}
@@ -35,13 +35,22 @@ func (c ChangeType) String() string {
 // FileHashRecord represents a stored file hash record
 type FileHashRecord struct {
 	ID           int64
-	SourceType   string // "local" or "s3"
+	SourceType   string // "local", "s3" or "github"
 	FilePath     string
 	ContentHash  string // MD5 hash in hex format
 	FileSize     int64
 	VectorizedAt time.Time
 }
 
+// RepositoryCommitRecord represents the last indexed commit of a repository branch
+type RepositoryCommitRecord struct {
+	ID         int64
+	Repository string // "owner/name"
+	Branch     string
+	CommitSHA  string
+	IndexedAt  time.Time
+}
+
 // FileChange represents a detected change for a file
 type FileChange struct {
 	FilePath   string
@@ -61,3 +70,83 @@ type ChangeDetectionResult struct {
 	UnchangeCount int
 	DeleteCount   int
 }
+
+// RunStatus represents the lifecycle state of a vectorize run
+type RunStatus string
+
+const (
+	// RunStatusRunning indicates the run is in progress, or died without finishing
+	RunStatusRunning RunStatus = "running"
+	// RunStatusCompleted indicates the run finished and its checkpoints were discarded
+	RunStatusCompleted RunStatus = "completed"
+	// RunStatusFailed indicates the run finished with errors
+	RunStatusFailed RunStatus = "failed"
+	// RunStatusInterrupted indicates the run was cancelled before finishing
+	RunStatusInterrupted RunStatus = "interrupted"
+	// RunStatusSuperseded indicates a newer run was started without resuming this one
+	RunStatusSuperseded RunStatus = "superseded"
+)
+
+// Resumable reports whether a run with this status can be resumed
+func (s RunStatus) Resumable() bool {
+	return s == RunStatusRunning || s == RunStatusFailed || s == RunStatusInterrupted
+}
+
+// VectorizeRunRecord represents a single vectorize run tracked for checkpointing
+type VectorizeRunRecord struct {
+	RunID      string
+	Sources    string // Normalized description of the sources the run covered
+	Status     RunStatus
+	StartedAt  time.Time
+	FinishedAt time.Time // Zero while the run is in progress
+}
+
+// FailureRecord represents a document that failed to vectorize
+type FailureRecord struct {
+	ID            int64
+	SourceType    string // "local", "s3" or "github"
+	FilePath      string // Failed document (file, CSV row or PDF page)
+	SourcePath    string // File the document was read from
+	ErrorType     string // config.ErrorType value
+	Backend       string // Backend that failed; empty when the failure happened before any backend write
+	Message       string
+	Retryable     bool
+	Attempts      int
+	FirstFailedAt time.Time
+	LastFailedAt  time.Time
+}
+
+// FailureFilter narrows down the failures returned by ListFailures
+type FailureFilter struct {
+	ErrorType string // Only failures of this type; empty for all
+	Limit     int    // Maximum number of records; 0 for no limit
+}
+
+// ModelMigrationRecord represents an embedding model migration in progress for an alias.
+// While it exists, vectorize runs also write into the candidate index with the target model.
+type ModelMigrationRecord struct {
+	Alias          string
+	CandidateIndex string // Index generation embedded with the target model
+	Provider       string // Target EMBEDDING_PROVIDER
+	Model          string // Target EMBEDDING_MODEL
+	Dimension      int    // Target EMBEDDING_DIMENSION; 0 for the model default
+	StartedAt      time.Time
+}
+
+// ModelMigrationFailure is a source document that is missing from the candidate index of a
+// model migration. Cutover is refused while any are recorded.
+type ModelMigrationFailure struct {
+	Alias      string
+	SourcePath string
+	Error      string
+	FailedAt   time.Time
+}
+
+// TransferCheckpoint records how far a vector export, import or migration has progressed
+type TransferCheckpoint struct {
+	Name        string // Identifies the transfer, e.g. "migrate:s3:bucket/index->sqlite:/path"
+	Cursor      string // Source cursor of the next batch
+	Offset      int64  // Size of the export file at the checkpoint; 0 when not writing a file
+	Transferred int64
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,489 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ca-srg/ragent/internal/ingestion/vectorizer"
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	"github.com/ca-srg/ragent/internal/pkg/embedding"
+	"github.com/ca-srg/ragent/internal/pkg/opensearch"
+)
+
+// DefaultKeepGenerations is the number of previous index generations kept for rollback
+const DefaultKeepGenerations = 2
+
+// DefaultMinDocRatio is the minimum document count of a new generation relative to the live one
+const DefaultMinDocRatio = 0.95
+
+// generationTimeFormat is the timestamp suffix of generation index names (<alias>-<timestamp>)
+const generationTimeFormat = "20060102150405"
+
+// IndexRebuildOptions holds the flags of `index rebuild`
+type IndexRebuildOptions struct {
+	Alias              string
+	Keep               int
+	MinDocRatio        float64
+	ReplaceLegacyIndex bool
+	Vectorize          VectorizeOptions
+}
+
+// IndexRollbackOptions holds the flags of `index rollback`
+type IndexRollbackOptions struct {
+	Alias string
+	To    string
+}
+
+// IndexListOptions holds the flags of `index list`
+type IndexListOptions struct {
+	Alias string
+}
+
+// IndexCheckOptions holds the flags of `index check`
+type IndexCheckOptions struct {
+	Alias string
+}
+
+// indexAliasClient is the subset of the OpenSearch client used to manage index generations
+type indexAliasClient interface {
+	GetAliasIndices(ctx context.Context, alias string) ([]string, error)
+	ListIndices(ctx context.Context, pattern string) ([]string, error)
+	IndexExists(ctx context.Context, name string) (bool, error)
+	CountDocuments(ctx context.Context, name string) (int64, error)
+	RefreshIndex(ctx context.Context, name string) error
+	DeleteIndex(ctx context.Context, name string) error
+	UpdateAliases(ctx context.Context, actions []opensearch.AliasAction) error
+}
+
+// rebuildSettings controls a single blue/green rebuild
+type rebuildSettings struct {
+	alias              string
+	keep               int
+	minDocRatio        float64
+	replaceLegacyIndex bool
+	now                time.Time
+}
+
+// rebuildReport describes the outcome of a rebuild
+type rebuildReport struct {
+	Alias    string
+	NewIndex string
+	Previous []string
+	OldCount int64
+	NewCount int64
+	Deleted  []string
+}
+
+// RunIndexRebuild builds a new index generation, vectorizes every source into it and
+// atomically points the alias at it once its document count has been validated
+func RunIndexRebuild(cmd *cobra.Command, opts IndexRebuildOptions) error {
+	applyVectorizeOptions(opts.Vectorize)
+	// A rebuild always writes every document into the new generation
+	dryRun = false
+	clearVectors = false
+	followMode = false
+	watchMode = false
+	resumeRun = false
+	resumeRunID = ""
+	forceProcess = true
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cfg, client, alias, err := openIndexAliasClient(opts.Alias)
+	if err != nil {
+		return err
+	}
+	if concurrency > 0 {
+		cfg.Concurrency = concurrency
+	}
+	// Rebuild with the model the alias serves, which a model migration may have changed
+	cfg = client.ActiveEmbeddingConfig(ctx, cfg, alias)
+
+	keep := opts.Keep
+	if keep < 0 {
+		keep = 0
+	}
+	minDocRatio := opts.MinDocRatio
+	if minDocRatio < 0 {
+		minDocRatio = DefaultMinDocRatio
+	}
+
+	dimension := resolveEmbeddingDimension(cfg)
+	settings := rebuildSettings{
+		alias:              alias,
+		keep:               keep,
+		minDocRatio:        minDocRatio,
+		replaceLegacyIndex: opts.ReplaceLegacyIndex,
+		now:                time.Now(),
+	}
+
+	report, err := rebuildIndex(ctx, client, settings, func(ctx context.Context, index string) error {
+		indexer := vectorizer.NewOpenSearchIndexer(client, index, dimension)
+		indexer.SetIndexProfile(opensearch.NewKNNIndexProfileFromConfig(cfg))
+		log.Printf("Creating index generation %s with %d-dimensional embedding field", index, dimension)
+		if err := indexer.CreateVectorIndexWithJapanese(ctx, index, dimension); err != nil {
+			return fmt.Errorf("failed to create index %s: %w", index, err)
+		}
+		if err := client.SetIndexEmbeddingModel(ctx, index, opensearch.EmbeddingModelFromConfig(cfg)); err != nil {
+			return fmt.Errorf("failed to record the embedding model of %s: %w", index, err)
+		}
+
+		openSearchIndexName = index
+		result, err := vectorizationRunner(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		if result != nil {
+			printResults(result, false)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n✅ Alias %s now points to %s (%d documents", report.Alias, report.NewIndex, report.NewCount)
+	if len(report.Previous) > 0 {
+		fmt.Printf(", previously %s with %d", strings.Join(report.Previous, ", "), report.OldCount)
+	}
+	fmt.Println(")")
+	for _, index := range report.Deleted {
+		fmt.Printf("  Deleted expired generation: %s\n", index)
+	}
+	fmt.Printf("Roll back with: RAGent index rollback --alias %s\n", report.Alias)
+	return nil
+}
+
+// RunIndexRollback points the alias back at a previous index generation
+func RunIndexRollback(cmd *cobra.Command, opts IndexRollbackOptions) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	_, client, alias, err := openIndexAliasClient(opts.Alias)
+	if err != nil {
+		return err
+	}
+
+	live, err := client.GetAliasIndices(ctx, alias)
+	if err != nil {
+		return fmt.Errorf("failed to resolve alias %s: %w", alias, err)
+	}
+	if len(live) == 0 {
+		return fmt.Errorf("%s is not an alias; run `index rebuild` first", alias)
+	}
+
+	indices, err := client.ListIndices(ctx, alias+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to list index generations: %w", err)
+	}
+	generations := generationsOf(alias, indices)
+
+	target := opts.To
+	if target == "" {
+		target = previousGeneration(generations, live[len(live)-1])
+		if target == "" {
+			return fmt.Errorf("no previous generation of %s to roll back to", alias)
+		}
+	} else if !containsString(generations, target) {
+		return fmt.Errorf("%s is not a generation of %s", target, alias)
+	}
+
+	if err := client.UpdateAliases(ctx, opensearch.SwapAliasActions(alias, target, live, "")); err != nil {
+		return fmt.Errorf("failed to swap alias %s: %w", alias, err)
+	}
+
+	fmt.Printf("✅ Alias %s now points to %s (was %s)\n", alias, target, strings.Join(live, ", "))
+	return nil
+}
+
+// RunIndexList prints the generations of an alias and the one currently serving it
+func RunIndexList(cmd *cobra.Command, opts IndexListOptions) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	_, client, alias, err := openIndexAliasClient(opts.Alias)
+	if err != nil {
+		return err
+	}
+
+	live, err := client.GetAliasIndices(ctx, alias)
+	if err != nil {
+		return fmt.Errorf("failed to resolve alias %s: %w", alias, err)
+	}
+	indices, err := client.ListIndices(ctx, alias+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to list index generations: %w", err)
+	}
+	generations := generationsOf(alias, indices)
+
+	fmt.Printf("\nIndex generations of %s:\n", alias)
+	if len(generations) == 0 {
+		fmt.Println("  (no generations)")
+	}
+	for i := len(generations) - 1; i >= 0; i-- {
+		index := generations[i]
+		marker := " "
+		if containsString(live, index) {
+			marker = "*"
+		}
+		count, err := client.CountDocuments(ctx, index)
+		if err != nil {
+			fmt.Printf("%s %s  (count unavailable: %v)\n", marker, index, err)
+			continue
+		}
+		fmt.Printf("%s %s  %d documents\n", marker, index, count)
+	}
+	if len(live) == 0 {
+		fmt.Printf("\n%s is not an alias yet. Run `index rebuild` to switch to alias-based indexes.\n", alias)
+	}
+	return nil
+}
+
+// RunIndexCheck compares the live index behind the alias with the k-NN mapping profile
+// (OPENSEARCH_KNN_* and shard settings) and returns an error when any value has drifted
+func RunIndexCheck(cmd *cobra.Command, opts IndexCheckOptions) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cfg, client, alias, err := openIndexAliasClient(opts.Alias)
+	if err != nil {
+		return err
+	}
+
+	indexer := vectorizer.NewOpenSearchIndexer(client, alias, 0)
+	indexer.SetIndexProfile(opensearch.NewKNNIndexProfileFromConfig(cfg))
+	comparison, err := indexer.CompareIndexSettings(ctx, alias, resolveEmbeddingDimension(cfg))
+	if err != nil {
+		return err
+	}
+
+	name := alias
+	if resolved, ok := comparison["resolved_index"].(string); ok && resolved != alias {
+		name = fmt.Sprintf("%s (%s)", alias, resolved)
+	}
+	issues, _ := comparison["issues"].([]string)
+	if len(issues) == 0 {
+		fmt.Printf("✅ %s matches the k-NN index profile\n", name)
+		return nil
+	}
+	fmt.Printf("\n%s differs from the k-NN index profile:\n", name)
+	for _, issue := range issues {
+		fmt.Printf("  - %s\n", issue)
+	}
+	fmt.Println("\nReplica counts can be changed in place; other values require `index rebuild`.")
+	return fmt.Errorf("%d settings of %s differ from the profile", len(issues), alias)
+}
+
+// rebuildIndex creates a new generation with build, validates its document count against the
+// live index and atomically swaps the alias. Expired generations are deleted afterwards.
+func rebuildIndex(
+	ctx context.Context,
+	client indexAliasClient,
+	settings rebuildSettings,
+	build func(ctx context.Context, index string) error,
+) (*rebuildReport, error) {
+	alias := settings.alias
+	report := &rebuildReport{Alias: alias, NewIndex: generationName(alias, settings.now)}
+
+	live, err := client.GetAliasIndices(ctx, alias)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve alias %s: %w", alias, err)
+	}
+
+	// An index named like the alias predates alias management and has to be replaced
+	legacyIndex := ""
+	if len(live) == 0 {
+		exists, err := client.IndexExists(ctx, alias)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check index %s: %w", alias, err)
+		}
+		if exists {
+			if !settings.replaceLegacyIndex {
+				return nil, fmt.Errorf("%s is a concrete index, not an alias; rerun with --replace-legacy-index to replace it with an alias once the new generation is validated", alias)
+			}
+			legacyIndex = alias
+		}
+	}
+
+	if len(live) > 0 || legacyIndex != "" {
+		report.OldCount, err = client.CountDocuments(ctx, alias)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count documents in %s: %w", alias, err)
+		}
+	}
+	report.Previous = live
+	if legacyIndex != "" {
+		report.Previous = []string{legacyIndex}
+	}
+
+	log.Printf("Building index generation %s for alias %s", report.NewIndex, alias)
+	if err := build(ctx, report.NewIndex); err != nil {
+		discardGeneration(client, report.NewIndex)
+		return nil, fmt.Errorf("failed to build index generation %s: %w", report.NewIndex, err)
+	}
+
+	if err := client.RefreshIndex(ctx, report.NewIndex); err != nil {
+		discardGeneration(client, report.NewIndex)
+		return nil, fmt.Errorf("failed to refresh index %s: %w", report.NewIndex, err)
+	}
+	report.NewCount, err = client.CountDocuments(ctx, report.NewIndex)
+	if err != nil {
+		discardGeneration(client, report.NewIndex)
+		return nil, fmt.Errorf("failed to count documents in %s: %w", report.NewIndex, err)
+	}
+	if err := validateGenerationCount(report.OldCount, report.NewCount, settings.minDocRatio); err != nil {
+		discardGeneration(client, report.NewIndex)
+		return nil, fmt.Errorf("alias %s was left unchanged: %w", alias, err)
+	}
+
+	actions := opensearch.SwapAliasActions(alias, report.NewIndex, live, legacyIndex)
+	if err := client.UpdateAliases(ctx, actions); err != nil {
+		return nil, fmt.Errorf("failed to swap alias %s to %s: %w", alias, report.NewIndex, err)
+	}
+	log.Printf("Alias %s swapped to %s", alias, report.NewIndex)
+
+	indices, err := client.ListIndices(ctx, alias+"-*")
+	if err != nil {
+		log.Printf("Warning: failed to list index generations, skipping cleanup: %v", err)
+		return report, nil
+	}
+	for _, index := range expiredGenerations(generationsOf(alias, indices), report.NewIndex, settings.keep) {
+		if err := client.DeleteIndex(ctx, index); err != nil {
+			log.Printf("Warning: failed to delete expired generation %s: %v", index, err)
+			continue
+		}
+		report.Deleted = append(report.Deleted, index)
+	}
+
+	return report, nil
+}
+
+// discardGeneration deletes a generation that never went live
+func discardGeneration(client indexAliasClient, index string) {
+	// The run context may already be cancelled; cleanup has to happen regardless
+	if err := client.DeleteIndex(context.Background(), index); err != nil {
+		log.Printf("Warning: failed to delete unfinished generation %s: %v", index, err)
+		return
+	}
+	log.Printf("Deleted unfinished generation %s", index)
+}
+
+// validateGenerationCount rejects a new generation with noticeably fewer documents than the live index
+func validateGenerationCount(oldCount, newCount int64, minRatio float64) error {
+	if newCount == 0 {
+		return fmt.Errorf("new generation has no documents")
+	}
+	if oldCount == 0 {
+		return nil
+	}
+	if float64(newCount) < float64(oldCount)*minRatio {
+		return fmt.Errorf("new generation has %d documents but the live index has %d (minimum ratio %.2f, see --min-doc-ratio)",
+			newCount, oldCount, minRatio)
+	}
+	return nil
+}
+
+// generationName returns the name of the generation of alias created at t
+func generationName(alias string, t time.Time) string {
+	return alias + "-" + t.UTC().Format(generationTimeFormat)
+}
+
+// generationsOf returns the indices that are generations of alias, oldest first
+func generationsOf(alias string, indices []string) []string {
+	var generations []string
+	for _, index := range indices {
+		suffix, ok := strings.CutPrefix(index, alias+"-")
+		if !ok || len(suffix) != len(generationTimeFormat) {
+			continue
+		}
+		if _, err := time.Parse(generationTimeFormat, suffix); err != nil {
+			continue
+		}
+		generations = append(generations, index)
+	}
+	sort.Strings(generations)
+	return generations
+}
+
+// expiredGenerations returns the generations older than live beyond the newest keep
+func expiredGenerations(generations []string, live string, keep int) []string {
+	var older []string
+	for _, index := range generations {
+		if index < live {
+			older = append(older, index)
+		}
+	}
+	if len(older) <= keep {
+		return nil
+	}
+	return older[:len(older)-keep]
+}
+
+// previousGeneration returns the newest generation older than live
+func previousGeneration(generations []string, live string) string {
+	previous := ""
+	for _, index := range generations {
+		if index < live {
+			previous = index
+		}
+	}
+	return previous
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+// openIndexAliasClient loads the configuration and connects to OpenSearch.
+// The alias defaults to OPENSEARCH_INDEX, which is also what queries search.
+func openIndexAliasClient(alias string) (*appconfig.Config, *opensearch.Client, string, error) {
+	cfg, err := appconfig.Load()
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := validateOpenSearchFlags(); err != nil {
+		return nil, nil, "", fmt.Errorf("flag validation failed: %w", err)
+	}
+	if alias == "" {
+		alias = openSearchIndexName
+	}
+
+	client, err := opensearch.NewClient(vectorizer.NewIndexerFactory(cfg).GetOpenSearchConfiguration())
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to create OpenSearch client: %w", err)
+	}
+	return cfg, client, alias, nil
+}
+
+// resolveEmbeddingDimension returns the dimension of the configured embedding model
+func resolveEmbeddingDimension(cfg *appconfig.Config) int {
+	dimension := 768
+	embeddingClient, err := embedding.NewEmbeddingClient(cfg)
+	if err == nil {
+		_, d, dErr := embeddingClient.GetModelInfo()
+		if dErr == nil && d > 0 {
+			dimension = d
+		}
+	}
+	return dimension
+}
@@ -0,0 +1,192 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ca-srg/ragent/internal/pkg/opensearch"
+)
+
+// fakeAliasClient keeps indices, document counts and one alias in memory
+type fakeAliasClient struct {
+	counts  map[string]int64
+	aliased []string
+	alias   string
+	deleted []string
+	actions []opensearch.AliasAction
+}
+
+func (f *fakeAliasClient) GetAliasIndices(ctx context.Context, alias string) ([]string, error) {
+	if alias != f.alias || len(f.aliased) == 0 {
+		return nil, nil
+	}
+	return append([]string(nil), f.aliased...), nil
+}
+
+func (f *fakeAliasClient) ListIndices(ctx context.Context, pattern string) ([]string, error) {
+	prefix := strings.TrimSuffix(pattern, "*")
+	var indices []string
+	for index := range f.counts {
+		if strings.HasPrefix(index, prefix) {
+			indices = append(indices, index)
+		}
+	}
+	sort.Strings(indices)
+	return indices, nil
+}
+
+func (f *fakeAliasClient) IndexExists(ctx context.Context, name string) (bool, error) {
+	_, ok := f.counts[name]
+	return ok, nil
+}
+
+func (f *fakeAliasClient) CountDocuments(ctx context.Context, name string) (int64, error) {
+	if name == f.alias && len(f.aliased) > 0 {
+		var total int64
+		for _, index := range f.aliased {
+			total += f.counts[index]
+		}
+		return total, nil
+	}
+	count, ok := f.counts[name]
+	if !ok {
+		return 0, errors.New("index not found")
+	}
+	return count, nil
+}
+
+func (f *fakeAliasClient) RefreshIndex(ctx context.Context, name string) error { return nil }
+
+func (f *fakeAliasClient) DeleteIndex(ctx context.Context, name string) error {
+	delete(f.counts, name)
+	f.deleted = append(f.deleted, name)
+	return nil
+}
+
+func (f *fakeAliasClient) UpdateAliases(ctx context.Context, actions []opensearch.AliasAction) error {
+	f.actions = actions
+	var aliased []string
+	for _, action := range actions {
+		switch {
+		case action.RemoveIndex != nil:
+			delete(f.counts, action.RemoveIndex.Index)
+		case action.Add != nil:
+			aliased = append(aliased, action.Add.Index)
+		}
+	}
+	f.aliased = aliased
+	return nil
+}
+
+func buildWith(client *fakeAliasClient, docs int64) func(ctx context.Context, index string) error {
+	return func(ctx context.Context, index string) error {
+		client.counts[index] = docs
+		return nil
+	}
+}
+
+func TestRebuildIndex_SwapsAliasAndKeepsGenerations(t *testing.T) {
+	client := &fakeAliasClient{
+		alias: "docs",
+		counts: map[string]int64{
+			"docs-20260101000000": 100,
+			"docs-20260201000000": 100,
+			"docs-20260301000000": 100,
+			"docs-archive":        5,
+		},
+		aliased: []string{"docs-20260301000000"},
+	}
+	settings := rebuildSettings{
+		alias:       "docs",
+		keep:        1,
+		minDocRatio: 0.95,
+		now:         time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	report, err := rebuildIndex(context.Background(), client, settings, buildWith(client, 98))
+	require.NoError(t, err)
+
+	assert.Equal(t, "docs-20260401120000", report.NewIndex)
+	assert.Equal(t, int64(100), report.OldCount)
+	assert.Equal(t, int64(98), report.NewCount)
+	assert.Equal(t, []string{"docs-20260401120000"}, client.aliased)
+	assert.Equal(t, []string{"docs-20260101000000", "docs-20260201000000"}, report.Deleted)
+	assert.Contains(t, client.counts, "docs-20260301000000", "the previous generation is kept for rollback")
+	assert.Contains(t, client.counts, "docs-archive", "indices that are not generations are never deleted")
+}
+
+func TestRebuildIndex_RejectsShortGeneration(t *testing.T) {
+	client := &fakeAliasClient{
+		alias:   "docs",
+		counts:  map[string]int64{"docs-20260301000000": 100},
+		aliased: []string{"docs-20260301000000"},
+	}
+	settings := rebuildSettings{alias: "docs", minDocRatio: 0.95, now: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)}
+
+	_, err := rebuildIndex(context.Background(), client, settings, buildWith(client, 50))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "left unchanged")
+	assert.Equal(t, []string{"docs-20260301000000"}, client.aliased)
+	assert.Equal(t, []string{"docs-20260401000000"}, client.deleted, "the unfinished generation is discarded")
+	assert.Nil(t, client.actions)
+}
+
+func TestRebuildIndex_ReplacesLegacyIndexOnlyWhenAllowed(t *testing.T) {
+	newClient := func() *fakeAliasClient {
+		return &fakeAliasClient{alias: "docs", counts: map[string]int64{"docs": 10}}
+	}
+	settings := rebuildSettings{alias: "docs", minDocRatio: 0.95, now: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)}
+
+	client := newClient()
+	_, err := rebuildIndex(context.Background(), client, settings, buildWith(client, 10))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--replace-legacy-index")
+	assert.Contains(t, client.counts, "docs")
+
+	client = newClient()
+	settings.replaceLegacyIndex = true
+	report, err := rebuildIndex(context.Background(), client, settings, buildWith(client, 10))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs"}, report.Previous)
+	assert.NotContains(t, client.counts, "docs")
+	assert.Equal(t, []string{"docs-20260401000000"}, client.aliased)
+}
+
+func TestRebuildIndex_DiscardsGenerationWhenBuildFails(t *testing.T) {
+	client := &fakeAliasClient{alias: "docs", counts: map[string]int64{}}
+	settings := rebuildSettings{alias: "docs", now: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)}
+
+	_, err := rebuildIndex(context.Background(), client, settings, func(ctx context.Context, index string) error {
+		client.counts[index] = 3
+		return errors.New("embedding failed")
+	})
+	require.Error(t, err)
+	assert.Empty(t, client.counts)
+	assert.Nil(t, client.aliased)
+}
+
+func TestGenerationHelpers(t *testing.T) {
+	generations := generationsOf("docs", []string{
+		"docs-20260301000000", "docs-20260101000000", "docs-old", "docs-2026", "other-20260101000000", "docs-20260201000000",
+	})
+	assert.Equal(t, []string{"docs-20260101000000", "docs-20260201000000", "docs-20260301000000"}, generations)
+
+	assert.Equal(t, "docs-20260201000000", previousGeneration(generations, "docs-20260301000000"))
+	assert.Empty(t, previousGeneration(generations, "docs-20260101000000"))
+
+	assert.Equal(t, []string{"docs-20260101000000"}, expiredGenerations(generations, "docs-20260301000000", 1))
+	assert.Nil(t, expiredGenerations(generations, "docs-20260301000000", 2))
+	assert.Nil(t, expiredGenerations(generations, "docs-20260101000000", 0), "newer generations are never expired")
+
+	assert.NoError(t, validateGenerationCount(0, 5, 0.95))
+	assert.Error(t, validateGenerationCount(0, 0, 0.95))
+	assert.Error(t, validateGenerationCount(100, 90, 0.95))
+	assert.NoError(t, validateGenerationCount(100, 90, 0))
+}
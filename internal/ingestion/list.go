@@ -9,6 +9,8 @@ import (
 
 	"github.com/ca-srg/ragent/internal/ingestion/vectorizer"
 	"github.com/ca-srg/ragent/internal/pkg/config"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+	"github.com/ca-srg/ragent/internal/pkg/opensearch"
 )
 
 // RunList lists all vectors stored in the vector store with optional prefix filtering.
@@ -41,6 +43,7 @@ func RunList(prefix string) error {
 	if err != nil {
 		return fmt.Errorf("failed to list vectors: %w", err)
 	}
+	items = filterListItems(items, cliAccessFilter(cfg))
 
 	fmt.Printf("\nFound %d vectors in vector store:\n", len(items))
 	info, err := service.GetBackendInfo(ctx)
@@ -71,3 +74,28 @@ func RunList(prefix string) error {
 
 	return nil
 }
+
+// filterListItems drops the vectors the caller may not read according to the secret,
+// tenant and allowed_groups keys of their metadata
+func filterListItems(items []pkgdomain.VectorListItem, access opensearch.DocumentFilter) []pkgdomain.VectorListItem {
+	allowed := items[:0]
+	for _, item := range items {
+		secret, _ := item.RawMetadata["secret"].(bool)
+		tenant, _ := item.RawMetadata["tenant"].(string)
+		var groups []string
+		switch values := item.RawMetadata["allowed_groups"].(type) {
+		case []string:
+			groups = values
+		case []interface{}:
+			for _, value := range values {
+				if group, ok := value.(string); ok {
+					groups = append(groups, group)
+				}
+			}
+		}
+		if access.Allows(secret, tenant, groups) {
+			allowed = append(allowed, item)
+		}
+	}
+	return allowed
+}
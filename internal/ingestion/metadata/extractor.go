@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	pkgconfig "github.com/ca-srg/ragent/internal/pkg/config"
 	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
 	"gopkg.in/yaml.v3"
 )
@@ -57,6 +58,12 @@ func (e *MetadataExtractor) ExtractMetadata(filePath string, content string) (*p
 	// Extract secret from front matter
 	metadata.Secret = e.extractSecret(frontMatter)
 
+	// Extract groups allowed to read the document from front matter
+	metadata.AllowedGroups, err = e.extractAllowedGroups(frontMatter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid front matter in %s: %w", filePath, err)
+	}
+
 	// Calculate word count
 	metadata.WordCount = e.calculateWordCount(cleanContent)
 
@@ -412,11 +419,44 @@ func (e *MetadataExtractor) extractSecret(frontMatter map[string]interface{}) bo
 	}
 }
 
+// extractAllowedGroups reads allowed_groups as a YAML list or a comma-separated string.
+// A value that names no group, such as [], {}, [1, 2] or an empty value, is an error
+// rather than an unrestricted document.
+func (e *MetadataExtractor) extractAllowedGroups(frontMatter map[string]interface{}) ([]string, error) {
+	val, ok := frontMatter["allowed_groups"]
+	if !ok {
+		return nil, nil
+	}
+
+	var groups []string
+	switch v := val.(type) {
+	case string:
+		groups = pkgconfig.ParseGroupList(v)
+	case []interface{}:
+		names := make([]string, 0, len(v))
+		for _, item := range v {
+			group, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("allowed_groups must list group names, got %v", item)
+			}
+			names = append(names, group)
+		}
+		groups = pkgconfig.NormalizeGroups(names)
+	default:
+		return nil, fmt.Errorf("allowed_groups must be a list or a comma-separated string, got %T", val)
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("allowed_groups names no group; remove it to make the document unrestricted")
+	}
+	return groups, nil
+}
+
 // isReservedField checks if a field name is reserved for specific metadata fields
 func (e *MetadataExtractor) isReservedField(fieldName string) bool {
 	reserved := []string{
 		"title", "category", "tags", "author", "date", "updated",
 		"created", "created_at", "updated_at", "reference", "secret",
+		"allowed_groups",
 	}
 
 	fieldLower := strings.ToLower(fieldName)
@@ -477,6 +517,10 @@ func (e *MetadataExtractor) ExtractGitHubMetadata(repoOwner, repoName, repoRelat
 
 	metadata.CreatedAt, metadata.UpdatedAt = e.extractDates(frontMatter, repoRelativePath)
 	metadata.Secret = e.extractSecret(frontMatter)
+	metadata.AllowedGroups, err = e.extractAllowedGroups(frontMatter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid front matter in %s: %w", metadata.FilePath, err)
+	}
 	metadata.WordCount = e.calculateWordCount(cleanContent)
 
 	for key, value := range frontMatter {
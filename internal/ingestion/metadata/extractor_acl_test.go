@@ -0,0 +1,65 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractMetadata_AllowedGroupsFromFrontmatter(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantGroups []string
+	}{
+		{
+			name: "yaml list",
+			content: `---
+title: Runbook
+allowed_groups: [SRE, security]
+---
+# Runbook`,
+			wantGroups: []string{"security", "sre"},
+		},
+		{
+			name: "comma-separated string",
+			content: `---
+allowed_groups: "sre, sre, oncall"
+---
+# Runbook`,
+			wantGroups: []string{"oncall", "sre"},
+		},
+		{
+			name:       "not set",
+			content:    "# Plain Doc\nContent",
+			wantGroups: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewMetadataExtractor()
+			meta, err := e.ExtractMetadata("docs/test.md", tt.content)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantGroups, meta.AllowedGroups)
+			_, inCustom := meta.CustomFields["allowed_groups"]
+			assert.False(t, inCustom, "allowed_groups should be a reserved field")
+		})
+	}
+}
+
+func TestExtractMetadata_MalformedAllowedGroupsFailClosed(t *testing.T) {
+	for _, value := range []string{"[1, 2]", "[]", "{}", `""`, "", "[sre, 3]"} {
+		t.Run(value, func(t *testing.T) {
+			content := "---\ntitle: Runbook\nallowed_groups: " + value + "\n---\n# Runbook"
+			e := NewMetadataExtractor()
+
+			_, err := e.ExtractMetadata("docs/test.md", content)
+			assert.Error(t, err)
+			_, err = e.ExtractGitHubMetadata("owner", "repo", "docs/test.md", content)
+			assert.Error(t, err)
+		})
+	}
+}
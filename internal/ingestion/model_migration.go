@@ -0,0 +1,906 @@
+package ingestion
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ca-srg/ragent/internal/ingestion/csv"
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	"github.com/ca-srg/ragent/internal/ingestion/metadata"
+	"github.com/ca-srg/ragent/internal/ingestion/pdf"
+	"github.com/ca-srg/ragent/internal/ingestion/scanner"
+	"github.com/ca-srg/ragent/internal/ingestion/vectorizer"
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+	"github.com/ca-srg/ragent/internal/pkg/embedding"
+	"github.com/ca-srg/ragent/internal/pkg/evalexport"
+	"github.com/ca-srg/ragent/internal/pkg/opensearch"
+)
+
+// DefaultMigrationTopK is the number of results compared per query in a shadow comparison
+const DefaultMigrationTopK = 10
+
+// DefaultMigrationSample is the maximum number of distinct queries used in a shadow comparison
+const DefaultMigrationSample = 200
+
+// DefaultMinOverlap is the mean top-k overlap the candidate model needs before cutover
+const DefaultMinOverlap = 0.8
+
+// migrationFailurePreview is the number of failed documents listed when a cutover is refused
+const migrationFailurePreview = 5
+
+// ModelMigrateStartOptions holds the flags of `index migrate start`
+type ModelMigrateStartOptions struct {
+	Alias     string
+	Provider  string
+	Model     string
+	Dimension int
+	Vectorize VectorizeOptions
+}
+
+// ModelMigrateCompareOptions holds the flags of `index migrate compare` and `index migrate cutover`
+type ModelMigrateCompareOptions struct {
+	Alias       string
+	Queries     string
+	K           int
+	Sample      int
+	MinOverlap  float64
+	MinDocRatio float64
+}
+
+// ModelMigrateOptions holds the flags of `index migrate status` and `index migrate abort`
+type ModelMigrateOptions struct {
+	Alias string
+}
+
+// querySearcher returns the IDs of the top k documents for a query
+type querySearcher func(ctx context.Context, query string, k int) ([]string, error)
+
+// queryOverlap is the top-k overlap of a single query
+type queryOverlap struct {
+	Query   string
+	Overlap float64
+}
+
+// shadowReport summarizes a shadow comparison of the live and candidate models
+type shadowReport struct {
+	K       int
+	Queries int
+	Failed  int
+	Mean    float64
+	Min     float64
+	Worst   []queryOverlap
+}
+
+// RunModelMigrateStart creates a candidate index generation embedded with the target model,
+// records the migration so later vectorize runs dual-write into it, and backfills it from
+// every source. The alias keeps pointing at the live generation.
+func RunModelMigrateStart(cmd *cobra.Command, opts ModelMigrateStartOptions) error {
+	applyVectorizeOptions(opts.Vectorize)
+	// The backfill writes every document into the candidate index
+	dryRun = false
+	clearVectors = false
+	followMode = false
+	watchMode = false
+	resumeRun = false
+	resumeRunID = ""
+	forceProcess = true
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cfg, client, alias, err := openIndexAliasClient(opts.Alias)
+	if err != nil {
+		return err
+	}
+	if concurrency > 0 {
+		cfg.Concurrency = concurrency
+	}
+	cfg = client.ActiveEmbeddingConfig(ctx, cfg, alias)
+
+	store, err := hashstore.NewHashStore()
+	if err != nil {
+		return fmt.Errorf("failed to open hash store: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	existing, err := store.GetModelMigration(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("a model migration of %s into %s is already in progress; run `index migrate abort` first", alias, existing.CandidateIndex)
+	}
+
+	live, err := client.GetAliasIndices(ctx, alias)
+	if err != nil {
+		return fmt.Errorf("failed to resolve alias %s: %w", alias, err)
+	}
+	if len(live) == 0 {
+		return fmt.Errorf("%s is not an alias; run `index rebuild` first so the cutover can swap it", alias)
+	}
+
+	record := &hashstore.ModelMigrationRecord{
+		Alias:     alias,
+		Provider:  opts.Provider,
+		Model:     opts.Model,
+		Dimension: opts.Dimension,
+		StartedAt: time.Now(),
+	}
+	if record.Provider == "" {
+		record.Provider = cfg.EmbeddingProvider
+	}
+	if record.Model == "" {
+		return fmt.Errorf("--to-model is required")
+	}
+	if record.Provider == cfg.EmbeddingProvider && record.Model == cfg.EmbeddingModel && record.Dimension == cfg.EmbeddingDimension {
+		return fmt.Errorf("target model %s/%s is the current embedding model", record.Provider, record.Model)
+	}
+	record.CandidateIndex = generationName(alias, record.StartedAt)
+
+	candidateCfg := migrationConfig(cfg, record)
+	if _, err := embedding.NewEmbeddingClient(candidateCfg); err != nil {
+		return fmt.Errorf("failed to create embedding client for the target model: %w", err)
+	}
+	dimension := resolveEmbeddingDimension(candidateCfg)
+
+	indexer := vectorizer.NewOpenSearchIndexer(client, record.CandidateIndex, dimension)
+	indexer.SetIndexProfile(opensearch.NewKNNIndexProfileFromConfig(cfg))
+	log.Printf("Creating candidate index %s with %d-dimensional embedding field", record.CandidateIndex, dimension)
+	if err := indexer.CreateVectorIndexWithJapanese(ctx, record.CandidateIndex, dimension); err != nil {
+		return fmt.Errorf("failed to create index %s: %w", record.CandidateIndex, err)
+	}
+	if err := client.SetIndexEmbeddingModel(ctx, record.CandidateIndex, opensearch.EmbeddingModelFromConfig(candidateCfg)); err != nil {
+		discardGeneration(client, record.CandidateIndex)
+		return fmt.Errorf("failed to record the embedding model of %s: %w", record.CandidateIndex, err)
+	}
+
+	// Record the migration before the backfill so vectorize runs started meanwhile dual-write
+	if err := store.StartModelMigration(ctx, record); err != nil {
+		discardGeneration(client, record.CandidateIndex)
+		return err
+	}
+
+	result, err := executeVectorization(ctx, cfg, currentProgressCallback, record)
+	if err != nil {
+		discardGeneration(client, record.CandidateIndex)
+		if finishErr := store.FinishModelMigration(context.Background(), alias); finishErr != nil {
+			log.Printf("Warning: %v", finishErr)
+		}
+		return fmt.Errorf("failed to backfill candidate index %s: %w", record.CandidateIndex, err)
+	}
+	if result != nil {
+		printResults(result, false)
+	}
+
+	fmt.Printf("\n✅ Candidate index %s is embedded with %s/%s (%d dimensions)\n",
+		record.CandidateIndex, record.Provider, record.Model, dimension)
+	fmt.Printf("Alias %s still points to %s. Vectorize runs now also write into the candidate.\n", alias, strings.Join(live, ", "))
+	if result != nil && len(result.Errors) > 0 {
+		fmt.Printf("⚠️  %d documents failed and block the cutover; re-run `RAGent vectorize --force` for them or abort the migration\n", len(result.Errors))
+	}
+	fmt.Printf("Compare with: RAGent index migrate compare --alias %s --queries <eval export directory>\n", alias)
+	return nil
+}
+
+// RunModelMigrateCompare runs a shadow comparison of the live and candidate models
+func RunModelMigrateCompare(cmd *cobra.Command, opts ModelMigrateCompareOptions) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cfg, client, alias, err := openIndexAliasClient(opts.Alias)
+	if err != nil {
+		return err
+	}
+	cfg = client.ActiveEmbeddingConfig(ctx, cfg, alias)
+	migration, err := loadModelMigration(ctx, alias)
+	if err != nil {
+		return err
+	}
+
+	report, err := compareMigration(ctx, cfg, client, migration, opts)
+	if err != nil {
+		return err
+	}
+
+	minOverlap := resolveMinOverlap(opts.MinOverlap)
+	if report.Mean >= minOverlap {
+		fmt.Printf("\n✅ Mean overlap@%d %.3f meets --min-overlap %.2f; run `index migrate cutover` to switch\n", report.K, report.Mean, minOverlap)
+	} else {
+		fmt.Printf("\n❌ Mean overlap@%d %.3f is below --min-overlap %.2f; cutover would be refused\n", report.K, report.Mean, minOverlap)
+	}
+	return nil
+}
+
+// RunModelMigrateCutover repeats the shadow comparison and points the alias at the candidate
+// index only if its mean top-k overlap and document count meet the thresholds
+func RunModelMigrateCutover(cmd *cobra.Command, opts ModelMigrateCompareOptions) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cfg, client, alias, err := openIndexAliasClient(opts.Alias)
+	if err != nil {
+		return err
+	}
+
+	store, err := hashstore.NewHashStore()
+	if err != nil {
+		return fmt.Errorf("failed to open hash store: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	migration, err := store.GetModelMigration(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if migration == nil {
+		return fmt.Errorf("no model migration of %s is in progress; run `index migrate start` first", alias)
+	}
+	failures, err := store.ListModelMigrationFailures(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if err := checkMigrationFailures(migration, failures); err != nil {
+		return err
+	}
+
+	cfg = client.ActiveEmbeddingConfig(ctx, cfg, alias)
+	report, err := compareMigration(ctx, cfg, client, migration, opts)
+	if err != nil {
+		return err
+	}
+	if err := tagMigrationGenerations(ctx, client, cfg, migration); err != nil {
+		return err
+	}
+
+	minDocRatio := opts.MinDocRatio
+	if minDocRatio < 0 {
+		minDocRatio = DefaultMinDocRatio
+	}
+	previous, err := cutoverModelMigration(ctx, client, migration, report, resolveMinOverlap(opts.MinOverlap), minDocRatio)
+	if err != nil {
+		return err
+	}
+	if err := store.FinishModelMigration(ctx, alias); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	fmt.Printf("\n✅ Alias %s now points to %s (was %s)\n", alias, migration.CandidateIndex, strings.Join(previous, ", "))
+	fmt.Printf("Queries and vectorize runs on %s now embed with %s/%s, as recorded on the index.\n", alias, migration.Provider, migration.Model)
+	fmt.Println("Update EMBEDDING_PROVIDER, EMBEDDING_MODEL and EMBEDDING_DIMENSION to match when convenient.")
+	fmt.Printf("Roll back with `RAGent index rollback --alias %s`; queries follow the previous generation's model.\n", alias)
+	return nil
+}
+
+// RunModelMigrateStatus prints the model migration in progress for an alias
+func RunModelMigrateStatus(cmd *cobra.Command, opts ModelMigrateOptions) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cfg, client, alias, err := openIndexAliasClient(opts.Alias)
+	if err != nil {
+		return err
+	}
+	cfg = client.ActiveEmbeddingConfig(ctx, cfg, alias)
+
+	store, err := hashstore.NewHashStore()
+	if err != nil {
+		return fmt.Errorf("failed to open hash store: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	migration, err := store.GetModelMigration(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if migration == nil {
+		return fmt.Errorf("no model migration of %s is in progress; run `index migrate start` first", alias)
+	}
+	failures, err := store.ListModelMigrationFailures(ctx, alias)
+	if err != nil {
+		return err
+	}
+
+	live, err := client.GetAliasIndices(ctx, alias)
+	if err != nil {
+		return fmt.Errorf("failed to resolve alias %s: %w", alias, err)
+	}
+
+	fmt.Printf("\nModel migration of %s (started %s)\n", alias, migration.StartedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("  Live:      %s  %s/%s\n", strings.Join(live, ", "), cfg.EmbeddingProvider, cfg.EmbeddingModel)
+	fmt.Printf("  Candidate: %s  %s/%s\n", migration.CandidateIndex, migration.Provider, migration.Model)
+	if count, err := client.CountDocuments(ctx, alias); err == nil {
+		fmt.Printf("  Live documents:      %d\n", count)
+	}
+	if count, err := client.CountDocuments(ctx, migration.CandidateIndex); err == nil {
+		fmt.Printf("  Candidate documents: %d\n", count)
+	} else {
+		fmt.Printf("  Candidate documents: unavailable (%v)\n", err)
+	}
+	fmt.Printf("  Failed documents:    %d\n", len(failures))
+	for i, failure := range failures {
+		if i == migrationFailurePreview {
+			fmt.Printf("    ... and %d more\n", len(failures)-i)
+			break
+		}
+		fmt.Printf("    %s: %s\n", failure.SourcePath, failure.Error)
+	}
+	return nil
+}
+
+// RunModelMigrateAbort deletes the candidate index and stops dual-writing
+func RunModelMigrateAbort(cmd *cobra.Command, opts ModelMigrateOptions) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	_, client, alias, err := openIndexAliasClient(opts.Alias)
+	if err != nil {
+		return err
+	}
+
+	store, err := hashstore.NewHashStore()
+	if err != nil {
+		return fmt.Errorf("failed to open hash store: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	migration, err := store.GetModelMigration(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if migration == nil {
+		return fmt.Errorf("no model migration of %s is in progress", alias)
+	}
+
+	live, err := client.GetAliasIndices(ctx, alias)
+	if err != nil {
+		return fmt.Errorf("failed to resolve alias %s: %w", alias, err)
+	}
+	if containsString(live, migration.CandidateIndex) {
+		return fmt.Errorf("%s is serving alias %s and cannot be discarded", migration.CandidateIndex, alias)
+	}
+	if err := client.DeleteIndex(ctx, migration.CandidateIndex); err != nil {
+		return fmt.Errorf("failed to delete candidate index %s: %w", migration.CandidateIndex, err)
+	}
+	if err := store.FinishModelMigration(ctx, alias); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Model migration of %s aborted; deleted %s\n", alias, migration.CandidateIndex)
+	return nil
+}
+
+// cutoverModelMigration swaps the alias to the candidate index if the shadow comparison and the
+// document count pass. It returns the indices the alias pointed to before.
+func cutoverModelMigration(
+	ctx context.Context,
+	client indexAliasClient,
+	migration *hashstore.ModelMigrationRecord,
+	report *shadowReport,
+	minOverlap float64,
+	minDocRatio float64,
+) ([]string, error) {
+	alias := migration.Alias
+	if report.Mean < minOverlap {
+		return nil, fmt.Errorf("alias %s was left unchanged: mean overlap@%d %.3f is below --min-overlap %.2f",
+			alias, report.K, report.Mean, minOverlap)
+	}
+
+	live, err := client.GetAliasIndices(ctx, alias)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve alias %s: %w", alias, err)
+	}
+	if len(live) == 0 {
+		return nil, fmt.Errorf("%s is not an alias", alias)
+	}
+
+	oldCount, err := client.CountDocuments(ctx, alias)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents in %s: %w", alias, err)
+	}
+	if err := client.RefreshIndex(ctx, migration.CandidateIndex); err != nil {
+		return nil, fmt.Errorf("failed to refresh index %s: %w", migration.CandidateIndex, err)
+	}
+	newCount, err := client.CountDocuments(ctx, migration.CandidateIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents in %s: %w", migration.CandidateIndex, err)
+	}
+	if err := validateGenerationCount(oldCount, newCount, minDocRatio); err != nil {
+		return nil, fmt.Errorf("alias %s was left unchanged: %w", alias, err)
+	}
+
+	if err := client.UpdateAliases(ctx, opensearch.SwapAliasActions(alias, migration.CandidateIndex, live, "")); err != nil {
+		return nil, fmt.Errorf("failed to swap alias %s to %s: %w", alias, migration.CandidateIndex, err)
+	}
+	log.Printf("Alias %s swapped to %s", alias, migration.CandidateIndex)
+	return live, nil
+}
+
+// compareMigration embeds the query sample with both models and compares the top-k results of
+// the live alias and the candidate index
+func compareMigration(
+	ctx context.Context,
+	cfg *appconfig.Config,
+	client *opensearch.Client,
+	migration *hashstore.ModelMigrationRecord,
+	opts ModelMigrateCompareOptions,
+) (*shadowReport, error) {
+	if opts.Queries == "" {
+		return nil, fmt.Errorf("--queries is required")
+	}
+	k := opts.K
+	if k <= 0 {
+		k = DefaultMigrationTopK
+	}
+	sample := opts.Sample
+	if sample <= 0 {
+		sample = DefaultMigrationSample
+	}
+
+	queries, err := loadQuerySample(opts.Queries, sample)
+	if err != nil {
+		return nil, err
+	}
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("no queries found in %s", opts.Queries)
+	}
+
+	liveEmbedding, err := embedding.NewEmbeddingClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding client for the live model: %w", err)
+	}
+	candidateEmbedding, err := embedding.NewEmbeddingClient(migrationConfig(cfg, migration))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding client for the target model: %w", err)
+	}
+
+	log.Printf("Comparing top-%d results of %d queries: %s vs %s", k, len(queries), migration.Alias, migration.CandidateIndex)
+	report, err := shadowCompare(ctx, queries, k,
+		vectorSearcher(client, liveEmbedding, migration.Alias),
+		vectorSearcher(client, candidateEmbedding, migration.CandidateIndex),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	printShadowReport(migration, cfg, report)
+	return report, nil
+}
+
+// shadowCompare runs every query against both searchers and computes the top-k overlap.
+// Queries that fail on either side are counted and skipped.
+func shadowCompare(ctx context.Context, queries []string, k int, live, candidate querySearcher) (*shadowReport, error) {
+	report := &shadowReport{K: k, Min: 1}
+	var overlaps []queryOverlap
+	var total float64
+
+	for _, query := range queries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		liveIDs, err := live(ctx, query, k)
+		if err != nil {
+			log.Printf("Warning: live search failed for %q: %v", query, err)
+			report.Failed++
+			continue
+		}
+		candidateIDs, err := candidate(ctx, query, k)
+		if err != nil {
+			log.Printf("Warning: candidate search failed for %q: %v", query, err)
+			report.Failed++
+			continue
+		}
+
+		overlap := overlapAtK(liveIDs, candidateIDs, k)
+		overlaps = append(overlaps, queryOverlap{Query: query, Overlap: overlap})
+		total += overlap
+		if overlap < report.Min {
+			report.Min = overlap
+		}
+	}
+
+	if len(overlaps) == 0 {
+		return nil, fmt.Errorf("all %d queries failed", len(queries))
+	}
+
+	report.Queries = len(overlaps)
+	report.Mean = total / float64(len(overlaps))
+	sort.SliceStable(overlaps, func(i, j int) bool { return overlaps[i].Overlap < overlaps[j].Overlap })
+	if len(overlaps) > 5 {
+		overlaps = overlaps[:5]
+	}
+	report.Worst = overlaps
+	return report, nil
+}
+
+// overlapAtK returns the share of the top k documents both result lists have in common
+func overlapAtK(a, b []string, k int) float64 {
+	if len(a) > k {
+		a = a[:k]
+	}
+	if len(b) > k {
+		b = b[:k]
+	}
+	size := max(len(a), len(b))
+	if size == 0 {
+		return 1
+	}
+
+	seen := make(map[string]bool, len(a))
+	for _, id := range a {
+		seen[id] = true
+	}
+	common := 0
+	for _, id := range b {
+		if seen[id] {
+			common++
+			delete(seen, id)
+		}
+	}
+	return float64(common) / float64(size)
+}
+
+// vectorSearcher returns a querySearcher that embeds queries with embeddingClient and runs a
+// k-NN search on index
+func vectorSearcher(client *opensearch.Client, embeddingClient embedding.EmbeddingClient, index string) querySearcher {
+	return func(ctx context.Context, query string, k int) ([]string, error) {
+		vector, err := embeddingClient.GenerateEmbedding(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed query: %w", err)
+		}
+		resp, err := client.SearchDenseVector(ctx, index, &opensearch.VectorQuery{Vector: vector, K: k, Size: k})
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, 0, len(resp.Hits.Hits))
+		for _, hit := range resp.Hits.Hits {
+			ids = append(ids, hit.ID)
+		}
+		return ids, nil
+	}
+}
+
+// loadQuerySample reads up to limit distinct queries from an eval JSONL export file or from
+// every *.jsonl file in a directory, newest file first
+func loadQuerySample(path string, limit int) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queries: %w", err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = filepath.Glob(filepath.Join(path, "*.jsonl"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", path, err)
+		}
+		// Eval exports are named eval_YYYY-MM-DD.jsonl, so reverse order is newest first
+		sort.Sort(sort.Reverse(sort.StringSlice(files)))
+	}
+
+	seen := make(map[string]bool)
+	var queries []string
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", file, err)
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() && len(queries) < limit {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var record evalexport.EvalRecord
+			if err := json.Unmarshal([]byte(line), &record); err != nil {
+				log.Printf("Warning: skipping malformed line in %s: %v", file, err)
+				continue
+			}
+			query := strings.TrimSpace(record.UserInput)
+			if query == "" || seen[query] {
+				continue
+			}
+			seen[query] = true
+			queries = append(queries, query)
+		}
+		scanErr := scanner.Err()
+		_ = f.Close()
+		if scanErr != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, scanErr)
+		}
+		if len(queries) >= limit {
+			break
+		}
+	}
+	return queries, nil
+}
+
+// printShadowReport prints the result of a shadow comparison
+func printShadowReport(migration *hashstore.ModelMigrationRecord, cfg *appconfig.Config, report *shadowReport) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("SHADOW COMPARISON")
+	fmt.Println(strings.Repeat("=", 60))
+	fmt.Printf("Live:       %s (%s/%s)\n", migration.Alias, cfg.EmbeddingProvider, cfg.EmbeddingModel)
+	fmt.Printf("Candidate:  %s (%s/%s)\n", migration.CandidateIndex, migration.Provider, migration.Model)
+	fmt.Printf("Queries:    %d", report.Queries)
+	if report.Failed > 0 {
+		fmt.Printf(" (%d failed)", report.Failed)
+	}
+	fmt.Println()
+	fmt.Printf("Overlap@%d:  mean %.3f, min %.3f\n", report.K, report.Mean, report.Min)
+	if len(report.Worst) > 0 {
+		fmt.Println("\nLowest overlap:")
+		for _, q := range report.Worst {
+			fmt.Printf("  %.2f  %s\n", q.Overlap, q.Query)
+		}
+	}
+}
+
+// dualWriteMigrationCandidate writes the documents of a successful vectorize run into the
+// candidate index of a model migration in progress for index. Documents that fail are kept
+// in the migration's failure list, which blocks the cutover until they are re-embedded.
+func dualWriteMigrationCandidate(
+	ctx context.Context,
+	cfg *appconfig.Config,
+	store *hashstore.HashStore,
+	csvCfg *csv.Config,
+	index string,
+	files []*pkgdomain.FileInfo,
+) {
+	if len(files) == 0 {
+		return
+	}
+	if store == nil {
+		var err error
+		store, err = hashstore.NewHashStore()
+		if err != nil {
+			log.Printf("Warning: failed to open hash store, skipping model migration dual-write: %v", err)
+			return
+		}
+		defer func() { _ = store.Close() }()
+	}
+
+	migration, err := store.GetModelMigration(ctx, index)
+	if err != nil {
+		log.Printf("Warning: failed to look up model migration: %v", err)
+		return
+	}
+	if migration == nil {
+		return
+	}
+
+	var customPrompt string
+	if ocrPromptFile != "" {
+		if customPrompt, err = pdf.LoadOCRPromptFile(ocrPromptFile); err != nil {
+			recordMigrationFailures(ctx, store, migration, files, nil, err)
+			return
+		}
+	}
+	service, err := createCandidateVectorizerService(cfg, csvCfg, customPrompt, migration)
+	if err != nil {
+		recordMigrationFailures(ctx, store, migration, files, nil, fmt.Errorf("failed to create vectorizer: %w", err))
+		return
+	}
+	service.SetOCRCache(store)
+
+	log.Printf("Dual-writing %d files into candidate index %s (%s/%s)", len(files), migration.CandidateIndex, migration.Provider, migration.Model)
+	result, err := service.VectorizeFiles(ctx, files, false)
+	recordMigrationFailures(ctx, store, migration, files, result, err)
+}
+
+// recordMigrationFailures keeps the documents that could not be written into the candidate
+// index of a migration and clears the ones that were. runErr fails every file.
+func recordMigrationFailures(
+	ctx context.Context,
+	store *hashstore.HashStore,
+	migration *hashstore.ModelMigrationRecord,
+	files []*pkgdomain.FileInfo,
+	result *pkgdomain.ProcessingResult,
+	runErr error,
+) {
+	if store == nil {
+		return
+	}
+	interrupted := ctx.Err() != nil
+
+	// The run context may already be cancelled, so write the failures independently of it
+	storeCtx := context.Background()
+
+	failed := make(map[string]string)
+	if runErr != nil {
+		for _, f := range files {
+			failed[f.Path] = runErr.Error()
+		}
+	} else if result != nil {
+		for _, procErr := range result.Errors {
+			sourcePath := procErr.SourcePath
+			if sourcePath == "" {
+				sourcePath = procErr.FilePath
+			}
+			failed[sourcePath] = procErr.Message
+		}
+	}
+	for sourcePath, message := range failed {
+		if err := store.RecordModelMigrationFailure(storeCtx, migration.Alias, sourcePath, message); err != nil {
+			log.Printf("Warning: Failed to record migration failure of %s: %v", sourcePath, err)
+		}
+	}
+	if len(failed) > 0 {
+		log.Printf("Warning: %d files could not be written into candidate index %s; they block the cutover until re-embedded",
+			len(failed), migration.CandidateIndex)
+	}
+
+	if interrupted || runErr != nil {
+		return
+	}
+
+	// Only files already in the failure list need clearing, which avoids a delete per file
+	existing, err := store.ListModelMigrationFailures(storeCtx, migration.Alias)
+	if err != nil {
+		log.Printf("Warning: Failed to read migration failures: %v", err)
+		return
+	}
+	previouslyFailed := make(map[string]bool, len(existing))
+	for _, f := range existing {
+		previouslyFailed[f.SourcePath] = true
+	}
+	for _, f := range files {
+		if _, ok := failed[f.Path]; ok || !previouslyFailed[f.Path] {
+			continue
+		}
+		if err := store.ClearModelMigrationFailure(storeCtx, migration.Alias, f.Path); err != nil {
+			log.Printf("Warning: Failed to clear migration failure of %s: %v", f.Path, err)
+		}
+	}
+}
+
+// checkMigrationFailures refuses a cutover while documents are missing from the candidate index
+func checkMigrationFailures(migration *hashstore.ModelMigrationRecord, failures []*hashstore.ModelMigrationFailure) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	paths := make([]string, 0, migrationFailurePreview)
+	for i, failure := range failures {
+		if i == migrationFailurePreview {
+			paths = append(paths, "...")
+			break
+		}
+		paths = append(paths, failure.SourcePath)
+	}
+	return fmt.Errorf("alias %s was left unchanged: %d documents failed to be written into %s (%s); re-run `RAGent vectorize --force` for them or `index migrate abort`",
+		migration.Alias, len(failures), migration.CandidateIndex, strings.Join(paths, ", "))
+}
+
+// tagMigrationGenerations records the embedding model on the live and candidate generations of
+// a migration before the cutover, so queries follow the alias across cutover and rollback
+func tagMigrationGenerations(ctx context.Context, client *opensearch.Client, cfg *appconfig.Config, migration *hashstore.ModelMigrationRecord) error {
+	live, err := client.GetAliasIndices(ctx, migration.Alias)
+	if err != nil {
+		return fmt.Errorf("failed to resolve alias %s: %w", migration.Alias, err)
+	}
+	for _, index := range live {
+		model, err := client.GetIndexEmbeddingModel(ctx, index)
+		if err != nil {
+			return fmt.Errorf("failed to read the embedding model of %s: %w", index, err)
+		}
+		if model != nil {
+			continue
+		}
+		if err := client.SetIndexEmbeddingModel(ctx, index, opensearch.EmbeddingModelFromConfig(cfg)); err != nil {
+			return fmt.Errorf("failed to record the embedding model of %s: %w", index, err)
+		}
+	}
+
+	candidate := opensearch.EmbeddingModelFromConfig(migrationConfig(cfg, migration))
+	if err := client.SetIndexEmbeddingModel(ctx, migration.CandidateIndex, candidate); err != nil {
+		return fmt.Errorf("failed to record the embedding model of %s: %w", migration.CandidateIndex, err)
+	}
+	return nil
+}
+
+// createCandidateVectorizerService creates a vectorizer service that embeds with the target
+// model of a migration and writes only to its candidate index
+func createCandidateVectorizerService(
+	cfg *appconfig.Config,
+	csvCfg *csv.Config,
+	customPrompt string,
+	migration *hashstore.ModelMigrationRecord,
+) (*vectorizer.VectorizerService, error) {
+	candidateCfg := migrationConfig(cfg, migration)
+	embeddingClient, err := embedding.NewEmbeddingClient(candidateCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding client: %w", err)
+	}
+
+	return vectorizer.NewServiceFactory(candidateCfg).CreateVectorizerServiceWithCSVConfig(
+		embeddingClient,
+		discardVectorStore{},
+		metadata.NewMetadataExtractor(),
+		scanner.NewFileScanner(),
+		true,
+		migration.CandidateIndex,
+		csvCfg,
+		pdf.NewReaderFromConfig(cfg, customPrompt),
+	)
+}
+
+// migrationConfig returns a copy of cfg that embeds with the target model of a migration
+func migrationConfig(cfg *appconfig.Config, migration *hashstore.ModelMigrationRecord) *appconfig.Config {
+	candidate := *cfg
+	candidate.EmbeddingProvider = migration.Provider
+	candidate.EmbeddingModel = migration.Model
+	candidate.EmbeddingDimension = migration.Dimension
+	return &candidate
+}
+
+// loadModelMigration returns the migration in progress for an alias or an error if there is none
+func loadModelMigration(ctx context.Context, alias string) (*hashstore.ModelMigrationRecord, error) {
+	store, err := hashstore.NewHashStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open hash store: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	migration, err := store.GetModelMigration(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+	if migration == nil {
+		return nil, fmt.Errorf("no model migration of %s is in progress; run `index migrate start` first", alias)
+	}
+	return migration, nil
+}
+
+func resolveMinOverlap(minOverlap float64) float64 {
+	if minOverlap < 0 {
+		return DefaultMinOverlap
+	}
+	return minOverlap
+}
+
+// discardVectorStore drops every vector. Candidate indexes of a model migration live only in
+// OpenSearch, so the primary vector store keeps the live model's embeddings.
+type discardVectorStore struct{}
+
+func (discardVectorStore) StoreVector(ctx context.Context, vectorData *pkgdomain.VectorData) error {
+	return nil
+}
+
+func (discardVectorStore) ValidateAccess(ctx context.Context) error { return nil }
+
+func (discardVectorStore) ListVectors(ctx context.Context, prefix string) ([]string, error) {
+	return nil, nil
+}
+
+func (discardVectorStore) ListVectorsWithMetadata(ctx context.Context, prefix string) ([]pkgdomain.VectorListItem, error) {
+	return nil, nil
+}
+
+func (discardVectorStore) DeleteVector(ctx context.Context, vectorID string) error { return nil }
+
+func (discardVectorStore) GetBackendInfo(ctx context.Context) (map[string]interface{}, error) {
+	return map[string]interface{}{"backend": "none"}, nil
+}
+
+func (discardVectorStore) DeleteAllVectors(ctx context.Context) (int, error) { return 0, nil }
@@ -0,0 +1,170 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+func TestOverlapAtK(t *testing.T) {
+	assert.Equal(t, 1.0, overlapAtK([]string{"a", "b", "c"}, []string{"c", "b", "a"}, 3))
+	assert.InDelta(t, 2.0/3.0, overlapAtK([]string{"a", "b", "c"}, []string{"a", "b", "x"}, 3), 1e-9)
+	assert.Equal(t, 0.5, overlapAtK([]string{"a", "b", "c", "d"}, []string{"a", "x", "y", "b"}, 4))
+	assert.Equal(t, 0.5, overlapAtK([]string{"a", "b", "c"}, []string{"a", "x", "b"}, 2), "only the top k results count")
+	assert.Equal(t, 0.5, overlapAtK([]string{"a", "b"}, []string{"a"}, 10), "a shorter result list is a disagreement")
+	assert.Equal(t, 1.0, overlapAtK(nil, nil, 10))
+}
+
+func TestShadowCompare(t *testing.T) {
+	live := func(ctx context.Context, query string, k int) ([]string, error) {
+		return []string{query + "-1", query + "-2"}, nil
+	}
+	candidate := func(ctx context.Context, query string, k int) ([]string, error) {
+		switch query {
+		case "broken":
+			return nil, errors.New("search failed")
+		case "drift":
+			return []string{"other", query + "-1"}, nil
+		}
+		return []string{query + "-2", query + "-1"}, nil
+	}
+
+	report, err := shadowCompare(context.Background(), []string{"same", "drift", "broken"}, 2, live, candidate)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Queries)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 0.75, report.Mean)
+	assert.Equal(t, 0.5, report.Min)
+	require.NotEmpty(t, report.Worst)
+	assert.Equal(t, "drift", report.Worst[0].Query)
+
+	_, err = shadowCompare(context.Background(), []string{"broken"}, 2, live, candidate)
+	assert.Error(t, err)
+}
+
+func TestLoadQuerySample(t *testing.T) {
+	dir := t.TempDir()
+	older := strings.Join([]string{
+		`{"user_input":"old question"}`,
+		`{"user_input":"shared question"}`,
+	}, "\n")
+	newer := strings.Join([]string{
+		`{"user_input":"new question"}`,
+		``,
+		`not json`,
+		`{"user_input":"  shared question  "}`,
+		`{"user_input":""}`,
+	}, "\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "eval_2026-01-01.jsonl"), []byte(older), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "eval_2026-02-01.jsonl"), []byte(newer), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(`{"user_input":"ignored"}`), 0o644))
+
+	queries, err := loadQuerySample(dir, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new question", "shared question", "old question"}, queries)
+
+	queries, err = loadQuerySample(dir, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new question"}, queries)
+
+	queries, err = loadQuerySample(filepath.Join(dir, "eval_2026-01-01.jsonl"), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old question", "shared question"}, queries)
+
+	_, err = loadQuerySample(filepath.Join(dir, "missing"), 10)
+	assert.Error(t, err)
+}
+
+func TestCutoverModelMigration(t *testing.T) {
+	migration := &hashstore.ModelMigrationRecord{Alias: "docs", CandidateIndex: "docs-20260501000000"}
+	newClient := func(candidateDocs int64) *fakeAliasClient {
+		return &fakeAliasClient{
+			alias:   "docs",
+			counts:  map[string]int64{"docs-20260401000000": 100, "docs-20260501000000": candidateDocs},
+			aliased: []string{"docs-20260401000000"},
+		}
+	}
+
+	client := newClient(100)
+	_, err := cutoverModelMigration(context.Background(), client, migration, &shadowReport{K: 10, Mean: 0.6}, 0.8, 0.95)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--min-overlap")
+	assert.Nil(t, client.actions)
+
+	client = newClient(50)
+	_, err = cutoverModelMigration(context.Background(), client, migration, &shadowReport{K: 10, Mean: 0.9}, 0.8, 0.95)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "left unchanged")
+	assert.Equal(t, []string{"docs-20260401000000"}, client.aliased)
+
+	client = newClient(99)
+	previous, err := cutoverModelMigration(context.Background(), client, migration, &shadowReport{K: 10, Mean: 0.9}, 0.8, 0.95)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs-20260401000000"}, previous)
+	assert.Equal(t, []string{"docs-20260501000000"}, client.aliased)
+	assert.Contains(t, client.counts, "docs-20260401000000", "the previous generation is kept for rollback")
+}
+
+func TestMigrationConfig(t *testing.T) {
+	cfg := &appconfig.Config{EmbeddingProvider: "bedrock", EmbeddingModel: "amazon.titan-embed-text-v1", Concurrency: 4}
+	migration := &hashstore.ModelMigrationRecord{
+		Provider:  "gemini",
+		Model:     "gemini-embedding-001",
+		Dimension: 768,
+		StartedAt: time.Now(),
+	}
+
+	candidate := migrationConfig(cfg, migration)
+	assert.Equal(t, "gemini", candidate.EmbeddingProvider)
+	assert.Equal(t, "gemini-embedding-001", candidate.EmbeddingModel)
+	assert.Equal(t, 768, candidate.EmbeddingDimension)
+	assert.Equal(t, 4, candidate.Concurrency)
+	assert.Equal(t, "amazon.titan-embed-text-v1", cfg.EmbeddingModel, "the live configuration is not modified")
+}
+
+func TestRecordMigrationFailures(t *testing.T) {
+	ctx := context.Background()
+	store := newTestHashStore(t)
+	migration := &hashstore.ModelMigrationRecord{Alias: "docs", CandidateIndex: "docs-20260501000000", Model: "m", StartedAt: time.Now()}
+	require.NoError(t, store.StartModelMigration(ctx, migration))
+
+	a := &pkgdomain.FileInfo{Path: "docs/a.md"}
+	b := &pkgdomain.FileInfo{Path: "docs/b.md"}
+	files := []*pkgdomain.FileInfo{a, b}
+
+	recordMigrationFailures(ctx, store, migration, files, nil, errors.New("embedding model unavailable"))
+	failures, err := store.ListModelMigrationFailures(ctx, "docs")
+	require.NoError(t, err)
+	require.Len(t, failures, 2, "a failed run fails every file")
+
+	recordMigrationFailures(ctx, store, migration, files, &pkgdomain.ProcessingResult{
+		Errors: []pkgdomain.ProcessingError{{FilePath: "docs/b.md#2", SourcePath: "docs/b.md", Message: "throttled"}},
+	}, nil)
+	failures, err = store.ListModelMigrationFailures(ctx, "docs")
+	require.NoError(t, err)
+	require.Len(t, failures, 1)
+	assert.Equal(t, "docs/b.md", failures[0].SourcePath)
+	assert.Equal(t, "throttled", failures[0].Error)
+
+	err = checkMigrationFailures(migration, failures)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "left unchanged")
+	assert.Contains(t, err.Error(), "docs/b.md")
+
+	recordMigrationFailures(ctx, store, migration, []*pkgdomain.FileInfo{b}, &pkgdomain.ProcessingResult{SuccessCount: 1}, nil)
+	failures, err = store.ListModelMigrationFailures(ctx, "docs")
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+	assert.NoError(t, checkMigrationFailures(migration, failures))
+}
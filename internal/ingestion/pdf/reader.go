@@ -2,6 +2,9 @@ package pdf
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
@@ -17,6 +20,7 @@ import (
 type Reader struct {
 	client OCRClient
 	config PDFReaderConfig
+	cache  ResultCache
 }
 
 // NewReader creates a new PDF Reader with the given OCR client and configuration.
@@ -27,6 +31,21 @@ func NewReader(client OCRClient, config PDFReaderConfig) *Reader {
 	}
 }
 
+// SetCache enables caching of OCR results. Pass nil to disable caching.
+func (r *Reader) SetCache(cache ResultCache) {
+	r.cache = cache
+}
+
+// Model returns the OCR model configured for the reader
+func (r *Reader) Model() string {
+	return r.config.Model
+}
+
+// CountPages returns the number of pages in a PDF without running OCR, or 0 if it cannot be parsed
+func CountPages(pdfData []byte) int {
+	return countPDFPages(pdfData)
+}
+
 // ReadFile reads a local PDF file and returns one FileInfo per page.
 func (r *Reader) ReadFile(filePath string) ([]*pkgdomain.FileInfo, error) {
 	stat, err := os.Stat(filePath)
@@ -58,8 +77,7 @@ func (r *Reader) readFileFromBytesWithModTime(pdfData []byte, filePath string, m
 
 	filename := filepath.Base(filePath)
 
-	log.Printf("Running OCR on PDF: %s (%d bytes)", filePath, len(pdfData))
-	pages, err := r.client.ExtractPages(ctx, pdfData, filename)
+	pages, err := r.extractPages(ctx, pdfData, filePath, filename)
 	if err != nil {
 		return nil, &pkgdomain.ProcessingError{
 			Type:      pkgconfig.ErrorTypeOCR,
@@ -99,6 +117,51 @@ func (r *Reader) readFileFromBytesWithModTime(pdfData []byte, filePath string, m
 	return files, nil
 }
 
+// extractPages runs OCR on the PDF, serving and storing results through the cache when one is set.
+func (r *Reader) extractPages(ctx context.Context, pdfData []byte, filePath, filename string) ([]*PageResult, error) {
+	var key string
+	if r.cache != nil {
+		key = r.cacheKey(pdfData)
+		data, found, err := r.cache.GetOCRResult(ctx, key)
+		if err != nil {
+			log.Printf("Warning: failed to read OCR cache for %s: %v", filePath, err)
+		} else if found {
+			var pages []*PageResult
+			if err := json.Unmarshal(data, &pages); err == nil {
+				log.Printf("Using cached OCR result for PDF: %s (%d pages)", filePath, len(pages))
+				return pages, nil
+			}
+			log.Printf("Warning: ignoring corrupt OCR cache entry for %s", filePath)
+		}
+	}
+
+	log.Printf("Running OCR on PDF: %s (%d bytes)", filePath, len(pdfData))
+	pages, err := r.client.ExtractPages(ctx, pdfData, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.cache != nil && len(pages) > 0 {
+		data, err := json.Marshal(pages)
+		if err == nil {
+			err = r.cache.PutOCRResult(ctx, key, data)
+		}
+		if err != nil {
+			log.Printf("Warning: failed to cache OCR result for %s: %v", filePath, err)
+		}
+	}
+
+	return pages, nil
+}
+
+// cacheKey identifies an OCR result by PDF content and the settings that influence the output.
+func (r *Reader) cacheKey(pdfData []byte) string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s\x00%s\x00%s\x00", r.config.Provider, r.config.Model, r.config.CustomPrompt)
+	_, _ = h.Write(pdfData)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // pageToFileInfo converts a PageResult to a domain.FileInfo.
 func (r *Reader) pageToFileInfo(page *PageResult, filePath string, fileTime time.Time, coverAuthor string) *pkgdomain.FileInfo {
 	if page == nil || strings.TrimSpace(page.Text) == "" {
@@ -135,6 +198,7 @@ func (r *Reader) pageToFileInfo(page *PageResult, filePath string, fileTime time
 		IsPDF:        true,
 		PDFPageIndex: page.PageIndex,
 		Content:      page.Text,
+		SourcePath:   filePath,
 		Metadata: pkgdomain.DocumentMetadata{
 			Title:     title,
 			Category:  category,
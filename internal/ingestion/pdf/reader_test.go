@@ -322,3 +322,77 @@ func TestReader_ReadFileFromBytes_SizeIsTextLength(t *testing.T) {
 	// Size should be the length of the text content
 	assert.Equal(t, int64(len("twelve chars")), files[0].Size)
 }
+
+type memoryResultCache struct {
+	entries map[string][]byte
+}
+
+func (c *memoryResultCache) GetOCRResult(ctx context.Context, key string) ([]byte, bool, error) {
+	data, ok := c.entries[key]
+	return data, ok, nil
+}
+
+func (c *memoryResultCache) PutOCRResult(ctx context.Context, key string, data []byte) error {
+	c.entries[key] = data
+	return nil
+}
+
+func TestReader_ReadFileFromBytes_UsesCache(t *testing.T) {
+	calls := 0
+	mockClient := &MockOCRClient{
+		ExtractPagesFunc: func(ctx context.Context, pdfData []byte, filename string) ([]*PageResult, error) {
+			calls++
+			return []*PageResult{
+				{PageIndex: 1, Text: "Page 1 content", Title: "Cached Doc"},
+			}, nil
+		},
+	}
+	cache := &memoryResultCache{entries: make(map[string][]byte)}
+	reader := NewReader(mockClient, newTestReaderConfig())
+	reader.SetCache(cache)
+
+	first, err := reader.ReadFileFromBytes([]byte("fake pdf bytes"), "/path/to/test.pdf")
+	require.NoError(t, err)
+	second, err := reader.ReadFileFromBytes([]byte("fake pdf bytes"), "/path/to/copy.pdf")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, calls, "second read should be served from the cache")
+	require.Len(t, second, 1)
+	assert.Equal(t, first[0].Content, second[0].Content)
+	assert.Equal(t, "Cached Doc", second[0].Metadata.Title)
+	assert.Equal(t, "/path/to/copy.pdf", second[0].SourcePath)
+
+	// Different content or OCR settings must miss the cache
+	_, err = reader.ReadFileFromBytes([]byte("other pdf bytes"), "/path/to/other.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	otherModel := newTestReaderConfig()
+	otherModel.Model = "other-model"
+	otherReader := NewReader(mockClient, otherModel)
+	otherReader.SetCache(cache)
+	_, err = otherReader.ReadFileFromBytes([]byte("fake pdf bytes"), "/path/to/test.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestReader_ReadFileFromBytes_DoesNotCacheFailures(t *testing.T) {
+	calls := 0
+	mockClient := &MockOCRClient{
+		ExtractPagesFunc: func(ctx context.Context, pdfData []byte, filename string) ([]*PageResult, error) {
+			calls++
+			return nil, errors.New("throttled")
+		},
+	}
+	cache := &memoryResultCache{entries: make(map[string][]byte)}
+	reader := NewReader(mockClient, newTestReaderConfig())
+	reader.SetCache(cache)
+
+	_, err := reader.ReadFileFromBytes([]byte("fake pdf bytes"), "/path/to/test.pdf")
+	require.Error(t, err)
+	_, err = reader.ReadFileFromBytes([]byte("fake pdf bytes"), "/path/to/test.pdf")
+	require.Error(t, err)
+
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, cache.entries)
+}
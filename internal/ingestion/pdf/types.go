@@ -35,3 +35,13 @@ type PDFReaderConfig struct {
 	Concurrency  int // Number of concurrent OCR requests (for page-level parallelism)
 	CustomPrompt string
 }
+
+// ResultCache stores OCR results so that unchanged PDFs are not sent to the OCR provider again.
+// Keys are derived from the PDF content and the OCR settings.
+type ResultCache interface {
+	// GetOCRResult returns the cached result for key, and whether it was found.
+	GetOCRResult(ctx context.Context, key string) ([]byte, bool, error)
+
+	// PutOCRResult stores the result for key.
+	PutOCRResult(ctx context.Context, key string, data []byte) error
+}
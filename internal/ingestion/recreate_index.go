@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/ca-srg/ragent/internal/ingestion/vectorizer"
@@ -48,6 +49,12 @@ func RunRecreateIndex() error {
 		indexName = "kiberag-vectors"
 	}
 
+	// Aliased indexes are rebuilt side by side instead of being deleted
+	if aliased, err := osClient.GetAliasIndices(ctx, indexName); err == nil && len(aliased) > 0 {
+		return fmt.Errorf("%s is an alias of %s; use `RAGent index rebuild` to rebuild it without downtime",
+			indexName, strings.Join(aliased, ", "))
+	}
+
 	embeddingClient, embErr := embedding.NewEmbeddingClient(cfg)
 	embDimension := 768
 	if embErr == nil {
@@ -58,6 +65,7 @@ func RunRecreateIndex() error {
 	}
 
 	indexer := vectorizer.NewOpenSearchIndexer(osClient, indexName, embDimension)
+	indexer.SetIndexProfile(opensearch.NewKNNIndexProfileFromConfig(cfg))
 
 	// Check if index exists
 	exists, err := indexer.IndexExists(ctx, indexName)
@@ -0,0 +1,178 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+// runCheckpointer persists the progress of a vectorize run in the hash store.
+// Completed documents are recorded under the run ID, and source files are written
+// to the file hash table as soon as all of their documents succeed, so an
+// interrupted run does not lose the work finished before it died.
+type runCheckpointer struct {
+	store     *hashstore.HashStore
+	runID     string
+	completed map[string]string // Document path to the content hash it was completed at
+	mu        sync.Mutex        // serializes SQLite writes from concurrent workers
+}
+
+// IsCompleted reports whether the document was completed by the run being resumed from
+// the same content. Documents of files edited since the interruption are not skipped.
+func (c *runCheckpointer) IsCompleted(documentPath, contentHash string) bool {
+	completedHash, ok := c.completed[documentPath]
+	return ok && completedHash == contentHash
+}
+
+// DocumentCompleted records a document checkpoint for the run
+func (c *runCheckpointer) DocumentCompleted(ctx context.Context, document *pkgdomain.FileInfo, contentHash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.store.MarkDocumentCompleted(ctx, c.runID, document.Path, contentHash); err != nil {
+		log.Printf("Warning: Failed to checkpoint %s: %v", document.Path, err)
+	}
+}
+
+// SourceCompleted updates the file hash of a source file whose documents all succeeded
+func (c *runCheckpointer) SourceCompleted(ctx context.Context, source *pkgdomain.FileInfo) {
+	if source.ContentHash == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.store.UpsertFileHash(ctx, newFileHashRecord(source)); err != nil {
+		log.Printf("Warning: Failed to update hash for %s: %v", source.Path, err)
+	}
+}
+
+// startVectorizeRun registers a run for checkpointing.
+// With --resume the most recent unfinished run for the same sources (or the run given
+// by --resume-run) is continued; otherwise a new run is started and older unfinished
+// runs for the same sources are superseded.
+func startVectorizeRun(ctx context.Context, store *hashstore.HashStore, sources string) (*runCheckpointer, error) {
+	if resumeRun || resumeRunID != "" {
+		run, err := findRunToResume(ctx, store, sources)
+		if err != nil {
+			return nil, err
+		}
+		if run != nil {
+			completed, err := store.GetCompletedDocuments(ctx, run.RunID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load checkpoints of run %s: %w", run.RunID, err)
+			}
+			if err := store.ResumeRun(ctx, run.RunID); err != nil {
+				return nil, err
+			}
+			log.Printf("Resuming vectorize run %s started at %s (%d documents already completed)",
+				run.RunID, run.StartedAt.Format(time.RFC3339), len(completed))
+			return &runCheckpointer{store: store, runID: run.RunID, completed: completed}, nil
+		}
+		log.Println("No interrupted run found for these sources, starting a new run")
+	}
+
+	runID := newRunID()
+	if err := store.CreateRun(ctx, runID, sources); err != nil {
+		return nil, err
+	}
+	if superseded, err := store.SupersedeRuns(ctx, sources, runID); err != nil {
+		log.Printf("Warning: Failed to supersede unfinished runs: %v", err)
+	} else if superseded > 0 {
+		log.Printf("Discarded checkpoints of %d unfinished run(s); use --resume to continue an interrupted run", superseded)
+	}
+	log.Printf("Vectorize run ID: %s", runID)
+	return &runCheckpointer{store: store, runID: runID, completed: map[string]string{}}, nil
+}
+
+// findRunToResume looks up the run selected by --resume-run, or the latest unfinished run for sources
+func findRunToResume(ctx context.Context, store *hashstore.HashStore, sources string) (*hashstore.VectorizeRunRecord, error) {
+	if resumeRunID == "" {
+		return store.FindResumableRun(ctx, sources)
+	}
+
+	run, err := store.GetRun(ctx, resumeRunID)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, fmt.Errorf("vectorize run %s not found", resumeRunID)
+	}
+	if !run.Status.Resumable() {
+		return nil, fmt.Errorf("vectorize run %s is %s and cannot be resumed", run.RunID, run.Status)
+	}
+	if run.Sources != sources {
+		log.Printf("Warning: Run %s covered different sources (%s)", run.RunID, run.Sources)
+	}
+	return run, nil
+}
+
+// finishVectorizeRun records the outcome of a run. Runs that did not complete cleanly stay resumable.
+func finishVectorizeRun(ctx context.Context, cp *runCheckpointer, result *pkgdomain.ProcessingResult, runErr error) {
+	if cp == nil {
+		return
+	}
+
+	status := hashstore.RunStatusCompleted
+	switch {
+	case ctx.Err() != nil:
+		status = hashstore.RunStatusInterrupted
+	case runErr != nil, result != nil && result.FailureCount > 0:
+		status = hashstore.RunStatusFailed
+	}
+
+	// The run context may already be cancelled, so record the status independently of it
+	if err := cp.store.FinishRun(context.Background(), cp.runID, status); err != nil {
+		log.Printf("Warning: Failed to record status of run %s: %v", cp.runID, err)
+		return
+	}
+	if status != hashstore.RunStatusCompleted {
+		log.Printf("Vectorize run %s %s; continue it with: ragent vectorize --resume", cp.runID, status)
+	}
+}
+
+// describeSources builds the key used to match a resumed run with the sources of the current invocation
+func describeSources() string {
+	var parts []string
+	if directory != "" {
+		dir := directory
+		if abs, err := filepath.Abs(directory); err == nil {
+			dir = abs
+		}
+		parts = append(parts, "local:"+dir)
+	}
+	if enableS3 {
+		parts = append(parts, fmt.Sprintf("s3:%s/%s", s3Bucket, s3Prefix))
+	}
+	if githubRepos != "" {
+		parts = append(parts, "github:"+githubRepos)
+	}
+	return strings.Join(parts, ";")
+}
+
+// newRunID returns a sortable, unique identifier for a vectorize run
+func newRunID() string {
+	return fmt.Sprintf("%s-%s", time.Now().Format("20060102-150405"), uuid.New().String()[:8])
+}
+
+// newFileHashRecord builds the hash store record for a successfully vectorized file
+func newFileHashRecord(f *pkgdomain.FileInfo) *hashstore.FileHashRecord {
+	sourceType := f.SourceType
+	if sourceType == "" {
+		sourceType = "local"
+	}
+	return &hashstore.FileHashRecord{
+		SourceType:   sourceType,
+		FilePath:     f.Path,
+		ContentHash:  f.ContentHash,
+		FileSize:     f.Size,
+		VectorizedAt: time.Now(),
+	}
+}
@@ -0,0 +1,159 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+func newTestHashStore(t *testing.T) *hashstore.HashStore {
+	t.Helper()
+	store, err := hashstore.NewHashStoreWithPath(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func setResumeFlags(t *testing.T, resume bool, runID string) {
+	t.Helper()
+	prevResume, prevRunID := resumeRun, resumeRunID
+	resumeRun, resumeRunID = resume, runID
+	t.Cleanup(func() { resumeRun, resumeRunID = prevResume, prevRunID })
+}
+
+func TestStartVectorizeRun_ResumesInterruptedRun(t *testing.T) {
+	ctx := context.Background()
+	store := newTestHashStore(t)
+	setResumeFlags(t, false, "")
+
+	first, err := startVectorizeRun(ctx, store, "local:/docs")
+	require.NoError(t, err)
+	first.DocumentCompleted(ctx, &pkgdomain.FileInfo{Path: "pdf:///docs/a.pdf/page/1"}, "hash-a")
+	first.SourceCompleted(ctx, &pkgdomain.FileInfo{Path: "/docs/b.md", ContentHash: "hash-b", Size: 10})
+
+	// The hash of a completed source is written immediately, not at the end of the run
+	record, err := store.GetFileHash(ctx, "local", "/docs/b.md")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "hash-b", record.ContentHash)
+
+	// The process dies here: the run is never finished
+	setResumeFlags(t, true, "")
+	resumed, err := startVectorizeRun(ctx, store, "local:/docs")
+	require.NoError(t, err)
+	assert.Equal(t, first.runID, resumed.runID)
+	assert.True(t, resumed.IsCompleted("pdf:///docs/a.pdf/page/1", "hash-a"))
+	assert.False(t, resumed.IsCompleted("pdf:///docs/a.pdf/page/2", "hash-a"))
+
+	finishVectorizeRun(ctx, resumed, &pkgdomain.ProcessingResult{SuccessCount: 1}, nil)
+	run, err := store.GetRun(ctx, first.runID)
+	require.NoError(t, err)
+	assert.Equal(t, hashstore.RunStatusCompleted, run.Status)
+
+	// Nothing is left to resume once the run completed
+	next, err := startVectorizeRun(ctx, store, "local:/docs")
+	require.NoError(t, err)
+	assert.NotEqual(t, first.runID, next.runID)
+	assert.Empty(t, next.completed)
+}
+
+func TestStartVectorizeRun_ResumeReprocessesEditedFiles(t *testing.T) {
+	ctx := context.Background()
+	store := newTestHashStore(t)
+	setResumeFlags(t, false, "")
+
+	first, err := startVectorizeRun(ctx, store, "local:/docs")
+	require.NoError(t, err)
+	first.DocumentCompleted(ctx, &pkgdomain.FileInfo{Path: "/docs/a.md"}, "hash-a1")
+	first.DocumentCompleted(ctx, &pkgdomain.FileInfo{Path: "/docs/b.md"}, "hash-b1")
+
+	// The process dies, then a.md is edited before the run is resumed
+	setResumeFlags(t, true, "")
+	resumed, err := startVectorizeRun(ctx, store, "local:/docs")
+	require.NoError(t, err)
+	require.Equal(t, first.runID, resumed.runID)
+	assert.False(t, resumed.IsCompleted("/docs/a.md", "hash-a2"), "an edited file must be vectorized again")
+	assert.True(t, resumed.IsCompleted("/docs/b.md", "hash-b1"))
+
+	// Its hash is only recorded once the new content is processed
+	resumed.DocumentCompleted(ctx, &pkgdomain.FileInfo{Path: "/docs/a.md"}, "hash-a2")
+	resumed.SourceCompleted(ctx, &pkgdomain.FileInfo{Path: "/docs/a.md", ContentHash: "hash-a2", Size: 10})
+	completed, err := store.GetCompletedDocuments(ctx, first.runID)
+	require.NoError(t, err)
+	assert.Equal(t, "hash-a2", completed["/docs/a.md"])
+	record, err := store.GetFileHash(ctx, "local", "/docs/a.md")
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "hash-a2", record.ContentHash)
+}
+
+func TestStartVectorizeRun_NewRunSupersedesUnfinished(t *testing.T) {
+	ctx := context.Background()
+	store := newTestHashStore(t)
+	setResumeFlags(t, false, "")
+
+	first, err := startVectorizeRun(ctx, store, "local:/docs")
+	require.NoError(t, err)
+	second, err := startVectorizeRun(ctx, store, "local:/docs")
+	require.NoError(t, err)
+
+	run, err := store.GetRun(ctx, first.runID)
+	require.NoError(t, err)
+	assert.Equal(t, hashstore.RunStatusSuperseded, run.Status)
+
+	setResumeFlags(t, false, first.runID)
+	_, err = startVectorizeRun(ctx, store, "local:/docs")
+	assert.ErrorContains(t, err, "cannot be resumed")
+
+	setResumeFlags(t, false, second.runID)
+	resumed, err := startVectorizeRun(ctx, store, "local:/docs")
+	require.NoError(t, err)
+	assert.Equal(t, second.runID, resumed.runID)
+
+	setResumeFlags(t, false, "missing")
+	_, err = startVectorizeRun(ctx, store, "local:/docs")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestFinishVectorizeRun_Status(t *testing.T) {
+	tests := []struct {
+		name     string
+		cancel   bool
+		result   *pkgdomain.ProcessingResult
+		runErr   error
+		expected hashstore.RunStatus
+	}{
+		{name: "completed", result: &pkgdomain.ProcessingResult{SuccessCount: 2}, expected: hashstore.RunStatusCompleted},
+		{name: "failures", result: &pkgdomain.ProcessingResult{SuccessCount: 1, FailureCount: 1}, expected: hashstore.RunStatusFailed},
+		{name: "error", runErr: errors.New("boom"), expected: hashstore.RunStatusFailed},
+		{name: "cancelled", cancel: true, result: &pkgdomain.ProcessingResult{}, expected: hashstore.RunStatusInterrupted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestHashStore(t)
+			setResumeFlags(t, false, "")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cp, err := startVectorizeRun(ctx, store, "local:/docs")
+			require.NoError(t, err)
+
+			if tt.cancel {
+				cancel()
+			}
+			finishVectorizeRun(ctx, cp, tt.result, tt.runErr)
+
+			run, err := store.GetRun(context.Background(), cp.runID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, run.Status)
+		})
+	}
+}
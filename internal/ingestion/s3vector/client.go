@@ -16,6 +16,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3vectors/document"
 	"github.com/aws/aws-sdk-go-v2/service/s3vectors/types"
 	"github.com/aws/smithy-go"
+	smithydocument "github.com/aws/smithy-go/document"
 	smithyhttp "github.com/aws/smithy-go/transport/http"
 
 	"github.com/ca-srg/ragent/internal/pkg/domain"
@@ -168,6 +169,12 @@ func (s *S3VectorService) StoreVector(ctx context.Context, vectorData *domain.Ve
 	}
 
 	metadataMap["secret"] = vectorData.Metadata.Secret
+	if vectorData.Metadata.Tenant != "" {
+		metadataMap["tenant"] = vectorData.Metadata.Tenant
+	}
+	if len(vectorData.Metadata.AllowedGroups) > 0 {
+		metadataMap["allowed_groups"] = vectorData.Metadata.AllowedGroups
+	}
 
 	vectorMetadata := document.NewLazyDocument(metadataMap)
 
@@ -387,6 +394,133 @@ func (s *S3VectorService) ListVectorsWithMetadata(
 	return items, nil
 }
 
+// ExportVectors returns a page of up to limit vectors with their data and metadata.
+// cursor is the NextToken of the previous page ("" for the first page); the returned
+// cursor is "" when there are no more vectors. Content is the stored excerpt.
+func (s *S3VectorService) ExportVectors(
+	ctx context.Context, cursor string, limit int,
+) ([]*domain.VectorData, string, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 500
+	}
+
+	input := &s3vectors.ListVectorsInput{
+		VectorBucketName: aws.String(s.vectorBucketName),
+		IndexName:        aws.String(s.indexName),
+		MaxResults:       aws.Int32(int32(limit)),
+		ReturnData:       true,
+		ReturnMetadata:   true,
+	}
+	if cursor != "" {
+		input.NextToken = aws.String(cursor)
+	}
+
+	var result *s3vectors.ListVectorsOutput
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		var err error
+		result, err = s.client.ListVectors(ctx, input)
+		if err == nil {
+			break
+		}
+		if !s.isTooManyRequestsError(err) || attempt == s.maxRetries {
+			return nil, "", fmt.Errorf("failed to list vectors: %w", err)
+		}
+		if err := s.waitForRetry(ctx, s.calculateBackoffDelay(attempt+1)); err != nil {
+			return nil, "", fmt.Errorf("context cancelled while waiting to retry S3 Vectors listing: %w", err)
+		}
+	}
+
+	vectors := make([]*domain.VectorData, 0, len(result.Vectors))
+	for _, v := range result.Vectors {
+		vd := &domain.VectorData{}
+		if v.Key != nil {
+			vd.ID = *v.Key
+		}
+		if data, ok := v.Data.(*types.VectorDataMemberFloat32); ok {
+			vd.Embedding = make([]float64, len(data.Value))
+			for i, f := range data.Value {
+				vd.Embedding[i] = float64(f)
+			}
+		}
+		if v.Metadata != nil {
+			var md map[string]interface{}
+			if err := v.Metadata.UnmarshalSmithyDocument(&md); err == nil {
+				applyExportedMetadata(vd, md)
+			}
+		}
+		vectors = append(vectors, vd)
+	}
+
+	next := ""
+	if result.NextToken != nil {
+		next = *result.NextToken
+	}
+	return vectors, next, nil
+}
+
+// applyExportedMetadata fills vd from the metadata written by StoreVector.
+// Keys StoreVector does not write itself are returned as custom fields.
+func applyExportedMetadata(vd *domain.VectorData, md map[string]interface{}) {
+	for key, value := range md {
+		switch key {
+		case "title":
+			vd.Metadata.Title, _ = value.(string)
+		case "category":
+			vd.Metadata.Category, _ = value.(string)
+		case "file_path":
+			vd.Metadata.FilePath, _ = value.(string)
+		case "reference":
+			vd.Metadata.Reference, _ = value.(string)
+		case "author":
+			vd.Metadata.Author, _ = value.(string)
+		case "content_excerpt":
+			vd.Content, _ = value.(string)
+		case "secret":
+			vd.Metadata.Secret, _ = value.(bool)
+		case "tenant":
+			vd.Metadata.Tenant, _ = value.(string)
+		case "word_count":
+			switch wc := value.(type) {
+			case float64:
+				vd.Metadata.WordCount = int(wc)
+			case smithydocument.Number:
+				if n, err := wc.Int64(); err == nil {
+					vd.Metadata.WordCount = int(n)
+				}
+			}
+		case "created_at":
+			if str, ok := value.(string); ok {
+				if t, err := time.Parse(time.RFC3339, str); err == nil {
+					vd.CreatedAt = t
+					vd.Metadata.CreatedAt = t
+					vd.Metadata.UpdatedAt = t
+				}
+			}
+		case "tags":
+			if tags, ok := value.([]interface{}); ok {
+				for _, tag := range tags {
+					if str, ok := tag.(string); ok {
+						vd.Metadata.Tags = append(vd.Metadata.Tags, str)
+					}
+				}
+			}
+		case "allowed_groups":
+			if groups, ok := value.([]interface{}); ok {
+				for _, group := range groups {
+					if str, ok := group.(string); ok {
+						vd.Metadata.AllowedGroups = append(vd.Metadata.AllowedGroups, str)
+					}
+				}
+			}
+		default:
+			if vd.Metadata.CustomFields == nil {
+				vd.Metadata.CustomFields = map[string]interface{}{}
+			}
+			vd.Metadata.CustomFields[key] = value
+		}
+	}
+}
+
 // DeleteVector removes a vector from S3 Vectors
 func (s *S3VectorService) DeleteVector(ctx context.Context, vectorID string) error {
 	if vectorID == "" {
@@ -0,0 +1,116 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+)
+
+// GitStatus is the single-letter status reported by `git diff --name-status`.
+type GitStatus string
+
+const (
+	GitStatusAdded    GitStatus = "A"
+	GitStatusModified GitStatus = "M"
+	GitStatusDeleted  GitStatus = "D"
+	GitStatusRenamed  GitStatus = "R"
+)
+
+// GitFileChange is one entry of a name-status diff between two commits.
+// OldPath is only set for renames. Paths are slash-separated and relative
+// to the repository root.
+type GitFileChange struct {
+	Status  GitStatus
+	Path    string
+	OldPath string
+}
+
+// ResolveHead returns the checked-out branch name and HEAD commit of the clone at repoDir.
+func ResolveHead(repoDir string) (string, string, error) {
+	r, err := git.PlainOpen(repoDir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	head, err := r.Head()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	branch := "HEAD"
+	if head.Name().IsBranch() {
+		branch = head.Name().Short()
+	}
+
+	return branch, head.Hash().String(), nil
+}
+
+// DiffCommits computes the equivalent of `git diff --name-status -M from..to`
+// for the clone at repoDir. It fails if either commit is not available locally,
+// in which case callers should fall back to a full scan.
+func DiffCommits(ctx context.Context, repoDir, from, to string) ([]GitFileChange, error) {
+	r, err := git.PlainOpen(repoDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	fromTree, err := commitTree(r, from)
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := commitTree(r, to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := object.DiffTreeWithOptions(ctx, fromTree, toTree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s..%s: %w", shortCommit(from), shortCommit(to), err)
+	}
+
+	result := make([]GitFileChange, 0, len(changes))
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return nil, fmt.Errorf("failed to classify change: %w", err)
+		}
+
+		switch action {
+		case merkletrie.Insert:
+			result = append(result, GitFileChange{Status: GitStatusAdded, Path: change.To.Name})
+		case merkletrie.Delete:
+			result = append(result, GitFileChange{Status: GitStatusDeleted, Path: change.From.Name})
+		case merkletrie.Modify:
+			if change.From.Name != change.To.Name {
+				result = append(result, GitFileChange{Status: GitStatusRenamed, Path: change.To.Name, OldPath: change.From.Name})
+			} else {
+				result = append(result, GitFileChange{Status: GitStatusModified, Path: change.To.Name})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func commitTree(r *git.Repository, hash string) (*object.Tree, error) {
+	commit, err := r.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return nil, fmt.Errorf("commit %s not available: %w", shortCommit(hash), err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tree of %s: %w", shortCommit(hash), err)
+	}
+	return tree, nil
+}
+
+func shortCommit(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
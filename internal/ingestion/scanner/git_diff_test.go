@@ -0,0 +1,108 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commitAll(t *testing.T, wt *git.Worktree, msg string) string {
+	t.Helper()
+	require.NoError(t, wt.AddWithOptions(&git.AddOptions{All: true}))
+	hash, err := wt.Commit(msg, &git.CommitOptions{
+		All:    true,
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	return hash.String()
+}
+
+func TestDiffCommits_NameStatus(t *testing.T) {
+	repoDir := t.TempDir()
+	r, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	wt, err := r.Worktree()
+	require.NoError(t, err)
+
+	write := func(name, content string) {
+		path := filepath.Join(repoDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	write("keep.md", "# Keep\nunchanged")
+	write("edit.md", "# Edit\nbefore")
+	write("remove.md", "# Remove\ngone soon")
+	write("docs/old.md", "# Moved\nthis document is moved to a new directory without content changes")
+	base := commitAll(t, wt, "initial")
+
+	write("edit.md", "# Edit\nafter")
+	require.NoError(t, os.Remove(filepath.Join(repoDir, "remove.md")))
+	require.NoError(t, os.MkdirAll(filepath.Join(repoDir, "guides"), 0o755))
+	require.NoError(t, os.Rename(filepath.Join(repoDir, "docs/old.md"), filepath.Join(repoDir, "guides/new.md")))
+	write("added.md", "# Added")
+	head := commitAll(t, wt, "update")
+
+	branch, resolved, err := ResolveHead(repoDir)
+	require.NoError(t, err)
+	assert.Equal(t, "master", branch)
+	assert.Equal(t, head, resolved)
+
+	changes, err := DiffCommits(context.Background(), repoDir, base, head)
+	require.NoError(t, err)
+
+	byPath := make(map[string]GitFileChange)
+	for _, c := range changes {
+		byPath[c.Path] = c
+	}
+
+	assert.Len(t, changes, 4)
+	assert.Equal(t, GitStatusModified, byPath["edit.md"].Status)
+	assert.Equal(t, GitStatusDeleted, byPath["remove.md"].Status)
+	assert.Equal(t, GitStatusAdded, byPath["added.md"].Status)
+	assert.Equal(t, GitStatusRenamed, byPath["guides/new.md"].Status)
+	assert.Equal(t, "docs/old.md", byPath["guides/new.md"].OldPath)
+	assert.NotContains(t, byPath, "keep.md")
+
+	repo := GitHubRepo{Owner: "owner", Name: "repo"}
+	s := NewGitHubScanner([]GitHubRepo{repo}, "")
+	files, deleted := s.ScanChanges(repo, repoDir, changes)
+
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+		assert.Equal(t, "github", f.SourceType)
+		assert.NotEmpty(t, f.ContentHash)
+	}
+	assert.ElementsMatch(t, []string{
+		"github://owner/repo/edit.md",
+		"github://owner/repo/added.md",
+		"github://owner/repo/guides/new.md",
+	}, paths)
+	assert.ElementsMatch(t, []string{
+		"github://owner/repo/remove.md",
+		"github://owner/repo/docs/old.md",
+	}, deleted)
+}
+
+func TestDiffCommits_UnknownBaseCommit(t *testing.T) {
+	repoDir := t.TempDir()
+	r, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	wt, err := r.Worktree()
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("# Readme"), 0o644))
+	head := commitAll(t, wt, "initial")
+
+	_, err = DiffCommits(context.Background(), repoDir, "0123456789abcdef0123456789abcdef01234567", head)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not available")
+}
@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
@@ -10,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 
 	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
@@ -20,12 +22,44 @@ type GitHubRepo struct {
 	Name  string
 }
 
+// FullName returns the repository in "owner/name" form.
+func (r GitHubRepo) FullName() string {
+	return r.Owner + "/" + r.Name
+}
+
+// PathPrefix returns the github:// path prefix shared by every file in the repository.
+func (r GitHubRepo) PathPrefix() string {
+	return fmt.Sprintf("github://%s/%s/", r.Owner, r.Name)
+}
+
 type GitHubScanner struct {
 	repos    []GitHubRepo
 	token    string
+	cacheDir string
 	tempDirs []string
 }
 
+// GitHubScanResult holds the files collected from a single repository.
+// When BaseCommit is set, Files and Deleted only cover the paths that changed
+// between BaseCommit and HeadCommit; otherwise Files is a full snapshot.
+type GitHubScanResult struct {
+	Repo       GitHubRepo
+	Branch     string
+	HeadCommit string
+	BaseCommit string
+	Files      []*pkgdomain.FileInfo
+	Deleted    []string
+}
+
+// Incremental reports whether the result was produced from a commit diff.
+func (r *GitHubScanResult) Incremental() bool {
+	return r.BaseCommit != ""
+}
+
+// CommitLookup returns the last indexed commit for a repository branch,
+// or an empty string when the branch has never been indexed.
+type CommitLookup func(ctx context.Context, repo GitHubRepo, branch string) (string, error)
+
 func ParseGitHubRepos(reposStr string) ([]GitHubRepo, error) {
 	reposStr = strings.TrimSpace(reposStr)
 	if reposStr == "" {
@@ -66,6 +100,39 @@ func NewGitHubScanner(repos []GitHubRepo, token string) *GitHubScanner {
 	}
 }
 
+// NewGitHubScannerWithCache creates a GitHubScanner that keeps clones under cacheDir
+// and updates them with git fetch instead of re-cloning on every run.
+func NewGitHubScannerWithCache(repos []GitHubRepo, token, cacheDir string) *GitHubScanner {
+	return &GitHubScanner{
+		repos:    repos,
+		token:    token,
+		cacheDir: cacheDir,
+	}
+}
+
+// DefaultGitHubCacheDir returns ~/.ragent/github, the default clone cache location.
+func DefaultGitHubCacheDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".ragent", "github"), nil
+}
+
+func (g *GitHubScanner) auth() *http.BasicAuth {
+	if g.token == "" {
+		return nil
+	}
+	return &http.BasicAuth{
+		Username: "x-access-token",
+		Password: g.token,
+	}
+}
+
+func (g *GitHubScanner) cloneURL(repo GitHubRepo) string {
+	return fmt.Sprintf("https://github.com/%s/%s.git", repo.Owner, repo.Name)
+}
+
 func (g *GitHubScanner) CloneRepository(ctx context.Context, repo GitHubRepo) (string, error) {
 	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("ragent-github-%s-%s-*", repo.Owner, repo.Name))
 	if err != nil {
@@ -73,18 +140,13 @@ func (g *GitHubScanner) CloneRepository(ctx context.Context, repo GitHubRepo) (s
 	}
 	g.tempDirs = append(g.tempDirs, tmpDir)
 
-	cloneURL := fmt.Sprintf("https://github.com/%s/%s.git", repo.Owner, repo.Name)
-
 	cloneOpts := &git.CloneOptions{
-		URL:   cloneURL,
+		URL:   g.cloneURL(repo),
 		Depth: 1,
 	}
 
-	if g.token != "" {
-		cloneOpts.Auth = &http.BasicAuth{
-			Username: "x-access-token",
-			Password: g.token,
-		}
+	if auth := g.auth(); auth != nil {
+		cloneOpts.Auth = auth
 	}
 
 	log.Printf("Cloning GitHub repository: %s/%s", repo.Owner, repo.Name)
@@ -98,6 +160,232 @@ func (g *GitHubScanner) CloneRepository(ctx context.Context, repo GitHubRepo) (s
 	return tmpDir, nil
 }
 
+// SyncRepository returns a working copy of repo at the tip of its default branch.
+// With a cache directory configured, an existing clone is fetched and hard-reset
+// in place; a missing or unusable clone is replaced by a fresh one. Without a
+// cache directory it falls back to CloneRepository.
+func (g *GitHubScanner) SyncRepository(ctx context.Context, repo GitHubRepo) (string, error) {
+	if g.cacheDir == "" {
+		return g.CloneRepository(ctx, repo)
+	}
+
+	repoDir := filepath.Join(g.cacheDir, repo.Owner, repo.Name)
+
+	if _, err := os.Stat(filepath.Join(repoDir, ".git")); err == nil {
+		err := g.fetchRepository(ctx, repo, repoDir)
+		if err == nil {
+			return repoDir, nil
+		}
+		log.Printf("Warning: failed to update cached clone of %s: %v (re-cloning)", repo.FullName(), err)
+	}
+
+	if err := os.RemoveAll(repoDir); err != nil {
+		return "", fmt.Errorf("failed to clear cache directory %s: %w", repoDir, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(repoDir), 0755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	cloneOpts := &git.CloneOptions{
+		URL:          g.cloneURL(repo),
+		Depth:        1,
+		SingleBranch: true,
+	}
+	if auth := g.auth(); auth != nil {
+		cloneOpts.Auth = auth
+	}
+
+	log.Printf("Cloning GitHub repository into cache: %s", repo.FullName())
+
+	if _, err := git.PlainCloneContext(ctx, repoDir, false, cloneOpts); err != nil {
+		_ = os.RemoveAll(repoDir)
+		return "", fmt.Errorf("failed to clone %s: %w", repo.FullName(), err)
+	}
+
+	log.Printf("Successfully cloned %s to %s", repo.FullName(), repoDir)
+	return repoDir, nil
+}
+
+// fetchRepository fetches the checked-out branch of a cached clone and resets the
+// worktree to the fetched commit. Objects of previously indexed commits stay in
+// the local object store so they remain available for diffing.
+func (g *GitHubScanner) fetchRepository(ctx context.Context, repo GitHubRepo, repoDir string) error {
+	r, err := git.PlainOpen(repoDir)
+	if err != nil {
+		return fmt.Errorf("failed to open cached clone: %w", err)
+	}
+
+	head, err := r.Head()
+	if err != nil {
+		return fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	if !head.Name().IsBranch() {
+		return fmt.Errorf("cached clone is not on a branch")
+	}
+	branch := head.Name().Short()
+
+	fetchOpts := &git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		Depth:      1,
+		Force:      true,
+	}
+	if auth := g.auth(); auth != nil {
+		fetchOpts.Auth = auth
+	}
+
+	log.Printf("Fetching GitHub repository: %s (%s)", repo.FullName(), branch)
+
+	if err := r.FetchContext(ctx, fetchOpts); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to fetch: %w", err)
+	}
+
+	// Single-branch clones track the remote HEAD rather than origin/<branch>
+	remoteRef, err := r.Reference(plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch), true)
+	if err != nil {
+		remoteRef, err = r.Reference(plumbing.NewRemoteHEADReferenceName(git.DefaultRemoteName), true)
+		if err != nil {
+			return fmt.Errorf("failed to resolve fetched commit for %s: %w", branch, err)
+		}
+	}
+
+	wt, err := r.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open worktree: %w", err)
+	}
+	if err := wt.Reset(&git.ResetOptions{Commit: remoteRef.Hash(), Mode: git.HardReset}); err != nil {
+		return fmt.Errorf("failed to reset worktree: %w", err)
+	}
+
+	return nil
+}
+
+// ScanRepositories syncs every configured repository and scans it. When lookup
+// returns a previously indexed commit that is still present in the clone, only
+// the files changed since that commit are read; otherwise the whole tree is scanned.
+func (g *GitHubScanner) ScanRepositories(ctx context.Context, lookup CommitLookup) ([]*GitHubScanResult, error) {
+	var results []*GitHubScanResult
+
+	for _, repo := range g.repos {
+		repoDir, err := g.SyncRepository(ctx, repo)
+		if err != nil {
+			log.Printf("Warning: Failed to clone %s/%s: %v (skipping)", repo.Owner, repo.Name, err)
+			continue
+		}
+
+		branch, headCommit, err := ResolveHead(repoDir)
+		if err != nil {
+			log.Printf("Warning: Failed to resolve HEAD of %s/%s: %v (skipping)", repo.Owner, repo.Name, err)
+			continue
+		}
+
+		result := &GitHubScanResult{
+			Repo:       repo,
+			Branch:     branch,
+			HeadCommit: headCommit,
+		}
+
+		var baseCommit string
+		if lookup != nil {
+			baseCommit, err = lookup(ctx, repo, branch)
+			if err != nil {
+				log.Printf("Warning: Failed to look up last indexed commit for %s: %v", repo.FullName(), err)
+				baseCommit = ""
+			}
+		}
+
+		if baseCommit != "" {
+			changes, err := DiffCommits(ctx, repoDir, baseCommit, headCommit)
+			if err != nil {
+				log.Printf("Warning: Cannot diff %s against %s: %v (falling back to full scan)", repo.FullName(), shortCommit(baseCommit), err)
+			} else {
+				files, deleted := g.ScanChanges(repo, repoDir, changes)
+				result.BaseCommit = baseCommit
+				result.Files = files
+				result.Deleted = deleted
+				log.Printf("Found %d changed and %d deleted files in %s (%s..%s)",
+					len(files), len(deleted), repo.FullName(), shortCommit(baseCommit), shortCommit(headCommit))
+				results = append(results, result)
+				continue
+			}
+		}
+
+		files, err := g.ScanRepository(ctx, repo, repoDir)
+		if err != nil {
+			log.Printf("Warning: Failed to scan %s/%s: %v (skipping)", repo.Owner, repo.Name, err)
+			continue
+		}
+		result.Files = files
+
+		log.Printf("Found %d supported files in %s/%s", len(files), repo.Owner, repo.Name)
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// ScanChanges reads the files added, modified or renamed by changes and returns
+// them together with the github:// paths of files that were deleted or renamed away.
+// Paths of unsupported file types are ignored.
+func (g *GitHubScanner) ScanChanges(repo GitHubRepo, repoDir string, changes []GitFileChange) ([]*pkgdomain.FileInfo, []string) {
+	var files []*pkgdomain.FileInfo
+	var deleted []string
+
+	for _, change := range changes {
+		switch change.Status {
+		case GitStatusDeleted:
+			if g.isSupportedFile(change.Path) {
+				deleted = append(deleted, repo.PathPrefix()+change.Path)
+			}
+			continue
+		case GitStatusRenamed:
+			if g.isSupportedFile(change.OldPath) {
+				deleted = append(deleted, repo.PathPrefix()+change.OldPath)
+			}
+		}
+
+		if !g.isSupportedFile(change.Path) {
+			continue
+		}
+
+		fileInfo, err := g.loadFile(repo, repoDir, change.Path)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+			continue
+		}
+		files = append(files, fileInfo)
+	}
+
+	return files, deleted
+}
+
+func (g *GitHubScanner) loadFile(repo GitHubRepo, repoDir, relPath string) (*pkgdomain.FileInfo, error) {
+	path := filepath.Join(repoDir, filepath.FromSlash(relPath))
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info for %s: %w", path, err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	contentStr := string(content)
+
+	return &pkgdomain.FileInfo{
+		Path:        repo.PathPrefix() + relPath,
+		Name:        filepath.Base(path),
+		Size:        info.Size(),
+		ModTime:     info.ModTime(),
+		IsMarkdown:  g.isMarkdownFile(path),
+		IsCSV:       g.isCSVFile(path),
+		Content:     contentStr,
+		ContentHash: ComputeMD5Hash(contentStr),
+		SourceType:  "github",
+	}, nil
+}
+
 func (g *GitHubScanner) ScanRepository(ctx context.Context, repo GitHubRepo, repoDir string) ([]*pkgdomain.FileInfo, error) {
 	var files []*pkgdomain.FileInfo
 
@@ -184,6 +472,7 @@ func (g *GitHubScanner) ScanAllRepositories(ctx context.Context) ([]*pkgdomain.F
 	return allFiles, nil
 }
 
+// Cleanup removes temporary clones. Cached clones are kept for the next run.
 func (g *GitHubScanner) Cleanup() {
 	for _, dir := range g.tempDirs {
 		if err := os.RemoveAll(dir); err != nil {
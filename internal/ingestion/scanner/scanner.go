@@ -72,6 +72,31 @@ func (s *FileScanner) ScanDirectory(dirPath string) ([]*pkgdomain.FileInfo, erro
 	return files, nil
 }
 
+// ScanFile returns file info for a single supported file
+func (s *FileScanner) ScanFile(path string) (*pkgdomain.FileInfo, error) {
+	if !s.IsSupportedFile(path) {
+		return nil, fmt.Errorf("unsupported file type: %s", path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info for %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("path is a directory: %s", path)
+	}
+
+	return &pkgdomain.FileInfo{
+		Path:       path,
+		Name:       info.Name(),
+		Size:       info.Size(),
+		ModTime:    info.ModTime(),
+		IsMarkdown: s.IsMarkdownFile(path),
+		IsCSV:      s.IsCSVFile(path),
+		IsPDF:      s.IsPDFFile(path),
+	}, nil
+}
+
 // ValidateDirectory checks if the directory exists and is readable
 func (s *FileScanner) ValidateDirectory(dirPath string) error {
 	info, err := os.Stat(dirPath)
@@ -0,0 +1,355 @@
+package sqlitevec
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ANN index settings. The index is an inverted file: vectors are assigned to the nearest
+// of ANNLists k-means centroids and a query only scans the lists of its nearest centroids.
+const (
+	// minVectorsPerList is the number of vectors per list below which every vector is scanned
+	minVectorsPerList = 16
+	// trainingVectorsPerList limits the k-means sample to this many vectors per list
+	trainingVectorsPerList = 64
+	// kmeansIterations is the number of k-means refinement passes
+	kmeansIterations = 10
+	// annRebuildGrowth rebuilds the index once the store has grown by this factor since training
+	annRebuildGrowth = 2
+)
+
+// settingANNTrainedCount records the number of vectors when the ANN index was trained
+const settingANNTrainedCount = "ann_trained_count"
+
+// settingANNVersion changes whenever the ANN index is rebuilt, so stores opened by other
+// processes reload the centroids
+const settingANNVersion = "ann_version"
+
+// annCentroids returns the centroids of the ANN index, reloading them from the database
+// when another store rebuilt the index. It returns nil when the store has no index.
+func (s *SqliteVecStore) annCentroids(ctx context.Context) ([][]float32, error) {
+	version, err := s.getSetting(ctx, settingANNVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded && s.annVersion == version {
+		return s.centroids, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT centroid FROM ann_lists ORDER BY list_id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ANN centroids: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var centroids [][]float32
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan ANN centroid: %w", err)
+		}
+		centroid, err := decodeEmbedding(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to deserialize ANN centroid: %w", err)
+		}
+		centroids = append(centroids, centroid)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ANN centroids: %w", err)
+	}
+	s.centroids = centroids
+	s.annVersion = version
+	s.loaded = true
+	return centroids, nil
+}
+
+// assignList returns the ANN list of a new vector, or nil when the store has no index
+func (s *SqliteVecStore) assignList(ctx context.Context, embedding []float32) (any, error) {
+	if s.opts.ANNLists == 0 {
+		return nil, nil
+	}
+	centroids, err := s.annCentroids(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(centroids) == 0 || len(centroids[0]) != len(embedding) {
+		return nil, nil
+	}
+	return nearestCentroids(centroids, embedding, 1)[0], nil
+}
+
+// queryCentroids returns the centroids used to narrow a query, or nil when the query
+// scans every vector. Queries only read the index; it is trained by MaintainIndex.
+func (s *SqliteVecStore) queryCentroids(ctx context.Context, query []float32) ([][]float32, error) {
+	if s.opts.ANNLists == 0 {
+		return nil, nil
+	}
+	centroids, err := s.annCentroids(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(centroids) == 0 || len(centroids[0]) != len(query) {
+		return nil, nil
+	}
+	return centroids, nil
+}
+
+// MaintainIndex trains the ANN index when it is configured and missing, outdated or sized
+// for a different number of lists. It runs after vectorize runs and `vectorize compact`,
+// never on the query path. Stores with too few vectors keep scanning every vector.
+func (s *SqliteVecStore) MaintainIndex(ctx context.Context) error {
+	if s.opts.ANNLists == 0 {
+		return nil
+	}
+	var count int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM vectors").Scan(&count); err != nil {
+		return fmt.Errorf("failed to count vectors: %w", err)
+	}
+	if count < s.opts.ANNLists*minVectorsPerList {
+		return nil
+	}
+
+	centroids, err := s.annCentroids(ctx)
+	if err != nil {
+		return err
+	}
+	trained, err := s.getSetting(ctx, settingANNTrainedCount)
+	if err != nil {
+		return err
+	}
+	trainedCount, _ := strconv.Atoi(trained)
+	if len(centroids) == s.opts.ANNLists && count <= trainedCount*annRebuildGrowth {
+		return nil
+	}
+	_, err = s.BuildANNIndex(ctx)
+	return err
+}
+
+// BuildANNIndex trains ANNLists centroids with k-means on a sample of the stored vectors
+// and assigns every vector to its nearest centroid.
+func (s *SqliteVecStore) BuildANNIndex(ctx context.Context) ([][]float32, error) {
+	if s.opts.ANNLists == 0 {
+		return nil, fmt.Errorf("the ANN index is disabled (SQLITE_VEC_ANN_LISTS=0)")
+	}
+	var count int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM vectors").Scan(&count); err != nil {
+		return nil, fmt.Errorf("failed to count vectors: %w", err)
+	}
+	if count < s.opts.ANNLists {
+		return nil, fmt.Errorf("cannot train %d ANN lists with %d vectors", s.opts.ANNLists, count)
+	}
+	log.Printf("INFO: building ANN index with %d lists over %d vectors", s.opts.ANNLists, count)
+
+	step := max(count/(s.opts.ANNLists*trainingVectorsPerList), 1)
+	var sample [][]float32
+	index := 0
+	err := s.scanVectors(ctx, func(key string, embedding []float32) error {
+		if index%step == 0 {
+			sample = append(sample, embedding)
+		}
+		index++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	centroids := trainCentroids(sample, s.opts.ANNLists, kmeansIterations)
+
+	assignments := make(map[string]int, count)
+	err = s.scanVectors(ctx, func(key string, embedding []float32) error {
+		if len(embedding) == len(centroids[0]) {
+			assignments[key] = nearestCentroids(centroids, embedding, 1)[0]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	version := strconv.FormatInt(time.Now().UnixNano(), 10)
+	if err := s.saveANNIndex(ctx, centroids, assignments, count, version); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.centroids = centroids
+	s.annVersion = version
+	s.loaded = true
+	s.mu.Unlock()
+	return centroids, nil
+}
+
+// saveANNIndex replaces the centroids and the list assignments in one transaction
+func (s *SqliteVecStore) saveANNIndex(ctx context.Context, centroids [][]float32, assignments map[string]int, count int, version string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM ann_lists"); err != nil {
+		return fmt.Errorf("failed to clear ANN centroids: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE vectors SET list_id = NULL"); err != nil {
+		return fmt.Errorf("failed to clear ANN lists: %w", err)
+	}
+	for i, centroid := range centroids {
+		data, err := encodeEmbedding(centroid)
+		if err != nil {
+			return fmt.Errorf("failed to serialize ANN centroid: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO ann_lists (list_id, centroid) VALUES (?, ?)", i, data); err != nil {
+			return fmt.Errorf("failed to save ANN centroid: %w", err)
+		}
+	}
+	for key, list := range assignments {
+		if _, err := tx.ExecContext(ctx, "UPDATE vectors SET list_id = ? WHERE key = ?", list, key); err != nil {
+			return fmt.Errorf("failed to assign ANN list of %q: %w", key, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO vector_settings (name, value) VALUES (?, ?) ON CONFLICT(name) DO UPDATE SET value = excluded.value",
+		settingANNTrainedCount, strconv.Itoa(count)); err != nil {
+		return fmt.Errorf("failed to record ANN training: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO vector_settings (name, value) VALUES (?, ?) ON CONFLICT(name) DO UPDATE SET value = excluded.value",
+		settingANNVersion, version); err != nil {
+		return fmt.Errorf("failed to record ANN training: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit ANN index: %w", err)
+	}
+	return nil
+}
+
+// scanVectors calls fn with the float32 embedding of every vector, page by page
+func (s *SqliteVecStore) scanVectors(ctx context.Context, fn func(key string, embedding []float32) error) error {
+	cursor := ""
+	for {
+		rows, err := s.db.QueryContext(ctx,
+			"SELECT key, embedding, quantized FROM vectors WHERE key > ? ORDER BY key LIMIT ?", cursor, layoutPageSize)
+		if err != nil {
+			return fmt.Errorf("failed to read vectors: %w", err)
+		}
+		type page struct {
+			key       string
+			embedding []float32
+		}
+		var vectors []page
+		for rows.Next() {
+			var (
+				key             string
+				data, codeBytes []byte
+			)
+			if err := rows.Scan(&key, &data, &codeBytes); err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("failed to scan vector: %w", err)
+			}
+			embedding, err := s.decodeStored(data, codeBytes)
+			if err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("failed to deserialize embedding for %q: %w", key, err)
+			}
+			vectors = append(vectors, page{key: key, embedding: embedding})
+		}
+		_ = rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read vectors: %w", err)
+		}
+		if len(vectors) == 0 {
+			return nil
+		}
+		// The connection is released before fn runs, so fn may query the store
+		for _, v := range vectors {
+			if err := fn(v.key, v.embedding); err != nil {
+				return err
+			}
+		}
+		cursor = vectors[len(vectors)-1].key
+	}
+}
+
+// trainCentroids runs spherical k-means: centroids are unit vectors and vectors are
+// assigned by cosine similarity. Initial centroids are spread evenly over the sample.
+func trainCentroids(sample [][]float32, lists, iterations int) [][]float32 {
+	lists = min(lists, len(sample))
+	centroids := make([][]float32, lists)
+	for i := range centroids {
+		centroids[i] = normalized(sample[i*len(sample)/lists])
+	}
+
+	dim := len(centroids[0])
+	for iter := 0; iter < iterations; iter++ {
+		sums := make([][]float64, lists)
+		counts := make([]int, lists)
+		for i := range sums {
+			sums[i] = make([]float64, dim)
+		}
+		for _, v := range sample {
+			if len(v) != dim {
+				continue
+			}
+			nearest := nearestCentroids(centroids, v, 1)[0]
+			unit := normalized(v)
+			for d, x := range unit {
+				sums[nearest][d] += float64(x)
+			}
+			counts[nearest]++
+		}
+		for i := range centroids {
+			// An empty list keeps its centroid
+			if counts[i] == 0 {
+				continue
+			}
+			centroid := make([]float32, dim)
+			for d := range centroid {
+				centroid[d] = float32(sums[i][d] / float64(counts[i]))
+			}
+			centroids[i] = normalized(centroid)
+		}
+	}
+	return centroids
+}
+
+// nearestCentroids returns the indexes of the n centroids closest to v by cosine similarity
+func nearestCentroids(centroids [][]float32, v []float32, n int) []int {
+	type scored struct {
+		index    int
+		distance float64
+	}
+	scores := make([]scored, 0, len(centroids))
+	for i, centroid := range centroids {
+		distance, err := cosineDistance(centroid, v)
+		if err != nil {
+			continue
+		}
+		scores = append(scores, scored{index: i, distance: distance})
+	}
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].distance < scores[j].distance })
+
+	n = min(n, len(scores))
+	nearest := make([]int, n)
+	for i := range nearest {
+		nearest[i] = scores[i].index
+	}
+	return nearest
+}
+
+func normalized(v []float32) []float32 {
+	n := norm(v)
+	unit := make([]float32, len(v))
+	if n == 0 {
+		return unit
+	}
+	for i, x := range v {
+		unit[i] = float32(float64(x) / n)
+	}
+	return unit
+}
@@ -8,18 +8,21 @@
 // that sqlite-vec uses internally), but stored in a standard TEXT/BLOB table
 // because vec0 virtual tables require the C extension which conflicts with
 // CGO-free goals.
+//
+// Embeddings can additionally be stored as int8 or binary codes, which are
+// scanned by QueryVectors before the best candidates are rescored with the
+// float32 embeddings, and grouped into the inverted lists of an ANN index.
 package sqlitevec
 
 import (
-	"bytes"
 	"context"
 	"database/sql"
-	"encoding/binary"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	_ "modernc.org/sqlite" // registers "sqlite" driver – pure Go, CGO-free
@@ -52,16 +55,53 @@ CREATE INDEX IF NOT EXISTS idx_vectors_key ON vectors(key);`
 // SQLite 3.35+; for older versions we silently ignore the error.
 const migrateAddSecretColumn = `ALTER TABLE vectors ADD COLUMN secret INTEGER DEFAULT 0`
 
+// migrateAddSearchColumns adds the quantized code and the ANN list of each vector.
+// An empty embedding BLOB marks a vector stored only as a quantized code.
+var migrateAddSearchColumns = []string{
+	`ALTER TABLE vectors ADD COLUMN quantized BLOB`,
+	`ALTER TABLE vectors ADD COLUMN list_id INTEGER`,
+}
+
+// createSearchTablesSQL defines the store settings and the centroids of the ANN index.
+const createSearchTablesSQL = `
+CREATE TABLE IF NOT EXISTS vector_settings (
+    name TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS ann_lists (
+    list_id INTEGER PRIMARY KEY,
+    centroid BLOB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_vectors_list_id ON vectors(list_id);`
+
 // SqliteVecStore stores embedding vectors in a local SQLite database.
 type SqliteVecStore struct {
-	db     *sql.DB
-	dbPath string
+	db         *sql.DB
+	dbPath     string
+	opts       Options // Layout in use, which lags configured until MigrateLayout runs
+	configured Options
+
+	mu         sync.Mutex
+	centroids  [][]float32 // Centroids of the ANN index, loaded on first use
+	annVersion string
+	loaded     bool
 }
 
 // NewSqliteVecStore opens (or creates) a SQLite database at dbPath and
 // ensures the schema is initialised. The path may start with "~/" which is
 // expanded to the current user's home directory.
 func NewSqliteVecStore(dbPath string) (*SqliteVecStore, error) {
+	return NewSqliteVecStoreWithOptions(dbPath, DefaultOptions())
+}
+
+// NewSqliteVecStoreWithOptions opens a store with the given quantization, storage
+// layout and ANN settings. Opening never rewrites stored vectors: a database encoded
+// with a different quantization keeps it until MigrateLayout re-encodes the vectors.
+func NewSqliteVecStoreWithOptions(dbPath string, opts Options) (*SqliteVecStore, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Expand ~ prefix.
 	if strings.HasPrefix(dbPath, "~/") {
 		home, err := os.UserHomeDir()
@@ -94,13 +134,17 @@ func NewSqliteVecStore(dbPath string) (*SqliteVecStore, error) {
 		return nil, fmt.Errorf("failed to ping sqlite database: %w", err)
 	}
 
-	store := &SqliteVecStore{db: db, dbPath: dbPath}
+	store := &SqliteVecStore{db: db, dbPath: dbPath, opts: opts, configured: opts}
 
 	ctx := context.Background()
 	if err := store.initSchema(ctx); err != nil {
 		_ = db.Close()
 		return nil, fmt.Errorf("failed to initialize schema: %w", err)
 	}
+	if err := store.checkLayout(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
 
 	log.Printf("INFO: sqlite-vec store opened at %s", dbPath)
 	return store, nil
@@ -119,6 +163,13 @@ func (s *SqliteVecStore) initSchema(ctx context.Context) error {
 	}
 
 	_, _ = s.db.ExecContext(ctx, migrateAddSecretColumn)
+	for _, stmt := range migrateAddSearchColumns {
+		_, _ = s.db.ExecContext(ctx, stmt)
+	}
+
+	if _, err := s.db.ExecContext(ctx, createSearchTablesSQL); err != nil {
+		return fmt.Errorf("failed to create search tables: %w", err)
+	}
 
 	return nil
 }
@@ -138,11 +189,18 @@ func (s *SqliteVecStore) StoreVector(ctx context.Context, vectorData *domain.Vec
 	for i, v := range vectorData.Embedding {
 		embedding32[i] = float32(v)
 	}
-	buf := new(bytes.Buffer)
-	if err := binary.Write(buf, binary.LittleEndian, embedding32); err != nil {
+	embeddingBytes, err := encodeEmbedding(embedding32)
+	if err != nil {
 		return fmt.Errorf("failed to serialize embedding for %q: %w", vectorData.ID, err)
 	}
-	embeddingBytes := buf.Bytes()
+	code := quantize(s.opts.Quantization, embedding32)
+	if s.opts.Storage == StorageQuantized {
+		embeddingBytes = []byte{}
+	}
+	listID, err := s.assignList(ctx, embedding32)
+	if err != nil {
+		return err
+	}
 
 	// DELETE existing row first to support upsert behaviour.
 	if _, err := s.db.ExecContext(ctx, "DELETE FROM vectors WHERE key = ?", vectorData.ID); err != nil {
@@ -160,13 +218,16 @@ func (s *SqliteVecStore) StoreVector(ctx context.Context, vectorData *domain.Vec
 		secretInt = 1
 	}
 
-	_, err := s.db.ExecContext(ctx, `
+	_, err = s.db.ExecContext(ctx, `
 		INSERT INTO vectors
-			(key, embedding, title, category, file_path, reference, author, word_count, content_excerpt, created_at, secret)
+			(key, embedding, quantized, list_id, title, category, file_path, reference, author, word_count,
+			 content_excerpt, created_at, secret)
 		VALUES
-			(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		vectorData.ID,
 		embeddingBytes,
+		code,
+		listID,
 		vectorData.Metadata.Title,
 		vectorData.Metadata.Category,
 		vectorData.Metadata.FilePath,
@@ -236,29 +297,47 @@ func (s *SqliteVecStore) ListVectors(ctx context.Context, prefix string) ([]stri
 func (s *SqliteVecStore) ListVectorsWithMetadata(
 	ctx context.Context, prefix string,
 ) ([]domain.VectorListItem, error) {
-	const baseCols = "key, title, category, file_path, reference, author, word_count, created_at, secret"
-
-	var (
-		rows *sql.Rows
-		err  error
-	)
-
+	var rows []metadataRow
+	var err error
 	if prefix == "" {
-		rows, err = s.db.QueryContext(ctx, "SELECT "+baseCols+" FROM vectors ORDER BY key")
+		rows, err = s.listWithMetadata(ctx, "")
 	} else {
-		escapedPrefix := escapeLIKE(prefix)
-		rows, err = s.db.QueryContext(ctx,
-			"SELECT "+baseCols+" FROM vectors WHERE key LIKE ? ESCAPE '\\' ORDER BY key",
-			escapedPrefix+"%")
+		rows, err = s.listWithMetadata(ctx, "key LIKE ? ESCAPE '\\'", escapeLIKE(prefix)+"%")
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]domain.VectorListItem, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, row.VectorListItem)
+	}
+	return items, nil
+}
+
+// metadataRow is a listed vector together with its stored content excerpt
+type metadataRow struct {
+	domain.VectorListItem
+	excerpt string
+}
+
+// listWithMetadata returns the metadata of the vectors matching the where
+// clause (all vectors when empty), ordered by key.
+func (s *SqliteVecStore) listWithMetadata(ctx context.Context, where string, args ...any) ([]metadataRow, error) {
+	stmt := "SELECT key, title, category, file_path, reference, author, word_count, created_at, secret, " +
+		"content_excerpt FROM vectors"
+	if where != "" {
+		stmt += " WHERE " + where
+	}
+	rows, err := s.db.QueryContext(ctx, stmt+" ORDER BY key", args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list vectors with metadata: %w", err)
 	}
 	defer func() { _ = rows.Close() }()
 
-	items := []domain.VectorListItem{}
+	items := []metadataRow{}
 	for rows.Next() {
-		var item domain.VectorListItem
+		var item metadataRow
 		var (
 			title     sql.NullString
 			category  sql.NullString
@@ -268,10 +347,11 @@ func (s *SqliteVecStore) ListVectorsWithMetadata(
 			wordCount sql.NullInt64
 			createdAt sql.NullString
 			secret    sql.NullInt64
+			content   sql.NullString
 		)
 		if err := rows.Scan(
 			&item.Key, &title, &category, &filePath,
-			&reference, &author, &wordCount, &createdAt, &secret,
+			&reference, &author, &wordCount, &createdAt, &secret, &content,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan vector metadata: %w", err)
 		}
@@ -282,6 +362,7 @@ func (s *SqliteVecStore) ListVectorsWithMetadata(
 		item.Author = author.String
 		item.WordCount = int(wordCount.Int64)
 		item.CreatedAt = createdAt.String
+		item.excerpt = content.String
 
 		item.RawMetadata = map[string]interface{}{
 			"title":      title.String,
@@ -298,6 +379,87 @@ func (s *SqliteVecStore) ListVectorsWithMetadata(
 	return items, rows.Err()
 }
 
+// ExportVectors returns up to limit stored vectors ordered by key, starting
+// after the key given as cursor ("" starts from the beginning). The returned
+// cursor is the last key of the page, or "" when there are no more vectors.
+// Content is the stored excerpt, not the full document text.
+func (s *SqliteVecStore) ExportVectors(
+	ctx context.Context, cursor string, limit int,
+) ([]*domain.VectorData, string, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT key, embedding, quantized, title, category, file_path, reference, author,
+			word_count, content_excerpt, created_at, secret
+		FROM vectors WHERE key > ? ORDER BY key LIMIT ?`,
+		cursor, limit)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to export vectors: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	vectors := []*domain.VectorData{}
+	for rows.Next() {
+		var (
+			vd             domain.VectorData
+			embeddingBytes []byte
+			code           []byte
+			title          sql.NullString
+			category       sql.NullString
+			filePath       sql.NullString
+			reference      sql.NullString
+			author         sql.NullString
+			wordCount      sql.NullInt64
+			content        sql.NullString
+			createdAt      sql.NullString
+			secret         sql.NullInt64
+		)
+		if err := rows.Scan(
+			&vd.ID, &embeddingBytes, &code, &title, &category, &filePath, &reference,
+			&author, &wordCount, &content, &createdAt, &secret,
+		); err != nil {
+			return nil, "", fmt.Errorf("failed to scan vector: %w", err)
+		}
+
+		embedding32, err := s.decodeStored(embeddingBytes, code)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to deserialize embedding for %q: %w", vd.ID, err)
+		}
+		vd.Embedding = make([]float64, len(embedding32))
+		for i, v := range embedding32 {
+			vd.Embedding[i] = float64(v)
+		}
+
+		vd.Metadata = domain.DocumentMetadata{
+			Title:     title.String,
+			Category:  category.String,
+			FilePath:  filePath.String,
+			Reference: reference.String,
+			Author:    author.String,
+			WordCount: int(wordCount.Int64),
+			Secret:    secret.Int64 == 1,
+		}
+		vd.Content = content.String
+		if t, err := time.Parse(time.RFC3339, createdAt.String); err == nil {
+			vd.CreatedAt = t
+			vd.Metadata.CreatedAt = t
+			vd.Metadata.UpdatedAt = t
+		}
+		vectors = append(vectors, &vd)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("failed to export vectors: %w", err)
+	}
+
+	next := ""
+	if len(vectors) == limit {
+		next = vectors[len(vectors)-1].ID
+	}
+	return vectors, next, nil
+}
+
 // escapeLIKE escapes the three special LIKE characters (%, _, \) so a raw
 // user-supplied string can be passed as a LIKE pattern prefix.
 func escapeLIKE(s string) string {
@@ -335,7 +497,8 @@ func (s *SqliteVecStore) DeleteAllVectors(ctx context.Context) (int, error) {
 }
 
 // GetBackendInfo returns diagnostic metadata about the SQLite backend:
-// db_path, file_size_bytes, vector_count, and backend name.
+// db_path, file_size_bytes, vector_count, backend name, the quantization and
+// ANN settings, and the disk and memory used by embeddings and codes.
 func (s *SqliteVecStore) GetBackendInfo(ctx context.Context) (map[string]interface{}, error) {
 	// Count stored vectors.
 	var count int
@@ -349,11 +512,27 @@ func (s *SqliteVecStore) GetBackendInfo(ctx context.Context) (map[string]interfa
 		fileSize = fi.Size()
 	}
 
+	usage, err := s.storageUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	return map[string]interface{}{
-		"backend":         "sqlite",
-		"db_path":         s.dbPath,
-		"file_size_bytes": fileSize,
-		"vector_count":    count,
+		"backend":                "sqlite",
+		"db_path":                s.dbPath,
+		"file_size_bytes":        fileSize,
+		"vector_count":           count,
+		"quantization":           s.opts.Quantization,
+		"storage":                s.opts.Storage,
+		"full_precision_rescore": s.rescores(),
+		"rescore_factor":         s.opts.RescoreFactor,
+		"ann_lists":              usage.lists,
+		"ann_probes":             s.opts.ANNProbes,
+		"embedding_bytes":        usage.embeddingBytes,
+		"quantized_bytes":        usage.quantizedBytes,
+		"bytes_per_vector":       usage.bytesPerVector(count),
+		"ann_centroid_bytes":     usage.centroidBytes,
+		"query_scan_bytes":       usage.queryScanBytes(s.opts),
 	}, nil
 }
 
@@ -252,3 +252,37 @@ func TestGetBackendInfo(t *testing.T) {
 	assert.Equal(t, 1, info["vector_count"])
 	assert.Equal(t, "sqlite", info["backend"])
 }
+
+func TestExportVectors_Pages(t *testing.T) {
+	store := newTestStore(t)
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, key := range []string{"c", "a", "b"} {
+		embedding := make([]float64, 4)
+		embedding[0] = 0.5
+		require.NoError(t, store.StoreVector(context.Background(), &domain.VectorData{
+			ID:        key,
+			Embedding: embedding,
+			Metadata:  domain.DocumentMetadata{Title: "title " + key, Reference: "https://example.com/" + key, Secret: key == "b"},
+			Content:   "content " + key,
+			CreatedAt: created,
+		}))
+	}
+
+	page, cursor, err := store.ExportVectors(context.Background(), "", 2)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, "a", page[0].ID)
+	assert.Equal(t, "b", cursor)
+	assert.Equal(t, []float64{0.5, 0, 0, 0}, page[0].Embedding)
+	assert.Equal(t, "title a", page[0].Metadata.Title)
+	assert.Equal(t, "https://example.com/a", page[0].Metadata.Reference)
+	assert.Equal(t, "content a", page[0].Content)
+	assert.Equal(t, created, page[0].CreatedAt.UTC())
+	assert.True(t, page[1].Metadata.Secret)
+
+	page, cursor, err = store.ExportVectors(context.Background(), cursor, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "c", page[0].ID)
+	assert.Empty(t, cursor, "a short page ends the export")
+}
@@ -0,0 +1,255 @@
+package sqlitevec
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// settingQuantization records the quantization of the codes stored in the database
+const settingQuantization = "quantization"
+
+// layoutPageSize is the number of vectors re-encoded per transaction
+const layoutPageSize = 1000
+
+// encodeEmbedding serialises an embedding as little-endian float32 bytes
+func encodeEmbedding(embedding []float32) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := binary.Write(buf, binary.LittleEndian, embedding); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeEmbedding deserialises little-endian float32 bytes
+func decodeEmbedding(data []byte) ([]float32, error) {
+	embedding := make([]float32, len(data)/4)
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, embedding); err != nil {
+		return nil, err
+	}
+	return embedding, nil
+}
+
+// decodeStored returns the float32 embedding of a row, reconstructed from its quantized
+// code when the row is stored only as a code
+func (s *SqliteVecStore) decodeStored(embedding, code []byte) ([]float32, error) {
+	if len(embedding) == 0 && len(code) > 0 {
+		return dequantize(s.opts.Quantization, code)
+	}
+	return decodeEmbedding(embedding)
+}
+
+// rescores reports whether quantized search results are rescored with the float32 embeddings
+func (s *SqliteVecStore) rescores() bool {
+	return s.opts.Quantization != QuantizationNone && s.opts.Storage == StorageFull && s.opts.RescoreFactor > 0
+}
+
+func (s *SqliteVecStore) getSetting(ctx context.Context, name string) (string, error) {
+	var value string
+	err := s.db.QueryRowContext(ctx, "SELECT value FROM vector_settings WHERE name = ?", name).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read setting %s: %w", name, err)
+	}
+	return value, nil
+}
+
+func (s *SqliteVecStore) setSetting(ctx context.Context, name, value string) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO vector_settings (name, value) VALUES (?, ?) ON CONFLICT(name) DO UPDATE SET value = excluded.value",
+		name, value)
+	if err != nil {
+		return fmt.Errorf("failed to write setting %s: %w", name, err)
+	}
+	return nil
+}
+
+// checkLayout compares the configured quantization with the one the stored vectors are
+// encoded with. An empty database takes the configured layout right away. Otherwise the
+// store keeps using the stored encoding, since opening a store must not rewrite it, until
+// MigrateLayout re-encodes the vectors.
+func (s *SqliteVecStore) checkLayout(ctx context.Context) error {
+	stored, err := s.getSetting(ctx, settingQuantization)
+	if err != nil {
+		return err
+	}
+	if stored == "" {
+		stored = QuantizationNone
+	}
+	if stored == s.configured.Quantization {
+		return nil
+	}
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM vectors").Scan(&count); err != nil {
+		return fmt.Errorf("failed to count vectors: %w", err)
+	}
+	if count == 0 {
+		return s.setSetting(ctx, settingQuantization, s.configured.Quantization)
+	}
+
+	log.Printf("WARNING: the %d vectors in %s are encoded as %s, not %s; keeping %s until `ragent vectorize compact` re-encodes them",
+		count, s.dbPath, stored, s.configured.Quantization, stored)
+	s.opts.Quantization = stored
+	s.opts.Storage = StorageFull
+	return nil
+}
+
+// MigrateLayout applies the configured layout to the stored vectors: it re-encodes them when
+// the configured quantization differs from the stored one, drops the float32 embeddings for
+// quantized storage, reclaims the freed space and rebuilds the ANN index. Vectors already
+// stored only as codes cannot be re-encoded and must be imported again.
+func (s *SqliteVecStore) MigrateLayout(ctx context.Context) error {
+	stored, err := s.getSetting(ctx, settingQuantization)
+	if err != nil {
+		return err
+	}
+	if stored == "" {
+		stored = QuantizationNone
+	}
+
+	if stored != s.configured.Quantization {
+		var codeOnly int
+		if err := s.db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM vectors WHERE length(embedding) = 0").Scan(&codeOnly); err != nil {
+			return fmt.Errorf("failed to inspect stored vectors: %w", err)
+		}
+		if codeOnly > 0 {
+			return fmt.Errorf("%d vectors are stored only as %s codes and cannot be re-encoded as %s; "+
+				"export them and import them into a new database", codeOnly, stored, s.configured.Quantization)
+		}
+		s.opts.Quantization = s.configured.Quantization
+		if err := s.requantize(ctx); err != nil {
+			return err
+		}
+		if err := s.setSetting(ctx, settingQuantization, s.configured.Quantization); err != nil {
+			return err
+		}
+	}
+	s.opts = s.configured
+
+	if s.opts.Storage == StorageQuantized {
+		result, err := s.db.ExecContext(ctx,
+			"UPDATE vectors SET embedding = X'' WHERE length(embedding) > 0 AND quantized IS NOT NULL")
+		if err != nil {
+			return fmt.Errorf("failed to drop float32 embeddings: %w", err)
+		}
+		if dropped, _ := result.RowsAffected(); dropped > 0 {
+			log.Printf("INFO: dropped the float32 embeddings of %d vectors (SQLITE_VEC_STORAGE=quantized)", dropped)
+		}
+	}
+	if _, err := s.db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum %s: %w", s.dbPath, err)
+	}
+
+	return s.MaintainIndex(ctx)
+}
+
+// requantize replaces the quantized code of every vector with one of the configured mode
+func (s *SqliteVecStore) requantize(ctx context.Context) error {
+	cursor := ""
+	total := 0
+	for {
+		rows, err := s.db.QueryContext(ctx,
+			"SELECT key, embedding FROM vectors WHERE key > ? ORDER BY key LIMIT ?", cursor, layoutPageSize)
+		if err != nil {
+			return fmt.Errorf("failed to read vectors: %w", err)
+		}
+		keys := make([]string, 0, layoutPageSize)
+		codes := make([][]byte, 0, layoutPageSize)
+		for rows.Next() {
+			var (
+				key  string
+				data []byte
+			)
+			if err := rows.Scan(&key, &data); err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("failed to scan vector: %w", err)
+			}
+			embedding, err := decodeEmbedding(data)
+			if err != nil {
+				_ = rows.Close()
+				return fmt.Errorf("failed to deserialize embedding for %q: %w", key, err)
+			}
+			keys = append(keys, key)
+			codes = append(codes, quantize(s.opts.Quantization, embedding))
+		}
+		_ = rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to read vectors: %w", err)
+		}
+		if len(keys) == 0 {
+			break
+		}
+
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		for i, key := range keys {
+			if _, err := tx.ExecContext(ctx, "UPDATE vectors SET quantized = ? WHERE key = ?", codes[i], key); err != nil {
+				_ = tx.Rollback()
+				return fmt.Errorf("failed to update vector %q: %w", key, err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit quantized codes: %w", err)
+		}
+		total += len(keys)
+		cursor = keys[len(keys)-1]
+	}
+	if total > 0 {
+		log.Printf("INFO: re-encoded %d vectors with %s quantization", total, s.opts.Quantization)
+	}
+	return nil
+}
+
+// storageUsage holds the bytes used by the embeddings, codes and ANN centroids
+type storageUsage struct {
+	embeddingBytes int64
+	quantizedBytes int64
+	centroidBytes  int64
+	lists          int
+}
+
+func (s *SqliteVecStore) storageUsage(ctx context.Context) (*storageUsage, error) {
+	var usage storageUsage
+	err := s.db.QueryRowContext(ctx,
+		"SELECT COALESCE(SUM(length(embedding)), 0), COALESCE(SUM(length(quantized)), 0) FROM vectors").
+		Scan(&usage.embeddingBytes, &usage.quantizedBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure vector storage: %w", err)
+	}
+	err = s.db.QueryRowContext(ctx, "SELECT COUNT(*), COALESCE(SUM(length(centroid)), 0) FROM ann_lists").
+		Scan(&usage.lists, &usage.centroidBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure ANN index: %w", err)
+	}
+	return &usage, nil
+}
+
+func (u *storageUsage) bytesPerVector(count int) int64 {
+	if count == 0 {
+		return 0
+	}
+	return (u.embeddingBytes + u.quantizedBytes) / int64(count)
+}
+
+// queryScanBytes estimates the bytes a query reads: the codes (or float32 embeddings
+// without quantization) of the probed lists
+func (u *storageUsage) queryScanBytes(opts Options) int64 {
+	scanned := u.quantizedBytes
+	if opts.Quantization == QuantizationNone {
+		scanned = u.embeddingBytes
+	}
+	if opts.ANNLists > 0 && u.lists > 0 && opts.ANNProbes < u.lists {
+		scanned = scanned * int64(opts.ANNProbes) / int64(u.lists)
+	}
+	return scanned
+}
@@ -0,0 +1,242 @@
+package sqlitevec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// Quantization modes of the stored embeddings.
+const (
+	// QuantizationNone keeps only the float32 embeddings (4 bytes per dimension).
+	QuantizationNone = "none"
+	// QuantizationInt8 adds a scalar-quantized copy with one signed byte per dimension.
+	QuantizationInt8 = "int8"
+	// QuantizationBinary adds a sign-bit copy with one bit per dimension.
+	QuantizationBinary = "binary"
+)
+
+// Storage layouts of the vectors table.
+const (
+	// StorageFull keeps the float32 embeddings next to the quantized codes, so the
+	// top candidates can be rescored with full precision.
+	StorageFull = "full"
+	// StorageQuantized keeps only the quantized codes. Search results are approximate
+	// and exported embeddings are reconstructed from the codes.
+	StorageQuantized = "quantized"
+)
+
+// Default search settings.
+const (
+	DefaultRescoreFactor = 4
+	DefaultANNProbes     = 8
+)
+
+// Options configures quantization, storage layout and the ANN index of a store.
+type Options struct {
+	Quantization  string // QuantizationNone, QuantizationInt8 or QuantizationBinary
+	Storage       string // StorageFull or StorageQuantized
+	RescoreFactor int    // Candidates rescored with full precision per requested result (0 disables rescoring)
+	ANNLists      int    // Number of inverted lists of the ANN index (0 scans every vector)
+	ANNProbes     int    // Number of lists searched per query
+}
+
+// DefaultOptions returns the options of a store without quantization or ANN index.
+func DefaultOptions() Options {
+	return Options{
+		Quantization:  QuantizationNone,
+		Storage:       StorageFull,
+		RescoreFactor: DefaultRescoreFactor,
+		ANNProbes:     DefaultANNProbes,
+	}
+}
+
+// Validate checks the option values and fills in defaults for empty ones.
+func (o *Options) Validate() error {
+	if o.Quantization == "" {
+		o.Quantization = QuantizationNone
+	}
+	if o.Storage == "" {
+		o.Storage = StorageFull
+	}
+	switch o.Quantization {
+	case QuantizationNone, QuantizationInt8, QuantizationBinary:
+	default:
+		return fmt.Errorf("invalid sqlite-vec quantization %q (must be none, int8 or binary)", o.Quantization)
+	}
+	switch o.Storage {
+	case StorageFull:
+	case StorageQuantized:
+		if o.Quantization == QuantizationNone {
+			return fmt.Errorf("sqlite-vec storage %q requires int8 or binary quantization", o.Storage)
+		}
+	default:
+		return fmt.Errorf("invalid sqlite-vec storage %q (must be full or quantized)", o.Storage)
+	}
+	if o.RescoreFactor < 0 || o.ANNLists < 0 || o.ANNProbes < 0 {
+		return fmt.Errorf("sqlite-vec rescore factor, ANN lists and ANN probes must not be negative")
+	}
+	if o.ANNProbes == 0 {
+		o.ANNProbes = DefaultANNProbes
+	}
+	return nil
+}
+
+// quantizedHeaderSize is the size of the dimension and scale that prefix every code
+const quantizedHeaderSize = 8
+
+// quantize encodes an embedding as a quantized code: the dimension (uint32), a float32
+// parameter and the codes. The parameter is the int8 scale or the L2 norm for binary codes.
+func quantize(mode string, embedding []float32) []byte {
+	dim := len(embedding)
+	switch mode {
+	case QuantizationInt8:
+		maxAbs := float32(0)
+		for _, v := range embedding {
+			maxAbs = max(maxAbs, float32(math.Abs(float64(v))))
+		}
+		scale := maxAbs / 127
+		if scale == 0 {
+			scale = 1
+		}
+		code := quantizedHeader(dim, scale, dim)
+		for i, v := range embedding {
+			code[quantizedHeaderSize+i] = byte(int8(math.Round(float64(v / scale))))
+		}
+		return code
+	case QuantizationBinary:
+		code := quantizedHeader(dim, float32(norm(embedding)), (dim+7)/8)
+		for i, v := range embedding {
+			if v > 0 {
+				code[quantizedHeaderSize+i/8] |= 1 << (i % 8)
+			}
+		}
+		return code
+	default:
+		return nil
+	}
+}
+
+func quantizedHeader(dim int, param float32, codeSize int) []byte {
+	code := make([]byte, quantizedHeaderSize+codeSize)
+	binary.LittleEndian.PutUint32(code, uint32(dim))
+	binary.LittleEndian.PutUint32(code[4:], math.Float32bits(param))
+	return code
+}
+
+func parseQuantizedHeader(code []byte) (int, float32, []byte, error) {
+	if len(code) < quantizedHeaderSize {
+		return 0, 0, nil, fmt.Errorf("quantized code is too short (%d bytes)", len(code))
+	}
+	dim := int(binary.LittleEndian.Uint32(code))
+	param := math.Float32frombits(binary.LittleEndian.Uint32(code[4:]))
+	return dim, param, code[quantizedHeaderSize:], nil
+}
+
+// dequantize reconstructs an approximate embedding from a quantized code. Binary codes
+// become ±norm/√dim per dimension, which preserves the direction of the sign pattern.
+func dequantize(mode string, code []byte) ([]float32, error) {
+	dim, param, body, err := parseQuantizedHeader(code)
+	if err != nil {
+		return nil, err
+	}
+	embedding := make([]float32, dim)
+	switch mode {
+	case QuantizationInt8:
+		if len(body) != dim {
+			return nil, fmt.Errorf("int8 code has %d values, expected %d", len(body), dim)
+		}
+		for i, c := range body {
+			embedding[i] = float32(int8(c)) * param
+		}
+	case QuantizationBinary:
+		if len(body) != (dim+7)/8 {
+			return nil, fmt.Errorf("binary code has %d bytes, expected %d", len(body), (dim+7)/8)
+		}
+		magnitude := param / float32(math.Sqrt(float64(max(dim, 1))))
+		for i := range embedding {
+			if body[i/8]&(1<<(i%8)) != 0 {
+				embedding[i] = magnitude
+			} else {
+				embedding[i] = -magnitude
+			}
+		}
+	default:
+		return nil, fmt.Errorf("cannot dequantize %q codes", mode)
+	}
+	return embedding, nil
+}
+
+// queryCode holds a query vector prepared for comparisons with quantized codes
+type queryCode struct {
+	vector []float32
+	norm   float64
+	bits   []byte // Sign bits of the query for binary codes
+}
+
+func newQueryCode(mode string, vector []float32) *queryCode {
+	q := &queryCode{vector: vector, norm: norm(vector)}
+	if mode == QuantizationBinary {
+		_, _, q.bits, _ = parseQuantizedHeader(quantize(QuantizationBinary, vector))
+	}
+	return q
+}
+
+// approximateDistance returns the cosine distance between the query and a quantized code.
+// Binary codes use the Hamming distance, mapped to an angle as in SimHash.
+func (q *queryCode) approximateDistance(mode string, code []byte) (float64, error) {
+	dim, _, body, err := parseQuantizedHeader(code)
+	if err != nil {
+		return 0, err
+	}
+	if dim != len(q.vector) {
+		return 0, fmt.Errorf("dimension mismatch: query has %d dimensions, vector has %d", len(q.vector), dim)
+	}
+	switch mode {
+	case QuantizationInt8:
+		var dot, sq float64
+		for i, c := range body {
+			v := float64(int8(c))
+			dot += float64(q.vector[i]) * v
+			sq += v * v
+		}
+		// The scale cancels out of the cosine
+		if q.norm == 0 || sq == 0 {
+			return 1, nil
+		}
+		return 1 - dot/(q.norm*math.Sqrt(sq)), nil
+	case QuantizationBinary:
+		hamming := 0
+		for i := range body {
+			hamming += bits.OnesCount8(body[i] ^ q.bits[i])
+		}
+		return 1 - math.Cos(math.Pi*float64(hamming)/float64(dim)), nil
+	default:
+		return 0, fmt.Errorf("cannot compare with %q codes", mode)
+	}
+}
+
+// cosineDistance returns 1 - cosine similarity, the distance used by every search mode
+func cosineDistance(a, b []float32) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("dimension mismatch: query has %d dimensions, vector has %d", len(a), len(b))
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	na, nb := norm(a), norm(b)
+	if na == 0 || nb == 0 {
+		return 1, nil
+	}
+	return 1 - dot/(na*nb), nil
+}
+
+func norm(v []float32) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	return math.Sqrt(sum)
+}
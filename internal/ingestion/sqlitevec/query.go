@@ -0,0 +1,293 @@
+package sqlitevec
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+// filterColumns are the metadata fields QueryVectors can filter on
+var filterColumns = map[string]string{
+	"title":     "title",
+	"category":  "category",
+	"file_path": "file_path",
+	"reference": "reference",
+	"author":    "author",
+	"secret":    "secret",
+}
+
+// candidate is a vector scored by the coarse search
+type candidate struct {
+	key      string
+	distance float64
+}
+
+// candidateHeap is a max-heap on distance that keeps the closest candidates
+type candidateHeap []candidate
+
+func (h candidateHeap) Len() int           { return len(h) }
+func (h candidateHeap) Less(i, j int) bool { return h[i].distance > h[j].distance }
+func (h candidateHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *candidateHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *candidateHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// offer adds a candidate while keeping at most limit of the closest ones
+func (h *candidateHeap) offer(c candidate, limit int) {
+	if h.Len() < limit {
+		heap.Push(h, c)
+		return
+	}
+	if c.distance < (*h)[0].distance {
+		(*h)[0] = c
+		heap.Fix(h, 0)
+	}
+}
+
+// QueryVectors returns the topK vectors closest to queryVector by cosine distance.
+// With quantization the codes are scanned first and, for full storage, the best
+// topK*RescoreFactor candidates are rescored with the float32 embeddings. With an ANN
+// index only the lists of the ANNProbes nearest centroids are scanned. filter restricts
+// the search to exact metadata values, e.g. {"category": "docs"} or {"secret": false}.
+func (s *SqliteVecStore) QueryVectors(
+	ctx context.Context, queryVector []float64, topK int, filter map[string]interface{},
+) (*domain.QueryVectorsResult, error) {
+	if len(queryVector) == 0 {
+		return nil, fmt.Errorf("query vector cannot be empty")
+	}
+	if topK <= 0 {
+		topK = 10
+	}
+	query := make([]float32, len(queryVector))
+	for i, v := range queryVector {
+		query[i] = float32(v)
+	}
+
+	where, args, err := filterClause(filter)
+	if err != nil {
+		return nil, err
+	}
+	centroids, err := s.queryCentroids(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(centroids) > 0 {
+		probes := nearestCentroids(centroids, query, s.opts.ANNProbes)
+		placeholders := make([]string, len(probes))
+		for i, list := range probes {
+			placeholders[i] = "?"
+			args = append(args, list)
+		}
+		// Vectors stored before the index existed have no list yet
+		where = append(where, "(list_id IN ("+strings.Join(placeholders, ", ")+") OR list_id IS NULL)")
+	}
+
+	limit := topK
+	if s.rescores() {
+		limit = topK * s.opts.RescoreFactor
+	}
+	candidates, err := s.scanCandidates(ctx, query, where, args, limit)
+	if err != nil {
+		return nil, err
+	}
+	if s.rescores() {
+		candidates, err = s.rescore(ctx, query, candidates)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(candidates) > topK {
+		candidates = candidates[:topK]
+	}
+
+	results, err := s.queryResults(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.QueryVectorsResult{Results: results, TotalCount: len(results), TopK: topK}, nil
+}
+
+// filterClause converts an exact-match metadata filter to SQL conditions
+func filterClause(filter map[string]interface{}) ([]string, []any, error) {
+	names := make([]string, 0, len(filter))
+	for name := range filter {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var (
+		where []string
+		args  []any
+	)
+	for _, name := range names {
+		column, ok := filterColumns[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("unsupported filter field %q", name)
+		}
+		value := filter[name]
+		if ops, ok := value.(map[string]interface{}); ok {
+			eq, ok := ops["$eq"]
+			if !ok || len(ops) != 1 {
+				return nil, nil, fmt.Errorf("filter field %q only supports exact values or $eq", name)
+			}
+			value = eq
+		}
+		if b, ok := value.(bool); ok {
+			value = 0
+			if b {
+				value = 1
+			}
+		}
+		where = append(where, column+" = ?")
+		args = append(args, value)
+	}
+	return where, args, nil
+}
+
+// scanCandidates scores the codes (or float32 embeddings without quantization) of the
+// matching vectors and returns the limit closest ones, closest first
+func (s *SqliteVecStore) scanCandidates(ctx context.Context, query []float32, where []string, args []any, limit int) ([]candidate, error) {
+	column := "quantized"
+	if s.opts.Quantization == QuantizationNone {
+		column = "embedding"
+	}
+	stmt := "SELECT key, embedding, " + column + " FROM vectors"
+	if len(where) > 0 {
+		stmt += " WHERE " + strings.Join(where, " AND ")
+	}
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vectors: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	code := newQueryCode(s.opts.Quantization, query)
+	best := &candidateHeap{}
+	for rows.Next() {
+		var (
+			key        string
+			embedding  []byte
+			comparable []byte
+		)
+		if err := rows.Scan(&key, &embedding, &comparable); err != nil {
+			return nil, fmt.Errorf("failed to scan vector: %w", err)
+		}
+		distance, err := s.coarseDistance(code, key, embedding, comparable)
+		if err != nil {
+			return nil, err
+		}
+		best.offer(candidate{key: key, distance: distance}, limit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query vectors: %w", err)
+	}
+
+	candidates := make([]candidate, best.Len())
+	for i := len(candidates) - 1; i >= 0; i-- {
+		candidates[i] = heap.Pop(best).(candidate)
+	}
+	return candidates, nil
+}
+
+// coarseDistance compares the query with a stored code. Vectors stored before
+// quantization was enabled have no code and are compared in full precision.
+func (s *SqliteVecStore) coarseDistance(q *queryCode, key string, embedding, comparable []byte) (float64, error) {
+	if s.opts.Quantization != QuantizationNone && len(comparable) > 0 {
+		distance, err := q.approximateDistance(s.opts.Quantization, comparable)
+		if err != nil {
+			return 0, fmt.Errorf("vector %q: %w", key, err)
+		}
+		return distance, nil
+	}
+	vector, err := decodeEmbedding(embedding)
+	if err != nil {
+		return 0, fmt.Errorf("failed to deserialize embedding for %q: %w", key, err)
+	}
+	distance, err := cosineDistance(q.vector, vector)
+	if err != nil {
+		return 0, fmt.Errorf("vector %q: %w", key, err)
+	}
+	return distance, nil
+}
+
+// rescore recomputes the distances of the candidates with their float32 embeddings
+func (s *SqliteVecStore) rescore(ctx context.Context, query []float32, candidates []candidate) ([]candidate, error) {
+	if len(candidates) == 0 {
+		return candidates, nil
+	}
+	placeholders := make([]string, len(candidates))
+	args := make([]any, len(candidates))
+	for i, c := range candidates {
+		placeholders[i] = "?"
+		args[i] = c.key
+	}
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT key, embedding FROM vectors WHERE key IN ("+strings.Join(placeholders, ", ")+")", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read candidate embeddings: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	exact := make(map[string]float64, len(candidates))
+	for rows.Next() {
+		var (
+			key  string
+			data []byte
+		)
+		if err := rows.Scan(&key, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan candidate embedding: %w", err)
+		}
+		if len(data) == 0 {
+			continue
+		}
+		vector, err := decodeEmbedding(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to deserialize embedding for %q: %w", key, err)
+		}
+		if exact[key], err = cosineDistance(query, vector); err != nil {
+			return nil, fmt.Errorf("vector %q: %w", key, err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read candidate embeddings: %w", err)
+	}
+
+	rescored := make([]candidate, len(candidates))
+	for i, c := range candidates {
+		if distance, ok := exact[c.key]; ok {
+			c.distance = distance
+		}
+		rescored[i] = c
+	}
+	sort.SliceStable(rescored, func(i, j int) bool { return rescored[i].distance < rescored[j].distance })
+	return rescored, nil
+}
+
+// queryResults loads the metadata and content excerpt of the result vectors
+func (s *SqliteVecStore) queryResults(ctx context.Context, candidates []candidate) ([]domain.QueryResult, error) {
+	results := make([]domain.QueryResult, 0, len(candidates))
+	for _, c := range candidates {
+		items, err := s.listWithMetadata(ctx, "key = ?", c.key)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			continue
+		}
+		results = append(results, domain.QueryResult{
+			Key:      c.key,
+			Distance: c.distance,
+			Metadata: items[0].RawMetadata,
+			Content:  items[0].excerpt,
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,197 @@
+package sqlitevec
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+// unitEmbedding returns a deterministic embedding that points mostly along axis
+func unitEmbedding(axis, dim int, noise float64) []float64 {
+	v := make([]float64, dim)
+	for i := range v {
+		v[i] = noise * math.Sin(float64(axis*dim+i))
+	}
+	v[axis%dim] = 1
+	return v
+}
+
+func newTestStoreWithOptions(t *testing.T, path string, opts Options) *SqliteVecStore {
+	t.Helper()
+	store, err := NewSqliteVecStoreWithOptions(path, opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func storeEmbeddings(t *testing.T, store *SqliteVecStore, count, dim int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		err := store.StoreVector(context.Background(), &domain.VectorData{
+			ID:        fmt.Sprintf("doc-%03d", i),
+			Embedding: unitEmbedding(i, dim, 0.1),
+			Metadata:  domain.DocumentMetadata{Title: fmt.Sprintf("Doc %d", i), Category: fmt.Sprintf("cat-%d", i%2)},
+			Content:   fmt.Sprintf("content %d", i),
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestQuantize_RoundTrip(t *testing.T) {
+	embedding := []float32{0.5, -0.25, 0.125, -1, 0}
+
+	code := quantize(QuantizationInt8, embedding)
+	assert.Len(t, code, quantizedHeaderSize+len(embedding))
+	decoded, err := dequantize(QuantizationInt8, code)
+	require.NoError(t, err)
+	for i := range embedding {
+		assert.InDelta(t, embedding[i], decoded[i], 0.01)
+	}
+
+	code = quantize(QuantizationBinary, embedding)
+	assert.Len(t, code, quantizedHeaderSize+1)
+	decoded, err = dequantize(QuantizationBinary, code)
+	require.NoError(t, err)
+	assert.InDelta(t, norm(embedding), norm(decoded), 1e-5)
+	assert.Greater(t, decoded[0], float32(0))
+	assert.Less(t, decoded[1], float32(0))
+}
+
+func TestOptionsValidate(t *testing.T) {
+	opts := Options{}
+	require.NoError(t, opts.Validate())
+	assert.Equal(t, QuantizationNone, opts.Quantization)
+	assert.Equal(t, StorageFull, opts.Storage)
+	assert.Equal(t, DefaultANNProbes, opts.ANNProbes)
+
+	assert.Error(t, (&Options{Quantization: "int4"}).Validate())
+	assert.Error(t, (&Options{Storage: StorageQuantized}).Validate())
+	assert.Error(t, (&Options{ANNLists: -1}).Validate())
+}
+
+func TestQueryVectors(t *testing.T) {
+	const dim = 32
+	for _, opts := range []Options{
+		{Quantization: QuantizationNone},
+		{Quantization: QuantizationInt8, RescoreFactor: 4},
+		{Quantization: QuantizationBinary, RescoreFactor: 4},
+		{Quantization: QuantizationInt8, Storage: StorageQuantized},
+		{Quantization: QuantizationInt8, RescoreFactor: 4, ANNLists: 2, ANNProbes: 2},
+	} {
+		t.Run(fmt.Sprintf("%s-%s-%d", opts.Quantization, opts.Storage, opts.ANNLists), func(t *testing.T) {
+			store := newTestStoreWithOptions(t, filepath.Join(t.TempDir(), "test.db"), opts)
+			storeEmbeddings(t, store, 40, dim)
+
+			result, err := store.QueryVectors(context.Background(), unitEmbedding(7, dim, 0.1), 3, nil)
+			require.NoError(t, err)
+			require.Len(t, result.Results, 3)
+			assert.Equal(t, "doc-007", result.Results[0].Key)
+			assert.Equal(t, "content 7", result.Results[0].Content)
+			assert.Equal(t, "Doc 7", result.Results[0].Metadata["title"])
+			assert.InDelta(t, 0, result.Results[0].Distance, 0.05)
+
+			filtered, err := store.QueryVectors(context.Background(), unitEmbedding(7, dim, 0.1), 5,
+				map[string]interface{}{"category": "cat-0"})
+			require.NoError(t, err)
+			for _, r := range filtered.Results {
+				assert.Equal(t, "cat-0", r.Metadata["category"])
+			}
+		})
+	}
+}
+
+func TestQueryVectors_UnsupportedFilter(t *testing.T) {
+	store := newTestStore(t)
+	_, err := store.QueryVectors(context.Background(), []float64{1, 0}, 1, map[string]interface{}{"content": "x"})
+	assert.Error(t, err)
+}
+
+func TestLayoutChange_Requantizes(t *testing.T) {
+	const dim = 16
+	path := filepath.Join(t.TempDir(), "test.db")
+	store, err := NewSqliteVecStore(path)
+	require.NoError(t, err)
+	storeEmbeddings(t, store, 5, dim)
+	require.NoError(t, store.Close())
+
+	// Opening with a different layout leaves the stored vectors untouched
+	quantized := newTestStoreWithOptions(t, path, Options{Quantization: QuantizationBinary, Storage: StorageQuantized})
+	info, err := quantized.GetBackendInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(5*dim*4), info["embedding_bytes"])
+	result, err := quantized.QueryVectors(context.Background(), unitEmbedding(3, dim, 0.1), 1, nil)
+	require.NoError(t, err)
+	require.Len(t, result.Results, 1)
+	assert.Equal(t, "doc-003", result.Results[0].Key)
+
+	require.NoError(t, quantized.MigrateLayout(context.Background()))
+	info, err = quantized.GetBackendInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), info["embedding_bytes"])
+	assert.Equal(t, int64(5*(quantizedHeaderSize+dim/8)), info["quantized_bytes"])
+	assert.Equal(t, int64(quantizedHeaderSize+dim/8), info["bytes_per_vector"])
+
+	vectors, _, err := quantized.ExportVectors(context.Background(), "", 10)
+	require.NoError(t, err)
+	require.Len(t, vectors, 5)
+	assert.Len(t, vectors[0].Embedding, dim)
+	require.NoError(t, quantized.Close())
+
+	// Codes cannot be re-encoded without the float32 embeddings
+	int8Store := newTestStoreWithOptions(t, path, Options{Quantization: QuantizationInt8})
+	assert.Error(t, int8Store.MigrateLayout(context.Background()))
+}
+
+func TestBuildANNIndex(t *testing.T) {
+	const dim = 8
+	store := newTestStoreWithOptions(t, filepath.Join(t.TempDir(), "test.db"),
+		Options{ANNLists: 4, ANNProbes: 1})
+	storeEmbeddings(t, store, 64, dim)
+
+	centroids, err := store.BuildANNIndex(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, centroids, 4)
+
+	var unassigned int
+	require.NoError(t, store.db.QueryRow("SELECT COUNT(*) FROM vectors WHERE list_id IS NULL").Scan(&unassigned))
+	assert.Equal(t, 0, unassigned)
+
+	info, err := store.GetBackendInfo(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, info["ann_lists"])
+	assert.Equal(t, int64(4*dim*4), info["ann_centroid_bytes"])
+}
+
+func TestMaintainIndex(t *testing.T) {
+	const dim = 8
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+	store := newTestStoreWithOptions(t, path, Options{ANNLists: 4, ANNProbes: 1})
+	storeEmbeddings(t, store, 64, dim)
+
+	// Queries never train the index
+	_, err := store.QueryVectors(ctx, unitEmbedding(7, dim, 0.1), 3, nil)
+	require.NoError(t, err)
+	centroids, err := store.annCentroids(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, centroids)
+
+	require.NoError(t, store.MaintainIndex(ctx))
+	centroids, err = store.annCentroids(ctx)
+	require.NoError(t, err)
+	assert.Len(t, centroids, 4)
+
+	// Another store sharing the database picks up a rebuilt index
+	other := newTestStoreWithOptions(t, path, Options{ANNLists: 2, ANNProbes: 1})
+	require.NoError(t, other.MaintainIndex(ctx))
+	centroids, err = store.annCentroids(ctx)
+	require.NoError(t, err)
+	assert.Len(t, centroids, 2)
+}
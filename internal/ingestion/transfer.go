@@ -0,0 +1,413 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	"github.com/ca-srg/ragent/internal/ingestion/transfer"
+	"github.com/ca-srg/ragent/internal/ingestion/vectorizer"
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	"github.com/ca-srg/ragent/internal/pkg/opensearch"
+)
+
+// Vector endpoints that export, import and migrate can read from or write to
+const (
+	TransferEndpointS3         = "s3"
+	TransferEndpointSqlite     = "sqlite"
+	TransferEndpointOpenSearch = "opensearch"
+)
+
+// TransferEndpointOptions selects the vector store or OpenSearch index of a transfer
+type TransferEndpointOptions struct {
+	SqlitePath     string // Overrides SQLITE_VEC_DB_PATH
+	Index          string // Overrides OPENSEARCH_INDEX
+	S3VectorRegion string
+}
+
+// ExportOptions holds the flags of the export command
+type ExportOptions struct {
+	From      string
+	Output    string
+	BatchSize int
+	Resume    bool
+	Endpoint  TransferEndpointOptions
+}
+
+// ImportOptions holds the flags of the import command
+type ImportOptions struct {
+	Input     string
+	To        string
+	BatchSize int
+	Resume    bool
+	Endpoint  TransferEndpointOptions
+}
+
+// MigrateOptions holds the flags of the migrate command
+type MigrateOptions struct {
+	From      string
+	To        string
+	BatchSize int
+	Resume    bool
+	Endpoint  TransferEndpointOptions
+}
+
+// transferPlan names both endpoints of a transfer and opens them.
+// The names identify the transfer's checkpoint.
+type transferPlan struct {
+	sourceName string
+	sinkName   string
+	openSource func() (vectorizer.VectorExporter, io.Closer, error)
+	openSink   func(checkpoint *hashstore.TransferCheckpoint) (transfer.Sink, io.Closer, error)
+}
+
+// transferCheckpointStore persists transfer progress
+type transferCheckpointStore interface {
+	SaveTransferCheckpoint(ctx context.Context, checkpoint *hashstore.TransferCheckpoint) error
+	DeleteTransferCheckpoint(ctx context.Context, name string) error
+}
+
+// RunExport writes every vector of a vector store or OpenSearch index to a JSONL file
+func RunExport(cmd *cobra.Command, opts ExportOptions) error {
+	if opts.Output == "" {
+		return fmt.Errorf("--output is required")
+	}
+	output, err := filepath.Abs(opts.Output)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", opts.Output, err)
+	}
+
+	return runVectorTransfer(cmd, opts.Resume, opts.BatchSize, opts.Endpoint, func(cfg *appconfig.Config) (*transferPlan, error) {
+		from := defaultTransferEndpoint(opts.From, cfg)
+		sourceName, err := transferEndpointName(cfg, from, opts.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		return &transferPlan{
+			sourceName: sourceName,
+			sinkName:   "file:" + output,
+			openSource: func() (vectorizer.VectorExporter, io.Closer, error) {
+				return openTransferSource(cfg, from, opts.Endpoint)
+			},
+			openSink: func(checkpoint *hashstore.TransferCheckpoint) (transfer.Sink, io.Closer, error) {
+				offset := int64(0)
+				if checkpoint != nil {
+					offset = checkpoint.Offset
+				}
+				sink, err := transfer.CreateFileSink(output, offset)
+				if err != nil {
+					return nil, nil, err
+				}
+				return sink, sink, nil
+			},
+		}, nil
+	})
+}
+
+// RunImport loads a JSONL export into a vector store or OpenSearch index
+func RunImport(cmd *cobra.Command, opts ImportOptions) error {
+	if opts.Input == "" {
+		return fmt.Errorf("--input is required")
+	}
+	input, err := filepath.Abs(opts.Input)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", opts.Input, err)
+	}
+
+	return runVectorTransfer(cmd, opts.Resume, opts.BatchSize, opts.Endpoint, func(cfg *appconfig.Config) (*transferPlan, error) {
+		to := defaultTransferEndpoint(opts.To, cfg)
+		sinkName, err := transferEndpointName(cfg, to, opts.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		return &transferPlan{
+			sourceName: "file:" + input,
+			sinkName:   sinkName,
+			openSource: func() (vectorizer.VectorExporter, io.Closer, error) {
+				source, err := transfer.OpenFileSource(input)
+				if err != nil {
+					return nil, nil, err
+				}
+				return source, source, nil
+			},
+			openSink: func(checkpoint *hashstore.TransferCheckpoint) (transfer.Sink, io.Closer, error) {
+				return openTransferSink(cfg, to, opts.Endpoint)
+			},
+		}, nil
+	})
+}
+
+// RunMigrate copies every vector from one backend to another, e.g. from S3 Vectors to sqlite-vec
+func RunMigrate(cmd *cobra.Command, opts MigrateOptions) error {
+	if opts.From == "" || opts.To == "" {
+		return fmt.Errorf("--from and --to are required")
+	}
+	if opts.From == opts.To {
+		return fmt.Errorf("--from and --to must be different backends")
+	}
+
+	return runVectorTransfer(cmd, opts.Resume, opts.BatchSize, opts.Endpoint, func(cfg *appconfig.Config) (*transferPlan, error) {
+		sourceName, err := transferEndpointName(cfg, opts.From, opts.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		sinkName, err := transferEndpointName(cfg, opts.To, opts.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		return &transferPlan{
+			sourceName: sourceName,
+			sinkName:   sinkName,
+			openSource: func() (vectorizer.VectorExporter, io.Closer, error) {
+				return openTransferSource(cfg, opts.From, opts.Endpoint)
+			},
+			openSink: func(checkpoint *hashstore.TransferCheckpoint) (transfer.Sink, io.Closer, error) {
+				return openTransferSink(cfg, opts.To, opts.Endpoint)
+			},
+		}, nil
+	})
+}
+
+// runVectorTransfer opens both endpoints of the plan and copies the vectors, checkpointing
+// after every batch. With resume the transfer continues from the checkpoint of an
+// interrupted run between the same endpoints; otherwise it starts over.
+func runVectorTransfer(
+	cmd *cobra.Command,
+	resume bool,
+	batchSize int,
+	endpoint TransferEndpointOptions,
+	newPlan func(cfg *appconfig.Config) (*transferPlan, error),
+) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cfg, err := appconfig.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if endpoint.S3VectorRegion != "" {
+		cfg.S3VectorRegion = endpoint.S3VectorRegion
+	}
+
+	plan, err := newPlan(cfg)
+	if err != nil {
+		return err
+	}
+	if plan.sourceName == plan.sinkName {
+		return fmt.Errorf("source and destination are both %s", plan.sourceName)
+	}
+	name := plan.sourceName + "->" + plan.sinkName
+
+	store, err := hashstore.NewHashStore()
+	if err != nil {
+		return fmt.Errorf("failed to open hash store: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	var checkpoint *hashstore.TransferCheckpoint
+	if resume {
+		checkpoint, err = store.GetTransferCheckpoint(ctx, name)
+		if err != nil {
+			return err
+		}
+		if checkpoint == nil {
+			log.Printf("No checkpoint found for %s, starting from the beginning", name)
+		} else {
+			log.Printf("Resuming %s after %d vectors (checkpoint of %s)", name, checkpoint.Transferred, checkpoint.UpdatedAt.Format("2006-01-02 15:04:05"))
+		}
+	}
+
+	source, sourceCloser, err := plan.openSource()
+	if err != nil {
+		return err
+	}
+	if sourceCloser != nil {
+		defer func() { _ = sourceCloser.Close() }()
+	}
+
+	sink, sinkCloser, err := plan.openSink(checkpoint)
+	if err != nil {
+		return err
+	}
+	if sinkCloser != nil {
+		defer func() { _ = sinkCloser.Close() }()
+	}
+
+	if isExcerptEndpoint(plan.sourceName) && strings.HasPrefix(plan.sinkName, TransferEndpointOpenSearch+":") {
+		log.Printf("Warning: %s stores only a content excerpt; BM25 search in OpenSearch matches that excerpt until the documents are re-vectorized", plan.sourceName)
+	}
+
+	log.Printf("Transferring vectors from %s to %s", plan.sourceName, plan.sinkName)
+	copied, err := copyWithCheckpoint(ctx, store, name, source, sink, batchSize, checkpoint)
+	if err != nil {
+		return fmt.Errorf("transfer stopped after %d vectors (rerun with --resume to continue): %w", copied, err)
+	}
+
+	fmt.Printf("Transferred %d vectors from %s to %s\n", copied, plan.sourceName, plan.sinkName)
+	return nil
+}
+
+// defaultTransferEndpoint falls back to the configured VECTOR_DB_BACKEND
+func defaultTransferEndpoint(kind string, cfg *appconfig.Config) string {
+	if kind != "" {
+		return kind
+	}
+	return cfg.VectorDBBackend
+}
+
+// transferEndpointName identifies an endpoint, e.g. "sqlite:/home/me/.ragent/vectors.db"
+func transferEndpointName(cfg *appconfig.Config, kind string, opts TransferEndpointOptions) (string, error) {
+	switch kind {
+	case TransferEndpointS3:
+		return fmt.Sprintf("%s:%s/%s", kind, cfg.AWSS3VectorBucket, cfg.AWSS3VectorIndex), nil
+	case TransferEndpointSqlite:
+		path := opts.SqlitePath
+		if path == "" {
+			path = cfg.SqliteVecDBPath
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		return kind + ":" + path, nil
+	case TransferEndpointOpenSearch:
+		index, err := transferOpenSearchIndex(opts)
+		if err != nil {
+			return "", err
+		}
+		return kind + ":" + index, nil
+	default:
+		return "", fmt.Errorf("unsupported backend %q (must be %q, %q or %q)", kind, TransferEndpointS3, TransferEndpointSqlite, TransferEndpointOpenSearch)
+	}
+}
+
+// isExcerptEndpoint reports whether the endpoint stores only an excerpt of each document
+func isExcerptEndpoint(name string) bool {
+	return strings.HasPrefix(name, TransferEndpointS3+":") || strings.HasPrefix(name, TransferEndpointSqlite+":")
+}
+
+// transferOpenSearchIndex returns --index, or OPENSEARCH_INDEX when it is not set
+func transferOpenSearchIndex(opts TransferEndpointOptions) (string, error) {
+	if opts.Index != "" {
+		return opts.Index, nil
+	}
+	if err := validateOpenSearchFlags(); err != nil {
+		return "", fmt.Errorf("flag validation failed: %w", err)
+	}
+	return openSearchIndexName, nil
+}
+
+// openTransferSource opens a vector store or OpenSearch index for reading
+func openTransferSource(cfg *appconfig.Config, kind string, opts TransferEndpointOptions) (vectorizer.VectorExporter, io.Closer, error) {
+	if kind == TransferEndpointOpenSearch {
+		client, index, err := openTransferOpenSearch(cfg, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return transfer.NewOpenSearchSource(client, index), nil, nil
+	}
+
+	store, closer, err := openTransferVectorStore(cfg, kind, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	exporter, ok := store.(vectorizer.VectorExporter)
+	if !ok {
+		return nil, nil, fmt.Errorf("the %s backend does not support export", kind)
+	}
+	return exporter, closer, nil
+}
+
+// openTransferSink opens a vector store or OpenSearch index for writing
+func openTransferSink(cfg *appconfig.Config, kind string, opts TransferEndpointOptions) (transfer.Sink, io.Closer, error) {
+	if kind == TransferEndpointOpenSearch {
+		client, index, err := openTransferOpenSearch(cfg, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return transfer.NewOpenSearchSink(client, index, opensearch.NewKNNIndexProfileFromConfig(cfg)), nil, nil
+	}
+
+	store, closer, err := openTransferVectorStore(cfg, kind, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return transfer.NewStoreSink(store), closer, nil
+}
+
+// openTransferVectorStore creates the S3 Vectors or sqlite-vec store through the service factory
+func openTransferVectorStore(cfg *appconfig.Config, kind string, opts TransferEndpointOptions) (vectorizer.VectorStore, io.Closer, error) {
+	storeCfg := *cfg
+	storeCfg.VectorDBBackend = kind
+	if kind == TransferEndpointSqlite && opts.SqlitePath != "" {
+		storeCfg.SqliteVecDBPath = opts.SqlitePath
+	}
+
+	store, err := vectorizer.NewServiceFactory(&storeCfg).CreateVectorStore()
+	if err != nil {
+		return nil, nil, err
+	}
+	closer, _ := store.(io.Closer)
+	return store, closer, nil
+}
+
+// openTransferOpenSearch connects to OpenSearch and resolves the index of the transfer
+func openTransferOpenSearch(cfg *appconfig.Config, opts TransferEndpointOptions) (*opensearch.Client, string, error) {
+	index, err := transferOpenSearchIndex(opts)
+	if err != nil {
+		return nil, "", err
+	}
+	client, err := opensearch.NewClient(vectorizer.NewIndexerFactory(cfg).GetOpenSearchConfiguration())
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create OpenSearch client: %w", err)
+	}
+	return client, index, nil
+}
+
+// copyWithCheckpoint copies src into dst, saving a checkpoint after every batch and
+// deleting it once the transfer completes
+func copyWithCheckpoint(
+	ctx context.Context,
+	store transferCheckpointStore,
+	name string,
+	src vectorizer.VectorExporter,
+	dst transfer.Sink,
+	batchSize int,
+	checkpoint *hashstore.TransferCheckpoint,
+) (int64, error) {
+	opts := transfer.CopyOptions{BatchSize: batchSize}
+	if checkpoint != nil {
+		opts.Cursor = checkpoint.Cursor
+		opts.Copied = checkpoint.Transferred
+	}
+	opts.OnBatch = func(p transfer.Progress) error {
+		if p.Cursor == "" {
+			return nil
+		}
+		next := &hashstore.TransferCheckpoint{Name: name, Cursor: p.Cursor, Transferred: p.Copied}
+		if fileSink, ok := dst.(interface{ Offset() int64 }); ok {
+			next.Offset = fileSink.Offset()
+		}
+		if err := store.SaveTransferCheckpoint(ctx, next); err != nil {
+			return err
+		}
+		log.Printf("Transferred %d vectors", p.Copied)
+		return nil
+	}
+
+	copied, err := transfer.Copy(ctx, src, dst, opts)
+	if err != nil {
+		return copied, err
+	}
+	if err := store.DeleteTransferCheckpoint(ctx, name); err != nil {
+		log.Printf("Warning: failed to delete transfer checkpoint %s: %v", name, err)
+	}
+	return copied, nil
+}
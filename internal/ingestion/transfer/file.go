@@ -0,0 +1,179 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+// Record is one vector of the portable export format, written as one JSON object per line
+type Record struct {
+	ID        string                     `json:"id"`
+	Vector    []float64                  `json:"vector"`
+	Content   string                     `json:"content"`
+	Metadata  pkgdomain.DocumentMetadata `json:"metadata"`
+	CreatedAt time.Time                  `json:"created_at"`
+}
+
+// NewRecord converts stored vector data to a Record
+func NewRecord(vd *pkgdomain.VectorData) *Record {
+	return &Record{
+		ID:        vd.ID,
+		Vector:    vd.Embedding,
+		Content:   vd.Content,
+		Metadata:  vd.Metadata,
+		CreatedAt: vd.CreatedAt,
+	}
+}
+
+// VectorData converts the record back to vector data
+func (r *Record) VectorData() *pkgdomain.VectorData {
+	return &pkgdomain.VectorData{
+		ID:        r.ID,
+		Embedding: r.Vector,
+		Content:   r.Content,
+		Metadata:  r.Metadata,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// FileSink writes vectors to a JSONL export file.
+// Each batch is flushed and synced, so Offset is a safe point to resume from.
+type FileSink struct {
+	file   *os.File
+	writer *bufio.Writer
+	offset int64
+}
+
+// CreateFileSink creates the export file at path. A positive offset resumes an earlier
+// export: the file is truncated to offset, dropping a batch that was not checkpointed.
+func CreateFileSink(path string, offset int64) (*FileSink, error) {
+	flags := os.O_CREATE | os.O_WRONLY
+	if offset <= 0 {
+		flags |= os.O_TRUNC
+		offset = 0
+	}
+	file, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	if offset > 0 {
+		if err := file.Truncate(offset); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to truncate %s to the checkpoint: %w", path, err)
+		}
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to seek %s: %w", path, err)
+		}
+	}
+	return &FileSink{file: file, writer: bufio.NewWriter(file), offset: offset}, nil
+}
+
+// WriteVectors appends one line per vector and syncs the file
+func (s *FileSink) WriteVectors(ctx context.Context, vectors []*pkgdomain.VectorData) error {
+	for _, vector := range vectors {
+		line, err := json.Marshal(NewRecord(vector))
+		if err != nil {
+			return fmt.Errorf("failed to marshal vector %s: %w", vector.ID, err)
+		}
+		n, err := s.writer.Write(append(line, '\n'))
+		s.offset += int64(n)
+		if err != nil {
+			return fmt.Errorf("failed to write vector %s: %w", vector.ID, err)
+		}
+	}
+	if err := s.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush export file: %w", err)
+	}
+	return s.file.Sync()
+}
+
+// Offset returns the size of the file after the last written batch
+func (s *FileSink) Offset() int64 {
+	return s.offset
+}
+
+// Close flushes and closes the file
+func (s *FileSink) Close() error {
+	if err := s.writer.Flush(); err != nil {
+		_ = s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
+
+// FileSource reads vectors from a JSONL export file. Its cursor is a byte offset.
+type FileSource struct {
+	file   *os.File
+	reader *bufio.Reader
+	offset int64
+}
+
+// OpenFileSource opens a JSONL export file
+func OpenFileSource(path string) (*FileSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	return &FileSource{file: file, reader: bufio.NewReaderSize(file, 1024*1024)}, nil
+}
+
+// ExportVectors reads up to limit records starting at the byte offset given as cursor
+func (s *FileSource) ExportVectors(ctx context.Context, cursor string, limit int) ([]*pkgdomain.VectorData, string, error) {
+	if limit <= 0 {
+		limit = DefaultBatchSize
+	}
+	offset := int64(0)
+	if cursor != "" {
+		var err error
+		offset, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil || offset < 0 {
+			return nil, "", fmt.Errorf("invalid file cursor %q", cursor)
+		}
+	}
+	if offset != s.offset {
+		if _, err := s.file.Seek(offset, io.SeekStart); err != nil {
+			return nil, "", fmt.Errorf("failed to seek to %d: %w", offset, err)
+		}
+		s.reader.Reset(s.file)
+		s.offset = offset
+	}
+
+	vectors := []*pkgdomain.VectorData{}
+	for len(vectors) < limit {
+		line, err := s.reader.ReadBytes('\n')
+		s.offset += int64(len(line))
+		if len(line) > 0 {
+			trimmed := bytes.TrimRight(line, "\r\n")
+			if len(trimmed) > 0 {
+				var record Record
+				if jsonErr := json.Unmarshal(trimmed, &record); jsonErr != nil {
+					return nil, "", fmt.Errorf("invalid record at byte %d: %w", s.offset-int64(len(line)), jsonErr)
+				}
+				vectors = append(vectors, record.VectorData())
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return vectors, "", nil
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read export file: %w", err)
+		}
+	}
+	return vectors, strconv.FormatInt(s.offset, 10), nil
+}
+
+// Close closes the file
+func (s *FileSource) Close() error {
+	return s.file.Close()
+}
@@ -0,0 +1,161 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ca-srg/ragent/internal/ingestion/vectorizer"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+	"github.com/ca-srg/ragent/internal/pkg/opensearch"
+)
+
+// OpenSearchSource reads vectors from the embedding field of an OpenSearch index.
+// Its cursor is the last document ID of the previous page.
+type OpenSearchSource struct {
+	client *opensearch.Client
+	index  string
+}
+
+// NewOpenSearchSource creates a source that scans index
+func NewOpenSearchSource(client *opensearch.Client, index string) *OpenSearchSource {
+	return &OpenSearchSource{client: client, index: index}
+}
+
+// storedDocument is the subset of an indexed document that is transferred
+type storedDocument struct {
+	Title        string                 `json:"title"`
+	Content      string                 `json:"content"`
+	Category     string                 `json:"category"`
+	Tags         []string               `json:"tags"`
+	Author       string                 `json:"author"`
+	Reference    string                 `json:"reference"`
+	Source       string                 `json:"source"`
+	FilePath     string                 `json:"file_path"`
+	WordCount    int                    `json:"word_count"`
+	Secret       bool                   `json:"secret"`
+	Tenant       string                 `json:"tenant"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+	Embedding    []float64              `json:"embedding"`
+	CustomFields map[string]interface{} `json:"custom_fields"`
+
+	AllowedGroups []string `json:"allowed_groups"`
+}
+
+// ExportVectors returns up to limit documents ordered by ID
+func (s *OpenSearchSource) ExportVectors(ctx context.Context, cursor string, limit int) ([]*pkgdomain.VectorData, string, error) {
+	if limit <= 0 {
+		limit = DefaultBatchSize
+	}
+	docs, err := s.client.ScanDocuments(ctx, s.index, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	vectors := make([]*pkgdomain.VectorData, 0, len(docs))
+	for _, doc := range docs {
+		var stored storedDocument
+		if err := json.Unmarshal(doc.Source, &stored); err != nil {
+			return nil, "", fmt.Errorf("failed to decode document %s: %w", doc.ID, err)
+		}
+		vectors = append(vectors, &pkgdomain.VectorData{
+			ID:        doc.ID,
+			Embedding: stored.Embedding,
+			Content:   stored.Content,
+			CreatedAt: stored.CreatedAt,
+			Metadata: pkgdomain.DocumentMetadata{
+				Title:        stored.Title,
+				Category:     stored.Category,
+				Tags:         stored.Tags,
+				CreatedAt:    stored.CreatedAt,
+				UpdatedAt:    stored.UpdatedAt,
+				Author:       stored.Author,
+				Reference:    stored.Reference,
+				Source:       stored.Source,
+				FilePath:     stored.FilePath,
+				WordCount:    stored.WordCount,
+				Secret:       stored.Secret,
+				Tenant:       stored.Tenant,
+				CustomFields: stored.CustomFields,
+
+				AllowedGroups: stored.AllowedGroups,
+			},
+		})
+	}
+
+	next := ""
+	if len(docs) == limit {
+		next = docs[len(docs)-1].ID
+	}
+	return vectors, next, nil
+}
+
+// OpenSearchSink bulk-indexes vectors into an OpenSearch index, creating it with the
+// Japanese mapping and the dimension of the first vector if it does not exist
+type OpenSearchSink struct {
+	indexer *vectorizer.OpenSearchIndexerImpl
+	index   string
+	ready   bool
+}
+
+// NewOpenSearchSink creates a sink that writes into index, creating it with profile
+func NewOpenSearchSink(client *opensearch.Client, index string, profile opensearch.KNNIndexProfile) *OpenSearchSink {
+	indexer := vectorizer.NewOpenSearchIndexer(client, index, 0)
+	indexer.SetIndexProfile(profile)
+	return &OpenSearchSink{indexer: indexer, index: index}
+}
+
+// WriteVectors indexes a batch of vectors
+func (s *OpenSearchSink) WriteVectors(ctx context.Context, vectors []*pkgdomain.VectorData) error {
+	if len(vectors) == 0 {
+		return nil
+	}
+	if !s.ready {
+		exists, err := s.indexer.IndexExists(ctx, s.index)
+		if err != nil {
+			return fmt.Errorf("failed to check index %s: %w", s.index, err)
+		}
+		if !exists {
+			dimension := len(vectors[0].Embedding)
+			log.Printf("Creating OpenSearch index %s with %d-dimensional embedding field", s.index, dimension)
+			if err := s.indexer.CreateVectorIndexWithJapanese(ctx, s.index, dimension); err != nil {
+				return fmt.Errorf("failed to create index %s: %w", s.index, err)
+			}
+		}
+		s.ready = true
+	}
+
+	docs := make([]*vectorizer.OpenSearchDocument, 0, len(vectors))
+	for _, vector := range vectors {
+		docs = append(docs, vectorizer.NewOpenSearchDocument(completeForIndexing(vector), ""))
+	}
+	return s.indexer.IndexDocuments(ctx, s.index, docs)
+}
+
+// completeForIndexing fills the fields OpenSearch documents require but vector stores do
+// not keep, such as the title and timestamps of vectors exported from S3 Vectors
+func completeForIndexing(vector *pkgdomain.VectorData) *pkgdomain.VectorData {
+	vd := *vector
+	if vd.Metadata.Title == "" {
+		vd.Metadata.Title = vd.Metadata.FilePath
+		if vd.Metadata.Title == "" {
+			vd.Metadata.Title = vd.ID
+		}
+	}
+	if vd.Content == "" {
+		vd.Content = vd.Metadata.Title
+	}
+	if vd.CreatedAt.IsZero() {
+		vd.CreatedAt = time.Now()
+	}
+	if vd.Metadata.CreatedAt.IsZero() {
+		vd.Metadata.CreatedAt = vd.CreatedAt
+	}
+	if vd.Metadata.UpdatedAt.IsZero() {
+		vd.Metadata.UpdatedAt = vd.Metadata.CreatedAt
+	}
+	return &vd
+}
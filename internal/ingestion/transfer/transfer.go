@@ -0,0 +1,103 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ca-srg/ragent/internal/ingestion/vectorizer"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+// DefaultBatchSize is the number of vectors read and written per batch
+const DefaultBatchSize = 200
+
+// Sink receives the vectors of a transfer batch by batch
+type Sink interface {
+	WriteVectors(ctx context.Context, vectors []*pkgdomain.VectorData) error
+}
+
+// Progress is reported after each batch has been written to the sink
+type Progress struct {
+	Cursor string // Cursor of the next batch; "" once the source is exhausted
+	Copied int64  // Vectors copied so far, including Copied of CopyOptions
+}
+
+// CopyOptions controls a transfer
+type CopyOptions struct {
+	BatchSize int
+	Cursor    string               // Cursor to start from, e.g. from a checkpoint
+	Copied    int64                // Vectors copied before Cursor
+	OnBatch   func(Progress) error // Called after each batch, e.g. to save a checkpoint
+}
+
+// Copy streams every vector of src into dst in batches and returns the total number copied.
+// All vectors must have the same dimension.
+func Copy(ctx context.Context, src vectorizer.VectorExporter, dst Sink, opts CopyOptions) (int64, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	cursor := opts.Cursor
+	copied := opts.Copied
+	dimension := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return copied, err
+		}
+
+		vectors, next, err := src.ExportVectors(ctx, cursor, batchSize)
+		if err != nil {
+			return copied, fmt.Errorf("failed to read vectors: %w", err)
+		}
+
+		for _, vector := range vectors {
+			if len(vector.Embedding) == 0 {
+				return copied, fmt.Errorf("vector %s has no embedding", vector.ID)
+			}
+			if dimension == 0 {
+				dimension = len(vector.Embedding)
+			} else if len(vector.Embedding) != dimension {
+				return copied, fmt.Errorf("vector %s has %d dimensions, expected %d", vector.ID, len(vector.Embedding), dimension)
+			}
+		}
+
+		if len(vectors) > 0 {
+			if err := dst.WriteVectors(ctx, vectors); err != nil {
+				return copied, fmt.Errorf("failed to write vectors: %w", err)
+			}
+			copied += int64(len(vectors))
+		}
+
+		if opts.OnBatch != nil {
+			if err := opts.OnBatch(Progress{Cursor: next, Copied: copied}); err != nil {
+				return copied, err
+			}
+		}
+
+		if next == "" {
+			return copied, nil
+		}
+		cursor = next
+	}
+}
+
+// StoreSink writes vectors into a VectorStore
+type StoreSink struct {
+	store vectorizer.VectorStore
+}
+
+// NewStoreSink creates a sink that writes into store
+func NewStoreSink(store vectorizer.VectorStore) *StoreSink {
+	return &StoreSink{store: store}
+}
+
+// WriteVectors stores each vector
+func (s *StoreSink) WriteVectors(ctx context.Context, vectors []*pkgdomain.VectorData) error {
+	for _, vector := range vectors {
+		if err := s.store.StoreVector(ctx, vector); err != nil {
+			return fmt.Errorf("failed to store vector %s: %w", vector.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package transfer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+// memorySource pages through a fixed list of vectors; its cursor is the next position
+type memorySource struct {
+	vectors []*pkgdomain.VectorData
+	calls   int
+}
+
+func (m *memorySource) ExportVectors(ctx context.Context, cursor string, limit int) ([]*pkgdomain.VectorData, string, error) {
+	m.calls++
+	start := 0
+	if cursor != "" {
+		start, _ = strconv.Atoi(cursor)
+	}
+	end := min(start+limit, len(m.vectors))
+	next := ""
+	if end < len(m.vectors) {
+		next = strconv.Itoa(end)
+	}
+	return m.vectors[start:end], next, nil
+}
+
+type memorySink struct {
+	ids []string
+}
+
+func (m *memorySink) WriteVectors(ctx context.Context, vectors []*pkgdomain.VectorData) error {
+	for _, v := range vectors {
+		m.ids = append(m.ids, v.ID)
+	}
+	return nil
+}
+
+func testVectors(ids ...string) []*pkgdomain.VectorData {
+	vectors := make([]*pkgdomain.VectorData, 0, len(ids))
+	for _, id := range ids {
+		vectors = append(vectors, &pkgdomain.VectorData{
+			ID:        id,
+			Embedding: []float64{0.1, 0.2, 0.3},
+			Content:   "content of " + id,
+			Metadata:  pkgdomain.DocumentMetadata{Title: id, Tags: []string{"t"}, CustomFields: map[string]interface{}{"team": "search"}},
+			CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		})
+	}
+	return vectors
+}
+
+func TestCopy_StreamsInBatchesAndReportsProgress(t *testing.T) {
+	src := &memorySource{vectors: testVectors("a", "b", "c", "d", "e")}
+	dst := &memorySink{}
+	var progress []Progress
+
+	copied, err := Copy(context.Background(), src, dst, CopyOptions{
+		BatchSize: 2,
+		OnBatch: func(p Progress) error {
+			progress = append(progress, p)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), copied)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, dst.ids)
+	assert.Equal(t, []Progress{{Cursor: "2", Copied: 2}, {Cursor: "4", Copied: 4}, {Cursor: "", Copied: 5}}, progress)
+}
+
+func TestCopy_ResumesFromCursor(t *testing.T) {
+	src := &memorySource{vectors: testVectors("a", "b", "c", "d")}
+	dst := &memorySink{}
+
+	copied, err := Copy(context.Background(), src, dst, CopyOptions{BatchSize: 2, Cursor: "2", Copied: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), copied)
+	assert.Equal(t, []string{"c", "d"}, dst.ids)
+}
+
+func TestCopy_RejectsMixedDimensions(t *testing.T) {
+	vectors := testVectors("a", "b")
+	vectors[1].Embedding = []float64{1}
+
+	_, err := Copy(context.Background(), &memorySource{vectors: vectors}, &memorySink{}, CopyOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dimensions")
+}
+
+func TestFileSinkAndSource_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vectors.jsonl")
+
+	sink, err := CreateFileSink(path, 0)
+	require.NoError(t, err)
+	_, err = Copy(context.Background(), &memorySource{vectors: testVectors("a", "b", "c")}, sink, CopyOptions{BatchSize: 2})
+	require.NoError(t, err)
+	require.NoError(t, sink.Close())
+
+	source, err := OpenFileSource(path)
+	require.NoError(t, err)
+	defer func() { _ = source.Close() }()
+
+	first, cursor, err := source.ExportVectors(context.Background(), "", 2)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	assert.NotEmpty(t, cursor)
+	assert.Equal(t, testVectors("a")[0], first[0])
+
+	rest, cursor, err := source.ExportVectors(context.Background(), cursor, 2)
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	assert.Equal(t, "c", rest[0].ID)
+	assert.Empty(t, cursor)
+}
+
+func TestFileSink_ResumeDropsUncheckpointedBatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vectors.jsonl")
+
+	sink, err := CreateFileSink(path, 0)
+	require.NoError(t, err)
+	require.NoError(t, sink.WriteVectors(context.Background(), testVectors("a")))
+	checkpoint := sink.Offset()
+	require.NoError(t, sink.WriteVectors(context.Background(), testVectors("partial")))
+	require.NoError(t, sink.Close())
+
+	sink, err = CreateFileSink(path, checkpoint)
+	require.NoError(t, err)
+	require.NoError(t, sink.WriteVectors(context.Background(), testVectors("b")))
+	require.NoError(t, sink.Close())
+
+	source, err := OpenFileSource(path)
+	require.NoError(t, err)
+	defer func() { _ = source.Close() }()
+	dst := &memorySink{}
+	_, err = Copy(context.Background(), source, dst, CopyOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, dst.ids)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2*checkpoint, info.Size())
+}
+
+func TestCompleteForIndexing(t *testing.T) {
+	vd := completeForIndexing(&pkgdomain.VectorData{ID: "doc-1", Embedding: []float64{1}})
+	assert.Equal(t, "doc-1", vd.Metadata.Title)
+	assert.Equal(t, "doc-1", vd.Content)
+	assert.False(t, vd.Metadata.CreatedAt.IsZero())
+	assert.Equal(t, vd.Metadata.CreatedAt, vd.Metadata.UpdatedAt)
+
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	vd = completeForIndexing(&pkgdomain.VectorData{
+		ID:        "doc-2",
+		Content:   "body",
+		CreatedAt: created,
+		Metadata:  pkgdomain.DocumentMetadata{FilePath: "docs/a.md"},
+	})
+	assert.Equal(t, "docs/a.md", vd.Metadata.Title)
+	assert.Equal(t, "body", vd.Content)
+	assert.Equal(t, created, vd.Metadata.CreatedAt)
+}
@@ -0,0 +1,129 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	"github.com/ca-srg/ragent/internal/ingestion/sqlitevec"
+	"github.com/ca-srg/ragent/internal/ingestion/transfer"
+	"github.com/ca-srg/ragent/internal/ingestion/vectorizer"
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+// failingSource returns a number of pages and then fails
+type failingSource struct {
+	vectorizer.VectorExporter
+	pages int
+}
+
+func (f *failingSource) ExportVectors(ctx context.Context, cursor string, limit int) ([]*pkgdomain.VectorData, string, error) {
+	if f.pages == 0 {
+		return nil, "", errors.New("connection reset")
+	}
+	f.pages--
+	return f.VectorExporter.ExportVectors(ctx, cursor, limit)
+}
+
+func newTransferTestStore(t *testing.T, name string, ids int) *sqlitevec.SqliteVecStore {
+	t.Helper()
+	store, err := sqlitevec.NewSqliteVecStore(filepath.Join(t.TempDir(), name))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	for i := 0; i < ids; i++ {
+		require.NoError(t, store.StoreVector(context.Background(), &pkgdomain.VectorData{
+			ID:        fmt.Sprintf("doc-%02d", i),
+			Embedding: []float64{float64(i), 1, 0, 0},
+			Content:   "content",
+			Metadata:  pkgdomain.DocumentMetadata{Title: fmt.Sprintf("Doc %d", i)},
+		}))
+	}
+	return store
+}
+
+func TestCopyWithCheckpoint_ResumesAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	checkpoints, err := hashstore.NewHashStoreWithPath(filepath.Join(t.TempDir(), "stats.db"))
+	require.NoError(t, err)
+	defer func() { _ = checkpoints.Close() }()
+
+	src := newTransferTestStore(t, "src.db", 5)
+	dst := newTransferTestStore(t, "dst.db", 0)
+	name := "sqlite:src->sqlite:dst"
+
+	copied, err := copyWithCheckpoint(ctx, checkpoints, name, &failingSource{VectorExporter: src, pages: 1}, transfer.NewStoreSink(dst), 2, nil)
+	require.Error(t, err)
+	assert.Equal(t, int64(2), copied)
+
+	checkpoint, err := checkpoints.GetTransferCheckpoint(ctx, name)
+	require.NoError(t, err)
+	require.NotNil(t, checkpoint)
+	assert.Equal(t, int64(2), checkpoint.Transferred)
+
+	copied, err = copyWithCheckpoint(ctx, checkpoints, name, src, transfer.NewStoreSink(dst), 2, checkpoint)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), copied)
+
+	keys, err := dst.ListVectors(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, keys, 5)
+
+	checkpoint, err = checkpoints.GetTransferCheckpoint(ctx, name)
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint, "the checkpoint is removed once the transfer completes")
+}
+
+func TestCopyWithCheckpoint_RecordsFileOffset(t *testing.T) {
+	ctx := context.Background()
+	checkpoints, err := hashstore.NewHashStoreWithPath(filepath.Join(t.TempDir(), "stats.db"))
+	require.NoError(t, err)
+	defer func() { _ = checkpoints.Close() }()
+
+	output := filepath.Join(t.TempDir(), "vectors.jsonl")
+	sink, err := transfer.CreateFileSink(output, 0)
+	require.NoError(t, err)
+	defer func() { _ = sink.Close() }()
+
+	_, err = copyWithCheckpoint(ctx, checkpoints, "export", &failingSource{VectorExporter: newTransferTestStore(t, "src.db", 3), pages: 1}, sink, 2, nil)
+	require.Error(t, err)
+
+	checkpoint, err := checkpoints.GetTransferCheckpoint(ctx, "export")
+	require.NoError(t, err)
+	require.NotNil(t, checkpoint)
+	assert.Equal(t, "doc-01", checkpoint.Cursor)
+	assert.Equal(t, sink.Offset(), checkpoint.Offset)
+	assert.Positive(t, checkpoint.Offset)
+}
+
+func TestTransferEndpointName(t *testing.T) {
+	cfg := &appconfig.Config{AWSS3VectorBucket: "vectors", AWSS3VectorIndex: "docs", SqliteVecDBPath: "/var/lib/ragent/vectors.db"}
+
+	name, err := transferEndpointName(cfg, TransferEndpointS3, TransferEndpointOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "s3:vectors/docs", name)
+
+	name, err = transferEndpointName(cfg, TransferEndpointSqlite, TransferEndpointOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "sqlite:/var/lib/ragent/vectors.db", name)
+
+	name, err = transferEndpointName(cfg, TransferEndpointSqlite, TransferEndpointOptions{SqlitePath: "/tmp/other.db"})
+	require.NoError(t, err)
+	assert.Equal(t, "sqlite:/tmp/other.db", name)
+
+	name, err = transferEndpointName(cfg, TransferEndpointOpenSearch, TransferEndpointOptions{Index: "docs-copy"})
+	require.NoError(t, err)
+	assert.Equal(t, "opensearch:docs-copy", name)
+
+	_, err = transferEndpointName(cfg, "parquet", TransferEndpointOptions{})
+	assert.Error(t, err)
+
+	assert.True(t, isExcerptEndpoint("s3:vectors/docs"))
+	assert.False(t, isExcerptEndpoint("opensearch:docs"))
+}
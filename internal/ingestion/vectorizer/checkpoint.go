@@ -0,0 +1,111 @@
+package vectorizer
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+// CompletionCallback is called when every backend write for a document succeeded
+type CompletionCallback func(document *pkgdomain.FileInfo)
+
+// checkpointTracker maps expanded documents (CSV rows, PDF pages) back to their source
+// files and reports completion to a Checkpointer as documents and sources finish
+type checkpointTracker struct {
+	checkpointer Checkpointer
+	sources      map[string]*pkgdomain.FileInfo
+	remaining    map[string]int
+	mu           sync.Mutex
+}
+
+// newCheckpointTracker filters out documents already completed by the run being resumed
+// and returns the tracker together with the documents that still need processing
+func newCheckpointTracker(
+	ctx context.Context,
+	checkpointer Checkpointer,
+	sources []*pkgdomain.FileInfo,
+	documents []*pkgdomain.FileInfo,
+) (*checkpointTracker, []*pkgdomain.FileInfo) {
+	tracker := &checkpointTracker{
+		checkpointer: checkpointer,
+		sources:      make(map[string]*pkgdomain.FileInfo, len(sources)),
+		remaining:    make(map[string]int),
+	}
+	for _, source := range sources {
+		tracker.sources[source.Path] = source
+	}
+
+	seen := make(map[string]bool)
+	pending := make([]*pkgdomain.FileInfo, 0, len(documents))
+	skipped := 0
+	for _, document := range documents {
+		sourcePath := documentSourcePath(document)
+		seen[sourcePath] = true
+		// A source edited since the interruption is processed again
+		if checkpointer.IsCompleted(document.Path, tracker.contentHash(document)) {
+			skipped++
+			continue
+		}
+		tracker.remaining[sourcePath]++
+		pending = append(pending, document)
+	}
+
+	if skipped > 0 {
+		log.Printf("Resume: skipping %d document(s) completed by the interrupted run", skipped)
+	}
+
+	// Sources whose documents were all completed before the interruption are done already
+	for sourcePath := range seen {
+		if tracker.remaining[sourcePath] > 0 {
+			continue
+		}
+		if source, ok := tracker.sources[sourcePath]; ok {
+			checkpointer.SourceCompleted(ctx, source)
+		}
+	}
+
+	return tracker, pending
+}
+
+// documentCompleted checkpoints a document and its source file once the source has no pending documents
+func (t *checkpointTracker) documentCompleted(ctx context.Context, document *pkgdomain.FileInfo) {
+	t.checkpointer.DocumentCompleted(ctx, document, t.contentHash(document))
+
+	sourcePath := documentSourcePath(document)
+	t.mu.Lock()
+	t.remaining[sourcePath]--
+	done := t.remaining[sourcePath] == 0
+	t.mu.Unlock()
+
+	if !done {
+		return
+	}
+	if source, ok := t.sources[sourcePath]; ok {
+		t.checkpointer.SourceCompleted(ctx, source)
+	}
+}
+
+// contentHash returns the current content hash of the source file of a document
+func (t *checkpointTracker) contentHash(document *pkgdomain.FileInfo) string {
+	if source, ok := t.sources[documentSourcePath(document)]; ok {
+		return source.ContentHash
+	}
+	return document.ContentHash
+}
+
+// callback returns a CompletionCallback bound to ctx
+func (t *checkpointTracker) callback(ctx context.Context) CompletionCallback {
+	return func(document *pkgdomain.FileInfo) {
+		t.documentCompleted(ctx, document)
+	}
+}
+
+// documentSourcePath returns the path of the file a document was read from
+func documentSourcePath(document *pkgdomain.FileInfo) string {
+	if document.SourcePath != "" {
+		return document.SourcePath
+	}
+	return document.Path
+}
@@ -0,0 +1,140 @@
+package vectorizer
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	pkgconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCheckpointer struct {
+	mu        sync.Mutex
+	completed map[string]string
+	documents []string
+	sources   []string
+}
+
+func newFakeCheckpointer(completed ...string) *fakeCheckpointer {
+	cp := &fakeCheckpointer{completed: make(map[string]string)}
+	for _, path := range completed {
+		cp.completed[path] = ""
+	}
+	return cp
+}
+
+func (c *fakeCheckpointer) IsCompleted(documentPath, contentHash string) bool {
+	completedHash, ok := c.completed[documentPath]
+	return ok && completedHash == contentHash
+}
+
+func (c *fakeCheckpointer) DocumentCompleted(ctx context.Context, document *pkgdomain.FileInfo, contentHash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.documents = append(c.documents, document.Path)
+}
+
+func (c *fakeCheckpointer) SourceCompleted(ctx context.Context, source *pkgdomain.FileInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sources = append(c.sources, source.Path)
+}
+
+func TestCheckpointTracker_GroupsDocumentsBySource(t *testing.T) {
+	ctx := context.Background()
+	pdfSource := &pkgdomain.FileInfo{Path: "docs/manual.pdf", IsPDF: true}
+	mdSource := &pkgdomain.FileInfo{Path: "docs/readme.md"}
+
+	page1 := &pkgdomain.FileInfo{Path: "pdf://docs/manual.pdf/page/1", SourcePath: "docs/manual.pdf"}
+	page2 := &pkgdomain.FileInfo{Path: "pdf://docs/manual.pdf/page/2", SourcePath: "docs/manual.pdf"}
+	page3 := &pkgdomain.FileInfo{Path: "pdf://docs/manual.pdf/page/3", SourcePath: "docs/manual.pdf"}
+
+	cp := newFakeCheckpointer(page1.Path)
+	tracker, pending := newCheckpointTracker(ctx, cp,
+		[]*pkgdomain.FileInfo{pdfSource, mdSource},
+		[]*pkgdomain.FileInfo{mdSource, page1, page2, page3})
+
+	assert.Equal(t, []*pkgdomain.FileInfo{mdSource, page2, page3}, pending)
+	assert.Empty(t, cp.sources)
+
+	tracker.documentCompleted(ctx, page2)
+	assert.Empty(t, cp.sources, "source must not complete while pages are pending")
+
+	tracker.documentCompleted(ctx, mdSource)
+	tracker.documentCompleted(ctx, page3)
+
+	assert.Equal(t, []string{page2.Path, mdSource.Path, page3.Path}, cp.documents)
+	assert.Equal(t, []string{mdSource.Path, pdfSource.Path}, cp.sources)
+}
+
+func TestCheckpointTracker_SourceAlreadyCompleted(t *testing.T) {
+	source := &pkgdomain.FileInfo{Path: "data/rows.csv", IsCSV: true}
+	row1 := &pkgdomain.FileInfo{Path: "csv://data/rows.csv/row1", SourcePath: "data/rows.csv"}
+	row2 := &pkgdomain.FileInfo{Path: "csv://data/rows.csv/row2", SourcePath: "data/rows.csv"}
+
+	cp := newFakeCheckpointer(row1.Path, row2.Path)
+	_, pending := newCheckpointTracker(context.Background(), cp,
+		[]*pkgdomain.FileInfo{source},
+		[]*pkgdomain.FileInfo{row1, row2})
+
+	assert.Empty(t, pending)
+	assert.Equal(t, []string{source.Path}, cp.sources)
+}
+
+func TestCheckpointTracker_SourceEditedSinceInterruption(t *testing.T) {
+	// Both pages were completed from the first version; the PDF was edited before the resume
+	source := &pkgdomain.FileInfo{Path: "docs/manual.pdf", IsPDF: true, ContentHash: "hash-v2"}
+	page1 := &pkgdomain.FileInfo{Path: "pdf://docs/manual.pdf/page/1", SourcePath: "docs/manual.pdf"}
+	page2 := &pkgdomain.FileInfo{Path: "pdf://docs/manual.pdf/page/2", SourcePath: "docs/manual.pdf"}
+	unchanged := &pkgdomain.FileInfo{Path: "docs/readme.md", ContentHash: "hash-readme"}
+
+	cp := newFakeCheckpointer()
+	cp.completed[page1.Path] = "hash-v1"
+	cp.completed[page2.Path] = "hash-v1"
+	cp.completed[unchanged.Path] = "hash-readme"
+
+	ctx := context.Background()
+	tracker, pending := newCheckpointTracker(ctx, cp,
+		[]*pkgdomain.FileInfo{source, unchanged},
+		[]*pkgdomain.FileInfo{page1, page2, unchanged})
+
+	assert.Equal(t, []*pkgdomain.FileInfo{page1, page2}, pending, "pages of the edited file are processed again")
+	assert.Equal(t, []string{unchanged.Path}, cp.sources, "the edited file must not be recorded as up to date before it is processed")
+
+	tracker.documentCompleted(ctx, page1)
+	tracker.documentCompleted(ctx, page2)
+	assert.Equal(t, []string{unchanged.Path, source.Path}, cp.sources)
+}
+
+func TestVectorizeFiles_Checkpointing(t *testing.T) {
+	service, err := NewVectorizerService(&ServiceConfig{
+		Config:            &pkgconfig.Config{Concurrency: 2},
+		EmbeddingClient:   NewMockEmbeddingClient(),
+		VectorStoreClient: NewMockVectorStore(),
+		MetadataExtractor: NewMockMetadataExtractor(),
+		FileScanner:       NewMockFileScanner(),
+	})
+	require.NoError(t, err)
+
+	cp := newFakeCheckpointer("docs/a.md")
+	service.SetCheckpointer(cp)
+
+	files := []*pkgdomain.FileInfo{
+		{Path: "docs/a.md", Name: "a.md", Content: "already done"},
+		{Path: "docs/b.md", Name: "b.md", Content: "pending"},
+		{Path: "docs/c.md", Name: "c.md", Content: "pending"},
+	}
+
+	result, err := service.VectorizeFiles(context.Background(), files, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.ProcessedFiles)
+
+	sort.Strings(cp.documents)
+	sort.Strings(cp.sources)
+	assert.Equal(t, []string{"docs/b.md", "docs/c.md"}, cp.documents)
+	assert.Equal(t, []string{"docs/a.md", "docs/b.md", "docs/c.md"}, cp.sources)
+}
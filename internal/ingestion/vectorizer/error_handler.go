@@ -156,6 +156,16 @@ func (eh *DualBackendErrorHandler) HandleError(ctx *BackendErrorContext) *ErrorH
 	return eh.createDecision(shouldRetry, retryDelay, decision, userMessage, technicalDetails, suggestions)
 }
 
+// ClassifyFailure returns the error type of a failure and whether retrying it may succeed.
+// fallback is used when the error cannot be classified from its message.
+func (eh *DualBackendErrorHandler) ClassifyFailure(err error, backendType BackendType, fallback pkgconfig.ErrorType) (pkgconfig.ErrorType, bool) {
+	errorType := eh.classifyError(err, backendType)
+	if errorType == pkgconfig.ErrorTypeUnknown {
+		errorType = fallback
+	}
+	return errorType, eh.isRetryableError(err, backendType, errorType)
+}
+
 // HandleDualBackendErrors handles errors from both S3 and OpenSearch operations
 func (eh *DualBackendErrorHandler) HandleDualBackendErrors(
 	s3Error error,
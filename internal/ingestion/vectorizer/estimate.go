@@ -0,0 +1,286 @@
+package vectorizer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ca-srg/ragent/internal/ingestion/csv"
+	"github.com/ca-srg/ragent/internal/ingestion/pdf"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPDFPageTokens is the assumed number of embedding tokens produced by one OCR'd PDF page
+const DefaultPDFPageTokens = 500
+
+// PriceTable holds the unit prices used to project the cost of a vectorize run
+type PriceTable struct {
+	// Currency is the label printed next to costs
+	Currency string `yaml:"currency" json:"currency"`
+	// Embedding maps an embedding model ID to its price per 1,000 input tokens
+	Embedding map[string]float64 `yaml:"embedding" json:"embedding"`
+	// OCR maps an OCR model ID to its price per PDF page
+	OCR map[string]float64 `yaml:"ocr" json:"ocr"`
+	// PDFPageTokens is the assumed number of embedding tokens per OCR'd page
+	PDFPageTokens int `yaml:"pdf_page_tokens" json:"pdf_page_tokens"`
+}
+
+// DefaultPriceTable returns list prices for the built-in embedding models.
+// OCR prices depend on the prompt and model and have to be configured explicitly.
+func DefaultPriceTable() *PriceTable {
+	return &PriceTable{
+		Currency: "USD",
+		Embedding: map[string]float64{
+			"amazon.titan-embed-text-v2:0": 0.00002,
+			"amazon.titan-embed-text-v1":   0.0001,
+			"gemini-embedding-001":         0.00015,
+		},
+		OCR:           map[string]float64{},
+		PDFPageTokens: DefaultPDFPageTokens,
+	}
+}
+
+// LoadPriceTable reads a YAML price table and merges it over the defaults
+func LoadPriceTable(path string) (*PriceTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price table: %w", err)
+	}
+
+	var loaded PriceTable
+	if err := yaml.Unmarshal(data, &loaded); err != nil {
+		return nil, fmt.Errorf("failed to parse price table YAML: %w", err)
+	}
+
+	table := DefaultPriceTable()
+	if loaded.Currency != "" {
+		table.Currency = loaded.Currency
+	}
+	for model, price := range loaded.Embedding {
+		if price < 0 {
+			return nil, fmt.Errorf("invalid price table: negative embedding price for %s", model)
+		}
+		table.Embedding[model] = price
+	}
+	for model, price := range loaded.OCR {
+		if price < 0 {
+			return nil, fmt.Errorf("invalid price table: negative OCR price for %s", model)
+		}
+		table.OCR[model] = price
+	}
+	if loaded.PDFPageTokens > 0 {
+		table.PDFPageTokens = loaded.PDFPageTokens
+	}
+	return table, nil
+}
+
+// EstimateTotals aggregates the estimated work and cost for a set of files
+type EstimateTotals struct {
+	Files           int     `json:"files"`
+	PDFFiles        int     `json:"pdf_files"`
+	Documents       int     `json:"documents"`
+	Chunks          int     `json:"chunks"`
+	EmbeddingTokens int     `json:"embedding_tokens"`
+	OCRPages        int     `json:"ocr_pages"`
+	EmbeddingCost   float64 `json:"embedding_cost"`
+	OCRCost         float64 `json:"ocr_cost"`
+	TotalCost       float64 `json:"total_cost"`
+}
+
+// FileEstimate is the estimate for a single source file
+type FileEstimate struct {
+	Path            string `json:"path"`
+	Cached          bool   `json:"cached"`
+	Documents       int    `json:"documents"`
+	Chunks          int    `json:"chunks"`
+	EmbeddingTokens int    `json:"embedding_tokens"`
+	OCRPages        int    `json:"ocr_pages,omitempty"`
+	Skipped         string `json:"skipped,omitempty"`
+}
+
+// CostEstimate is the projected token usage and cost of a vectorize run
+type CostEstimate struct {
+	Currency           string         `json:"currency"`
+	EmbeddingModel     string         `json:"embedding_model"`
+	EmbeddingPrice     *float64       `json:"embedding_price_per_1k_tokens"`
+	OCRModel           string         `json:"ocr_model,omitempty"`
+	OCRPrice           *float64       `json:"ocr_price_per_page,omitempty"`
+	PDFPageTokens      int            `json:"pdf_page_tokens"`
+	ToProcess          EstimateTotals `json:"to_process"`
+	Cached             EstimateTotals `json:"cached"`
+	Files              []FileEstimate `json:"files"`
+	MissingPrices      []string       `json:"missing_prices,omitempty"`
+	ChunkMaxTokens     int            `json:"chunk_max_tokens"`
+	ChunkOverlapTokens int            `json:"chunk_overlap_tokens"`
+}
+
+// EstimateCost projects chunk counts, embedding tokens, OCR pages and cost for files
+// that would be vectorized and, separately, for files skipped as unchanged by the hash store.
+// Nothing is sent to the embedding or OCR APIs.
+func (vs *VectorizerService) EstimateCost(files, cached []*pkgdomain.FileInfo, prices *PriceTable) *CostEstimate {
+	if prices == nil {
+		prices = DefaultPriceTable()
+	}
+	pageTokens := prices.PDFPageTokens
+	if pageTokens <= 0 {
+		pageTokens = DefaultPDFPageTokens
+	}
+
+	splitter := NewDocumentSplitter()
+	estimate := &CostEstimate{
+		Currency:           prices.Currency,
+		PDFPageTokens:      pageTokens,
+		Files:              make([]FileEstimate, 0, len(files)+len(cached)),
+		ChunkMaxTokens:     splitter.MaxTokens,
+		ChunkOverlapTokens: splitter.OverlapTokens,
+	}
+
+	if vs.embeddingClient != nil {
+		estimate.EmbeddingModel, _, _ = vs.embeddingClient.GetModelInfo()
+	}
+	if price, ok := prices.Embedding[estimate.EmbeddingModel]; ok {
+		estimate.EmbeddingPrice = &price
+	} else {
+		estimate.MissingPrices = append(estimate.MissingPrices, fmt.Sprintf("embedding:%s", estimate.EmbeddingModel))
+	}
+
+	if vs.pdfReader != nil && (containsPDF(files) || containsPDF(cached)) {
+		estimate.OCRModel = vs.pdfReader.Model()
+		if price, ok := prices.OCR[estimate.OCRModel]; ok {
+			estimate.OCRPrice = &price
+		} else {
+			estimate.MissingPrices = append(estimate.MissingPrices, fmt.Sprintf("ocr:%s", estimate.OCRModel))
+		}
+	}
+
+	for _, f := range files {
+		fe := vs.estimateFile(splitter, f, pageTokens)
+		estimate.Files = append(estimate.Files, fe)
+		estimate.addTo(&estimate.ToProcess, f, fe)
+	}
+	for _, f := range cached {
+		fe := vs.estimateFile(splitter, f, pageTokens)
+		fe.Cached = true
+		estimate.Files = append(estimate.Files, fe)
+		estimate.addTo(&estimate.Cached, f, fe)
+	}
+
+	return estimate
+}
+
+// containsPDF reports whether any of the files is a PDF
+func containsPDF(files []*pkgdomain.FileInfo) bool {
+	for _, f := range files {
+		if f.IsPDF {
+			return true
+		}
+	}
+	return false
+}
+
+// addTo accumulates a file estimate and its cost into totals
+func (e *CostEstimate) addTo(totals *EstimateTotals, f *pkgdomain.FileInfo, fe FileEstimate) {
+	totals.Files++
+	if f.IsPDF {
+		totals.PDFFiles++
+	}
+	totals.Documents += fe.Documents
+	totals.Chunks += fe.Chunks
+	totals.EmbeddingTokens += fe.EmbeddingTokens
+	totals.OCRPages += fe.OCRPages
+
+	if e.EmbeddingPrice != nil {
+		totals.EmbeddingCost = float64(totals.EmbeddingTokens) / 1000 * *e.EmbeddingPrice
+	}
+	if e.OCRPrice != nil {
+		totals.OCRCost = float64(totals.OCRPages) * *e.OCRPrice
+	}
+	totals.TotalCost = totals.EmbeddingCost + totals.OCRCost
+}
+
+// estimateFile estimates the documents, chunks and tokens a source file expands into
+func (vs *VectorizerService) estimateFile(splitter *DocumentSplitter, f *pkgdomain.FileInfo, pageTokens int) FileEstimate {
+	fe := FileEstimate{Path: f.Path}
+
+	switch {
+	case f.IsPDF:
+		if vs.pdfReader == nil {
+			fe.Skipped = "OCR_PROVIDER not set"
+			return fe
+		}
+		data := f.RawBytes
+		if data == nil {
+			var err error
+			if data, err = os.ReadFile(f.Path); err != nil {
+				fe.Skipped = fmt.Sprintf("failed to read PDF: %v", err)
+				return fe
+			}
+		}
+		pages := pdf.CountPages(data)
+		if pages == 0 {
+			fe.Skipped = "failed to count PDF pages"
+			return fe
+		}
+		// Each page becomes one document, chunked like any other text
+		fe.OCRPages = pages
+		fe.Documents = pages
+		perPage := (pageTokens + splitter.MaxTokens - 1) / splitter.MaxTokens
+		fe.Chunks = pages * perPage
+		fe.EmbeddingTokens = pages * pageTokens
+
+	case f.IsCSV:
+		var rows []*pkgdomain.FileInfo
+		var err error
+		if f.Content != "" {
+			rows, err = vs.csvReader.ReadContent(f.Content, f.Path)
+		} else {
+			rows, err = vs.csvReader.ReadFile(f.Path)
+		}
+		if err != nil {
+			if errors.Is(err, csv.ErrNoCSVConfig) {
+				fe.Skipped = "no matching csv-config pattern"
+			} else {
+				fe.Skipped = fmt.Sprintf("failed to read CSV: %v", err)
+			}
+			return fe
+		}
+		fe.Documents = len(rows)
+		for _, row := range rows {
+			chunks, tokens := estimateChunks(splitter, row.Content)
+			fe.Chunks += chunks
+			fe.EmbeddingTokens += tokens
+		}
+
+	default:
+		content := f.Content
+		if content == "" && vs.fileScanner != nil {
+			var err error
+			if content, err = vs.fileScanner.ReadFileContent(f.Path); err != nil {
+				fe.Skipped = fmt.Sprintf("failed to read file: %v", err)
+				return fe
+			}
+		}
+		fe.Documents = 1
+		fe.Chunks, fe.EmbeddingTokens = estimateChunks(splitter, content)
+	}
+
+	return fe
+}
+
+// estimateChunks returns the number of chunks text splits into and the tokens they embed,
+// counting overlapping text once per chunk it appears in
+func estimateChunks(splitter *DocumentSplitter, text string) (int, int) {
+	if !splitter.ShouldSplit(text) {
+		return 1, splitter.EstimateTokenCount(text)
+	}
+	chunks, err := splitter.SplitDocument(text, "")
+	if err != nil || len(chunks) == 0 {
+		return 1, splitter.EstimateTokenCount(text)
+	}
+	tokens := 0
+	for _, chunk := range chunks {
+		tokens += splitter.EstimateTokenCount(chunk.Content)
+	}
+	return len(chunks), tokens
+}
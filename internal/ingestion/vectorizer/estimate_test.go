@@ -0,0 +1,167 @@
+package vectorizer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ca-srg/ragent/internal/ingestion/pdf"
+	pkgconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+// buildTestPDF returns a minimal valid PDF with the given number of blank pages
+func buildTestPDF(pages int) []byte {
+	var objects []string
+	kids := make([]string, pages)
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", i+3)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pages))
+	for range pages {
+		objects = append(objects, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func newEstimateTestService(t *testing.T, withOCR bool) *VectorizerService {
+	t.Helper()
+	cfg := &ServiceConfig{
+		Config:            &pkgconfig.Config{Concurrency: 2},
+		EmbeddingClient:   NewMockEmbeddingClient(),
+		VectorStoreClient: NewMockVectorStore(),
+		MetadataExtractor: NewMockMetadataExtractor(),
+		FileScanner:       NewMockFileScanner(),
+	}
+	if withOCR {
+		cfg.PDFReader = pdf.NewReader(failingOCRClient{}, pdf.PDFReaderConfig{
+			Provider:    "bedrock",
+			Model:       "test-ocr",
+			Timeout:     time.Second,
+			Concurrency: 1,
+		})
+	}
+	service, err := NewVectorizerService(cfg)
+	require.NoError(t, err)
+	return service
+}
+
+func TestEstimateCost_SplitsTotalsAndCachedFiles(t *testing.T) {
+	service := newEstimateTestService(t, true)
+	prices := &PriceTable{
+		Currency:      "USD",
+		Embedding:     map[string]float64{"mock-model": 0.1},
+		OCR:           map[string]float64{"test-ocr": 0.01},
+		PDFPageTokens: 100,
+	}
+
+	long := strings.Repeat("あ", 10000) // 7000 tokens, split into two chunks
+	files := []*pkgdomain.FileInfo{
+		{Path: "docs/short.md", Content: strings.Repeat("a", 1000)},
+		{Path: "docs/long.md", Content: long},
+		{Path: "docs/manual.pdf", IsPDF: true, RawBytes: buildTestPDF(3)},
+	}
+	cached := []*pkgdomain.FileInfo{
+		{Path: "docs/cached.md", Content: strings.Repeat("a", 2000)},
+	}
+
+	estimate := service.EstimateCost(files, cached, prices)
+
+	assert.Equal(t, "mock-model", estimate.EmbeddingModel)
+	assert.Equal(t, "test-ocr", estimate.OCRModel)
+	assert.Empty(t, estimate.MissingPrices)
+
+	total := estimate.ToProcess
+	assert.Equal(t, 3, total.Files)
+	assert.Equal(t, 1, total.PDFFiles)
+	assert.Equal(t, 1+1+3, total.Documents)
+	assert.Equal(t, 1+2+3, total.Chunks)
+	assert.Equal(t, 3, total.OCRPages)
+	assert.Greater(t, total.EmbeddingTokens, 700+7000+300, "overlap is embedded once per chunk")
+	assert.InDelta(t, float64(total.EmbeddingTokens)/1000*0.1, total.EmbeddingCost, 1e-9)
+	assert.InDelta(t, 0.03, total.OCRCost, 1e-9)
+	assert.InDelta(t, total.EmbeddingCost+total.OCRCost, total.TotalCost, 1e-9)
+
+	assert.Equal(t, 1, estimate.Cached.Files)
+	assert.Equal(t, 1400, estimate.Cached.EmbeddingTokens)
+	require.Len(t, estimate.Files, 4)
+	assert.True(t, estimate.Files[3].Cached)
+}
+
+func TestEstimateCost_FlagsMissingPricesAndUnreadableFiles(t *testing.T) {
+	service := newEstimateTestService(t, false)
+
+	files := []*pkgdomain.FileInfo{
+		{Path: "docs/manual.pdf", IsPDF: true, RawBytes: buildTestPDF(2)},
+		{Path: "docs/a.md", Content: "hello"},
+	}
+	estimate := service.EstimateCost(files, nil, DefaultPriceTable())
+
+	assert.Equal(t, []string{"embedding:mock-model"}, estimate.MissingPrices)
+	assert.Zero(t, estimate.ToProcess.EmbeddingCost)
+	assert.Zero(t, estimate.ToProcess.OCRPages)
+	assert.Equal(t, "OCR_PROVIDER not set", estimate.Files[0].Skipped)
+	assert.Equal(t, 1, estimate.ToProcess.Documents)
+}
+
+func TestVectorizeFiles_DryRunDoesNotOCR(t *testing.T) {
+	service := newEstimateTestService(t, true)
+
+	files := []*pkgdomain.FileInfo{
+		{Path: "docs/manual.pdf", Name: "manual.pdf", IsPDF: true, RawBytes: buildTestPDF(1)},
+		{Path: "docs/readme.md", Name: "readme.md", Content: "hello"},
+	}
+
+	// failingOCRClient would report an OCR error if the dry run expanded the PDF
+	result, err := service.VectorizeFiles(t.Context(), files, true)
+	require.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, 1, result.ProcessedFiles)
+}
+
+func TestLoadPriceTable_MergesOverDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+currency: JPY
+embedding:
+  amazon.titan-embed-text-v2:0: 0.003
+ocr:
+  anthropic.claude-sonnet: 1.5
+pdf_page_tokens: 800
+`), 0644))
+
+	table, err := LoadPriceTable(path)
+	require.NoError(t, err)
+	assert.Equal(t, "JPY", table.Currency)
+	assert.Equal(t, 0.003, table.Embedding["amazon.titan-embed-text-v2:0"])
+	assert.Equal(t, 0.0001, table.Embedding["amazon.titan-embed-text-v1"], "unlisted models keep their defaults")
+	assert.Equal(t, 1.5, table.OCR["anthropic.claude-sonnet"])
+	assert.Equal(t, 800, table.PDFPageTokens)
+
+	require.NoError(t, os.WriteFile(path, []byte("embedding:\n  bad: -1\n"), 0644))
+	_, err = LoadPriceTable(path)
+	assert.Error(t, err)
+}
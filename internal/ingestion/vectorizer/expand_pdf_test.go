@@ -0,0 +1,71 @@
+package vectorizer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ca-srg/ragent/internal/ingestion/pdf"
+	pkgconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+type failingOCRClient struct{}
+
+func (failingOCRClient) ExtractPages(ctx context.Context, pdfData []byte, filename string) ([]*pdf.PageResult, error) {
+	return nil, errors.New("ocr service unavailable")
+}
+
+func (failingOCRClient) ValidateConnection(ctx context.Context) error {
+	return nil
+}
+
+func TestVectorizeFiles_ReportsPDFExpansionFailures(t *testing.T) {
+	service, err := NewVectorizerService(&ServiceConfig{
+		Config:            &pkgconfig.Config{Concurrency: 2},
+		EmbeddingClient:   NewMockEmbeddingClient(),
+		VectorStoreClient: NewMockVectorStore(),
+		MetadataExtractor: NewMockMetadataExtractor(),
+		FileScanner:       NewMockFileScanner(),
+		PDFReader: pdf.NewReader(failingOCRClient{}, pdf.PDFReaderConfig{
+			Provider:    "bedrock",
+			Model:       "test-model",
+			Timeout:     time.Second,
+			Concurrency: 1,
+		}),
+	})
+	require.NoError(t, err)
+
+	files := []*pkgdomain.FileInfo{
+		{Path: "docs/manual.pdf", Name: "manual.pdf", IsPDF: true, RawBytes: []byte("%PDF-1.4")},
+		{Path: "docs/readme.md", Name: "readme.md", Content: "hello"},
+	}
+
+	result, err := service.VectorizeFiles(context.Background(), files, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.SuccessCount)
+	assert.Equal(t, 1, result.FailureCount)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, pkgconfig.ErrorTypeOCR, result.Errors[0].Type)
+	assert.Equal(t, "docs/manual.pdf", result.Errors[0].FilePath)
+}
+
+func TestSingleBackendError_ClassifiesFailures(t *testing.T) {
+	page := &pkgdomain.FileInfo{Path: "pdf://docs/manual.pdf/page/2", SourcePath: "docs/manual.pdf"}
+
+	throttled := singleBackendError(
+		WrapError(errors.New("ThrottlingException: Too many requests"), pkgconfig.ErrorTypeEmbedding, page.Path), page)
+	assert.Equal(t, pkgconfig.ErrorTypeRateLimit, throttled.Type)
+	assert.True(t, throttled.Retryable)
+	assert.Equal(t, "docs/manual.pdf", throttled.SourcePath)
+	assert.Empty(t, throttled.Backend)
+
+	upload := singleBackendError(
+		WrapError(errors.New("invalid vector"), pkgconfig.ErrorTypeS3Upload, page.Path), page)
+	assert.Equal(t, pkgconfig.ErrorTypeS3Upload, upload.Type)
+	assert.Equal(t, "S3 Vector", upload.Backend)
+}
@@ -15,6 +15,8 @@ import (
 // Compile-time interface satisfaction checks moved here to avoid import cycles.
 var _ VectorStore = (*s3vector.S3VectorService)(nil)
 var _ VectorStore = (*sqlitevec.SqliteVecStore)(nil)
+var _ VectorExporter = (*s3vector.S3VectorService)(nil)
+var _ VectorExporter = (*sqlitevec.SqliteVecStore)(nil)
 
 // IndexerFactory creates OpenSearch indexers based on configuration
 type IndexerFactory struct {
@@ -54,6 +56,7 @@ func (f *IndexerFactory) CreateOpenSearchIndexer(indexName string, dimension int
 		MaxConnections:    f.config.OpenSearchMaxConnections,
 		MaxIdleConns:      f.config.OpenSearchMaxIdleConns,
 		IdleConnTimeout:   f.config.OpenSearchIdleConnTimeout,
+		KNNEngine:         f.config.OpenSearchKNNEngine,
 	}
 
 	log.Printf("Creating OpenSearch client with endpoint: %s", openSearchConfig.Endpoint)
@@ -71,6 +74,7 @@ func (f *IndexerFactory) CreateOpenSearchIndexer(indexName string, dimension int
 
 	// Create OpenSearch indexer
 	indexer := NewOpenSearchIndexer(osClient, indexName, dimension)
+	indexer.SetIndexProfile(opensearch.NewKNNIndexProfileFromConfig(f.config))
 
 	log.Printf("Successfully created OpenSearch indexer for index '%s' with dimension %d",
 		indexName, dimension)
@@ -165,6 +169,7 @@ func (f *IndexerFactory) GetOpenSearchConfiguration() *opensearch.Config {
 		MaxConnections:    f.config.OpenSearchMaxConnections,
 		MaxIdleConns:      f.config.OpenSearchMaxIdleConns,
 		IdleConnTimeout:   f.config.OpenSearchIdleConnTimeout,
+		KNNEngine:         f.config.OpenSearchKNNEngine,
 	}
 }
 
@@ -307,7 +312,13 @@ func (sf *ServiceFactory) CreateVectorStore() (VectorStore, error) {
 		return svc, nil
 
 	case "sqlite":
-		store, err := sqlitevec.NewSqliteVecStore(cfg.SqliteVecDBPath)
+		store, err := sqlitevec.NewSqliteVecStoreWithOptions(cfg.SqliteVecDBPath, sqlitevec.Options{
+			Quantization:  cfg.SqliteVecQuantization,
+			Storage:       cfg.SqliteVecStorage,
+			RescoreFactor: cfg.SqliteVecRescoreFactor,
+			ANNLists:      cfg.SqliteVecANNLists,
+			ANNProbes:     cfg.SqliteVecANNProbes,
+		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to create SQLite vector store: %w", err)
 		}
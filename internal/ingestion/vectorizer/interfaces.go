@@ -43,6 +43,20 @@ type VectorStore interface {
 	DeleteAllVectors(ctx context.Context) (int, error)
 }
 
+// VectorExporter is implemented by vector stores that can read back their stored vectors
+type VectorExporter interface {
+	// ExportVectors returns up to limit vectors starting at cursor ("" for the first page)
+	// and the cursor of the next page, which is "" once every vector has been returned
+	ExportVectors(ctx context.Context, cursor string, limit int) ([]*pkgdomain.VectorData, string, error)
+}
+
+// IndexMaintainer is implemented by vector stores that keep a search index beside the
+// vectors, such as the sqlite-vec ANN index, and bring it up to date after each run
+type IndexMaintainer interface {
+	// MaintainIndex rebuilds the search index when it is missing or outdated
+	MaintainIndex(ctx context.Context) error
+}
+
 // MetadataExtractor defines the interface for extracting metadata from files
 type MetadataExtractor interface {
 	// ExtractMetadata extracts metadata from a file's content and path
@@ -135,3 +149,18 @@ type OpenSearchIndexer interface {
 	// ProcessJapaneseText processes text using Japanese analyzer for better indexing
 	ProcessJapaneseText(text string) (string, error)
 }
+
+// Checkpointer records documents whose writes succeeded on every backend so that an
+// interrupted run can be resumed without redoing completed work
+type Checkpointer interface {
+	// IsCompleted reports whether the document was already completed by the run being
+	// resumed while its source file had the given content hash
+	IsCompleted(documentPath, contentHash string) bool
+
+	// DocumentCompleted is called once every backend write for a document succeeded.
+	// contentHash is the hash of the source file the document was read from.
+	DocumentCompleted(ctx context.Context, document *pkgdomain.FileInfo, contentHash string)
+
+	// SourceCompleted is called once every document expanded from a source file succeeded
+	SourceCompleted(ctx context.Context, source *pkgdomain.FileInfo)
+}
@@ -13,3 +13,12 @@ func applyUploadSecretMetadata(metadata *pkgdomain.DocumentMetadata, secret bool
 	}
 	metadata.CustomFields["secret"] = secret
 }
+
+// applyTenantMetadata assigns the tenant of the vectorize run to a document
+func applyTenantMetadata(metadata *pkgdomain.DocumentMetadata, tenant string) {
+	if metadata == nil || tenant == "" {
+		return
+	}
+
+	metadata.Tenant = tenant
+}
@@ -32,6 +32,10 @@ type OpenSearchDocument struct {
 	TotalChunks  *int                   `json:"total_chunks,omitempty"` // Total number of chunks
 	CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
 	Secret       bool                   `json:"secret"`
+	Tenant       string                 `json:"tenant,omitempty"`
+
+	// Groups allowed to read the document; empty means unrestricted
+	AllowedGroups []string `json:"allowed_groups,omitempty"`
 }
 
 // NewOpenSearchDocument creates a new OpenSearchDocument from VectorData
@@ -68,6 +72,9 @@ func NewOpenSearchDocument(vectorData *pkgdomain.VectorData, contentJa string) *
 		Embedding:    embeddingCopy,
 		CustomFields: vectorData.Metadata.CustomFields,
 		Secret:       vectorData.Metadata.Secret,
+		Tenant:       vectorData.Metadata.Tenant,
+
+		AllowedGroups: vectorData.Metadata.AllowedGroups,
 	}
 }
 
@@ -247,6 +254,12 @@ func (doc *OpenSearchDocument) ToMap() map[string]interface{} {
 		"indexed_at": doc.IndexedAt.Format(time.RFC3339),
 		"embedding":  embedding,
 	}
+	if doc.Tenant != "" {
+		result["tenant"] = doc.Tenant
+	}
+	if len(doc.AllowedGroups) > 0 {
+		result["allowed_groups"] = doc.AllowedGroups
+	}
 
 	// Add Japanese processed content if present
 	if doc.ContentJa != "" {
@@ -286,6 +299,7 @@ func (doc *OpenSearchDocument) MarshalJSON() ([]byte, error) {
 		FilePath     string                 `json:"file_path"`
 		WordCount    int                    `json:"word_count"`
 		Secret       bool                   `json:"secret"`
+		Tenant       string                 `json:"tenant,omitempty"`
 		CreatedAt    string                 `json:"created_at"`
 		UpdatedAt    string                 `json:"updated_at"`
 		IndexedAt    string                 `json:"indexed_at"`
@@ -293,6 +307,8 @@ func (doc *OpenSearchDocument) MarshalJSON() ([]byte, error) {
 		ChunkIndex   *int                   `json:"chunk_index,omitempty"`
 		TotalChunks  *int                   `json:"total_chunks,omitempty"`
 		CustomFields map[string]interface{} `json:"custom_fields,omitempty"`
+
+		AllowedGroups []string `json:"allowed_groups,omitempty"`
 	}
 
 	jsonDoc := &JSONDoc{
@@ -309,6 +325,7 @@ func (doc *OpenSearchDocument) MarshalJSON() ([]byte, error) {
 		FilePath:     doc.FilePath,
 		WordCount:    doc.WordCount,
 		Secret:       doc.Secret,
+		Tenant:       doc.Tenant,
 		CreatedAt:    doc.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:    doc.UpdatedAt.Format(time.RFC3339),
 		IndexedAt:    doc.IndexedAt.Format(time.RFC3339),
@@ -316,6 +333,8 @@ func (doc *OpenSearchDocument) MarshalJSON() ([]byte, error) {
 		ChunkIndex:   doc.ChunkIndex,
 		TotalChunks:  doc.TotalChunks,
 		CustomFields: doc.CustomFields,
+
+		AllowedGroups: doc.AllowedGroups,
 	}
 
 	return json.Marshal(jsonDoc)
@@ -377,6 +396,7 @@ func (doc *OpenSearchDocument) Clone() *OpenSearchDocument {
 		IndexedAt: doc.IndexedAt,
 	}
 	clone.Secret = doc.Secret
+	clone.Tenant = doc.Tenant
 
 	// Deep copy chunk information
 	if doc.ChunkIndex != nil {
@@ -394,6 +414,12 @@ func (doc *OpenSearchDocument) Clone() *OpenSearchDocument {
 		copy(clone.Tags, doc.Tags)
 	}
 
+	// Deep copy allowed groups
+	if len(doc.AllowedGroups) > 0 {
+		clone.AllowedGroups = make([]string, len(doc.AllowedGroups))
+		copy(clone.AllowedGroups, doc.AllowedGroups)
+	}
+
 	// Deep copy embedding
 	if len(doc.Embedding) > 0 {
 		clone.Embedding = make([]float64, len(doc.Embedding))
@@ -21,6 +21,7 @@ type OpenSearchClient interface {
 	RecordRequest(time.Duration, bool)
 	HealthCheck(context.Context) error
 	CreateVectorIndex(context.Context, string, int, string, string) error
+	EnsureAccessFieldMappings(context.Context, string) error
 }
 
 // OpenSearchIndexerImpl implements the OpenSearchIndexer interface
@@ -29,6 +30,7 @@ type OpenSearchIndexerImpl struct {
 	textProcessor    *opensearch.JapaneseTextProcessor
 	defaultIndex     string
 	defaultDimension int
+	profile          opensearch.KNNIndexProfile
 }
 
 // NewOpenSearchIndexer creates a new OpenSearchIndexer implementation
@@ -38,9 +40,20 @@ func NewOpenSearchIndexer(client OpenSearchClient, defaultIndex string, defaultD
 		textProcessor:    opensearch.NewJapaneseTextProcessor(),
 		defaultIndex:     defaultIndex,
 		defaultDimension: defaultDimension,
+		profile:          opensearch.DefaultKNNIndexProfile(),
 	}
 }
 
+// SetIndexProfile sets the k-NN mapping profile used when creating indexes
+func (osi *OpenSearchIndexerImpl) SetIndexProfile(profile opensearch.KNNIndexProfile) {
+	osi.profile = profile
+}
+
+// IndexProfile returns the k-NN mapping profile used when creating indexes
+func (osi *OpenSearchIndexerImpl) IndexProfile() opensearch.KNNIndexProfile {
+	return osi.profile
+}
+
 // IndexDocument indexes a single document in OpenSearch
 func (osi *OpenSearchIndexerImpl) IndexDocument(ctx context.Context, indexName string, document *OpenSearchDocument) error {
 	if document == nil {
@@ -260,7 +273,7 @@ func (osi *OpenSearchIndexerImpl) CreateIndex(ctx context.Context, indexName str
 			return WrapError(err, pkgconfig.ErrorTypeRateLimit, indexName)
 		}
 
-		return osi.client.CreateVectorIndex(ctx, indexName, dimension, "lucene", "cosinesimil")
+		return osi.client.CreateVectorIndex(ctx, indexName, dimension, osi.profile.Engine, osi.profile.SpaceType)
 	}
 
 	err := osi.client.ExecuteWithRetry(ctx, operation, fmt.Sprintf("CreateIndex[%s]", indexName))
@@ -544,21 +557,23 @@ func (osi *OpenSearchIndexerImpl) CreateVectorIndexWithJapanese(ctx context.Cont
 		dimension = osi.defaultDimension
 	}
 
+	if err := osi.profile.Validate(); err != nil {
+		return WrapError(err, pkgconfig.ErrorTypeValidation, indexName)
+	}
+
 	operation := func() error {
 		if err := osi.client.WaitForRateLimit(ctx); err != nil {
 			return WrapError(err, pkgconfig.ErrorTypeRateLimit, indexName)
 		}
 
+		indexSettings := osi.profile.IndexSettings()
+		indexSettings["max_result_window"] = 10000
+		indexSettings["max_rescore_window"] = 10000
+
 		// Create Japanese-optimized index settings and mappings
 		settings := map[string]interface{}{
 			"settings": map[string]interface{}{
-				"index": map[string]interface{}{
-					"knn":                true,
-					"number_of_shards":   1,
-					"number_of_replicas": 0,
-					"max_result_window":  10000,
-					"max_rescore_window": 10000,
-				},
+				"index": indexSettings,
 				"analysis": map[string]interface{}{
 					"analyzer": map[string]interface{}{
 						"kuromoji": map[string]interface{}{
@@ -663,6 +678,12 @@ func (osi *OpenSearchIndexerImpl) CreateVectorIndexWithJapanese(ctx context.Cont
 					"secret": map[string]interface{}{
 						"type": "boolean",
 					},
+					"tenant": map[string]interface{}{
+						"type": "keyword",
+					},
+					"allowed_groups": map[string]interface{}{
+						"type": "keyword",
+					},
 					"created_at": map[string]interface{}{
 						"type": "date",
 					},
@@ -672,19 +693,7 @@ func (osi *OpenSearchIndexerImpl) CreateVectorIndexWithJapanese(ctx context.Cont
 					"indexed_at": map[string]interface{}{
 						"type": "date",
 					},
-					"embedding": map[string]interface{}{
-						"type":      "knn_vector",
-						"dimension": dimension,
-						"method": map[string]interface{}{
-							"engine":     "lucene",
-							"space_type": "cosinesimil",
-							"name":       "hnsw",
-							"parameters": map[string]interface{}{
-								"ef_construction": 256,
-								"m":               16,
-							},
-						},
-					},
+					"embedding": osi.profile.EmbeddingMapping(dimension),
 					"custom_fields": map[string]interface{}{
 						"type":    "object",
 						"enabled": true,
@@ -720,7 +729,8 @@ func (osi *OpenSearchIndexerImpl) CreateVectorIndexWithJapanese(ctx context.Cont
 		return WrapError(err, pkgconfig.ErrorTypeOpenSearchIndex, indexName)
 	}
 
-	log.Printf("Successfully created Japanese-optimized index %s with dimension %d in %v", indexName, dimension, duration)
+	log.Printf("Successfully created Japanese-optimized index %s with dimension %d (engine %s, %d shards, %d replicas) in %v",
+		indexName, dimension, osi.profile.Engine, osi.profile.Shards, osi.profile.Replicas, duration)
 	return nil
 }
 
@@ -865,13 +875,15 @@ func (osi *OpenSearchIndexerImpl) SafeDeleteIndex(ctx context.Context, indexName
 	return stats, nil
 }
 
-// CompareIndexSettings compares current index settings with expected configuration
+// CompareIndexSettings compares the live settings and mappings of an index with the
+// k-NN mapping profile and the expected dimension, and reports every drifted value
 func (osi *OpenSearchIndexerImpl) CompareIndexSettings(ctx context.Context, indexName string, expectedDimension int) (map[string]interface{}, error) {
 	startTime := time.Now()
 
 	comparison := make(map[string]interface{})
 	comparison["index_name"] = indexName
 	comparison["expected_dimension"] = expectedDimension
+	comparison["profile"] = osi.profile
 	comparison["timestamp"] = time.Now().Unix()
 
 	// Check if index exists
@@ -889,7 +901,10 @@ func (osi *OpenSearchIndexerImpl) CompareIndexSettings(ctx context.Context, inde
 
 	comparison["exists"] = true
 
-	var issues []string
+	var (
+		issues []string
+		drift  []string
+	)
 	operation := func() error {
 		if err := osi.client.WaitForRateLimit(ctx); err != nil {
 			return WrapError(err, pkgconfig.ErrorTypeRateLimit, indexName)
@@ -900,28 +915,20 @@ func (osi *OpenSearchIndexerImpl) CompareIndexSettings(ctx context.Context, inde
 			Indices: []string{indexName},
 		}
 
-		_, err := osi.client.GetClient().Indices.Get(ctx, req)
+		resp, err := osi.client.GetClient().Indices.Get(ctx, req)
 		if err != nil {
 			return osi.classifyOpenSearchError(err, indexName)
 		}
 
-		// TODO: Parse response and perform detailed comparison when client API is clarified
-		// For now, perform basic checks
-
-		// Check basic accessibility
-		info, err := osi.GetIndexInfo(ctx, indexName)
-		if err != nil {
-			issues = append(issues, fmt.Sprintf("cannot retrieve index info: %v", err))
-		} else {
-			comparison["current_info"] = info
+		// An alias resolves to its backing index, which is the only entry of the response
+		for liveName, index := range resp.Indices {
+			comparison["resolved_index"] = liveName
+			drift, err = opensearch.KNNProfileDrift(osi.profile, "embedding", expectedDimension, index.Settings, index.Mappings)
+			if err != nil {
+				issues = append(issues, err.Error())
+			}
+			break
 		}
-
-		// Future enhancements would include:
-		// - Compare vector dimensions
-		// - Check analyzer configuration
-		// - Verify field mappings
-		// - Check index settings (shards, replicas, etc.)
-
 		return nil
 	}
 
@@ -934,12 +941,13 @@ func (osi *OpenSearchIndexerImpl) CompareIndexSettings(ctx context.Context, inde
 		issues = append(issues, fmt.Sprintf("failed to retrieve settings: %v", err))
 	}
 
-	comparison["issues"] = issues
-	comparison["compatible"] = len(issues) == 0
+	comparison["drift"] = drift
+	comparison["issues"] = append(issues, drift...)
+	comparison["compatible"] = len(issues)+len(drift) == 0
 	comparison["check_duration"] = duration.String()
 
-	log.Printf("Index %s settings comparison completed in %v (%d issues found)",
-		indexName, duration, len(issues))
+	log.Printf("Index %s settings comparison completed in %v (%d drifted values, %d issues found)",
+		indexName, duration, len(drift), len(issues))
 
 	return comparison, nil
 }
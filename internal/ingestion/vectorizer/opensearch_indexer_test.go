@@ -64,6 +64,14 @@ func (m *MockOpenSearchClient) CreateVectorIndex(ctx context.Context, indexName
 	return nil
 }
 
+func (m *MockOpenSearchClient) EnsureAccessFieldMappings(ctx context.Context, indexName string) error {
+	m.callCounts["EnsureAccessFieldMappings"]++
+	if m.shouldReturnError {
+		return m.errorToReturn
+	}
+	return nil
+}
+
 func (m *MockOpenSearchClient) WaitForRateLimit(ctx context.Context) error {
 	m.callCounts["WaitForRateLimit"]++
 	if m.shouldReturnError {
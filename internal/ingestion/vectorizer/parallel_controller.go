@@ -19,6 +19,7 @@ type ParallelController struct {
 	vectorStore       VectorStore
 	opensearchIndexer OpenSearchIndexer
 	concurrencyLimit  int
+	errorHandler      *DualBackendErrorHandler
 
 	// Statistics tracking with atomic operations
 	stats *ParallelProcessingStats
@@ -26,6 +27,9 @@ type ParallelController struct {
 	// Progress callback
 	progressCallback ProgressCallback
 	progressMu       sync.RWMutex
+
+	// Completion callback for checkpointing
+	completionCallback CompletionCallback
 }
 
 // ParallelProcessingStats tracks statistics for dual backend processing
@@ -85,6 +89,7 @@ type FileProcessingResult struct {
 	OSError        error
 	OSDuration     time.Duration
 	ProcessingTime time.Duration
+	Error          *pkgdomain.ProcessingError // Failure before any backend write (read, metadata, split)
 }
 
 // NewParallelController creates a new parallel controller
@@ -97,6 +102,7 @@ func NewParallelController(vectorStore VectorStore, opensearchIndexer OpenSearch
 		vectorStore:       vectorStore,
 		opensearchIndexer: opensearchIndexer,
 		concurrencyLimit:  concurrencyLimit,
+		errorHandler:      NewDualBackendErrorHandler(0, 0),
 		stats: &ParallelProcessingStats{
 			StartTime: time.Now(),
 			Errors:    make([]pkgdomain.ProcessingError, 0),
@@ -142,6 +148,9 @@ func (pc *ParallelController) ProcessFiles(
 			defer func() { <-semaphore }()
 
 			result := pc.processFile(ctx, f, indexName, embeddingClient, metadataExtractor, dryRun)
+			if result.Decision == ProcessingSuccess {
+				pc.notifyCompletion(f)
+			}
 			resultChan <- result
 
 			// Update progress
@@ -200,6 +209,7 @@ func (pc *ParallelController) processFile(
 		content, err := os.ReadFile(fileInfo.Path)
 		if err != nil {
 			log.Printf("Failed to read file %s: %v", fileInfo.Name, err)
+			result.Error = WrapError(err, pkgconfig.ErrorTypeFileRead, fileInfo.Path)
 			result.Decision = ProcessingCompleteFailure
 			result.ProcessingTime = time.Since(startTime)
 			return result
@@ -215,6 +225,7 @@ func (pc *ParallelController) processFile(
 		metadata, err := metadataExtractor.ExtractMetadata(fileInfo.Path, fileInfo.Content)
 		if err != nil {
 			log.Printf("Failed to extract metadata for %s: %v", fileInfo.Name, err)
+			result.Error = WrapError(err, pkgconfig.ErrorTypeMetadata, fileInfo.Path)
 			result.Decision = ProcessingCompleteFailure
 			result.ProcessingTime = time.Since(startTime)
 			return result
@@ -222,6 +233,7 @@ func (pc *ParallelController) processFile(
 		if fileInfo.SourceType == "upload" {
 			applyUploadSecretMetadata(metadata, secret)
 		}
+		applyTenantMetadata(metadata, fileInfo.Metadata.Tenant)
 		fileInfo.Metadata = *metadata
 	}
 
@@ -232,6 +244,7 @@ func (pc *ParallelController) processFile(
 	chunks, err := splitter.SplitDocument(fileInfo.Content, documentID)
 	if err != nil {
 		log.Printf("Failed to split document %s: %v", fileInfo.Name, err)
+		result.Error = WrapError(err, pkgconfig.ErrorTypeValidation, fileInfo.Path)
 		result.Decision = ProcessingCompleteFailure
 		result.ProcessingTime = time.Since(startTime)
 		return result
@@ -443,6 +456,12 @@ func (pc *ParallelController) updateStatisticsFromResult(result *FileProcessingR
 		// Don't count as success or failure
 	}
 
+	if result.Error != nil {
+		procErr := *result.Error
+		procErr.SourcePath = result.FileInfo.SourcePath
+		pc.addError(procErr)
+	}
+
 	// Update S3 statistics
 	atomic.AddInt64(&pc.stats.S3ProcessedCount, 1)
 	if result.S3Success {
@@ -450,7 +469,7 @@ func (pc *ParallelController) updateStatisticsFromResult(result *FileProcessingR
 	} else {
 		atomic.AddInt64(&pc.stats.S3FailureCount, 1)
 		if result.S3Error != nil {
-			pc.addError(result.S3Error, result.FileInfo.Path, pkgconfig.ErrorTypeS3Upload)
+			pc.addError(pc.backendError(result.S3Error, result.FileInfo, BackendS3Vector, pkgconfig.ErrorTypeS3Upload))
 		}
 	}
 
@@ -462,7 +481,7 @@ func (pc *ParallelController) updateStatisticsFromResult(result *FileProcessingR
 	} else {
 		atomic.AddInt64(&pc.stats.OSFailureCount, 1)
 		if result.OSError != nil {
-			pc.addError(result.OSError, result.FileInfo.Path, pkgconfig.ErrorTypeOpenSearchIndexing)
+			pc.addError(pc.backendError(result.OSError, result.FileInfo, BackendOpenSearch, pkgconfig.ErrorTypeOpenSearchIndexing))
 		}
 	}
 
@@ -471,19 +490,25 @@ func (pc *ParallelController) updateStatisticsFromResult(result *FileProcessingR
 	pc.addDurationToStats(&pc.stats.OSProcessingTime, result.OSDuration)
 }
 
+// backendError builds a ProcessingError for a failed backend write, classified by the error handler
+func (pc *ParallelController) backendError(err error, fileInfo *pkgdomain.FileInfo, backendType BackendType, fallback pkgconfig.ErrorType) pkgdomain.ProcessingError {
+	errorType, retryable := pc.errorHandler.ClassifyFailure(err, backendType, fallback)
+	return pkgdomain.ProcessingError{
+		Type:       errorType,
+		Message:    err.Error(),
+		FilePath:   fileInfo.Path,
+		Timestamp:  time.Now(),
+		Retryable:  retryable,
+		SourcePath: fileInfo.SourcePath,
+		Backend:    pc.errorHandler.getBackendName(backendType),
+	}
+}
+
 // addError safely adds an error to the statistics
-func (pc *ParallelController) addError(err error, filePath string, errorType pkgconfig.ErrorType) {
+func (pc *ParallelController) addError(procErr pkgdomain.ProcessingError) {
 	pc.stats.errorsMu.Lock()
 	defer pc.stats.errorsMu.Unlock()
 
-	procErr := pkgdomain.ProcessingError{
-		Type:      errorType,
-		Message:   err.Error(),
-		FilePath:  filePath,
-		Timestamp: time.Now(),
-		Retryable: false, // Determine this based on error type
-	}
-
 	pc.stats.Errors = append(pc.stats.Errors, procErr)
 }
 
@@ -678,3 +703,22 @@ func (pc *ParallelController) notifyProgress(processed, total int) {
 		callback(processed, total)
 	}
 }
+
+// SetCompletionCallback sets a callback function to be called when a file was written to both backends.
+// Pass nil to remove the callback.
+func (pc *ParallelController) SetCompletionCallback(callback CompletionCallback) {
+	pc.progressMu.Lock()
+	defer pc.progressMu.Unlock()
+	pc.completionCallback = callback
+}
+
+// notifyCompletion calls the completion callback if set
+func (pc *ParallelController) notifyCompletion(fileInfo *pkgdomain.FileInfo) {
+	pc.progressMu.RLock()
+	callback := pc.completionCallback
+	pc.progressMu.RUnlock()
+
+	if callback != nil {
+		callback(fileInfo)
+	}
+}
@@ -36,6 +36,7 @@ type VectorizerService struct {
 	pdfReader           *pdf.Reader
 	progressCallback    ProgressCallback
 	progressMu          sync.RWMutex
+	checkpointer        Checkpointer
 }
 
 // ProcessingStats tracks processing statistics
@@ -226,8 +227,9 @@ func (vs *VectorizerService) expandCSVFiles(files []*pkgdomain.FileInfo) ([]*pkg
 	return result, nil
 }
 
-// expandPDFFiles expands PDF files into individual FileInfo entries (one per page)
-func (vs *VectorizerService) expandPDFFiles(files []*pkgdomain.FileInfo) ([]*pkgdomain.FileInfo, error) {
+// expandPDFFiles expands PDF files into individual FileInfo entries (one per page).
+// PDF files that fail OCR are skipped and returned as processing errors.
+func (vs *VectorizerService) expandPDFFiles(files []*pkgdomain.FileInfo) ([]*pkgdomain.FileInfo, []pkgdomain.ProcessingError, error) {
 	if vs.pdfReader == nil {
 		// OCR_PROVIDER not configured - skip PDF files with warning
 		hasPDF := false
@@ -247,7 +249,7 @@ func (vs *VectorizerService) expandPDFFiles(files []*pkgdomain.FileInfo) ([]*pkg
 				result = append(result, f)
 			}
 		}
-		return result, nil
+		return result, nil, nil
 	}
 
 	// Separate PDF and non-PDF files.
@@ -262,7 +264,7 @@ func (vs *VectorizerService) expandPDFFiles(files []*pkgdomain.FileInfo) ([]*pkg
 	}
 
 	if len(pdfFiles) == 0 {
-		return nonPDFFiles, nil
+		return nonPDFFiles, nil, nil
 	}
 
 	// Process PDF files concurrently.
@@ -276,6 +278,7 @@ func (vs *VectorizerService) expandPDFFiles(files []*pkgdomain.FileInfo) ([]*pkg
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	var expandedPDFs []*pkgdomain.FileInfo
+	var failures []pkgdomain.ProcessingError
 
 	for _, file := range pdfFiles {
 		wg.Add(1)
@@ -294,6 +297,9 @@ func (vs *VectorizerService) expandPDFFiles(files []*pkgdomain.FileInfo) ([]*pkg
 			}
 			if err != nil {
 				log.Printf("Warning: failed to expand PDF file %s: %v, skipping", f.Path, err)
+				mu.Lock()
+				failures = append(failures, *WrapError(err, pkgconfig.ErrorTypeOCR, f.Path))
+				mu.Unlock()
 				return
 			}
 			log.Printf("  Expanded to %d pages", len(pages))
@@ -318,7 +324,24 @@ func (vs *VectorizerService) expandPDFFiles(files []*pkgdomain.FileInfo) ([]*pkg
 	result := make([]*pkgdomain.FileInfo, 0, len(nonPDFFiles)+len(expandedPDFs))
 	result = append(result, nonPDFFiles...)
 	result = append(result, expandedPDFs...)
-	return result, nil
+	return result, failures, nil
+}
+
+// dryRunPDFFiles removes PDF files from a dry run without calling OCR
+func (vs *VectorizerService) dryRunPDFFiles(files []*pkgdomain.FileInfo) []*pkgdomain.FileInfo {
+	result := make([]*pkgdomain.FileInfo, 0, len(files))
+	for _, f := range files {
+		if !f.IsPDF {
+			result = append(result, f)
+			continue
+		}
+		if vs.pdfReader == nil {
+			log.Printf("DRY RUN: [PDF SKIPPED - OCR_PROVIDER not set] %s", f.Path)
+		} else {
+			log.Printf("DRY RUN: Would OCR PDF file %s", f.Path)
+		}
+	}
+	return result
 }
 
 // VectorizeFiles processes a slice of FileInfo objects
@@ -332,6 +355,7 @@ func (vs *VectorizerService) VectorizeFiles(ctx context.Context, files []*pkgdom
 	}
 
 	log.Printf("Processing %d files", len(files))
+	sources := files
 
 	// Expand CSV files into individual rows (each row becomes a separate document)
 	expandedFiles, err := vs.expandCSVFiles(files)
@@ -343,21 +367,60 @@ func (vs *VectorizerService) VectorizeFiles(ctx context.Context, files []*pkgdom
 	}
 	files = expandedFiles
 
-	// Expand PDF files into individual pages (for S3/GitHub sources with RawBytes, and local files)
-	expandedFiles, err = vs.expandPDFFiles(files)
-	if err != nil {
-		return nil, fmt.Errorf("failed to expand PDF files: %w", err)
+	// Expand PDF files into individual pages (for S3/GitHub sources with RawBytes, and local files).
+	// Dry runs must not send PDFs to OCR, so their pages are only counted by EstimateCost.
+	var expansionErrors []pkgdomain.ProcessingError
+	if dryRun {
+		expandedFiles = vs.dryRunPDFFiles(files)
+	} else {
+		expandedFiles, expansionErrors, err = vs.expandPDFFiles(files)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand PDF files: %w", err)
+		}
 	}
 	if len(expandedFiles) != len(files) {
 		log.Printf("After PDF expansion: %d total documents to process", len(expandedFiles))
 	}
 	files = expandedFiles
 
+	// Skip documents completed by an interrupted run and checkpoint the rest as they finish
+	var onComplete CompletionCallback
+	if vs.checkpointer != nil && !dryRun {
+		var tracker *checkpointTracker
+		tracker, files = newCheckpointTracker(ctx, vs.checkpointer, sources, files)
+		onComplete = tracker.callback(ctx)
+		if len(files) == 0 {
+			log.Println("All documents were completed by the interrupted run")
+			return addExpansionErrors(vs.createEmptyResult(), expansionErrors), nil
+		}
+	}
+
+	result, err := vs.processDocuments(ctx, files, dryRun, onComplete)
+	if err != nil {
+		return nil, err
+	}
+	if maintainer, ok := vs.vectorStore.(IndexMaintainer); ok && !dryRun {
+		if err := maintainer.MaintainIndex(ctx); err != nil {
+			log.Printf("Warning: failed to update the vector search index: %v", err)
+		}
+	}
+	return addExpansionErrors(result, expansionErrors), nil
+}
+
+// processDocuments vectorizes expanded documents with the configured backends
+func (vs *VectorizerService) processDocuments(ctx context.Context, files []*pkgdomain.FileInfo, dryRun bool, onComplete CompletionCallback) (*pkgdomain.ProcessingResult, error) {
+	if vs.config != nil && vs.config.TenantID != "" {
+		log.Printf("Assigning documents to tenant: %s", vs.config.TenantID)
+		for _, file := range files {
+			applyTenantMetadata(&file.Metadata, vs.config.TenantID)
+		}
+	}
+
 	// Determine processing mode
 	if vs.enableOpenSearch && vs.parallelController != nil {
 		log.Printf("Using dual backend processing (S3 Vector + OpenSearch) with index: %s",
 			vs.opensearchIndexName)
-		return vs.processDualBackend(ctx, files, dryRun)
+		return vs.processDualBackend(ctx, files, dryRun, onComplete)
 	} else {
 		log.Println("Using single backend processing (S3 Vector only)")
 		if dryRun {
@@ -365,8 +428,18 @@ func (vs *VectorizerService) VectorizeFiles(ctx context.Context, files []*pkgdom
 			return vs.dryRunProcessing(files)
 		}
 		// Fallback to original single backend processing
-		return vs.processFilesConcurrently(ctx, files)
+		return vs.processFilesConcurrently(ctx, files, onComplete)
+	}
+}
+
+// addExpansionErrors reports source files that could not be expanded into documents as failures
+func addExpansionErrors(result *pkgdomain.ProcessingResult, expansionErrors []pkgdomain.ProcessingError) *pkgdomain.ProcessingResult {
+	if len(expansionErrors) == 0 {
+		return result
 	}
+	result.Errors = append(result.Errors, expansionErrors...)
+	result.FailureCount += len(expansionErrors)
+	return result
 }
 
 // ProcessSingleFile processes a single markdown file
@@ -392,6 +465,7 @@ func (vs *VectorizerService) ProcessSingleFile(ctx context.Context, fileInfo *pk
 		if fileInfo.SourceType == "upload" {
 			applyUploadSecretMetadata(metadata, secret)
 		}
+		applyTenantMetadata(metadata, fileInfo.Metadata.Tenant)
 		fileInfo.Metadata = *metadata
 	}
 
@@ -434,7 +508,7 @@ func (vs *VectorizerService) ProcessSingleFile(ctx context.Context, fileInfo *pk
 }
 
 // processFilesConcurrently processes files with controlled concurrency
-func (vs *VectorizerService) processFilesConcurrently(ctx context.Context, files []*pkgdomain.FileInfo) (*pkgdomain.ProcessingResult, error) {
+func (vs *VectorizerService) processFilesConcurrently(ctx context.Context, files []*pkgdomain.FileInfo, onComplete CompletionCallback) (*pkgdomain.ProcessingResult, error) {
 	// Create semaphore for concurrency control
 	semaphore := make(chan struct{}, vs.config.Concurrency)
 	var wg sync.WaitGroup
@@ -458,14 +532,13 @@ func (vs *VectorizerService) processFilesConcurrently(ctx context.Context, files
 			defer func() { <-semaphore }()
 
 			if err := vs.ProcessSingleFile(ctx, f, false); err != nil {
-				if procErr, ok := err.(*pkgdomain.ProcessingError); ok {
-					errorChan <- *procErr
-				} else {
-					errorChan <- *WrapError(err, pkgconfig.ErrorTypeUnknown, f.Path)
-				}
+				errorChan <- singleBackendError(err, f)
 				vs.updateStats(false)
 			} else {
 				vs.updateStats(true)
+				if onComplete != nil {
+					onComplete(f)
+				}
 			}
 
 			// Update progress and report every 10%
@@ -513,6 +586,28 @@ func (vs *VectorizerService) processFilesConcurrently(ctx context.Context, files
 	return result, nil
 }
 
+// singleBackendError converts a ProcessSingleFile error into a ProcessingError for the failure ledger.
+// Rate limit and timeout errors are classified from the message since they can occur in any step.
+func singleBackendError(err error, f *pkgdomain.FileInfo) pkgdomain.ProcessingError {
+	procErr, ok := err.(*pkgdomain.ProcessingError)
+	if !ok {
+		procErr = WrapError(err, pkgconfig.ErrorTypeUnknown, f.Path)
+	}
+
+	handler := NewDualBackendErrorHandler(0, 0)
+	errorType, retryable := handler.ClassifyFailure(err, BackendS3Vector, procErr.Type)
+	switch errorType {
+	case pkgconfig.ErrorTypeRateLimit, pkgconfig.ErrorTypeTimeout, pkgconfig.ErrorTypeNetworkTimeout:
+		procErr.Type = errorType
+		procErr.Retryable = retryable
+	}
+	if procErr.Type == pkgconfig.ErrorTypeS3Upload {
+		procErr.Backend = handler.getBackendName(BackendS3Vector)
+	}
+	procErr.SourcePath = f.SourcePath
+	return *procErr
+}
+
 // dryRunProcessing simulates processing without making actual API calls
 func (vs *VectorizerService) dryRunProcessing(files []*pkgdomain.FileInfo) (*pkgdomain.ProcessingResult, error) {
 	log.Println("Starting dry run processing...")
@@ -555,6 +650,7 @@ func (vs *VectorizerService) dryRunProcessing(files []*pkgdomain.FileInfo) (*pkg
 		if file.SourceType == "upload" {
 			applyUploadSecretMetadata(metadata, file.Metadata.Secret)
 		}
+		applyTenantMetadata(metadata, file.Metadata.Tenant)
 
 		log.Printf("  Title: %s", metadata.Title)
 		log.Printf("  Category: %s", metadata.Category)
@@ -654,6 +750,18 @@ func (vs *VectorizerService) SetProgressCallback(callback ProgressCallback) {
 	vs.progressCallback = callback
 }
 
+// SetCheckpointer enables per-document checkpointing. Pass nil to disable it.
+func (vs *VectorizerService) SetCheckpointer(checkpointer Checkpointer) {
+	vs.checkpointer = checkpointer
+}
+
+// SetOCRCache enables caching of PDF OCR results. It has no effect when OCR is not configured.
+func (vs *VectorizerService) SetOCRCache(cache pdf.ResultCache) {
+	if vs.pdfReader != nil {
+		vs.pdfReader.SetCache(cache)
+	}
+}
+
 // notifyProgress calls the progress callback if set
 func (vs *VectorizerService) notifyProgress(processed, total int) {
 	vs.progressMu.RLock()
@@ -666,7 +774,7 @@ func (vs *VectorizerService) notifyProgress(processed, total int) {
 }
 
 // processDualBackend processes files using both S3 Vector and OpenSearch backends
-func (vs *VectorizerService) processDualBackend(ctx context.Context, files []*pkgdomain.FileInfo, dryRun bool) (*pkgdomain.ProcessingResult, error) {
+func (vs *VectorizerService) processDualBackend(ctx context.Context, files []*pkgdomain.FileInfo, dryRun bool, onComplete CompletionCallback) (*pkgdomain.ProcessingResult, error) {
 	log.Printf("Starting dual backend processing for %d files", len(files))
 
 	// Validate OpenSearch index exists or create it if needed
@@ -684,6 +792,7 @@ func (vs *VectorizerService) processDualBackend(ctx context.Context, files []*pk
 	if callback != nil {
 		vs.parallelController.SetProgressCallback(callback)
 	}
+	vs.parallelController.SetCompletionCallback(onComplete)
 
 	// Use parallel controller for dual backend processing
 	result, err := vs.parallelController.ProcessFiles(
@@ -740,6 +849,11 @@ func (vs *VectorizerService) ensureOpenSearchIndex(ctx context.Context) error {
 		log.Printf("Successfully created OpenSearch index: %s", indexName)
 	} else {
 		log.Printf("OpenSearch index already exists: %s", indexName)
+		if osIndexer, ok := vs.opensearchIndexer.(*OpenSearchIndexerImpl); ok {
+			if err := osIndexer.client.EnsureAccessFieldMappings(ctx, indexName); err != nil {
+				log.Printf("Warning: tenant and group filters may not match documents in %s: %v", indexName, err)
+			}
+		}
 	}
 
 	return nil
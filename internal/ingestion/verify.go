@@ -0,0 +1,533 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	"github.com/ca-srg/ragent/internal/ingestion/vectorizer"
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+	"github.com/ca-srg/ragent/internal/pkg/opensearch"
+)
+
+// Repair modes of `verify --repair`
+const (
+	// RepairReindex drops the hash records of affected source files so the next vectorize run re-processes them
+	RepairReindex = "reindex"
+	// RepairDelete deletes vectors and documents that exist in only one backend and stale hash records
+	RepairDelete = "delete"
+)
+
+// DefaultVerifyLimit is the number of issues printed by verify
+const DefaultVerifyLimit = 50
+
+// Backends named in consistency issues
+const (
+	backendVectorStore = "vector_store"
+	backendOpenSearch  = "opensearch"
+	backendHashStore   = "hashstore"
+)
+
+// verifyPageSize is the number of vectors or documents read per request
+const verifyPageSize = 500
+
+// hashSourceTypes are the source types recorded in the hash store
+var hashSourceTypes = []string{"local", "s3", "github"}
+
+// chunkIDPattern matches chunk IDs generated by DocumentSplitter.GenerateChunkID
+var chunkIDPattern = regexp.MustCompile(`^(.+)_chunk_(\d+)$`)
+
+// csvRowPattern matches the file path of a CSV row document
+var csvRowPattern = regexp.MustCompile(`^csv://(.+)/row/\d+$`)
+
+// VerifyOptions holds the flags of the verify command
+type VerifyOptions struct {
+	Index  string
+	Repair string
+	Limit  int // Maximum number of issues printed; 0 prints all
+}
+
+// storedEntry is a vector or document seen by the consistency check
+type storedEntry struct {
+	ID          string
+	FilePath    string
+	Dimension   int
+	TotalChunks int // 0 when the document is not chunked or the backend does not record it
+}
+
+// consistencyTargets are the stores compared by the consistency check
+type consistencyTargets struct {
+	vectors   vectorizer.VectorStore
+	documents *opensearch.Client
+	index     string
+	hashes    *hashstore.HashStore
+}
+
+// RunVerify compares the vector store, the OpenSearch index and the hash store, prints the
+// inconsistencies and optionally repairs them
+func RunVerify(cmd *cobra.Command, opts VerifyOptions) error {
+	if opts.Repair != "" && opts.Repair != RepairReindex && opts.Repair != RepairDelete {
+		return fmt.Errorf("invalid --repair %q (must be %q or %q)", opts.Repair, RepairReindex, RepairDelete)
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cfg, err := appconfig.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := validateOpenSearchFlags(); err != nil {
+		return fmt.Errorf("flag validation failed: %w", err)
+	}
+	index := opts.Index
+	if index == "" {
+		index = openSearchIndexName
+	}
+
+	targets, closeTargets, err := openConsistencyTargets(cfg, index)
+	if err != nil {
+		return err
+	}
+	defer closeTargets()
+
+	report, records, err := targets.check(ctx)
+	if err != nil {
+		return err
+	}
+	printConsistencyReport(report, opts.Limit)
+
+	if len(report.Issues) == 0 {
+		return nil
+	}
+	if opts.Repair == "" {
+		fmt.Println("\nRepair them with: ragent verify --repair reindex|delete")
+		return nil
+	}
+
+	repaired, skipped := repairConsistency(ctx, opts.Repair, report.Issues, records, targets.vectors, targets.documents, index, targets.hashes)
+	fmt.Printf("\nRepaired %d issue(s) with --repair %s", repaired, opts.Repair)
+	if skipped > 0 {
+		fmt.Printf(", %d issue(s) cannot be repaired this way", skipped)
+	}
+	fmt.Println()
+	if opts.Repair == RepairReindex && repaired > 0 {
+		fmt.Println("Run `ragent vectorize` to re-process the affected source files")
+	}
+	return nil
+}
+
+// runFollowVerify runs a consistency check between follow mode cycles and stores the report
+// for the dashboard. Issues are only reported, never repaired.
+func runFollowVerify(ctx context.Context, cfg *appconfig.Config) {
+	targets, closeTargets, err := openConsistencyTargets(cfg, openSearchIndexName)
+	if err != nil {
+		log.Printf("[Follow Mode] Consistency check skipped: %v", err)
+		return
+	}
+	defer closeTargets()
+
+	report, _, err := targets.check(ctx)
+	if err != nil {
+		log.Printf("[Follow Mode] Consistency check failed: %v", err)
+		return
+	}
+	if len(report.Issues) == 0 {
+		log.Printf("[Follow Mode] Consistency check passed (%d vectors, %d documents)", report.VectorCount, report.DocumentCount)
+		return
+	}
+	log.Printf("[Follow Mode] Consistency check found %d issue(s) %v; run `ragent verify` for details", len(report.Issues), report.CountByKind())
+}
+
+// openConsistencyTargets opens the configured vector store, OpenSearch and the hash store
+func openConsistencyTargets(cfg *appconfig.Config, index string) (*consistencyTargets, func(), error) {
+	cfg.S3VectorRegion = resolveS3VectorRegion(cfg)
+	vectors, err := vectorizer.NewServiceFactory(cfg).CreateVectorStore()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create vector store client: %w", err)
+	}
+	closeVectors := func() {}
+	if closer, ok := vectors.(io.Closer); ok {
+		closeVectors = func() { _ = closer.Close() }
+	}
+
+	documents, err := opensearch.NewClient(vectorizer.NewIndexerFactory(cfg).GetOpenSearchConfiguration())
+	if err != nil {
+		closeVectors()
+		return nil, nil, fmt.Errorf("failed to create OpenSearch client: %w", err)
+	}
+
+	hashes, err := hashstore.NewHashStore()
+	if err != nil {
+		closeVectors()
+		return nil, nil, fmt.Errorf("failed to open hash store: %w", err)
+	}
+
+	targets := &consistencyTargets{vectors: vectors, documents: documents, index: index, hashes: hashes}
+	return targets, func() {
+		_ = hashes.Close()
+		closeVectors()
+	}, nil
+}
+
+// check reads every vector, document and hash record, saves the report to the hash store
+// and returns it together with the hash records
+func (t *consistencyTargets) check(ctx context.Context) (*pkgdomain.ConsistencyReport, map[string]*hashstore.FileHashRecord, error) {
+	exporter, ok := t.vectors.(vectorizer.VectorExporter)
+	if !ok {
+		return nil, nil, fmt.Errorf("the vector store does not support listing vectors with their embeddings")
+	}
+
+	log.Println("Reading vectors from the vector store...")
+	vectors, err := collectVectorEntries(ctx, exporter)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Printf("Reading documents from OpenSearch index %s...", t.index)
+	documents, err := collectDocumentEntries(ctx, t.documents, t.index)
+	if err != nil {
+		return nil, nil, err
+	}
+	records, err := t.hashes.GetAllFileHashesForSourceTypes(ctx, hashSourceTypes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report := &pkgdomain.ConsistencyReport{
+		CheckedAt:       time.Now(),
+		Index:           t.index,
+		VectorCount:     len(vectors),
+		DocumentCount:   len(documents),
+		HashRecordCount: len(records),
+		Issues:          checkConsistency(vectors, documents, records),
+	}
+	if err := t.hashes.SaveConsistencyReport(ctx, report); err != nil {
+		log.Printf("Warning: failed to save consistency report: %v", err)
+	}
+	return report, records, nil
+}
+
+// collectVectorEntries pages through every vector of the vector store
+func collectVectorEntries(ctx context.Context, exporter vectorizer.VectorExporter) ([]storedEntry, error) {
+	var entries []storedEntry
+	cursor := ""
+	for {
+		vectors, next, err := exporter.ExportVectors(ctx, cursor, verifyPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list vectors: %w", err)
+		}
+		for _, v := range vectors {
+			entries = append(entries, storedEntry{ID: v.ID, FilePath: v.Metadata.FilePath, Dimension: len(v.Embedding)})
+		}
+		if next == "" {
+			return entries, nil
+		}
+		cursor = next
+	}
+}
+
+// indexedDocument holds the fields of an OpenSearch document read by the consistency check
+type indexedDocument struct {
+	FilePath    string    `json:"file_path"`
+	Embedding   []float64 `json:"embedding"`
+	TotalChunks *int      `json:"total_chunks"`
+}
+
+// collectDocumentEntries pages through every document of the OpenSearch index
+func collectDocumentEntries(ctx context.Context, client *opensearch.Client, index string) ([]storedEntry, error) {
+	var entries []storedEntry
+	after := ""
+	for {
+		docs, err := client.ScanDocuments(ctx, index, after, verifyPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read documents of %s: %w", index, err)
+		}
+		for _, doc := range docs {
+			var source indexedDocument
+			if err := json.Unmarshal(doc.Source, &source); err != nil {
+				return nil, fmt.Errorf("failed to decode document %s: %w", doc.ID, err)
+			}
+			entry := storedEntry{ID: doc.ID, FilePath: source.FilePath, Dimension: len(source.Embedding)}
+			if source.TotalChunks != nil {
+				entry.TotalChunks = *source.TotalChunks
+			}
+			entries = append(entries, entry)
+		}
+		if len(docs) < verifyPageSize {
+			return entries, nil
+		}
+		after = docs[len(docs)-1].ID
+	}
+}
+
+// checkConsistency compares the vectors, the OpenSearch documents and the hash records.
+// It reports IDs present in only one backend, chunks missing from both, embeddings whose
+// dimension differs from the majority and hash records of files without indexed documents.
+func checkConsistency(vectors, documents []storedEntry, records map[string]*hashstore.FileHashRecord) []pkgdomain.ConsistencyIssue {
+	var issues []pkgdomain.ConsistencyIssue
+
+	vectorIDs := make(map[string]storedEntry, len(vectors))
+	for _, v := range vectors {
+		vectorIDs[v.ID] = v
+	}
+	documentIDs := make(map[string]storedEntry, len(documents))
+	for _, d := range documents {
+		documentIDs[d.ID] = d
+	}
+
+	for _, v := range vectors {
+		if _, ok := documentIDs[v.ID]; !ok {
+			issues = append(issues, pkgdomain.ConsistencyIssue{
+				Kind: pkgdomain.IssueMissingInOpenSearch, Backend: backendVectorStore, ID: v.ID, FilePath: v.FilePath,
+				Detail: "vector has no OpenSearch document",
+			})
+		}
+	}
+	for _, d := range documents {
+		if _, ok := vectorIDs[d.ID]; !ok {
+			issues = append(issues, pkgdomain.ConsistencyIssue{
+				Kind: pkgdomain.IssueMissingInVectorStore, Backend: backendOpenSearch, ID: d.ID, FilePath: d.FilePath,
+				Detail: "document has no vector in the vector store",
+			})
+		}
+	}
+
+	issues = append(issues, missingChunks(documents, documentIDs, vectorIDs)...)
+
+	dimension := majorityDimension(vectors, documents)
+	for _, side := range []struct {
+		backend string
+		entries []storedEntry
+	}{{backendVectorStore, vectors}, {backendOpenSearch, documents}} {
+		for _, e := range side.entries {
+			if e.Dimension != dimension {
+				issues = append(issues, pkgdomain.ConsistencyIssue{
+					Kind: pkgdomain.IssueDimensionMismatch, Backend: side.backend, ID: e.ID, FilePath: e.FilePath,
+					Detail: fmt.Sprintf("embedding has %d dimensions, expected %d", e.Dimension, dimension),
+				})
+			}
+		}
+	}
+
+	indexedSources := make(map[string]bool)
+	for _, entries := range [][]storedEntry{vectors, documents} {
+		for _, e := range entries {
+			indexedSources[sourcePathOf(e.FilePath)] = true
+		}
+	}
+	for path, record := range records {
+		if !indexedSources[path] {
+			issues = append(issues, pkgdomain.ConsistencyIssue{
+				Kind: pkgdomain.IssueStaleHashRecord, Backend: backendHashStore, FilePath: path,
+				Detail: fmt.Sprintf("%s file recorded as vectorized at %s has no vectors or documents", record.SourceType, record.VectorizedAt.Format(time.DateTime)),
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Kind != issues[j].Kind {
+			return issues[i].Kind < issues[j].Kind
+		}
+		if issues[i].ID != issues[j].ID {
+			return issues[i].ID < issues[j].ID
+		}
+		return issues[i].FilePath < issues[j].FilePath
+	})
+	return issues
+}
+
+// missingChunks reports chunks of split documents that neither backend has.
+// A chunk present in only one backend is already reported as missing from the other.
+func missingChunks(documents []storedEntry, documentIDs, vectorIDs map[string]storedEntry) []pkgdomain.ConsistencyIssue {
+	type chunkedDocument struct {
+		filePath string
+		total    int
+	}
+	chunked := make(map[string]*chunkedDocument)
+	for _, d := range documents {
+		if d.TotalChunks <= 1 {
+			continue
+		}
+		match := chunkIDPattern.FindStringSubmatch(d.ID)
+		if match == nil {
+			continue
+		}
+		doc := chunked[match[1]]
+		if doc == nil {
+			doc = &chunkedDocument{filePath: d.FilePath}
+			chunked[match[1]] = doc
+		}
+		doc.total = max(doc.total, d.TotalChunks)
+	}
+
+	var issues []pkgdomain.ConsistencyIssue
+	for key, doc := range chunked {
+		for i := 0; i < doc.total; i++ {
+			id := key + "_chunk_" + strconv.Itoa(i)
+			_, inDocuments := documentIDs[id]
+			_, inVectors := vectorIDs[id]
+			if !inDocuments && !inVectors {
+				issues = append(issues, pkgdomain.ConsistencyIssue{
+					Kind: pkgdomain.IssueMissingChunk, ID: id, FilePath: doc.filePath,
+					Detail: fmt.Sprintf("chunk %d/%d is missing from both backends", i+1, doc.total),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// majorityDimension returns the most common embedding dimension, preferring the larger one on ties
+func majorityDimension(entries ...[]storedEntry) int {
+	counts := make(map[int]int)
+	for _, list := range entries {
+		for _, e := range list {
+			counts[e.Dimension]++
+		}
+	}
+	dimension, best := 0, 0
+	for d, n := range counts {
+		if n > best || (n == best && d > dimension) {
+			dimension, best = d, n
+		}
+	}
+	return dimension
+}
+
+// sourcePathOf returns the path of the source file a document was read from, which is the
+// path recorded in the hash store. CSV rows are stored as csv://<path>/row/<n>.
+func sourcePathOf(filePath string) string {
+	if match := csvRowPattern.FindStringSubmatch(filePath); match != nil {
+		return match[1]
+	}
+	return filePath
+}
+
+// vectorDeleter deletes vectors from the vector store
+type vectorDeleter interface {
+	DeleteVector(ctx context.Context, vectorID string) error
+}
+
+// documentDeleter deletes documents from an OpenSearch index
+type documentDeleter interface {
+	DeleteDocument(ctx context.Context, indexName, docID string) error
+}
+
+// hashRecordDeleter deletes hash store records
+type hashRecordDeleter interface {
+	DeleteFileHash(ctx context.Context, sourceType, filePath string) error
+}
+
+// repairConsistency repairs the issues with the given mode and returns the number of issues
+// repaired and the number that the mode cannot repair
+func repairConsistency(
+	ctx context.Context,
+	mode string,
+	issues []pkgdomain.ConsistencyIssue,
+	records map[string]*hashstore.FileHashRecord,
+	vectors vectorDeleter,
+	documents documentDeleter,
+	index string,
+	hashes hashRecordDeleter,
+) (int, int) {
+	repaired, skipped := 0, 0
+	dropped := make(map[string]bool)
+	dropHashRecord := func(path string) bool {
+		if dropped[path] {
+			return true
+		}
+		record, ok := records[path]
+		if !ok {
+			return false
+		}
+		if err := hashes.DeleteFileHash(ctx, record.SourceType, path); err != nil {
+			log.Printf("Warning: failed to delete hash record of %s: %v", path, err)
+			return false
+		}
+		dropped[path] = true
+		return true
+	}
+
+	for _, issue := range issues {
+		ok := false
+		switch mode {
+		case RepairReindex:
+			ok = dropHashRecord(sourcePathOf(issue.FilePath))
+		case RepairDelete:
+			switch {
+			case issue.Kind == pkgdomain.IssueStaleHashRecord:
+				ok = dropHashRecord(issue.FilePath)
+			case issue.Backend == backendVectorStore:
+				ok = deleteOrWarn(issue, vectors.DeleteVector(ctx, issue.ID))
+			case issue.Backend == backendOpenSearch:
+				ok = deleteOrWarn(issue, documents.DeleteDocument(ctx, index, issue.ID))
+			}
+		}
+		if ok {
+			repaired++
+		} else {
+			skipped++
+		}
+	}
+	return repaired, skipped
+}
+
+func deleteOrWarn(issue pkgdomain.ConsistencyIssue, err error) bool {
+	if err != nil {
+		log.Printf("Warning: failed to delete %s from %s: %v", issue.ID, issue.Backend, err)
+		return false
+	}
+	return true
+}
+
+// printConsistencyReport prints the counts and up to limit issues
+func printConsistencyReport(report *pkgdomain.ConsistencyReport, limit int) {
+	fmt.Printf("\nConsistency check of %s (%s)\n", report.Index, report.CheckedAt.Format(time.DateTime))
+	fmt.Printf("  Vectors:       %d\n", report.VectorCount)
+	fmt.Printf("  Documents:     %d\n", report.DocumentCount)
+	fmt.Printf("  Hash records:  %d\n", report.HashRecordCount)
+
+	if len(report.Issues) == 0 {
+		fmt.Println("\nNo inconsistencies found")
+		return
+	}
+
+	counts := report.CountByKind()
+	kinds := make([]string, 0, len(counts))
+	for kind := range counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	fmt.Printf("\nFound %d issue(s):\n", len(report.Issues))
+	for _, kind := range kinds {
+		fmt.Printf("  %-24s %d\n", kind, counts[kind])
+	}
+
+	fmt.Println()
+	for i, issue := range report.Issues {
+		if limit > 0 && i >= limit {
+			fmt.Printf("  ... and %d more (use --limit 0 to show all)\n", len(report.Issues)-limit)
+			break
+		}
+		target := issue.ID
+		if target == "" {
+			target = issue.FilePath
+		} else if issue.FilePath != "" {
+			target = fmt.Sprintf("%s (%s)", issue.ID, issue.FilePath)
+		}
+		fmt.Printf("  [%s] %s: %s\n", issue.Kind, target, strings.TrimSpace(issue.Detail))
+	}
+}
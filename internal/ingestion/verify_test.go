@@ -0,0 +1,138 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+type fakeConsistencyDeleter struct {
+	vectors   []string
+	documents []string
+	hashes    []string
+	fail      string
+}
+
+func (f *fakeConsistencyDeleter) DeleteVector(ctx context.Context, vectorID string) error {
+	if vectorID == f.fail {
+		return errors.New("delete failed")
+	}
+	f.vectors = append(f.vectors, vectorID)
+	return nil
+}
+
+func (f *fakeConsistencyDeleter) DeleteDocument(ctx context.Context, indexName, docID string) error {
+	f.documents = append(f.documents, indexName+"/"+docID)
+	return nil
+}
+
+func (f *fakeConsistencyDeleter) DeleteFileHash(ctx context.Context, sourceType, filePath string) error {
+	f.hashes = append(f.hashes, sourceType+":"+filePath)
+	return nil
+}
+
+func issueKinds(issues []pkgdomain.ConsistencyIssue) map[string][]string {
+	kinds := make(map[string][]string)
+	for _, issue := range issues {
+		target := issue.ID
+		if target == "" {
+			target = issue.FilePath
+		}
+		kinds[issue.Kind] = append(kinds[issue.Kind], target)
+	}
+	return kinds
+}
+
+func TestCheckConsistency(t *testing.T) {
+	vectors := []storedEntry{
+		{ID: "a_chunk_0", FilePath: "docs/a.md", Dimension: 4},
+		{ID: "a_chunk_1", FilePath: "docs/a.md", Dimension: 4},
+		{ID: "b_chunk_0", FilePath: "docs/b.md", Dimension: 4},
+		{ID: "c_chunk_0", FilePath: "docs/c.md", Dimension: 8},
+		{ID: "r_chunk_0", FilePath: "csv://data/rows.csv/row/1", Dimension: 4},
+	}
+	documents := []storedEntry{
+		{ID: "a_chunk_0", FilePath: "docs/a.md", Dimension: 4, TotalChunks: 4},
+		{ID: "a_chunk_2", FilePath: "docs/a.md", Dimension: 4, TotalChunks: 4},
+		{ID: "c_chunk_0", FilePath: "docs/c.md", Dimension: 4},
+		{ID: "r_chunk_0", FilePath: "csv://data/rows.csv/row/1", Dimension: 4},
+	}
+	records := map[string]*hashstore.FileHashRecord{
+		"docs/a.md":     {SourceType: "local", FilePath: "docs/a.md"},
+		"data/rows.csv": {SourceType: "local", FilePath: "data/rows.csv"},
+		"docs/gone.md":  {SourceType: "local", FilePath: "docs/gone.md", VectorizedAt: time.Now()},
+	}
+
+	issues := checkConsistency(vectors, documents, records)
+	kinds := issueKinds(issues)
+
+	assert.Equal(t, []string{"a_chunk_1", "b_chunk_0"}, kinds[pkgdomain.IssueMissingInOpenSearch])
+	assert.Equal(t, []string{"a_chunk_2"}, kinds[pkgdomain.IssueMissingInVectorStore])
+	assert.Equal(t, []string{"a_chunk_3"}, kinds[pkgdomain.IssueMissingChunk], "chunks present in one backend are not reported as missing")
+	assert.Equal(t, []string{"c_chunk_0"}, kinds[pkgdomain.IssueDimensionMismatch])
+	assert.Equal(t, []string{"docs/gone.md"}, kinds[pkgdomain.IssueStaleHashRecord], "CSV rows map to the hash record of their file")
+
+	for _, issue := range issues {
+		if issue.Kind == pkgdomain.IssueDimensionMismatch {
+			assert.Equal(t, backendVectorStore, issue.Backend)
+			assert.Contains(t, issue.Detail, "8 dimensions, expected 4")
+		}
+	}
+}
+
+func TestCheckConsistency_Consistent(t *testing.T) {
+	entries := []storedEntry{{ID: "a_chunk_0", FilePath: "docs/a.md", Dimension: 4}}
+	records := map[string]*hashstore.FileHashRecord{"docs/a.md": {SourceType: "local"}}
+	assert.Empty(t, checkConsistency(entries, entries, records))
+}
+
+func TestRepairConsistency(t *testing.T) {
+	records := map[string]*hashstore.FileHashRecord{
+		"docs/a.md":     {SourceType: "local", FilePath: "docs/a.md"},
+		"data/rows.csv": {SourceType: "s3", FilePath: "data/rows.csv"},
+	}
+	issues := []pkgdomain.ConsistencyIssue{
+		{Kind: pkgdomain.IssueMissingInOpenSearch, Backend: backendVectorStore, ID: "a_chunk_1", FilePath: "docs/a.md"},
+		{Kind: pkgdomain.IssueMissingInOpenSearch, Backend: backendVectorStore, ID: "broken", FilePath: "docs/a.md"},
+		{Kind: pkgdomain.IssueMissingInVectorStore, Backend: backendOpenSearch, ID: "r_chunk_0", FilePath: "csv://data/rows.csv/row/1"},
+		{Kind: pkgdomain.IssueMissingChunk, ID: "x_chunk_3", FilePath: "docs/untracked.md"},
+		{Kind: pkgdomain.IssueStaleHashRecord, Backend: backendHashStore, FilePath: "docs/a.md"},
+	}
+
+	reindex := &fakeConsistencyDeleter{}
+	repaired, skipped := repairConsistency(context.Background(), RepairReindex, issues, records, reindex, reindex, "docs", reindex)
+	assert.Equal(t, 4, repaired)
+	assert.Equal(t, 1, skipped, "files without hash records cannot be re-indexed")
+	assert.Equal(t, []string{"local:docs/a.md", "s3:data/rows.csv"}, reindex.hashes, "each file is re-indexed once")
+	assert.Empty(t, reindex.vectors)
+	assert.Empty(t, reindex.documents)
+
+	deleter := &fakeConsistencyDeleter{fail: "broken"}
+	repaired, skipped = repairConsistency(context.Background(), RepairDelete, issues, records, deleter, deleter, "docs", deleter)
+	assert.Equal(t, 3, repaired)
+	assert.Equal(t, 2, skipped, "a failed delete and a missing chunk are not repaired")
+	assert.Equal(t, []string{"a_chunk_1"}, deleter.vectors)
+	assert.Equal(t, []string{"docs/r_chunk_0"}, deleter.documents)
+	assert.Equal(t, []string{"local:docs/a.md"}, deleter.hashes)
+}
+
+func TestSourcePathOf(t *testing.T) {
+	assert.Equal(t, "data/rows.csv", sourcePathOf("csv://data/rows.csv/row/12"))
+	assert.Equal(t, "s3://bucket/rows.csv", sourcePathOf("csv://s3://bucket/rows.csv/row/0"))
+	assert.Equal(t, "docs/a.md", sourcePathOf("docs/a.md"))
+	assert.Equal(t, "spreadsheet://id/Sheet1/row/2", sourcePathOf("spreadsheet://id/Sheet1/row/2"))
+}
+
+func TestConsistencyReportCountByKind(t *testing.T) {
+	report := &pkgdomain.ConsistencyReport{Issues: []pkgdomain.ConsistencyIssue{
+		{Kind: pkgdomain.IssueMissingChunk}, {Kind: pkgdomain.IssueMissingChunk}, {Kind: pkgdomain.IssueStaleHashRecord},
+	}}
+	require.Equal(t, map[string]int{pkgdomain.IssueMissingChunk: 2, pkgdomain.IssueStaleHashRecord: 1}, report.CountByKind())
+}
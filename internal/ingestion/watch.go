@@ -0,0 +1,268 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ca-srg/ragent/internal/ingestion/csv"
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+	"github.com/ca-srg/ragent/internal/ingestion/scanner"
+	"github.com/ca-srg/ragent/internal/ingestion/vectorizer"
+	"github.com/ca-srg/ragent/internal/ingestion/watcher"
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	pkgdomain "github.com/ca-srg/ragent/internal/pkg/domain"
+	"github.com/ca-srg/ragent/internal/pkg/ipc"
+)
+
+// DefaultWatchDebounce is the default debounce window for watch mode.
+const DefaultWatchDebounce = "2s"
+
+// validateWatchModeFlags ensures watch mode related flags are used with valid combinations.
+func validateWatchModeFlags(cmd *cobra.Command) error {
+	if !watchMode {
+		flag := cmd.Flags().Lookup("debounce")
+		if flag != nil && flag.Changed {
+			return fmt.Errorf("--debounce flag requires --watch")
+		}
+		watchDebounceDuration = 0
+		return nil
+	}
+
+	if followMode {
+		return fmt.Errorf("--watch cannot be used with --follow")
+	}
+
+	if dryRun {
+		return fmt.Errorf("--watch cannot be used with --dry-run")
+	}
+
+	if clearVectors {
+		return fmt.Errorf("--watch cannot be used with --clear")
+	}
+
+	if spreadsheetConfigPath != "" {
+		return fmt.Errorf("--watch cannot be used with --spreadsheet-config")
+	}
+
+	if enableS3 || githubRepos != "" {
+		return fmt.Errorf("--watch only supports the local --directory source")
+	}
+
+	if directory == "" {
+		return fmt.Errorf("--watch requires --directory")
+	}
+
+	debounceValue := watchDebounce
+	if debounceValue == "" {
+		debounceValue = DefaultWatchDebounce
+	}
+
+	duration, err := time.ParseDuration(debounceValue)
+	if err != nil {
+		return fmt.Errorf("invalid debounce: %w", err)
+	}
+
+	if duration <= 0 {
+		return fmt.Errorf("debounce must be positive")
+	}
+
+	watchDebounceDuration = duration
+	return nil
+}
+
+// runWatchMode vectorizes the local directory once and then re-vectorizes files as
+// file system events arrive, instead of rescanning at a fixed interval.
+func runWatchMode(ctx context.Context, cfg *appconfig.Config) error {
+	watchCtx, cancel := setupSignalHandler(ctx)
+	defer cancel()
+
+	ipcServer, stopIPC, err := startIPCServer(watchCtx, "[Watch Mode]")
+	if err != nil {
+		return err
+	}
+	defer stopIPC()
+
+	fileScanner := scanner.NewFileScanner()
+
+	// Start watching before the initial run so edits made during it are not lost
+	fsWatcher, err := watcher.New(directory, watchDebounceDuration, fileScanner.IsSupportedFile)
+	if err != nil {
+		return fmt.Errorf("failed to watch directory %s: %w", directory, err)
+	}
+	watchErrCh := make(chan error, 1)
+	go func() {
+		watchErrCh <- fsWatcher.Run(watchCtx)
+	}()
+
+	log.Printf("Watch mode enabled. Watching %s (debounce: %s). Press Ctrl+C to stop.", directory, watchDebounceDuration)
+
+	// Catch up on changes made while nothing was watching
+	if _, err := runCycleWithIPC(watchCtx, cfg, ipcServer, "[Watch Mode]"); err != nil {
+		log.Printf("[Watch Mode] Initial vectorization failed: %v", err)
+	}
+
+	service, csvCfg, err := createVectorizerServiceFromFlags(cfg, nil)
+	if err != nil {
+		return err
+	}
+
+	store, err := hashstore.NewHashStore()
+	if err != nil {
+		return fmt.Errorf("failed to initialize hash store: %w", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	var ipcStopChan <-chan struct{}
+	if ipcServer != nil {
+		ipcStopChan = ipcServer.StopChan()
+	}
+
+	remove := newSourceRemover(cfg, openSearchIndexName)
+	batches := fsWatcher.Batches()
+
+	for {
+		select {
+		case <-watchCtx.Done():
+			log.Println("[Watch Mode] Shutdown complete.")
+			return nil
+		case <-ipcStopChan:
+			log.Println("[Watch Mode] Stop requested via IPC.")
+			return nil
+		case err := <-watchErrCh:
+			if err != nil {
+				return fmt.Errorf("file watcher stopped: %w", err)
+			}
+			log.Println("[Watch Mode] Shutdown complete.")
+			return nil
+		case batch, ok := <-batches:
+			if !ok {
+				batches = nil
+				continue
+			}
+			if len(batch.Removed) > 0 {
+				handleWatchRemovals(watchCtx, store, batch.Removed, remove)
+			}
+			if len(batch.Changed) > 0 {
+				if err := processWatchBatch(watchCtx, cfg, csvCfg, service, store, fileScanner, batch.Changed, ipcServer); err != nil {
+					log.Printf("[Watch Mode] Vectorization batch failed: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// handleWatchRemovals deletes the documents of removed files from the vector store and
+// OpenSearch, then drops their hash records so a file restored later is vectorized again.
+// The records are kept when the documents could not be deleted.
+func handleWatchRemovals(ctx context.Context, store *hashstore.HashStore, paths []string, remove sourceRemover) {
+	if err := remove(ctx, paths); err != nil {
+		log.Printf("[Watch Mode] Warning: Failed to delete documents of removed files: %v", err)
+		return
+	}
+	for _, path := range paths {
+		if err := store.DeleteFileHash(ctx, "local", path); err != nil {
+			log.Printf("[Watch Mode] Warning: Failed to delete hash for %s: %v", path, err)
+			continue
+		}
+		log.Printf("[Watch Mode] Removed: %s", path)
+	}
+}
+
+// processWatchBatch vectorizes the changed files of a single debounced batch.
+// Files whose content hash matches the hash store are skipped.
+func processWatchBatch(
+	ctx context.Context,
+	cfg *appconfig.Config,
+	csvCfg *csv.Config,
+	service *vectorizer.VectorizerService,
+	store *hashstore.HashStore,
+	fileScanner *scanner.FileScanner,
+	paths []string,
+	ipcServer *ipc.Server,
+) error {
+	files := make([]*pkgdomain.FileInfo, 0, len(paths))
+	for _, path := range paths {
+		f, err := loadLocalFile(fileScanner, path)
+		if err != nil {
+			// The file may have been removed again before the batch was flushed
+			log.Printf("[Watch Mode] Skipping %s: %v", path, err)
+			continue
+		}
+		files = append(files, f)
+	}
+
+	detector := hashstore.NewChangeDetector(store)
+	filesToProcess, err := detector.FilterChangedFiles(ctx, files)
+	if err != nil {
+		return fmt.Errorf("change detection failed: %w", err)
+	}
+	if len(filesToProcess) == 0 {
+		return nil
+	}
+
+	if !startFollowProcessing() {
+		return fmt.Errorf("previous vectorization still running")
+	}
+	defer finishFollowProcessing()
+
+	log.Printf("[Watch Mode] Vectorizing %d changed file(s)...", len(filesToProcess))
+
+	if ipcServer != nil {
+		ipcServer.SetStateWithTime(ipc.StateRunning, time.Now())
+		service.SetProgressCallback(func(processed, total int) {
+			percentage := 0.0
+			if total > 0 {
+				percentage = float64(processed) / float64(total) * 100.0
+			}
+			ipcServer.UpdateProgress(&ipc.ProgressResponse{
+				TotalFiles:     total,
+				ProcessedFiles: processed,
+				Percentage:     percentage,
+			})
+		})
+	}
+
+	result, err := service.VectorizeFiles(ctx, filesToProcess, false)
+	if err != nil {
+		if ipcServer != nil {
+			ipcServer.UpdateStatus(&ipc.StatusResponse{
+				State: ipc.StateError,
+				Error: err.Error(),
+				PID:   os.Getpid(),
+			})
+		}
+		return fmt.Errorf("vectorization failed: %w", err)
+	}
+
+	if result != nil && result.SuccessCount > 0 {
+		updateHashStoreForSuccessfulFiles(ctx, store, filesToProcess, result)
+	}
+	recordFailures(ctx, store, filesToProcess, result)
+	if result != nil && result.SuccessCount > 0 {
+		dualWriteMigrationCandidate(ctx, cfg, store, csvCfg, openSearchIndexName, filesToProcess)
+	}
+
+	if ipcServer != nil {
+		ipcServer.SetState(ipc.StateWaiting)
+		if result != nil {
+			ipcServer.UpdateProgress(&ipc.ProgressResponse{
+				TotalFiles:     result.ProcessedFiles,
+				ProcessedFiles: result.ProcessedFiles,
+				SuccessCount:   result.SuccessCount,
+				FailedCount:    result.FailureCount,
+				Percentage:     100.0,
+			})
+		}
+	}
+
+	if result != nil {
+		log.Printf("[Watch Mode] Batch completed. Succeeded: %d, Failed: %d", result.SuccessCount, result.FailureCount)
+	}
+
+	return nil
+}
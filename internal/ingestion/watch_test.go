@@ -0,0 +1,155 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ca-srg/ragent/internal/ingestion/hashstore"
+)
+
+func newTestWatchCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "vectorize"}
+	cmd.Flags().StringVar(&watchDebounce, "debounce", DefaultWatchDebounce, "test debounce flag")
+	return cmd
+}
+
+func resetWatchModeState() {
+	resetFollowModeState()
+	watchMode = false
+	watchDebounce = DefaultWatchDebounce
+	watchDebounceDuration = 0
+	directory = "./source"
+	enableS3 = false
+	githubRepos = ""
+	spreadsheetConfigPath = ""
+}
+
+func TestValidateWatchModeFlags(t *testing.T) {
+	resetWatchModeState()
+	t.Cleanup(resetWatchModeState)
+
+	tests := []struct {
+		name         string
+		setup        func(*cobra.Command)
+		wantErr      bool
+		errContains  string
+		wantDuration time.Duration
+	}{
+		{
+			name:         "default debounce",
+			setup:        func(cmd *cobra.Command) { watchMode = true },
+			wantDuration: 2 * time.Second,
+		},
+		{
+			name: "custom debounce",
+			setup: func(cmd *cobra.Command) {
+				watchMode = true
+				flag := cmd.Flags().Lookup("debounce")
+				_ = flag.Value.Set("500ms")
+				flag.Changed = true
+			},
+			wantDuration: 500 * time.Millisecond,
+		},
+		{
+			name: "debounce without watch",
+			setup: func(cmd *cobra.Command) {
+				flag := cmd.Flags().Lookup("debounce")
+				_ = flag.Value.Set("1s")
+				flag.Changed = true
+			},
+			wantErr:     true,
+			errContains: "--debounce flag requires --watch",
+		},
+		{
+			name: "watch with follow",
+			setup: func(cmd *cobra.Command) {
+				watchMode = true
+				followMode = true
+			},
+			wantErr:     true,
+			errContains: "--watch cannot be used with --follow",
+		},
+		{
+			name: "watch with dry run",
+			setup: func(cmd *cobra.Command) {
+				watchMode = true
+				dryRun = true
+			},
+			wantErr:     true,
+			errContains: "--watch cannot be used with --dry-run",
+		},
+		{
+			name: "watch with github source",
+			setup: func(cmd *cobra.Command) {
+				watchMode = true
+				githubRepos = "owner/repo"
+			},
+			wantErr:     true,
+			errContains: "only supports the local --directory source",
+		},
+		{
+			name: "invalid debounce",
+			setup: func(cmd *cobra.Command) {
+				watchMode = true
+				watchDebounce = "soon"
+			},
+			wantErr:     true,
+			errContains: "invalid debounce",
+		},
+		{
+			name: "zero debounce",
+			setup: func(cmd *cobra.Command) {
+				watchMode = true
+				watchDebounce = "0s"
+			},
+			wantErr:     true,
+			errContains: "debounce must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetWatchModeState()
+			cmd := newTestWatchCmd()
+			tt.setup(cmd)
+
+			err := validateWatchModeFlags(cmd)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDuration, watchDebounceDuration)
+		})
+	}
+}
+
+func TestHandleWatchRemovalsDeletesDocuments(t *testing.T) {
+	ctx := context.Background()
+	store := newTestHashStore(t)
+	require.NoError(t, store.UpsertFileHash(ctx, &hashstore.FileHashRecord{SourceType: "local", FilePath: "/docs/a.md", ContentHash: "h"}))
+
+	handleWatchRemovals(ctx, store, []string{"/docs/a.md"}, func(ctx context.Context, sources []string) error {
+		return errors.New("OpenSearch unavailable")
+	})
+	record, err := store.GetFileHash(ctx, "local", "/docs/a.md")
+	require.NoError(t, err)
+	assert.NotNil(t, record, "the hash record stays while the documents could not be deleted")
+
+	var removed []string
+	handleWatchRemovals(ctx, store, []string{"/docs/a.md"}, func(ctx context.Context, sources []string) error {
+		removed = append(removed, sources...)
+		return nil
+	})
+	assert.Equal(t, []string{"/docs/a.md"}, removed)
+	record, err = store.GetFileHash(ctx, "local", "/docs/a.md")
+	require.NoError(t, err)
+	assert.Nil(t, record)
+}
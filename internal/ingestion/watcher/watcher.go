@@ -0,0 +1,239 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce is the default quiet period before changed files are flushed as a batch.
+const DefaultDebounce = 2 * time.Second
+
+// Batch is a set of file system changes under the watched directory.
+// Changed holds files that were created or written; Removed holds files
+// that were deleted or moved away.
+type Batch struct {
+	Changed []string
+	Removed []string
+}
+
+// Watcher watches a directory tree and groups events for supported files into batches.
+// Writes are debounced so that a burst of saves turns into a single batch, while
+// removals are emitted immediately.
+type Watcher struct {
+	debounce time.Duration
+	filter   func(path string) bool
+
+	fsw     *fsnotify.Watcher
+	batches chan Batch
+
+	mu      sync.Mutex
+	known   map[string]bool
+	pending map[string]bool
+}
+
+// New creates a Watcher for root. filter reports whether a file path is of interest;
+// directories are always watched. Reported paths are built from root as given, so
+// they match the paths produced by scanning the same directory with filepath.WalkDir.
+// A non-positive debounce uses DefaultDebounce.
+func New(root string, debounce time.Duration, filter func(path string) bool) (*Watcher, error) {
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	w := &Watcher{
+		debounce: debounce,
+		filter:   filter,
+		fsw:      fsw,
+		batches:  make(chan Batch, 16),
+		known:    make(map[string]bool),
+		pending:  make(map[string]bool),
+	}
+
+	if _, err := w.addTree(root); err != nil {
+		_ = fsw.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Batches returns the channel on which batches are delivered. It is closed when Run returns.
+func (w *Watcher) Batches() <-chan Batch {
+	return w.batches
+}
+
+// Run processes file system events until ctx is cancelled or the watcher fails.
+func (w *Watcher) Run(ctx context.Context) error {
+	defer close(w.batches)
+	defer func() { _ = w.fsw.Close() }()
+
+	timer := time.NewTimer(w.debounce)
+	if !timer.Stop() {
+		<-timer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("[Watch Mode] Watcher error: %v", err)
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return nil
+			}
+			if removed := w.handleEvent(event); len(removed) > 0 {
+				if !w.send(ctx, Batch{Removed: removed}) {
+					return nil
+				}
+			}
+			if w.hasPending() {
+				timer.Reset(w.debounce)
+			}
+		case <-timer.C:
+			if changed := w.flushPending(); len(changed) > 0 {
+				if !w.send(ctx, Batch{Changed: changed}) {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+func (w *Watcher) send(ctx context.Context, batch Batch) bool {
+	select {
+	case w.batches <- batch:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// handleEvent records a single event and returns any files that were removed.
+func (w *Watcher) handleEvent(event fsnotify.Event) []string {
+	path := event.Name
+
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		return w.forget(path)
+	}
+
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+
+	if info.IsDir() {
+		if event.Has(fsnotify.Create) {
+			// Files may have been written before the new directory was watched
+			files, err := w.addTree(path)
+			if err != nil {
+				log.Printf("[Watch Mode] Warning: %v", err)
+			}
+			w.mu.Lock()
+			for _, f := range files {
+				w.pending[f] = true
+			}
+			w.mu.Unlock()
+		}
+		return nil
+	}
+
+	if !w.filter(path) {
+		return nil
+	}
+
+	w.mu.Lock()
+	w.known[path] = true
+	w.pending[path] = true
+	w.mu.Unlock()
+	return nil
+}
+
+// forget drops path, or every known file below it when it was a directory.
+func (w *Watcher) forget(path string) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	prefix := path + string(filepath.Separator)
+	var removed []string
+	for f := range w.known {
+		if f == path || strings.HasPrefix(f, prefix) {
+			removed = append(removed, f)
+			delete(w.known, f)
+			delete(w.pending, f)
+		}
+	}
+	return removed
+}
+
+func (w *Watcher) hasPending() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pending) > 0
+}
+
+func (w *Watcher) flushPending() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	changed := make([]string, 0, len(w.pending))
+	for f := range w.pending {
+		changed = append(changed, f)
+	}
+	w.pending = make(map[string]bool)
+	return changed
+}
+
+// addTree watches dir and all of its subdirectories and returns the supported files found.
+func (w *Watcher) addTree(dir string) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip entries with permission errors but continue walking
+			return nil
+		}
+		if d.IsDir() {
+			if err := w.fsw.Add(path); err != nil {
+				return fmt.Errorf("failed to watch %s: %w", path, err)
+			}
+			return nil
+		}
+		if w.filter(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	for _, f := range files {
+		w.known[f] = true
+	}
+	w.mu.Unlock()
+
+	return files, nil
+}
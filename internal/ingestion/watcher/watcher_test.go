@@ -0,0 +1,98 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func isMarkdown(path string) bool {
+	return strings.HasSuffix(path, ".md")
+}
+
+func startWatcher(t *testing.T, dir string) *Watcher {
+	t.Helper()
+	w, err := New(dir, 50*time.Millisecond, isMarkdown)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = w.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return w
+}
+
+func nextBatch(t *testing.T, w *Watcher) Batch {
+	t.Helper()
+	select {
+	case batch := <-w.Batches():
+		return batch
+	case <-time.After(3 * time.Second):
+		t.Fatal("timed out waiting for batch")
+		return Batch{}
+	}
+}
+
+func TestWatcher_DebouncesWrites(t *testing.T) {
+	dir := t.TempDir()
+	w := startWatcher(t, dir)
+
+	docPath := filepath.Join(dir, "doc.md")
+	for i := 0; i < 5; i++ {
+		require.NoError(t, os.WriteFile(docPath, []byte(strings.Repeat("x", i+1)), 0o644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ignored.txt"), []byte("x"), 0o644))
+
+	batch := nextBatch(t, w)
+	assert.Equal(t, []string{docPath}, batch.Changed)
+	assert.Empty(t, batch.Removed)
+}
+
+func TestWatcher_EmitsRemovals(t *testing.T) {
+	dir := t.TempDir()
+	docPath := filepath.Join(dir, "doc.md")
+	require.NoError(t, os.WriteFile(docPath, []byte("# Doc"), 0o644))
+
+	w := startWatcher(t, dir)
+
+	require.NoError(t, os.Remove(docPath))
+
+	batch := nextBatch(t, w)
+	assert.Equal(t, []string{docPath}, batch.Removed)
+	assert.Empty(t, batch.Changed)
+}
+
+func TestWatcher_NewDirectory(t *testing.T) {
+	dir := t.TempDir()
+	w := startWatcher(t, dir)
+
+	subDir := filepath.Join(dir, "guides")
+	require.NoError(t, os.Mkdir(subDir, 0o755))
+	time.Sleep(20 * time.Millisecond)
+	docPath := filepath.Join(subDir, "setup.md")
+	require.NoError(t, os.WriteFile(docPath, []byte("# Setup"), 0o644))
+
+	batch := nextBatch(t, w)
+	assert.Contains(t, batch.Changed, docPath)
+
+	// Removing the directory removes every known file beneath it
+	require.NoError(t, os.RemoveAll(subDir))
+
+	var removed []string
+	for len(removed) == 0 {
+		removed = append(removed, nextBatch(t, w).Removed...)
+	}
+	assert.Equal(t, []string{docPath}, removed)
+}
@@ -0,0 +1,374 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/sync/singleflight"
+
+	appcfg "github.com/ca-srg/ragent/internal/pkg/config"
+)
+
+const (
+	// APIKeyPrefix marks generated keys so they can be told apart from OIDC bearer tokens
+	APIKeyPrefix = "rgk_"
+	// APIKeyHeader carries an API key; "Authorization: Bearer rgk_..." works as well
+	APIKeyHeader = "X-API-Key"
+
+	apiKeyHashPrefix           = "sha256:"
+	apiKeySecretsManagerPrefix = "secretsmanager://"
+)
+
+var loadAPIKeysSecret = appcfg.LoadSecretString
+
+// APIKey is one entry of the MCP_API_KEYS file. Only the SHA-256 hash of the key is
+// stored. Entries may share a name, which is how a key is rotated: add the new key
+// under the same name, move the callers over, then remove or expire the old entry.
+type APIKey struct {
+	Name         string     `json:"name"`
+	Hash         string     `json:"hash"`                    // "sha256:<hex>" of the raw key
+	Tools        []string   `json:"tools,omitempty"`         // Allowed tools; empty allows every tool
+	SecretAccess bool       `json:"secret_access,omitempty"` // Whether secret documents are searchable
+	Scopes       []string   `json:"scopes,omitempty"`        // Treated like OIDC scopes, e.g. ragent:write
+	Groups       []string   `json:"groups,omitempty"`        // Treated like the ACL_OIDC_CLAIM groups
+	Tenants      []string   `json:"tenants,omitempty"`       // Treated like the TENANT_OIDC_CLAIM tenants
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	Disabled     bool       `json:"disabled,omitempty"`
+}
+
+// APIKeyFile is the JSON document stored in the file or secret named by MCP_API_KEYS
+type APIKeyFile struct {
+	Keys []APIKey `json:"keys"`
+}
+
+// APIKeyIdentity is the authenticated API key attached to the request context
+type APIKeyIdentity struct {
+	Name         string
+	Tools        map[string]bool
+	SecretAccess bool
+	ExpiresAt    *time.Time
+
+	token *TokenInfo
+}
+
+// AllowsTool reports whether the key may call tool. Names are matched with and
+// without MCP_TOOL_PREFIX, like the rate limit settings.
+func (id *APIKeyIdentity) AllowsTool(tool, prefix string) bool {
+	if id == nil || len(id.Tools) == 0 {
+		return true
+	}
+	return id.Tools[tool] || (prefix != "" && id.Tools[strings.TrimPrefix(tool, prefix)])
+}
+
+// APIKeyStore validates API keys against the hashed entries loaded from a file or
+// from Secrets Manager, reloading them periodically so rotations need no restart.
+type APIKeyStore struct {
+	source         string
+	reloadInterval time.Duration
+	groupsClaim    string
+	tenantsClaim   string
+	load           func(ctx context.Context) ([]byte, error)
+	now            func() time.Time
+
+	mu       sync.RWMutex
+	keys     map[string]APIKey // keyed by hex hash
+	loadedAt time.Time
+
+	reloads singleflight.Group // Shares one reload among the requests that find the keys stale
+}
+
+// NewAPIKeyStore loads the keys named by MCP_API_KEYS: a file path or secretsmanager://SECRET_ID
+func NewAPIKeyStore(ctx context.Context, cfg *appcfg.Config) (*APIKeyStore, error) {
+	source := strings.TrimSpace(cfg.MCPAPIKeys)
+	if source == "" {
+		return nil, fmt.Errorf("MCP_API_KEYS is required for API key authentication")
+	}
+
+	store := &APIKeyStore{
+		source:         source,
+		reloadInterval: cfg.MCPAPIKeysReloadInterval,
+		groupsClaim:    cfg.ACLOIDCClaim,
+		tenantsClaim:   cfg.TenantOIDCClaim,
+		now:            time.Now,
+	}
+	if secretID, ok := strings.CutPrefix(source, apiKeySecretsManagerPrefix); ok {
+		if secretID == "" {
+			return nil, fmt.Errorf("MCP_API_KEYS secret ID is empty")
+		}
+		store.load = func(ctx context.Context) ([]byte, error) {
+			value, err := loadAPIKeysSecret(ctx, secretID, "")
+			return []byte(value), err
+		}
+	} else {
+		store.load = func(ctx context.Context) ([]byte, error) {
+			return os.ReadFile(source)
+		}
+	}
+
+	if err := store.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Reload replaces the keys with the current contents of the source
+func (s *APIKeyStore) Reload(ctx context.Context) error {
+	data, err := s.load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read API keys from %s: %w", s.source, err)
+	}
+	keys, err := parseAPIKeys(data)
+	if err != nil {
+		return fmt.Errorf("invalid API keys in %s: %w", s.source, err)
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.loadedAt = s.now()
+	s.mu.Unlock()
+	return nil
+}
+
+func parseAPIKeys(data []byte) (map[string]APIKey, error) {
+	var file APIKeyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]APIKey, len(file.Keys))
+	for i, key := range file.Keys {
+		key.Name = strings.TrimSpace(key.Name)
+		if key.Name == "" {
+			return nil, fmt.Errorf("key %d has no name", i)
+		}
+		hash, ok := strings.CutPrefix(strings.ToLower(strings.TrimSpace(key.Hash)), apiKeyHashPrefix)
+		if decoded, err := hex.DecodeString(hash); !ok || err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("key %q must have a hash of the form sha256:<64 hex characters>", key.Name)
+		}
+		if _, exists := keys[hash]; exists {
+			return nil, fmt.Errorf("key %q duplicates the hash of another key", key.Name)
+		}
+		keys[hash] = key
+	}
+	return keys, nil
+}
+
+// Authenticate returns the identity of a valid, unexpired key
+func (s *APIKeyStore) Authenticate(ctx context.Context, rawKey string) (*APIKeyIdentity, error) {
+	s.reloadIfStale(ctx)
+
+	sum := sha256.Sum256([]byte(rawKey))
+	hash := hex.EncodeToString(sum[:])
+
+	s.mu.RLock()
+	key, found := s.keys[hash]
+	s.mu.RUnlock()
+
+	if !found || key.Disabled {
+		return nil, fmt.Errorf("invalid API key")
+	}
+	if key.ExpiresAt != nil && !s.now().Before(*key.ExpiresAt) {
+		return nil, fmt.Errorf("API key %q expired at %s", key.Name, key.ExpiresAt.Format(time.RFC3339))
+	}
+
+	identity := &APIKeyIdentity{
+		Name:         key.Name,
+		Tools:        make(map[string]bool, len(key.Tools)),
+		SecretAccess: key.SecretAccess,
+		ExpiresAt:    key.ExpiresAt,
+		token:        s.tokenInfo(key),
+	}
+	for _, tool := range key.Tools {
+		identity.Tools[strings.TrimSpace(tool)] = true
+	}
+	return identity, nil
+}
+
+// reloadIfStale re-reads the source after MCP_API_KEYS_RELOAD_INTERVAL. A failed reload
+// keeps the previous keys so a broken edit does not lock every service out. Concurrent
+// requests wait for a single reload instead of each reading the source.
+func (s *APIKeyStore) reloadIfStale(ctx context.Context) {
+	if s.reloadInterval <= 0 || !s.stale() {
+		return
+	}
+	_, _, _ = s.reloads.Do("reload", func() (interface{}, error) {
+		// A request arriving just after another reload finished finds the keys fresh
+		if !s.stale() {
+			return nil, nil
+		}
+		if err := s.Reload(ctx); err != nil {
+			log.Printf("Keeping previously loaded API keys: %v", err)
+			s.mu.Lock()
+			s.loadedAt = s.now()
+			s.mu.Unlock()
+		}
+		return nil, nil
+	})
+}
+
+func (s *APIKeyStore) stale() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.now().Sub(s.loadedAt) >= s.reloadInterval
+}
+
+// tokenInfo builds the identity policies see for a key: secret, tenant, group and write
+// checks read the same claims they read from an OIDC token.
+func (s *APIKeyStore) tokenInfo(key APIKey) *TokenInfo {
+	claims := map[string]interface{}{}
+	if len(key.Scopes) > 0 {
+		claims["scope"] = strings.Join(key.Scopes, " ")
+	}
+	if len(key.Groups) > 0 && s.groupsClaim != "" {
+		claims[s.groupsClaim] = key.Groups
+	}
+	if len(key.Tenants) > 0 && s.tenantsClaim != "" {
+		claims[s.tenantsClaim] = key.Tenants
+	}
+
+	info := &TokenInfo{Subject: "apikey:" + key.Name, Claims: claims}
+	if key.ExpiresAt != nil {
+		info.ExpiresAt = *key.ExpiresAt
+	}
+	return info
+}
+
+// Size returns the number of loaded keys
+func (s *APIKeyStore) Size() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.keys)
+}
+
+// extractAPIKey returns the key from X-API-Key or from a bearer token carrying APIKeyPrefix
+func extractAPIKey(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get(APIKeyHeader)); key != "" {
+		return key
+	}
+	const bearerPrefix = "Bearer "
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), bearerPrefix); ok {
+		if token = strings.TrimSpace(token); strings.HasPrefix(token, APIKeyPrefix) {
+			return token
+		}
+	}
+	return ""
+}
+
+// withAPIKeyIdentity attaches the key and its derived identity to ctx
+func withAPIKeyIdentity(ctx context.Context, identity *APIKeyIdentity) context.Context {
+	ctx = context.WithValue(ctx, apiKeyContextKey, identity)
+	return context.WithValue(ctx, userContextKey, identity.token)
+}
+
+func getAPIKeyFromContext(ctx context.Context) *APIKeyIdentity {
+	if ctx == nil {
+		return nil
+	}
+	identity, _ := ctx.Value(apiKeyContextKey).(*APIKeyIdentity)
+	return identity
+}
+
+// sendAPIKeyError answers with 401 and a JSON-RPC error, like the OIDC middleware
+func sendAPIKeyError(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("WWW-Authenticate", `Bearer realm="ragent-mcp"`)
+	w.WriteHeader(http.StatusUnauthorized)
+	response := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"error": map[string]interface{}{
+			"code":    -32001,
+			"message": message,
+		},
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
+
+// Allowlist entries that grant API keys with a tool allowlist access to the resources
+// and prompts of the server
+const (
+	apiKeyResourcesEntry = "resources"
+	apiKeyPromptsEntry   = "prompts"
+)
+
+// apiKeyToolMiddleware enforces the tool allowlist of API key callers: tools/list only
+// shows the allowed tools and tools/call rejects the others. Resources and prompts need
+// a "resources" or "prompts" entry; without it they are listed empty and cannot be read.
+func apiKeyToolMiddleware(prefix string) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			identity := getAPIKeyFromContext(ctx)
+			if identity == nil || len(identity.Tools) == 0 {
+				return next(ctx, method, req)
+			}
+
+			switch method {
+			case "tools/call":
+				if call, ok := req.(*mcp.CallToolRequest); ok && call.Params != nil && !identity.AllowsTool(call.Params.Name, prefix) {
+					return nil, fmt.Errorf("API key %q is not allowed to call tool %s", identity.Name, call.Params.Name)
+				}
+			case "tools/list":
+				result, err := next(ctx, method, req)
+				if list, ok := result.(*mcp.ListToolsResult); ok && err == nil {
+					allowed := make([]*mcp.Tool, 0, len(list.Tools))
+					for _, tool := range list.Tools {
+						if identity.AllowsTool(tool.Name, prefix) {
+							allowed = append(allowed, tool)
+						}
+					}
+					list.Tools = allowed
+				}
+				return result, err
+			case "resources/list":
+				if !identity.Tools[apiKeyResourcesEntry] {
+					return &mcp.ListResourcesResult{Resources: []*mcp.Resource{}}, nil
+				}
+			case "resources/templates/list":
+				if !identity.Tools[apiKeyResourcesEntry] {
+					return &mcp.ListResourceTemplatesResult{ResourceTemplates: []*mcp.ResourceTemplate{}}, nil
+				}
+			case "resources/read", "resources/subscribe", "resources/unsubscribe":
+				if !identity.Tools[apiKeyResourcesEntry] {
+					return nil, fmt.Errorf("API key %q is not allowed to read resources", identity.Name)
+				}
+			case "prompts/list":
+				if !identity.Tools[apiKeyPromptsEntry] {
+					return &mcp.ListPromptsResult{Prompts: []*mcp.Prompt{}}, nil
+				}
+			case "prompts/get":
+				if !identity.Tools[apiKeyPromptsEntry] {
+					return nil, fmt.Errorf("API key %q is not allowed to get prompts", identity.Name)
+				}
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// GenerateAPIKey returns a new random key and the hash to store in MCP_API_KEYS
+func GenerateAPIKey() (key, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key = APIKeyPrefix + hex.EncodeToString(buf)
+	return key, HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the "sha256:<hex>" form of key used in MCP_API_KEYS
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return apiKeyHashPrefix + hex.EncodeToString(sum[:])
+}
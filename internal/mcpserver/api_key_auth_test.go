@@ -0,0 +1,310 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appcfg "github.com/ca-srg/ragent/internal/pkg/config"
+)
+
+func writeAPIKeyFile(t *testing.T, path string, keys ...APIKey) {
+	t.Helper()
+	data, err := json.Marshal(APIKeyFile{Keys: keys})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func newTestAPIKeyStore(t *testing.T, keys ...APIKey) (*APIKeyStore, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "api-keys.json")
+	writeAPIKeyFile(t, path, keys...)
+	store, err := NewAPIKeyStore(context.Background(), &appcfg.Config{
+		MCPAPIKeys:      path,
+		ACLOIDCClaim:    "groups",
+		TenantOIDCClaim: "tenant",
+	})
+	require.NoError(t, err)
+	return store, path
+}
+
+func TestAPIKeyStoreAuthenticate(t *testing.T) {
+	expired := time.Now().Add(-time.Hour)
+	store, _ := newTestAPIKeyStore(t,
+		APIKey{Name: "ci", Hash: HashAPIKey("rgk_ci"), Tools: []string{"hybrid_search"}, Scopes: []string{"ragent:write"}, Groups: []string{"eng"}, Tenants: []string{"acme"}},
+		APIKey{Name: "old", Hash: HashAPIKey("rgk_old"), ExpiresAt: &expired},
+		APIKey{Name: "off", Hash: HashAPIKey("rgk_off"), Disabled: true},
+	)
+
+	identity, err := store.Authenticate(context.Background(), "rgk_ci")
+	require.NoError(t, err)
+	assert.Equal(t, "ci", identity.Name)
+	assert.False(t, identity.SecretAccess)
+	assert.True(t, identity.AllowsTool("kb_hybrid_search", "kb_"))
+	assert.False(t, identity.AllowsTool("kb_ask", "kb_"))
+	assert.Equal(t, "apikey:ci", identity.token.Subject)
+	assert.Equal(t, "ragent:write", identity.token.Claims["scope"])
+	assert.Equal(t, []string{"eng"}, identity.token.Claims["groups"])
+	assert.Equal(t, []string{"acme"}, identity.token.Claims["tenant"])
+
+	_, err = store.Authenticate(context.Background(), "rgk_old")
+	assert.ErrorContains(t, err, "expired")
+	_, err = store.Authenticate(context.Background(), "rgk_off")
+	assert.ErrorContains(t, err, "invalid API key")
+	_, err = store.Authenticate(context.Background(), "rgk_unknown")
+	assert.ErrorContains(t, err, "invalid API key")
+}
+
+func TestAPIKeyStoreReloadsRotatedKeys(t *testing.T) {
+	store, path := newTestAPIKeyStore(t, APIKey{Name: "ci", Hash: HashAPIKey("rgk_v1")})
+	store.reloadInterval = time.Minute
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	writeAPIKeyFile(t, path, APIKey{Name: "ci", Hash: HashAPIKey("rgk_v2")})
+	_, err := store.Authenticate(context.Background(), "rgk_v1")
+	require.NoError(t, err, "keys must not reload before the interval")
+
+	now = now.Add(time.Minute)
+	_, err = store.Authenticate(context.Background(), "rgk_v1")
+	assert.Error(t, err, "the rotated-out key must stop working after a reload")
+	_, err = store.Authenticate(context.Background(), "rgk_v2")
+	assert.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+	now = now.Add(time.Minute)
+	_, err = store.Authenticate(context.Background(), "rgk_v2")
+	assert.NoError(t, err, "a broken file must keep the previous keys")
+}
+
+func TestAPIKeyStoreReloadsOnceForConcurrentRequests(t *testing.T) {
+	store, _ := newTestAPIKeyStore(t, APIKey{Name: "ci", Hash: HashAPIKey("rgk_ci")})
+	store.reloadInterval = time.Minute
+	now := time.Now().Add(time.Minute)
+	store.now = func() time.Time { return now }
+
+	data, err := json.Marshal(APIKeyFile{Keys: []APIKey{{Name: "ci", Hash: HashAPIKey("rgk_ci")}}})
+	require.NoError(t, err)
+	var loads atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	store.load = func(ctx context.Context) ([]byte, error) {
+		if loads.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return data, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.Authenticate(context.Background(), "rgk_ci")
+			assert.NoError(t, err)
+		}()
+	}
+	<-started
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), loads.Load(), "stale keys must be reloaded once, not once per request")
+}
+
+func TestNewAPIKeyStoreFromSecretsManager(t *testing.T) {
+	original := loadAPIKeysSecret
+	t.Cleanup(func() { loadAPIKeysSecret = original })
+	loadAPIKeysSecret = func(ctx context.Context, secretID, region string) (string, error) {
+		assert.Equal(t, "ragent/mcp-api-keys", secretID)
+		return `{"keys":[{"name":"svc","hash":"` + HashAPIKey("rgk_svc") + `","secret_access":true}]}`, nil
+	}
+
+	store, err := NewAPIKeyStore(context.Background(), &appcfg.Config{MCPAPIKeys: "secretsmanager://ragent/mcp-api-keys"})
+	require.NoError(t, err)
+	identity, err := store.Authenticate(context.Background(), "rgk_svc")
+	require.NoError(t, err)
+	assert.True(t, identity.SecretAccess)
+}
+
+func TestParseAPIKeysRejectsInvalidEntries(t *testing.T) {
+	for _, data := range []string{
+		`{"keys":[{"hash":"` + HashAPIKey("a") + `"}]}`,
+		`{"keys":[{"name":"plain","hash":"rgk_plaintext"}]}`,
+		`{"keys":[{"name":"a","hash":"` + HashAPIKey("a") + `"},{"name":"b","hash":"` + HashAPIKey("a") + `"}]}`,
+	} {
+		_, err := parseAPIKeys([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestUnifiedAuthAPIKeyMethods(t *testing.T) {
+	store, _ := newTestAPIKeyStore(t, APIKey{Name: "ci", Hash: HashAPIKey("rgk_ci")})
+
+	serve := func(method AuthMethod, clientIP string, headers map[string]string) (*httptest.ResponseRecorder, context.Context) {
+		config := &UnifiedAuthConfig{AuthMethod: method, APIKeys: store}
+		if method == AuthMethodIP || method == AuthMethodBoth {
+			config.IPConfig = &IPAuthConfig{AllowedIPs: []string{"127.0.0.1"}}
+		}
+		if method == AuthMethodBoth {
+			config.OIDCConfig = &OIDCConfig{ClientID: "test-client", AuthorizationURL: "http://localhost/auth", TokenURL: "http://localhost/token", SkipDiscovery: true}
+		}
+		middleware, err := NewUnifiedAuthMiddleware(config)
+		require.NoError(t, err)
+
+		var captured context.Context
+		handler := middleware.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			captured = r.Context()
+			w.WriteHeader(http.StatusOK)
+		}))
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.RemoteAddr = clientIP + ":12345"
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec, captured
+	}
+
+	rec, _ := serve(AuthMethodAPIKey, "10.0.0.1", nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "apikey method requires a key")
+
+	rec, _ = serve(AuthMethodAPIKey, "10.0.0.1", map[string]string{APIKeyHeader: "rgk_wrong"})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec, ctx := serve(AuthMethodAPIKey, "10.0.0.1", map[string]string{"Authorization": "Bearer rgk_ci"})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, string(AuthMethodAPIKey), getAuthMethodFromContext(ctx))
+	assert.Equal(t, "ci", getAPIKeyFromContext(ctx).Name)
+
+	// With ip the key lets a caller in from outside the allowlist
+	rec, _ = serve(AuthMethodIP, "10.0.0.1", map[string]string{APIKeyHeader: "rgk_ci"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec, ctx = serve(AuthMethodIP, "127.0.0.1", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Nil(t, getAPIKeyFromContext(ctx))
+
+	// With both the key replaces OIDC but the IP must still be allowed
+	rec, _ = serve(AuthMethodBoth, "10.0.0.1", map[string]string{APIKeyHeader: "rgk_ci"})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec, ctx = serve(AuthMethodBoth, "127.0.0.1", map[string]string{APIKeyHeader: "rgk_ci"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, string(AuthMethodAPIKey), getAuthMethodFromContext(ctx))
+
+	_, err := NewUnifiedAuthMiddleware(&UnifiedAuthConfig{AuthMethod: AuthMethodAPIKey})
+	assert.Error(t, err, "apikey method without keys must fail")
+}
+
+func TestAPIKeySecretPolicyAndPrincipal(t *testing.T) {
+	store, _ := newTestAPIKeyStore(t,
+		APIKey{Name: "reader", Hash: HashAPIKey("rgk_reader")},
+		APIKey{Name: "trusted", Hash: HashAPIKey("rgk_trusted"), SecretAccess: true},
+	)
+	adapter := &HybridSearchToolAdapter{}
+
+	for key, excludeSecret := range map[string]bool{"rgk_reader": true, "rgk_trusted": false} {
+		identity, err := store.Authenticate(context.Background(), key)
+		require.NoError(t, err)
+		ctx := withAPIKeyIdentity(context.Background(), identity)
+
+		request := &HybridSearchRequest{}
+		adapter.applySecretPolicyFromContext(ctx, request)
+		assert.Equal(t, excludeSecret, request.ExcludeSecret, key)
+
+		limiter := &RateLimiter{}
+		assert.Equal(t, "apikey:"+identity.Name, limiter.Principal(ctx))
+	}
+}
+
+func TestAPIKeyToolMiddleware(t *testing.T) {
+	identity := &APIKeyIdentity{Name: "ci", Tools: map[string]bool{"hybrid_search": true}}
+	ctx := context.WithValue(context.Background(), apiKeyContextKey, identity)
+
+	called := false
+	handler := apiKeyToolMiddleware("kb_")(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		called = true
+		if method == "tools/list" {
+			return &mcp.ListToolsResult{Tools: []*mcp.Tool{{Name: "kb_hybrid_search"}, {Name: "kb_ask"}}}, nil
+		}
+		return &mcp.CallToolResult{}, nil
+	})
+
+	result, err := handler(ctx, "tools/list", &mcp.ListToolsRequest{})
+	require.NoError(t, err)
+	tools := result.(*mcp.ListToolsResult).Tools
+	require.Len(t, tools, 1)
+	assert.Equal(t, "kb_hybrid_search", tools[0].Name)
+
+	_, err = handler(ctx, "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "kb_ask"}})
+	assert.ErrorContains(t, err, "not allowed to call tool kb_ask")
+
+	called = false
+	_, err = handler(ctx, "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "kb_hybrid_search"}})
+	assert.NoError(t, err)
+	assert.True(t, called)
+
+	called = false
+	_, err = handler(context.Background(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "kb_ask"}})
+	assert.NoError(t, err, "callers without an API key are not restricted")
+	assert.True(t, called)
+}
+
+func TestAPIKeyToolMiddlewareGatesResourcesAndPrompts(t *testing.T) {
+	var calls []string
+	handler := apiKeyToolMiddleware("kb_")(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		calls = append(calls, method)
+		switch method {
+		case "resources/list":
+			return &mcp.ListResourcesResult{Resources: []*mcp.Resource{{URI: "ragent://stats"}}}, nil
+		case "prompts/list":
+			return &mcp.ListPromptsResult{Prompts: []*mcp.Prompt{{Name: "summarize"}}}, nil
+		case "resources/read":
+			return &mcp.ReadResourceResult{}, nil
+		}
+		return &mcp.GetPromptResult{}, nil
+	})
+
+	restricted := withAPIKeyIdentity(context.Background(), &APIKeyIdentity{Name: "ci", Tools: map[string]bool{"hybrid_search": true}})
+	read := &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: "ragent://documents/secret.md"}}
+
+	_, err := handler(restricted, "resources/read", read)
+	assert.ErrorContains(t, err, "not allowed to read resources")
+	_, err = handler(restricted, "prompts/get", &mcp.GetPromptRequest{Params: &mcp.GetPromptParams{Name: "summarize"}})
+	assert.ErrorContains(t, err, "not allowed to get prompts")
+
+	result, err := handler(restricted, "resources/list", &mcp.ListResourcesRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, result.(*mcp.ListResourcesResult).Resources)
+	result, err = handler(restricted, "resources/templates/list", &mcp.ListResourceTemplatesRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, result.(*mcp.ListResourceTemplatesResult).ResourceTemplates)
+	result, err = handler(restricted, "prompts/list", &mcp.ListPromptsRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, result.(*mcp.ListPromptsResult).Prompts)
+	assert.Empty(t, calls, "restricted keys must not reach the resource and prompt handlers")
+
+	granted := withAPIKeyIdentity(context.Background(), &APIKeyIdentity{Name: "docs", Tools: map[string]bool{"hybrid_search": true, "resources": true, "prompts": true}})
+	_, err = handler(granted, "resources/read", read)
+	assert.NoError(t, err)
+	_, err = handler(granted, "prompts/get", &mcp.GetPromptRequest{Params: &mcp.GetPromptParams{Name: "summarize"}})
+	assert.NoError(t, err)
+	result, err = handler(granted, "resources/list", &mcp.ListResourcesRequest{})
+	require.NoError(t, err)
+	assert.Len(t, result.(*mcp.ListResourcesResult).Resources, 1)
+
+	unrestricted := withAPIKeyIdentity(context.Background(), &APIKeyIdentity{Name: "admin"})
+	_, err = handler(unrestricted, "resources/read", read)
+	assert.NoError(t, err, "keys without an allowlist keep full access")
+}
@@ -0,0 +1,399 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/ca-srg/ragent/internal/pkg/embedding/bedrock"
+	"github.com/ca-srg/ragent/internal/pkg/metrics"
+)
+
+// AskToolName is the base name of the answer tool; MCP_TOOL_PREFIX is prepended like for hybrid_search
+const AskToolName = "ask"
+
+// Share of the progress range reported by retrieval; the rest covers answer generation
+const askRetrievalProgressShare = 0.8
+
+// AskRetriever runs the hybrid_search pipeline. HybridSearchToolAdapter implements it,
+// so ask applies the same access policies and gathers the same Slack and MCP context.
+type AskRetriever interface {
+	HandleToolCallWithProgress(ctx context.Context, params map[string]interface{}, progressFn ProgressCallback) (*MCPToolCallResult, error)
+}
+
+// AskChatClient generates the answer. bedrock.BedrockClient implements it.
+type AskChatClient interface {
+	GenerateChatResponseWithMaxTokens(ctx context.Context, messages []bedrock.ChatMessage, maxTokens int) (string, error)
+}
+
+// AskModelProvider returns the chat client for a model ID
+type AskModelProvider func(model string) AskChatClient
+
+// AskConfig holds the model and token budget rules of the ask tool
+type AskConfig struct {
+	DefaultModel  string   // CHAT_MODEL, used when the caller does not pick a model
+	AllowedModels []string // MCP_ASK_MODELS, additional models a caller may pick
+	MaxTokens     int      // MCP_ASK_MAX_TOKENS, default and upper bound of max_tokens
+	SlackEnabled  bool     // Whether Slack search runs unless the caller disables it
+}
+
+// AskTool serves the ask tool: it retrieves context like hybrid_search and has the
+// chat model answer from it, citing the numbered sources.
+type AskTool struct {
+	retriever AskRetriever
+	models    AskModelProvider
+	config    AskConfig
+	logger    *log.Logger
+}
+
+// askArgs are the arguments of the ask tool
+type askArgs struct {
+	Question          string `json:"question"`
+	TopK              int    `json:"top_k"`
+	Category          string `json:"category"`
+	EnableSlackSearch *bool  `json:"enable_slack_search"`
+	Model             string `json:"model"`
+	MaxTokens         int    `json:"max_tokens"`
+}
+
+// AskResponse is the result of the ask tool
+type AskResponse struct {
+	Question      string        `json:"question"`
+	Answer        string        `json:"answer"`
+	Model         string        `json:"model"`
+	MaxTokens     int           `json:"max_tokens"`
+	Citations     []AskCitation `json:"citations"`
+	SearchSources []string      `json:"search_sources,omitempty"`
+}
+
+// AskCitation is a numbered source given to the model. Cited reports whether the
+// answer refers to it as [Number].
+type AskCitation struct {
+	Number      int     `json:"number"`
+	Type        string  `json:"type"` // "document", "slack" or "mcp"
+	Title       string  `json:"title,omitempty"`
+	ResourceURI string  `json:"resource_uri,omitempty"`
+	Path        string  `json:"path,omitempty"`
+	Score       float64 `json:"score,omitempty"`
+	Channel     string  `json:"channel,omitempty"`
+	Permalink   string  `json:"permalink,omitempty"`
+	Server      string  `json:"server,omitempty"`
+	Tool        string  `json:"tool,omitempty"`
+	Cited       bool    `json:"cited"`
+
+	content string
+	item    *HybridSearchResultItem
+}
+
+var askCitationPattern = regexp.MustCompile(`\[(\d+(?:\s*,\s*\d+)*)\]`)
+
+func NewAskTool(retriever AskRetriever, models AskModelProvider, config AskConfig) *AskTool {
+	return &AskTool{
+		retriever: retriever,
+		models:    models,
+		config:    config,
+		logger:    log.New(log.Writer(), "[AskTool] ", log.LstdFlags),
+	}
+}
+
+// Definition returns the tool with prefix prepended to its name
+func (at *AskTool) Definition(prefix string) ToolDefinition {
+	tool := &mcp.Tool{
+		Name:        prefix + AskToolName,
+		Description: "質問に対して、ハイブリッド検索・Slack・外部MCPツールから集めた情報をもとにサーバー側で回答を生成し、番号付きの引用とともに返します。\n\nEnglish: Answer a question on the server. The tool gathers context like hybrid_search (documents, Slack and external MCP tools), has the chat model answer from it and returns the answer with structured citations. Use hybrid_search instead when you want the raw hits.",
+		InputSchema: at.schema(),
+	}
+	markToolReadOnly(tool, tool.Name)
+	return ToolDefinition{Tool: tool, Handler: at.handle}
+}
+
+func (at *AskTool) handle(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	metrics.RecordInvocation(metrics.ModeMCP)
+
+	ctx, span := mcpTracer.Start(ctx, "mcpserver.ask")
+	defer span.End()
+
+	toolName := AskToolName
+	var arguments json.RawMessage
+	if req != nil && req.Params != nil {
+		if req.Params.Name != "" {
+			toolName = req.Params.Name
+		}
+		arguments = req.Params.Arguments
+	}
+	metricAttrs := []attribute.KeyValue{attribute.String("mcp.tool.name", toolName)}
+	span.SetAttributes(metricAttrs...)
+	if method := getAuthMethodFromContext(ctx); method != "" {
+		metricAttrs = append(metricAttrs, attribute.String("mcp.auth.method", method))
+	}
+	start := time.Now()
+	errType := ""
+	defer func() {
+		recordMCPMetrics(ctx, metricAttrs, time.Since(start), errType)
+	}()
+
+	// Same best-effort progress notifications as hybrid_search
+	var progressFn ProgressCallback
+	if req != nil && req.Params != nil {
+		if token := req.Params.GetProgressToken(); token != nil {
+			span.SetAttributes(attribute.Bool("mcp.progress.enabled", true))
+			progressFn = func(progress, total float64, message string) {
+				if req.Session == nil {
+					return
+				}
+				_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+					ProgressToken: token,
+					Progress:      progress,
+					Total:         total,
+					Message:       message,
+				})
+			}
+		}
+	}
+
+	result, err := at.ask(ctx, arguments, progressFn)
+	if err != nil {
+		errType = "tool_call_failed"
+		span.RecordError(err)
+		span.SetStatus(codes.Error, errType)
+		at.logger.Printf("ask failed: %v", err)
+		return convertRAGentResultToSDK(CreateToolCallErrorResult(err.Error())), nil
+	}
+	return convertRAGentResultToSDK(result), nil
+}
+
+func (at *AskTool) ask(ctx context.Context, arguments json.RawMessage, progressFn ProgressCallback) (*MCPToolCallResult, error) {
+	sendProgress := func(progress float64, message string) {
+		if progressFn != nil {
+			progressFn(progress, 1.0, message)
+		}
+	}
+
+	var args askArgs
+	if err := decodeToolArguments(arguments, &args); err != nil {
+		return nil, err
+	}
+	question := strings.TrimSpace(args.Question)
+	if question == "" {
+		return nil, fmt.Errorf("question is required")
+	}
+	model, err := at.resolveModel(args.Model)
+	if err != nil {
+		return nil, err
+	}
+	maxTokens := args.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = at.config.MaxTokens
+	}
+	if maxTokens < 1 || maxTokens > at.config.MaxTokens {
+		return nil, fmt.Errorf("max_tokens must be between 1 and %d", at.config.MaxTokens)
+	}
+
+	params := map[string]interface{}{"query": question}
+	if args.TopK != 0 {
+		params["top_k"] = args.TopK
+	}
+	if category := strings.TrimSpace(args.Category); category != "" {
+		params["filters"] = map[string]interface{}{"category": category}
+	}
+	enableSlack := at.config.SlackEnabled
+	if args.EnableSlackSearch != nil {
+		enableSlack = *args.EnableSlackSearch
+	}
+	params["enable_slack_search"] = enableSlack
+
+	// Retrieval reports its own phases; they are scaled into the first part of the range
+	var retrievalProgress ProgressCallback
+	if progressFn != nil {
+		retrievalProgress = func(progress, total float64, message string) {
+			if total <= 0 {
+				total = 1.0
+			}
+			sendProgress(progress/total*askRetrievalProgressShare, message)
+		}
+	}
+	searchResult, err := at.retriever.HandleToolCallWithProgress(ctx, params, retrievalProgress)
+	if err != nil {
+		return nil, err
+	}
+	if searchResult == nil || len(searchResult.Content) == 0 {
+		return nil, fmt.Errorf("search returned no response")
+	}
+	if searchResult.IsError {
+		return nil, fmt.Errorf("%s", searchResult.Content[0].Text)
+	}
+	var retrieved HybridSearchResponse
+	if err := json.Unmarshal([]byte(searchResult.Content[0].Text), &retrieved); err != nil {
+		return nil, fmt.Errorf("failed to parse search response: %w", err)
+	}
+
+	citations := askCitations(&retrieved)
+	sendProgress(askRetrievalProgressShare+0.05, fmt.Sprintf("Generating answer with %s from %d sources...", model, len(citations)))
+
+	answer, err := at.models(model).GenerateChatResponseWithMaxTokens(ctx, askMessages(question, citations), maxTokens)
+	if err != nil {
+		return nil, fmt.Errorf("answer generation failed: %w", err)
+	}
+	answer = strings.TrimSpace(answer)
+	markCitedSources(answer, citations)
+
+	response := AskResponse{
+		Question:      question,
+		Answer:        answer,
+		Model:         model,
+		MaxTokens:     maxTokens,
+		Citations:     citations,
+		SearchSources: retrieved.SearchSources,
+	}
+	result, err := jsonToolResult(response)
+	if err != nil {
+		return nil, err
+	}
+
+	// Link the cited documents so clients can open them in full
+	var cited []HybridSearchResultItem
+	for _, citation := range citations {
+		if citation.Cited && citation.item != nil {
+			cited = append(cited, *citation.item)
+		}
+	}
+	appendResourceLinks(result, cited)
+
+	sendProgress(1.0, "Answer completed")
+	return result, nil
+}
+
+// resolveModel returns the requested model if the server allows it, or CHAT_MODEL
+func (at *AskTool) resolveModel(requested string) (string, error) {
+	requested = strings.TrimSpace(requested)
+	if requested == "" || requested == at.config.DefaultModel {
+		return at.config.DefaultModel, nil
+	}
+	for _, model := range at.config.AllowedModels {
+		if model == requested {
+			return model, nil
+		}
+	}
+	return "", fmt.Errorf("model %s is not allowed; available models: %s", requested, strings.Join(at.availableModels(), ", "))
+}
+
+func (at *AskTool) availableModels() []string {
+	models := []string{at.config.DefaultModel}
+	for _, model := range at.config.AllowedModels {
+		if model != at.config.DefaultModel {
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+func (at *AskTool) schema() *jsonschema.Schema {
+	slackDefault, _ := json.Marshal(at.config.SlackEnabled)
+	schema := objectSchema("Ask Parameters", map[string]*jsonschema.Schema{
+		"question":            {Type: "string", Description: "Question to answer from the knowledge base"},
+		"top_k":               integerProperty("Number of documents retrieved as context", 1, 100, 10),
+		"category":            {Type: "string", Description: "Only use documents of this category"},
+		"enable_slack_search": {Type: "boolean", Description: "Include Slack messages in the context", Default: slackDefault},
+		"model":               {Type: "string", Description: "Chat model that writes the answer", Enum: modelEnum(at.availableModels())},
+		"max_tokens":          integerProperty("Output token budget of the answer", 1, at.config.MaxTokens, at.config.MaxTokens),
+	})
+	schema.Required = []string{"question"}
+	return schema
+}
+
+// askCitations numbers the retrieved documents, Slack messages and MCP tool results
+func askCitations(retrieved *HybridSearchResponse) []AskCitation {
+	var citations []AskCitation
+	add := func(citation AskCitation) {
+		citation.Number = len(citations) + 1
+		citations = append(citations, citation)
+	}
+
+	for i := range retrieved.Results {
+		item := &retrieved.Results[i]
+		add(AskCitation{
+			Type:        "document",
+			Title:       item.Title,
+			ResourceURI: item.ResourceURI,
+			Path:        item.Path,
+			Score:       item.Score,
+			content:     item.Content,
+			item:        item,
+		})
+	}
+	slackMessages := append(append([]HybridSearchSlackResult{}, retrieved.ReferencedSlackURLs...), retrieved.SlackResults...)
+	for _, message := range slackMessages {
+		add(AskCitation{
+			Type:      "slack",
+			Title:     fmt.Sprintf("#%s %s", message.Channel, message.User),
+			Channel:   message.Channel,
+			Permalink: message.Permalink,
+			content:   message.Message,
+		})
+	}
+	for _, toolResult := range retrieved.MCPResults {
+		add(AskCitation{
+			Type:    "mcp",
+			Title:   toolResult.Server + "/" + toolResult.Tool,
+			Server:  toolResult.Server,
+			Tool:    toolResult.Tool,
+			content: toolResult.Text,
+		})
+	}
+	return citations
+}
+
+// askMessages builds the chat messages that ask the model to answer from the sources
+func askMessages(question string, citations []AskCitation) []bedrock.ChatMessage {
+	system := "You answer questions using only the numbered sources provided. " +
+		"Cite every statement with the number of its source, e.g. [1] or [2, 3]. " +
+		"If the sources do not contain the answer, say so instead of guessing. " +
+		"Answer in the language of the question."
+
+	var b strings.Builder
+	b.WriteString("## Sources\n\n")
+	if len(citations) == 0 {
+		b.WriteString("No sources matched the question.\n\n")
+	}
+	for _, citation := range citations {
+		fmt.Fprintf(&b, "[%d] (%s) %s\n%s\n\n", citation.Number, citation.Type, citation.Title, strings.TrimSpace(citation.content))
+	}
+	b.WriteString("## Question\n\n")
+	b.WriteString(question)
+
+	return []bedrock.ChatMessage{
+		{Role: "system", Content: system},
+		{Role: "user", Content: b.String()},
+	}
+}
+
+// markCitedSources flags the citations the answer refers to as [n] or [n, m]
+func markCitedSources(answer string, citations []AskCitation) {
+	for _, match := range askCitationPattern.FindAllStringSubmatch(answer, -1) {
+		for _, field := range strings.Split(match[1], ",") {
+			number, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || number < 1 || number > len(citations) {
+				continue
+			}
+			citations[number-1].Cited = true
+		}
+	}
+}
+
+func modelEnum(models []string) []any {
+	values := make([]any, 0, len(models))
+	for _, model := range models {
+		values = append(values, model)
+	}
+	return values
+}
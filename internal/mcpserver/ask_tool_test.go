@@ -0,0 +1,174 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/ca-srg/ragent/internal/pkg/embedding/bedrock"
+	"github.com/ca-srg/ragent/internal/pkg/mcpclient"
+)
+
+type fakeAskRetriever struct {
+	params   map[string]interface{}
+	response HybridSearchResponse
+}
+
+func (r *fakeAskRetriever) HandleToolCallWithProgress(ctx context.Context, params map[string]interface{}, progressFn ProgressCallback) (*MCPToolCallResult, error) {
+	r.params = params
+	if progressFn != nil {
+		progressFn(1.0, 1.0, "Search completed")
+	}
+	data, _ := json.Marshal(r.response)
+	return CreateToolCallResult(string(data)), nil
+}
+
+type fakeAskChatClient struct {
+	answer    string
+	messages  []bedrock.ChatMessage
+	maxTokens int
+}
+
+func (c *fakeAskChatClient) GenerateChatResponseWithMaxTokens(ctx context.Context, messages []bedrock.ChatMessage, maxTokens int) (string, error) {
+	c.messages = messages
+	c.maxTokens = maxTokens
+	return c.answer, nil
+}
+
+func newTestAskTool(retriever *fakeAskRetriever, chat *fakeAskChatClient, models *[]string) *AskTool {
+	return NewAskTool(retriever, func(model string) AskChatClient {
+		*models = append(*models, model)
+		return chat
+	}, AskConfig{
+		DefaultModel:  "chat-model",
+		AllowedModels: []string{"small-model"},
+		MaxTokens:     2000,
+		SlackEnabled:  true,
+	})
+}
+
+func callAskTool(t *testing.T, tool *AskTool, args map[string]interface{}) *mcp.CallToolResult {
+	t.Helper()
+	definition := tool.Definition("")
+	data, _ := json.Marshal(args)
+	result, err := definition.Handler(context.Background(), &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: AskToolName, Arguments: data}})
+	if err != nil {
+		t.Fatalf("ask returned error: %v", err)
+	}
+	return result
+}
+
+func TestAskAnswersWithCitations(t *testing.T) {
+	retriever := &fakeAskRetriever{response: HybridSearchResponse{
+		Results: []HybridSearchResultItem{
+			{ID: "doc-1", Title: "Failover", Content: "Promote the replica.", ResourceURI: "ragent://doc/doc-1", Path: "runbooks/failover.md"},
+			{ID: "doc-2", Title: "Backups", Content: "Nightly snapshots."},
+		},
+		SlackResults:  []HybridSearchSlackResult{{Message: "Failover done in 5 minutes", Channel: "C1", User: "bob", Permalink: "https://slack.example/p1"}},
+		MCPResults:    []mcpclient.ToolResult{{Server: "wiki", Tool: "search", Text: "Failover SLA is 10 minutes"}},
+		SearchSources: []string{"opensearch", "slack", "mcp"},
+	}}
+	chat := &fakeAskChatClient{answer: "Promote the replica [1]. It took 5 minutes [3, 4]."}
+	var models []string
+	tool := newTestAskTool(retriever, chat, &models)
+
+	result := callAskTool(t, tool, map[string]interface{}{
+		"question": "How do we fail over?", "category": "Runbook", "max_tokens": 500, "model": "small-model",
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", toolResultText(t, result))
+	}
+
+	if retriever.params["query"] != "How do we fail over?" || retriever.params["enable_slack_search"] != true {
+		t.Fatalf("unexpected search params: %+v", retriever.params)
+	}
+	if filters, _ := retriever.params["filters"].(map[string]interface{}); filters["category"] != "Runbook" {
+		t.Fatalf("expected category filter, got %+v", retriever.params["filters"])
+	}
+	if len(models) != 1 || models[0] != "small-model" || chat.maxTokens != 500 {
+		t.Fatalf("unexpected model %v or max tokens %d", models, chat.maxTokens)
+	}
+	prompt := chat.messages[len(chat.messages)-1].Content
+	for _, want := range []string{"[1] (document) Failover", "[3] (slack)", "[4] (mcp) wiki/search", "How do we fail over?"} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("prompt is missing %q:\n%s", want, prompt)
+		}
+	}
+
+	var response AskResponse
+	if err := json.Unmarshal([]byte(toolResultText(t, result)), &response); err != nil {
+		t.Fatalf("invalid response JSON: %v", err)
+	}
+	if response.Answer != chat.answer || response.Model != "small-model" || len(response.Citations) != 4 {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+	cited := []bool{true, false, true, true}
+	for i, citation := range response.Citations {
+		if citation.Number != i+1 || citation.Cited != cited[i] {
+			t.Fatalf("unexpected citation %d: %+v", i, citation)
+		}
+	}
+	if response.Citations[0].ResourceURI != "ragent://doc/doc-1" || response.Citations[2].Permalink != "https://slack.example/p1" {
+		t.Fatalf("citations lost their references: %+v", response.Citations)
+	}
+
+	links := 0
+	for _, content := range result.Content {
+		if link, ok := content.(*mcp.ResourceLink); ok {
+			links++
+			if link.URI != "ragent://doc/doc-1" {
+				t.Fatalf("unexpected resource link %s", link.URI)
+			}
+		}
+	}
+	if links != 1 {
+		t.Fatalf("expected a resource link for the cited document, got %d", links)
+	}
+}
+
+func TestAskValidatesModelAndTokenBudget(t *testing.T) {
+	chat := &fakeAskChatClient{answer: "ok"}
+	var models []string
+	tool := newTestAskTool(&fakeAskRetriever{}, chat, &models)
+
+	cases := map[string]map[string]interface{}{
+		"question is required":         {"question": " "},
+		"is not allowed":               {"question": "q", "model": "other-model"},
+		"must be between 1 and 2000":   {"question": "q", "max_tokens": 4000},
+		"max_tokens must be between 1": {"question": "q", "max_tokens": -1},
+	}
+	for want, args := range cases {
+		result := callAskTool(t, tool, args)
+		if !result.IsError || !strings.Contains(toolResultText(t, result), want) {
+			t.Fatalf("expected error containing %q, got %s", want, toolResultText(t, result))
+		}
+	}
+	if len(models) != 0 {
+		t.Fatalf("chat model must not be called for invalid arguments")
+	}
+
+	result := callAskTool(t, tool, map[string]interface{}{"question": "q", "enable_slack_search": false})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", toolResultText(t, result))
+	}
+	if models[0] != "chat-model" || chat.maxTokens != 2000 {
+		t.Fatalf("expected CHAT_MODEL and MCP_ASK_MAX_TOKENS defaults, got %v / %d", models, chat.maxTokens)
+	}
+}
+
+func TestAskDefinitionIsReadOnly(t *testing.T) {
+	var models []string
+	definition := newTestAskTool(&fakeAskRetriever{}, &fakeAskChatClient{}, &models).Definition("kb_")
+	if definition.Tool.Name != "kb_ask" {
+		t.Fatalf("unexpected tool name %s", definition.Tool.Name)
+	}
+	if definition.Tool.Annotations == nil || !definition.Tool.Annotations.ReadOnlyHint {
+		t.Fatalf("ask must be annotated read-only")
+	}
+	if schema := definition.Tool.InputSchema; schema == nil || len(schema.Required) != 1 || schema.Required[0] != "question" {
+		t.Fatalf("question must be the only required argument")
+	}
+}
@@ -19,6 +19,8 @@ const (
 	AuthMethodBoth AuthMethod = "both"
 	// AuthMethodEither allows either IP or OIDC authentication
 	AuthMethodEither AuthMethod = "either"
+	// AuthMethodAPIKey requires a static API key from MCP_API_KEYS
+	AuthMethodAPIKey AuthMethod = "apikey"
 )
 
 // UnifiedAuthMiddleware combines IP and OIDC authentication with bypass support
@@ -30,6 +32,7 @@ type UnifiedAuthMiddleware struct {
 	bypassChecker  BypassIPChecker
 	bypassLogger   BypassAuditLogger
 	trustedProxies []string
+	apiKeys        *APIKeyStore
 }
 
 // UnifiedAuthConfig contains configuration for unified authentication
@@ -39,6 +42,7 @@ type UnifiedAuthConfig struct {
 	OIDCConfig    *OIDCConfig     // Configuration for OIDC authentication
 	EnableLogging bool            // Enable detailed logging
 	BypassConfig  *BypassIPConfig // Configuration for IP bypass authentication
+	APIKeys       *APIKeyStore    // API keys accepted alongside any method (required for apikey)
 }
 
 // IPAuthConfig contains configuration for IP authentication
@@ -64,6 +68,11 @@ func NewUnifiedAuthMiddleware(config *UnifiedAuthConfig) (*UnifiedAuthMiddleware
 	middleware := &UnifiedAuthMiddleware{
 		authMethod:    config.AuthMethod,
 		enableLogging: config.EnableLogging,
+		apiKeys:       config.APIKeys,
+	}
+
+	if config.AuthMethod == AuthMethodAPIKey && config.APIKeys == nil {
+		return nil, fmt.Errorf("API keys (MCP_API_KEYS) are required for method %s", config.AuthMethod)
 	}
 
 	// Initialize bypass authentication if configured
@@ -177,16 +186,33 @@ func (m *UnifiedAuthMiddleware) Middleware(next http.Handler) http.Handler {
 						}
 					}
 				}
+				if m.apiKeys != nil {
+					if key := extractAPIKey(r); key != "" {
+						if identity, err := m.apiKeys.Authenticate(r.Context(), key); err == nil {
+							ctx = withAPIKeyIdentity(ctx, identity)
+						}
+					}
+				}
 				next.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
 		}
 
-		// Bypass authentication for OAuth2 paths when OIDC is configured
-		if (r.URL.Path == "/callback" || r.URL.Path == "/login") && m.oidcAuth != nil {
+		// Bypass authentication for OAuth2 paths and resource metadata when OIDC is configured
+		if (r.URL.Path == "/callback" || r.URL.Path == "/login" || isProtectedResourceMetadataPath(r.URL.Path)) && m.oidcAuth != nil {
 			next.ServeHTTP(w, r)
 			return
 		}
+
+		// A presented API key decides the request in every mode; requests without one
+		// go through the configured method.
+		if m.apiKeys != nil {
+			if key := extractAPIKey(r); key != "" {
+				m.handleAPIKeyAuth(next, w, r, key)
+				return
+			}
+		}
+
 		switch m.authMethod {
 		case AuthMethodIP:
 			m.ipAuth.Middleware(next).ServeHTTP(w, r)
@@ -204,6 +230,12 @@ func (m *UnifiedAuthMiddleware) Middleware(next http.Handler) http.Handler {
 			// Allow either IP or OIDC authentication
 			m.handleEitherAuth(next, w, r)
 
+		case AuthMethodAPIKey:
+			if m.enableLogging {
+				log.Printf("Access denied: no API key presented (Path: %s)", r.URL.Path)
+			}
+			sendAPIKeyError(w, "API key required")
+
 		default:
 			// No authentication
 			next.ServeHTTP(w, r)
@@ -256,6 +288,37 @@ func (m *UnifiedAuthMiddleware) handleEitherAuth(next http.Handler, w http.Respo
 	m.oidcAuth.sendAuthenticationRequired(w, r)
 }
 
+// handleAPIKeyAuth authenticates a request that presented an API key. With "both" the key
+// stands in for the OIDC half, so the client IP must still be allowed.
+func (m *UnifiedAuthMiddleware) handleAPIKeyAuth(next http.Handler, w http.ResponseWriter, r *http.Request, key string) {
+	identity, err := m.apiKeys.Authenticate(r.Context(), key)
+	if err != nil {
+		if m.enableLogging {
+			log.Printf("API key authentication failed (Path: %s): %v", r.URL.Path, err)
+		}
+		sendAPIKeyError(w, err.Error())
+		return
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.enableLogging {
+			log.Printf("Access granted via API key: %s", identity.Name)
+		}
+		ctx := withAPIKeyIdentity(r.Context(), identity)
+		ctx = context.WithValue(ctx, authMethodContextKey, string(AuthMethodAPIKey))
+		if clientIP := extractClientIPFromRequest(r); clientIP != "" {
+			ctx = context.WithValue(ctx, clientIPContextKey, clientIP)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+
+	if m.authMethod == AuthMethodBoth {
+		m.ipAuth.Middleware(handler).ServeHTTP(w, r)
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
 // GetAuthMethod returns the current authentication method
 func (m *UnifiedAuthMiddleware) GetAuthMethod() AuthMethod {
 	return m.authMethod
@@ -277,6 +340,10 @@ func (m *UnifiedAuthMiddleware) SetAuthMethod(method AuthMethod) error {
 		if m.ipAuth == nil || m.oidcAuth == nil {
 			return fmt.Errorf("both IP and OIDC authentication must be configured")
 		}
+	case AuthMethodAPIKey:
+		if m.apiKeys == nil {
+			return fmt.Errorf("API key authentication is not configured")
+		}
 	}
 
 	m.authMethod = method
@@ -19,7 +19,7 @@ import (
 	"github.com/spf13/pflag"
 
 	appcfg "github.com/ca-srg/ragent/internal/pkg/config"
-	"github.com/ca-srg/ragent/internal/pkg/embedding"
+	"github.com/ca-srg/ragent/internal/pkg/domain"
 	"github.com/ca-srg/ragent/internal/pkg/embedding/bedrock"
 	"github.com/ca-srg/ragent/internal/pkg/evalexport"
 	"github.com/ca-srg/ragent/internal/pkg/mcpclient"
@@ -27,6 +27,7 @@ import (
 	"github.com/ca-srg/ragent/internal/pkg/observability"
 	"github.com/ca-srg/ragent/internal/pkg/opensearch"
 	"github.com/ca-srg/ragent/internal/pkg/slacksearch"
+	"github.com/ca-srg/ragent/internal/query/search"
 )
 
 // FlagChecker is a minimal interface so RunMCPServer can call cmd.Flags().Changed()
@@ -37,6 +38,7 @@ type FlagChecker interface {
 
 // MCPServerOptions holds all the command-line flag values for the mcp-server command.
 type MCPServerOptions struct {
+	Transport         string
 	AuthMethod        string
 	AuthEnableLogging bool
 	OIDCIssuer        string
@@ -59,6 +61,7 @@ type MCPServerOptions struct {
 	DashboardHandler  http.Handler
 	DashboardCleanup  func()
 	DashboardBasePath string
+	DocumentWriter    domain.DocumentWriter // Enables ingest_document/update_document when set
 }
 
 // RunMCPServer is the entry point for the mcp-server command.
@@ -73,6 +76,23 @@ func RunMCPServer(ctx context.Context, cmd FlagChecker, opts MCPServerOptions) e
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	transport := strings.ToLower(strings.TrimSpace(opts.Transport))
+	if transport == "" {
+		transport = TransportHTTP
+	}
+	if transport != TransportHTTP && transport != TransportStdio {
+		return fmt.Errorf("invalid transport: %s (allowed: http|stdio)", opts.Transport)
+	}
+	stdio := transport == TransportStdio
+
+	// In stdio mode stdout carries the protocol stream, so everything else that
+	// writes to os.Stdout (loggers, library prints) is redirected to stderr.
+	protocolOut := os.Stdout
+	if stdio {
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = protocolOut }()
+	}
+
 	logger := log.New(os.Stdout, "[MCP Server] ", log.LstdFlags)
 
 	// Override configuration with command line flags if provided
@@ -171,7 +191,7 @@ func RunMCPServer(ctx context.Context, cmd FlagChecker, opts MCPServerOptions) e
 	server.SetLogger(logger)
 
 	// Configure authentication
-	// If auth-method is provided, prefer unified auth routing (supports ip/oidc/both/either)
+	// If auth-method is provided, prefer unified auth routing (supports ip/oidc/both/either/apikey)
 	method := opts.AuthMethod
 	if method == "" {
 		method = "ip"
@@ -179,12 +199,51 @@ func RunMCPServer(ctx context.Context, cmd FlagChecker, opts MCPServerOptions) e
 
 	// Normalize method to lowercase
 	switch method {
-	case "ip", "oidc", "both", "either":
+	case "ip", "oidc", "both", "either", "apikey":
 	default:
-		return fmt.Errorf("invalid auth-method: %s (allowed: ip|oidc|both|either)", method)
+		return fmt.Errorf("invalid auth-method: %s (allowed: ip|oidc|both|either|apikey)", method)
 	}
 
-	if method == "ip" && cfg.MCPIPAuthEnabled {
+	// API keys are accepted by every HTTP method once MCP_API_KEYS is set
+	var apiKeys *APIKeyStore
+	if !stdio && (method == "apikey" || cfg.MCPAPIKeys != "") {
+		apiKeys, err = NewAPIKeyStore(ctx, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to load API keys: %w", err)
+		}
+		server.GetSDKServer().AddReceivingMiddleware(apiKeyToolMiddleware(cfg.MCPToolPrefix))
+		logger.Printf("API key authentication enabled with %d keys", apiKeys.Size())
+	}
+
+	if stdio {
+		logger.Printf("Stdio transport: HTTP authentication skipped (MCP_STDIO_TRUST_LOCAL_USER=%t)", cfg.MCPStdioTrustLocalUser)
+	} else if method == "apikey" || (method == "ip" && apiKeys != nil) {
+		// API keys without OIDC: alone, or next to the IP allowlist
+		unifiedCfg := &UnifiedAuthConfig{
+			AuthMethod:    AuthMethodAPIKey,
+			EnableLogging: opts.AuthEnableLogging,
+			APIKeys:       apiKeys,
+			BypassConfig:  bypassConfigFromConfig(cfg, logger),
+		}
+		if method == "ip" {
+			// Without MCP_IP_AUTH_ENABLED requests without a key stay unauthenticated, as before
+			unifiedCfg.AuthMethod = ""
+			if cfg.MCPIPAuthEnabled {
+				unifiedCfg.AuthMethod = AuthMethodIP
+				unifiedCfg.IPConfig = &IPAuthConfig{
+					AllowedIPs:    cfg.MCPAllowedIPs,
+					EnableLogging: cfg.MCPIPAuthEnableLogging,
+				}
+			}
+		}
+
+		unified, err := NewUnifiedAuthMiddleware(unifiedCfg)
+		if err != nil {
+			return fmt.Errorf("failed to create unified auth middleware: %w", err)
+		}
+		server.SetUnifiedAuthMiddleware(unified)
+		logger.Printf("Unified auth enabled (method=%s)", method)
+	} else if method == "ip" && cfg.MCPIPAuthEnabled {
 		// Backward-compatible IP-only behavior
 		ipAuthAdapter, err := NewIPAuthMiddlewareAdapter(cfg.MCPAllowedIPs, cfg.MCPIPAuthEnableLogging)
 		if err != nil {
@@ -257,27 +316,8 @@ func RunMCPServer(ctx context.Context, cmd FlagChecker, opts MCPServerOptions) e
 		unifiedCfg := &UnifiedAuthConfig{
 			AuthMethod:    authMethod,
 			EnableLogging: opts.AuthEnableLogging,
-		}
-
-		// Add bypass configuration if provided
-		if len(cfg.MCPBypassIPRanges) > 0 {
-			// Validate all CIDR formats before proceeding
-			for _, cidr := range cfg.MCPBypassIPRanges {
-				if _, _, err := net.ParseCIDR(cidr); err != nil {
-					// Try parsing as single IP (will be converted to CIDR internally)
-					if ip := net.ParseIP(cidr); ip == nil {
-						logger.Printf("Warning: Invalid CIDR format for bypass IP range: %s", cidr)
-					}
-				}
-			}
-
-			unifiedCfg.BypassConfig = &BypassIPConfig{
-				BypassIPRanges: cfg.MCPBypassIPRanges,
-				VerboseLogging: cfg.MCPBypassVerboseLog,
-				AuditLogging:   cfg.MCPBypassAuditLog,
-				TrustedProxies: cfg.MCPTrustedProxies,
-			}
-			logger.Printf("Bypass IP authentication configured with %d ranges", len(cfg.MCPBypassIPRanges))
+			APIKeys:       apiKeys,
+			BypassConfig:  bypassConfigFromConfig(cfg, logger),
 		}
 
 		// IP part (for both/either)
@@ -289,6 +329,10 @@ func RunMCPServer(ctx context.Context, cmd FlagChecker, opts MCPServerOptions) e
 		}
 
 		// OIDC part
+		sessionStore, err := sessionStoreFromConfig(cfg, logger)
+		if err != nil {
+			return err
+		}
 		unifiedCfg.OIDCConfig = &OIDCConfig{
 			Issuer:       oidcIssuer,
 			ClientID:     oidcClientID,
@@ -302,6 +346,13 @@ func RunMCPServer(ctx context.Context, cmd FlagChecker, opts MCPServerOptions) e
 			UserInfoURL:      oidcUserInfoURL,
 			JWKSURL:          oidcJWKSURL,
 			SkipDiscovery:    oidcSkipDiscovery,
+			Resource:         cfg.MCPOAuthResource,
+			RequiredScopes:   cfg.MCPOAuthRequiredScopes,
+			AllowIDTokens:    cfg.MCPOAuthAllowIDTokens,
+			SessionStore:     sessionStore,
+			SessionTTL:       cfg.MCPOIDCSessionTTL,
+			RefreshBefore:    cfg.MCPOIDCRefreshBefore,
+			SessionAdmins:    cfg.MCPSessionAdmins,
 		}
 
 		unified, err := NewUnifiedAuthMiddleware(unifiedCfg)
@@ -309,6 +360,7 @@ func RunMCPServer(ctx context.Context, cmd FlagChecker, opts MCPServerOptions) e
 			return fmt.Errorf("failed to create unified auth middleware: %w", err)
 		}
 		server.SetUnifiedAuthMiddleware(unified)
+		unified.GetOIDCAuthMiddleware().StartSessionRefresher(context.Background(), time.Minute)
 
 		logger.Printf("Unified auth enabled (method=%s)", method)
 		if method == "oidc" || method == "both" || method == "either" {
@@ -321,6 +373,12 @@ func RunMCPServer(ctx context.Context, cmd FlagChecker, opts MCPServerOptions) e
 				logger.Printf("OIDC issuer: %s", oidcIssuer)
 			}
 			logger.Printf("OAuth2 callback port: %d", cfg.MCPServerPort)
+			if cfg.MCPOAuthResource != "" {
+				logger.Printf("OAuth protected resource: %s (required scopes: %v, ID tokens allowed: %t)",
+					cfg.MCPOAuthResource, cfg.MCPOAuthRequiredScopes, cfg.MCPOAuthAllowIDTokens)
+			} else {
+				logger.Printf("WARNING: MCP_OAUTH_RESOURCE is not set; bearer tokens are checked against the client ID audience only")
+			}
 		}
 	} else {
 		logger.Printf("WARNING: No authentication middleware enabled (auth-method=%s)", method)
@@ -464,7 +522,8 @@ func RunMCPServer(ctx context.Context, cmd FlagChecker, opts MCPServerOptions) e
 		}
 		logger.Printf("OpenSearch connection established: %s", cfg.OpenSearchEndpoint)
 
-		embeddingClient, err := embedding.NewEmbeddingClient(cfg)
+		// Queries follow the embedding model of the served index across model migrations
+		embeddingClient, err := opensearch.NewActiveEmbeddingClient(bgCtx, osClient, cfg, cfg.OpenSearchIndex)
 		if err != nil {
 			return fmt.Errorf("failed to create embedding client: %w", err)
 		}
@@ -478,6 +537,8 @@ func RunMCPServer(ctx context.Context, cmd FlagChecker, opts MCPServerOptions) e
 			DefaultFusionMethod:   "weighted_sum",
 			DefaultUseJapaneseNLP: cfg.MCPDefaultUseJapaneseNLP,
 			DefaultTimeoutSeconds: cfg.MCPDefaultTimeoutSeconds,
+			DefaultEfSearch:       cfg.OpenSearchKNNEfSearch,
+			AccessControl:         cfg,
 		}
 
 		// Create hybrid search tool handler for SDK integration
@@ -518,9 +579,96 @@ func RunMCPServer(ctx context.Context, cmd FlagChecker, opts MCPServerOptions) e
 			documentedParams,
 		)
 		registeredTools = append(registeredTools, toolName)
+
+		// Register ask, which answers from the hybrid_search context on the server
+		if cfg.MCPAskEnabled {
+			askTool := NewAskTool(hybridSearchHandler.GetAdapter(), func(model string) AskChatClient {
+				return bedrock.GetSharedBedrockClient(awsConfig, model)
+			}, AskConfig{
+				DefaultModel:  cfg.ChatModel,
+				AllowedModels: cfg.MCPAskModels,
+				MaxTokens:     cfg.MCPAskMaxTokens,
+				SlackEnabled:  slackService != nil,
+			})
+			askDefinition := askTool.Definition(cfg.MCPToolPrefix)
+			if evalWriter != nil {
+				markToolMutating(askDefinition.Tool, askDefinition.Tool.Name)
+			}
+			if err := server.RegisterCustomTool(askDefinition.Tool, askDefinition.Handler); err != nil {
+				return fmt.Errorf("failed to register %s tool: %w", askDefinition.Tool.Name, err)
+			}
+			registeredTools = append(registeredTools, askDefinition.Tool.Name)
+		}
+
+		// Register the read-only document tools next to hybrid_search
+		documentTools := NewDocumentTools(osClient, hybridSearchConfig)
+		for _, definition := range documentTools.Definitions(cfg.MCPToolPrefix) {
+			if err := server.RegisterCustomTool(definition.Tool, definition.Handler); err != nil {
+				return fmt.Errorf("failed to register %s tool: %w", definition.Tool.Name, err)
+			}
+			registeredTools = append(registeredTools, definition.Tool.Name)
+		}
+
+		// Register the document write tools only when an ingest writer was provided
+		if opts.DocumentWriter != nil {
+			writePermission, err := NewWritePermission(cfg)
+			if err != nil {
+				return err
+			}
+			ingestTools := NewIngestTools(opts.DocumentWriter, writePermission, hybridSearchConfig)
+			for _, definition := range ingestTools.Definitions(cfg.MCPToolPrefix) {
+				if err := server.RegisterCustomTool(definition.Tool, definition.Handler); err != nil {
+					return fmt.Errorf("failed to register %s tool: %w", definition.Tool.Name, err)
+				}
+				registeredTools = append(registeredTools, definition.Tool.Name)
+			}
+			logger.Printf("Document write tools enabled (ingest directory: %s)", cfg.MCPIngestDirectory)
+		}
+
+		// Expose indexed documents as ragent:// resources under the same access policies
+		resourceProvider := NewDocumentResourceProvider(osClient, hybridSearchConfig)
+		for _, template := range resourceProvider.Templates() {
+			if err := server.RegisterResourceTemplate(template, resourceProvider.HandleReadResource); err != nil {
+				return fmt.Errorf("failed to register resource template %s: %w", template.URITemplate, err)
+			}
+		}
+
+		// Register server-defined prompts that embed freshly retrieved context
+		promptSearchService, err := search.NewHybridSearchService(cfg, embeddingClient, nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create prompt search service: %w", err)
+		}
+		if err := promptSearchService.Initialize(bgCtx); err != nil {
+			return fmt.Errorf("failed to initialize prompt search service: %w", err)
+		}
+		defer func() { _ = promptSearchService.Close() }()
+
+		var slackFetcher SlackThreadFetcher
+		if slackService != nil {
+			slackFetcher = slackService
+		}
+		promptProvider := NewPromptProvider(promptSearchService, resourceProvider, slackFetcher, hybridSearchConfig)
+		for _, definition := range promptProvider.Definitions() {
+			if err := server.RegisterPrompt(definition.Prompt, definition.Handler); err != nil {
+				return fmt.Errorf("failed to register prompt %s: %w", definition.Prompt.Name, err)
+			}
+		}
 	}
 
-	if opts.DashboardHandler != nil {
+	// Throttle tool calls per principal; stdio has a single local caller and no HTTP chain
+	if cfg.MCPRateLimitEnabled && !stdio {
+		if err := metrics.Init(); err != nil || metrics.GetStore() == nil {
+			return fmt.Errorf("failed to open metrics store for rate limiting: %v", err)
+		}
+		limiter, err := NewRateLimiter(cfg, metrics.GetStore())
+		if err != nil {
+			return fmt.Errorf("failed to create rate limiter: %w", err)
+		}
+		server.SetRateLimiter(limiter)
+		logger.Printf("Rate limiting enabled (%d calls/min per tool, burst %d, daily quota %d)", cfg.MCPRateLimitPerMinute, cfg.MCPRateLimitBurst, cfg.MCPDailyQuota)
+	}
+
+	if opts.DashboardHandler != nil && !stdio {
 		basePath := opts.DashboardBasePath
 		if basePath == "" {
 			basePath = "/dashboard"
@@ -537,6 +685,25 @@ func RunMCPServer(ctx context.Context, cmd FlagChecker, opts MCPServerOptions) e
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	if stdio {
+		go func() {
+			select {
+			case <-sigChan:
+				logger.Printf("Received shutdown signal, stopping server...")
+				cancel()
+			case <-runCtx.Done():
+			}
+		}()
+
+		logger.Printf("Starting MCP server (SDK-based) on stdio")
+		logger.Printf("Available tools: %s", strings.Join(registeredTools, ", "))
+		if err := server.ServeStdio(runCtx, os.Stdin, protocolOut); err != nil {
+			return fmt.Errorf("MCP stdio server failed: %w", err)
+		}
+		logger.Printf("MCP server (SDK-based) stopped successfully")
+		return nil
+	}
+
 	go func() {
 		<-sigChan
 		logger.Printf("Received shutdown signal, stopping server...")
@@ -721,6 +888,21 @@ func BuildHybridSearchToolDefinition(base *mcp.Tool, toolName string, defaults *
 	slackToggleProp.Description = "Slack のワークスペース会話を同時に検索する場合は true を指定します。ユーザーが「Slack検索を利用せず」等と明示的に Slack 検索の除外を指示した場合は必ず false にしてください。サーバー側で Slack の資格情報が設定されている必要があります。"
 	slackToggleProp.Default = toRaw(false)
 
+	efSearchProp := ensureProperty("ef_search", "integer")
+	efSearchProp.Title = "HNSW ef_search"
+	efSearchProp.Description = "ベクトル検索の HNSW ef_search を指定します。大きくすると再現率が上がる代わりにレイテンシが増えます。省略時はサーバー設定 (OPENSEARCH_KNN_EF_SEARCH) または k の 2 倍を使用します。"
+	minEfSearch := float64(1)
+	efSearchProp.Minimum = &minEfSearch
+	efSearchProp.Examples = []any{100, 512}
+
+	indexesProp := ensureProperty("indexes", "array")
+	indexesProp.Title = "Indexes / 検索対象インデックス"
+	indexesProp.Description = "複数のインデックス (ワイルドカードパターン可) を横断してフェデレーテッド検索する場合に指定します。`name^2` のように `^` で重みを付けられます。結果には取得元インデックスが付与されます。省略時はサーバーの既定インデックスを使用します。"
+	if indexesProp.Items == nil {
+		indexesProp.Items = &jsonschema.Schema{Type: "string"}
+	}
+	indexesProp.Examples = []any{[]string{"docs", "runbooks^2", "tickets-*^0.5"}}
+
 	schema.Properties["query"] = queryProp
 	schema.Properties["top_k"] = topKProp
 	schema.Properties["filters"] = filtersProp
@@ -732,6 +914,8 @@ func BuildHybridSearchToolDefinition(base *mcp.Tool, toolName string, defaults *
 	schema.Properties["fusion_method"] = fusionMethodProp
 	schema.Properties["use_japanese_nlp"] = nlpProp
 	schema.Properties["enable_slack_search"] = slackToggleProp
+	schema.Properties["ef_search"] = efSearchProp
+	schema.Properties["indexes"] = indexesProp
 
 	schema.Examples = []any{
 		map[string]any{
@@ -754,3 +938,53 @@ func BuildHybridSearchToolDefinition(base *mcp.Tool, toolName string, defaults *
 	toolCopy.InputSchema = schema
 	return &toolCopy
 }
+
+// bypassConfigFromConfig returns the MCP_BYPASS_IP_RANGE settings, or nil when no range is set
+func bypassConfigFromConfig(cfg *appcfg.Config, logger *log.Logger) *BypassIPConfig {
+	if len(cfg.MCPBypassIPRanges) == 0 {
+		return nil
+	}
+
+	// Validate all CIDR formats before proceeding
+	for _, cidr := range cfg.MCPBypassIPRanges {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			// Try parsing as single IP (will be converted to CIDR internally)
+			if ip := net.ParseIP(cidr); ip == nil {
+				logger.Printf("Warning: Invalid CIDR format for bypass IP range: %s", cidr)
+			}
+		}
+	}
+
+	logger.Printf("Bypass IP authentication configured with %d ranges", len(cfg.MCPBypassIPRanges))
+	return &BypassIPConfig{
+		BypassIPRanges: cfg.MCPBypassIPRanges,
+		VerboseLogging: cfg.MCPBypassVerboseLog,
+		AuditLogging:   cfg.MCPBypassAuditLog,
+		TrustedProxies: cfg.MCPTrustedProxies,
+	}
+}
+
+// sessionStoreFromConfig opens the encrypted OIDC session database when MCP_OIDC_SESSION_KEY
+// is set. Without a key sessions live in memory and users sign in again after a restart.
+func sessionStoreFromConfig(cfg *appcfg.Config, logger *log.Logger) (SessionPersistence, error) {
+	if cfg.MCPOIDCSessionKey == "" {
+		logger.Printf("WARNING: MCP_OIDC_SESSION_KEY is not set; OIDC sessions are kept in memory only")
+		return nil, nil
+	}
+	key, err := ParseSessionKey(cfg.MCPOIDCSessionKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MCP_OIDC_SESSION_KEY: %w", err)
+	}
+	dbPath := cfg.MCPOIDCSessionDB
+	if dbPath == "" {
+		if dbPath, err = DefaultSessionDBPath(); err != nil {
+			return nil, err
+		}
+	}
+	store, err := NewSQLiteSessionStore(dbPath, key)
+	if err != nil {
+		return nil, err
+	}
+	logger.Printf("OIDC sessions persisted to %s", dbPath)
+	return store, nil
+}
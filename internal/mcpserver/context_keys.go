@@ -6,4 +6,8 @@ const (
 	userContextKey       contextKey = "user"
 	authMethodContextKey contextKey = "auth_method"
 	clientIPContextKey   contextKey = "client_ip"
+	apiKeyContextKey     contextKey = "api_key"
+
+	// trustedClientIPContextKey holds the client IP derived with MCP_TRUSTED_PROXIES
+	trustedClientIPContextKey contextKey = "trusted_client_ip"
 )
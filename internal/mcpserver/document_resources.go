@@ -0,0 +1,458 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/ca-srg/ragent/internal/pkg/opensearch"
+)
+
+const (
+	// ResourceScheme is the URI scheme of documents exposed as MCP resources
+	ResourceScheme = "ragent"
+
+	documentResourceTemplate = "ragent://doc/{id}{?index}"
+	categoryResourceTemplate = "ragent://category/{name}"
+
+	resourceMIMEType = "application/json"
+
+	resourceScanPageSize     = 100
+	maxDocumentChunks        = 1000
+	maxCategoryResourceItems = 500
+)
+
+// DocumentStore reads stored documents for MCP resources
+type DocumentStore interface {
+	ScanMatchingDocuments(ctx context.Context, index string, match map[string]any, after string, size int) ([]opensearch.ScannedDocument, error)
+}
+
+// DocumentResourceProvider serves indexed documents as ragent:// resources. Reads apply the
+// same secret, tenant and group policies as the hybrid_search tool.
+type DocumentResourceProvider struct {
+	store  DocumentStore
+	config *HybridSearchConfig
+	logger *log.Logger
+}
+
+// DocumentResource is the content of a ragent://doc/{id} resource
+type DocumentResource struct {
+	ID          string                 `json:"id"`
+	URI         string                 `json:"uri"`
+	Title       string                 `json:"title"`
+	Content     string                 `json:"content"`
+	Category    string                 `json:"category,omitempty"`
+	CategoryURI string                 `json:"category_uri,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Author      string                 `json:"author,omitempty"`
+	Reference   string                 `json:"reference,omitempty"`
+	Path        string                 `json:"path,omitempty"`
+	CreatedAt   string                 `json:"created_at,omitempty"`
+	UpdatedAt   string                 `json:"updated_at,omitempty"`
+	ChunkIDs    []string               `json:"chunk_ids,omitempty"` // Set when the content was joined from chunks
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// CategoryResource is the content of a ragent://category/{name} resource
+type CategoryResource struct {
+	Category  string                 `json:"category"`
+	Total     int                    `json:"total"`
+	Truncated bool                   `json:"truncated,omitempty"`
+	Documents []CategoryResourceItem `json:"documents"`
+}
+
+// CategoryResourceItem is a document listed by a category resource
+type CategoryResourceItem struct {
+	ID        string `json:"id"`
+	URI       string `json:"uri"`
+	Title     string `json:"title"`
+	Path      string `json:"path,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// storedDocument is the part of an indexed document that resources read
+type storedDocument struct {
+	Title       string   `json:"title"`
+	Content     string   `json:"content"`
+	Category    string   `json:"category"`
+	Tags        []string `json:"tags"`
+	Author      string   `json:"author"`
+	Reference   string   `json:"reference"`
+	FilePath    string   `json:"file_path"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+	ChunkIndex  *int     `json:"chunk_index"`
+	TotalChunks *int     `json:"total_chunks"`
+}
+
+// NewDocumentResourceProvider creates a provider reading from store with the index and
+// access control of config
+func NewDocumentResourceProvider(store DocumentStore, config *HybridSearchConfig) *DocumentResourceProvider {
+	return &DocumentResourceProvider{
+		store:  store,
+		config: config,
+		logger: log.New(log.Writer(), "[DocumentResources] ", log.LstdFlags),
+	}
+}
+
+// DocumentResourceURI returns the ragent:// URI of a document. index is only set for
+// documents outside the default index, e.g. federated search hits.
+func DocumentResourceURI(id, index string) string {
+	uri := ResourceScheme + "://doc/" + escapeResourceSegment(id)
+	if index != "" {
+		uri += "?index=" + escapeResourceSegment(index)
+	}
+	return uri
+}
+
+// CategoryResourceURI returns the ragent:// URI listing the documents of a category
+func CategoryResourceURI(name string) string {
+	return ResourceScheme + "://category/" + escapeResourceSegment(name)
+}
+
+// escapeResourceSegment percent-encodes everything but RFC 3986 unreserved characters so
+// that the URI matches the {id} and {name} template variables
+func escapeResourceSegment(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// parseResourceURI returns the kind ("doc" or "category"), the decoded name and the
+// optional index of a ragent:// URI
+func parseResourceURI(raw string) (kind, name, index string, err error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid resource URI %q: %w", raw, err)
+	}
+	if u.Scheme != ResourceScheme {
+		return "", "", "", fmt.Errorf("unsupported resource scheme %q", u.Scheme)
+	}
+	name = strings.TrimPrefix(u.Path, "/")
+	if name == "" {
+		return "", "", "", fmt.Errorf("resource URI %q has no name", raw)
+	}
+	return u.Host, name, u.Query().Get("index"), nil
+}
+
+// Templates returns the resource templates served by the provider
+func (p *DocumentResourceProvider) Templates() []*mcp.ResourceTemplate {
+	return []*mcp.ResourceTemplate{
+		{
+			Name:        "document",
+			Title:       "RAGent document",
+			URITemplate: documentResourceTemplate,
+			Description: "Full content and metadata of an indexed document. Chunked documents are returned joined in chunk order. Search hits link to this resource.",
+			MIMEType:    resourceMIMEType,
+		},
+		{
+			Name:        "category",
+			Title:       "RAGent category",
+			URITemplate: categoryResourceTemplate,
+			Description: "Documents of a category with their ragent://doc URIs.",
+			MIMEType:    resourceMIMEType,
+		},
+	}
+}
+
+// HandleReadResource implements mcp.ResourceHandler for both templates
+func (p *DocumentResourceProvider) HandleReadResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	if req == nil || req.Params == nil {
+		return nil, fmt.Errorf("resource request is empty")
+	}
+	uri := req.Params.URI
+	kind, name, index, err := parseResourceURI(uri)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	filter, err := p.accessFilter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("access denied: %w", err)
+	}
+	// Links keep an explicit index so that documents outside the default index stay reachable
+	index, linkIndex, err := p.resolveIndex(index, filter.Tenants)
+	if err != nil {
+		return nil, fmt.Errorf("access denied: %w", err)
+	}
+
+	var payload interface{}
+	switch kind {
+	case "doc":
+		doc, err := p.readDocument(ctx, index, linkIndex, name, filter)
+		if err != nil {
+			p.logger.Printf("Failed to read %s: %v", uri, err)
+			return nil, err
+		}
+		if doc == nil {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		payload = doc
+	case "category":
+		listing, err := p.listCategory(ctx, index, linkIndex, name, filter)
+		if err != nil {
+			p.logger.Printf("Failed to read %s: %v", uri, err)
+			return nil, err
+		}
+		payload = listing
+	default:
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize resource: %w", err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: resourceMIMEType, Text: string(data)}},
+	}, nil
+}
+
+// accessFilter resolves the caller's secret, tenant and group access exactly as the
+// hybrid_search tool does
+func (p *DocumentResourceProvider) accessFilter(ctx context.Context) (opensearch.DocumentFilter, error) {
+	return callerAccessFilter(ctx, p.config)
+}
+
+// callerAccessFilter resolves the caller's access under the tenant and group settings of config
+func callerAccessFilter(ctx context.Context, config *HybridSearchConfig) (opensearch.DocumentFilter, error) {
+	policy := &HybridSearchToolAdapter{defaultConfig: config}
+	request := &HybridSearchRequest{}
+	policy.applySecretPolicyFromContext(ctx, request)
+	if err := policy.applyTenantPolicyFromContext(ctx, request); err != nil {
+		return opensearch.DocumentFilter{}, err
+	}
+	policy.applyGroupPolicyFromContext(ctx, request)
+
+	return opensearch.DocumentFilter{
+		ExcludeSecret:   request.ExcludeSecret,
+		Tenants:         request.Tenants,
+		Groups:          request.Groups,
+		EnforceGroupACL: true,
+	}, nil
+}
+
+func (p *DocumentResourceProvider) defaultIndex(tenants []string) string {
+	indexName := "ragent-docs"
+	if p.config == nil {
+		return indexName
+	}
+	if p.config.DefaultIndexName != "" {
+		indexName = p.config.DefaultIndexName
+	}
+	return unweightedIndexList(p.config.AccessControl.TenantIndexSpec(indexName, tenants))
+}
+
+// resolveIndex returns the index to read and the index kept in links, which is empty for
+// the default index. An explicit index must be one the caller may search with hybrid_search.
+func (p *DocumentResourceProvider) resolveIndex(explicit string, tenants []string) (index, linkIndex string, err error) {
+	explicit = strings.TrimSpace(explicit)
+	if explicit == "" {
+		return p.defaultIndex(tenants), "", nil
+	}
+	if _, err := callerIndexes(p.config, tenants, []string{explicit}); err != nil {
+		return "", "", err
+	}
+	return explicit, explicit, nil
+}
+
+// readDocument returns the document with the given ID, joined with its sibling chunks, or
+// nil when it does not exist or is not visible to the caller
+func (p *DocumentResourceProvider) readDocument(ctx context.Context, index, linkIndex, id string, filter opensearch.DocumentFilter) (*DocumentResource, error) {
+	byID := filter
+	byID.IDs = []string{id}
+	hits, err := p.store.ScanMatchingDocuments(ctx, index, byID.Query(), "", 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(hits) == 0 {
+		return nil, nil
+	}
+
+	hit := hits[0]
+	var stored storedDocument
+	if err := json.Unmarshal(hit.Source, &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode document %s: %w", hit.ID, err)
+	}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(hit.Source, &metadata); err == nil {
+		sanitizeSourceFields(metadata)
+		delete(metadata, "content")
+	}
+
+	doc := &DocumentResource{
+		ID:        hit.ID,
+		URI:       DocumentResourceURI(hit.ID, linkIndex),
+		Title:     stored.Title,
+		Content:   stored.Content,
+		Category:  stored.Category,
+		Tags:      stored.Tags,
+		Author:    stored.Author,
+		Reference: stored.Reference,
+		Path:      stored.FilePath,
+		CreatedAt: stored.CreatedAt,
+		UpdatedAt: stored.UpdatedAt,
+		Metadata:  metadata,
+	}
+	if stored.Category != "" {
+		doc.CategoryURI = CategoryResourceURI(stored.Category)
+	}
+
+	if stored.TotalChunks == nil || *stored.TotalChunks <= 1 || stored.FilePath == "" {
+		return doc, nil
+	}
+
+	byPath := filter
+	byPath.FilePath = stored.FilePath
+	chunks, err := p.scanAll(ctx, index, byPath, maxDocumentChunks)
+	if err != nil {
+		return nil, err
+	}
+	type chunk struct {
+		id      string
+		index   int
+		content string
+	}
+	ordered := make([]chunk, 0, len(chunks))
+	for _, c := range chunks {
+		var source storedDocument
+		if err := json.Unmarshal(c.Source, &source); err != nil {
+			continue
+		}
+		position := 0
+		if source.ChunkIndex != nil {
+			position = *source.ChunkIndex
+		}
+		ordered = append(ordered, chunk{id: c.ID, index: position, content: source.Content})
+	}
+	if len(ordered) <= 1 {
+		return doc, nil
+	}
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].index < ordered[j].index })
+
+	contents := make([]string, len(ordered))
+	doc.ChunkIDs = make([]string, len(ordered))
+	for i, c := range ordered {
+		contents[i] = c.content
+		doc.ChunkIDs[i] = c.id
+	}
+	doc.Content = strings.Join(contents, "\n\n")
+	return doc, nil
+}
+
+// readDocumentByPath is readDocument for the document stored under a file path
+func (p *DocumentResourceProvider) readDocumentByPath(ctx context.Context, index, linkIndex, path string, filter opensearch.DocumentFilter) (*DocumentResource, error) {
+	byPath := filter
+	byPath.FilePath = path
+	hits, err := p.store.ScanMatchingDocuments(ctx, index, byPath.Query(), "", 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(hits) == 0 {
+		return nil, nil
+	}
+	return p.readDocument(ctx, index, linkIndex, hits[0].ID, filter)
+}
+
+// listCategory lists the documents of a category, one entry per file for chunked documents
+func (p *DocumentResourceProvider) listCategory(ctx context.Context, index, linkIndex, category string, filter opensearch.DocumentFilter) (*CategoryResource, error) {
+	byCategory := filter
+	byCategory.Category = category
+	hits, err := p.scanAll(ctx, index, byCategory, maxCategoryResourceItems+1)
+	if err != nil {
+		return nil, err
+	}
+
+	listing := &CategoryResource{Category: category, Documents: []CategoryResourceItem{}}
+	if len(hits) > maxCategoryResourceItems {
+		hits = hits[:maxCategoryResourceItems]
+		listing.Truncated = true
+	}
+
+	seenPaths := make(map[string]int)
+	for _, hit := range hits {
+		var stored storedDocument
+		if err := json.Unmarshal(hit.Source, &stored); err != nil {
+			continue
+		}
+		item := CategoryResourceItem{
+			ID:        hit.ID,
+			URI:       DocumentResourceURI(hit.ID, linkIndex),
+			Title:     stored.Title,
+			Path:      stored.FilePath,
+			UpdatedAt: stored.UpdatedAt,
+		}
+		if stored.FilePath != "" {
+			if pos, ok := seenPaths[stored.FilePath]; ok {
+				// Keep the first chunk of a document as its entry
+				if stored.ChunkIndex != nil && *stored.ChunkIndex == 0 {
+					listing.Documents[pos] = item
+				}
+				continue
+			}
+			seenPaths[stored.FilePath] = len(listing.Documents)
+		}
+		listing.Documents = append(listing.Documents, item)
+	}
+	listing.Total = len(listing.Documents)
+	return listing, nil
+}
+
+// scanAll pages through the documents matching filter, stopping after limit documents
+func (p *DocumentResourceProvider) scanAll(ctx context.Context, index string, filter opensearch.DocumentFilter, limit int) ([]opensearch.ScannedDocument, error) {
+	query := filter.Query()
+	var all []opensearch.ScannedDocument
+	after := ""
+	for len(all) < limit {
+		page, err := p.store.ScanMatchingDocuments(ctx, index, query, after, resourceScanPageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < resourceScanPageSize {
+			break
+		}
+		after = page[len(page)-1].ID
+	}
+	if len(all) > limit {
+		all = all[:limit]
+	}
+	return all, nil
+}
+
+// appendResourceLinks adds a resource_link content item per search hit so clients can open
+// the full document instead of re-searching for fragments
+func appendResourceLinks(result *MCPToolCallResult, items []HybridSearchResultItem) {
+	if result == nil || result.IsError {
+		return
+	}
+	for _, item := range items {
+		if item.ResourceURI == "" {
+			continue
+		}
+		name := item.Title
+		if name == "" {
+			name = item.ID
+		}
+		result.Content = append(result.Content, MCPContent{
+			Type:     "resource_link",
+			URI:      item.ResourceURI,
+			Name:     name,
+			MIMEType: resourceMIMEType,
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	"github.com/ca-srg/ragent/internal/pkg/opensearch"
+)
+
+type fakeDocumentStore struct {
+	docs    []opensearch.ScannedDocument
+	indexes []string
+	queries []map[string]any
+}
+
+func (s *fakeDocumentStore) ScanMatchingDocuments(ctx context.Context, index string, match map[string]any, after string, size int) ([]opensearch.ScannedDocument, error) {
+	s.indexes = append(s.indexes, index)
+	s.queries = append(s.queries, match)
+
+	data, _ := json.Marshal(match)
+	query := string(data)
+	var hits []opensearch.ScannedDocument
+	for _, doc := range s.docs {
+		var source map[string]interface{}
+		_ = json.Unmarshal(doc.Source, &source)
+		switch {
+		case strings.Contains(query, `"ids"`):
+			if !strings.Contains(query, `"`+doc.ID+`"`) {
+				continue
+			}
+		case strings.Contains(query, `"file_path"`):
+			if path, _ := source["file_path"].(string); !strings.Contains(query, `"file_path":"`+path+`"`) {
+				continue
+			}
+		case strings.Contains(query, `"category"`):
+			if category, _ := source["category"].(string); !strings.Contains(query, `"category":"`+category+`"`) {
+				continue
+			}
+		}
+		hits = append(hits, doc)
+		if len(hits) == size {
+			break
+		}
+	}
+	return hits, nil
+}
+
+func storedDoc(t *testing.T, id string, source map[string]interface{}) opensearch.ScannedDocument {
+	t.Helper()
+	data, err := json.Marshal(source)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	return opensearch.ScannedDocument{ID: id, Source: data}
+}
+
+func readResourceText(t *testing.T, provider *DocumentResourceProvider, ctx context.Context, uri string) string {
+	t.Helper()
+	result, err := provider.HandleReadResource(ctx, &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: uri}})
+	if err != nil {
+		t.Fatalf("read %s failed: %v", uri, err)
+	}
+	if len(result.Contents) != 1 {
+		t.Fatalf("expected one content item, got %d", len(result.Contents))
+	}
+	return result.Contents[0].Text
+}
+
+func TestDocumentResourceJoinsChunksInOrder(t *testing.T) {
+	store := &fakeDocumentStore{docs: []opensearch.ScannedDocument{
+		storedDoc(t, "guide_1", map[string]interface{}{"title": "Guide", "content": "second", "file_path": "docs/guide.md", "category": "Runbook", "chunk_index": 1, "total_chunks": 2, "embedding": []float64{0.1}}),
+		storedDoc(t, "guide_0", map[string]interface{}{"title": "Guide", "content": "first", "file_path": "docs/guide.md", "category": "Runbook", "chunk_index": 0, "total_chunks": 2}),
+	}}
+	provider := NewDocumentResourceProvider(store, &HybridSearchConfig{DefaultIndexName: "docs-index"})
+
+	text := readResourceText(t, provider, context.Background(), DocumentResourceURI("guide_1", ""))
+
+	var doc DocumentResource
+	if err := json.Unmarshal([]byte(text), &doc); err != nil {
+		t.Fatalf("invalid resource JSON: %v", err)
+	}
+	if doc.Content != "first\n\nsecond" {
+		t.Fatalf("expected chunks joined in order, got %q", doc.Content)
+	}
+	if len(doc.ChunkIDs) != 2 || doc.ChunkIDs[0] != "guide_0" {
+		t.Fatalf("unexpected chunk ids: %v", doc.ChunkIDs)
+	}
+	if doc.CategoryURI != "ragent://category/Runbook" {
+		t.Fatalf("unexpected category uri: %s", doc.CategoryURI)
+	}
+	if _, ok := doc.Metadata["embedding"]; ok {
+		t.Fatalf("embedding must not be exposed in resource metadata")
+	}
+	if store.indexes[0] != "docs-index" {
+		t.Fatalf("expected default index, got %s", store.indexes[0])
+	}
+}
+
+func TestDocumentResourceAppliesSecretPolicy(t *testing.T) {
+	store := &fakeDocumentStore{}
+	provider := NewDocumentResourceProvider(store, &HybridSearchConfig{DefaultIndexName: "docs-index"})
+	uri := DocumentResourceURI("missing", "")
+
+	_, err := provider.HandleReadResource(context.Background(), &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: uri}})
+	if err == nil {
+		t.Fatalf("expected not found error")
+	}
+	data, _ := json.Marshal(store.queries[0])
+	if !strings.Contains(string(data), `"must_not":[{"term":{"secret":true}}]`) {
+		t.Fatalf("anonymous reads must exclude secret documents: %s", data)
+	}
+	if !strings.Contains(string(data), `"allowed_groups"`) {
+		t.Fatalf("reads must enforce the group ACL: %s", data)
+	}
+
+	store.queries = nil
+	ctx := context.WithValue(context.Background(), userContextKey, &TokenInfo{Subject: "alice"})
+	_, _ = provider.HandleReadResource(ctx, &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: uri}})
+	data, _ = json.Marshal(store.queries[0])
+	if strings.Contains(string(data), `"secret"`) {
+		t.Fatalf("authenticated reads must include secret documents: %s", data)
+	}
+}
+
+func TestDocumentResourceNotFound(t *testing.T) {
+	provider := NewDocumentResourceProvider(&fakeDocumentStore{}, nil)
+
+	_, err := provider.HandleReadResource(context.Background(), &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: "ragent://doc/none"}})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected resource not found error, got %v", err)
+	}
+}
+
+func TestCategoryResourceListsOneEntryPerDocument(t *testing.T) {
+	store := &fakeDocumentStore{docs: []opensearch.ScannedDocument{
+		storedDoc(t, "a_1", map[string]interface{}{"title": "A", "file_path": "a.md", "category": "Runbook", "chunk_index": 1}),
+		storedDoc(t, "a_0", map[string]interface{}{"title": "A", "file_path": "a.md", "category": "Runbook", "chunk_index": 0}),
+		storedDoc(t, "b", map[string]interface{}{"title": "B", "file_path": "b.md", "category": "Runbook"}),
+		storedDoc(t, "c", map[string]interface{}{"title": "C", "file_path": "c.md", "category": "Other"}),
+	}}
+	provider := NewDocumentResourceProvider(store, &HybridSearchConfig{DefaultIndexName: "docs-index"})
+
+	text := readResourceText(t, provider, context.Background(), CategoryResourceURI("Runbook"))
+
+	var listing CategoryResource
+	if err := json.Unmarshal([]byte(text), &listing); err != nil {
+		t.Fatalf("invalid resource JSON: %v", err)
+	}
+	if listing.Total != 2 || listing.Documents[0].ID != "a_0" || listing.Documents[1].ID != "b" {
+		t.Fatalf("unexpected listing: %+v", listing)
+	}
+	if listing.Documents[1].URI != "ragent://doc/b" {
+		t.Fatalf("unexpected document uri: %s", listing.Documents[1].URI)
+	}
+}
+
+func TestResourceURIRoundTrip(t *testing.T) {
+	uri := DocumentResourceURI("docs/手順 1.md", "team-a")
+	if uri != "ragent://doc/docs%2F%E6%89%8B%E9%A0%86%201.md?index=team-a" {
+		t.Fatalf("unexpected uri: %s", uri)
+	}
+	kind, name, index, err := parseResourceURI(uri)
+	if err != nil || kind != "doc" || name != "docs/手順 1.md" || index != "team-a" {
+		t.Fatalf("unexpected parse result: %s %s %s %v", kind, name, index, err)
+	}
+}
+
+func TestAppendResourceLinks(t *testing.T) {
+	result := CreateToolCallResult("{}")
+	appendResourceLinks(result, []HybridSearchResultItem{
+		{ID: "doc-1", Title: "Guide", ResourceURI: DocumentResourceURI("doc-1", "")},
+		{ID: "doc-2"},
+	})
+	if len(result.Content) != 2 {
+		t.Fatalf("expected text plus one link, got %d items", len(result.Content))
+	}
+	link := result.Content[1]
+	if link.Type != "resource_link" || link.URI != "ragent://doc/doc-1" || link.Name != "Guide" {
+		t.Fatalf("unexpected link: %+v", link)
+	}
+
+	sdkResult := convertRAGentResultToSDK(result)
+	if _, ok := sdkResult.Content[1].(*mcp.ResourceLink); !ok {
+		t.Fatalf("expected SDK resource link, got %T", sdkResult.Content[1])
+	}
+}
+
+func TestDocumentResourceRestrictsExplicitIndex(t *testing.T) {
+	store := &fakeDocumentStore{}
+	provider := NewDocumentResourceProvider(store, &HybridSearchConfig{
+		DefaultIndexName: "docs-index",
+		AccessControl:    &appconfig.Config{MCPFederatedIndexes: []string{"shared-docs"}},
+	})
+
+	for _, index := range []string{"other-index", "*", "docs-index,other-index"} {
+		_, err := provider.HandleReadResource(context.Background(), &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: DocumentResourceURI("doc-1", index)}})
+		if err == nil || !strings.Contains(err.Error(), "access denied") {
+			t.Fatalf("expected access denied for index %q, got %v", index, err)
+		}
+	}
+	if len(store.indexes) != 0 {
+		t.Fatalf("rejected indexes must not be read: %v", store.indexes)
+	}
+
+	for _, index := range []string{"shared-docs", "docs-index"} {
+		_, _ = provider.HandleReadResource(context.Background(), &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: DocumentResourceURI("doc-1", index)}})
+	}
+	if len(store.indexes) != 2 || store.indexes[0] != "shared-docs" || store.indexes[1] != "docs-index" {
+		t.Fatalf("expected the allowed indexes to be read, got %v", store.indexes)
+	}
+}
@@ -0,0 +1,563 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/ca-srg/ragent/internal/pkg/opensearch"
+)
+
+// Base names of the document tools; MCP_TOOL_PREFIX is prepended like for hybrid_search
+const (
+	GetDocumentToolName    = "get_document"
+	FindSimilarToolName    = "find_similar"
+	ListCategoriesToolName = "list_categories"
+	ListTagsToolName       = "list_tags"
+	IndexStatsToolName     = "index_stats"
+)
+
+const (
+	maxSimilarResults     = 50
+	defaultTermListLimit  = 100
+	maxTermListLimit      = 500
+	similarSnippetLength  = 300
+	similarCandidateRatio = 4
+)
+
+// DocumentToolBackend is the OpenSearch access used by the document tools
+type DocumentToolBackend interface {
+	DocumentStore
+	SearchDenseVector(ctx context.Context, indexName string, query *opensearch.VectorQuery) (*opensearch.VectorSearchResponse, error)
+	AggregateTerms(ctx context.Context, index, field string, match map[string]any, size int) ([]opensearch.TermCount, error)
+	SummarizeDocuments(ctx context.Context, index string, match map[string]any) (*opensearch.DocumentSummary, error)
+}
+
+// ToolDefinition pairs an SDK tool with its handler for registration
+type ToolDefinition struct {
+	Tool    *mcp.Tool
+	Handler mcp.ToolHandler
+}
+
+// DocumentTools serves the read-only document tools next to hybrid_search. Every tool
+// applies the caller's secret, tenant and group access like hybrid_search does.
+type DocumentTools struct {
+	backend   DocumentToolBackend
+	documents *DocumentResourceProvider
+	config    *HybridSearchConfig
+	logger    *log.Logger
+}
+
+// TermListResponse is the result of list_categories and list_tags
+type TermListResponse struct {
+	Field  string         `json:"field"`
+	Index  string         `json:"index"`
+	Total  int            `json:"total"`
+	Values []TermListItem `json:"values"`
+}
+
+// TermListItem is a category or tag with its counts
+type TermListItem struct {
+	Name      string `json:"name"`
+	Documents int    `json:"documents"`
+	Chunks    int    `json:"chunks"`
+	URI       string `json:"uri,omitempty"` // ragent://category URI for categories
+}
+
+// IndexStatsResponse is the result of index_stats
+type IndexStatsResponse struct {
+	Index string `json:"index"`
+	opensearch.DocumentSummary
+}
+
+// FindSimilarResponse is the result of find_similar
+type FindSimilarResponse struct {
+	Source  HybridSearchResultItem   `json:"source"`
+	Results []HybridSearchResultItem `json:"results"`
+	Total   int                      `json:"total"`
+}
+
+type documentRefArgs struct {
+	ID       string `json:"id"`
+	FilePath string `json:"file_path"`
+	Index    string `json:"index"`
+}
+
+type findSimilarArgs struct {
+	documentRefArgs
+	TopK     int    `json:"top_k"`
+	Category string `json:"category"`
+}
+
+type termListArgs struct {
+	Limit    int    `json:"limit"`
+	Category string `json:"category"`
+	Index    string `json:"index"`
+}
+
+type indexStatsArgs struct {
+	Index string `json:"index"`
+}
+
+// NewDocumentTools creates the document tools reading from backend with the index and
+// access control of config
+func NewDocumentTools(backend DocumentToolBackend, config *HybridSearchConfig) *DocumentTools {
+	return &DocumentTools{
+		backend:   backend,
+		documents: NewDocumentResourceProvider(backend, config),
+		config:    config,
+		logger:    log.New(log.Writer(), "[DocumentTools] ", log.LstdFlags),
+	}
+}
+
+// Definitions returns the tools with prefix prepended to their names
+func (dt *DocumentTools) Definitions(prefix string) []ToolDefinition {
+	definitions := []ToolDefinition{
+		{
+			Tool: &mcp.Tool{
+				Name:        prefix + GetDocumentToolName,
+				Description: "文書ID・ragent://doc URI・ファイルパスのいずれかで文書全体を取得します。チャンク分割された文書はチャンク順に結合して返します。\n\nEnglish: Fetch a full indexed document by ID, ragent://doc URI or file path. Chunked documents are reassembled in chunk order. Use it after hybrid_search to read a hit in full.",
+				InputSchema: objectSchema("Get Document Parameters", documentRefProperties()),
+			},
+			Handler: dt.instrument("get_document", dt.getDocument),
+		},
+		{
+			Tool: &mcp.Tool{
+				Name:        prefix + FindSimilarToolName,
+				Description: fmt.Sprintf("指定した文書の保存済み埋め込みベクトルで類似文書を検索します（テキストクエリ不要）。\n\nEnglish: More-like-this search. Uses the stored embedding of a document (by ID, ragent://doc URI or file path) to find up to %d similar documents, excluding the source document itself.", maxSimilarResults),
+				InputSchema: objectSchema("Find Similar Parameters", mergeProperties(documentRefProperties(), map[string]*jsonschema.Schema{
+					"top_k":    integerProperty("Number of similar documents to return", 1, maxSimilarResults, dt.defaultTopK()),
+					"category": {Type: "string", Description: "Only return documents of this category (exact match)"},
+				})),
+			},
+			Handler: dt.instrument("find_similar", dt.findSimilar),
+		},
+		{
+			Tool: &mcp.Tool{
+				Name:        prefix + ListCategoriesToolName,
+				Description: "インデックス内のカテゴリ一覧を文書数付きで返します。\n\nEnglish: List the categories of the knowledge base with document and chunk counts, most used first. Each category links to its ragent://category resource.",
+				InputSchema: objectSchema("List Categories Parameters", map[string]*jsonschema.Schema{
+					"limit": integerProperty("Maximum number of categories to return", 1, maxTermListLimit, defaultTermListLimit),
+					"index": indexProperty(),
+				}),
+			},
+			Handler: dt.instrument("list_categories", dt.listTerms("category")),
+		},
+		{
+			Tool: &mcp.Tool{
+				Name:        prefix + ListTagsToolName,
+				Description: "インデックス内のタグ一覧を文書数付きで返します。\n\nEnglish: List the tags of the knowledge base with document and chunk counts, most used first. Optionally restricted to one category.",
+				InputSchema: objectSchema("List Tags Parameters", map[string]*jsonschema.Schema{
+					"limit":    integerProperty("Maximum number of tags to return", 1, maxTermListLimit, defaultTermListLimit),
+					"category": {Type: "string", Description: "Only count tags of documents in this category"},
+					"index":    indexProperty(),
+				}),
+			},
+			Handler: dt.instrument("list_tags", dt.listTerms("tags")),
+		},
+		{
+			Tool: &mcp.Tool{
+				Name:        prefix + IndexStatsToolName,
+				Description: "インデックスの文書数・チャンク数・カテゴリ数・最終更新日時を返します。\n\nEnglish: Summarize the index visible to the caller: chunk, document, category and tag counts and the latest update and indexing times.",
+				InputSchema: objectSchema("Index Stats Parameters", map[string]*jsonschema.Schema{
+					"index": indexProperty(),
+				}),
+			},
+			Handler: dt.instrument("index_stats", dt.indexStats),
+		},
+	}
+
+	for _, definition := range definitions {
+		markToolReadOnly(definition.Tool, definition.Tool.Name)
+	}
+	return definitions
+}
+
+// instrument wraps a tool implementation with tracing, metrics and error results
+func (dt *DocumentTools) instrument(spanName string, fn func(ctx context.Context, arguments json.RawMessage) (*MCPToolCallResult, error)) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, span := mcpTracer.Start(ctx, "mcpserver."+spanName)
+		defer span.End()
+
+		metricAttrs := []attribute.KeyValue{attribute.String("mcp.tool.name", spanName)}
+		if req != nil && req.Params != nil && req.Params.Name != "" {
+			metricAttrs[0] = attribute.String("mcp.tool.name", req.Params.Name)
+		}
+		span.SetAttributes(metricAttrs...)
+		if method := getAuthMethodFromContext(ctx); method != "" {
+			metricAttrs = append(metricAttrs, attribute.String("mcp.auth.method", method))
+		}
+		start := time.Now()
+		errType := ""
+		defer func() {
+			recordMCPMetrics(ctx, metricAttrs, time.Since(start), errType)
+		}()
+
+		var arguments json.RawMessage
+		if req != nil && req.Params != nil {
+			arguments = req.Params.Arguments
+		}
+		result, err := fn(ctx, arguments)
+		if err != nil {
+			errType = "tool_call_failed"
+			span.RecordError(err)
+			span.SetStatus(codes.Error, errType)
+			dt.logger.Printf("%s failed: %v", spanName, err)
+			return convertRAGentResultToSDK(CreateToolCallErrorResult(err.Error())), nil
+		}
+		return convertRAGentResultToSDK(result), nil
+	}
+}
+
+func (dt *DocumentTools) getDocument(ctx context.Context, arguments json.RawMessage) (*MCPToolCallResult, error) {
+	var args documentRefArgs
+	if err := decodeToolArguments(arguments, &args); err != nil {
+		return nil, err
+	}
+	id, path, linkIndex, err := args.resolve()
+	if err != nil {
+		return nil, err
+	}
+	filter, index, err := dt.scope(ctx, linkIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc *DocumentResource
+	if id != "" {
+		doc, err = dt.documents.readDocument(ctx, index, linkIndex, id, filter)
+	} else {
+		doc, err = dt.documents.readDocumentByPath(ctx, index, linkIndex, path, filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document: %w", err)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("document not found")
+	}
+
+	result, err := jsonToolResult(doc)
+	if err != nil {
+		return nil, err
+	}
+	appendResourceLinks(result, []HybridSearchResultItem{{ID: doc.ID, Title: doc.Title, ResourceURI: doc.URI}})
+	return result, nil
+}
+
+func (dt *DocumentTools) findSimilar(ctx context.Context, arguments json.RawMessage) (*MCPToolCallResult, error) {
+	var args findSimilarArgs
+	if err := decodeToolArguments(arguments, &args); err != nil {
+		return nil, err
+	}
+	id, path, linkIndex, err := args.resolve()
+	if err != nil {
+		return nil, err
+	}
+	topK := args.TopK
+	if topK == 0 {
+		topK = dt.defaultTopK()
+	}
+	if topK < 1 || topK > maxSimilarResults {
+		return nil, fmt.Errorf("top_k must be between 1 and %d", maxSimilarResults)
+	}
+	filter, index, err := dt.scope(ctx, linkIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := filter
+	if id != "" {
+		lookup.IDs = []string{id}
+	} else {
+		lookup.FilePath = path
+	}
+	hits, err := dt.backend.ScanMatchingDocuments(ctx, index, lookup.Query(), "", maxDocumentChunks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source document: %w", err)
+	}
+	source, embedding := similaritySource(hits)
+	if source == nil {
+		return nil, fmt.Errorf("document not found")
+	}
+	if len(embedding) == 0 {
+		return nil, fmt.Errorf("document %s has no stored embedding", source.ID)
+	}
+
+	query := &opensearch.VectorQuery{
+		Vector:          embedding,
+		K:               topK * similarCandidateRatio,
+		Size:            topK * similarCandidateRatio,
+		ExcludeSecret:   filter.ExcludeSecret,
+		Tenants:         filter.Tenants,
+		Groups:          filter.Groups,
+		EnforceGroupACL: filter.EnforceGroupACL,
+	}
+	if dt.config != nil {
+		query.EfSearch = dt.config.DefaultEfSearch
+	}
+	if category := strings.TrimSpace(args.Category); category != "" {
+		query.Filters = map[string]string{"category": category}
+	}
+	response, err := dt.backend.SearchDenseVector(ctx, index, query)
+	if err != nil {
+		return nil, fmt.Errorf("similarity search failed: %w", err)
+	}
+
+	sourceItem := similarResultItem(source.ID, source.Source, 0, linkIndex)
+	similar := &FindSimilarResponse{Source: sourceItem, Results: []HybridSearchResultItem{}}
+	seenPaths := map[string]bool{}
+	if sourceItem.Path != "" {
+		seenPaths[sourceItem.Path] = true
+	}
+	if response != nil {
+		for _, hit := range response.Hits.Hits {
+			if hit.ID == source.ID {
+				continue
+			}
+			// Hits outside the default index keep their index in the resource URI
+			hitIndex := linkIndex
+			if hitIndex != "" {
+				hitIndex = hit.Index
+			}
+			item := similarResultItem(hit.ID, hit.Source, hit.Score, hitIndex)
+			// One entry per document; hits come ordered by score
+			if item.Path != "" {
+				if seenPaths[item.Path] {
+					continue
+				}
+				seenPaths[item.Path] = true
+			}
+			similar.Results = append(similar.Results, item)
+			if len(similar.Results) == topK {
+				break
+			}
+		}
+	}
+	similar.Total = len(similar.Results)
+
+	result, err := jsonToolResult(similar)
+	if err != nil {
+		return nil, err
+	}
+	appendResourceLinks(result, similar.Results)
+	return result, nil
+}
+
+func (dt *DocumentTools) listTerms(field string) func(ctx context.Context, arguments json.RawMessage) (*MCPToolCallResult, error) {
+	return func(ctx context.Context, arguments json.RawMessage) (*MCPToolCallResult, error) {
+		var args termListArgs
+		if err := decodeToolArguments(arguments, &args); err != nil {
+			return nil, err
+		}
+		limit := args.Limit
+		if limit == 0 {
+			limit = defaultTermListLimit
+		}
+		if limit < 1 || limit > maxTermListLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", maxTermListLimit)
+		}
+		filter, index, err := dt.scope(ctx, args.Index)
+		if err != nil {
+			return nil, err
+		}
+		if field != "category" {
+			filter.Category = strings.TrimSpace(args.Category)
+		}
+
+		counts, err := dt.backend.AggregateTerms(ctx, index, field, filter.Query(), limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate %s: %w", field, err)
+		}
+
+		response := &TermListResponse{Field: field, Index: index, Values: make([]TermListItem, 0, len(counts))}
+		for _, count := range counts {
+			item := TermListItem{Name: count.Value, Documents: count.Documents, Chunks: count.Chunks}
+			if field == "category" {
+				item.URI = CategoryResourceURI(count.Value)
+			}
+			response.Values = append(response.Values, item)
+		}
+		response.Total = len(response.Values)
+		return jsonToolResult(response)
+	}
+}
+
+func (dt *DocumentTools) indexStats(ctx context.Context, arguments json.RawMessage) (*MCPToolCallResult, error) {
+	var args indexStatsArgs
+	if err := decodeToolArguments(arguments, &args); err != nil {
+		return nil, err
+	}
+	filter, index, err := dt.scope(ctx, args.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := dt.backend.SummarizeDocuments(ctx, index, filter.Query())
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize index: %w", err)
+	}
+	return jsonToolResult(&IndexStatsResponse{Index: index, DocumentSummary: *summary})
+}
+
+// scope resolves the caller's access filter and the index to read. An explicit index
+// replaces the default (tenant) index when the caller may search it, and the access
+// filter still applies.
+func (dt *DocumentTools) scope(ctx context.Context, explicitIndex string) (opensearch.DocumentFilter, string, error) {
+	filter, err := dt.documents.accessFilter(ctx)
+	if err != nil {
+		return opensearch.DocumentFilter{}, "", fmt.Errorf("access denied: %w", err)
+	}
+	index, _, err := dt.documents.resolveIndex(explicitIndex, filter.Tenants)
+	if err != nil {
+		return opensearch.DocumentFilter{}, "", fmt.Errorf("access denied: %w", err)
+	}
+	return filter, index, nil
+}
+
+func (dt *DocumentTools) defaultTopK() int {
+	if dt.config != nil && dt.config.DefaultSize > 0 && dt.config.DefaultSize <= maxSimilarResults {
+		return dt.config.DefaultSize
+	}
+	return 10
+}
+
+// resolve returns either the document ID or the file path, and the optional index. A
+// ragent://doc URI in id supplies both the ID and its index.
+func (a documentRefArgs) resolve() (id, path, index string, err error) {
+	id = strings.TrimSpace(a.ID)
+	path = strings.TrimSpace(a.FilePath)
+	index = strings.TrimSpace(a.Index)
+	if (id == "") == (path == "") {
+		return "", "", "", fmt.Errorf("exactly one of id or file_path is required")
+	}
+	if strings.HasPrefix(id, ResourceScheme+"://") {
+		kind, name, uriIndex, perr := parseResourceURI(id)
+		if perr != nil || kind != "doc" {
+			return "", "", "", fmt.Errorf("%s is not a ragent://doc URI", id)
+		}
+		id = name
+		if index == "" {
+			index = uriIndex
+		}
+	}
+	return id, path, index, nil
+}
+
+// similaritySource picks the chunk whose embedding represents the document: the requested
+// chunk for an ID lookup, otherwise the first chunk of the file
+func similaritySource(hits []opensearch.ScannedDocument) (*opensearch.ScannedDocument, []float64) {
+	var (
+		best      *opensearch.ScannedDocument
+		bestIndex int
+		embedding []float64
+	)
+	for i := range hits {
+		var source struct {
+			ChunkIndex *int      `json:"chunk_index"`
+			Embedding  []float64 `json:"embedding"`
+		}
+		if err := json.Unmarshal(hits[i].Source, &source); err != nil {
+			continue
+		}
+		position := 0
+		if source.ChunkIndex != nil {
+			position = *source.ChunkIndex
+		}
+		if best == nil || position < bestIndex {
+			best, bestIndex, embedding = &hits[i], position, source.Embedding
+		}
+	}
+	return best, embedding
+}
+
+func similarResultItem(id string, raw json.RawMessage, score float64, index string) HybridSearchResultItem {
+	var stored storedDocument
+	_ = json.Unmarshal(raw, &stored)
+	content := []rune(stored.Content)
+	if len(content) > similarSnippetLength {
+		content = append(content[:similarSnippetLength], '…')
+	}
+	return HybridSearchResultItem{
+		ID:          id,
+		ResourceURI: DocumentResourceURI(id, index),
+		Title:       stored.Title,
+		Content:     string(content),
+		Score:       score,
+		Source:      "opensearch",
+		Path:        stored.FilePath,
+		Index:       index,
+		Category:    stored.Category,
+		Author:      stored.Author,
+		Tags:        stored.Tags,
+		CreatedAt:   stored.CreatedAt,
+		UpdatedAt:   stored.UpdatedAt,
+	}
+}
+
+func decodeToolArguments(arguments json.RawMessage, target interface{}) error {
+	if len(arguments) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(arguments, target); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+func jsonToolResult(payload interface{}) (*MCPToolCallResult, error) {
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize response: %w", err)
+	}
+	return CreateToolCallResult(string(data)), nil
+}
+
+func objectSchema(title string, properties map[string]*jsonschema.Schema) *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:       "object",
+		Title:      title,
+		Properties: properties,
+	}
+}
+
+func documentRefProperties() map[string]*jsonschema.Schema {
+	return map[string]*jsonschema.Schema{
+		"id":        {Type: "string", Description: "Document ID or ragent://doc URI, e.g. the id or resource_uri of a hybrid_search hit"},
+		"file_path": {Type: "string", Description: "File path of the document, as an alternative to id"},
+		"index":     indexProperty(),
+	}
+}
+
+func indexProperty() *jsonschema.Schema {
+	return &jsonschema.Schema{Type: "string", Description: "Index to read instead of the default index: one of the caller's tenant indexes or an MCP_FEDERATED_INDEXES entry"}
+}
+
+func integerProperty(description string, minimum, maximum, defaultValue int) *jsonschema.Schema {
+	lower, upper := float64(minimum), float64(maximum)
+	defaultJSON, _ := json.Marshal(defaultValue)
+	return &jsonschema.Schema{
+		Type:        "integer",
+		Description: description,
+		Minimum:     &lower,
+		Maximum:     &upper,
+		Default:     defaultJSON,
+	}
+}
+
+func mergeProperties(sets ...map[string]*jsonschema.Schema) map[string]*jsonschema.Schema {
+	merged := make(map[string]*jsonschema.Schema)
+	for _, set := range sets {
+		for key, schema := range set {
+			merged[key] = schema
+		}
+	}
+	return merged
+}
@@ -0,0 +1,260 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	"github.com/ca-srg/ragent/internal/pkg/opensearch"
+)
+
+type fakeDocumentToolBackend struct {
+	fakeDocumentStore
+	vectorQueries []*opensearch.VectorQuery
+	vectorHits    []opensearch.VectorSearchResult
+	aggFields     []string
+	aggQueries    []map[string]any
+	termCounts    []opensearch.TermCount
+	summary       *opensearch.DocumentSummary
+}
+
+func (b *fakeDocumentToolBackend) SearchDenseVector(ctx context.Context, indexName string, query *opensearch.VectorQuery) (*opensearch.VectorSearchResponse, error) {
+	b.vectorQueries = append(b.vectorQueries, query)
+	response := &opensearch.VectorSearchResponse{}
+	response.Hits.Hits = b.vectorHits
+	return response, nil
+}
+
+func (b *fakeDocumentToolBackend) AggregateTerms(ctx context.Context, index, field string, match map[string]any, size int) ([]opensearch.TermCount, error) {
+	b.aggFields = append(b.aggFields, field)
+	b.aggQueries = append(b.aggQueries, match)
+	return b.termCounts, nil
+}
+
+func (b *fakeDocumentToolBackend) SummarizeDocuments(ctx context.Context, index string, match map[string]any) (*opensearch.DocumentSummary, error) {
+	b.aggQueries = append(b.aggQueries, match)
+	return b.summary, nil
+}
+
+func callDocumentTool(t *testing.T, tools *DocumentTools, name string, args map[string]interface{}) *mcp.CallToolResult {
+	t.Helper()
+	for _, definition := range tools.Definitions("") {
+		if definition.Tool.Name != name {
+			continue
+		}
+		data, _ := json.Marshal(args)
+		result, err := definition.Handler(context.Background(), &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: name, Arguments: data}})
+		if err != nil {
+			t.Fatalf("%s returned error: %v", name, err)
+		}
+		return result
+	}
+	t.Fatalf("tool %s not defined", name)
+	return nil
+}
+
+func toolResultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	if len(result.Content) == 0 {
+		t.Fatalf("empty tool result")
+	}
+	text, ok := result.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatalf("expected text content, got %T", result.Content[0])
+	}
+	return text.Text
+}
+
+func TestDocumentToolDefinitionsArePrefixedAndReadOnly(t *testing.T) {
+	tools := NewDocumentTools(&fakeDocumentToolBackend{}, &HybridSearchConfig{DefaultIndexName: "docs-index"})
+
+	definitions := tools.Definitions("kb_")
+	if len(definitions) != 5 {
+		t.Fatalf("expected 5 tools, got %d", len(definitions))
+	}
+	for _, definition := range definitions {
+		if !strings.HasPrefix(definition.Tool.Name, "kb_") {
+			t.Fatalf("tool name %s is not prefixed", definition.Tool.Name)
+		}
+		if definition.Tool.Annotations == nil || !definition.Tool.Annotations.ReadOnlyHint {
+			t.Fatalf("tool %s must be annotated read-only", definition.Tool.Name)
+		}
+	}
+}
+
+func TestGetDocumentByFilePath(t *testing.T) {
+	backend := &fakeDocumentToolBackend{fakeDocumentStore: fakeDocumentStore{docs: []opensearch.ScannedDocument{
+		storedDoc(t, "guide_0", map[string]interface{}{"title": "Guide", "content": "first", "file_path": "docs/guide.md", "chunk_index": 0, "total_chunks": 2}),
+		storedDoc(t, "guide_1", map[string]interface{}{"title": "Guide", "content": "second", "file_path": "docs/guide.md", "chunk_index": 1, "total_chunks": 2}),
+	}}}
+	tools := NewDocumentTools(backend, &HybridSearchConfig{DefaultIndexName: "docs-index"})
+
+	result := callDocumentTool(t, tools, GetDocumentToolName, map[string]interface{}{"file_path": "docs/guide.md"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", toolResultText(t, result))
+	}
+	var doc DocumentResource
+	if err := json.Unmarshal([]byte(toolResultText(t, result)), &doc); err != nil {
+		t.Fatalf("invalid document JSON: %v", err)
+	}
+	if doc.Content != "first\n\nsecond" {
+		t.Fatalf("expected reassembled chunks, got %q", doc.Content)
+	}
+	if link, ok := result.Content[len(result.Content)-1].(*mcp.ResourceLink); !ok || link.URI != doc.URI {
+		t.Fatalf("expected a resource link to the document")
+	}
+}
+
+func TestGetDocumentValidatesReference(t *testing.T) {
+	tools := NewDocumentTools(&fakeDocumentToolBackend{}, &HybridSearchConfig{DefaultIndexName: "docs-index"})
+
+	for _, args := range []map[string]interface{}{
+		{},
+		{"id": "a", "file_path": "b"},
+		{"id": "ragent://category/Runbook"},
+	} {
+		if result := callDocumentTool(t, tools, GetDocumentToolName, args); !result.IsError {
+			t.Fatalf("expected error result for %v", args)
+		}
+	}
+
+	result := callDocumentTool(t, tools, GetDocumentToolName, map[string]interface{}{"id": "missing"})
+	if !result.IsError || !strings.Contains(toolResultText(t, result), "not found") {
+		t.Fatalf("expected not found error result")
+	}
+}
+
+func TestFindSimilarUsesStoredEmbeddingAndSkipsSource(t *testing.T) {
+	backend := &fakeDocumentToolBackend{
+		fakeDocumentStore: fakeDocumentStore{docs: []opensearch.ScannedDocument{
+			storedDoc(t, "guide_0", map[string]interface{}{"title": "Guide", "content": "intro", "file_path": "docs/guide.md", "chunk_index": 0, "embedding": []float64{0.1, 0.2}}),
+		}},
+		vectorHits: []opensearch.VectorSearchResult{
+			{ID: "guide_0", Score: 1.0, Source: json.RawMessage(`{"title":"Guide","file_path":"docs/guide.md"}`)},
+			{ID: "guide_1", Score: 0.99, Source: json.RawMessage(`{"title":"Guide","file_path":"docs/guide.md"}`)},
+			{ID: "ops_0", Score: 0.9, Source: json.RawMessage(`{"title":"Ops","file_path":"docs/ops.md","category":"Runbook"}`)},
+			{ID: "ops_1", Score: 0.8, Source: json.RawMessage(`{"title":"Ops","file_path":"docs/ops.md","category":"Runbook"}`)},
+			{ID: "faq_0", Score: 0.7, Source: json.RawMessage(`{"title":"FAQ","file_path":"docs/faq.md"}`)},
+		},
+	}
+	tools := NewDocumentTools(backend, &HybridSearchConfig{DefaultIndexName: "docs-index", DefaultSize: 5})
+
+	result := callDocumentTool(t, tools, FindSimilarToolName, map[string]interface{}{"id": DocumentResourceURI("guide_0", ""), "top_k": 2, "category": "Runbook"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", toolResultText(t, result))
+	}
+	var response FindSimilarResponse
+	if err := json.Unmarshal([]byte(toolResultText(t, result)), &response); err != nil {
+		t.Fatalf("invalid response JSON: %v", err)
+	}
+	if response.Total != 2 || response.Results[0].ID != "ops_0" || response.Results[1].ID != "faq_0" {
+		t.Fatalf("expected one hit per other document, got %+v", response.Results)
+	}
+
+	query := backend.vectorQueries[0]
+	if len(query.Vector) != 2 || query.Vector[0] != 0.1 {
+		t.Fatalf("expected the stored embedding as query vector, got %v", query.Vector)
+	}
+	if query.Filters["category"] != "Runbook" {
+		t.Fatalf("expected category filter, got %v", query.Filters)
+	}
+	if !query.ExcludeSecret || !query.EnforceGroupACL {
+		t.Fatalf("anonymous similarity search must exclude secrets and enforce group ACLs")
+	}
+}
+
+func TestFindSimilarRequiresEmbedding(t *testing.T) {
+	backend := &fakeDocumentToolBackend{fakeDocumentStore: fakeDocumentStore{docs: []opensearch.ScannedDocument{
+		storedDoc(t, "plain", map[string]interface{}{"title": "Plain", "file_path": "docs/plain.md"}),
+	}}}
+	tools := NewDocumentTools(backend, &HybridSearchConfig{DefaultIndexName: "docs-index"})
+
+	result := callDocumentTool(t, tools, FindSimilarToolName, map[string]interface{}{"id": "plain"})
+	if !result.IsError || !strings.Contains(toolResultText(t, result), "no stored embedding") {
+		t.Fatalf("expected missing embedding error")
+	}
+}
+
+func TestListCategoriesAndTags(t *testing.T) {
+	backend := &fakeDocumentToolBackend{termCounts: []opensearch.TermCount{{Value: "Runbook", Chunks: 9, Documents: 3}}}
+	tools := NewDocumentTools(backend, &HybridSearchConfig{DefaultIndexName: "docs-index"})
+
+	result := callDocumentTool(t, tools, ListCategoriesToolName, map[string]interface{}{"limit": 20})
+	var categories TermListResponse
+	if err := json.Unmarshal([]byte(toolResultText(t, result)), &categories); err != nil {
+		t.Fatalf("invalid response JSON: %v", err)
+	}
+	if categories.Total != 1 || categories.Values[0].URI != "ragent://category/Runbook" || categories.Values[0].Documents != 3 {
+		t.Fatalf("unexpected categories: %+v", categories)
+	}
+
+	callDocumentTool(t, tools, ListTagsToolName, map[string]interface{}{"category": "Runbook"})
+	if backend.aggFields[1] != "tags" {
+		t.Fatalf("expected tags aggregation, got %s", backend.aggFields[1])
+	}
+	data, _ := json.Marshal(backend.aggQueries[1])
+	if !strings.Contains(string(data), `"category":"Runbook"`) || !strings.Contains(string(data), `"secret":true`) {
+		t.Fatalf("tag aggregation must apply the category and access filters: %s", data)
+	}
+
+	if result := callDocumentTool(t, tools, ListTagsToolName, map[string]interface{}{"limit": 1000}); !result.IsError {
+		t.Fatalf("expected error for limit above maximum")
+	}
+}
+
+func TestIndexStats(t *testing.T) {
+	backend := &fakeDocumentToolBackend{summary: &opensearch.DocumentSummary{Chunks: 42, Documents: 10}}
+	tools := NewDocumentTools(backend, &HybridSearchConfig{DefaultIndexName: "docs-index"})
+
+	result := callDocumentTool(t, tools, IndexStatsToolName, nil)
+	var stats IndexStatsResponse
+	if err := json.Unmarshal([]byte(toolResultText(t, result)), &stats); err != nil {
+		t.Fatalf("invalid response JSON: %v", err)
+	}
+	if stats.Index != "docs-index" || stats.Chunks != 42 || stats.Documents != 10 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestDocumentToolsRestrictExplicitIndex(t *testing.T) {
+	backend := &fakeDocumentToolBackend{summary: &opensearch.DocumentSummary{Chunks: 1, Documents: 1}}
+	tools := NewDocumentTools(backend, &HybridSearchConfig{
+		DefaultIndexName: "docs-index",
+		AccessControl:    &appconfig.Config{MCPFederatedIndexes: []string{"shared-docs"}},
+	})
+
+	denied := []struct {
+		tool string
+		args map[string]interface{}
+	}{
+		{IndexStatsToolName, map[string]interface{}{"index": "*"}},
+		{IndexStatsToolName, map[string]interface{}{"index": "other-index"}},
+		{ListCategoriesToolName, map[string]interface{}{"index": "docs-index,other-index"}},
+		{ListTagsToolName, map[string]interface{}{"index": "other-index"}},
+		{GetDocumentToolName, map[string]interface{}{"id": "doc-1", "index": "other-index"}},
+		{GetDocumentToolName, map[string]interface{}{"id": DocumentResourceURI("doc-1", "other-index")}},
+		{FindSimilarToolName, map[string]interface{}{"id": "doc-1", "index": "other-index"}},
+	}
+	for _, tc := range denied {
+		result := callDocumentTool(t, tools, tc.tool, tc.args)
+		if !result.IsError || !strings.Contains(toolResultText(t, result), "access denied") {
+			t.Fatalf("expected access denied from %s with %v, got %s", tc.tool, tc.args, toolResultText(t, result))
+		}
+	}
+	if len(backend.indexes) != 0 || len(backend.aggQueries) != 0 {
+		t.Fatalf("rejected indexes must not be read: %v", backend.indexes)
+	}
+
+	result := callDocumentTool(t, tools, IndexStatsToolName, map[string]interface{}{"index": "shared-docs"})
+	var stats IndexStatsResponse
+	if err := json.Unmarshal([]byte(toolResultText(t, result)), &stats); err != nil {
+		t.Fatalf("invalid response JSON: %v", err)
+	}
+	if stats.Index != "shared-docs" {
+		t.Fatalf("expected the federated index to be read, got %+v", stats)
+	}
+}
@@ -196,6 +196,12 @@ func convertRAGentResultToSDK(ragentResult *MCPToolCallResult) *mcp.CallToolResu
 			content = append(content, &mcp.TextContent{
 				Text: c.Text,
 			})
+		case "resource_link":
+			content = append(content, &mcp.ResourceLink{
+				URI:      c.URI,
+				Name:     c.Name,
+				MIMEType: c.MIMEType,
+			})
 		// Add other content types as needed in the future
 		default:
 			// Default to text content
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
 	"github.com/ca-srg/ragent/internal/pkg/evalexport"
 	"github.com/ca-srg/ragent/internal/pkg/mcpclient"
 	"github.com/ca-srg/ragent/internal/pkg/opensearch"
@@ -53,6 +54,10 @@ type HybridSearchConfig struct {
 	DefaultFusionMethod   string
 	DefaultUseJapaneseNLP bool
 	DefaultTimeoutSeconds int
+	DefaultEfSearch       int // 0 lets OpenSearch use 2*k
+	// AccessControl resolves the caller's tenants and groups from OIDC claims. Without it
+	// searches are not tenant scoped and only documents without allowed_groups are returned.
+	AccessControl *appconfig.Config
 }
 
 // NewHybridSearchToolAdapter creates a new hybrid search tool adapter
@@ -147,6 +152,19 @@ func (hsta *HybridSearchToolAdapter) GetToolDefinition() MCPToolDefinition {
 				"description": "Enable Japanese NLP processing for better Japanese text matching",
 				"default":     true,
 			},
+			"ef_search": map[string]interface{}{
+				"type":        "integer",
+				"description": "HNSW ef_search of the vector query; higher values trade latency for recall",
+				"minimum":     1,
+			},
+			"indexes": map[string]interface{}{
+				"type":        "array",
+				"description": "Indexes to search together (federated search): your own index and those listed in MCP_FEDERATED_INDEXES. Wildcards are not accepted; append ^weight to weight an index, e.g. runbooks^2",
+				"items": map[string]interface{}{
+					"type":      "string",
+					"minLength": 1,
+				},
+			},
 			"enable_slack_search": map[string]interface{}{
 				"type":        "boolean",
 				"description": "Include Slack workspace conversations in the response (requires server Slack configuration)",
@@ -207,6 +225,13 @@ func (hsta *HybridSearchToolAdapter) HandleToolCallWithProgress(ctx context.Cont
 		return CreateToolCallErrorResult(fmt.Sprintf("Invalid parameters: %v", err)), err
 	}
 	hsta.applySecretPolicyFromContext(ctx, searchRequest)
+	if err := hsta.applyTenantPolicyFromContext(ctx, searchRequest); err != nil {
+		return CreateToolCallErrorResult(fmt.Sprintf("Access denied: %v", err)), err
+	}
+	if err := hsta.applyIndexPolicy(searchRequest); err != nil {
+		return CreateToolCallErrorResult(fmt.Sprintf("Access denied: %v", err)), err
+	}
+	hsta.applyGroupPolicyFromContext(ctx, searchRequest)
 
 	directive := slacksearch.DetectSlackSearchDirective(searchRequest.Query)
 	switch directive.Directive {
@@ -324,13 +349,17 @@ func (hsta *HybridSearchToolAdapter) HandleToolCallWithProgress(ctx context.Cont
 
 	if hsta.evalWriter != nil {
 		record := evalexport.NewEvalRecord("mcp-server", searchRequest.Query)
+		evalIndexName := hsta.defaultConfig.DefaultIndexName
+		if len(searchRequest.Indexes) > 0 {
+			evalIndexName = strings.Join(searchRequest.Indexes, ",")
+		}
 		record.RunConfig = evalexport.RunConfig{
 			SearchMode:         searchRequest.SearchMode,
 			BM25Weight:         searchRequest.BM25Weight,
 			VectorWeight:       searchRequest.VectorWeight,
 			FusionMethod:       hsta.defaultConfig.DefaultFusionMethod,
 			TopK:               searchRequest.TopK,
-			IndexName:          hsta.defaultConfig.DefaultIndexName,
+			IndexName:          evalIndexName,
 			UseJapaneseNLP:     hsta.defaultConfig.DefaultUseJapaneseNLP,
 			SlackSearchEnabled: searchRequest.EnableSlackSearch,
 		}
@@ -390,7 +419,9 @@ func (hsta *HybridSearchToolAdapter) HandleToolCallWithProgress(ctx context.Cont
 
 	sendProgress(1.0, 1.0, "Search completed")
 
-	return CreateToolCallResult(string(responseJSON)), nil
+	toolResult := CreateToolCallResult(string(responseJSON))
+	appendResourceLinks(toolResult, mcpResponse.Results)
+	return toolResult, nil
 }
 
 // parseParams extracts and validates parameters from MCP tool call
@@ -414,6 +445,9 @@ func (hsta *HybridSearchToolAdapter) parseParams(params map[string]interface{})
 		IncludeMetadata: false,
 		Filters:         make(map[string]string),
 	}
+	if hsta != nil && hsta.defaultConfig != nil {
+		request.EfSearch = hsta.defaultConfig.DefaultEfSearch
+	}
 
 	// Required query parameter
 	if queryInterface, ok := params["query"]; ok {
@@ -459,12 +493,31 @@ func (hsta *HybridSearchToolAdapter) parseParams(params map[string]interface{})
 		request.MinScore = parseFloatParam(minScoreInterface, request.MinScore)
 	}
 
+	if efSearchInterface, ok := params["ef_search"]; ok {
+		parsed, parseErr := parseIntParamStrict(efSearchInterface)
+		if parseErr != nil {
+			return nil, fmt.Errorf("ef_search must be an integer: %w", parseErr)
+		}
+		if parsed < 1 {
+			return nil, fmt.Errorf("ef_search must be at least 1")
+		}
+		request.EfSearch = parsed
+	}
+
 	if includeMetadataInterface, ok := params["include_metadata"]; ok {
 		if includeMetadata, ok := includeMetadataInterface.(bool); ok {
 			request.IncludeMetadata = includeMetadata
 		}
 	}
 
+	if indexesInterface, ok := params["indexes"]; ok {
+		indexes := parseStringSliceParam(indexesInterface)
+		if _, err := opensearch.ParseIndexTargetList(indexes); err != nil {
+			return nil, fmt.Errorf("invalid indexes: %w", err)
+		}
+		request.Indexes = indexes
+	}
+
 	request.EnableSlackSearch = parseBoolParam(params["enable_slack_search"])
 	if channels := parseStringSliceParam(params["slack_channels"]); len(channels) > 0 {
 		request.SlackChannels = slacksearch.NormalizeSlackChannels(channels)
@@ -508,10 +561,99 @@ func (hsta *HybridSearchToolAdapter) applySecretPolicyFromContext(ctx context.Co
 	request.ExcludeSecret = true
 	tokenInfo := hsta.getOIDCTokenInfo(ctx)
 	if tokenInfo != nil {
+		// API keys only see secret documents when their entry grants secret_access
+		if apiKey := getAPIKeyFromContext(ctx); apiKey != nil && !apiKey.SecretAccess {
+			return
+		}
 		request.ExcludeSecret = false
 	}
 }
 
+// applyTenantPolicyFromContext restricts the search to the tenants in the caller's OIDC claims
+func (hsta *HybridSearchToolAdapter) applyTenantPolicyFromContext(ctx context.Context, request *HybridSearchRequest) error {
+	if request == nil || hsta.defaultConfig == nil || hsta.defaultConfig.AccessControl == nil {
+		return nil
+	}
+
+	tenancy := hsta.defaultConfig.AccessControl
+	var callerTenants []string
+	if tokenInfo := hsta.getOIDCTokenInfo(ctx); tokenInfo != nil {
+		callerTenants = tenancy.TenantsFromClaims(tokenInfo.Claims)
+	}
+	tenants, err := tenancy.ResolveSearchTenants(callerTenants)
+	if err != nil {
+		return err
+	}
+	request.Tenants = tenants
+	return nil
+}
+
+// applyIndexPolicy keeps the entries of the indexes parameter that the caller may search.
+// It must run after applyTenantPolicyFromContext has resolved the caller's tenants.
+func (hsta *HybridSearchToolAdapter) applyIndexPolicy(request *HybridSearchRequest) error {
+	if request == nil || len(request.Indexes) == 0 {
+		return nil
+	}
+	targets, err := opensearch.ParseIndexTargetList(request.Indexes)
+	if err != nil {
+		return err
+	}
+	names := make([]string, len(targets))
+	for i, target := range targets {
+		names[i] = target.Name
+	}
+	allowed, err := callerIndexes(hsta.defaultConfig, request.Tenants, names)
+	if err != nil {
+		return err
+	}
+
+	keep := make(map[string]bool, len(allowed))
+	for _, name := range allowed {
+		keep[name] = true
+	}
+	request.Indexes = request.Indexes[:0]
+	for _, target := range targets {
+		if !keep[target.Name] {
+			continue
+		}
+		entry := target.Name
+		if target.Weight != 1.0 {
+			entry += "^" + strconv.FormatFloat(target.Weight, 'g', -1, 64)
+		}
+		request.Indexes = append(request.Indexes, entry)
+	}
+	return nil
+}
+
+// callerIndexes returns the requested index names a caller restricted to tenants may read:
+// its own tenant indexes and the MCP_FEDERATED_INDEXES entries. Wildcards and lists are rejected.
+func callerIndexes(config *HybridSearchConfig, tenants, requested []string) ([]string, error) {
+	base := "ragent-docs"
+	var access *appconfig.Config
+	if config != nil {
+		if config.DefaultIndexName != "" {
+			base = config.DefaultIndexName
+		}
+		access = config.AccessControl
+	}
+	return access.CallerIndexes(base, tenants, requested)
+}
+
+// applyGroupPolicyFromContext sets the caller's groups from OIDC claims. Requests without an
+// OIDC identity get no groups and only see documents without allowed_groups.
+func (hsta *HybridSearchToolAdapter) applyGroupPolicyFromContext(ctx context.Context, request *HybridSearchRequest) {
+	if request == nil {
+		return
+	}
+
+	request.Groups = nil
+	tokenInfo := hsta.getOIDCTokenInfo(ctx)
+	if tokenInfo == nil || hsta.defaultConfig == nil {
+		return
+	}
+	request.Groups = hsta.defaultConfig.AccessControl.GroupsFromClaims(tokenInfo.Claims)
+}
+
 func (hsta *HybridSearchToolAdapter) getOIDCTokenInfo(ctx context.Context) *TokenInfo {
 	if ctx == nil {
 		return nil
@@ -584,7 +726,21 @@ func (hsta *HybridSearchToolAdapter) executeHybridSearch(ctx context.Context, re
 	if hybridQuery == nil {
 		return nil, fmt.Errorf("failed to build hybrid query: request is nil")
 	}
-	return hsta.hybridEngine.Search(ctx, hybridQuery)
+	return hsta.hybridEngine.SearchIndexes(ctx, hybridQuery)
+}
+
+// unweightedIndexList turns an index spec into the comma-separated index list that a
+// single OpenSearch request accepts; per-index weights only apply to hybrid fusion
+func unweightedIndexList(spec string) string {
+	targets, err := opensearch.ParseIndexTargets(spec)
+	if err != nil || len(targets) == 0 {
+		return spec
+	}
+	names := make([]string, len(targets))
+	for i, target := range targets {
+		names[i] = target.Name
+	}
+	return strings.Join(names, ",")
 }
 
 // executeBM25Search performs BM25-only search
@@ -593,6 +749,7 @@ func (hsta *HybridSearchToolAdapter) executeBM25Search(ctx context.Context, requ
 	if hybridQuery == nil {
 		return nil, fmt.Errorf("failed to build hybrid query: request is nil")
 	}
+	hybridQuery.IndexName = unweightedIndexList(hybridQuery.IndexName)
 	return hsta.hybridEngine.SearchBM25Only(ctx, hybridQuery)
 }
 
@@ -602,6 +759,7 @@ func (hsta *HybridSearchToolAdapter) executeVectorSearch(ctx context.Context, re
 	if hybridQuery == nil {
 		return nil, fmt.Errorf("failed to build hybrid query: request is nil")
 	}
+	hybridQuery.IndexName = unweightedIndexList(hybridQuery.IndexName)
 	return hsta.hybridEngine.SearchVectorOnly(ctx, hybridQuery)
 }
 
@@ -630,20 +788,29 @@ func (hsta *HybridSearchToolAdapter) buildHybridQuery(request *HybridSearchReque
 			timeoutSeconds = 30
 		}
 	}
+	if len(request.Indexes) > 0 {
+		indexName = strings.Join(request.Indexes, ",")
+	} else if hsta != nil && hsta.defaultConfig != nil {
+		indexName = hsta.defaultConfig.AccessControl.TenantIndexSpec(indexName, request.Tenants)
+	}
 
 	return &opensearch.HybridQuery{
-		Query:          request.Query,
-		IndexName:      indexName,
-		Size:           request.TopK,
-		BM25Weight:     request.BM25Weight,
-		VectorWeight:   request.VectorWeight,
-		FusionMethod:   fusionMethod,
-		UseJapaneseNLP: useJapaneseNLP,
-		TimeoutSeconds: timeoutSeconds,
-		Filters:        request.Filters,
-		ExcludeSecret:  request.ExcludeSecret,
-		MinScore:       request.MinScore,
-		K:              request.TopK * 2, // Fetch more candidates for better fusion
+		Query:           request.Query,
+		IndexName:       indexName,
+		Size:            request.TopK,
+		BM25Weight:      request.BM25Weight,
+		VectorWeight:    request.VectorWeight,
+		FusionMethod:    fusionMethod,
+		UseJapaneseNLP:  useJapaneseNLP,
+		TimeoutSeconds:  timeoutSeconds,
+		Filters:         request.Filters,
+		ExcludeSecret:   request.ExcludeSecret,
+		MinScore:        request.MinScore,
+		K:               request.TopK * 2, // Fetch more candidates for better fusion
+		EfSearch:        request.EfSearch,
+		Tenants:         request.Tenants,
+		Groups:          request.Groups,
+		EnforceGroupACL: true,
 	}
 }
 
@@ -671,6 +838,10 @@ func (hsta *HybridSearchToolAdapter) convertToMCPResponse(request *HybridSearchR
 			Score:  doc.FusedScore,
 			Source: request.SearchMode,
 		}
+		if doc.IndexTarget != "" {
+			item.Index = doc.Index
+		}
+		item.ResourceURI = DocumentResourceURI(doc.ID, item.Index)
 
 		// Extract standard fields
 		if title, ok := source["title"].(string); ok {
@@ -0,0 +1,40 @@
+package mcpserver
+
+import (
+	"context"
+	"testing"
+
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+)
+
+func TestHybridSearchTool_GroupPolicy_UsesOIDCClaims(t *testing.T) {
+	adapter := &HybridSearchToolAdapter{defaultConfig: &HybridSearchConfig{
+		AccessControl: &appconfig.Config{ACLOIDCClaim: "groups"},
+	}}
+	request := &HybridSearchRequest{Query: "incident"}
+	ctx := context.WithValue(context.Background(), userContextKey, &TokenInfo{
+		Subject: "user-1",
+		Claims:  map[string]interface{}{"groups": []interface{}{"SRE"}},
+	})
+
+	adapter.applyGroupPolicyFromContext(ctx, request)
+	query := adapter.buildHybridQuery(request)
+	if len(query.Groups) != 1 || query.Groups[0] != "sre" {
+		t.Fatalf("expected groups from claims, got %v", query.Groups)
+	}
+	if !query.EnforceGroupACL {
+		t.Fatalf("expected the group ACL to be enforced")
+	}
+}
+
+func TestHybridSearchTool_GroupPolicy_NoGroupsWithoutOIDC(t *testing.T) {
+	adapter := &HybridSearchToolAdapter{defaultConfig: &HybridSearchConfig{
+		AccessControl: &appconfig.Config{ACLOIDCClaim: "groups"},
+	}}
+	request := &HybridSearchRequest{Query: "incident", Groups: []string{"sre"}}
+
+	adapter.applyGroupPolicyFromContext(context.Background(), request)
+	if request.Groups != nil {
+		t.Fatalf("expected no groups without an OIDC identity, got %v", request.Groups)
+	}
+}
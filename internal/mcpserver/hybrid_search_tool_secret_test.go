@@ -64,3 +64,53 @@ func TestHybridSearchTool_BuildHybridQueryPropagatesSecretPolicy(t *testing.T) {
 		t.Fatalf("expected exclude_secret=true to be propagated to opensearch query")
 	}
 }
+
+func TestHybridSearchTool_parseParamsEfSearch(t *testing.T) {
+	adapter := &HybridSearchToolAdapter{defaultConfig: &HybridSearchConfig{DefaultSize: 10, DefaultEfSearch: 128}}
+
+	request, err := adapter.parseParams(map[string]interface{}{"query": "kiberag"})
+	if err != nil {
+		t.Fatalf("parseParams returned error: %v", err)
+	}
+	if request.EfSearch != 128 {
+		t.Fatalf("expected default ef_search 128, got %d", request.EfSearch)
+	}
+
+	request, err = adapter.parseParams(map[string]interface{}{"query": "kiberag", "ef_search": float64(512)})
+	if err != nil {
+		t.Fatalf("parseParams returned error: %v", err)
+	}
+	if query := adapter.buildHybridQuery(request); query.EfSearch != 512 {
+		t.Fatalf("expected ef_search 512 in hybrid query, got %d", query.EfSearch)
+	}
+
+	if _, err := adapter.parseParams(map[string]interface{}{"query": "kiberag", "ef_search": float64(0)}); err == nil {
+		t.Fatalf("expected error for ef_search below 1")
+	}
+}
+
+func TestHybridSearchTool_parseParamsIndexes(t *testing.T) {
+	adapter := &HybridSearchToolAdapter{}
+
+	request, err := adapter.parseParams(map[string]interface{}{
+		"query":   "rollback",
+		"indexes": []interface{}{"docs", "runbooks^2"},
+	})
+	if err != nil {
+		t.Fatalf("parseParams returned error: %v", err)
+	}
+	query := adapter.buildHybridQuery(request)
+	if query.IndexName != "docs,runbooks^2" {
+		t.Fatalf("expected federated index spec, got %q", query.IndexName)
+	}
+	if got := unweightedIndexList(query.IndexName); got != "docs,runbooks" {
+		t.Fatalf("expected weights to be stripped for single-request modes, got %q", got)
+	}
+
+	if _, err := adapter.parseParams(map[string]interface{}{
+		"query":   "rollback",
+		"indexes": []interface{}{"docs^zero"},
+	}); err == nil {
+		t.Fatalf("expected invalid index weight to be rejected")
+	}
+}
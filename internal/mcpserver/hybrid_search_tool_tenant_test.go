@@ -0,0 +1,85 @@
+package mcpserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+)
+
+func TestHybridSearchTool_TenantPolicy_UsesOIDCClaims(t *testing.T) {
+	adapter := &HybridSearchToolAdapter{defaultConfig: &HybridSearchConfig{
+		DefaultIndexName: "docs",
+		AccessControl:    &appconfig.Config{TenantOIDCClaim: "tenants", TenantIndexPerTenant: true},
+	}}
+	request := &HybridSearchRequest{Query: "budget"}
+	ctx := context.WithValue(context.Background(), userContextKey, &TokenInfo{
+		Subject: "user-1",
+		Claims:  map[string]interface{}{"tenants": []interface{}{"hr", "finance"}},
+	})
+
+	if err := adapter.applyTenantPolicyFromContext(ctx, request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query := adapter.buildHybridQuery(request)
+	if len(query.Tenants) != 2 || query.Tenants[0] != "finance" || query.Tenants[1] != "hr" {
+		t.Fatalf("expected tenants from claims, got %v", query.Tenants)
+	}
+	if query.IndexName != "docs-finance,docs-hr" {
+		t.Fatalf("expected per-tenant indexes, got %q", query.IndexName)
+	}
+}
+
+func TestHybridSearchTool_TenantPolicy_DeniesUnmappedCallerWithIsolation(t *testing.T) {
+	adapter := &HybridSearchToolAdapter{defaultConfig: &HybridSearchConfig{
+		AccessControl: &appconfig.Config{TenantOIDCClaim: "tenants", TenantIsolation: true},
+	}}
+	ctx := context.WithValue(context.Background(), userContextKey, &TokenInfo{Subject: "user-1"})
+
+	err := adapter.applyTenantPolicyFromContext(ctx, &HybridSearchRequest{Query: "budget"})
+	if !errors.Is(err, appconfig.ErrNoTenantAccess) {
+		t.Fatalf("expected ErrNoTenantAccess, got %v", err)
+	}
+}
+
+func TestHybridSearchTool_TenantPolicy_UnrestrictedWithoutTenancy(t *testing.T) {
+	adapter := &HybridSearchToolAdapter{}
+	request := &HybridSearchRequest{Query: "budget"}
+
+	if err := adapter.applyTenantPolicyFromContext(context.Background(), request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if request.Tenants != nil {
+		t.Fatalf("expected no tenant restriction, got %v", request.Tenants)
+	}
+}
+
+func TestHybridSearchTool_IndexPolicy(t *testing.T) {
+	adapter := &HybridSearchToolAdapter{defaultConfig: &HybridSearchConfig{
+		DefaultIndexName: "docs",
+		AccessControl: &appconfig.Config{
+			TenantIndexPerTenant: true,
+			MCPFederatedIndexes:  []string{"runbooks", "docs-finance"},
+		},
+	}}
+
+	request := &HybridSearchRequest{Tenants: []string{"hr"}, Indexes: []string{"docs-hr", "docs-finance^3", "runbooks^2", "payroll"}}
+	if err := adapter.applyIndexPolicy(request); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query := adapter.buildHybridQuery(request); query.IndexName != "docs-hr,runbooks^2" {
+		t.Fatalf("expected other tenants' and unlisted indexes to be dropped, got %q", query.IndexName)
+	}
+
+	err := adapter.applyIndexPolicy(&HybridSearchRequest{Tenants: []string{"hr"}, Indexes: []string{"docs-finance"}})
+	if !errors.Is(err, appconfig.ErrNoIndexAccess) {
+		t.Fatalf("expected ErrNoIndexAccess, got %v", err)
+	}
+	if err := adapter.applyIndexPolicy(&HybridSearchRequest{Indexes: []string{"docs-*"}}); err == nil {
+		t.Fatalf("expected wildcard index to be rejected")
+	}
+	if err := (&HybridSearchToolAdapter{}).applyIndexPolicy(&HybridSearchRequest{Indexes: []string{"runbooks"}}); err == nil {
+		t.Fatalf("expected indexes to be rejected without an allowlist")
+	}
+}
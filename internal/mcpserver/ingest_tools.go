@@ -0,0 +1,332 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	appcfg "github.com/ca-srg/ragent/internal/pkg/config"
+	"github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+// Base names of the document write tools; MCP_TOOL_PREFIX is prepended like for hybrid_search
+const (
+	IngestDocumentToolName = "ingest_document"
+	UpdateDocumentToolName = "update_document"
+)
+
+// WritePermission decides which callers may use the document write tools. Passing the
+// server authentication only grants read access; writing additionally needs one of
+// MCP_WRITE_SCOPES or MCP_WRITE_GROUPS in the OIDC claims, a client IP within
+// MCP_WRITE_ALLOWED_IPS, or a trusted stdio local user.
+type WritePermission struct {
+	config   *appcfg.Config
+	scopes   map[string]bool
+	groups   map[string]bool
+	networks []*net.IPNet
+}
+
+// NewWritePermission parses the write rules of cfg
+func NewWritePermission(cfg *appcfg.Config) (*WritePermission, error) {
+	p := &WritePermission{config: cfg, scopes: make(map[string]bool), groups: make(map[string]bool)}
+	for _, scope := range cfg.MCPWriteScopes {
+		p.scopes[scope] = true
+	}
+	for _, group := range cfg.MCPWriteGroups {
+		p.groups[group] = true
+	}
+	for _, entry := range cfg.MCPWriteAllowedIPs {
+		network, err := ParseCIDROrIP(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MCP_WRITE_ALLOWED_IPS entry: %w", err)
+		}
+		p.networks = append(p.networks, network)
+	}
+	return p, nil
+}
+
+// Authorize returns the rule that grants the caller write access, or an error if none does
+func (p *WritePermission) Authorize(ctx context.Context) (string, error) {
+	method := getAuthMethodFromContext(ctx)
+	token, _ := ctx.Value(userContextKey).(*TokenInfo)
+
+	if method == stdioAuthMethod {
+		if token != nil {
+			return "stdio", nil
+		}
+		return "", fmt.Errorf("write permission denied: the stdio local user is not trusted (MCP_STDIO_TRUST_LOCAL_USER)")
+	}
+
+	if token != nil {
+		for _, scope := range appcfg.ScopesFromClaims(token.Claims) {
+			if p.scopes[scope] {
+				return "scope:" + scope, nil
+			}
+		}
+		for _, group := range p.config.GroupsFromClaims(token.Claims) {
+			if p.groups[group] {
+				return "group:" + group, nil
+			}
+		}
+	}
+
+	// Forwarding headers only count from MCP_TRUSTED_PROXIES, so clients cannot claim an allowed IP
+	if ip := net.ParseIP(getTrustedClientIPFromContext(ctx)); ip != nil {
+		for _, network := range p.networks {
+			if network.Contains(ip) {
+				return "ip:" + network.String(), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("write permission denied: requires a write scope or group, or a client IP in MCP_WRITE_ALLOWED_IPS")
+}
+
+// WriteAuditEntry is the audit record of a document write tool call
+type WriteAuditEntry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Tool       string    `json:"tool"`
+	Path       string    `json:"path"`
+	Subject    string    `json:"subject,omitempty"`
+	Email      string    `json:"email,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	AuthMethod string    `json:"auth_method,omitempty"`
+	GrantedBy  string    `json:"granted_by,omitempty"`
+	Success    bool      `json:"success"`
+	Message    string    `json:"message,omitempty"`
+}
+
+// IngestTools serves ingest_document and update_document. Both write the submitted
+// markdown to the ingest directory and index it like `vectorize` does.
+type IngestTools struct {
+	writer     domain.DocumentWriter
+	permission *WritePermission
+	config     *HybridSearchConfig // Tenant and group settings resolving the caller's access
+	logger     *log.Logger
+}
+
+// ingestArgs are the arguments of ingest_document and update_document
+type ingestArgs struct {
+	Path     string   `json:"path"`
+	Content  string   `json:"content"`
+	Category string   `json:"category"`
+	Tags     []string `json:"tags"`
+	Secret   bool     `json:"secret"`
+	Tenant   string   `json:"tenant"`
+}
+
+// IngestResponse is the result of ingest_document and update_document
+type IngestResponse struct {
+	Path             string `json:"path"`
+	FilePath         string `json:"file_path"`
+	Replaced         bool   `json:"replaced"`
+	RemovedVectors   int    `json:"removed_vectors"`
+	RemovedDocuments int    `json:"removed_documents"`
+	VectorsStored    int    `json:"vectors_stored"`
+	DocumentsIndexed int    `json:"documents_indexed"`
+	ProcessingTime   string `json:"processing_time"`
+}
+
+func NewIngestTools(writer domain.DocumentWriter, permission *WritePermission, config *HybridSearchConfig) *IngestTools {
+	return &IngestTools{
+		writer:     writer,
+		permission: permission,
+		config:     config,
+		logger:     log.New(log.Writer(), "[IngestTools] ", log.LstdFlags),
+	}
+}
+
+// Definitions returns the tools with prefix prepended to their names
+func (it *IngestTools) Definitions(prefix string) []ToolDefinition {
+	definitions := []ToolDefinition{
+		{
+			Tool: &mcp.Tool{
+				Name:        prefix + IngestDocumentToolName,
+				Description: "Markdown文書をナレッジベースに新規登録します。front matterのメタデータ抽出・チャンク分割・ベクトルストアとOpenSearchへのインデックスを行います。書き込み権限が必要です。\n\nEnglish: Add a new markdown document to the knowledge base. The document is saved under the ingest directory and indexed in the vector store and OpenSearch. Fails if the path already exists; use update_document to replace it. Requires write permission.",
+				InputSchema: ingestSchema("Ingest Document Parameters"),
+			},
+			Handler: it.handler("ingest_document", false),
+		},
+		{
+			Tool: &mcp.Tool{
+				Name:        prefix + UpdateDocumentToolName,
+				Description: "登録済みのMarkdown文書を置き換えて再インデックスし、不要になった古いチャンクを削除します。呼び出し元が参照できる文書だけを置き換えられます。書き込み権限が必要です。\n\nEnglish: Replace a document previously added with ingest_document. The new content is indexed first, then the chunks it no longer has are removed from both backends. Only documents visible to the caller can be replaced. Requires write permission.",
+				InputSchema: ingestSchema("Update Document Parameters"),
+			},
+			Handler: it.handler("update_document", true),
+		},
+	}
+
+	for _, definition := range definitions {
+		markToolDestructive(definition.Tool, definition.Tool.Name)
+	}
+	return definitions
+}
+
+// handler checks the write permission, writes the document and audits the outcome
+func (it *IngestTools) handler(spanName string, replace bool) mcp.ToolHandler {
+	return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, span := mcpTracer.Start(ctx, "mcpserver."+spanName)
+		defer span.End()
+
+		toolName := spanName
+		var arguments json.RawMessage
+		if req != nil && req.Params != nil {
+			if req.Params.Name != "" {
+				toolName = req.Params.Name
+			}
+			arguments = req.Params.Arguments
+		}
+		metricAttrs := []attribute.KeyValue{attribute.String("mcp.tool.name", toolName)}
+		span.SetAttributes(metricAttrs...)
+		if method := getAuthMethodFromContext(ctx); method != "" {
+			metricAttrs = append(metricAttrs, attribute.String("mcp.auth.method", method))
+		}
+		start := time.Now()
+		errType := ""
+		defer func() {
+			recordMCPMetrics(ctx, metricAttrs, time.Since(start), errType)
+		}()
+
+		var args ingestArgs
+		entry := newWriteAuditEntry(ctx, toolName)
+		result, err := func() (*MCPToolCallResult, error) {
+			if err := decodeToolArguments(arguments, &args); err != nil {
+				return nil, err
+			}
+			entry.Path = args.Path
+			grantedBy, err := it.permission.Authorize(ctx)
+			if err != nil {
+				errType = "permission_denied"
+				return nil, err
+			}
+			entry.GrantedBy = grantedBy
+			return it.write(ctx, args, replace)
+		}()
+
+		if err != nil {
+			if errType == "" {
+				errType = "tool_call_failed"
+			}
+			span.RecordError(err)
+			span.SetStatus(codes.Error, errType)
+			entry.Message = err.Error()
+			it.audit(entry)
+			return convertRAGentResultToSDK(CreateToolCallErrorResult(err.Error())), nil
+		}
+		entry.Success = true
+		it.audit(entry)
+		return convertRAGentResultToSDK(result), nil
+	}
+}
+
+func (it *IngestTools) write(ctx context.Context, args ingestArgs, replace bool) (*MCPToolCallResult, error) {
+	filter, err := callerAccessFilter(ctx, it.config)
+	if err != nil {
+		return nil, fmt.Errorf("access denied: %w", err)
+	}
+	tenant, err := writeTenant(args.Tenant, filter.Tenants)
+	if err != nil {
+		return nil, err
+	}
+
+	written, err := it.writer.WriteDocument(ctx, &domain.DocumentSubmission{
+		Path:     args.Path,
+		Content:  args.Content,
+		Category: args.Category,
+		Tags:     args.Tags,
+		Secret:   args.Secret,
+		Tenant:   tenant,
+		Access: &domain.DocumentAccess{
+			ExcludeSecret: filter.ExcludeSecret,
+			Tenants:       filter.Tenants,
+			Groups:        filter.Groups,
+		},
+	}, replace)
+	if err != nil {
+		return nil, err
+	}
+
+	response := IngestResponse{
+		Path:             args.Path,
+		FilePath:         written.FilePath,
+		Replaced:         written.Replaced,
+		RemovedVectors:   written.RemovedVectors,
+		RemovedDocuments: written.RemovedDocuments,
+	}
+	if written.Processing != nil {
+		response.VectorsStored = written.Processing.SuccessCount
+		response.DocumentsIndexed = written.Processing.OpenSearchIndexedCount
+		response.ProcessingTime = written.Processing.Duration.String()
+	}
+	return jsonToolResult(response)
+}
+
+// writeTenant picks the tenant a document is written to: the requested tenant, which must be
+// one of the caller's, or the caller's only tenant. Callers without a tenant restriction
+// may name any tenant or keep the server TENANT_ID.
+func writeTenant(requested string, tenants []string) (string, error) {
+	requested = strings.ToLower(strings.TrimSpace(requested))
+	if requested != "" {
+		if err := appcfg.ValidateTenantName(requested); err != nil {
+			return "", err
+		}
+		if len(tenants) > 0 && !slices.Contains(tenants, requested) {
+			return "", fmt.Errorf("access denied: tenant %s is not one of the caller's tenants", requested)
+		}
+		return requested, nil
+	}
+	switch len(tenants) {
+	case 0:
+		return "", nil
+	case 1:
+		return tenants[0], nil
+	}
+	return "", fmt.Errorf("tenant is required: the caller belongs to the tenants %s", strings.Join(tenants, ", "))
+}
+
+// audit logs every write attempt, whether it was denied, failed or succeeded
+func (it *IngestTools) audit(entry WriteAuditEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		it.logger.Printf("[MCP-WRITE-AUDIT] ERROR: Failed to marshal audit entry for %s on %s: %v", entry.Tool, entry.Path, err)
+		return
+	}
+	it.logger.Printf("[MCP-WRITE-AUDIT] %s %s (success=%v): %s", entry.Tool, entry.Path, entry.Success, string(data))
+}
+
+func newWriteAuditEntry(ctx context.Context, tool string) WriteAuditEntry {
+	entry := WriteAuditEntry{
+		Timestamp:  time.Now(),
+		Tool:       tool,
+		IP:         getTrustedClientIPFromContext(ctx),
+		AuthMethod: getAuthMethodFromContext(ctx),
+	}
+	if token, ok := ctx.Value(userContextKey).(*TokenInfo); ok && token != nil {
+		entry.Subject = token.Subject
+		entry.Email = token.Email
+	}
+	return entry
+}
+
+func ingestSchema(title string) *jsonschema.Schema {
+	schema := objectSchema(title, map[string]*jsonschema.Schema{
+		"path":     {Type: "string", Description: "Relative markdown path inside the ingest directory, e.g. runbooks/db-failover.md (.md is added when missing)"},
+		"content":  {Type: "string", Description: "Markdown content, optionally starting with YAML front matter (title, category, tags, secret, allowed_groups, ...)"},
+		"category": {Type: "string", Description: "Category of the document; overrides the front matter category"},
+		"tags":     {Type: "array", Items: &jsonschema.Schema{Type: "string"}, Description: "Tags of the document; override the front matter tags"},
+		"secret":   {Type: "boolean", Description: "Mark the document as secret so only OIDC-authenticated callers can find it"},
+		"tenant":   {Type: "string", Description: "Tenant to write the document to; required when the caller belongs to several tenants"},
+	})
+	schema.Required = []string{"path", "content"}
+	return schema
+}
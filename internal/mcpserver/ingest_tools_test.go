@@ -0,0 +1,234 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	appcfg "github.com/ca-srg/ragent/internal/pkg/config"
+	"github.com/ca-srg/ragent/internal/pkg/domain"
+)
+
+type fakeDocumentWriter struct {
+	submissions []*domain.DocumentSubmission
+	replace     []bool
+}
+
+func (w *fakeDocumentWriter) WriteDocument(ctx context.Context, doc *domain.DocumentSubmission, replace bool) (*domain.DocumentWriteResult, error) {
+	w.submissions = append(w.submissions, doc)
+	w.replace = append(w.replace, replace)
+	return &domain.DocumentWriteResult{
+		FilePath:       "source/mcp/" + doc.Path,
+		Replaced:       replace,
+		RemovedVectors: 2,
+		Processing:     &domain.ProcessingResult{SuccessCount: 1, OpenSearchIndexedCount: 1, Duration: time.Second},
+	}, nil
+}
+
+func newTestWritePermission(t *testing.T) *WritePermission {
+	t.Helper()
+	permission, err := NewWritePermission(&appcfg.Config{
+		ACLOIDCClaim:       "groups",
+		MCPWriteScopes:     []string{"ragent:write"},
+		MCPWriteGroups:     []string{"editors"},
+		MCPWriteAllowedIPs: []string{"10.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatalf("NewWritePermission returned error: %v", err)
+	}
+	return permission
+}
+
+func oidcContext(claims map[string]interface{}) context.Context {
+	ctx := context.WithValue(context.Background(), authMethodContextKey, string(AuthMethodOIDC))
+	return context.WithValue(ctx, userContextKey, &TokenInfo{Subject: "alice", Claims: claims})
+}
+
+func ipContext(ip string) context.Context {
+	ctx := context.WithValue(context.Background(), authMethodContextKey, string(AuthMethodIP))
+	ctx = context.WithValue(ctx, clientIPContextKey, ip)
+	return context.WithValue(ctx, trustedClientIPContextKey, ip)
+}
+
+func TestWritePermissionAuthorize(t *testing.T) {
+	permission := newTestWritePermission(t)
+
+	allowed := map[string]context.Context{
+		"scope:ragent:write": oidcContext(map[string]interface{}{"scope": "openid ragent:write"}),
+		"group:editors":      oidcContext(map[string]interface{}{"groups": []interface{}{"Editors"}}),
+		"ip:10.0.0.0/8":      ipContext("10.1.2.3"),
+		"stdio": context.WithValue(
+			context.WithValue(context.Background(), authMethodContextKey, stdioAuthMethod),
+			userContextKey, &TokenInfo{Subject: "local"}),
+	}
+	for want, ctx := range allowed {
+		granted, err := permission.Authorize(ctx)
+		if err != nil || granted != want {
+			t.Fatalf("expected grant %s, got %q (%v)", want, granted, err)
+		}
+	}
+
+	denied := []context.Context{
+		oidcContext(map[string]interface{}{"scope": "openid profile", "groups": "sre"}),
+		ipContext("127.0.0.1"),
+		context.WithValue(context.Background(), authMethodContextKey, stdioAuthMethod),
+		context.Background(),
+	}
+	for i, ctx := range denied {
+		if _, err := permission.Authorize(ctx); err == nil {
+			t.Fatalf("expected case %d to be denied", i)
+		}
+	}
+}
+
+func TestWritePermissionIgnoresForgedForwardedFor(t *testing.T) {
+	permission := newTestWritePermission(t)
+	authorize := func(remoteAddr, forwardedFor string, trustedProxies []string) error {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		var err error
+		withTrustedClientIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err = permission.Authorize(r.Context())
+		}), trustedProxies).ServeHTTP(httptest.NewRecorder(), req)
+		return err
+	}
+
+	if err := authorize("203.0.113.7:5000", "10.1.2.3", []string{"192.168.0.1"}); err == nil {
+		t.Fatalf("a forged X-Forwarded-For from an untrusted peer must not grant write access")
+	}
+	if err := authorize("203.0.113.7:5000", "10.1.2.3", nil); err == nil {
+		t.Fatalf("X-Forwarded-For must be ignored without MCP_TRUSTED_PROXIES")
+	}
+	if err := authorize("192.168.0.1:5000", "10.1.2.3", []string{"192.168.0.1"}); err != nil {
+		t.Fatalf("expected the client IP forwarded by a trusted proxy to be allowed, got %v", err)
+	}
+	if err := authorize("10.1.2.3:5000", "", nil); err != nil {
+		t.Fatalf("expected a direct client in MCP_WRITE_ALLOWED_IPS to be allowed, got %v", err)
+	}
+}
+
+func TestNewWritePermissionRejectsInvalidIP(t *testing.T) {
+	if _, err := NewWritePermission(&appcfg.Config{MCPWriteAllowedIPs: []string{"not-an-ip"}}); err == nil {
+		t.Fatalf("expected invalid MCP_WRITE_ALLOWED_IPS entry to fail")
+	}
+}
+
+func TestIngestToolDefinitionsAreDestructive(t *testing.T) {
+	tools := NewIngestTools(&fakeDocumentWriter{}, newTestWritePermission(t), nil)
+
+	definitions := tools.Definitions("kb_")
+	if len(definitions) != 2 {
+		t.Fatalf("expected 2 tools, got %d", len(definitions))
+	}
+	for _, definition := range definitions {
+		if !strings.HasPrefix(definition.Tool.Name, "kb_") {
+			t.Fatalf("tool name %s is not prefixed", definition.Tool.Name)
+		}
+		annotations := definition.Tool.Annotations
+		if annotations == nil || annotations.ReadOnlyHint || annotations.DestructiveHint == nil || !*annotations.DestructiveHint {
+			t.Fatalf("tool %s must be annotated destructive", definition.Tool.Name)
+		}
+	}
+}
+
+func callIngestTool(t *testing.T, tools *IngestTools, ctx context.Context, name string, args map[string]interface{}) *mcp.CallToolResult {
+	t.Helper()
+	for _, definition := range tools.Definitions("") {
+		if definition.Tool.Name != name {
+			continue
+		}
+		data, _ := json.Marshal(args)
+		result, err := definition.Handler(ctx, &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: name, Arguments: data}})
+		if err != nil {
+			t.Fatalf("%s returned error: %v", name, err)
+		}
+		return result
+	}
+	t.Fatalf("tool %s not defined", name)
+	return nil
+}
+
+func TestIngestDocumentRequiresWritePermission(t *testing.T) {
+	writer := &fakeDocumentWriter{}
+	tools := NewIngestTools(writer, newTestWritePermission(t), nil)
+
+	result := callIngestTool(t, tools, oidcContext(map[string]interface{}{"scope": "openid"}), IngestDocumentToolName, map[string]interface{}{
+		"path": "notes.md", "content": "# Notes",
+	})
+	if !result.IsError || !strings.Contains(toolResultText(t, result), "write permission denied") {
+		t.Fatalf("expected permission error result")
+	}
+	if len(writer.submissions) != 0 {
+		t.Fatalf("writer must not be called without write permission")
+	}
+}
+
+func TestIngestAndUpdateDocument(t *testing.T) {
+	writer := &fakeDocumentWriter{}
+	tools := NewIngestTools(writer, newTestWritePermission(t), nil)
+	ctx := oidcContext(map[string]interface{}{"scope": "ragent:write"})
+
+	result := callIngestTool(t, tools, ctx, IngestDocumentToolName, map[string]interface{}{
+		"path": "runbooks/failover.md", "content": "# Failover", "category": "Runbook", "tags": []string{"db"}, "secret": true,
+	})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", toolResultText(t, result))
+	}
+	submission := writer.submissions[0]
+	if submission.Category != "Runbook" || !submission.Secret || len(submission.Tags) != 1 || writer.replace[0] {
+		t.Fatalf("unexpected submission: %+v (replace=%v)", submission, writer.replace[0])
+	}
+
+	result = callIngestTool(t, tools, ctx, UpdateDocumentToolName, map[string]interface{}{
+		"path": "runbooks/failover.md", "content": "# Failover v2",
+	})
+	var response IngestResponse
+	if err := json.Unmarshal([]byte(toolResultText(t, result)), &response); err != nil {
+		t.Fatalf("invalid response JSON: %v", err)
+	}
+	if !writer.replace[1] || !response.Replaced || response.RemovedVectors != 2 || response.VectorsStored != 1 {
+		t.Fatalf("unexpected update response: %+v", response)
+	}
+}
+
+func TestIngestDocumentWritesWithCallerAccess(t *testing.T) {
+	writer := &fakeDocumentWriter{}
+	tools := NewIngestTools(writer, newTestWritePermission(t), &HybridSearchConfig{
+		AccessControl: &appcfg.Config{ACLOIDCClaim: "groups", TenantOIDCClaim: "tenants"},
+	})
+
+	ctx := oidcContext(map[string]interface{}{"scope": "ragent:write", "groups": []interface{}{"eng"}, "tenants": "acme"})
+	result := callIngestTool(t, tools, ctx, IngestDocumentToolName, map[string]interface{}{"path": "notes.md", "content": "# Notes"})
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", toolResultText(t, result))
+	}
+	submission := writer.submissions[0]
+	if submission.Tenant != "acme" || submission.Access == nil {
+		t.Fatalf("expected the caller's tenant and access, got %+v", submission)
+	}
+	if strings.Join(submission.Access.Tenants, ",") != "acme" || strings.Join(submission.Access.Groups, ",") != "eng" || submission.Access.ExcludeSecret {
+		t.Fatalf("unexpected access: %+v", submission.Access)
+	}
+
+	result = callIngestTool(t, tools, ctx, IngestDocumentToolName, map[string]interface{}{"path": "notes.md", "content": "# Notes", "tenant": "beta"})
+	if !result.IsError || !strings.Contains(toolResultText(t, result), "not one of the caller's tenants") {
+		t.Fatalf("expected other tenants to be rejected")
+	}
+
+	multi := oidcContext(map[string]interface{}{"scope": "ragent:write", "tenants": "acme beta"})
+	result = callIngestTool(t, tools, multi, IngestDocumentToolName, map[string]interface{}{"path": "notes.md", "content": "# Notes"})
+	if !result.IsError || !strings.Contains(toolResultText(t, result), "tenant is required") {
+		t.Fatalf("expected a tenant to be required for callers of several tenants")
+	}
+	result = callIngestTool(t, tools, multi, IngestDocumentToolName, map[string]interface{}{"path": "notes.md", "content": "# Notes", "tenant": "Beta"})
+	if result.IsError || writer.submissions[len(writer.submissions)-1].Tenant != "beta" {
+		t.Fatalf("expected the requested tenant to be used")
+	}
+}
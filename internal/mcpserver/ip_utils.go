@@ -1,6 +1,7 @@
 package mcpserver
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
@@ -116,6 +117,37 @@ func ExtractClientIPFromRequest(r *http.Request, trustedProxies []string) string
 	return ExtractClientIP(adapter, trustedProxies)
 }
 
+// TrustedClientIP returns the client IP used for authorization decisions. Unlike
+// ExtractClientIPFromRequest it never trusts forwarding headers without MCP_TRUSTED_PROXIES:
+// X-Forwarded-For and X-Real-IP only count when the direct peer is a listed proxy.
+func TrustedClientIP(r *http.Request, trustedProxies []string) string {
+	if len(trustedProxies) > 0 {
+		return ExtractClientIPFromRequest(r, trustedProxies)
+	}
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// withTrustedClientIP stores the TrustedClientIP of every request in its context, where
+// write permissions and rate limit principals read it
+func withTrustedClientIP(next http.Handler, trustedProxies []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), trustedClientIPContextKey, TrustedClientIP(r, trustedProxies))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func getTrustedClientIPFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	clientIP, _ := ctx.Value(trustedClientIPContextKey).(string)
+	return clientIP
+}
+
 // ValidateIPFormat checks if the given string is a valid IP address
 func ValidateIPFormat(ipStr string) bool {
 	return net.ParseIP(ipStr) != nil
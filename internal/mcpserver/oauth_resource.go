@@ -0,0 +1,208 @@
+package mcpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	appcfg "github.com/ca-srg/ragent/internal/pkg/config"
+)
+
+// ProtectedResourceMetadataPath is the RFC 9728 well-known path MCP clients use to
+// discover which authorization server issues tokens for this server
+const ProtectedResourceMetadataPath = "/.well-known/oauth-protected-resource"
+
+// ProtectedResourceMetadata is the RFC 9728 OAuth 2.0 Protected Resource Metadata document
+type ProtectedResourceMetadata struct {
+	Resource               string   `json:"resource"`
+	AuthorizationServers   []string `json:"authorization_servers,omitempty"`
+	ScopesSupported        []string `json:"scopes_supported,omitempty"`
+	BearerMethodsSupported []string `json:"bearer_methods_supported,omitempty"`
+	ResourceName           string   `json:"resource_name,omitempty"`
+}
+
+// insufficientScopeError reports a valid token that lacks MCP_OAUTH_REQUIRED_SCOPES
+type insufficientScopeError struct {
+	missing []string
+}
+
+func (e *insufficientScopeError) Error() string {
+	return fmt.Sprintf("token is missing required scopes: %s", strings.Join(e.missing, " "))
+}
+
+// isProtectedResourceMetadataPath matches the metadata path with or without the
+// resource path suffix (e.g. /.well-known/oauth-protected-resource/mcp)
+func isProtectedResourceMetadataPath(path string) bool {
+	return path == ProtectedResourceMetadataPath || strings.HasPrefix(path, ProtectedResourceMetadataPath+"/")
+}
+
+// resourceMetadataURL returns where the metadata of MCP_OAUTH_RESOURCE is served, or ""
+// when no resource is configured. Per RFC 9728 the resource path is appended to the
+// well-known path.
+func (m *OIDCAuthMiddleware) resourceMetadataURL() string {
+	if m.resource == "" {
+		return ""
+	}
+	resource, err := url.Parse(m.resource)
+	if err != nil {
+		return ""
+	}
+	return resource.Scheme + "://" + resource.Host + ProtectedResourceMetadataPath + strings.TrimSuffix(resource.Path, "/")
+}
+
+// challengeScopes are the scopes a client should request for this resource
+func (m *OIDCAuthMiddleware) challengeScopes() []string {
+	if len(m.requiredScopes) > 0 {
+		return m.requiredScopes
+	}
+	return m.oauth2Config.Scopes
+}
+
+// HandleProtectedResourceMetadata serves the RFC 9728 metadata document. The
+// authorization server is the OIDC issuer, so clients authenticate against the IdP
+// directly instead of through /login. Without MCP_OAUTH_RESOURCE there is no resource
+// to describe (and no audience is enforced), so the document is not served.
+func (m *OIDCAuthMiddleware) HandleProtectedResourceMetadata(w http.ResponseWriter, r *http.Request) {
+	if m.resource == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	metadata := ProtectedResourceMetadata{
+		Resource:               m.resource,
+		BearerMethodsSupported: []string{"header"},
+		ResourceName:           "RAGent MCP Server",
+	}
+	if m.customConfig != nil && m.customConfig.Issuer != "" {
+		metadata.AuthorizationServers = []string{m.customConfig.Issuer}
+	}
+	for _, scope := range append(slices.Clone(m.oauth2Config.Scopes), m.requiredScopes...) {
+		if !slices.Contains(metadata.ScopesSupported, scope) {
+			metadata.ScopesSupported = append(metadata.ScopesSupported, scope)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "max-age=3600")
+	if err := json.NewEncoder(w).Encode(metadata); err != nil {
+		log.Printf("Failed to encode protected resource metadata: %v", err)
+	}
+}
+
+// authorizeToken applies the audience and scope rules of MCP_OAUTH_RESOURCE to a verified
+// token. Access tokens must name the resource in aud and carry MCP_OAUTH_REQUIRED_SCOPES.
+// ID tokens issued to our client ID (the /login flow) pass when MCP_OAUTH_ALLOW_ID_TOKENS is on.
+func (m *OIDCAuthMiddleware) authorizeToken(audience []string, claims map[string]interface{}) error {
+	if m.resource == "" {
+		return nil
+	}
+
+	if slices.Contains(audience, m.resource) {
+		granted := appcfg.ScopesFromClaims(claims)
+		var missing []string
+		for _, scope := range m.requiredScopes {
+			if !slices.Contains(granted, scope) {
+				missing = append(missing, scope)
+			}
+		}
+		if len(missing) > 0 {
+			return &insufficientScopeError{missing: missing}
+		}
+		return nil
+	}
+
+	if m.allowIDTokens && slices.Contains(audience, m.oauth2Config.ClientID) {
+		return nil
+	}
+	return fmt.Errorf("token audience %v does not include resource %s", audience, m.resource)
+}
+
+// audienceFromClaims reads the aud claim, which may be a string or a list
+func audienceFromClaims(claims map[string]interface{}) []string {
+	switch aud := claims["aud"].(type) {
+	case string:
+		return []string{aud}
+	case []interface{}:
+		values := make([]string, 0, len(aud))
+		for _, value := range aud {
+			if s, ok := value.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	case []string:
+		return aud
+	}
+	return nil
+}
+
+// bearerChallenge builds the RFC 6750 WWW-Authenticate value, pointing at the resource
+// metadata when MCP_OAUTH_RESOURCE is configured
+func (m *OIDCAuthMiddleware) bearerChallenge(errorCode, description string, scopes []string) string {
+	var params []string
+	if metadataURL := m.resourceMetadataURL(); metadataURL != "" {
+		params = append(params, fmt.Sprintf("resource_metadata=%q", metadataURL))
+	}
+	if errorCode != "" {
+		params = append(params, fmt.Sprintf("error=%q", errorCode))
+	}
+	if description != "" {
+		params = append(params, fmt.Sprintf("error_description=%q", description))
+	}
+	if len(scopes) > 0 {
+		params = append(params, fmt.Sprintf("scope=%q", strings.Join(scopes, " ")))
+	}
+	if len(params) == 0 {
+		return `Bearer realm="ragent-mcp"`
+	}
+	return "Bearer " + strings.Join(params, ", ")
+}
+
+// sendInvalidToken rejects a presented bearer token: 403 insufficient_scope when the
+// token lacks required scopes, otherwise 401 invalid_token
+func (m *OIDCAuthMiddleware) sendInvalidToken(w http.ResponseWriter, r *http.Request, err error) {
+	if scopeErr, ok := err.(*insufficientScopeError); ok {
+		w.Header().Set("WWW-Authenticate", m.bearerChallenge("insufficient_scope", scopeErr.Error(), m.requiredScopes))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		response := map[string]interface{}{
+			"jsonrpc": "2.0",
+			"error": map[string]interface{}{
+				"code":    -32001,
+				"message": "Insufficient scope",
+				"data": map[string]interface{}{
+					"required_scopes": m.requiredScopes,
+				},
+			},
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Printf("Failed to encode response: %v", err)
+		}
+		return
+	}
+	m.writeAuthenticationRequired(w, r, m.bearerChallenge("invalid_token", "", m.challengeScopes()))
+}
+
+// requestBaseURL returns scheme://host of the request, honoring X-Forwarded-* headers
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	} else if xfProto := r.Header.Get("X-Forwarded-Proto"); xfProto != "" {
+		scheme = xfProto
+	}
+	host := r.Host
+	if xfh := r.Header.Get("X-Forwarded-Host"); xfh != "" {
+		host = xfh
+	}
+	return fmt.Sprintf("%s://%s", scheme, host)
+}
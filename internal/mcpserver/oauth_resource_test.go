@@ -0,0 +1,211 @@
+package mcpserver
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testResource = "https://mcp.example.com/mcp"
+
+// fakeIssuer is a local OIDC provider serving discovery and JWKS for signed test tokens
+type fakeIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	issuer := &fakeIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                issuer.server.URL,
+			"authorization_endpoint":                issuer.server.URL + "/authorize",
+			"token_endpoint":                        issuer.server.URL + "/token",
+			"jwks_uri":                              issuer.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"alg": "RS256",
+				"use": "sig",
+				"kid": "test",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (f *fakeIssuer) token(t *testing.T, audience interface{}, scope string) string {
+	t.Helper()
+	claims := jwt.MapClaims{
+		"iss": f.server.URL,
+		"sub": "alice",
+		"aud": audience,
+		"exp": time.Now().Add(time.Hour).Unix(),
+		"iat": time.Now().Unix(),
+	}
+	if scope != "" {
+		claims["scope"] = scope
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(f.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func newResourceMiddleware(t *testing.T, issuer *fakeIssuer, allowIDTokens bool) *OIDCAuthMiddleware {
+	t.Helper()
+	middleware, err := NewOIDCAuthMiddleware(&OIDCConfig{
+		Issuer:         issuer.server.URL,
+		ClientID:       "ragent-client",
+		Scopes:         []string{"openid", "email"},
+		Resource:       testResource,
+		RequiredScopes: []string{"ragent:read"},
+		AllowIDTokens:  allowIDTokens,
+	})
+	require.NoError(t, err)
+	return middleware
+}
+
+func TestProtectedResourceMetadata(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	middleware := newResourceMiddleware(t, issuer, true)
+
+	rec := httptest.NewRecorder()
+	middleware.HandleProtectedResourceMetadata(rec, httptest.NewRequest(http.MethodGet, ProtectedResourceMetadataPath+"/mcp", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var metadata ProtectedResourceMetadata
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &metadata))
+	assert.Equal(t, testResource, metadata.Resource)
+	assert.Equal(t, []string{issuer.server.URL}, metadata.AuthorizationServers)
+	assert.Equal(t, []string{"openid", "email", "ragent:read"}, metadata.ScopesSupported)
+	assert.Equal(t, []string{"header"}, metadata.BearerMethodsSupported)
+
+}
+
+func TestProtectedResourceMetadataRequiresConfiguredResource(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	middleware, err := NewOIDCAuthMiddleware(&OIDCConfig{Issuer: issuer.server.URL, ClientID: "ragent-client"})
+	require.NoError(t, err)
+
+	// A header-derived host must never be advertised as the resource
+	req := httptest.NewRequest(http.MethodGet, ProtectedResourceMetadataPath, nil)
+	req.Header.Set("X-Forwarded-Host", "attacker.example.com")
+	rec := httptest.NewRecorder()
+	middleware.HandleProtectedResourceMetadata(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "attacker.example.com")
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.Header.Set("X-Forwarded-Host", "attacker.example.com")
+	rec = httptest.NewRecorder()
+	middleware.Middleware(http.NotFoundHandler()).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	challenge := rec.Header().Get("WWW-Authenticate")
+	assert.True(t, strings.HasPrefix(challenge, "Bearer "), challenge)
+	assert.NotContains(t, challenge, "resource_metadata")
+	assert.NotContains(t, rec.Body.String(), "resource_metadata")
+}
+
+func TestOIDCBearerChallengesAndAudience(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	middleware := newResourceMiddleware(t, issuer, true)
+
+	var subject string
+	handler := middleware.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subject = r.Context().Value(userContextKey).(*TokenInfo).Subject
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	metadataParam := `resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource/mcp"`
+
+	rec := serve("")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	challenge := rec.Header().Get("WWW-Authenticate")
+	assert.True(t, strings.HasPrefix(challenge, "Bearer "), challenge)
+	assert.Contains(t, challenge, metadataParam)
+	assert.Contains(t, challenge, `scope="ragent:read"`)
+	assert.NotContains(t, challenge, "error=")
+
+	rec = serve(issuer.token(t, testResource, "openid ragent:read"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "alice", subject)
+
+	rec = serve(issuer.token(t, []string{"https://other.example.com", testResource}, "ragent:read"))
+	assert.Equal(t, http.StatusOK, rec.Code, "aud may list several resources")
+
+	rec = serve(issuer.token(t, "https://other.example.com/mcp", "ragent:read"))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+
+	rec = serve(issuer.token(t, testResource, "openid"))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	challenge = rec.Header().Get("WWW-Authenticate")
+	assert.Contains(t, challenge, `error="insufficient_scope"`)
+	assert.Contains(t, challenge, metadataParam)
+
+	// ID tokens from the /login flow carry the client ID as audience
+	rec = serve(issuer.token(t, "ragent-client", ""))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestOIDCRejectsIDTokensWhenDisabled(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	middleware := newResourceMiddleware(t, issuer, false)
+
+	_, err := middleware.validateToken(issuer.token(t, "ragent-client", ""))
+	assert.ErrorContains(t, err, "does not include resource")
+
+	info, err := middleware.validateToken(issuer.token(t, testResource, "ragent:read"))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", info.Subject)
+}
+
+func TestUnifiedAuthServesResourceMetadataWithoutToken(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	unified, err := NewUnifiedAuthMiddleware(&UnifiedAuthConfig{
+		AuthMethod: AuthMethodOIDC,
+		OIDCConfig: &OIDCConfig{Issuer: issuer.server.URL, ClientID: "ragent-client", Resource: testResource},
+	})
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(ProtectedResourceMetadataPath+"/", unified.GetOIDCAuthMiddleware().HandleProtectedResourceMetadata)
+	rec := httptest.NewRecorder()
+	unified.Middleware(mux).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ProtectedResourceMetadataPath+"/mcp", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), testResource)
+}
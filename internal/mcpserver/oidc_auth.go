@@ -28,6 +28,18 @@ type OIDCAuthMiddleware struct {
 	customConfig  *OIDCConfig
 	mutex         sync.RWMutex
 
+	// OAuth protected resource settings (MCP_OAUTH_*)
+	resource       string
+	requiredScopes []string
+	allowIDTokens  bool
+
+	// Persistent sessions with refresh-token rotation (MCP_OIDC_SESSION_*)
+	sessionStore  SessionPersistence
+	sessionTTL    time.Duration
+	refreshBefore time.Duration
+	sessionAdmins map[string]bool
+	refreshLocks  sync.Map // Session ID to *sync.Mutex; refreshes of different sessions run in parallel
+
 	// Integrated callback support
 	pendingMutex sync.Mutex
 	pendingFlows map[string]*authFlow
@@ -51,12 +63,24 @@ type OIDCConfig struct {
 
 	// Skip OIDC discovery and use only custom endpoints
 	SkipDiscovery bool // If true, skip OIDC discovery and use custom endpoints only
+
+	// OAuth 2.0 protected resource settings (MCP authorization spec)
+	Resource       string   // Resource URI bearer tokens must be issued for (aud); empty keeps client ID audience checks
+	RequiredScopes []string // Scopes access tokens for Resource must carry
+	AllowIDTokens  bool     // Also accept ID tokens issued to ClientID when Resource is set
+
+	// Sessions issued by /callback
+	SessionStore  SessionPersistence // Keeps sessions across restarts; nil keeps them in memory only
+	SessionTTL    time.Duration      // Lifetime of a session (defaults to 7 days)
+	RefreshBefore time.Duration      // Refresh IdP tokens this long before they expire (defaults to 5 minutes)
+	SessionAdmins []string           // Principals allowed to list and revoke every session
 }
 
 // TokenStore manages authentication tokens
 type TokenStore struct {
-	tokens map[string]*TokenInfo
-	mutex  sync.RWMutex
+	tokens   map[string]*TokenInfo
+	sessions map[string]*Session // Keyed by session ID
+	mutex    sync.RWMutex
 }
 
 // TokenInfo contains token information
@@ -192,6 +216,20 @@ func NewOIDCAuthMiddleware(config *OIDCConfig) (*OIDCAuthMiddleware, error) {
 		enableLogging: config.EnableLogging,
 		customConfig:  config,
 		pendingFlows:  make(map[string]*authFlow),
+
+		resource:       strings.TrimSpace(config.Resource),
+		requiredScopes: config.RequiredScopes,
+		allowIDTokens:  config.AllowIDTokens,
+	}
+
+	// Access tokens are issued for the resource rather than our client ID, so the
+	// audience is checked by authorizeToken instead of the verifier
+	if middleware.resource != "" && provider != nil {
+		middleware.verifier = provider.Verifier(&oidc.Config{SkipClientIDCheck: true})
+	}
+
+	if err := middleware.initSessions(config); err != nil {
+		return nil, err
 	}
 
 	if middleware.enableLogging {
@@ -208,15 +246,16 @@ func NewOIDCAuthMiddleware(config *OIDCConfig) (*OIDCAuthMiddleware, error) {
 // NewTokenStore creates a new token store
 func NewTokenStore() *TokenStore {
 	return &TokenStore{
-		tokens: make(map[string]*TokenInfo),
+		tokens:   make(map[string]*TokenInfo),
+		sessions: make(map[string]*Session),
 	}
 }
 
 // Middleware returns the HTTP middleware function
 func (m *OIDCAuthMiddleware) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Allow OAuth2 callback path to bypass auth checks
-		if r.URL.Path == "/callback" {
+		// Allow OAuth2 callback and resource metadata paths to bypass auth checks
+		if r.URL.Path == "/callback" || isProtectedResourceMetadataPath(r.URL.Path) {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -236,7 +275,7 @@ func (m *OIDCAuthMiddleware) Middleware(next http.Handler) http.Handler {
 				segments := strings.Count(token, ".")
 				log.Printf("Token validation failed (len=%d, segments=%d): %v", len(token), segments+1, err)
 			}
-			m.sendAuthenticationRequired(w, r)
+			m.sendInvalidToken(w, r, err)
 			return
 		}
 
@@ -245,7 +284,7 @@ func (m *OIDCAuthMiddleware) Middleware(next http.Handler) http.Handler {
 			if m.enableLogging {
 				log.Printf("Token expired for user: %s", tokenInfo.Email)
 			}
-			m.sendAuthenticationRequired(w, r)
+			m.sendInvalidToken(w, r, fmt.Errorf("token is expired"))
 			return
 		}
 
@@ -307,6 +346,10 @@ func sanitizeToken(token string) string {
 
 // validateToken validates the ID token
 func (m *OIDCAuthMiddleware) validateToken(tokenString string) (*TokenInfo, error) {
+	if strings.HasPrefix(tokenString, SessionTokenPrefix) {
+		return m.validateSession(context.Background(), tokenString)
+	}
+
 	// Check token store first
 	m.tokenStore.mutex.RLock()
 	if info, exists := m.tokenStore.tokens[tokenString]; exists {
@@ -335,6 +378,9 @@ func (m *OIDCAuthMiddleware) validateToken(tokenString string) (*TokenInfo, erro
 		if err := idToken.Claims(&claims); err != nil {
 			return nil, fmt.Errorf("failed to extract claims: %w", err)
 		}
+		if err := m.authorizeToken(idToken.Audience, claims); err != nil {
+			return nil, err
+		}
 
 		// Create token info
 		tokenInfo := &TokenInfo{
@@ -378,6 +424,9 @@ func (m *OIDCAuthMiddleware) validateTokenWithoutVerifier(tokenString string) (*
 	if !ok {
 		return nil, fmt.Errorf("failed to extract JWT claims")
 	}
+	if err := m.authorizeToken(audienceFromClaims(claims), claims); err != nil {
+		return nil, err
+	}
 
 	// Extract standard claims
 	tokenInfo := &TokenInfo{
@@ -416,22 +465,32 @@ func (m *OIDCAuthMiddleware) validateTokenWithoutVerifier(tokenString string) (*
 	return tokenInfo, nil
 }
 
-// sendAuthenticationRequired sends an authentication required response
+// sendAuthenticationRequired sends an authentication required response with a bearer
+// challenge that points MCP clients at the protected resource metadata
 func (m *OIDCAuthMiddleware) sendAuthenticationRequired(w http.ResponseWriter, r *http.Request) {
+	m.writeAuthenticationRequired(w, r, m.bearerChallenge("", "", m.challengeScopes()))
+}
+
+func (m *OIDCAuthMiddleware) writeAuthenticationRequired(w http.ResponseWriter, r *http.Request, challenge string) {
 	// Return JSON-RPC error for API calls
+	w.Header().Set("WWW-Authenticate", challenge)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusUnauthorized)
 
-	// Build auth URL bound to request host
+	// Build auth URL bound to request host; auth_url keeps the /login flow working
 	authURL := m.GetAuthURLForRequest(r)
+	data := map[string]interface{}{
+		"auth_url": authURL,
+	}
+	if metadataURL := m.resourceMetadataURL(); metadataURL != "" {
+		data["resource_metadata"] = metadataURL
+	}
 	response := map[string]interface{}{
 		"jsonrpc": "2.0",
 		"error": map[string]interface{}{
 			"code":    -32001,
 			"message": "Authentication required",
-			"data": map[string]interface{}{
-				"auth_url": authURL,
-			},
+			"data":    data,
 		},
 	}
 
@@ -457,17 +516,7 @@ func (m *OIDCAuthMiddleware) GetAuthURL() string {
 // GetAuthURLForRequest generates an auth URL using the request's host/scheme
 func (m *OIDCAuthMiddleware) GetAuthURLForRequest(r *http.Request) string {
 	state := m.generateState()
-	scheme := "http"
-	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
-		scheme = "https"
-	} else if xfProto := r.Header.Get("X-Forwarded-Proto"); xfProto != "" {
-		scheme = xfProto
-	}
-	host := r.Host
-	if xfh := r.Header.Get("X-Forwarded-Host"); xfh != "" {
-		host = xfh
-	}
-	redirect := fmt.Sprintf("%s://%s/callback", scheme, host)
+	redirect := requestBaseURL(r) + "/callback"
 
 	m.mutex.Lock()
 	m.oauth2Config.RedirectURL = redirect
@@ -614,27 +663,39 @@ func (m *OIDCAuthMiddleware) HandleCallback(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
+	if err := m.authorizeToken(audienceFromClaims(tokenInfo.Claims), tokenInfo.Claims); err != nil {
+		m.notifyPendingFlowError(state, err)
+		http.Error(w, "Token not accepted for this resource", http.StatusForbidden)
+		return
+	}
+
 	// Store token
 	m.tokenStore.mutex.Lock()
 	m.tokenStore.tokens[rawIDToken] = tokenInfo
 	m.tokenStore.mutex.Unlock()
 
-	// Build MCP add command suggestion
-	scheme := "http"
-	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
-		scheme = "https"
-	} else if xfProto := r.Header.Get("X-Forwarded-Proto"); xfProto != "" {
-		scheme = xfProto
-	}
-	host := r.Host
-	if xfh := r.Header.Get("X-Forwarded-Host"); xfh != "" {
-		host = xfh
+	// Hand out a session token so the client keeps working after the ID token expires
+	sessionToken, err := m.createSession(tokenInfo)
+	if err != nil {
+		m.notifyPendingFlowError(state, err)
+		http.Error(w, "Session creation failed", http.StatusInternalServerError)
+		return
 	}
-	serverURL := fmt.Sprintf("%s://%s", scheme, host)
-	addCmd := fmt.Sprintf("claude mcp add --transport <http or sse> ragent %s --header \"Authorization: Bearer %s\"", serverURL, rawIDToken)
+
+	// Build MCP add command suggestion
+	serverURL := requestBaseURL(r)
+	addCmd := fmt.Sprintf("claude mcp add --transport <http or sse> ragent %s --header \"Authorization: Bearer %s\"", serverURL, sessionToken)
 
 	// Optionally set a cookie for browser-based calls
-	http.SetCookie(w, &http.Cookie{Name: "mcp_auth_token", Value: rawIDToken, Path: "/", HttpOnly: true, Secure: scheme == "https"})
+	http.SetCookie(w, &http.Cookie{
+		Name:     "mcp_auth_token",
+		Value:    sessionToken,
+		Path:     "/",
+		MaxAge:   int(m.sessionTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(serverURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
 
 	// Success response with instruction (English first, then Japanese)
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -789,6 +850,10 @@ func (m *OIDCAuthMiddleware) getUserInfoFromCustomEndpoint(ctx context.Context,
 
 // RevokeToken revokes an access or refresh token
 func (m *OIDCAuthMiddleware) RevokeToken(ctx context.Context, token string) error {
+	if strings.HasPrefix(token, SessionTokenPrefix) {
+		return m.RevokeSession(ctx, sessionID(token))
+	}
+
 	// Note: Token revocation endpoint is not part of standard OIDC discovery
 	// Many providers support it as an extension
 	// For now, we'll just remove from local store
@@ -805,18 +870,24 @@ func (m *OIDCAuthMiddleware) RevokeToken(ctx context.Context, token string) erro
 	return nil
 }
 
-// ClearTokenStore clears all stored tokens
+// ClearTokenStore clears all stored tokens and sessions
 func (m *OIDCAuthMiddleware) ClearTokenStore() {
 	m.tokenStore.mutex.Lock()
 	defer m.tokenStore.mutex.Unlock()
 	m.tokenStore.tokens = make(map[string]*TokenInfo)
+	m.tokenStore.sessions = make(map[string]*Session)
+	if m.sessionStore != nil {
+		if err := m.sessionStore.DeleteAll(); err != nil {
+			log.Printf("Failed to delete persisted OIDC sessions: %v", err)
+		}
+	}
 }
 
-// GetStoredTokenCount returns the number of stored tokens
+// GetStoredTokenCount returns the number of stored tokens and sessions
 func (m *OIDCAuthMiddleware) GetStoredTokenCount() int {
 	m.tokenStore.mutex.RLock()
 	defer m.tokenStore.mutex.RUnlock()
-	return len(m.tokenStore.tokens)
+	return len(m.tokenStore.tokens) + len(m.tokenStore.sessions)
 }
 
 // openBrowser opens the default web browser to the specified URL
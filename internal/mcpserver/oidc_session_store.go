@@ -0,0 +1,187 @@
+package mcpserver
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// SessionPersistence stores OIDC sessions across restarts
+type SessionPersistence interface {
+	Save(session *Session) error
+	Delete(id string) error
+	DeleteAll() error
+	Load() ([]*Session, error)
+	Close() error
+}
+
+// storedSession is the encrypted payload of a session row
+type storedSession struct {
+	Session
+	Token *TokenInfo `json:"token"`
+}
+
+// SQLiteSessionStore keeps sessions in SQLite. Everything except the session ID and
+// expiry is encrypted with AES-256-GCM, so the database holds no usable tokens.
+type SQLiteSessionStore struct {
+	db   *sql.DB
+	aead cipher.AEAD
+}
+
+// DefaultSessionDBPath returns ~/.ragent/mcp_sessions.db, next to the metrics database
+func DefaultSessionDBPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".ragent", "mcp_sessions.db"), nil
+}
+
+// ParseSessionKey decodes MCP_OIDC_SESSION_KEY, a base64-encoded 32-byte key
+func ParseSessionKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("session key must be base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("session key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// NewSQLiteSessionStore opens or creates the session database at dbPath
+func NewSQLiteSessionStore(dbPath string, key []byte) (*SQLiteSessionStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session cipher: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session database: %w", err)
+	}
+
+	createTableSQL := `
+		CREATE TABLE IF NOT EXISTS oidc_sessions (
+			id TEXT PRIMARY KEY,
+			expires_at INTEGER NOT NULL,
+			data BLOB NOT NULL
+		);
+	`
+	if _, err := db.Exec(createTableSQL); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create session table: %w", err)
+	}
+
+	return &SQLiteSessionStore{db: db, aead: aead}, nil
+}
+
+// Save inserts or replaces a session
+func (s *SQLiteSessionStore) Save(session *Session) error {
+	plaintext, err := json.Marshal(storedSession{Session: *session, Token: session.Token})
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	// The session ID is bound as additional data so rows cannot be swapped
+	data := s.aead.Seal(nonce, nonce, plaintext, []byte(session.ID))
+
+	_, err = s.db.Exec(
+		`INSERT INTO oidc_sessions (id, expires_at, data) VALUES (?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET expires_at = excluded.expires_at, data = excluded.data`,
+		session.ID, session.ExpiresAt.Unix(), data,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// Delete removes one session
+func (s *SQLiteSessionStore) Delete(id string) error {
+	if _, err := s.db.Exec("DELETE FROM oidc_sessions WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes every session
+func (s *SQLiteSessionStore) DeleteAll() error {
+	if _, err := s.db.Exec("DELETE FROM oidc_sessions"); err != nil {
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	return nil
+}
+
+// Load returns the unexpired sessions and purges the expired ones. Rows that cannot be
+// decrypted, e.g. after the key changed, are skipped.
+func (s *SQLiteSessionStore) Load() ([]*Session, error) {
+	if _, err := s.db.Exec("DELETE FROM oidc_sessions WHERE expires_at <= ?", time.Now().Unix()); err != nil {
+		return nil, fmt.Errorf("failed to purge expired sessions: %w", err)
+	}
+
+	rows, err := s.db.Query("SELECT id, data FROM oidc_sessions")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var sessions []*Session
+	for rows.Next() {
+		var id string
+		var data []byte
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		session, err := s.decrypt(id, data)
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+func (s *SQLiteSessionStore) decrypt(id string, data []byte) (*Session, error) {
+	nonceSize := s.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("session data is too short")
+	}
+	plaintext, err := s.aead.Open(nil, data[:nonceSize], data[nonceSize:], []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt session: %w", err)
+	}
+	var stored storedSession
+	if err := json.Unmarshal(plaintext, &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	session := stored.Session
+	session.Token = stored.Token
+	return &session, nil
+}
+
+// Close closes the database connection
+func (s *SQLiteSessionStore) Close() error {
+	return s.db.Close()
+}
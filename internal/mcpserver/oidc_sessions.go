@@ -0,0 +1,467 @@
+package mcpserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// SessionTokenPrefix marks the opaque session tokens handed out by /callback
+	SessionTokenPrefix = "rgs_"
+
+	defaultSessionTTL    = 7 * 24 * time.Hour
+	defaultRefreshBefore = 5 * time.Minute
+)
+
+// errSessionRevoked reports a session removed before or while its tokens were refreshed
+var errSessionRevoked = errors.New("session was revoked")
+
+// Session is a signed-in user. Clients hold an opaque session token; the server keeps the
+// IdP tokens and rotates them with the refresh token, so the client token stays valid.
+type Session struct {
+	ID          string    `json:"id"` // SHA-256 of the session token
+	Subject     string    `json:"subject"`
+	Email       string    `json:"email,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	RefreshedAt time.Time `json:"refreshed_at,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at"` // End of the session (MCP_OIDC_SESSION_TTL)
+
+	Token *TokenInfo `json:"-"`
+}
+
+// SessionSummary is the admin view of a session
+type SessionSummary struct {
+	ID             string    `json:"id"`
+	Subject        string    `json:"subject"`
+	Email          string    `json:"email,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	RefreshedAt    time.Time `json:"refreshed_at,omitempty"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	TokenExpiresAt time.Time `json:"token_expires_at"`
+	Refreshable    bool      `json:"refreshable"`
+}
+
+func sessionID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// initSessions loads persisted sessions into the token store
+func (m *OIDCAuthMiddleware) initSessions(config *OIDCConfig) error {
+	m.sessionTTL = config.SessionTTL
+	if m.sessionTTL <= 0 {
+		m.sessionTTL = defaultSessionTTL
+	}
+	m.refreshBefore = config.RefreshBefore
+	if m.refreshBefore <= 0 {
+		m.refreshBefore = defaultRefreshBefore
+	}
+	m.sessionAdmins = make(map[string]bool, len(config.SessionAdmins))
+	for _, admin := range config.SessionAdmins {
+		m.sessionAdmins[admin] = true
+	}
+
+	m.sessionStore = config.SessionStore
+	if m.sessionStore == nil {
+		return nil
+	}
+	sessions, err := m.sessionStore.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load OIDC sessions: %w", err)
+	}
+	m.tokenStore.mutex.Lock()
+	for _, session := range sessions {
+		if session.Token != nil {
+			m.tokenStore.sessions[session.ID] = session
+		}
+	}
+	m.tokenStore.mutex.Unlock()
+	if m.enableLogging {
+		log.Printf("Restored %d OIDC sessions", len(sessions))
+	}
+	return nil
+}
+
+// createSession stores tokenInfo under a new session token and returns the token
+func (m *OIDCAuthMiddleware) createSession(tokenInfo *TokenInfo) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+	token := SessionTokenPrefix + hex.EncodeToString(b)
+
+	now := time.Now()
+	session := &Session{
+		ID:        sessionID(token),
+		Subject:   tokenInfo.Subject,
+		Email:     tokenInfo.Email,
+		CreatedAt: now,
+		ExpiresAt: now.Add(m.sessionTTL),
+		Token:     tokenInfo,
+	}
+	m.tokenStore.mutex.Lock()
+	m.tokenStore.sessions[session.ID] = session
+	m.tokenStore.mutex.Unlock()
+	m.persistSession(session)
+	return token, nil
+}
+
+func (m *OIDCAuthMiddleware) persistSession(session *Session) {
+	if m.sessionStore == nil {
+		return
+	}
+	if err := m.sessionStore.Save(session); err != nil {
+		log.Printf("Failed to persist OIDC session: %v", err)
+	}
+}
+
+// validateSession resolves a session token, refreshing the IdP tokens when they are
+// about to expire
+func (m *OIDCAuthMiddleware) validateSession(ctx context.Context, token string) (*TokenInfo, error) {
+	id := sessionID(token)
+	m.tokenStore.mutex.RLock()
+	session, ok := m.tokenStore.sessions[id]
+	var info *TokenInfo
+	var expiresAt time.Time
+	if ok {
+		info, expiresAt = session.Token, session.ExpiresAt
+	}
+	m.tokenStore.mutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown session")
+	}
+
+	if !time.Now().Before(expiresAt) {
+		m.removeSession(id)
+		return nil, fmt.Errorf("session expired")
+	}
+	// Providers that omit expires_in leave ExpiresAt unset; the session TTL applies alone
+	if info.ExpiresAt.IsZero() || time.Until(info.ExpiresAt) > m.refreshBefore {
+		return info, nil
+	}
+
+	refreshed, err := m.refreshSession(ctx, id)
+	if err != nil {
+		if errors.Is(err, errSessionRevoked) {
+			return nil, err
+		}
+		if time.Now().Before(info.ExpiresAt) {
+			// Still usable; the next request or the background refresher retries
+			log.Printf("OIDC session refresh failed, using current token: %v", err)
+			return info, nil
+		}
+		m.removeSession(id)
+		return nil, fmt.Errorf("session token expired and refresh failed: %w", err)
+	}
+	return refreshed, nil
+}
+
+// refreshSession exchanges the refresh token for new tokens. Providers that rotate
+// refresh tokens return a new one, which replaces the old one.
+func (m *OIDCAuthMiddleware) refreshSession(ctx context.Context, id string) (*TokenInfo, error) {
+	lock, _ := m.refreshLocks.LoadOrStore(id, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	m.tokenStore.mutex.RLock()
+	session, ok := m.tokenStore.sessions[id]
+	var current *TokenInfo
+	if ok {
+		current = session.Token
+	}
+	m.tokenStore.mutex.RUnlock()
+	if !ok {
+		return nil, errSessionRevoked
+	}
+	// Another request may have refreshed the session while we waited
+	if time.Until(current.ExpiresAt) > m.refreshBefore {
+		return current, nil
+	}
+	if current.RefreshToken == "" {
+		return nil, fmt.Errorf("session has no refresh token")
+	}
+
+	m.mutex.RLock()
+	oauth2Config := m.oauth2Config
+	m.mutex.RUnlock()
+	token, err := oauth2Config.TokenSource(ctx, &oauth2.Token{RefreshToken: current.RefreshToken}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	refreshed := *current
+	refreshed.AccessToken = token.AccessToken
+	refreshed.ExpiresAt = token.Expiry
+	if token.RefreshToken != "" {
+		refreshed.RefreshToken = token.RefreshToken
+	}
+	if rawIDToken, ok := token.Extra("id_token").(string); ok && rawIDToken != "" {
+		if info, err := m.verifyIDToken(ctx, rawIDToken); err == nil {
+			refreshed.IDToken = rawIDToken
+			refreshed.Claims = info.Claims
+			if refreshed.ExpiresAt.IsZero() {
+				refreshed.ExpiresAt = info.ExpiresAt
+			}
+		} else {
+			log.Printf("Ignoring invalid ID token in refresh response: %v", err)
+		}
+	}
+
+	// A session revoked during the exchange must stay revoked. It is persisted under the
+	// lock so that removeSession deletes it from the store after this save, never before.
+	m.tokenStore.mutex.Lock()
+	if m.tokenStore.sessions[id] != session {
+		m.tokenStore.mutex.Unlock()
+		return nil, errSessionRevoked
+	}
+	session.Token = &refreshed
+	session.RefreshedAt = time.Now()
+	m.persistSession(session)
+	m.tokenStore.mutex.Unlock()
+
+	if m.enableLogging {
+		log.Printf("Refreshed OIDC session for user: %s", refreshed.Email)
+	}
+	return &refreshed, nil
+}
+
+// verifyIDToken checks an ID token from a refresh response like validateToken does
+func (m *OIDCAuthMiddleware) verifyIDToken(ctx context.Context, rawIDToken string) (*TokenInfo, error) {
+	if m.verifier == nil {
+		return m.validateTokenWithoutVerifier(rawIDToken)
+	}
+	idToken, err := m.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify ID token: %w", err)
+	}
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to extract claims: %w", err)
+	}
+	if err := m.authorizeToken(idToken.Audience, claims); err != nil {
+		return nil, err
+	}
+	return &TokenInfo{Claims: claims, ExpiresAt: idToken.Expiry, Subject: idToken.Subject}, nil
+}
+
+// RefreshExpiringSessions refreshes every session whose tokens expire within the refresh window
+func (m *OIDCAuthMiddleware) RefreshExpiringSessions(ctx context.Context) {
+	now := time.Now()
+	var due, expired []string
+	m.tokenStore.mutex.RLock()
+	for id, session := range m.tokenStore.sessions {
+		switch {
+		case !now.Before(session.ExpiresAt):
+			expired = append(expired, id)
+		case session.Token.RefreshToken != "" && !session.Token.ExpiresAt.IsZero() && session.Token.ExpiresAt.Sub(now) <= m.refreshBefore:
+			due = append(due, id)
+		}
+	}
+	m.tokenStore.mutex.RUnlock()
+
+	for _, id := range expired {
+		m.removeSession(id)
+	}
+	for _, id := range due {
+		if _, err := m.refreshSession(ctx, id); err != nil {
+			if errors.Is(err, errSessionRevoked) {
+				continue
+			}
+			log.Printf("Background OIDC session refresh failed: %v", err)
+			// A failed refresh is only tolerated while the current access token is valid
+			if m.sessionTokenExpired(id) {
+				m.removeSession(id)
+			}
+		}
+	}
+}
+
+// sessionTokenExpired reports whether the access token of a stored session has expired
+func (m *OIDCAuthMiddleware) sessionTokenExpired(id string) bool {
+	m.tokenStore.mutex.RLock()
+	defer m.tokenStore.mutex.RUnlock()
+	session, ok := m.tokenStore.sessions[id]
+	return ok && !time.Now().Before(session.Token.ExpiresAt)
+}
+
+// StartSessionRefresher refreshes expiring sessions every interval until ctx is done
+func (m *OIDCAuthMiddleware) StartSessionRefresher(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.RefreshExpiringSessions(ctx)
+			}
+		}
+	}()
+}
+
+func (m *OIDCAuthMiddleware) removeSession(id string) *Session {
+	m.tokenStore.mutex.Lock()
+	session := m.tokenStore.sessions[id]
+	delete(m.tokenStore.sessions, id)
+	m.tokenStore.mutex.Unlock()
+	m.refreshLocks.Delete(id)
+	if m.sessionStore != nil {
+		if err := m.sessionStore.Delete(id); err != nil {
+			log.Printf("Failed to delete persisted OIDC session: %v", err)
+		}
+	}
+	return session
+}
+
+// RevokeSession ends a session and, when the provider advertises a revocation endpoint,
+// revokes its refresh token there as well
+func (m *OIDCAuthMiddleware) RevokeSession(ctx context.Context, id string) error {
+	session := m.removeSession(id)
+	if session == nil {
+		return fmt.Errorf("session not found")
+	}
+	if session.Token != nil && session.Token.RefreshToken != "" {
+		if err := m.revokeAtProvider(ctx, session.Token.RefreshToken); err != nil {
+			log.Printf("Failed to revoke refresh token at provider: %v", err)
+		}
+	}
+	if m.enableLogging {
+		log.Printf("OIDC session revoked for user: %s", session.Email)
+	}
+	return nil
+}
+
+// revokeAtProvider calls the RFC 7009 revocation endpoint from the provider discovery document
+func (m *OIDCAuthMiddleware) revokeAtProvider(ctx context.Context, refreshToken string) error {
+	if m.provider == nil {
+		return nil
+	}
+	var discovery struct {
+		RevocationEndpoint string `json:"revocation_endpoint"`
+	}
+	if err := m.provider.Claims(&discovery); err != nil || discovery.RevocationEndpoint == "" {
+		return nil
+	}
+
+	form := url.Values{"token": {refreshToken}, "token_type_hint": {"refresh_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.RevocationEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(m.oauth2Config.ClientID), url.QueryEscape(m.oauth2Config.ClientSecret))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("revocation endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// ListSessions returns the active sessions, oldest first
+func (m *OIDCAuthMiddleware) ListSessions() []SessionSummary {
+	m.tokenStore.mutex.RLock()
+	summaries := make([]SessionSummary, 0, len(m.tokenStore.sessions))
+	for _, session := range m.tokenStore.sessions {
+		summaries = append(summaries, SessionSummary{
+			ID:             session.ID,
+			Subject:        session.Subject,
+			Email:          session.Email,
+			CreatedAt:      session.CreatedAt,
+			RefreshedAt:    session.RefreshedAt,
+			ExpiresAt:      session.ExpiresAt,
+			TokenExpiresAt: session.Token.ExpiresAt,
+			Refreshable:    session.Token.RefreshToken != "",
+		})
+	}
+	m.tokenStore.mutex.RUnlock()
+
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].CreatedAt.Before(summaries[j].CreatedAt) })
+	return summaries
+}
+
+// SessionsResponse is the body of GET /admin/sessions
+type SessionsResponse struct {
+	Principal string           `json:"principal"`
+	Admin     bool             `json:"admin"`
+	Sessions  []SessionSummary `json:"sessions"`
+}
+
+// HandleSessions serves /admin/sessions. GET lists sessions, DELETE ?id= revokes one and
+// DELETE ?all=true revokes every session. MCP_SESSION_ADMINS manage all sessions; other
+// callers only see and revoke their own.
+func (m *OIDCAuthMiddleware) HandleSessions(w http.ResponseWriter, r *http.Request) {
+	principal := principalFromContext(r.Context(), nil)
+	admin := m.sessionAdmins[principal]
+	owns := func(session SessionSummary) bool {
+		return admin || principal == "oidc:"+session.Subject
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		response := SessionsResponse{Principal: principal, Admin: admin, Sessions: []SessionSummary{}}
+		for _, session := range m.ListSessions() {
+			if owns(session) {
+				response.Sessions = append(response.Sessions, session)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Printf("Failed to encode sessions response: %v", err)
+		}
+
+	case http.MethodDelete:
+		if r.URL.Query().Get("all") == "true" {
+			if !admin {
+				http.Error(w, "only MCP_SESSION_ADMINS can revoke all sessions", http.StatusForbidden)
+				return
+			}
+			// Each refresh token is revoked at the provider too, and tokens cached for
+			// clients without a session stay. A session removed meanwhile is already gone.
+			for _, session := range m.ListSessions() {
+				_ = m.RevokeSession(r.Context(), session.ID)
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		id := r.URL.Query().Get("id")
+		for _, session := range m.ListSessions() {
+			if session.ID != id || !owns(session) {
+				continue
+			}
+			if err := m.RevokeSession(r.Context(), id); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		http.Error(w, "session not found", http.StatusNotFound)
+
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
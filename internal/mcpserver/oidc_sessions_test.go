@@ -0,0 +1,310 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSessionKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return key
+}
+
+func newSessionMiddleware(t *testing.T, tokenURL string, store SessionPersistence, admins ...string) *OIDCAuthMiddleware {
+	t.Helper()
+	middleware, err := NewOIDCAuthMiddleware(&OIDCConfig{
+		ClientID:         "ragent-client",
+		AuthorizationURL: "http://localhost/auth",
+		TokenURL:         tokenURL,
+		SkipDiscovery:    true,
+		SessionStore:     store,
+		SessionAdmins:    admins,
+	})
+	require.NoError(t, err)
+	return middleware
+}
+
+func TestSQLiteSessionStoreEncryptsSessions(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "sessions.db")
+	key := newSessionKey(t)
+	store, err := NewSQLiteSessionStore(dbPath, key)
+	require.NoError(t, err)
+
+	session := &Session{
+		ID:        sessionID("rgs_test"),
+		Subject:   "alice",
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+		Token:     &TokenInfo{Subject: "alice", RefreshToken: "refresh-secret", ExpiresAt: time.Now().Add(time.Hour)},
+	}
+	require.NoError(t, store.Save(session))
+	require.NoError(t, store.Save(&Session{ID: "expired", ExpiresAt: time.Now().Add(-time.Minute), Token: &TokenInfo{}}))
+
+	sessions, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, sessions, 1, "expired sessions are purged on load")
+	assert.Equal(t, "alice", sessions[0].Subject)
+	assert.Equal(t, "refresh-secret", sessions[0].Token.RefreshToken)
+	require.NoError(t, store.Close())
+
+	data, err := os.ReadFile(dbPath)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(data, []byte("refresh-secret")), "tokens must be encrypted at rest")
+
+	// A different key cannot read the rows
+	other, err := NewSQLiteSessionStore(dbPath, newSessionKey(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = other.Close() })
+	sessions, err = other.Load()
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	_, err = ParseSessionKey(base64.StdEncoding.EncodeToString(key[:16]))
+	assert.Error(t, err)
+	parsed, err := ParseSessionKey(base64.StdEncoding.EncodeToString(key))
+	require.NoError(t, err)
+	assert.Equal(t, key, parsed)
+}
+
+func TestOIDCSessionRefreshRotatesTokens(t *testing.T) {
+	var refreshes atomic.Int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh_token", r.Form.Get("grant_type"))
+		assert.Equal(t, "refresh-1", r.Form.Get("refresh_token"), "the rotated-out refresh token must not be reused")
+		refreshes.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "access-2",
+			"refresh_token": "refresh-2",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+		})
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	store, err := NewSQLiteSessionStore(filepath.Join(t.TempDir(), "sessions.db"), newSessionKey(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	middleware := newSessionMiddleware(t, tokenServer.URL, store)
+	sessionToken, err := middleware.createSession(&TokenInfo{
+		Subject:      "alice",
+		AccessToken:  "access-1",
+		RefreshToken: "refresh-1",
+		ExpiresAt:    time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	info, err := middleware.validateToken(sessionToken)
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", info.AccessToken)
+	assert.Equal(t, "refresh-2", info.RefreshToken)
+	assert.Equal(t, int32(1), refreshes.Load())
+
+	_, err = middleware.validateToken(sessionToken)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), refreshes.Load(), "fresh tokens are not refreshed again")
+
+	// A restarted server picks the rotated tokens up from the store
+	restarted := newSessionMiddleware(t, tokenServer.URL, store)
+	info, err = restarted.validateToken(sessionToken)
+	require.NoError(t, err)
+	assert.Equal(t, "refresh-2", info.RefreshToken)
+	assert.Equal(t, "alice", info.Subject)
+}
+
+func TestOIDCSessionExpiry(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+	}))
+	t.Cleanup(failing.Close)
+	middleware := newSessionMiddleware(t, failing.URL, nil)
+
+	_, err := middleware.validateToken(SessionTokenPrefix + "unknown")
+	assert.ErrorContains(t, err, "unknown session")
+
+	ended, err := middleware.createSession(&TokenInfo{Subject: "alice", ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	middleware.tokenStore.sessions[sessionID(ended)].ExpiresAt = time.Now().Add(-time.Second)
+	_, err = middleware.validateToken(ended)
+	assert.ErrorContains(t, err, "session expired")
+
+	// A refresh failure is tolerated until the current token actually expires
+	expiring, err := middleware.createSession(&TokenInfo{Subject: "alice", RefreshToken: "r", ExpiresAt: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	_, err = middleware.validateToken(expiring)
+	assert.NoError(t, err)
+
+	expired, err := middleware.createSession(&TokenInfo{Subject: "alice", RefreshToken: "r", ExpiresAt: time.Now().Add(-time.Minute)})
+	require.NoError(t, err)
+	_, err = middleware.validateToken(expired)
+	assert.ErrorContains(t, err, "refresh failed")
+	assert.Equal(t, 1, middleware.GetStoredTokenCount(), "failed sessions are removed")
+}
+
+func TestRefreshExpiringSessionsRemovesExpiredSessionsAfterFailedRefresh(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+	}))
+	t.Cleanup(failing.Close)
+	store, err := NewSQLiteSessionStore(filepath.Join(t.TempDir(), "sessions.db"), newSessionKey(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	middleware := newSessionMiddleware(t, failing.URL, store)
+
+	valid, err := middleware.createSession(&TokenInfo{Subject: "alice", RefreshToken: "r", ExpiresAt: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	expired, err := middleware.createSession(&TokenInfo{Subject: "bob", RefreshToken: "r", ExpiresAt: time.Now().Add(-time.Minute)})
+	require.NoError(t, err)
+
+	middleware.RefreshExpiringSessions(context.Background())
+	assert.NotContains(t, middleware.tokenStore.sessions, sessionID(expired))
+	assert.Contains(t, middleware.tokenStore.sessions, sessionID(valid))
+
+	_, err = middleware.validateToken(valid)
+	assert.NoError(t, err, "a session whose token is still valid survives a failed refresh")
+	_, err = middleware.validateToken(expired)
+	assert.ErrorContains(t, err, "unknown session")
+
+	restarted := newSessionMiddleware(t, failing.URL, store)
+	_, err = restarted.validateToken(expired)
+	assert.ErrorContains(t, err, "unknown session", "the removed session must not be restored from the store")
+}
+
+func TestHandleSessionsAdminEndpoints(t *testing.T) {
+	middleware := newSessionMiddleware(t, "http://localhost/token", nil, "oidc:admin")
+	_, err := middleware.createSession(&TokenInfo{Subject: "alice", Email: "alice@example.com", ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	bobToken, err := middleware.createSession(&TokenInfo{Subject: "bob", ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	bobID := sessionID(bobToken)
+
+	serve := func(subject, method, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req = req.WithContext(context.WithValue(req.Context(), userContextKey, &TokenInfo{Subject: subject}))
+		rec := httptest.NewRecorder()
+		middleware.HandleSessions(rec, req)
+		return rec
+	}
+	list := func(subject string) SessionsResponse {
+		rec := serve(subject, http.MethodGet, "/admin/sessions")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var response SessionsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response
+	}
+
+	alice := list("alice")
+	assert.False(t, alice.Admin)
+	require.Len(t, alice.Sessions, 1)
+	assert.Equal(t, "alice@example.com", alice.Sessions[0].Email)
+	assert.Len(t, list("admin").Sessions, 2)
+
+	assert.Equal(t, http.StatusNotFound, serve("alice", http.MethodDelete, "/admin/sessions?id="+bobID).Code)
+	assert.Equal(t, http.StatusForbidden, serve("alice", http.MethodDelete, "/admin/sessions?all=true").Code)
+
+	assert.Equal(t, http.StatusNoContent, serve("admin", http.MethodDelete, "/admin/sessions?id="+bobID).Code)
+	_, err = middleware.validateToken(bobToken)
+	assert.Error(t, err, "revoked sessions stop working")
+	assert.Len(t, list("admin").Sessions, 1)
+
+	middleware.tokenStore.tokens["cached"] = &TokenInfo{Subject: "carol"}
+	assert.Equal(t, http.StatusNoContent, serve("admin", http.MethodDelete, "/admin/sessions?all=true").Code)
+	assert.Empty(t, list("admin").Sessions)
+	assert.Contains(t, middleware.tokenStore.tokens, "cached", "revoking sessions keeps tokens cached without a session")
+}
+
+func TestOIDCSessionRevokedDuringRefreshStaysRevoked(t *testing.T) {
+	var middleware *OIDCAuthMiddleware
+	var sessionToken string
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, middleware.RevokeSession(r.Context(), sessionID(sessionToken)))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "access-2",
+			"refresh_token": "refresh-2",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+		})
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	store, err := NewSQLiteSessionStore(filepath.Join(t.TempDir(), "sessions.db"), newSessionKey(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+
+	middleware = newSessionMiddleware(t, tokenServer.URL, store)
+	sessionToken, err = middleware.createSession(&TokenInfo{
+		Subject:      "alice",
+		RefreshToken: "refresh-1",
+		ExpiresAt:    time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	_, err = middleware.validateToken(sessionToken)
+	assert.ErrorIs(t, err, errSessionRevoked)
+	assert.Equal(t, 0, middleware.GetStoredTokenCount())
+	sessions, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, sessions, "the refresh must not persist a revoked session")
+}
+
+func TestOIDCSessionRefreshesRunPerSession(t *testing.T) {
+	aliceWaiting := make(chan struct{})
+	bobRefreshed := make(chan struct{})
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		switch r.Form.Get("refresh_token") {
+		case "refresh-alice":
+			close(aliceWaiting)
+			select {
+			case <-bobRefreshed:
+			case <-time.After(5 * time.Second):
+				t.Error("a slow refresh must not block the refresh of another session")
+			}
+		case "refresh-bob":
+			close(bobRefreshed)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access-2",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	middleware := newSessionMiddleware(t, tokenServer.URL, nil)
+	alice, err := middleware.createSession(&TokenInfo{Subject: "alice", RefreshToken: "refresh-alice", ExpiresAt: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	bob, err := middleware.createSession(&TokenInfo{Subject: "bob", RefreshToken: "refresh-bob", ExpiresAt: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := middleware.validateToken(alice)
+		done <- err
+	}()
+	<-aliceWaiting
+	info, err := middleware.validateToken(bob)
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", info.AccessToken)
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,378 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/slack-go/slack"
+
+	"github.com/ca-srg/ragent/internal/pkg/slacksearch"
+	"github.com/ca-srg/ragent/internal/query/search"
+)
+
+const (
+	answerPromptName   = "answer_with_citations"
+	incidentPromptName = "summarize_slack_incident"
+	comparePromptName  = "compare_documents"
+
+	maxPromptTopK          = 20
+	incidentRelatedDocs    = 5
+	incidentQueryMaxLength = 200
+)
+
+// PromptSearcher retrieves fresh document context for prompts
+type PromptSearcher interface {
+	Search(ctx context.Context, request *search.SearchRequest) (*search.SearchResponse, error)
+}
+
+// SlackThreadFetcher fetches the Slack messages linked from a prompt argument
+type SlackThreadFetcher interface {
+	FetchMessagesFromQuery(ctx context.Context, query string) (*slacksearch.FetchResponse, error)
+}
+
+// PromptProvider serves server-defined RAG prompts whose messages embed context retrieved
+// at request time. Retrieval applies the caller's secret, tenant and group access.
+type PromptProvider struct {
+	searcher  PromptSearcher
+	documents *DocumentResourceProvider
+	slack     SlackThreadFetcher
+	config    *HybridSearchConfig
+	logger    *log.Logger
+}
+
+// PromptDefinition pairs a prompt with its handler for registration
+type PromptDefinition struct {
+	Prompt  *mcp.Prompt
+	Handler mcp.PromptHandler
+}
+
+// NewPromptProvider creates a prompt provider. slackFetcher may be nil, in which case the
+// Slack incident prompt is not offered.
+func NewPromptProvider(searcher PromptSearcher, documents *DocumentResourceProvider, slackFetcher SlackThreadFetcher, config *HybridSearchConfig) *PromptProvider {
+	return &PromptProvider{
+		searcher:  searcher,
+		documents: documents,
+		slack:     slackFetcher,
+		config:    config,
+		logger:    log.New(log.Writer(), "[Prompts] ", log.LstdFlags),
+	}
+}
+
+// Definitions returns the prompts offered by the provider
+func (p *PromptProvider) Definitions() []PromptDefinition {
+	definitions := []PromptDefinition{
+		{
+			Prompt: &mcp.Prompt{
+				Name:        answerPromptName,
+				Title:       "Answer from internal docs with citations",
+				Description: "Searches the knowledge base for the question and asks for an answer grounded in the retrieved documents, citing them by number.",
+				Arguments: []*mcp.PromptArgument{
+					{Name: "question", Title: "Question", Description: "The question to answer", Required: true},
+					{Name: "category", Title: "Category", Description: "Only use documents of this category (exact match)"},
+					{Name: "top_k", Title: "Documents", Description: fmt.Sprintf("Number of documents to retrieve (integer 1-%d)", maxPromptTopK)},
+				},
+			},
+			Handler: p.handleAnswer,
+		},
+		{
+			Prompt: &mcp.Prompt{
+				Name:        comparePromptName,
+				Title:       "Compare two documents",
+				Description: "Loads two indexed documents in full and asks for a structured comparison.",
+				Arguments: []*mcp.PromptArgument{
+					{Name: "document_a", Title: "First document", Description: "ragent://doc URI or document ID", Required: true},
+					{Name: "document_b", Title: "Second document", Description: "ragent://doc URI or document ID", Required: true},
+					{Name: "aspect", Title: "Aspect", Description: "What to focus the comparison on, e.g. \"rollback procedure\""},
+				},
+			},
+			Handler: p.handleCompare,
+		},
+	}
+
+	if p.slack != nil {
+		definitions = append(definitions, PromptDefinition{
+			Prompt: &mcp.Prompt{
+				Name:        incidentPromptName,
+				Title:       "Summarize Slack incident thread",
+				Description: "Fetches a Slack incident thread and related runbooks and asks for an incident summary.",
+				Arguments: []*mcp.PromptArgument{
+					{Name: "thread_url", Title: "Thread URL", Description: "Slack permalink of the incident thread", Required: true},
+					{Name: "focus", Title: "Focus", Description: "Optional focus, e.g. \"customer impact\" or \"follow-up actions\""},
+				},
+			},
+			Handler: p.handleIncident,
+		})
+	}
+
+	return definitions
+}
+
+func (p *PromptProvider) handleAnswer(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := promptArguments(req)
+	question := strings.TrimSpace(args["question"])
+	if question == "" {
+		return nil, fmt.Errorf("argument question is required")
+	}
+	topK, err := parsePromptTopK(args["top_k"], p.defaultTopK())
+	if err != nil {
+		return nil, err
+	}
+
+	var filters map[string]string
+	if category := strings.TrimSpace(args["category"]); category != "" {
+		filters = map[string]string{"category": category}
+	}
+
+	response, err := p.searchDocuments(ctx, question, topK, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("Answer the question using only the internal documents below. ")
+	b.WriteString("Cite the documents you rely on as [1], [2], ... and list the cited references at the end. ")
+	b.WriteString("If the documents do not contain the answer, say so instead of guessing. ")
+	b.WriteString("Answer in the language of the question.\n\n")
+	fmt.Fprintf(&b, "Question: %s\n\n", question)
+	writeSearchContext(&b, response)
+
+	return &mcp.GetPromptResult{
+		Description: fmt.Sprintf("Answer with citations for %q", question),
+		Messages:    []*mcp.PromptMessage{userPromptMessage(b.String())},
+	}, nil
+}
+
+func (p *PromptProvider) handleIncident(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	if p.slack == nil {
+		return nil, fmt.Errorf("slack search is not configured")
+	}
+	args := promptArguments(req)
+	threadURL := strings.TrimSpace(args["thread_url"])
+	if threadURL == "" {
+		return nil, fmt.Errorf("argument thread_url is required")
+	}
+	if !slacksearch.HasSlackURL(threadURL) {
+		return nil, fmt.Errorf("argument thread_url must be a Slack message permalink")
+	}
+
+	fetched, err := p.slack.FetchMessagesFromQuery(ctx, threadURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Slack thread: %w", err)
+	}
+	if fetched == nil || len(fetched.EnrichedMessages) == 0 {
+		return nil, fmt.Errorf("no Slack messages found for %s", threadURL)
+	}
+	thread := fetched.EnrichedMessages[0]
+
+	var b strings.Builder
+	b.WriteString("Summarize the Slack incident thread below for an incident report. ")
+	b.WriteString("Cover the timeline, impact, suspected or confirmed root cause, actions taken and open follow-ups. ")
+	b.WriteString("Point out where the related internal documents (cited as [1], [2], ...) confirm or contradict the thread. ")
+	b.WriteString("Answer in the language of the thread.\n\n")
+	if focus := strings.TrimSpace(args["focus"]); focus != "" {
+		fmt.Fprintf(&b, "Focus: %s\n\n", focus)
+	}
+	fmt.Fprintf(&b, "## Slack thread (%s)\n\n", threadURL)
+	for _, msg := range incidentThreadMessages(thread) {
+		fmt.Fprintf(&b, "- [%s] %s: %s\n", msg.Timestamp, selectSlackUser(msg.User, msg.Username), strings.TrimSpace(msg.Text))
+	}
+	b.WriteString("\n")
+
+	if query := incidentSearchQuery(thread); query != "" {
+		response, err := p.searchDocuments(ctx, query, incidentRelatedDocs, nil)
+		if err != nil {
+			// The thread alone still makes a useful prompt
+			p.logger.Printf("Related document search failed: %v", err)
+		} else {
+			writeSearchContext(&b, response)
+		}
+	}
+
+	return &mcp.GetPromptResult{
+		Description: "Incident summary of " + threadURL,
+		Messages:    []*mcp.PromptMessage{userPromptMessage(b.String())},
+	}, nil
+}
+
+func (p *PromptProvider) handleCompare(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	args := promptArguments(req)
+	refA := strings.TrimSpace(args["document_a"])
+	refB := strings.TrimSpace(args["document_b"])
+	if refA == "" || refB == "" {
+		return nil, fmt.Errorf("arguments document_a and document_b are required")
+	}
+
+	filter, err := p.documents.accessFilter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("access denied: %w", err)
+	}
+
+	docs := make([]*DocumentResource, 0, 2)
+	for _, ref := range []string{refA, refB} {
+		id, index := ref, ""
+		if strings.HasPrefix(ref, ResourceScheme+"://") {
+			kind, name, explicitIndex, err := parseResourceURI(ref)
+			if err != nil || kind != "doc" {
+				return nil, fmt.Errorf("%s is not a ragent://doc URI", ref)
+			}
+			id, index = name, explicitIndex
+		}
+		index, linkIndex, err := p.documents.resolveIndex(index, filter.Tenants)
+		if err != nil {
+			return nil, fmt.Errorf("access denied: %w", err)
+		}
+		doc, err := p.documents.readDocument(ctx, index, linkIndex, id, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", ref, err)
+		}
+		if doc == nil {
+			return nil, fmt.Errorf("document %s not found", ref)
+		}
+		docs = append(docs, doc)
+	}
+
+	var b strings.Builder
+	b.WriteString("Compare the two internal documents below. ")
+	b.WriteString("Summarize what each covers, then list agreements, differences and contradictions, and say which is more current when dates allow. ")
+	b.WriteString("Refer to them as A and B. Answer in the language of the documents.\n\n")
+	if aspect := strings.TrimSpace(args["aspect"]); aspect != "" {
+		fmt.Fprintf(&b, "Focus the comparison on: %s\n\n", aspect)
+	}
+	for i, doc := range docs {
+		label := string(rune('A' + i))
+		fmt.Fprintf(&b, "## Document %s: %s\n", label, doc.Title)
+		fmt.Fprintf(&b, "URI: %s\n", doc.URI)
+		if doc.Reference != "" {
+			fmt.Fprintf(&b, "Reference: %s\n", doc.Reference)
+		}
+		if doc.UpdatedAt != "" {
+			fmt.Fprintf(&b, "Updated: %s\n", doc.UpdatedAt)
+		}
+		fmt.Fprintf(&b, "\n%s\n\n", doc.Content)
+	}
+
+	return &mcp.GetPromptResult{
+		Description: fmt.Sprintf("Comparison of %s and %s", docs[0].Title, docs[1].Title),
+		Messages:    []*mcp.PromptMessage{userPromptMessage(b.String())},
+	}, nil
+}
+
+// searchDocuments runs a hybrid search under the caller's access policies
+func (p *PromptProvider) searchDocuments(ctx context.Context, query string, topK int, filters map[string]string) (*search.SearchResponse, error) {
+	if p.searcher == nil {
+		return nil, fmt.Errorf("document search is not configured")
+	}
+	filter, err := p.documents.accessFilter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("access denied: %w", err)
+	}
+
+	request := &search.SearchRequest{
+		Query:           query,
+		IndexName:       p.documents.defaultIndex(filter.Tenants),
+		ContextSize:     topK,
+		Filters:         filters,
+		ExcludeSecret:   filter.ExcludeSecret,
+		Tenants:         filter.Tenants,
+		Groups:          filter.Groups,
+		EnforceGroupACL: true,
+	}
+	if p.config != nil {
+		request.BM25Weight = p.config.DefaultBM25Weight
+		request.VectorWeight = p.config.DefaultVectorWeight
+		request.UseJapaneseNLP = p.config.DefaultUseJapaneseNLP
+		request.TimeoutSeconds = p.config.DefaultTimeoutSeconds
+		request.EfSearch = p.config.DefaultEfSearch
+	}
+
+	response, err := p.searcher.Search(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("document search failed: %w", err)
+	}
+	return response, nil
+}
+
+func (p *PromptProvider) defaultTopK() int {
+	if p.config != nil && p.config.DefaultSize > 0 && p.config.DefaultSize <= maxPromptTopK {
+		return p.config.DefaultSize
+	}
+	return 5
+}
+
+func promptArguments(req *mcp.GetPromptRequest) map[string]string {
+	if req == nil || req.Params == nil || req.Params.Arguments == nil {
+		return map[string]string{}
+	}
+	return req.Params.Arguments
+}
+
+func parsePromptTopK(raw string, fallback int) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return fallback, nil
+	}
+	topK, err := strconv.Atoi(raw)
+	if err != nil || topK < 1 || topK > maxPromptTopK {
+		return 0, fmt.Errorf("argument top_k must be an integer between 1 and %d", maxPromptTopK)
+	}
+	return topK, nil
+}
+
+func userPromptMessage(text string) *mcp.PromptMessage {
+	return &mcp.PromptMessage{Role: "user", Content: &mcp.TextContent{Text: text}}
+}
+
+// writeSearchContext appends the retrieved documents numbered for citation
+func writeSearchContext(b *strings.Builder, response *search.SearchResponse) {
+	b.WriteString("## Internal documents\n\n")
+	if response == nil || len(response.ContextParts) == 0 {
+		b.WriteString("No internal documents matched.\n")
+		return
+	}
+	for i, part := range response.ContextParts {
+		fmt.Fprintf(b, "[%d]\n%s\n\n", i+1, strings.TrimSpace(part))
+	}
+	if len(response.References) == 0 {
+		return
+	}
+
+	titles := make([]string, 0, len(response.References))
+	for title := range response.References {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+	b.WriteString("## References\n\n")
+	for _, title := range titles {
+		fmt.Fprintf(b, "- %s: %s\n", title, response.References[title])
+	}
+}
+
+// incidentThreadMessages returns the root message followed by its replies
+func incidentThreadMessages(thread slacksearch.EnrichedMessage) []slack.Message {
+	messages := []slack.Message{thread.OriginalMessage}
+	for _, reply := range thread.ThreadMessages {
+		if reply.Timestamp == thread.OriginalMessage.Timestamp {
+			continue
+		}
+		messages = append(messages, reply)
+	}
+	return messages
+}
+
+// incidentSearchQuery derives a document search query from the thread's root message
+func incidentSearchQuery(thread slacksearch.EnrichedMessage) string {
+	text := strings.Join(strings.Fields(thread.OriginalMessage.Text), " ")
+	if text == "" && len(thread.ThreadMessages) > 0 {
+		text = strings.Join(strings.Fields(thread.ThreadMessages[0].Text), " ")
+	}
+	runes := []rune(text)
+	if len(runes) > incidentQueryMaxLength {
+		text = string(runes[:incidentQueryMaxLength])
+	}
+	return text
+}
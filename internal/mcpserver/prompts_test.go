@@ -0,0 +1,202 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/slack-go/slack"
+
+	"github.com/ca-srg/ragent/internal/pkg/opensearch"
+	"github.com/ca-srg/ragent/internal/pkg/slacksearch"
+	"github.com/ca-srg/ragent/internal/query/search"
+)
+
+type fakePromptSearcher struct {
+	requests []*search.SearchRequest
+	response *search.SearchResponse
+	err      error
+}
+
+func (s *fakePromptSearcher) Search(ctx context.Context, request *search.SearchRequest) (*search.SearchResponse, error) {
+	s.requests = append(s.requests, request)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.response, nil
+}
+
+type fakeSlackThreadFetcher struct {
+	response *slacksearch.FetchResponse
+}
+
+func (f *fakeSlackThreadFetcher) FetchMessagesFromQuery(ctx context.Context, query string) (*slacksearch.FetchResponse, error) {
+	return f.response, nil
+}
+
+func getPrompt(t *testing.T, handler mcp.PromptHandler, ctx context.Context, args map[string]string) string {
+	t.Helper()
+	result, err := handler(ctx, &mcp.GetPromptRequest{Params: &mcp.GetPromptParams{Arguments: args}})
+	if err != nil {
+		t.Fatalf("get prompt failed: %v", err)
+	}
+	if len(result.Messages) != 1 {
+		t.Fatalf("expected one message, got %d", len(result.Messages))
+	}
+	text, ok := result.Messages[0].Content.(*mcp.TextContent)
+	if !ok {
+		t.Fatalf("expected text content, got %T", result.Messages[0].Content)
+	}
+	return text.Text
+}
+
+func newTestPromptProvider(searcher PromptSearcher, store DocumentStore, slackFetcher SlackThreadFetcher) *PromptProvider {
+	config := &HybridSearchConfig{DefaultIndexName: "docs-index", DefaultSize: 5, DefaultBM25Weight: 0.5, DefaultVectorWeight: 0.5}
+	return NewPromptProvider(searcher, NewDocumentResourceProvider(store, config), slackFetcher, config)
+}
+
+func TestPromptDefinitionsOmitSlackPromptWithoutFetcher(t *testing.T) {
+	provider := newTestPromptProvider(&fakePromptSearcher{}, &fakeDocumentStore{}, nil)
+	for _, definition := range provider.Definitions() {
+		if definition.Prompt.Name == incidentPromptName {
+			t.Fatalf("slack incident prompt must not be offered without slack search")
+		}
+	}
+
+	provider = newTestPromptProvider(&fakePromptSearcher{}, &fakeDocumentStore{}, &fakeSlackThreadFetcher{})
+	if got := len(provider.Definitions()); got != 3 {
+		t.Fatalf("expected 3 prompts with slack search, got %d", got)
+	}
+}
+
+func TestAnswerPromptEmbedsContextWithAccessPolicy(t *testing.T) {
+	searcher := &fakePromptSearcher{response: &search.SearchResponse{
+		ContextParts: []string{"Restart the worker with make restart."},
+		References:   map[string]string{"Worker runbook": "https://example.com/runbook"},
+	}}
+	provider := newTestPromptProvider(searcher, &fakeDocumentStore{}, nil)
+
+	text := getPrompt(t, provider.handleAnswer, context.Background(), map[string]string{
+		"question": "How do I restart the worker?",
+		"category": "Runbook",
+		"top_k":    "3",
+	})
+
+	if !strings.Contains(text, "[1]\nRestart the worker with make restart.") {
+		t.Fatalf("expected numbered context in prompt: %s", text)
+	}
+	if !strings.Contains(text, "- Worker runbook: https://example.com/runbook") {
+		t.Fatalf("expected references in prompt: %s", text)
+	}
+	request := searcher.requests[0]
+	if request.ContextSize != 3 || request.Filters["category"] != "Runbook" || request.IndexName != "docs-index" {
+		t.Fatalf("unexpected search request: %+v", request)
+	}
+	if !request.ExcludeSecret || !request.EnforceGroupACL {
+		t.Fatalf("anonymous prompt searches must exclude secrets and enforce group ACLs: %+v", request)
+	}
+}
+
+func TestAnswerPromptValidatesArguments(t *testing.T) {
+	provider := newTestPromptProvider(&fakePromptSearcher{}, &fakeDocumentStore{}, nil)
+
+	cases := []map[string]string{
+		{},
+		{"question": "q", "top_k": "0"},
+		{"question": "q", "top_k": "many"},
+	}
+	for _, args := range cases {
+		if _, err := provider.handleAnswer(context.Background(), &mcp.GetPromptRequest{Params: &mcp.GetPromptParams{Arguments: args}}); err == nil {
+			t.Fatalf("expected error for arguments %v", args)
+		}
+	}
+}
+
+func TestAnswerPromptNotesEmptyResults(t *testing.T) {
+	provider := newTestPromptProvider(&fakePromptSearcher{response: &search.SearchResponse{}}, &fakeDocumentStore{}, nil)
+
+	text := getPrompt(t, provider.handleAnswer, context.Background(), map[string]string{"question": "unknown"})
+	if !strings.Contains(text, "No internal documents matched.") {
+		t.Fatalf("expected empty result note: %s", text)
+	}
+}
+
+func TestIncidentPromptIncludesThreadAndRelatedDocs(t *testing.T) {
+	fetcher := &fakeSlackThreadFetcher{response: &slacksearch.FetchResponse{EnrichedMessages: []slacksearch.EnrichedMessage{{
+		OriginalMessage: slack.Message{Msg: slack.Msg{Timestamp: "1700000000.000100", User: "U1", Text: "API latency spike in prod"}},
+		ThreadMessages: []slack.Message{
+			{Msg: slack.Msg{Timestamp: "1700000000.000100", User: "U1", Text: "API latency spike in prod"}},
+			{Msg: slack.Msg{Timestamp: "1700000100.000200", User: "U2", Text: "Rolled back deploy"}},
+		},
+	}}}}
+	searcher := &fakePromptSearcher{response: &search.SearchResponse{ContextParts: []string{"Latency runbook"}}}
+	provider := newTestPromptProvider(searcher, &fakeDocumentStore{}, fetcher)
+
+	url := "https://example.slack.com/archives/C123/p1700000000000100"
+	text := getPrompt(t, provider.handleIncident, context.Background(), map[string]string{"thread_url": url, "focus": "customer impact"})
+
+	if strings.Count(text, "API latency spike in prod") != 1 {
+		t.Fatalf("root message should appear once: %s", text)
+	}
+	if !strings.Contains(text, "U2: Rolled back deploy") || !strings.Contains(text, "Focus: customer impact") {
+		t.Fatalf("expected thread replies and focus in prompt: %s", text)
+	}
+	if !strings.Contains(text, "Latency runbook") {
+		t.Fatalf("expected related documents in prompt: %s", text)
+	}
+	if searcher.requests[0].Query != "API latency spike in prod" {
+		t.Fatalf("related search should use the root message, got %q", searcher.requests[0].Query)
+	}
+
+	if _, err := provider.handleIncident(context.Background(), &mcp.GetPromptRequest{Params: &mcp.GetPromptParams{Arguments: map[string]string{"thread_url": "https://example.com"}}}); err == nil {
+		t.Fatalf("expected error for non-Slack URL")
+	}
+}
+
+func TestIncidentPromptToleratesSearchFailure(t *testing.T) {
+	fetcher := &fakeSlackThreadFetcher{response: &slacksearch.FetchResponse{EnrichedMessages: []slacksearch.EnrichedMessage{{
+		OriginalMessage: slack.Message{Msg: slack.Msg{Timestamp: "1", User: "U1", Text: "DB failover"}},
+	}}}}
+	provider := newTestPromptProvider(&fakePromptSearcher{err: fmt.Errorf("opensearch down")}, &fakeDocumentStore{}, fetcher)
+
+	text := getPrompt(t, provider.handleIncident, context.Background(), map[string]string{"thread_url": "https://example.slack.com/archives/C123/p1700000000000100"})
+	if !strings.Contains(text, "DB failover") {
+		t.Fatalf("expected thread in prompt: %s", text)
+	}
+}
+
+func TestComparePromptLoadsBothDocuments(t *testing.T) {
+	store := &fakeDocumentStore{docs: []opensearch.ScannedDocument{
+		storedDoc(t, "old_0", map[string]interface{}{"title": "Deploy v1", "content": "manual deploy", "file_path": "docs/deploy-v1.md", "chunk_index": 0}),
+		storedDoc(t, "new_0", map[string]interface{}{"title": "Deploy v2", "content": "pipeline deploy", "file_path": "docs/deploy-v2.md", "chunk_index": 0}),
+	}}
+	provider := newTestPromptProvider(&fakePromptSearcher{}, store, nil)
+
+	text := getPrompt(t, provider.handleCompare, context.Background(), map[string]string{
+		"document_a": DocumentResourceURI("old_0", ""),
+		"document_b": "new_0",
+		"aspect":     "rollback",
+	})
+
+	if !strings.Contains(text, "## Document A: Deploy v1") || !strings.Contains(text, "manual deploy") {
+		t.Fatalf("expected document A in prompt: %s", text)
+	}
+	if !strings.Contains(text, "## Document B: Deploy v2") || !strings.Contains(text, "pipeline deploy") {
+		t.Fatalf("expected document B in prompt: %s", text)
+	}
+	if !strings.Contains(text, "Focus the comparison on: rollback") {
+		t.Fatalf("expected aspect in prompt: %s", text)
+	}
+
+	_, err := provider.handleCompare(context.Background(), &mcp.GetPromptRequest{Params: &mcp.GetPromptParams{Arguments: map[string]string{"document_a": "old_0", "document_b": "missing"}}})
+	if err == nil {
+		t.Fatalf("expected error for missing document")
+	}
+
+	_, err = provider.handleCompare(context.Background(), &mcp.GetPromptRequest{Params: &mcp.GetPromptParams{Arguments: map[string]string{"document_a": "old_0", "document_b": DocumentResourceURI("new_0", "other-index")}}})
+	if err == nil || !strings.Contains(err.Error(), "access denied") {
+		t.Fatalf("expected access denied for an index outside the caller's indexes, got %v", err)
+	}
+}
@@ -0,0 +1,463 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	appcfg "github.com/ca-srg/ragent/internal/pkg/config"
+	"github.com/ca-srg/ragent/internal/pkg/metrics"
+)
+
+// rateLimitErrorCode is the JSON-RPC error code of throttled calls (implementation-defined server error range)
+const rateLimitErrorCode = -32029
+
+// maxRateLimitBodyBytes bounds how much of a request body is read to find tools/call messages
+const maxRateLimitBodyBytes = 10 << 20
+
+// pruneBucketsAbove is the bucket count above which refilled buckets are dropped
+const pruneBucketsAbove = 10000
+
+// quotaLockStripes is the number of locks that serialize quota checks of principals
+const quotaLockStripes = 64
+
+// UsageStore persists the daily tool calls of each principal. metrics.Store implements it.
+type UsageStore interface {
+	RecordClientUsage(principal, tool string, rejected bool) error
+	GetClientCalls(principal, tool, date string) (int64, error)
+	GetClientUsageByDate(date string) ([]metrics.ClientUsage, error)
+}
+
+// RateLimiter throttles tools/call requests per authenticated principal and tool with
+// token buckets, and enforces daily quotas persisted in the metrics store.
+type RateLimiter struct {
+	perMinute  int
+	burst      int
+	toolLimits map[string]int
+	dailyQuota int
+	toolQuotas map[string]int
+	toolPrefix string
+	bypassNets []*net.IPNet
+	admins     map[string]bool
+	store      UsageStore
+	logger     *log.Logger
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+
+	// quotaLocks keep the quota check and the usage record of a principal together
+	// without making other principals wait for the store
+	quotaLocks [quotaLockStripes]sync.Mutex
+}
+
+// tokenBucket refills continuously at the per-minute rate up to the burst size
+type tokenBucket struct {
+	tokens        float64
+	last          time.Time
+	ratePerSecond float64
+}
+
+// RateLimitDecision reports why a call was rejected and when the client may retry
+type RateLimitDecision struct {
+	Allowed    bool
+	Reason     string // "rate_limit" or "daily_quota"
+	Limit      int
+	RetryAfter time.Duration
+}
+
+// RateLimitSettings are the limits reported on /admin/usage
+type RateLimitSettings struct {
+	PerMinute   int            `json:"per_minute"`
+	Burst       int            `json:"burst"`
+	ToolLimits  map[string]int `json:"tool_limits,omitempty"`
+	DailyQuota  int            `json:"daily_quota"`
+	ToolQuotas  map[string]int `json:"tool_quotas,omitempty"`
+	QuotaResets string         `json:"quota_resets"`
+}
+
+// UsageResponse is the body of /admin/usage
+type UsageResponse struct {
+	Date      string                `json:"date"`
+	Principal string                `json:"principal"`
+	Admin     bool                  `json:"admin"`
+	Limits    RateLimitSettings     `json:"limits"`
+	Usage     []metrics.ClientUsage `json:"usage"`
+}
+
+// UsageSummary is the rate limit section of /health
+type UsageSummary struct {
+	Enabled    bool   `json:"enabled"`
+	Date       string `json:"date"`
+	Principals int    `json:"principals"`
+	Calls      int64  `json:"calls"`
+	Rejected   int64  `json:"rejected"`
+}
+
+// jsonRPCMessage is the part of a JSON-RPC request the limiter needs
+type jsonRPCMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params struct {
+		Name string `json:"name"`
+	} `json:"params"`
+}
+
+// NewRateLimiter creates a limiter from the MCP_RATE_LIMIT_* and MCP_DAILY_QUOTA* settings
+func NewRateLimiter(cfg *appcfg.Config, store UsageStore) (*RateLimiter, error) {
+	if store == nil {
+		return nil, fmt.Errorf("rate limiting requires the metrics store")
+	}
+	rl := &RateLimiter{
+		perMinute:  cfg.MCPRateLimitPerMinute,
+		burst:      cfg.MCPRateLimitBurst,
+		toolLimits: cfg.MCPRateLimitTools,
+		dailyQuota: cfg.MCPDailyQuota,
+		toolQuotas: cfg.MCPDailyQuotaTools,
+		toolPrefix: cfg.MCPToolPrefix,
+		admins:     make(map[string]bool),
+		store:      store,
+		logger:     log.New(log.Writer(), "[RateLimit] ", log.LstdFlags),
+		buckets:    make(map[string]*tokenBucket),
+		now:        time.Now,
+	}
+	for _, entry := range cfg.MCPBypassIPRanges {
+		network, err := ParseCIDROrIP(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bypass IP range: %w", err)
+		}
+		rl.bypassNets = append(rl.bypassNets, network)
+	}
+	for _, admin := range cfg.MCPUsageAdmins {
+		rl.admins[admin] = true
+	}
+	return rl, nil
+}
+
+// Principal identifies the caller: the API key name, the OIDC subject, the matched bypass
+// range or the client IP
+func (rl *RateLimiter) Principal(ctx context.Context) string {
+	return principalFromContext(ctx, rl.bypassNets)
+}
+
+func principalFromContext(ctx context.Context, bypassNets []*net.IPNet) string {
+	if apiKey := getAPIKeyFromContext(ctx); apiKey != nil {
+		return "apikey:" + apiKey.Name
+	}
+	if token, ok := ctx.Value(userContextKey).(*TokenInfo); ok && token != nil && token.Subject != "" {
+		return "oidc:" + token.Subject
+	}
+	// X-Forwarded-For is only honored from MCP_TRUSTED_PROXIES, so callers cannot pick
+	// their principal or claim a bypass range
+	clientIP := getTrustedClientIPFromContext(ctx)
+	if getAuthMethodFromContext(ctx) == "bypass" {
+		if ip := net.ParseIP(clientIP); ip != nil {
+			for _, network := range bypassNets {
+				if network.Contains(ip) {
+					return "bypass:" + network.String()
+				}
+			}
+		}
+		return "bypass:" + clientIP
+	}
+	if clientIP != "" {
+		return "ip:" + clientIP
+	}
+	return "anonymous"
+}
+
+// Allow takes a token from the principal's bucket for tool and checks the daily quotas.
+// Every decision is recorded in the usage store.
+func (rl *RateLimiter) Allow(principal, tool string) RateLimitDecision {
+	rl.mu.Lock()
+	decision := rl.take(principal, tool)
+	rl.mu.Unlock()
+
+	quotaLock := rl.quotaLock(principal)
+	quotaLock.Lock()
+	if decision.Allowed {
+		decision = rl.checkQuota(principal, tool)
+	}
+	if err := rl.store.RecordClientUsage(principal, tool, !decision.Allowed); err != nil {
+		rl.logger.Printf("Failed to record usage of %s by %s: %v", tool, principal, err)
+	}
+	quotaLock.Unlock()
+
+	if !decision.Allowed {
+		rl.logger.Printf("Rejected %s for %s: %s (limit %d, retry after %s)", tool, principal, decision.Reason, decision.Limit, decision.RetryAfter)
+	}
+	return decision
+}
+
+// quotaLock returns the lock of the principal's stripe
+func (rl *RateLimiter) quotaLock(principal string) *sync.Mutex {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(principal))
+	return &rl.quotaLocks[h.Sum32()%quotaLockStripes]
+}
+
+// take updates the in-memory bucket; the caller holds rl.mu
+func (rl *RateLimiter) take(principal, tool string) RateLimitDecision {
+	perMinute := rl.toolSetting(rl.toolLimits, tool, rl.perMinute)
+	ratePerSecond := float64(perMinute) / 60
+	now := rl.now()
+
+	key := principal + "\x00" + tool
+	bucket, ok := rl.buckets[key]
+	if !ok {
+		if len(rl.buckets) >= pruneBucketsAbove {
+			rl.prune(now)
+		}
+		bucket = &tokenBucket{tokens: float64(rl.burst), last: now, ratePerSecond: ratePerSecond}
+		rl.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(float64(rl.burst), bucket.tokens+now.Sub(bucket.last).Seconds()*ratePerSecond)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / ratePerSecond * float64(time.Second))
+		return RateLimitDecision{Reason: "rate_limit", Limit: perMinute, RetryAfter: wait}
+	}
+	bucket.tokens--
+	return RateLimitDecision{Allowed: true}
+}
+
+// prune drops buckets that have refilled completely; a new bucket starts out full anyway
+func (rl *RateLimiter) prune(now time.Time) {
+	for key, bucket := range rl.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*bucket.ratePerSecond >= float64(rl.burst) {
+			delete(rl.buckets, key)
+		}
+	}
+}
+
+func (rl *RateLimiter) checkQuota(principal, tool string) RateLimitDecision {
+	now := rl.now()
+	date := now.Format("2006-01-02")
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+
+	checks := []struct {
+		tool  string
+		quota int
+	}{
+		{tool, rl.toolSetting(rl.toolQuotas, tool, 0)},
+		{"", rl.dailyQuota},
+	}
+	for _, check := range checks {
+		if check.quota <= 0 {
+			continue
+		}
+		calls, err := rl.store.GetClientCalls(principal, check.tool, date)
+		if err != nil {
+			// An unavailable store must not take the server down with it
+			rl.logger.Printf("Failed to read usage of %s: %v", principal, err)
+			continue
+		}
+		if calls >= int64(check.quota) {
+			return RateLimitDecision{Reason: "daily_quota", Limit: check.quota, RetryAfter: tomorrow.Sub(now)}
+		}
+	}
+	return RateLimitDecision{Allowed: true}
+}
+
+// toolSetting looks a tool up by its registered name, then without MCP_TOOL_PREFIX
+func (rl *RateLimiter) toolSetting(settings map[string]int, tool string, fallback int) int {
+	if value, ok := settings[tool]; ok {
+		return value
+	}
+	if rl.toolPrefix != "" {
+		if value, ok := settings[strings.TrimPrefix(tool, rl.toolPrefix)]; ok {
+			return value
+		}
+	}
+	return fallback
+}
+
+// Middleware rejects throttled tools/call requests with 429 and a JSON-RPC error.
+// It must run inside the authentication middleware, which identifies the principal.
+func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRateLimitBodyBytes+1))
+		_ = r.Body.Close()
+		if err != nil {
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		if len(body) > maxRateLimitBodyBytes {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		messages, batch := parseJSONRPCMessages(body)
+		principal := rl.Principal(r.Context())
+		for _, message := range messages {
+			if message.Method != "tools/call" || message.Params.Name == "" {
+				continue
+			}
+			decision := rl.Allow(principal, message.Params.Name)
+			if !decision.Allowed {
+				rl.writeRejection(w, messages, batch, principal, message.Params.Name, decision)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeRejection answers every request of the message (or batch) with the rate limit error
+func (rl *RateLimiter) writeRejection(w http.ResponseWriter, messages []jsonRPCMessage, batch bool, principal, tool string, decision RateLimitDecision) {
+	retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	message := fmt.Sprintf("rate limit exceeded for %s: %d calls per minute", tool, decision.Limit)
+	if decision.Reason == "daily_quota" {
+		message = fmt.Sprintf("daily quota exceeded for %s: %d calls per day", tool, decision.Limit)
+	}
+
+	responses := make([]map[string]interface{}, 0, len(messages))
+	for _, m := range messages {
+		if batch && len(m.ID) == 0 {
+			continue // Notifications get no response
+		}
+		id := m.ID
+		if len(id) == 0 {
+			id = json.RawMessage("null")
+		}
+		responses = append(responses, map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      id,
+			"error": map[string]interface{}{
+				"code":    rateLimitErrorCode,
+				"message": message,
+				"data": map[string]interface{}{
+					"reason":              decision.Reason,
+					"principal":           principal,
+					"tool":                tool,
+					"limit":               decision.Limit,
+					"retry_after_seconds": retryAfter,
+				},
+			},
+		})
+	}
+
+	var payload interface{} = responses
+	if !batch && len(responses) == 1 {
+		payload = responses[0]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	w.WriteHeader(http.StatusTooManyRequests)
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		rl.logger.Printf("Failed to write rate limit response: %v", err)
+	}
+}
+
+// HandleUsage serves /admin/usage. Principals listed in MCP_USAGE_ADMINS see every
+// client; everyone else sees their own usage.
+func (rl *RateLimiter) HandleUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		date = rl.now().Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", date); err != nil {
+		http.Error(w, "date must be YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	usage, err := rl.store.GetClientUsageByDate(date)
+	if err != nil {
+		rl.logger.Printf("Failed to read usage: %v", err)
+		http.Error(w, "failed to read usage", http.StatusInternalServerError)
+		return
+	}
+	principal := rl.Principal(r.Context())
+	admin := rl.admins[principal]
+	if !admin {
+		own := make([]metrics.ClientUsage, 0)
+		for _, u := range usage {
+			if u.Principal == principal {
+				own = append(own, u)
+			}
+		}
+		usage = own
+	}
+
+	response := UsageResponse{
+		Date:      date,
+		Principal: principal,
+		Admin:     admin,
+		Limits: RateLimitSettings{
+			PerMinute:   rl.perMinute,
+			Burst:       rl.burst,
+			ToolLimits:  rl.toolLimits,
+			DailyQuota:  rl.dailyQuota,
+			ToolQuotas:  rl.toolQuotas,
+			QuotaResets: "daily at local midnight",
+		},
+		Usage: usage,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		rl.logger.Printf("Failed to write usage response: %v", err)
+	}
+}
+
+// Summary returns today's totals across all principals for /health
+func (rl *RateLimiter) Summary() UsageSummary {
+	summary := UsageSummary{Enabled: true, Date: rl.now().Format("2006-01-02")}
+	usage, err := rl.store.GetClientUsageByDate(summary.Date)
+	if err != nil {
+		rl.logger.Printf("Failed to read usage: %v", err)
+		return summary
+	}
+	principals := make(map[string]bool)
+	for _, u := range usage {
+		principals[u.Principal] = true
+		summary.Calls += u.Calls
+		summary.Rejected += u.Rejected
+	}
+	summary.Principals = len(principals)
+	return summary
+}
+
+// parseJSONRPCMessages decodes a single JSON-RPC message or a batch. Bodies that are
+// not JSON-RPC yield no messages and pass through untouched.
+func parseJSONRPCMessages(body []byte) ([]jsonRPCMessage, bool) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, false
+	}
+	if trimmed[0] == '[' {
+		var batch []jsonRPCMessage
+		if err := json.Unmarshal(trimmed, &batch); err != nil {
+			return nil, true
+		}
+		return batch, true
+	}
+	var message jsonRPCMessage
+	if err := json.Unmarshal(trimmed, &message); err != nil {
+		return nil, false
+	}
+	return []jsonRPCMessage{message}, false
+}
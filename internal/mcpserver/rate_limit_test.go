@@ -0,0 +1,273 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	appcfg "github.com/ca-srg/ragent/internal/pkg/config"
+	"github.com/ca-srg/ragent/internal/pkg/metrics"
+)
+
+func newTestRateLimiter(t *testing.T, cfg *appcfg.Config) (*RateLimiter, *time.Time) {
+	t.Helper()
+	store, err := metrics.NewStoreWithPath(filepath.Join(t.TempDir(), "stats.db"))
+	if err != nil {
+		t.Fatalf("NewStoreWithPath returned error: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	limiter, err := NewRateLimiter(cfg, store)
+	if err != nil {
+		t.Fatalf("NewRateLimiter returned error: %v", err)
+	}
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+	return limiter, &now
+}
+
+func TestRateLimiterPrincipal(t *testing.T) {
+	limiter, _ := newTestRateLimiter(t, &appcfg.Config{MCPBypassIPRanges: []string{"10.0.0.0/8"}})
+
+	bypass := context.WithValue(context.WithValue(context.Background(), authMethodContextKey, "bypass"), trustedClientIPContextKey, "10.1.2.3")
+	forged := context.WithValue(context.WithValue(context.Background(), authMethodContextKey, "bypass"), clientIPContextKey, "10.1.2.3")
+	forged = context.WithValue(forged, trustedClientIPContextKey, "203.0.113.7")
+	cases := []struct {
+		ctx  context.Context
+		want string
+	}{
+		{oidcContext(map[string]interface{}{}), "oidc:alice"},
+		{ipContext("192.168.0.5"), "ip:192.168.0.5"},
+		{bypass, "bypass:10.0.0.0/8"},
+		{forged, "bypass:203.0.113.7"},
+		{context.WithValue(forged, authMethodContextKey, string(AuthMethodIP)), "ip:203.0.113.7"},
+		{context.WithValue(bypass, userContextKey, &TokenInfo{Subject: "alice"}), "oidc:alice"},
+		{context.Background(), "anonymous"},
+	}
+	for _, c := range cases {
+		if got := limiter.Principal(c.ctx); got != c.want {
+			t.Fatalf("expected principal %s, got %s", c.want, got)
+		}
+	}
+}
+
+func TestRateLimiterPrincipalIgnoresForgedForwardedFor(t *testing.T) {
+	limiter, _ := newTestRateLimiter(t, &appcfg.Config{})
+	principal := func(remoteAddr, forwardedFor string, trustedProxies []string) string {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		var got string
+		withTrustedClientIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = limiter.Principal(r.Context())
+		}), trustedProxies).ServeHTTP(httptest.NewRecorder(), req)
+		return got
+	}
+
+	if got := principal("203.0.113.7:5000", "198.51.100.1", nil); got != "ip:203.0.113.7" {
+		t.Fatalf("expected X-Forwarded-For to be ignored without MCP_TRUSTED_PROXIES, got %s", got)
+	}
+	if got := principal("203.0.113.7:5000", "198.51.100.1", []string{"192.168.0.1"}); got != "ip:203.0.113.7" {
+		t.Fatalf("expected X-Forwarded-For from an untrusted peer to be ignored, got %s", got)
+	}
+	if got := principal("192.168.0.1:5000", "198.51.100.1", []string{"192.168.0.1"}); got != "ip:198.51.100.1" {
+		t.Fatalf("expected the client IP forwarded by a trusted proxy, got %s", got)
+	}
+}
+
+// blockingUsageStore blocks RecordClientUsage of one principal until released
+type blockingUsageStore struct {
+	UsageStore
+	blocked string
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingUsageStore) RecordClientUsage(principal, tool string, rejected bool) error {
+	if principal == s.blocked {
+		close(s.entered)
+		<-s.release
+	}
+	return nil
+}
+
+func (s *blockingUsageStore) GetClientCalls(principal, tool, date string) (int64, error) {
+	return 0, nil
+}
+
+func TestRateLimiterDoesNotHoldLockAcrossStore(t *testing.T) {
+	store := &blockingUsageStore{blocked: "apikey:slow", entered: make(chan struct{}), release: make(chan struct{})}
+	limiter, err := NewRateLimiter(&appcfg.Config{MCPRateLimitPerMinute: 60, MCPRateLimitBurst: 5, MCPDailyQuota: 100}, store)
+	if err != nil {
+		t.Fatalf("NewRateLimiter returned error: %v", err)
+	}
+	if limiter.quotaLock("apikey:slow") == limiter.quotaLock("apikey:fast") {
+		t.Fatalf("test principals must use different quota locks")
+	}
+
+	go limiter.Allow("apikey:slow", "search")
+	<-store.entered
+	defer close(store.release)
+
+	done := make(chan RateLimitDecision)
+	go func() { done <- limiter.Allow("apikey:fast", "search") }()
+	select {
+	case decision := <-done:
+		if !decision.Allowed {
+			t.Fatalf("expected the call to be allowed, got %+v", decision)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("a slow usage store write must not block other principals")
+	}
+}
+
+func TestRateLimiterTokenBucketPerTool(t *testing.T) {
+	limiter, now := newTestRateLimiter(t, &appcfg.Config{
+		MCPRateLimitPerMinute: 60,
+		MCPRateLimitBurst:     2,
+		MCPRateLimitTools:     map[string]int{"ask": 6},
+		MCPToolPrefix:         "kb_",
+	})
+
+	for i := 0; i < 2; i++ {
+		if decision := limiter.Allow("ip:10.0.0.1", "kb_hybrid_search"); !decision.Allowed {
+			t.Fatalf("call %d within burst was rejected", i)
+		}
+	}
+	decision := limiter.Allow("ip:10.0.0.1", "kb_hybrid_search")
+	if decision.Allowed || decision.Reason != "rate_limit" || decision.Limit != 60 || decision.RetryAfter > time.Second {
+		t.Fatalf("expected rate limit rejection, got %+v", decision)
+	}
+	if !limiter.Allow("ip:10.0.0.2", "kb_hybrid_search").Allowed {
+		t.Fatalf("another principal must have its own bucket")
+	}
+	if !limiter.Allow("ip:10.0.0.1", "kb_ask").Allowed {
+		t.Fatalf("another tool must have its own bucket")
+	}
+
+	*now = now.Add(time.Second)
+	if !limiter.Allow("ip:10.0.0.1", "kb_hybrid_search").Allowed {
+		t.Fatalf("bucket must refill at the per-minute rate")
+	}
+
+	// ask is limited to 6 per minute, so a token takes 10 seconds to refill
+	limiter.Allow("ip:10.0.0.1", "kb_ask")
+	decision = limiter.Allow("ip:10.0.0.1", "kb_ask")
+	if decision.Allowed || decision.Limit != 6 || decision.RetryAfter < 8*time.Second || decision.RetryAfter > 10*time.Second {
+		t.Fatalf("expected the ask override to apply, got %+v", decision)
+	}
+}
+
+func TestRateLimiterDailyQuota(t *testing.T) {
+	limiter, _ := newTestRateLimiter(t, &appcfg.Config{
+		MCPRateLimitPerMinute: 60,
+		MCPRateLimitBurst:     100,
+		MCPDailyQuota:         3,
+		MCPDailyQuotaTools:    map[string]int{"ask": 1},
+	})
+
+	if !limiter.Allow("oidc:alice", "ask").Allowed {
+		t.Fatalf("first ask must be allowed")
+	}
+	decision := limiter.Allow("oidc:alice", "ask")
+	if decision.Allowed || decision.Reason != "daily_quota" || decision.Limit != 1 || decision.RetryAfter <= 0 {
+		t.Fatalf("expected the ask quota to apply, got %+v", decision)
+	}
+	limiter.Allow("oidc:alice", "hybrid_search")
+	limiter.Allow("oidc:alice", "hybrid_search")
+	decision = limiter.Allow("oidc:alice", "get_document")
+	if decision.Allowed || decision.Limit != 3 {
+		t.Fatalf("expected the total quota to apply, got %+v", decision)
+	}
+
+	summary := limiter.Summary()
+	if summary.Principals != 1 || summary.Calls != 3 || summary.Rejected != 2 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	limiter, _ := newTestRateLimiter(t, &appcfg.Config{MCPRateLimitPerMinute: 60, MCPRateLimitBurst: 1})
+
+	var forwarded []string
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		forwarded = append(forwarded, string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body)).WithContext(ipContext("10.0.0.1"))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	call := `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"ask","arguments":{"question":"q"}}}`
+	if rec := post(call); rec.Code != http.StatusOK || len(forwarded) != 1 || forwarded[0] != call {
+		t.Fatalf("first call must pass through unchanged, got %d %v", rec.Code, forwarded)
+	}
+	if rec := post(`{"jsonrpc":"2.0","id":8,"method":"tools/list"}`); rec.Code != http.StatusOK {
+		t.Fatalf("tools/list must not be limited, got %d", rec.Code)
+	}
+
+	rec := post(call)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("expected 429 with Retry-After, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	var response struct {
+		ID    int `json:"id"`
+		Error struct {
+			Code int                    `json:"code"`
+			Data map[string]interface{} `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid JSON-RPC error body: %v", err)
+	}
+	if response.ID != 7 || response.Error.Code != rateLimitErrorCode || response.Error.Data["principal"] != "ip:10.0.0.1" {
+		t.Fatalf("unexpected JSON-RPC error: %s", rec.Body.String())
+	}
+
+	rec = post(`[{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"ask"}},{"jsonrpc":"2.0","method":"notifications/progress"}]`)
+	var batch []map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &batch); err != nil || len(batch) != 1 {
+		t.Fatalf("expected one error per batched request, got %s", rec.Body.String())
+	}
+}
+
+func TestRateLimiterUsageReport(t *testing.T) {
+	limiter, _ := newTestRateLimiter(t, &appcfg.Config{
+		MCPRateLimitPerMinute: 60,
+		MCPRateLimitBurst:     10,
+		MCPUsageAdmins:        []string{"oidc:alice"},
+	})
+	limiter.Allow("oidc:alice", "ask")
+	limiter.Allow("ip:10.0.0.1", "hybrid_search")
+
+	report := func(ctx context.Context) UsageResponse {
+		rec := httptest.NewRecorder()
+		limiter.HandleUsage(rec, httptest.NewRequest(http.MethodGet, "/admin/usage", nil).WithContext(ctx))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unexpected status %d", rec.Code)
+		}
+		var response UsageResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("invalid usage JSON: %v", err)
+		}
+		return response
+	}
+
+	if admin := report(oidcContext(nil)); !admin.Admin || len(admin.Usage) != 2 {
+		t.Fatalf("admin must see every principal, got %+v", admin)
+	}
+	own := report(ipContext("10.0.0.1"))
+	if own.Admin || len(own.Usage) != 1 || own.Usage[0].Tool != "hybrid_search" || own.Limits.PerMinute != 60 {
+		t.Fatalf("non-admin must only see their own usage, got %+v", own)
+	}
+}
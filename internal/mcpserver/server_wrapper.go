@@ -2,6 +2,7 @@ package mcpserver
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net"
@@ -34,6 +35,7 @@ type ServerWrapper struct {
 	toolRegistry          *ToolRegistry
 	ipAuthMiddleware      *IPAuthMiddleware
 	unifiedAuthMiddleware *UnifiedAuthMiddleware
+	rateLimiter           *RateLimiter
 	sseManager            *SSEManager
 
 	// Dashboard embedding
@@ -215,6 +217,18 @@ func (sw *ServerWrapper) SetUnifiedAuthMiddleware(middleware *UnifiedAuthMiddlew
 	}
 }
 
+// SetRateLimiter enables per-principal rate limiting and daily quotas for tool calls
+// and serves the usage report on /admin/usage.
+func (sw *ServerWrapper) SetRateLimiter(limiter *RateLimiter) {
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+
+	sw.rateLimiter = limiter
+	if limiter != nil {
+		sw.logger.Printf("Rate limiter set")
+	}
+}
+
 // GetToolRegistry returns the tool registry
 // Maintains API compatibility with existing MCPServer
 func (sw *ServerWrapper) GetToolRegistry() *ToolRegistry {
@@ -299,6 +313,46 @@ func (sw *ServerWrapper) RegisterCustomTool(tool *mcp.Tool, handler mcp.ToolHand
 	return nil
 }
 
+// RegisterResourceTemplate registers a resource template with the SDK server.
+func (sw *ServerWrapper) RegisterResourceTemplate(template *mcp.ResourceTemplate, handler mcp.ResourceHandler) error {
+	if sw == nil {
+		return fmt.Errorf("server wrapper is nil")
+	}
+	if sw.sdkServer == nil {
+		return fmt.Errorf("SDK server not initialized")
+	}
+	if template == nil || template.URITemplate == "" {
+		return fmt.Errorf("resource template cannot be empty")
+	}
+	if handler == nil {
+		return fmt.Errorf("resource handler cannot be nil")
+	}
+
+	sw.sdkServer.AddResourceTemplate(template, handler)
+	sw.logger.Printf("Resource template %s registered successfully", template.URITemplate)
+	return nil
+}
+
+// RegisterPrompt registers a prompt with the SDK server.
+func (sw *ServerWrapper) RegisterPrompt(prompt *mcp.Prompt, handler mcp.PromptHandler) error {
+	if sw == nil {
+		return fmt.Errorf("server wrapper is nil")
+	}
+	if sw.sdkServer == nil {
+		return fmt.Errorf("SDK server not initialized")
+	}
+	if prompt == nil || prompt.Name == "" {
+		return fmt.Errorf("prompt name cannot be empty")
+	}
+	if handler == nil {
+		return fmt.Errorf("prompt handler cannot be nil")
+	}
+
+	sw.sdkServer.AddPrompt(prompt, handler)
+	sw.logger.Printf("Prompt %s registered successfully", prompt.Name)
+	return nil
+}
+
 // Start starts the server with lifecycle management
 // Maintains API compatibility with existing MCPServer.Start()
 func (sw *ServerWrapper) Start() error {
@@ -338,6 +392,9 @@ func (sw *ServerWrapper) Start() error {
 
 	mux.HandleFunc("/health", sw.handleHealthCheck)
 	sw.registerAuthRoutes(mux)
+	if sw.rateLimiter != nil {
+		mux.HandleFunc("/admin/usage", sw.rateLimiter.HandleUsage)
+	}
 
 	if sw.dashboardHandler != nil && sw.dashboardPath != "" {
 		prefix := sw.dashboardPath
@@ -345,8 +402,12 @@ func (sw *ServerWrapper) Start() error {
 		sw.logger.Printf("Dashboard mounted at %s/", prefix)
 	}
 
-	// Build handler chain with authentication
+	// Build handler chain with authentication; rate limiting runs inside it to see the principal
 	var handler http.Handler = mux
+	if sw.rateLimiter != nil {
+		handler = sw.rateLimiter.Middleware(handler)
+		sw.logger.Printf("Rate limiting middleware enabled")
+	}
 	if sw.unifiedAuthMiddleware != nil {
 		handler = sw.unifiedAuthMiddleware.Middleware(handler)
 		sw.logger.Printf("Unified authentication middleware enabled")
@@ -355,6 +416,9 @@ func (sw *ServerWrapper) Start() error {
 		sw.logger.Printf("IP authentication middleware enabled")
 	}
 
+	// Resolved before authentication so forged forwarding headers never reach authorization
+	handler = withTrustedClientIP(handler, sw.ragentConfig.MCPTrustedProxies)
+
 	// Always-on access logging
 	handler = mcpclient.InboundDepthMiddleware(handler)
 	handler = sw.loggingMiddleware(handler)
@@ -531,14 +595,13 @@ func (sw *ServerWrapper) handleHealthCheck(w http.ResponseWriter, r *http.Reques
 		"running":     sw.isRunning,
 		"address":     sw.GetServerAddress(),
 	}
+	if sw.rateLimiter != nil {
+		status["rate_limit"] = sw.rateLimiter.Summary()
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-
-	// Simple JSON encoding for health check
-	response := fmt.Sprintf(`{"status":"%v","server_type":"%v","sdk_version":"%v","running":%v,"address":"%v"}`,
-		status["status"], status["server_type"], status["sdk_version"], status["running"], status["address"])
-	if _, err := w.Write([]byte(response)); err != nil {
+	if err := json.NewEncoder(w).Encode(status); err != nil {
 		sw.logger.Printf("Failed to write response: %v", err)
 	}
 }
@@ -627,6 +690,11 @@ func (sw *ServerWrapper) registerAuthRoutes(mux *http.ServeMux) {
 				http.Redirect(w, r, authURL, http.StatusFound)
 			})
 			sw.logger.Printf("Registered OIDC login route: /login")
+			mux.HandleFunc(ProtectedResourceMetadataPath, oidc.HandleProtectedResourceMetadata)
+			mux.HandleFunc(ProtectedResourceMetadataPath+"/", oidc.HandleProtectedResourceMetadata)
+			sw.logger.Printf("Registered OAuth protected resource metadata route: %s", ProtectedResourceMetadataPath)
+			mux.HandleFunc("/admin/sessions", oidc.HandleSessions)
+			sw.logger.Printf("Registered OIDC session admin route: /admin/sessions")
 		}
 	}
 }
@@ -0,0 +1,223 @@
+package mcpserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+)
+
+// Transports accepted by mcp-server --transport
+const (
+	TransportHTTP  = "http"
+	TransportStdio = "stdio"
+)
+
+// stdioAuthMethod is recorded as the auth method for requests served over stdio
+const stdioAuthMethod = "stdio"
+
+// stdioTransport serves MCP over newline-delimited JSON-RPC on an arbitrary
+// reader/writer pair. mcp.StdioTransport is bound to os.Stdin/os.Stdout, which
+// makes it impossible to redirect process stdout away from the protocol stream.
+type stdioTransport struct {
+	in  io.Reader
+	out io.Writer
+}
+
+// Connect implements mcp.Transport
+func (t *stdioTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	return newStdioConnection(t.in, t.out), nil
+}
+
+type stdioFrame struct {
+	data []byte
+	err  error
+}
+
+// stdioConnection reads one JSON-RPC message per line and writes one message per line
+type stdioConnection struct {
+	in       io.Reader
+	out      io.Writer
+	writeMu  sync.Mutex
+	incoming chan stdioFrame
+	closed   chan struct{}
+	once     sync.Once
+}
+
+func newStdioConnection(in io.Reader, out io.Writer) *stdioConnection {
+	conn := &stdioConnection{
+		in:       in,
+		out:      out,
+		incoming: make(chan stdioFrame),
+		closed:   make(chan struct{}),
+	}
+	go conn.readLoop()
+	return conn
+}
+
+func (c *stdioConnection) readLoop() {
+	reader := bufio.NewReader(c.in)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			select {
+			case c.incoming <- stdioFrame{data: line}:
+			case <-c.closed:
+				return
+			}
+		}
+		if err != nil {
+			select {
+			case c.incoming <- stdioFrame{err: err}:
+			case <-c.closed:
+			}
+			return
+		}
+	}
+}
+
+// Read implements mcp.Connection
+func (c *stdioConnection) Read(ctx context.Context) (jsonrpc.Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.closed:
+		return nil, io.EOF
+	case frame := <-c.incoming:
+		if frame.err != nil {
+			return nil, frame.err
+		}
+		msg, err := jsonrpc.DecodeMessage(bytes.TrimSpace(frame.data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode stdio message: %w", err)
+		}
+		return msg, nil
+	}
+}
+
+// Write implements mcp.Connection
+func (c *stdioConnection) Write(ctx context.Context, msg jsonrpc.Message) error {
+	data, err := jsonrpc.EncodeMessage(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode stdio message: %w", err)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	select {
+	case <-c.closed:
+		return io.ErrClosedPipe
+	default:
+	}
+	_, err = c.out.Write(append(data, '\n'))
+	return err
+}
+
+// Close implements mcp.Connection. The reader is closed when it supports it so
+// that the read loop does not outlive the session.
+func (c *stdioConnection) Close() error {
+	var err error
+	c.once.Do(func() {
+		close(c.closed)
+		if closer, ok := c.in.(io.Closer); ok {
+			err = closer.Close()
+		}
+	})
+	return err
+}
+
+// SessionID implements mcp.Connection; a stdio connection carries a single session
+func (c *stdioConnection) SessionID() string {
+	return ""
+}
+
+// localTokenInfo builds the identity used for a trusted local stdio user. Groups and
+// tenant come from ACL_LOCAL_GROUPS and TENANT_ID, the same sources the CLI uses.
+func localTokenInfo(cfg *appconfig.Config) *TokenInfo {
+	subject := os.Getenv("USER")
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		subject = current.Username
+	}
+	if subject == "" {
+		subject = "local"
+	}
+
+	claims := map[string]interface{}{}
+	if cfg != nil {
+		if groups := cfg.LocalGroups(); len(groups) > 0 && cfg.ACLOIDCClaim != "" {
+			claims[cfg.ACLOIDCClaim] = groups
+		}
+		if cfg.TenantID != "" && cfg.TenantOIDCClaim != "" {
+			claims[cfg.TenantOIDCClaim] = []string{cfg.TenantID}
+		}
+	}
+
+	return &TokenInfo{
+		Subject: subject,
+		Claims:  claims,
+	}
+}
+
+// stdioContextMiddleware tags every request with the stdio auth method and, when the
+// local user is trusted, with the local identity so secret, tenant and group policies
+// treat it like an authenticated OIDC user.
+func stdioContextMiddleware(trusted *TokenInfo) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			ctx = context.WithValue(ctx, authMethodContextKey, stdioAuthMethod)
+			if trusted != nil {
+				ctx = context.WithValue(ctx, userContextKey, trusted)
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// ServeStdio serves the registered tools over in/out until the client disconnects or
+// ctx is cancelled. HTTP auth middleware does not apply; MCP_STDIO_TRUST_LOCAL_USER
+// decides whether the local user is treated as authenticated.
+func (sw *ServerWrapper) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	sw.mutex.Lock()
+	if sw.isRunning {
+		sw.mutex.Unlock()
+		return fmt.Errorf("server is already running")
+	}
+	if sw.sdkServer == nil {
+		sw.mutex.Unlock()
+		return fmt.Errorf("SDK server not initialized")
+	}
+
+	var trusted *TokenInfo
+	if sw.ragentConfig != nil && sw.ragentConfig.MCPStdioTrustLocalUser {
+		trusted = localTokenInfo(sw.ragentConfig)
+		sw.logger.Printf("Stdio transport trusts local user %q", trusted.Subject)
+	} else {
+		sw.logger.Printf("Stdio transport serves the local user as unauthenticated")
+	}
+	sw.sdkServer.AddReceivingMiddleware(stdioContextMiddleware(trusted))
+	sw.isRunning = true
+	sw.mutex.Unlock()
+
+	defer func() {
+		sw.mutex.Lock()
+		sw.isRunning = false
+		sw.mutex.Unlock()
+	}()
+
+	sw.logger.Printf("MCP server (SDK-based) serving over stdio")
+	err := sw.sdkServer.Run(ctx, &stdioTransport{in: in, out: out})
+	if err != nil && (errors.Is(err, io.EOF) || ctx.Err() != nil) {
+		return nil
+	}
+	return err
+}
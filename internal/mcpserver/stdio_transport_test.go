@@ -0,0 +1,130 @@
+package mcpserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+)
+
+func TestStdioTransport_InitializeRoundTrip(t *testing.T) {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "stdio-test", Version: "1.0.0"}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Run(ctx, &stdioTransport{in: inReader, out: outWriter})
+	}()
+
+	request := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1.0.0"}}}` + "\n"
+	if _, err := inWriter.Write([]byte(request)); err != nil {
+		t.Fatalf("failed to write request: %v", err)
+	}
+
+	line, err := bufio.NewReader(outReader).ReadString('\n')
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+
+	var response struct {
+		ID     int `json:"id"`
+		Result struct {
+			ServerInfo struct {
+				Name string `json:"name"`
+			} `json:"serverInfo"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(line), &response); err != nil {
+		t.Fatalf("response is not a single JSON line: %v (%q)", err, line)
+	}
+	if response.ID != 1 || response.Result.ServerInfo.Name != "stdio-test" {
+		t.Fatalf("unexpected initialize response: %s", line)
+	}
+
+	_ = inWriter.Close()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("server did not stop after stdin closed")
+	}
+}
+
+func TestStdioConnection_CloseUnblocksRead(t *testing.T) {
+	inReader, _ := io.Pipe()
+	conn := newStdioConnection(inReader, io.Discard)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := conn.Read(context.Background())
+		errCh <- err
+	}()
+
+	if err := conn.Close(); err != nil {
+		t.Fatalf("close failed: %v", err)
+	}
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Fatalf("expected read error after close")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("read did not return after close")
+	}
+}
+
+func TestLocalTokenInfo_UsesLocalGroupsAndTenant(t *testing.T) {
+	cfg := &appconfig.Config{
+		ACLOIDCClaim:    "groups",
+		ACLLocalGroups:  []string{"eng"},
+		TenantID:        "acme",
+		TenantOIDCClaim: "tenants",
+	}
+
+	info := localTokenInfo(cfg)
+	if info.Subject == "" {
+		t.Fatalf("expected a local subject")
+	}
+	if got := cfg.GroupsFromClaims(info.Claims); strings.Join(got, ",") != "eng" {
+		t.Fatalf("expected local groups from claims, got %v", got)
+	}
+	if got := cfg.TenantsFromClaims(info.Claims); strings.Join(got, ",") != "acme" {
+		t.Fatalf("expected tenant from claims, got %v", got)
+	}
+}
+
+func TestStdioContextMiddleware(t *testing.T) {
+	var captured context.Context
+	next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		captured = ctx
+		return nil, nil
+	}
+
+	trusted := &TokenInfo{Subject: "alice"}
+	if _, err := stdioContextMiddleware(trusted)(next)(context.Background(), "tools/call", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := getAuthMethodFromContext(captured); got != stdioAuthMethod {
+		t.Fatalf("expected auth method %q, got %q", stdioAuthMethod, got)
+	}
+	if token, ok := captured.Value(userContextKey).(*TokenInfo); !ok || token.Subject != "alice" {
+		t.Fatalf("expected trusted token in context, got %v", captured.Value(userContextKey))
+	}
+
+	if _, err := stdioContextMiddleware(nil)(next)(context.Background(), "tools/call", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if captured.Value(userContextKey) != nil {
+		t.Fatalf("untrusted stdio requests must not carry a user token")
+	}
+}
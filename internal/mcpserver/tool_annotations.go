@@ -31,3 +31,18 @@ func markToolMutating(tool *mcp.Tool, title string) {
 		tool.Annotations.Title = title
 	}
 }
+
+func markToolDestructive(tool *mcp.Tool, title string) {
+	if tool == nil {
+		return
+	}
+	destructive := true
+	if tool.Annotations == nil {
+		tool.Annotations = &mcp.ToolAnnotations{}
+	}
+	tool.Annotations.ReadOnlyHint = false
+	tool.Annotations.DestructiveHint = &destructive
+	if tool.Annotations.Title == "" {
+		tool.Annotations.Title = title
+	}
+}
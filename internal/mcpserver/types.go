@@ -18,10 +18,14 @@ type MCPToolCallResult struct {
 	IsError bool         `json:"isError,omitempty"`
 }
 
-// Legacy MCPContent with Type field for backward compatibility
+// Legacy MCPContent with Type field for backward compatibility.
+// URI, Name and MIMEType are set for "resource_link" content.
 type MCPContent struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	Name     string `json:"name,omitempty"`
+	MIMEType string `json:"mimeType,omitempty"`
 }
 
 // MCPToolRequest is now an alias to the SDK CallToolRequest type
@@ -88,6 +92,10 @@ type HybridSearchRequest struct {
 	ExcludeSecret     bool              `json:"exclude_secret,omitempty"`
 	EnableSlackSearch bool              `json:"enable_slack_search,omitempty"`
 	SlackChannels     []string          `json:"slack_channels,omitempty"`
+	EfSearch          int               `json:"ef_search,omitempty"` // HNSW ef_search of the vector query
+	Indexes           []string          `json:"indexes,omitempty"`   // Federated index list ("name" or "name^weight")
+	Tenants           []string          `json:"-"`                   // Resolved from the caller, never taken from params
+	Groups            []string          `json:"-"`                   // Resolved from the caller, never taken from params
 }
 
 // HybridSearchResponse represents the hybrid search tool response
@@ -108,18 +116,20 @@ type HybridSearchResponse struct {
 
 // HybridSearchResultItem represents a single search result
 type HybridSearchResultItem struct {
-	ID        string                 `json:"id"`
-	Title     string                 `json:"title"`
-	Content   string                 `json:"content"`
-	Score     float64                `json:"score"`  // Fused score after hybrid result combination
-	Source    string                 `json:"source"` // "s3vector", "opensearch", "hybrid"
-	Path      string                 `json:"path"`   // File path
-	Category  string                 `json:"category,omitempty"`
-	Author    string                 `json:"author,omitempty"`
-	Tags      []string               `json:"tags,omitempty"`
-	CreatedAt string                 `json:"created_at,omitempty"`
-	UpdatedAt string                 `json:"updated_at,omitempty"`
-	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	ID          string                 `json:"id"`
+	ResourceURI string                 `json:"resource_uri,omitempty"` // ragent://doc URI with the full document
+	Title       string                 `json:"title"`
+	Content     string                 `json:"content"`
+	Score       float64                `json:"score"`           // Fused score after hybrid result combination
+	Source      string                 `json:"source"`          // "s3vector", "opensearch", "hybrid"
+	Path        string                 `json:"path"`            // File path
+	Index       string                 `json:"index,omitempty"` // Source index of a federated search result
+	Category    string                 `json:"category,omitempty"`
+	Author      string                 `json:"author,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	CreatedAt   string                 `json:"created_at,omitempty"`
+	UpdatedAt   string                 `json:"updated_at,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 }
 
 // HybridSearchMetadata contains metadata about the search execution
@@ -0,0 +1,44 @@
+package config
+
+import "strings"
+
+// NormalizeGroups lowercases, trims, de-duplicates and sorts group names so that
+// document allowed_groups and caller groups compare as exact keywords.
+func NormalizeGroups(raw []string) []string {
+	groups := normalizeNames(raw)
+	if len(groups) == 0 {
+		return nil
+	}
+	return groups
+}
+
+// ParseGroupList parses a comma-separated group list such as "sre, security".
+func ParseGroupList(spec string) []string {
+	return NormalizeGroups(strings.Split(spec, ","))
+}
+
+// GroupsFromClaims returns the groups listed in the ACL_OIDC_CLAIM claim. The claim
+// may be an array or a comma- or space-separated string.
+func (c *Config) GroupsFromClaims(claims map[string]interface{}) []string {
+	if c == nil || c.ACLOIDCClaim == "" {
+		return nil
+	}
+	return NormalizeGroups(claimValues(claims, c.ACLOIDCClaim))
+}
+
+// ScopesFromClaims returns the OAuth scopes granted by the "scope" claim, or the
+// "scp" claim used by some providers. Scopes are case-sensitive and kept as issued.
+func ScopesFromClaims(claims map[string]interface{}) []string {
+	if scopes := claimValues(claims, "scope"); len(scopes) > 0 {
+		return scopes
+	}
+	return claimValues(claims, "scp")
+}
+
+// LocalGroups returns the groups of the local CLI identity from ACL_LOCAL_GROUPS.
+func (c *Config) LocalGroups() []string {
+	if c == nil {
+		return nil
+	}
+	return c.ACLLocalGroups
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGroupList(t *testing.T) {
+	assert.Equal(t, []string{"security", "sre"}, ParseGroupList(" SRE,security, sre ,"))
+	assert.Nil(t, ParseGroupList(" , "))
+}
+
+func TestGroupsFromClaims(t *testing.T) {
+	cfg := &Config{ACLOIDCClaim: "groups"}
+	assert.Equal(t, []string{"security", "sre"}, cfg.GroupsFromClaims(map[string]interface{}{
+		"groups": []interface{}{"SRE", "security", 42},
+	}))
+	assert.Equal(t, []string{"sre"}, cfg.GroupsFromClaims(map[string]interface{}{"groups": "sre"}))
+	assert.Nil(t, cfg.GroupsFromClaims(map[string]interface{}{"roles": []interface{}{"sre"}}))
+	assert.Nil(t, (&Config{}).GroupsFromClaims(map[string]interface{}{"groups": "sre"}))
+}
+
+func TestScopesFromClaims(t *testing.T) {
+	assert.Equal(t, []string{"openid", "ragent:write"}, ScopesFromClaims(map[string]interface{}{"scope": "openid ragent:write"}))
+	assert.Equal(t, []string{"ragent:write"}, ScopesFromClaims(map[string]interface{}{"scp": []interface{}{"ragent:write"}}))
+	assert.Nil(t, ScopesFromClaims(map[string]interface{}{"groups": "sre"}))
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"net"
 	"net/url"
@@ -62,6 +63,116 @@ func Load() (*Config, error) {
 		}
 	}
 
+	// Parse MCPWriteScopes from comma-separated string
+	if config.MCPWriteScopesStr != "" {
+		scopes := strings.Split(config.MCPWriteScopesStr, ",")
+		config.MCPWriteScopes = make([]string, 0, len(scopes))
+		for _, s := range scopes {
+			if trimmed := strings.TrimSpace(s); trimmed != "" {
+				config.MCPWriteScopes = append(config.MCPWriteScopes, trimmed)
+			}
+		}
+	}
+
+	// Parse MCPWriteGroups from comma-separated string
+	if config.MCPWriteGroupsStr != "" {
+		config.MCPWriteGroups = ParseGroupList(config.MCPWriteGroupsStr)
+	}
+
+	// Parse MCPWriteAllowedIPs from comma-separated string
+	if config.MCPWriteAllowedIPsStr != "" {
+		ips := strings.Split(config.MCPWriteAllowedIPsStr, ",")
+		config.MCPWriteAllowedIPs = make([]string, 0, len(ips))
+		for _, ip := range ips {
+			if trimmed := strings.TrimSpace(ip); trimmed != "" {
+				config.MCPWriteAllowedIPs = append(config.MCPWriteAllowedIPs, trimmed)
+			}
+		}
+	}
+
+	// Parse MCPOAuthRequiredScopes from comma-separated string
+	if config.MCPOAuthRequiredScopesStr != "" {
+		scopes := strings.Split(config.MCPOAuthRequiredScopesStr, ",")
+		config.MCPOAuthRequiredScopes = make([]string, 0, len(scopes))
+		for _, s := range scopes {
+			if trimmed := strings.TrimSpace(s); trimmed != "" {
+				config.MCPOAuthRequiredScopes = append(config.MCPOAuthRequiredScopes, trimmed)
+			}
+		}
+	}
+
+	// Parse MCPAskModels from comma-separated string
+	if config.MCPAskModelsStr != "" {
+		models := strings.Split(config.MCPAskModelsStr, ",")
+		config.MCPAskModels = make([]string, 0, len(models))
+		for _, m := range models {
+			if trimmed := strings.TrimSpace(m); trimmed != "" {
+				config.MCPAskModels = append(config.MCPAskModels, trimmed)
+			}
+		}
+	}
+
+	// Parse MCPFederatedIndexes from comma-separated string
+	if config.MCPFederatedIndexesStr != "" {
+		indexes, err := ParseIndexAllowlist(config.MCPFederatedIndexesStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse MCP_FEDERATED_INDEXES: %w", err)
+		}
+		config.MCPFederatedIndexes = indexes
+	}
+
+	// Parse MCPRateLimitTools and MCPDailyQuotaTools from "tool=limit,..." pairs
+	if config.MCPRateLimitToolsStr != "" {
+		limits, err := ParseToolLimits(config.MCPRateLimitToolsStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse MCP_RATE_LIMIT_TOOLS: %w", err)
+		}
+		config.MCPRateLimitTools = limits
+	}
+	if config.MCPDailyQuotaToolsStr != "" {
+		quotas, err := ParseToolLimits(config.MCPDailyQuotaToolsStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse MCP_DAILY_QUOTA_TOOLS: %w", err)
+		}
+		config.MCPDailyQuotaTools = quotas
+	}
+
+	// Parse MCPUsageAdmins from comma-separated string
+	if config.MCPUsageAdminsStr != "" {
+		admins := strings.Split(config.MCPUsageAdminsStr, ",")
+		config.MCPUsageAdmins = make([]string, 0, len(admins))
+		for _, a := range admins {
+			if trimmed := strings.TrimSpace(a); trimmed != "" {
+				config.MCPUsageAdmins = append(config.MCPUsageAdmins, trimmed)
+			}
+		}
+	}
+
+	// Parse MCPSessionAdmins from comma-separated string
+	if config.MCPSessionAdminsStr != "" {
+		admins := strings.Split(config.MCPSessionAdminsStr, ",")
+		config.MCPSessionAdmins = make([]string, 0, len(admins))
+		for _, a := range admins {
+			if trimmed := strings.TrimSpace(a); trimmed != "" {
+				config.MCPSessionAdmins = append(config.MCPSessionAdmins, trimmed)
+			}
+		}
+	}
+
+	// Parse TenantSlackChannels from "CHANNEL=tenant|tenant,..." pairs
+	if config.TenantSlackChannelsStr != "" {
+		channels, err := ParseTenantSlackChannels(config.TenantSlackChannelsStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse TENANT_SLACK_CHANNELS: %w", err)
+		}
+		config.TenantSlackChannels = channels
+	}
+
+	// Parse ACLLocalGroups from comma-separated string
+	if config.ACLLocalGroupsStr != "" {
+		config.ACLLocalGroups = ParseGroupList(config.ACLLocalGroupsStr)
+	}
+
 	if err := validateConfig(&config); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
 	}
@@ -115,6 +226,10 @@ func validateConfig(config *Config) error {
 		}
 	}
 
+	if err := validateTenantConfig(config); err != nil {
+		return fmt.Errorf("tenant configuration validation failed: %w", err)
+	}
+
 	// Validate Slack search configuration (always validate to catch early misconfiguration)
 	if err := validateSlackSearchConfig(config); err != nil {
 		return fmt.Errorf("slack search configuration validation failed: %w", err)
@@ -382,6 +497,43 @@ func validateMCPConfig(config *Config) error {
 		return fmt.Errorf("MCP_DEFAULT_TIMEOUT_SECONDS cannot exceed 300 seconds")
 	}
 
+	if config.MCPAskMaxTokens <= 0 {
+		return fmt.Errorf("MCP_ASK_MAX_TOKENS must be greater than 0")
+	}
+
+	if config.MCPRateLimitEnabled {
+		if config.MCPRateLimitPerMinute <= 0 {
+			return fmt.Errorf("MCP_RATE_LIMIT_PER_MINUTE must be greater than 0")
+		}
+		if config.MCPRateLimitBurst <= 0 {
+			return fmt.Errorf("MCP_RATE_LIMIT_BURST must be greater than 0")
+		}
+	}
+	if config.MCPDailyQuota < 0 {
+		return fmt.Errorf("MCP_DAILY_QUOTA cannot be negative")
+	}
+	if config.MCPOAuthResource != "" {
+		resource, err := url.Parse(config.MCPOAuthResource)
+		if err != nil || (resource.Scheme != "https" && resource.Scheme != "http") || resource.Host == "" || resource.Fragment != "" {
+			return fmt.Errorf("MCP_OAUTH_RESOURCE must be an absolute http(s) URL without a fragment: %s", config.MCPOAuthResource)
+		}
+	}
+	if config.MCPAPIKeysReloadInterval < 0 {
+		return fmt.Errorf("MCP_API_KEYS_RELOAD_INTERVAL cannot be negative")
+	}
+	if config.MCPOIDCSessionKey != "" {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(config.MCPOIDCSessionKey))
+		if err != nil || len(key) != 32 {
+			return fmt.Errorf("MCP_OIDC_SESSION_KEY must be a base64-encoded 32-byte key")
+		}
+	}
+	if config.MCPOIDCSessionTTL < 0 {
+		return fmt.Errorf("MCP_OIDC_SESSION_TTL cannot be negative")
+	}
+	if config.MCPOIDCRefreshBefore < 0 {
+		return fmt.Errorf("MCP_OIDC_REFRESH_BEFORE cannot be negative")
+	}
+
 	return nil
 }
 
@@ -688,7 +840,23 @@ func validateVectorBackend(config *Config) error {
 			return fmt.Errorf("AWS_S3_VECTOR_INDEX is required when VECTOR_DB_BACKEND is s3")
 		}
 	case "sqlite":
-		// No S3 validation needed for SQLite backend
+		switch config.SqliteVecQuantization {
+		case "", "none", "int8", "binary":
+		default:
+			return fmt.Errorf("SQLITE_VEC_QUANTIZATION must be none, int8 or binary, got: %q", config.SqliteVecQuantization)
+		}
+		switch config.SqliteVecStorage {
+		case "", "full":
+		case "quantized":
+			if config.SqliteVecQuantization == "" || config.SqliteVecQuantization == "none" {
+				return fmt.Errorf("SQLITE_VEC_STORAGE=quantized requires SQLITE_VEC_QUANTIZATION to be int8 or binary")
+			}
+		default:
+			return fmt.Errorf("SQLITE_VEC_STORAGE must be full or quantized, got: %q", config.SqliteVecStorage)
+		}
+		if config.SqliteVecRescoreFactor < 0 || config.SqliteVecANNLists < 0 || config.SqliteVecANNProbes < 0 {
+			return fmt.Errorf("SQLITE_VEC_RESCORE_FACTOR, SQLITE_VEC_ANN_LISTS and SQLITE_VEC_ANN_PROBES must not be negative")
+		}
 	default:
 		return fmt.Errorf("VECTOR_DB_BACKEND must be either \"s3\" or \"sqlite\", got: %q", config.VectorDBBackend)
 	}
@@ -159,3 +159,31 @@ func TestBedrockBearerTokenEmpty(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, cfg.BedrockBearerToken)
 }
+
+func TestMCPAskDisabledByDefault(t *testing.T) {
+	disableSecretsManager(t)
+	loadDotEnvForTest(t)
+	setRequiredEnvVars(t)
+	t.Setenv("VECTOR_DB_BACKEND", "sqlite")
+	t.Setenv("AWS_S3_VECTOR_BUCKET", "")
+	t.Setenv("AWS_S3_VECTOR_INDEX", "")
+
+	previousValue, wasSet := os.LookupEnv("MCP_ASK_ENABLED")
+	require.NoError(t, os.Unsetenv("MCP_ASK_ENABLED"))
+	t.Cleanup(func() {
+		if wasSet {
+			require.NoError(t, os.Setenv("MCP_ASK_ENABLED", previousValue))
+			return
+		}
+		require.NoError(t, os.Unsetenv("MCP_ASK_ENABLED"))
+	})
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.MCPAskEnabled, "the ask tool is opt-in")
+
+	t.Setenv("MCP_ASK_ENABLED", "true")
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.MCPAskEnabled)
+}
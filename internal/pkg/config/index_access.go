@@ -0,0 +1,106 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrNoIndexAccess is returned when none of the indexes a caller asked for may be searched
+// by that caller.
+var ErrNoIndexAccess = errors.New("none of the requested indexes is available to the caller")
+
+// indexNamePattern accepts concrete index and alias names. Wildcards, comma-separated lists,
+// "-" exclusions and remote "cluster:index" names are rejected.
+var indexNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,254}$`)
+
+// ValidateIndexName checks that name is a single concrete index or alias.
+func ValidateIndexName(name string) error {
+	if !indexNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid index %q (use a concrete lowercase index name without wildcards or commas)", name)
+	}
+	return nil
+}
+
+// ParseIndexAllowlist parses the comma-separated MCP_FEDERATED_INDEXES list.
+func ParseIndexAllowlist(spec string) ([]string, error) {
+	var indexes []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if err := ValidateIndexName(name); err != nil {
+			return nil, err
+		}
+		seen[name] = true
+		indexes = append(indexes, name)
+	}
+	return indexes, nil
+}
+
+// CallerIndexes returns the requested indexes that a caller restricted to tenants may
+// search: the indexes holding its own tenants' documents and the MCP_FEDERATED_INDEXES
+// entries, minus per-tenant indexes of other tenants. Names that are not concrete indexes
+// are rejected, and ErrNoIndexAccess is returned when no requested index is left.
+func (c *Config) CallerIndexes(base string, tenants, requested []string) ([]string, error) {
+	allowed := make(map[string]bool)
+	for _, name := range strings.Split(c.TenantIndexSpec(base, tenants), ",") {
+		if name != "" && ValidateIndexName(name) == nil {
+			allowed[name] = true
+		}
+	}
+	if c != nil {
+		for _, name := range c.MCPFederatedIndexes {
+			allowed[name] = true
+		}
+	}
+
+	var indexes []string
+	for _, name := range requested {
+		name = strings.TrimSpace(name)
+		if err := ValidateIndexName(name); err != nil {
+			return nil, err
+		}
+		if c.isOtherTenantIndex(base, tenants, name) {
+			continue
+		}
+		if allowed[name] || c.isUnrestrictedTenantIndex(base, tenants, name) {
+			indexes = append(indexes, name)
+		}
+	}
+	if len(indexes) == 0 {
+		return nil, ErrNoIndexAccess
+	}
+	return indexes, nil
+}
+
+// isUnrestrictedTenantIndex reports whether name is a per-tenant index that a caller
+// without a tenant restriction already searches through the "<base>-*" pattern.
+func (c *Config) isUnrestrictedTenantIndex(base string, tenants []string, name string) bool {
+	if c == nil || !c.TenantIndexPerTenant || base == "" || len(tenants) > 0 {
+		return false
+	}
+	tenant, ok := strings.CutPrefix(name, base+"-")
+	return ok && ValidateTenantName(tenant) == nil
+}
+
+// isOtherTenantIndex reports whether name is the per-tenant index of a tenant the caller
+// does not belong to.
+func (c *Config) isOtherTenantIndex(base string, tenants []string, name string) bool {
+	if c == nil || !c.TenantIndexPerTenant || base == "" || len(tenants) == 0 {
+		return false
+	}
+	tenant, ok := strings.CutPrefix(name, base+"-")
+	if !ok || ValidateTenantName(tenant) != nil {
+		return false
+	}
+	for _, own := range tenants {
+		if own == tenant {
+			return false
+		}
+	}
+	return true
+}
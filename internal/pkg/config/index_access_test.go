@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIndexAllowlist(t *testing.T) {
+	indexes, err := ParseIndexAllowlist(" runbooks, tickets ,runbooks,")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"runbooks", "tickets"}, indexes)
+
+	for _, spec := range []string{"tickets-*", "docs,-secret", "remote:docs", "Docs", "docs^2"} {
+		_, err := ParseIndexAllowlist(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestCallerIndexes(t *testing.T) {
+	shared := &Config{MCPFederatedIndexes: []string{"runbooks"}}
+	indexes, err := shared.CallerIndexes("docs", nil, []string{"docs", "runbooks", "payroll"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs", "runbooks"}, indexes)
+
+	_, err = shared.CallerIndexes("docs", nil, []string{"payroll"})
+	assert.ErrorIs(t, err, ErrNoIndexAccess)
+	_, err = shared.CallerIndexes("docs", nil, []string{"*"})
+	assert.Error(t, err)
+	_, err = shared.CallerIndexes("docs", nil, []string{"docs,payroll"})
+	assert.Error(t, err)
+
+	var unconfigured *Config
+	indexes, err = unconfigured.CallerIndexes("docs", nil, []string{"docs", "runbooks"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs"}, indexes)
+
+	perTenant := &Config{TenantIndexPerTenant: true, MCPFederatedIndexes: []string{"runbooks", "docs-finance"}}
+	indexes, err = perTenant.CallerIndexes("docs", []string{"hr"}, []string{"docs-hr", "docs-finance", "runbooks"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs-hr", "runbooks"}, indexes, "allowlisted indexes of other tenants are dropped")
+	_, err = perTenant.CallerIndexes("docs", []string{"hr"}, []string{"docs-finance"})
+	assert.ErrorIs(t, err, ErrNoIndexAccess)
+
+	indexes, err = perTenant.CallerIndexes("docs", nil, []string{"docs-hr", "docs-finance"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs-hr", "docs-finance"}, indexes, "unrestricted callers search every tenant index")
+}
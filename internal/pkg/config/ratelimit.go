@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseToolLimits parses "tool=limit,..." pairs as used by MCP_RATE_LIMIT_TOOLS and
+// MCP_DAILY_QUOTA_TOOLS. Limits must be positive integers.
+func ParseToolLimits(spec string) (map[string]int, error) {
+	limits := make(map[string]int)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		tool, value, ok := strings.Cut(pair, "=")
+		tool = strings.TrimSpace(tool)
+		if !ok || tool == "" {
+			return nil, fmt.Errorf("invalid entry %q (expected tool=limit)", pair)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("tool %s has an invalid limit %q", tool, strings.TrimSpace(value))
+		}
+		limits[tool] = limit
+	}
+	return limits, nil
+}
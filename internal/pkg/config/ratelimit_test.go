@@ -0,0 +1,19 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseToolLimits(t *testing.T) {
+	limits, err := ParseToolLimits("ask=10, hybrid_search = 120,,kb_get_document=30")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"ask": 10, "hybrid_search": 120, "kb_get_document": 30}, limits)
+
+	for _, spec := range []string{"ask", "=10", "ask=ten", "ask=0", "ask=-1"} {
+		_, err := ParseToolLimits(spec)
+		assert.Error(t, err, spec)
+	}
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrNoTenantAccess is returned when tenant isolation is enabled and a caller
+// cannot be mapped to any tenant.
+var ErrNoTenantAccess = errors.New("caller is not assigned to any tenant")
+
+// tenantNamePattern restricts tenant names to values that are valid in index names.
+var tenantNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ValidateTenantName checks that name can be stored as a tenant and used as an index suffix.
+func ValidateTenantName(name string) error {
+	if !tenantNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid tenant %q (use up to 64 lowercase letters, digits, '-' or '_')", name)
+	}
+	return nil
+}
+
+// ParseTenantSlackChannels parses "C0123=finance|hr,C0456=ops" into a channel to tenants map.
+// The channel "*" applies to channels without their own entry.
+func ParseTenantSlackChannels(spec string) (map[string][]string, error) {
+	channels := make(map[string][]string)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		channel, tenantList, ok := strings.Cut(pair, "=")
+		channel = strings.TrimSpace(channel)
+		if !ok || channel == "" {
+			return nil, fmt.Errorf("invalid entry %q (expected CHANNEL=tenant|tenant)", pair)
+		}
+		tenants := normalizeNames(strings.Split(tenantList, "|"))
+		if len(tenants) == 0 {
+			return nil, fmt.Errorf("channel %s has no tenants", channel)
+		}
+		channels[channel] = tenants
+	}
+	return channels, nil
+}
+
+// validateTenantConfig validates tenant names and the isolation settings.
+func validateTenantConfig(config *Config) error {
+	if config.TenantID != "" {
+		if err := ValidateTenantName(config.TenantID); err != nil {
+			return fmt.Errorf("TENANT_ID: %w", err)
+		}
+	}
+	for channel, tenants := range config.TenantSlackChannels {
+		for _, tenant := range tenants {
+			if err := ValidateTenantName(tenant); err != nil {
+				return fmt.Errorf("TENANT_SLACK_CHANNELS %s: %w", channel, err)
+			}
+		}
+	}
+	if config.TenantIsolation && strings.TrimSpace(config.TenantOIDCClaim) == "" && len(config.TenantSlackChannels) == 0 {
+		return fmt.Errorf("TENANT_ISOLATION requires TENANT_OIDC_CLAIM or TENANT_SLACK_CHANNELS")
+	}
+	return nil
+}
+
+// TenantIndexName returns the index that stores tenant's documents: base itself, or
+// "<base>-<tenant>" when TENANT_INDEX_PER_TENANT is enabled.
+func (c *Config) TenantIndexName(base, tenant string) string {
+	if c == nil || !c.TenantIndexPerTenant || tenant == "" || base == "" {
+		return base
+	}
+	return base + "-" + tenant
+}
+
+// TenantIndexSpec returns the index list searched for tenants. With per-tenant indexes
+// this is one index per tenant, or the "<base>-*" pattern for unrestricted callers.
+func (c *Config) TenantIndexSpec(base string, tenants []string) string {
+	if c == nil || !c.TenantIndexPerTenant || base == "" {
+		return base
+	}
+	if len(tenants) == 0 {
+		return base + "-*"
+	}
+	indexes := make([]string, len(tenants))
+	for i, tenant := range tenants {
+		indexes[i] = c.TenantIndexName(base, tenant)
+	}
+	return strings.Join(indexes, ",")
+}
+
+// TenantsFromClaims returns the tenants listed in the TENANT_OIDC_CLAIM claim. The claim
+// may be an array or a comma- or space-separated string.
+func (c *Config) TenantsFromClaims(claims map[string]interface{}) []string {
+	if c == nil || c.TenantOIDCClaim == "" {
+		return nil
+	}
+	return validTenants(normalizeNames(claimValues(claims, c.TenantOIDCClaim)))
+}
+
+// TenantsForSlackChannel returns the tenants mapped to a Slack channel, falling back to "*".
+func (c *Config) TenantsForSlackChannel(channelID string) []string {
+	if c == nil || len(c.TenantSlackChannels) == 0 {
+		return nil
+	}
+	if tenants, ok := c.TenantSlackChannels[strings.TrimSpace(channelID)]; ok {
+		return tenants
+	}
+	return c.TenantSlackChannels["*"]
+}
+
+// ResolveSearchTenants applies the isolation policy to the tenants resolved for a caller.
+// A nil result without error means the search is not restricted to any tenant.
+func (c *Config) ResolveSearchTenants(callerTenants []string) ([]string, error) {
+	if len(callerTenants) > 0 {
+		return callerTenants, nil
+	}
+	if c != nil && c.TenantIsolation {
+		return nil, ErrNoTenantAccess
+	}
+	return nil, nil
+}
+
+// claimValues reads a claim that may be an array or a comma- or space-separated string.
+func claimValues(claims map[string]interface{}, claim string) []string {
+	if claims == nil {
+		return nil
+	}
+	var raw []string
+	switch v := claims[claim].(type) {
+	case string:
+		raw = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	case []string:
+		raw = v
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	}
+	return raw
+}
+
+// normalizeNames lowercases, trims, de-duplicates and sorts tenant or group names.
+func normalizeNames(raw []string) []string {
+	seen := make(map[string]struct{}, len(raw))
+	out := make([]string, 0, len(raw))
+	for _, name := range raw {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// validTenants drops claim values that are not valid tenant names.
+func validTenants(tenants []string) []string {
+	out := tenants[:0]
+	for _, tenant := range tenants {
+		if ValidateTenantName(tenant) == nil {
+			out = append(out, tenant)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
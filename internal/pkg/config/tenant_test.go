@@ -0,0 +1,66 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTenantSlackChannels(t *testing.T) {
+	channels, err := ParseTenantSlackChannels("C0123=finance|HR, C0456=ops,*=public")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"finance", "hr"}, channels["C0123"])
+	assert.Equal(t, []string{"ops"}, channels["C0456"])
+
+	cfg := &Config{TenantSlackChannels: channels}
+	assert.Equal(t, []string{"ops"}, cfg.TenantsForSlackChannel("C0456"))
+	assert.Equal(t, []string{"public"}, cfg.TenantsForSlackChannel("C9999"))
+
+	_, err = ParseTenantSlackChannels("C0123")
+	assert.Error(t, err)
+	_, err = ParseTenantSlackChannels("C0123=")
+	assert.Error(t, err)
+}
+
+func TestTenantsFromClaims(t *testing.T) {
+	cfg := &Config{TenantOIDCClaim: "tenants"}
+	assert.Equal(t, []string{"finance", "hr"}, cfg.TenantsFromClaims(map[string]interface{}{
+		"tenants": []interface{}{"HR", "finance", "finance", "bad tenant!"},
+	}))
+	assert.Equal(t, []string{"finance", "hr"}, cfg.TenantsFromClaims(map[string]interface{}{"tenants": "hr, finance"}))
+	assert.Nil(t, cfg.TenantsFromClaims(map[string]interface{}{"groups": []interface{}{"hr"}}))
+}
+
+func TestResolveSearchTenants(t *testing.T) {
+	open := &Config{}
+	tenants, err := open.ResolveSearchTenants(nil)
+	require.NoError(t, err)
+	assert.Nil(t, tenants)
+
+	isolated := &Config{TenantIsolation: true}
+	_, err = isolated.ResolveSearchTenants(nil)
+	assert.ErrorIs(t, err, ErrNoTenantAccess)
+
+	tenants, err = isolated.ResolveSearchTenants([]string{"hr"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hr"}, tenants)
+}
+
+func TestTenantIndexSpec(t *testing.T) {
+	shared := &Config{}
+	assert.Equal(t, "docs", shared.TenantIndexSpec("docs", []string{"hr"}))
+	assert.Equal(t, "docs", shared.TenantIndexName("docs", "hr"))
+
+	perTenant := &Config{TenantIndexPerTenant: true}
+	assert.Equal(t, "docs-hr", perTenant.TenantIndexName("docs", "hr"))
+	assert.Equal(t, "docs-finance,docs-hr", perTenant.TenantIndexSpec("docs", []string{"finance", "hr"}))
+	assert.Equal(t, "docs-*", perTenant.TenantIndexSpec("docs", nil))
+}
+
+func TestValidateTenantConfig(t *testing.T) {
+	assert.NoError(t, validateTenantConfig(&Config{TenantID: "finance"}))
+	assert.Error(t, validateTenantConfig(&Config{TenantID: "Finance Team"}))
+	assert.Error(t, validateTenantConfig(&Config{TenantIsolation: true}))
+	assert.NoError(t, validateTenantConfig(&Config{TenantIsolation: true, TenantOIDCClaim: "tenants"}))
+}
@@ -7,20 +7,25 @@ import (
 // Config represents the vectorizer configuration
 type Config struct {
 	// AWS S3 Vectors configuration
-	AWSS3VectorBucket    string        `json:"aws_s3_vector_bucket" env:"AWS_S3_VECTOR_BUCKET"`
-	AWSS3VectorIndex     string        `json:"aws_s3_vector_index" env:"AWS_S3_VECTOR_INDEX"`
-	S3VectorRegion       string        `json:"s3_vector_region" env:"S3_VECTOR_REGION,default=us-east-1"`
-	VectorDBBackend      string        `json:"vector_db_backend" env:"VECTOR_DB_BACKEND,default=s3"`
-	SqliteVecDBPath      string        `json:"sqlite_vec_db_path" env:"SQLITE_VEC_DB_PATH,default=~/.ragent/vectors.db"`
-	S3SourceRegion       string        `json:"s3_source_region" env:"S3_SOURCE_REGION,default=us-east-1"`
-	BedrockRegion        string        `json:"bedrock_region" env:"BEDROCK_REGION,default=us-east-1"`
-	BedrockBearerToken   string        `json:"bedrock_bearer_token" env:"AWS_BEARER_TOKEN_BEDROCK"`
-	ChatModel            string        `json:"chat_model" env:"CHAT_MODEL,default=global.anthropic.claude-sonnet-4-6"`
-	Concurrency          int           `json:"concurrency" env:"VECTORIZER_CONCURRENCY,default=10"`
-	RetryAttempts        int           `json:"retry_attempts" env:"VECTORIZER_RETRY_ATTEMPTS,default=10"`
-	RetryDelay           time.Duration `json:"retry_delay" env:"VECTORIZER_RETRY_DELAY,default=10s"`
-	ExcludeCategoriesStr string        `json:"-" env:"EXCLUDE_CATEGORIES,default=日報"`
-	ExcludeCategories    []string      `json:"exclude_categories"`
+	AWSS3VectorBucket      string        `json:"aws_s3_vector_bucket" env:"AWS_S3_VECTOR_BUCKET"`
+	AWSS3VectorIndex       string        `json:"aws_s3_vector_index" env:"AWS_S3_VECTOR_INDEX"`
+	S3VectorRegion         string        `json:"s3_vector_region" env:"S3_VECTOR_REGION,default=us-east-1"`
+	VectorDBBackend        string        `json:"vector_db_backend" env:"VECTOR_DB_BACKEND,default=s3"`
+	SqliteVecDBPath        string        `json:"sqlite_vec_db_path" env:"SQLITE_VEC_DB_PATH,default=~/.ragent/vectors.db"`
+	SqliteVecQuantization  string        `json:"sqlite_vec_quantization" env:"SQLITE_VEC_QUANTIZATION,default=none"`
+	SqliteVecStorage       string        `json:"sqlite_vec_storage" env:"SQLITE_VEC_STORAGE,default=full"`
+	SqliteVecRescoreFactor int           `json:"sqlite_vec_rescore_factor" env:"SQLITE_VEC_RESCORE_FACTOR,default=4"`
+	SqliteVecANNLists      int           `json:"sqlite_vec_ann_lists" env:"SQLITE_VEC_ANN_LISTS,default=0"`
+	SqliteVecANNProbes     int           `json:"sqlite_vec_ann_probes" env:"SQLITE_VEC_ANN_PROBES,default=8"`
+	S3SourceRegion         string        `json:"s3_source_region" env:"S3_SOURCE_REGION,default=us-east-1"`
+	BedrockRegion          string        `json:"bedrock_region" env:"BEDROCK_REGION,default=us-east-1"`
+	BedrockBearerToken     string        `json:"bedrock_bearer_token" env:"AWS_BEARER_TOKEN_BEDROCK"`
+	ChatModel              string        `json:"chat_model" env:"CHAT_MODEL,default=global.anthropic.claude-sonnet-4-6"`
+	Concurrency            int           `json:"concurrency" env:"VECTORIZER_CONCURRENCY,default=10"`
+	RetryAttempts          int           `json:"retry_attempts" env:"VECTORIZER_RETRY_ATTEMPTS,default=10"`
+	RetryDelay             time.Duration `json:"retry_delay" env:"VECTORIZER_RETRY_DELAY,default=10s"`
+	ExcludeCategoriesStr   string        `json:"-" env:"EXCLUDE_CATEGORIES,default=日報"`
+	ExcludeCategories      []string      `json:"exclude_categories"`
 	// OpenSearch configuration
 	OpenSearchEndpoint          string        `json:"opensearch_endpoint" env:"OPENSEARCH_ENDPOINT"`
 	OpenSearchIndex             string        `json:"opensearch_index" env:"OPENSEARCH_INDEX"`
@@ -36,6 +41,32 @@ type Config struct {
 	OpenSearchMaxIdleConns      int           `json:"opensearch_max_idle_conns" env:"OPENSEARCH_MAX_IDLE_CONNS,default=10"`
 	OpenSearchIdleConnTimeout   time.Duration `json:"opensearch_idle_conn_timeout" env:"OPENSEARCH_IDLE_CONN_TIMEOUT,default=90s"`
 
+	// OpenSearch k-NN index profile (applied when ragent creates an index)
+	OpenSearchKNNEngine           string `json:"opensearch_knn_engine" env:"OPENSEARCH_KNN_ENGINE,default=lucene"`
+	OpenSearchKNNSpaceType        string `json:"opensearch_knn_space_type" env:"OPENSEARCH_KNN_SPACE_TYPE,default=cosinesimil"`
+	OpenSearchKNNM                int    `json:"opensearch_knn_m" env:"OPENSEARCH_KNN_M,default=16"`
+	OpenSearchKNNEfConstruction   int    `json:"opensearch_knn_ef_construction" env:"OPENSEARCH_KNN_EF_CONSTRUCTION,default=256"`
+	OpenSearchKNNEfSearch         int    `json:"opensearch_knn_ef_search" env:"OPENSEARCH_KNN_EF_SEARCH,default=0"`
+	OpenSearchKNNMode             string `json:"opensearch_knn_mode" env:"OPENSEARCH_KNN_MODE"`
+	OpenSearchKNNCompressionLevel string `json:"opensearch_knn_compression_level" env:"OPENSEARCH_KNN_COMPRESSION_LEVEL"`
+	OpenSearchKNNEncoder          string `json:"opensearch_knn_encoder" env:"OPENSEARCH_KNN_ENCODER"`
+	OpenSearchNumberOfShards      int    `json:"opensearch_number_of_shards" env:"OPENSEARCH_NUMBER_OF_SHARDS,default=1"`
+	OpenSearchNumberOfReplicas    int    `json:"opensearch_number_of_replicas" env:"OPENSEARCH_NUMBER_OF_REPLICAS,default=0"`
+
+	// Multi-tenant configuration
+	TenantID               string              `json:"tenant_id" env:"TENANT_ID"`
+	TenantIsolation        bool                `json:"tenant_isolation" env:"TENANT_ISOLATION,default=false"`
+	TenantOIDCClaim        string              `json:"tenant_oidc_claim" env:"TENANT_OIDC_CLAIM,default=tenants"`
+	TenantSlackChannelsStr string              `json:"-" env:"TENANT_SLACK_CHANNELS"`
+	TenantSlackChannels    map[string][]string `json:"tenant_slack_channels"`
+	TenantIndexPerTenant   bool                `json:"tenant_index_per_tenant" env:"TENANT_INDEX_PER_TENANT,default=false"`
+
+	// Group-based document ACL configuration
+	ACLOIDCClaim       string   `json:"acl_oidc_claim" env:"ACL_OIDC_CLAIM,default=groups"`
+	ACLLocalGroupsStr  string   `json:"-" env:"ACL_LOCAL_GROUPS"`
+	ACLLocalGroups     []string `json:"acl_local_groups"`
+	ACLSlackUserGroups bool     `json:"acl_slack_user_groups" env:"ACL_SLACK_USER_GROUPS,default=false"`
+
 	// MCP Server configuration
 	MCPServerEnabled          bool          `json:"mcp_server_enabled" env:"MCP_SERVER_ENABLED,default=false"`
 	MCPServerHost             string        `json:"mcp_server_host" env:"MCP_SERVER_HOST,default=localhost"`
@@ -46,6 +77,7 @@ type Config struct {
 	MCPServerMaxHeaderBytes   int           `json:"mcp_server_max_header_bytes" env:"MCP_SERVER_MAX_HEADER_BYTES,default=1048576"` // 1MB
 	MCPServerGracefulShutdown bool          `json:"mcp_server_graceful_shutdown" env:"MCP_SERVER_GRACEFUL_SHUTDOWN,default=true"`
 	MCPServerShutdownTimeout  time.Duration `json:"mcp_server_shutdown_timeout" env:"MCP_SERVER_SHUTDOWN_TIMEOUT,default=30s"`
+	MCPStdioTrustLocalUser    bool          `json:"mcp_stdio_trust_local_user" env:"MCP_STDIO_TRUST_LOCAL_USER,default=false"`
 
 	// MCP IP Authentication configuration
 	MCPIPAuthEnabled       bool     `json:"mcp_ip_auth_enabled" env:"MCP_IP_AUTH_ENABLED,default=true"`
@@ -61,6 +93,24 @@ type Config struct {
 	MCPTrustedProxiesStr string   `json:"-" env:"MCP_TRUSTED_PROXIES"`
 	MCPTrustedProxies    []string `json:"mcp_trusted_proxies"`
 
+	// MCP OAuth protected resource (RFC 9728) settings applied to OIDC bearer tokens
+	MCPOAuthResource          string   `json:"mcp_oauth_resource" env:"MCP_OAUTH_RESOURCE"`
+	MCPOAuthRequiredScopesStr string   `json:"-" env:"MCP_OAUTH_REQUIRED_SCOPES"`
+	MCPOAuthRequiredScopes    []string `json:"mcp_oauth_required_scopes"`
+	MCPOAuthAllowIDTokens     bool     `json:"mcp_oauth_allow_id_tokens" env:"MCP_OAUTH_ALLOW_ID_TOKENS,default=true"`
+
+	// MCP API key authentication (file path or secretsmanager://SECRET_ID with hashed keys)
+	MCPAPIKeys               string        `json:"mcp_api_keys" env:"MCP_API_KEYS"`
+	MCPAPIKeysReloadInterval time.Duration `json:"mcp_api_keys_reload_interval" env:"MCP_API_KEYS_RELOAD_INTERVAL,default=1m"`
+
+	// MCP OIDC sessions issued by /callback (persisted encrypted when MCP_OIDC_SESSION_KEY is set)
+	MCPOIDCSessionKey    string        `json:"-" env:"MCP_OIDC_SESSION_KEY"`
+	MCPOIDCSessionDB     string        `json:"mcp_oidc_session_db" env:"MCP_OIDC_SESSION_DB"`
+	MCPOIDCSessionTTL    time.Duration `json:"mcp_oidc_session_ttl" env:"MCP_OIDC_SESSION_TTL,default=168h"`
+	MCPOIDCRefreshBefore time.Duration `json:"mcp_oidc_refresh_before" env:"MCP_OIDC_REFRESH_BEFORE,default=5m"`
+	MCPSessionAdminsStr  string        `json:"-" env:"MCP_SESSION_ADMINS"`
+	MCPSessionAdmins     []string      `json:"mcp_session_admins"` // Principals that list and revoke every session on /admin/sessions
+
 	// MCP Tool configuration
 	MCPToolPrefix            string  `json:"mcp_tool_prefix" env:"MCP_TOOL_PREFIX,default="`
 	MCPHybridSearchToolName  string  `json:"mcp_hybrid_search_tool_name" env:"MCP_TOOL_NAME_HYBRID_SEARCH,default=hybrid_search"`
@@ -70,6 +120,38 @@ type Config struct {
 	MCPDefaultUseJapaneseNLP bool    `json:"mcp_default_use_japanese_nlp" env:"MCP_DEFAULT_USE_JAPANESE_NLP,default=true"`
 	MCPDefaultTimeoutSeconds int     `json:"mcp_default_timeout_seconds" env:"MCP_DEFAULT_TIMEOUT_SECONDS,default=30"`
 
+	// Indexes MCP callers may name in addition to their own tenant indexes (concrete names only)
+	MCPFederatedIndexesStr string   `json:"-" env:"MCP_FEDERATED_INDEXES"`
+	MCPFederatedIndexes    []string `json:"mcp_federated_indexes"`
+
+	// MCP ask tool configuration (server-side answer generation)
+	MCPAskEnabled   bool     `json:"mcp_ask_enabled" env:"MCP_ASK_ENABLED,default=false"`
+	MCPAskModelsStr string   `json:"-" env:"MCP_ASK_MODELS"`
+	MCPAskModels    []string `json:"mcp_ask_models"` // Models callers may pick in addition to CHAT_MODEL
+	MCPAskMaxTokens int      `json:"mcp_ask_max_tokens" env:"MCP_ASK_MAX_TOKENS,default=4000"`
+
+	// MCP rate limiting and daily quotas (per authenticated principal and tool)
+	MCPRateLimitEnabled   bool           `json:"mcp_rate_limit_enabled" env:"MCP_RATE_LIMIT_ENABLED,default=false"`
+	MCPRateLimitPerMinute int            `json:"mcp_rate_limit_per_minute" env:"MCP_RATE_LIMIT_PER_MINUTE,default=60"`
+	MCPRateLimitBurst     int            `json:"mcp_rate_limit_burst" env:"MCP_RATE_LIMIT_BURST,default=10"`
+	MCPRateLimitToolsStr  string         `json:"-" env:"MCP_RATE_LIMIT_TOOLS"`
+	MCPRateLimitTools     map[string]int `json:"mcp_rate_limit_tools"` // Per-minute limits overriding MCP_RATE_LIMIT_PER_MINUTE
+	MCPDailyQuota         int            `json:"mcp_daily_quota" env:"MCP_DAILY_QUOTA,default=0"`
+	MCPDailyQuotaToolsStr string         `json:"-" env:"MCP_DAILY_QUOTA_TOOLS"`
+	MCPDailyQuotaTools    map[string]int `json:"mcp_daily_quota_tools"`
+	MCPUsageAdminsStr     string         `json:"-" env:"MCP_USAGE_ADMINS"`
+	MCPUsageAdmins        []string       `json:"mcp_usage_admins"` // Principals that see every client on /admin/usage
+
+	// MCP document write configuration (ingest_document / update_document tools)
+	MCPIngestEnabled      bool     `json:"mcp_ingest_enabled" env:"MCP_INGEST_ENABLED,default=false"`
+	MCPIngestDirectory    string   `json:"mcp_ingest_directory" env:"MCP_INGEST_DIRECTORY,default=./source/mcp"`
+	MCPWriteScopesStr     string   `json:"-" env:"MCP_WRITE_SCOPES,default=ragent:write"`
+	MCPWriteScopes        []string `json:"mcp_write_scopes"`
+	MCPWriteGroupsStr     string   `json:"-" env:"MCP_WRITE_GROUPS"`
+	MCPWriteGroups        []string `json:"mcp_write_groups"`
+	MCPWriteAllowedIPsStr string   `json:"-" env:"MCP_WRITE_ALLOWED_IPS"`
+	MCPWriteAllowedIPs    []string `json:"mcp_write_allowed_ips"`
+
 	// MCP SSE (Server-Sent Events) configuration
 	MCPSSEEnabled           bool          `json:"mcp_sse_enabled" env:"MCP_SSE_ENABLED,default=true"`
 	MCPSSEHeartbeatInterval time.Duration `json:"mcp_sse_heartbeat_interval" env:"MCP_SSE_HEARTBEAT_INTERVAL,default=30s"`
@@ -98,7 +180,8 @@ type Config struct {
 	OTelTracesSamplerArg     float64 `json:"otel_traces_sampler_arg" env:"OTEL_TRACES_SAMPLER_ARG,default=1.0"`
 
 	// GitHub configuration
-	GitHubToken string `json:"github_token" env:"GITHUB_TOKEN"`
+	GitHubToken    string `json:"github_token" env:"GITHUB_TOKEN"`
+	GitHubCacheDir string `json:"github_cache_dir" env:"GITHUB_CACHE_DIR"`
 
 	// OCR configuration
 	OCRProvider        string        `json:"ocr_provider" env:"OCR_PROVIDER"`
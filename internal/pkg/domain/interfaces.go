@@ -9,3 +9,21 @@ type FileScanner interface {
 type Vectorizer interface {
 	VectorizeFiles(ctx context.Context, files []*FileInfo, dryRun bool) (*ProcessingResult, error)
 }
+
+// DocumentWriter saves a submitted document to the ingest directory and indexes it in
+// both backends. With replace set the document must exist and its old chunks are
+// removed first; otherwise the document must not exist yet.
+type DocumentWriter interface {
+	WriteDocument(ctx context.Context, doc *DocumentSubmission, replace bool) (*DocumentWriteResult, error)
+}
+
+// FailureLedger lists documents that failed to vectorize, most recent failure first.
+// RetryCount is the number of failed attempts after the first one.
+type FailureLedger interface {
+	ListRecentFailures(ctx context.Context, limit int) ([]ProcessingError, error)
+}
+
+// ConsistencyReports returns the latest result of `verify`. Returns nil if no check has run.
+type ConsistencyReports interface {
+	LatestConsistencyReport(ctx context.Context) (*ConsistencyReport, error)
+}
@@ -9,18 +9,20 @@ import (
 )
 
 type DocumentMetadata struct {
-	Title        string                 `json:"title"`
-	Category     string                 `json:"category"`
-	Tags         []string               `json:"tags"`
-	CreatedAt    time.Time              `json:"created_at"`
-	UpdatedAt    time.Time              `json:"updated_at"`
-	Author       string                 `json:"author"`
-	Reference    string                 `json:"reference"`
-	Source       string                 `json:"source"`
-	FilePath     string                 `json:"file_path"`
-	WordCount    int                    `json:"word_count"`
-	Secret       bool                   `json:"secret"`
-	CustomFields map[string]interface{} `json:"custom_fields"`
+	Title         string                 `json:"title"`
+	Category      string                 `json:"category"`
+	Tags          []string               `json:"tags"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+	Author        string                 `json:"author"`
+	Reference     string                 `json:"reference"`
+	Source        string                 `json:"source"`
+	FilePath      string                 `json:"file_path"`
+	WordCount     int                    `json:"word_count"`
+	Secret        bool                   `json:"secret"`
+	Tenant        string                 `json:"tenant,omitempty"`
+	AllowedGroups []string               `json:"allowed_groups,omitempty"`
+	CustomFields  map[string]interface{} `json:"custom_fields"`
 }
 
 type FileInfo struct {
@@ -37,6 +39,7 @@ type FileInfo struct {
 	Metadata     DocumentMetadata `json:"metadata"`
 	ContentHash  string           `json:"content_hash,omitempty"`
 	SourceType   string           `json:"source_type,omitempty"`
+	SourcePath   string           `json:"source_path,omitempty"` // File a CSV row or PDF page was expanded from
 	RawBytes     []byte           `json:"-"`
 }
 
@@ -84,6 +87,8 @@ type ProcessingError struct {
 	Timestamp  time.Time           `json:"timestamp"`
 	Retryable  bool                `json:"retryable"`
 	RetryCount int                 `json:"retry_count"`
+	SourcePath string              `json:"source_path,omitempty"` // File the failed document was read from
+	Backend    string              `json:"backend,omitempty"`     // Backend that failed, if any
 }
 
 func (pe *ProcessingError) Error() string {
@@ -97,3 +102,72 @@ func (pe *ProcessingError) IsRetryable() bool {
 func (pe *ProcessingError) IncrementRetry() {
 	pe.RetryCount++
 }
+
+// Consistency issue kinds reported by `verify`
+const (
+	IssueMissingInOpenSearch  = "missing_in_opensearch"   // Vector exists only in the vector store
+	IssueMissingInVectorStore = "missing_in_vector_store" // Document exists only in OpenSearch
+	IssueMissingChunk         = "missing_chunk"           // Chunk absent from both backends
+	IssueDimensionMismatch    = "dimension_mismatch"      // Embedding dimension differs from the rest
+	IssueStaleHashRecord      = "stale_hash_record"       // Hash store record without indexed documents
+)
+
+// ConsistencyIssue is a single inconsistency between the vector store, OpenSearch and the hash store
+type ConsistencyIssue struct {
+	Kind     string `json:"kind"`
+	Backend  string `json:"backend,omitempty"` // "vector_store", "opensearch" or "hashstore"
+	ID       string `json:"id,omitempty"`
+	FilePath string `json:"file_path,omitempty"`
+	Detail   string `json:"detail"`
+}
+
+// ConsistencyReport is the result of a consistency check
+type ConsistencyReport struct {
+	CheckedAt       time.Time          `json:"checked_at"`
+	Index           string             `json:"index"`
+	VectorCount     int                `json:"vector_count"`
+	DocumentCount   int                `json:"document_count"`
+	HashRecordCount int                `json:"hash_record_count"`
+	Issues          []ConsistencyIssue `json:"issues"`
+}
+
+// CountByKind returns the number of issues of each kind
+func (r *ConsistencyReport) CountByKind() map[string]int {
+	counts := make(map[string]int)
+	for _, issue := range r.Issues {
+		counts[issue.Kind]++
+	}
+	return counts
+}
+
+// DocumentSubmission is a markdown document written into the knowledge base by an agent.
+// Category, Tags and Secret override the matching front matter keys of Content.
+type DocumentSubmission struct {
+	Path     string   `json:"path"` // Relative to the ingest directory
+	Content  string   `json:"content"`
+	Category string   `json:"category,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Secret   bool     `json:"secret,omitempty"`
+
+	// Tenant the document is written to; "" keeps the server TENANT_ID
+	Tenant string `json:"tenant,omitempty"`
+	// Access of the caller, nil for a caller that may read every document
+	Access *DocumentAccess `json:"-"`
+}
+
+// DocumentAccess is the read access of the caller writing a document. Writers only
+// replace documents the caller can read and only share documents with its own groups.
+type DocumentAccess struct {
+	ExcludeSecret bool
+	Tenants       []string // nil when the caller is not restricted to tenants
+	Groups        []string
+}
+
+// DocumentWriteResult is the outcome of writing and indexing a submitted document
+type DocumentWriteResult struct {
+	FilePath         string            `json:"file_path"`
+	Replaced         bool              `json:"replaced"`
+	RemovedVectors   int               `json:"removed_vectors"`
+	RemovedDocuments int               `json:"removed_documents"`
+	Processing       *ProcessingResult `json:"processing"`
+}
@@ -11,6 +11,9 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 )
 
+// defaultChatMaxTokens is the output token budget of GenerateChatResponse
+const defaultChatMaxTokens = 4000
+
 // BedrockClient implements the EmbeddingClient interface for AWS Bedrock
 type BedrockClient struct {
 	client  *bedrockruntime.Client
@@ -179,6 +182,11 @@ func (c *BedrockClient) GenerateEmbedding(ctx context.Context, text string) ([]f
 
 // GenerateChatResponse generates a chat response using the configured chat model
 func (c *BedrockClient) GenerateChatResponse(ctx context.Context, messages []ChatMessage) (string, error) {
+	return c.GenerateChatResponseWithMaxTokens(ctx, messages, defaultChatMaxTokens)
+}
+
+// GenerateChatResponseWithMaxTokens generates a chat response limited to maxTokens output tokens
+func (c *BedrockClient) GenerateChatResponseWithMaxTokens(ctx context.Context, messages []ChatMessage, maxTokens int) (string, error) {
 	if len(messages) == 0 {
 		return "", fmt.Errorf("messages cannot be empty")
 	}
@@ -198,11 +206,14 @@ func (c *BedrockClient) GenerateChatResponse(ctx context.Context, messages []Cha
 	if len(sanitized) == 0 {
 		return "", fmt.Errorf("chat messages must include at least one user or assistant message")
 	}
+	if maxTokens <= 0 {
+		return "", fmt.Errorf("max tokens must be positive, got %d", maxTokens)
+	}
 
 	// Prepare request payload for Claude models in AWS Bedrock format
 	request := ChatRequest{
 		Messages:         sanitized,
-		MaxTokens:        4000,
+		MaxTokens:        maxTokens,
 		Temperature:      0.7,
 		AnthropicVersion: "bedrock-2023-05-31",
 	}
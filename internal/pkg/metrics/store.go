@@ -58,6 +58,14 @@ func NewStoreWithPath(dbPath string) (*Store, error) {
 			count INTEGER DEFAULT 0,
 			PRIMARY KEY (mode, date)
 		);
+		CREATE TABLE IF NOT EXISTS client_usage (
+			principal TEXT NOT NULL,
+			tool TEXT NOT NULL,
+			date TEXT NOT NULL,
+			calls INTEGER DEFAULT 0,
+			rejected INTEGER DEFAULT 0,
+			PRIMARY KEY (principal, tool, date)
+		);
 	`
 	if _, err := db.Exec(createTableSQL); err != nil {
 		_ = db.Close()
@@ -147,6 +155,81 @@ func (s *Store) GetCountByDate(mode Mode, date string) (int64, error) {
 	return count, nil
 }
 
+// ClientUsage is the daily tool call count of one MCP client principal.
+type ClientUsage struct {
+	Principal string `json:"principal"`
+	Tool      string `json:"tool"`
+	Date      string `json:"date"`
+	Calls     int64  `json:"calls"`
+	Rejected  int64  `json:"rejected"`
+}
+
+// RecordClientUsage counts a tool call of principal for today's date.
+// Rejected calls (rate limited or over quota) are counted separately.
+func (s *Store) RecordClientUsage(principal, tool string, rejected bool) error {
+	today := time.Now().Format("2006-01-02")
+	calls, rejects := 1, 0
+	if rejected {
+		calls, rejects = 0, 1
+	}
+
+	upsertSQL := `
+		INSERT INTO client_usage (principal, tool, date, calls, rejected)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(principal, tool, date) DO UPDATE SET
+			calls = calls + excluded.calls,
+			rejected = rejected + excluded.rejected;
+	`
+	if _, err := s.db.Exec(upsertSQL, principal, tool, today, calls, rejects); err != nil {
+		return fmt.Errorf("failed to record client usage: %w", err)
+	}
+	return nil
+}
+
+// GetClientCalls returns the accepted calls of principal on date.
+// An empty tool sums the calls of all tools.
+func (s *Store) GetClientCalls(principal, tool, date string) (int64, error) {
+	query := "SELECT COALESCE(SUM(calls), 0) FROM client_usage WHERE principal = ? AND date = ?"
+	args := []interface{}{principal, date}
+	if tool != "" {
+		query += " AND tool = ?"
+		args = append(args, tool)
+	}
+
+	var calls int64
+	if err := s.db.QueryRow(query, args...).Scan(&calls); err != nil {
+		return 0, fmt.Errorf("failed to get calls for %s: %w", principal, err)
+	}
+	return calls, nil
+}
+
+// GetClientUsageByDate returns the usage of every principal and tool on date.
+func (s *Store) GetClientUsageByDate(date string) ([]ClientUsage, error) {
+	rows, err := s.db.Query(
+		"SELECT principal, tool, date, calls, rejected FROM client_usage WHERE date = ? ORDER BY principal, tool",
+		date,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query client usage: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	usage := make([]ClientUsage, 0)
+	for rows.Next() {
+		var u ClientUsage
+		if err := rows.Scan(&u.Principal, &u.Tool, &u.Date, &u.Calls, &u.Rejected); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		usage = append(usage, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return usage, nil
+}
+
 // Close closes the database connection.
 func (s *Store) Close() error {
 	if s.db != nil {
@@ -143,6 +143,60 @@ func TestGetAllTotals(t *testing.T) {
 	}
 }
 
+func TestClientUsage(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test_stats.db")
+
+	store, err := NewStoreWithPath(dbPath)
+	if err != nil {
+		t.Fatalf("NewStoreWithPath failed: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	_ = store.RecordClientUsage("oidc:alice", "ask", false)
+	_ = store.RecordClientUsage("oidc:alice", "ask", false)
+	_ = store.RecordClientUsage("oidc:alice", "ask", true)
+	_ = store.RecordClientUsage("oidc:alice", "hybrid_search", false)
+	_ = store.RecordClientUsage("ip:10.0.0.1", "ask", false)
+
+	today := time.Now().Format("2006-01-02")
+	calls, err := store.GetClientCalls("oidc:alice", "ask", today)
+	if err != nil {
+		t.Fatalf("GetClientCalls failed: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 ask calls, got %d", calls)
+	}
+
+	calls, err = store.GetClientCalls("oidc:alice", "", today)
+	if err != nil {
+		t.Fatalf("GetClientCalls failed: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls across tools, got %d", calls)
+	}
+
+	usage, err := store.GetClientUsageByDate(today)
+	if err != nil {
+		t.Fatalf("GetClientUsageByDate failed: %v", err)
+	}
+	if len(usage) != 3 {
+		t.Fatalf("Expected 3 usage rows, got %d", len(usage))
+	}
+	ask := usage[1]
+	if ask.Principal != "oidc:alice" || ask.Tool != "ask" || ask.Calls != 2 || ask.Rejected != 1 {
+		t.Errorf("Unexpected usage row: %+v", ask)
+	}
+
+	usage, err = store.GetClientUsageByDate("2000-01-01")
+	if err != nil {
+		t.Fatalf("GetClientUsageByDate failed: %v", err)
+	}
+	if len(usage) != 0 {
+		t.Errorf("Expected no usage for another date, got %d rows", len(usage))
+	}
+}
+
 func TestModeConstants(t *testing.T) {
 	// Verify mode constants are as expected
 	if ModeMCP != "mcp" {
@@ -0,0 +1,34 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// accessFieldMappings maps the fields matched exactly by the tenant and group filters
+var accessFieldMappings = map[string]any{
+	"properties": map[string]any{
+		"tenant":         map[string]any{"type": "keyword"},
+		"allowed_groups": map[string]any{"type": "keyword"},
+	},
+}
+
+// EnsureAccessFieldMappings adds the keyword mappings of tenant and allowed_groups to an index
+// created before these fields existed. OpenSearch rejects the update when documents already
+// mapped a field dynamically as text; such indexes must be rebuilt.
+func (c *Client) EnsureAccessFieldMappings(ctx context.Context, index string) error {
+	body, err := json.Marshal(accessFieldMappings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal access field mappings: %w", err)
+	}
+	found, err := c.perform(ctx, rawRequest{method: http.MethodPut, path: "/" + index + "/_mapping", body: body}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to map tenant and allowed_groups as keywords on %s (run `ragent index rebuild` if they are mapped as text): %w", index, err)
+	}
+	if !found {
+		return fmt.Errorf("index %s not found", index)
+	}
+	return nil
+}
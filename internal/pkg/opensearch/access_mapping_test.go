@@ -0,0 +1,41 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_EnsureAccessFieldMappings(t *testing.T) {
+	var received map[string]map[string]map[string]string
+	client := newAliasTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/docs/_mapping":
+			body, _ := io.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(body, &received))
+			_, _ = w.Write([]byte(`{"acknowledged":true}`))
+		case r.URL.Path == "/legacy/_mapping":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"mapper [tenant] cannot be changed from type [text] to [keyword]","status":400}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"no such index","status":404}`))
+		}
+	})
+	ctx := context.Background()
+
+	require.NoError(t, client.EnsureAccessFieldMappings(ctx, "docs"))
+	assert.Equal(t, "keyword", received["properties"]["tenant"]["type"])
+	assert.Equal(t, "keyword", received["properties"]["allowed_groups"]["type"])
+
+	err := client.EnsureAccessFieldMappings(ctx, "legacy")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "index rebuild")
+	assert.Error(t, client.EnsureAccessFieldMappings(ctx, "missing"))
+}
@@ -0,0 +1,139 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// TermCount is a value of a keyword field with the number of chunks carrying it and the
+// approximate number of distinct documents (by file_path) they belong to
+type TermCount struct {
+	Value     string `json:"value"`
+	Chunks    int    `json:"chunks"`
+	Documents int    `json:"documents"`
+}
+
+// DocumentSummary describes the documents matching a query. Documents, Categories and Tags
+// are cardinality estimates.
+type DocumentSummary struct {
+	Chunks          int    `json:"chunks"`
+	Documents       int    `json:"documents"`
+	Categories      int    `json:"categories"`
+	Tags            int    `json:"tags"`
+	LatestUpdatedAt string `json:"latest_updated_at,omitempty"`
+	LatestIndexedAt string `json:"latest_indexed_at,omitempty"`
+}
+
+type cardinalityAggregation struct {
+	Value int `json:"value"`
+}
+
+type maxAggregation struct {
+	ValueAsString string `json:"value_as_string"`
+}
+
+// AggregateTerms returns the size most frequent values of a keyword field among the
+// documents matching a query DSL clause, ordered by chunk count
+func (c *Client) AggregateTerms(ctx context.Context, index, field string, match map[string]any, size int) ([]TermCount, error) {
+	if size <= 0 {
+		size = 100
+	}
+	body := map[string]any{
+		"size":  0,
+		"query": match,
+		"aggs": map[string]any{
+			"values": map[string]any{
+				"terms": map[string]any{"field": field, "size": size},
+				"aggs": map[string]any{
+					"documents": map[string]any{"cardinality": map[string]any{"field": "file_path"}},
+				},
+			},
+		},
+	}
+
+	var data struct {
+		Aggregations struct {
+			Values struct {
+				Buckets []struct {
+					Key       string                 `json:"key"`
+					DocCount  int                    `json:"doc_count"`
+					Documents cardinalityAggregation `json:"documents"`
+				} `json:"buckets"`
+			} `json:"values"`
+		} `json:"aggregations"`
+	}
+	if err := c.searchAggregations(ctx, index, body, &data); err != nil {
+		return nil, err
+	}
+
+	counts := make([]TermCount, 0, len(data.Aggregations.Values.Buckets))
+	for _, bucket := range data.Aggregations.Values.Buckets {
+		counts = append(counts, TermCount{
+			Value:     bucket.Key,
+			Chunks:    bucket.DocCount,
+			Documents: bucket.Documents.Value,
+		})
+	}
+	return counts, nil
+}
+
+// SummarizeDocuments counts the chunks, documents, categories and tags matching a query DSL
+// clause and reports the latest update and indexing times
+func (c *Client) SummarizeDocuments(ctx context.Context, index string, match map[string]any) (*DocumentSummary, error) {
+	body := map[string]any{
+		"size":             0,
+		"track_total_hits": true,
+		"query":            match,
+		"aggs": map[string]any{
+			"documents":      map[string]any{"cardinality": map[string]any{"field": "file_path"}},
+			"categories":     map[string]any{"cardinality": map[string]any{"field": "category"}},
+			"tags":           map[string]any{"cardinality": map[string]any{"field": "tags"}},
+			"latest_updated": map[string]any{"max": map[string]any{"field": "updated_at"}},
+			"latest_indexed": map[string]any{"max": map[string]any{"field": "indexed_at"}},
+		},
+	}
+
+	var data struct {
+		Hits struct {
+			Total struct {
+				Value int `json:"value"`
+			} `json:"total"`
+		} `json:"hits"`
+		Aggregations struct {
+			Documents     cardinalityAggregation `json:"documents"`
+			Categories    cardinalityAggregation `json:"categories"`
+			Tags          cardinalityAggregation `json:"tags"`
+			LatestUpdated maxAggregation         `json:"latest_updated"`
+			LatestIndexed maxAggregation         `json:"latest_indexed"`
+		} `json:"aggregations"`
+	}
+	if err := c.searchAggregations(ctx, index, body, &data); err != nil {
+		return nil, err
+	}
+
+	return &DocumentSummary{
+		Chunks:          data.Hits.Total.Value,
+		Documents:       data.Aggregations.Documents.Value,
+		Categories:      data.Aggregations.Categories.Value,
+		Tags:            data.Aggregations.Tags.Value,
+		LatestUpdatedAt: data.Aggregations.LatestUpdated.ValueAsString,
+		LatestIndexedAt: data.Aggregations.LatestIndexed.ValueAsString,
+	}, nil
+}
+
+func (c *Client) searchAggregations(ctx context.Context, index string, body map[string]any, data any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal aggregation query: %w", err)
+	}
+	found, err := c.perform(ctx, rawRequest{method: http.MethodPost, path: "/" + index + "/_search", body: payload}, data)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("index %s not found", index)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_AggregateTerms(t *testing.T) {
+	var received map[string]any
+	client := newAliasTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/docs/_search", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &received))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"hits":{"total":{"value":12}},"aggregations":{"values":{"buckets":[
+			{"key":"Runbook","doc_count":9,"documents":{"value":3}},
+			{"key":"Design","doc_count":3,"documents":{"value":2}}]}}}`))
+	})
+
+	counts, err := client.AggregateTerms(context.Background(), "docs", "category", map[string]any{"match_all": map[string]any{}}, 50)
+	require.NoError(t, err)
+	assert.Equal(t, []TermCount{
+		{Value: "Runbook", Chunks: 9, Documents: 3},
+		{Value: "Design", Chunks: 3, Documents: 2},
+	}, counts)
+
+	assert.EqualValues(t, 0, received["size"])
+	terms := received["aggs"].(map[string]any)["values"].(map[string]any)["terms"].(map[string]any)
+	assert.Equal(t, "category", terms["field"])
+	assert.EqualValues(t, 50, terms["size"])
+}
+
+func TestClient_SummarizeDocuments(t *testing.T) {
+	client := newAliasTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"hits":{"total":{"value":42}},"aggregations":{
+			"documents":{"value":10},"categories":{"value":4},"tags":{"value":7},
+			"latest_updated":{"value":1767225600000,"value_as_string":"2026-01-01T00:00:00.000Z"},
+			"latest_indexed":{"value":null}}}`))
+	})
+
+	summary, err := client.SummarizeDocuments(context.Background(), "docs", map[string]any{"match_all": map[string]any{}})
+	require.NoError(t, err)
+	assert.Equal(t, &DocumentSummary{
+		Chunks:          42,
+		Documents:       10,
+		Categories:      4,
+		Tags:            7,
+		LatestUpdatedAt: "2026-01-01T00:00:00.000Z",
+	}, summary)
+}
+
+func TestClient_AggregateTermsMissingIndex(t *testing.T) {
+	client := newAliasTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"no such index","status":404}`))
+	})
+
+	_, err := client.AggregateTerms(context.Background(), "missing", "tags", map[string]any{"match_all": map[string]any{}}, 10)
+	assert.ErrorContains(t, err, "index missing not found")
+}
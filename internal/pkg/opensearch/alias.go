@@ -0,0 +1,168 @@
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	opensearchgo "github.com/opensearch-project/opensearch-go/v4"
+)
+
+// rawRequest is a request to an index management endpoint without a typed wrapper
+type rawRequest struct {
+	method string
+	path   string
+	body   []byte
+}
+
+// GetRequest implements opensearchgo.Request
+func (r rawRequest) GetRequest() (*http.Request, error) {
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+	header := http.Header{}
+	if r.body != nil {
+		header.Set("Content-Type", "application/json")
+	}
+	return opensearchgo.BuildRequest(r.method, r.path, body, nil, header)
+}
+
+// AliasAction is a single action of an atomic alias update
+type AliasAction struct {
+	Add         *AliasTarget `json:"add,omitempty"`
+	Remove      *AliasTarget `json:"remove,omitempty"`
+	RemoveIndex *AliasTarget `json:"remove_index,omitempty"`
+}
+
+// AliasTarget names the index (and alias) an AliasAction applies to
+type AliasTarget struct {
+	Index string `json:"index"`
+	Alias string `json:"alias,omitempty"`
+}
+
+// perform executes a raw request. A 404 response is reported as found=false without an error.
+func (c *Client) perform(ctx context.Context, req rawRequest, data any) (bool, error) {
+	if err := c.WaitForRateLimit(ctx); err != nil {
+		return false, fmt.Errorf("rate limit error: %w", err)
+	}
+
+	resp, err := c.client.Client.Do(ctx, req, data)
+	if err != nil {
+		return false, ClassifyConnectionError(err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.IsError() {
+		body, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("%s %s failed with status %d: %s", req.method, req.path, resp.StatusCode, string(body))
+	}
+	return true, nil
+}
+
+// GetAliasIndices returns the sorted names of the indices an alias points to.
+// Returns nil if the alias does not exist.
+func (c *Client) GetAliasIndices(ctx context.Context, alias string) ([]string, error) {
+	var data map[string]json.RawMessage
+	found, err := c.perform(ctx, rawRequest{method: http.MethodGet, path: "/_alias/" + alias}, &data)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	indices := make([]string, 0, len(data))
+	for index := range data {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+	return indices, nil
+}
+
+// ListIndices returns the sorted names of the indices matching a wildcard pattern
+func (c *Client) ListIndices(ctx context.Context, pattern string) ([]string, error) {
+	var data []struct {
+		Index string `json:"index"`
+	}
+	path := "/_cat/indices/" + pattern + "?format=json&h=index"
+	found, err := c.perform(ctx, rawRequest{method: http.MethodGet, path: path}, &data)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	indices := make([]string, 0, len(data))
+	for _, entry := range data {
+		indices = append(indices, entry.Index)
+	}
+	sort.Strings(indices)
+	return indices, nil
+}
+
+// IndexExists reports whether an index or alias with the given name exists
+func (c *Client) IndexExists(ctx context.Context, name string) (bool, error) {
+	return c.perform(ctx, rawRequest{method: http.MethodHead, path: "/" + name}, nil)
+}
+
+// CountDocuments returns the number of documents in an index or alias
+func (c *Client) CountDocuments(ctx context.Context, name string) (int64, error) {
+	var data struct {
+		Count int64 `json:"count"`
+	}
+	found, err := c.perform(ctx, rawRequest{method: http.MethodGet, path: "/" + name + "/_count"}, &data)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, fmt.Errorf("index %s not found", name)
+	}
+	return data.Count, nil
+}
+
+// RefreshIndex makes all recent writes to an index visible to search
+func (c *Client) RefreshIndex(ctx context.Context, name string) error {
+	_, err := c.perform(ctx, rawRequest{method: http.MethodPost, path: "/" + name + "/_refresh"}, nil)
+	return err
+}
+
+// DeleteIndex deletes a concrete index. Deleting an index that does not exist is not an error.
+func (c *Client) DeleteIndex(ctx context.Context, name string) error {
+	_, err := c.perform(ctx, rawRequest{method: http.MethodDelete, path: "/" + name}, nil)
+	return err
+}
+
+// UpdateAliases applies alias actions in a single atomic request
+func (c *Client) UpdateAliases(ctx context.Context, actions []AliasAction) error {
+	body, err := json.Marshal(map[string]any{"actions": actions})
+	if err != nil {
+		return fmt.Errorf("failed to marshal alias actions: %w", err)
+	}
+	found, err := c.perform(ctx, rawRequest{method: http.MethodPost, path: "/_aliases", body: body}, nil)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("alias update failed: index not found")
+	}
+	return nil
+}
+
+// SwapAliasActions returns the actions that point alias at newIndex only.
+// The alias is removed from oldIndices, and replaceIndex (a concrete index that has the
+// alias name, if any) is deleted in the same atomic request.
+func SwapAliasActions(alias, newIndex string, oldIndices []string, replaceIndex string) []AliasAction {
+	actions := make([]AliasAction, 0, len(oldIndices)+2)
+	for _, index := range oldIndices {
+		if index == newIndex {
+			continue
+		}
+		actions = append(actions, AliasAction{Remove: &AliasTarget{Index: index, Alias: alias}})
+	}
+	if replaceIndex != "" {
+		actions = append(actions, AliasAction{RemoveIndex: &AliasTarget{Index: replaceIndex}})
+	}
+	actions = append(actions, AliasAction{Add: &AliasTarget{Index: newIndex, Alias: alias}})
+	return actions
+}
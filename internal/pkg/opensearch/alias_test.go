@@ -0,0 +1,109 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAliasTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(&Config{Endpoint: server.URL, MaxRetries: 1})
+	require.NoError(t, err)
+	return client
+}
+
+func TestClient_GetAliasIndices(t *testing.T) {
+	client := newAliasTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/_alias/docs":
+			_, _ = w.Write([]byte(`{"docs-20260101000000":{"aliases":{"docs":{}}},"docs-20251201000000":{"aliases":{"docs":{}}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"alias [missing] missing","status":404}`))
+		}
+	})
+
+	indices, err := client.GetAliasIndices(context.Background(), "docs")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docs-20251201000000", "docs-20260101000000"}, indices)
+
+	indices, err = client.GetAliasIndices(context.Background(), "missing")
+	require.NoError(t, err)
+	assert.Nil(t, indices)
+}
+
+func TestClient_UpdateAliases(t *testing.T) {
+	var received map[string][]map[string]map[string]string
+	client := newAliasTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/_aliases", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &received))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"acknowledged":true}`))
+	})
+
+	actions := SwapAliasActions("docs", "docs-2", []string{"docs-1", "docs-2"}, "")
+	require.NoError(t, client.UpdateAliases(context.Background(), actions))
+
+	assert.Equal(t, []map[string]map[string]string{
+		{"remove": {"index": "docs-1", "alias": "docs"}},
+		{"add": {"index": "docs-2", "alias": "docs"}},
+	}, received["actions"])
+}
+
+func TestClient_CountDocuments(t *testing.T) {
+	client := newAliasTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/docs/_count" {
+			_, _ = w.Write([]byte(`{"count":42}`))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error":"boom"}`))
+	})
+
+	count, err := client.CountDocuments(context.Background(), "docs")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), count)
+
+	_, err = client.CountDocuments(context.Background(), "broken")
+	assert.Error(t, err)
+}
+
+func TestSwapAliasActions_ReplacesLegacyIndex(t *testing.T) {
+	actions := SwapAliasActions("docs", "docs-20260101000000", nil, "docs")
+	require.Len(t, actions, 2)
+	assert.Equal(t, &AliasTarget{Index: "docs"}, actions[0].RemoveIndex)
+	assert.Equal(t, &AliasTarget{Index: "docs-20260101000000", Alias: "docs"}, actions[1].Add)
+}
+
+func TestClient_ScanDocuments(t *testing.T) {
+	var received map[string]any
+	client := newAliasTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/docs/_search", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &received))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"hits":{"hits":[{"_id":"b","_source":{"title":"B"}},{"_id":"c","_source":{"title":"C"}}]}}`))
+	})
+
+	docs, err := client.ScanDocuments(context.Background(), "docs", "a", 2)
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "b", docs[0].ID)
+	assert.JSONEq(t, `{"title":"B"}`, string(docs[0].Source))
+	assert.Equal(t, []any{"a"}, received["search_after"])
+	assert.Equal(t, float64(2), received["size"])
+}
@@ -47,6 +47,9 @@ type BM25Query struct {
 	Operator           string            `json:"operator,omitempty"`
 	MinimumShouldMatch string            `json:"minimum_should_match,omitempty"`
 	ExcludeSecret      bool              `json:"exclude_secret,omitempty"`
+	Tenants            []string          `json:"tenants,omitempty"`
+	Groups             []string          `json:"groups,omitempty"`
+	EnforceGroupACL    bool              `json:"enforce_group_acl,omitempty"`
 	Filters            map[string]string `json:"filters,omitempty"`
 	Size               int               `json:"size,omitempty"`
 	From               int               `json:"from,omitempty"`
@@ -197,6 +200,8 @@ func (c *Client) buildBM25SearchBody(query *BM25Query) map[string]interface{} {
 	}
 
 	applySecretExclusion(boolQuery, query.ExcludeSecret)
+	applyTenantFilter(boolQuery, query.Tenants)
+	applyGroupACL(boolQuery, query.Groups, query.EnforceGroupACL)
 	body["_source"] = map[string]interface{}{
 		"excludes": []string{"embedding"},
 	}
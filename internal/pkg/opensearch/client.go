@@ -38,6 +38,7 @@ type Config struct {
 	MaxConnections    int
 	MaxIdleConns      int
 	IdleConnTimeout   time.Duration
+	KNNEngine         string // Engine of the searched indexes; nmslib cannot filter inside k-NN queries
 }
 
 func NewClient(cfg *Config) (*Client, error) {
@@ -228,9 +229,13 @@ func (c *Client) SearchTermQuery(ctx context.Context, indexName string, query *T
 	}
 
 	normalized := TermQuery{
-		Field: query.Field,
-		Size:  query.Size,
-		From:  query.From,
+		Field:           query.Field,
+		ExcludeSecret:   query.ExcludeSecret,
+		Tenants:         query.Tenants,
+		Groups:          query.Groups,
+		EnforceGroupACL: query.EnforceGroupACL,
+		Size:            query.Size,
+		From:            query.From,
 	}
 
 	seen := make(map[string]struct{}, len(query.Values))
@@ -333,13 +338,15 @@ func BuildTermQueryBody(query *TermQuery) map[string]interface{} {
 		body["from"] = 0
 	}
 
-	if query.ExcludeSecret {
+	if query.ExcludeSecret || len(query.Tenants) > 0 || query.EnforceGroupACL {
 		boolQuery := map[string]interface{}{
 			"must": []map[string]interface{}{
 				{"terms": queryClause["terms"].(map[string]interface{})},
 			},
 		}
-		applySecretExclusion(boolQuery, true)
+		applySecretExclusion(boolQuery, query.ExcludeSecret)
+		applyTenantFilter(boolQuery, query.Tenants)
+		applyGroupACL(boolQuery, query.Groups, query.EnforceGroupACL)
 		body["query"] = map[string]interface{}{
 			"bool": boolQuery,
 		}
@@ -25,6 +25,7 @@ func NewConfigFromTypes(cfg *appconfig.Config) (*Config, error) {
 		MaxConnections:    cfg.OpenSearchMaxConnections,
 		MaxIdleConns:      cfg.OpenSearchMaxIdleConns,
 		IdleConnTimeout:   cfg.OpenSearchIdleConnTimeout,
+		KNNEngine:         cfg.OpenSearchKNNEngine,
 	}, nil
 }
 
@@ -0,0 +1,65 @@
+package opensearch
+
+// DocumentFilter selects stored documents by ID, category or file path under the same
+// secret, tenant and group filters that searches apply
+type DocumentFilter struct {
+	IDs             []string
+	Category        string
+	FilePath        string
+	ExcludeSecret   bool
+	Tenants         []string
+	Groups          []string
+	EnforceGroupACL bool
+}
+
+// Query returns the query DSL clause for ScanMatchingDocuments
+func (f DocumentFilter) Query() map[string]interface{} {
+	filters := make([]map[string]interface{}, 0, 3)
+	if len(f.IDs) > 0 {
+		filters = append(filters, map[string]interface{}{
+			"ids": map[string]interface{}{"values": f.IDs},
+		})
+	}
+	if f.Category != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"category": f.Category},
+		})
+	}
+	if f.FilePath != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"file_path": f.FilePath},
+		})
+	}
+
+	boolQuery := map[string]interface{}{}
+	if len(filters) > 0 {
+		boolQuery["filter"] = filters
+	} else {
+		boolQuery["must"] = []map[string]interface{}{{"match_all": map[string]interface{}{}}}
+	}
+	applySecretExclusion(boolQuery, f.ExcludeSecret)
+	applyTenantFilter(boolQuery, f.Tenants)
+	applyGroupACL(boolQuery, f.Groups, f.EnforceGroupACL)
+
+	return map[string]interface{}{"bool": boolQuery}
+}
+
+// Allows reports whether a document with the given secret flag, tenant and allowed_groups
+// passes the secret, tenant and group filters, for documents read outside OpenSearch
+func (f DocumentFilter) Allows(secret bool, tenant string, allowedGroups []string) bool {
+	if f.ExcludeSecret && secret {
+		return false
+	}
+	if len(f.Tenants) > 0 && !containsString(f.Tenants, tenant) {
+		return false
+	}
+	if !f.EnforceGroupACL || len(allowedGroups) == 0 {
+		return true
+	}
+	for _, group := range allowedGroups {
+		if containsString(f.Groups, group) {
+			return true
+		}
+	}
+	return false
+}
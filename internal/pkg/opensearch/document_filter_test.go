@@ -0,0 +1,77 @@
+package opensearch
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDocumentFilterQueryAppliesAccessFilters(t *testing.T) {
+	query := DocumentFilter{
+		IDs:             []string{"doc-1"},
+		ExcludeSecret:   true,
+		Tenants:         []string{"acme"},
+		Groups:          []string{"eng"},
+		EnforceGroupACL: true,
+	}.Query()
+
+	boolQuery, ok := query["bool"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("bool query missing: %#v", query)
+	}
+	if _, ok := boolQuery["must_not"]; !ok {
+		t.Fatalf("expected secret exclusion, got %#v", boolQuery)
+	}
+	filters, ok := boolQuery["filter"].([]map[string]interface{})
+	if !ok || len(filters) != 3 {
+		t.Fatalf("expected ids, tenant and ACL filters, got %#v", boolQuery["filter"])
+	}
+
+	data, err := json.Marshal(query)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	for _, want := range []string{`"ids":{"values":["doc-1"]}`, `"tenant":["acme"]`, `"allowed_groups":["eng"]`} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("expected %s in %s", want, data)
+		}
+	}
+}
+
+func TestDocumentFilterQueryWithoutSelectorsMatchesAll(t *testing.T) {
+	query := DocumentFilter{}.Query()
+
+	data, err := json.Marshal(query)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if string(data) != `{"bool":{"must":[{"match_all":{}}]}}` {
+		t.Fatalf("unexpected query: %s", data)
+	}
+}
+
+func TestDocumentFilterAllows(t *testing.T) {
+	filter := DocumentFilter{ExcludeSecret: true, Tenants: []string{"acme"}, Groups: []string{"eng"}, EnforceGroupACL: true}
+	cases := []struct {
+		secret bool
+		tenant string
+		groups []string
+		want   bool
+	}{
+		{false, "acme", nil, true},
+		{false, "acme", []string{"sre", "eng"}, true},
+		{true, "acme", nil, false},
+		{false, "other", nil, false},
+		{false, "", nil, false},
+		{false, "acme", []string{"sre"}, false},
+	}
+	for _, tc := range cases {
+		if got := filter.Allows(tc.secret, tc.tenant, tc.groups); got != tc.want {
+			t.Fatalf("Allows(%v, %q, %v) = %v, want %v", tc.secret, tc.tenant, tc.groups, got, tc.want)
+		}
+	}
+
+	if !(DocumentFilter{}).Allows(true, "", []string{"sre"}) {
+		t.Fatalf("an empty filter should allow every document")
+	}
+}
@@ -0,0 +1,223 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	"github.com/ca-srg/ragent/internal/pkg/embedding"
+)
+
+// embeddingModelMetaKey is the mapping _meta key holding the embedding model of an index
+const embeddingModelMetaKey = "ragent_embedding"
+
+// EmbeddingModelRefreshInterval is how often ActiveEmbeddingClient re-reads the model of its index
+const EmbeddingModelRefreshInterval = time.Minute
+
+// IndexEmbeddingModel is the embedding model the vectors of an index were created with.
+// Model migrations record it on their index generations, so the generation an alias points
+// to decides how queries are embedded.
+type IndexEmbeddingModel struct {
+	Provider  string `json:"provider"`
+	Model     string `json:"model"`
+	Dimension int    `json:"dimension,omitempty"`
+}
+
+// EmbeddingModelFromConfig returns the embedding model configured in cfg
+func EmbeddingModelFromConfig(cfg *appconfig.Config) IndexEmbeddingModel {
+	provider := cfg.EmbeddingProvider
+	if provider == "" {
+		provider = "bedrock"
+	}
+	return IndexEmbeddingModel{Provider: provider, Model: cfg.EmbeddingModel, Dimension: cfg.EmbeddingDimension}
+}
+
+// SetIndexEmbeddingModel records the embedding model in the mapping _meta of a concrete index
+func (c *Client) SetIndexEmbeddingModel(ctx context.Context, index string, model IndexEmbeddingModel) error {
+	body, err := json.Marshal(map[string]any{"_meta": map[string]any{embeddingModelMetaKey: model}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal embedding model: %w", err)
+	}
+	found, err := c.perform(ctx, rawRequest{method: http.MethodPut, path: "/" + index + "/_mapping", body: body}, nil)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("index %s not found", index)
+	}
+	return nil
+}
+
+// GetIndexEmbeddingModel returns the embedding model recorded on an index or on the indices an
+// alias points to. Returns nil if none is recorded or the index does not exist.
+func (c *Client) GetIndexEmbeddingModel(ctx context.Context, name string) (*IndexEmbeddingModel, error) {
+	var data map[string]struct {
+		Mappings struct {
+			Meta map[string]json.RawMessage `json:"_meta"`
+		} `json:"mappings"`
+	}
+	found, err := c.perform(ctx, rawRequest{method: http.MethodGet, path: "/" + name + "/_mapping"}, &data)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	indices := make([]string, 0, len(data))
+	for index := range data {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+	for _, index := range indices {
+		raw, ok := data[index].Mappings.Meta[embeddingModelMetaKey]
+		if !ok {
+			continue
+		}
+		var model IndexEmbeddingModel
+		if err := json.Unmarshal(raw, &model); err != nil {
+			return nil, fmt.Errorf("invalid embedding model on %s: %w", index, err)
+		}
+		return &model, nil
+	}
+	return nil, nil
+}
+
+// ActiveEmbeddingConfig returns cfg with the embedding model recorded on index, so documents
+// and queries are embedded like the generation the index name resolves to. cfg is returned
+// unchanged when no model is recorded or the index cannot be read.
+func (c *Client) ActiveEmbeddingConfig(ctx context.Context, cfg *appconfig.Config, index string) *appconfig.Config {
+	model, err := c.GetIndexEmbeddingModel(ctx, index)
+	if err != nil {
+		log.Printf("Warning: failed to read the embedding model of %s, using %s: %v", index, cfg.EmbeddingModel, err)
+		return cfg
+	}
+	active := withEmbeddingModel(cfg, model)
+	if active != cfg {
+		log.Printf("%s was embedded with %s/%s; using it instead of %s/%s", index, active.EmbeddingProvider, active.EmbeddingModel, cfg.EmbeddingProvider, cfg.EmbeddingModel)
+	}
+	return active
+}
+
+// ResolveActiveEmbeddingConfig is ActiveEmbeddingConfig for callers without an OpenSearch client
+func ResolveActiveEmbeddingConfig(ctx context.Context, cfg *appconfig.Config, index string) *appconfig.Config {
+	if cfg.OpenSearchEndpoint == "" || index == "" {
+		return cfg
+	}
+	osConfig, err := NewConfigFromTypes(cfg)
+	if err != nil {
+		return cfg
+	}
+	client, err := NewClient(osConfig)
+	if err != nil {
+		log.Printf("Warning: failed to read the embedding model of %s, using %s: %v", index, cfg.EmbeddingModel, err)
+		return cfg
+	}
+	return client.ActiveEmbeddingConfig(ctx, cfg, index)
+}
+
+// withEmbeddingModel returns a copy of cfg embedding with model, or cfg itself if model is
+// unset or already configured
+func withEmbeddingModel(cfg *appconfig.Config, model *IndexEmbeddingModel) *appconfig.Config {
+	if model == nil || model.Model == "" {
+		return cfg
+	}
+	if *model == EmbeddingModelFromConfig(cfg) {
+		return cfg
+	}
+	active := *cfg
+	active.EmbeddingProvider = model.Provider
+	active.EmbeddingModel = model.Model
+	active.EmbeddingDimension = model.Dimension
+	return &active
+}
+
+// ActiveEmbeddingClient embeds with the model recorded on an index. It re-reads the model
+// periodically so long-running servers follow a model migration cutover or rollback
+// without a restart.
+type ActiveEmbeddingClient struct {
+	client   *Client
+	cfg      *appconfig.Config
+	index    string
+	interval time.Duration
+
+	mu         sync.Mutex
+	current    embedding.EmbeddingClient
+	model      IndexEmbeddingModel
+	checkedAt  time.Time
+	refreshing bool
+}
+
+// NewActiveEmbeddingClient creates an embedding client following the model of index
+func NewActiveEmbeddingClient(ctx context.Context, client *Client, cfg *appconfig.Config, index string) (*ActiveEmbeddingClient, error) {
+	active := client.ActiveEmbeddingConfig(ctx, cfg, index)
+	current, err := embedding.NewEmbeddingClient(active)
+	if err != nil {
+		return nil, err
+	}
+	return &ActiveEmbeddingClient{
+		client:    client,
+		cfg:       cfg,
+		index:     index,
+		interval:  EmbeddingModelRefreshInterval,
+		current:   current,
+		model:     EmbeddingModelFromConfig(active),
+		checkedAt: time.Now(),
+	}, nil
+}
+
+// GenerateEmbedding embeds text with the active model of the index
+func (a *ActiveEmbeddingClient) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
+	return a.resolve(ctx).GenerateEmbedding(ctx, text)
+}
+
+// ValidateConnection validates the client of the active model
+func (a *ActiveEmbeddingClient) ValidateConnection(ctx context.Context) error {
+	return a.resolve(ctx).ValidateConnection(ctx)
+}
+
+// GetModelInfo returns the active model and its dimension
+func (a *ActiveEmbeddingClient) GetModelInfo() (string, int, error) {
+	return a.resolve(context.Background()).GetModelInfo()
+}
+
+// resolve returns the client of the active model. A single caller re-reads the model once the
+// interval has passed; the others keep using the current client meanwhile.
+func (a *ActiveEmbeddingClient) resolve(ctx context.Context) embedding.EmbeddingClient {
+	a.mu.Lock()
+	current := a.current
+	if a.refreshing || time.Since(a.checkedAt) < a.interval {
+		a.mu.Unlock()
+		return current
+	}
+	a.refreshing = true
+	a.mu.Unlock()
+
+	recorded, err := a.client.GetIndexEmbeddingModel(ctx, a.index)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.refreshing = false
+	a.checkedAt = time.Now()
+	if err != nil {
+		log.Printf("Warning: failed to read the embedding model of %s, keeping %s: %v", a.index, a.model.Model, err)
+		return a.current
+	}
+	active := withEmbeddingModel(a.cfg, recorded)
+	model := EmbeddingModelFromConfig(active)
+	if model == a.model {
+		return a.current
+	}
+	next, err := embedding.NewEmbeddingClient(active)
+	if err != nil {
+		log.Printf("Warning: failed to switch %s to embedding model %s/%s: %v", a.index, model.Provider, model.Model, err)
+		return a.current
+	}
+	log.Printf("%s now serves embedding model %s/%s; switching from %s/%s", a.index, model.Provider, model.Model, a.model.Provider, a.model.Model)
+	a.current = next
+	a.model = model
+	return next
+}
@@ -0,0 +1,73 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+)
+
+func TestClient_IndexEmbeddingModel(t *testing.T) {
+	var received map[string]map[string]IndexEmbeddingModel
+	client := newAliasTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/docs-2/_mapping":
+			body, _ := io.ReadAll(r.Body)
+			require.NoError(t, json.Unmarshal(body, &received))
+			_, _ = w.Write([]byte(`{"acknowledged":true}`))
+		case r.URL.Path == "/docs/_mapping":
+			_, _ = w.Write([]byte(`{"docs-2":{"mappings":{"_meta":{"ragent_embedding":{"provider":"gemini","model":"gemini-embedding-001","dimension":768}}}}}`))
+		case r.URL.Path == "/legacy/_mapping":
+			_, _ = w.Write([]byte(`{"legacy":{"mappings":{"properties":{}}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"no such index","status":404}`))
+		}
+	})
+	ctx := context.Background()
+
+	model := IndexEmbeddingModel{Provider: "gemini", Model: "gemini-embedding-001", Dimension: 768}
+	require.NoError(t, client.SetIndexEmbeddingModel(ctx, "docs-2", model))
+	assert.Equal(t, model, received["_meta"]["ragent_embedding"])
+	assert.Error(t, client.SetIndexEmbeddingModel(ctx, "missing", model))
+
+	got, err := client.GetIndexEmbeddingModel(ctx, "docs")
+	require.NoError(t, err)
+	assert.Equal(t, &model, got)
+
+	got, err = client.GetIndexEmbeddingModel(ctx, "legacy")
+	require.NoError(t, err)
+	assert.Nil(t, got, "indexes created before model tracking have no model")
+	got, err = client.GetIndexEmbeddingModel(ctx, "missing")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	cfg := &appconfig.Config{EmbeddingProvider: "bedrock", EmbeddingModel: "amazon.titan-embed-text-v2:0", Concurrency: 4}
+	active := client.ActiveEmbeddingConfig(ctx, cfg, "docs")
+	assert.Equal(t, "gemini", active.EmbeddingProvider)
+	assert.Equal(t, "gemini-embedding-001", active.EmbeddingModel)
+	assert.Equal(t, 768, active.EmbeddingDimension)
+	assert.Equal(t, 4, active.Concurrency)
+	assert.Equal(t, "bedrock", cfg.EmbeddingProvider, "the configuration is not modified")
+	assert.Same(t, cfg, client.ActiveEmbeddingConfig(ctx, cfg, "legacy"))
+}
+
+func TestWithEmbeddingModel(t *testing.T) {
+	cfg := &appconfig.Config{EmbeddingModel: "amazon.titan-embed-text-v2:0"}
+
+	assert.Same(t, cfg, withEmbeddingModel(cfg, nil))
+	assert.Same(t, cfg, withEmbeddingModel(cfg, &IndexEmbeddingModel{Provider: "gemini"}))
+	assert.Same(t, cfg, withEmbeddingModel(cfg, &IndexEmbeddingModel{Provider: "bedrock", Model: "amazon.titan-embed-text-v2:0"}),
+		"an empty provider means bedrock")
+
+	active := withEmbeddingModel(cfg, &IndexEmbeddingModel{Provider: "bedrock", Model: "cohere.embed-multilingual-v3", Dimension: 1024})
+	assert.Equal(t, "cohere.embed-multilingual-v3", active.EmbeddingModel)
+	assert.Equal(t, 1024, active.EmbeddingDimension)
+}
@@ -0,0 +1,267 @@
+package opensearch
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// IndexTarget is one member of a federated search: a concrete index, alias or
+// wildcard pattern together with the weight applied to its fused scores
+type IndexTarget struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+}
+
+// ParseIndexTargets parses a comma-separated index list such as
+// "docs,runbooks^0.5,tickets-*^2". A "^weight" suffix sets the per-index
+// weight (default 1.0); duplicates are dropped.
+func ParseIndexTargets(spec string) ([]IndexTarget, error) {
+	return ParseIndexTargetList(strings.Split(spec, ","))
+}
+
+// ParseIndexTargetList parses index entries that each use the "name^weight" form
+func ParseIndexTargetList(entries []string) ([]IndexTarget, error) {
+	targets := make([]IndexTarget, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		target := IndexTarget{Name: entry, Weight: 1.0}
+		if pos := strings.LastIndex(entry, "^"); pos >= 0 {
+			weight, err := strconv.ParseFloat(strings.TrimSpace(entry[pos+1:]), 64)
+			if err != nil || weight <= 0 {
+				return nil, fmt.Errorf("invalid weight in index entry %q (expected name^positive-number)", entry)
+			}
+			target.Name = strings.TrimSpace(entry[:pos])
+			target.Weight = weight
+		}
+		if target.Name == "" {
+			return nil, fmt.Errorf("index entry %q has no index name", entry)
+		}
+		if seen[target.Name] {
+			continue
+		}
+		seen[target.Name] = true
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// IsFederated reports whether the targets require a federated search rather
+// than a plain search against a single index
+func IsFederated(targets []IndexTarget) bool {
+	return len(targets) > 1 || (len(targets) == 1 && targets[0].Weight != 1.0)
+}
+
+// FederatedIndexResult summarizes the contribution of one target to a federated search
+type FederatedIndexResult struct {
+	Target        string        `json:"target"`
+	Weight        float64       `json:"weight"`
+	Hits          int           `json:"hits"`
+	SearchMethod  string        `json:"search_method,omitempty"`
+	ExecutionTime time.Duration `json:"execution_time"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// SearchFederated runs the hybrid query against every target in parallel and merges
+// the per-index results. With RRF fusion each document scores weight/(rank_constant+rank)
+// of its rank within its own index; otherwise its fused score is normalized by the
+// index's best score and multiplied by the weight. Documents keep the concrete index they
+// came from and are labeled with the target that matched them. The query embedding is
+// generated once and shared by all targets.
+func (hse *HybridSearchEngine) SearchFederated(ctx context.Context, query *HybridQuery, targets []IndexTarget) (*HybridSearchResult, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("at least one index is required")
+	}
+	if query == nil {
+		return nil, fmt.Errorf("query cannot be nil")
+	}
+
+	startTime := time.Now()
+	names := make([]string, len(targets))
+	for i, target := range targets {
+		names[i] = target.Name
+	}
+	log.Printf("Starting federated search: query='%s', indexes=%v", query.Query, names)
+
+	engine := *hse
+	engine.embeddingClient = &sharedEmbeddingClient{client: hse.embeddingClient}
+
+	results := make([]*HybridSearchResult, len(targets))
+	summaries := make([]FederatedIndexResult, len(targets))
+	g, gCtx := errgroup.WithContext(ctx)
+	for i, target := range targets {
+		g.Go(func() error {
+			indexQuery := *query
+			indexQuery.IndexName = target.Name
+			indexStart := time.Now()
+			result, err := engine.Search(gCtx, &indexQuery)
+			summaries[i] = FederatedIndexResult{
+				Target:        target.Name,
+				Weight:        target.Weight,
+				ExecutionTime: time.Since(indexStart),
+			}
+			if err != nil {
+				log.Printf("Federated search on index %s failed: %v", target.Name, err)
+				summaries[i].Error = err.Error()
+				return nil
+			}
+			summaries[i].SearchMethod = result.SearchMethod
+			if result.FusionResult != nil {
+				summaries[i].Hits = len(result.FusionResult.Documents)
+			}
+			results[i] = result
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	merged := &HybridSearchResult{
+		SearchMethod:     "federated_search",
+		FederatedIndexes: summaries,
+	}
+	succeeded := 0
+	for i, result := range results {
+		if result == nil {
+			merged.Errors = append(merged.Errors, fmt.Sprintf("index %s: %s", targets[i].Name, summaries[i].Error))
+			merged.PartialResults = true
+			continue
+		}
+		succeeded++
+		for _, msg := range result.Errors {
+			merged.Errors = append(merged.Errors, fmt.Sprintf("index %s: %s", targets[i].Name, msg))
+		}
+		merged.PartialResults = merged.PartialResults || result.PartialResults
+		merged.URLDetected = merged.URLDetected || result.URLDetected
+		if merged.ProcessedQuery == nil {
+			merged.ProcessedQuery = result.ProcessedQuery
+		}
+		merged.BM25Time = max(merged.BM25Time, result.BM25Time)
+		merged.VectorTime = max(merged.VectorTime, result.VectorTime)
+		merged.EmbeddingTime = max(merged.EmbeddingTime, result.EmbeddingTime)
+		merged.TermQueryTime = max(merged.TermQueryTime, result.TermQueryTime)
+	}
+	if succeeded == 0 {
+		return nil, fmt.Errorf("federated search failed on all indexes: %s", strings.Join(merged.Errors, "; "))
+	}
+
+	fusionStart := time.Now()
+	merged.FusionResult = mergeFederatedResults(results, targets, query.FusionMethod, query.RankConstant, query.Size)
+	merged.FusionTime = time.Since(fusionStart)
+	merged.ExecutionTime = time.Since(startTime)
+
+	log.Printf("Federated search completed: indexes=%d, results=%d, time=%v",
+		len(targets), merged.FusionResult.TotalHits, merged.ExecutionTime)
+	return merged, nil
+}
+
+// mergeFederatedResults combines the per-index results into a single ranking
+func mergeFederatedResults(results []*HybridSearchResult, targets []IndexTarget, method FusionMethod, rankConstant float64, size int) *FusionResult {
+	if rankConstant <= 0 {
+		rankConstant = 60.0
+	}
+	if method == "" {
+		method = FusionMethodRRF
+	}
+
+	merged := &FusionResult{FusionType: "federated_" + string(method)}
+	seen := make(map[string]bool)
+	for i, result := range results {
+		if result == nil || result.FusionResult == nil {
+			continue
+		}
+		merged.BM25Results += result.FusionResult.BM25Results
+		merged.VectorResults += result.FusionResult.VectorResults
+
+		best := 0.0
+		for _, doc := range result.FusionResult.Documents {
+			best = max(best, doc.FusedScore)
+		}
+		for rank, doc := range result.FusionResult.Documents {
+			if doc.Index == "" {
+				doc.Index = targets[i].Name
+			}
+			key := doc.Index + "\x00" + doc.ID
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			switch {
+			case method == FusionMethodRRF:
+				doc.FusedScore = targets[i].Weight / (rankConstant + float64(rank+1))
+			case best > 0:
+				doc.FusedScore = targets[i].Weight * doc.FusedScore / best
+			default:
+				doc.FusedScore = 0
+			}
+			doc.IndexTarget = targets[i].Name
+			merged.Documents = append(merged.Documents, doc)
+		}
+	}
+
+	sort.SliceStable(merged.Documents, func(a, b int) bool {
+		return merged.Documents[a].FusedScore > merged.Documents[b].FusedScore
+	})
+	if size > 0 && len(merged.Documents) > size {
+		merged.Documents = merged.Documents[:size]
+	}
+	for i := range merged.Documents {
+		merged.Documents[i].Rank = i + 1
+		merged.MaxScore = max(merged.MaxScore, merged.Documents[i].FusedScore)
+	}
+	merged.TotalHits = len(merged.Documents)
+	return merged
+}
+
+// sharedEmbeddingClient generates the query embedding once and hands the same
+// vector to every per-index search of a federated query
+type sharedEmbeddingClient struct {
+	client EmbeddingClient
+	mu     sync.Mutex
+	text   string
+	vector []float64
+}
+
+func (s *sharedEmbeddingClient) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.vector != nil && s.text == text {
+		return s.vector, nil
+	}
+	vector, err := s.client.GenerateEmbedding(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	s.text, s.vector = text, vector
+	return vector, nil
+}
+
+// SearchIndexes treats query.IndexName as an index spec as accepted by ParseIndexTargets.
+// A single unweighted index runs a plain hybrid search; anything else is federated.
+func (hse *HybridSearchEngine) SearchIndexes(ctx context.Context, query *HybridQuery) (*HybridSearchResult, error) {
+	if query == nil {
+		return hse.Search(ctx, query)
+	}
+	targets, err := ParseIndexTargets(query.IndexName)
+	if err != nil {
+		return nil, fmt.Errorf("query validation failed: %w", err)
+	}
+	if !IsFederated(targets) {
+		if len(targets) == 1 {
+			query.IndexName = targets[0].Name
+		}
+		return hse.Search(ctx, query)
+	}
+	return hse.SearchFederated(ctx, query, targets)
+}
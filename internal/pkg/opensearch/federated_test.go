@@ -0,0 +1,157 @@
+package opensearch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type federatedStubClient struct {
+	mu      sync.Mutex
+	hits    map[string][]string
+	failing map[string]bool
+	metrics PerformanceMetrics
+}
+
+func (c *federatedStubClient) SearchTermQuery(ctx context.Context, indexName string, query *TermQuery) (*TermQueryResponse, error) {
+	return &TermQueryResponse{}, nil
+}
+
+func (c *federatedStubClient) SearchBM25(ctx context.Context, indexName string, query *BM25Query) (*BM25SearchResponse, error) {
+	if c.failing[indexName] {
+		return nil, fmt.Errorf("index %s unavailable", indexName)
+	}
+	resp := &BM25SearchResponse{}
+	for i, id := range c.hits[indexName] {
+		resp.Hits.Hits = append(resp.Hits.Hits, BM25SearchResult{ID: id, Score: float64(10 - i), Index: indexName, Source: []byte(`{}`)})
+	}
+	return resp, nil
+}
+
+func (c *federatedStubClient) SearchDenseVector(ctx context.Context, indexName string, query *VectorQuery) (*VectorSearchResponse, error) {
+	if c.failing[indexName] {
+		return nil, fmt.Errorf("index %s unavailable", indexName)
+	}
+	resp := &VectorSearchResponse{}
+	for i, id := range c.hits[indexName] {
+		resp.Hits.Hits = append(resp.Hits.Hits, VectorSearchResult{ID: id, Score: 1 - float64(i)/10, Index: indexName, Source: []byte(`{}`)})
+	}
+	return resp, nil
+}
+
+func (c *federatedStubClient) RecordRequest(duration time.Duration, success bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metrics.RequestCount++
+}
+
+func (c *federatedStubClient) GetMetrics() *PerformanceMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	metrics := c.metrics
+	return &metrics
+}
+
+func (c *federatedStubClient) LogMetrics() {}
+
+type countingEmbeddingClient struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (c *countingEmbeddingClient) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	return []float64{0.1, 0.2}, nil
+}
+
+func TestParseIndexTargets(t *testing.T) {
+	targets, err := ParseIndexTargets(" docs, runbooks^0.5 ,tickets-*^2,docs,")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []IndexTarget{{"docs", 1}, {"runbooks", 0.5}, {"tickets-*", 2}}
+	if len(targets) != len(want) {
+		t.Fatalf("expected %v, got %v", want, targets)
+	}
+	for i := range want {
+		if targets[i] != want[i] {
+			t.Fatalf("target %d: expected %v, got %v", i, want[i], targets[i])
+		}
+	}
+	if !IsFederated(targets) {
+		t.Fatalf("expected multiple targets to be federated")
+	}
+	if IsFederated([]IndexTarget{{"docs", 1}}) {
+		t.Fatalf("expected a single unweighted target not to be federated")
+	}
+
+	for _, spec := range []string{"docs^x", "docs^0", "^2"} {
+		if _, err := ParseIndexTargets(spec); err == nil {
+			t.Fatalf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestSearchFederated(t *testing.T) {
+	client := &federatedStubClient{hits: map[string][]string{
+		"docs":     {"a", "b"},
+		"runbooks": {"a", "c"},
+	}}
+	embedding := &countingEmbeddingClient{}
+	engine := NewHybridSearchEngine(client, embedding)
+
+	result, err := engine.SearchFederated(context.Background(), &HybridQuery{Query: "deploy", Size: 10},
+		[]IndexTarget{{"docs", 1}, {"runbooks", 2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if embedding.calls != 1 {
+		t.Fatalf("expected the embedding to be generated once, got %d", embedding.calls)
+	}
+	if result.SearchMethod != "federated_search" || len(result.FederatedIndexes) != 2 {
+		t.Fatalf("unexpected result metadata: %+v", result)
+	}
+
+	docs := result.FusionResult.Documents
+	if len(docs) != 4 {
+		t.Fatalf("expected the same ID in different indexes to be kept apart, got %d docs", len(docs))
+	}
+	if docs[0].Index != "runbooks" || docs[0].IndexTarget != "runbooks" || docs[0].ID != "a" {
+		t.Fatalf("expected the heavier index to rank first, got %+v", docs[0])
+	}
+	for i, doc := range docs {
+		if doc.Rank != i+1 {
+			t.Fatalf("expected rank %d, got %d", i+1, doc.Rank)
+		}
+	}
+}
+
+func TestSearchFederated_PartialFailure(t *testing.T) {
+	client := &federatedStubClient{
+		hits:    map[string][]string{"docs": {"a"}},
+		failing: map[string]bool{"runbooks": true},
+	}
+	engine := NewHybridSearchEngine(client, &countingEmbeddingClient{})
+
+	result, err := engine.SearchFederated(context.Background(), &HybridQuery{Query: "deploy"},
+		[]IndexTarget{{"docs", 1}, {"runbooks", 1}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.PartialResults || len(result.FusionResult.Documents) != 1 {
+		t.Fatalf("expected partial results from docs only, got %+v", result)
+	}
+	if result.FederatedIndexes[1].Error == "" {
+		t.Fatalf("expected the failing index to report its error")
+	}
+
+	_, err = engine.SearchFederated(context.Background(), &HybridQuery{Query: "deploy"},
+		[]IndexTarget{{"runbooks", 1}})
+	if err == nil {
+		t.Fatalf("expected an error when every index fails")
+	}
+}
@@ -19,6 +19,7 @@ type ScoredDoc struct {
 	FusedScore  float64         `json:"fused_score"`
 	Source      json.RawMessage `json:"source"`
 	Index       string          `json:"index"`
+	IndexTarget string          `json:"index_target,omitempty"` // Federated search target that matched the document
 	Rank        int             `json:"rank"`
 	SearchType  string          `json:"search_type"`
 }
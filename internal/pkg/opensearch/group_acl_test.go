@@ -0,0 +1,83 @@
+package opensearch
+
+import "testing"
+
+func groupACLFromBool(t *testing.T, body map[string]interface{}) []map[string]interface{} {
+	t.Helper()
+	querySection, ok := body["query"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("query section missing")
+	}
+	boolQuery, ok := querySection["bool"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("bool query missing")
+	}
+	return groupACL(t, boolQuery)
+}
+
+func groupACL(t *testing.T, boolQuery map[string]interface{}) []map[string]interface{} {
+	t.Helper()
+	filters, _ := boolQuery["filter"].([]map[string]interface{})
+	for _, clause := range filters {
+		inner, ok := clause["bool"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if should, ok := inner["should"].([]map[string]interface{}); ok {
+			return should
+		}
+	}
+	t.Fatalf("group ACL clause missing from %#v", filters)
+	return nil
+}
+
+func TestBuildBM25SearchBodyAppliesGroupACL(t *testing.T) {
+	client := &Client{}
+	body := client.buildBM25SearchBody(&BM25Query{
+		Query:           "incident",
+		Fields:          []string{"title"},
+		Size:            10,
+		Groups:          []string{"sre", "security"},
+		EnforceGroupACL: true,
+	})
+
+	should := groupACLFromBool(t, body)
+	if len(should) != 2 {
+		t.Fatalf("expected unrestricted and group clauses, got %#v", should)
+	}
+	terms, ok := should[1]["terms"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected terms clause, got %#v", should[1])
+	}
+	if groups, _ := terms["allowed_groups"].([]string); len(groups) != 2 || groups[0] != "sre" {
+		t.Fatalf("unexpected groups: %v", terms["allowed_groups"])
+	}
+}
+
+func TestBuildVectorSearchBodyFailsClosedWithoutGroups(t *testing.T) {
+	client := &Client{}
+	body := client.buildVectorSearchBody(&VectorQuery{
+		Vector:          []float64{0.1, 0.2},
+		VectorField:     "embedding",
+		K:               5,
+		Size:            5,
+		EnforceGroupACL: true,
+	})
+
+	if should := groupACL(t, knnFilterBool(t, body)); len(should) != 1 {
+		t.Fatalf("expected only unrestricted documents without groups, got %#v", should)
+	}
+}
+
+func TestBuildTermQueryBodySkipsGroupACLWhenNotEnforced(t *testing.T) {
+	body := BuildTermQueryBody(&TermQuery{
+		Field:  "reference",
+		Values: []string{"https://example.com"},
+		Size:   10,
+	})
+
+	querySection, _ := body["query"].(map[string]interface{})
+	if _, ok := querySection["bool"]; ok {
+		t.Fatalf("did not expect a bool query when the group ACL is not enforced")
+	}
+}
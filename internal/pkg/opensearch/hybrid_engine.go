@@ -46,6 +46,9 @@ type HybridQuery struct {
 	K                      int               `json:"k"`
 	EfSearch               int               `json:"ef_search,omitempty"`
 	ExcludeSecret          bool              `json:"exclude_secret,omitempty"`
+	Tenants                []string          `json:"tenants,omitempty"`           // Restrict results to these tenants; empty means unrestricted
+	Groups                 []string          `json:"groups,omitempty"`            // Caller groups matched against allowed_groups; empty sees only unrestricted documents
+	EnforceGroupACL        bool              `json:"enforce_group_acl,omitempty"` // Filter on allowed_groups; every user-facing search sets this
 	Filters                map[string]string `json:"filters,omitempty"`
 	MinScore               float64           `json:"min_score,omitempty"`
 	BM25Weight             float64           `json:"bm25_weight"`
@@ -74,6 +77,8 @@ type HybridSearchResult struct {
 	URLDetected    bool                  `json:"url_detected"`
 	TermQueryTime  time.Duration         `json:"term_query_time,omitempty"`
 	FallbackReason string                `json:"fallback_reason,omitempty"`
+
+	FederatedIndexes []FederatedIndexResult `json:"federated_indexes,omitempty"`
 }
 
 func NewHybridSearchEngine(client SearchClient, embeddingClient EmbeddingClient) *HybridSearchEngine {
@@ -130,10 +135,13 @@ func (hse *HybridSearchEngine) Search(ctx context.Context, query *HybridQuery) (
 			log.Printf("URL detected in query: %v", detectionResult.URLs)
 			urlDetected = true
 			termQuery := &TermQuery{
-				Field:         "reference",
-				Values:        detectionResult.URLs,
-				ExcludeSecret: query.ExcludeSecret,
-				Size:          query.Size,
+				Field:           "reference",
+				Values:          detectionResult.URLs,
+				ExcludeSecret:   query.ExcludeSecret,
+				Tenants:         query.Tenants,
+				Groups:          query.Groups,
+				EnforceGroupACL: query.EnforceGroupACL,
+				Size:            query.Size,
 			}
 			if termQuery.Size <= 0 {
 				termQuery.Size = len(detectionResult.URLs)
@@ -416,6 +424,9 @@ func (hse *HybridSearchEngine) buildBM25Query(query *HybridQuery, processedQuery
 		Operator:           operator,
 		MinimumShouldMatch: minimumShouldMatch,
 		ExcludeSecret:      query.ExcludeSecret,
+		Tenants:            query.Tenants,
+		Groups:             query.Groups,
+		EnforceGroupACL:    query.EnforceGroupACL,
 		Filters:            query.Filters,
 		Size:               query.K,
 		From:               query.From,
@@ -425,15 +436,18 @@ func (hse *HybridSearchEngine) buildBM25Query(query *HybridQuery, processedQuery
 
 func (hse *HybridSearchEngine) buildVectorQuery(query *HybridQuery, vector []float64) *VectorQuery {
 	return &VectorQuery{
-		Vector:        vector,
-		VectorField:   query.VectorField,
-		K:             query.K,
-		EfSearch:      query.EfSearch,
-		ExcludeSecret: query.ExcludeSecret,
-		Filters:       query.Filters,
-		MinScore:      query.MinScore,
-		Size:          query.Size,
-		From:          query.From,
+		Vector:          vector,
+		VectorField:     query.VectorField,
+		K:               query.K,
+		EfSearch:        query.EfSearch,
+		ExcludeSecret:   query.ExcludeSecret,
+		Tenants:         query.Tenants,
+		Groups:          query.Groups,
+		EnforceGroupACL: query.EnforceGroupACL,
+		Filters:         query.Filters,
+		MinScore:        query.MinScore,
+		Size:            query.Size,
+		From:            query.From,
 	}
 }
 
@@ -0,0 +1,244 @@
+package opensearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+)
+
+// k-NN engines supported by the mapping profile
+const (
+	KNNEngineLucene = "lucene"
+	KNNEngineFaiss  = "faiss"
+	KNNEngineNmslib = "nmslib"
+)
+
+// KNNIndexProfile describes the k-NN vector field and the shard layout of a new index
+type KNNIndexProfile struct {
+	Engine           string `json:"engine"`                      // lucene, faiss or nmslib
+	SpaceType        string `json:"space_type"`                  // e.g. cosinesimil, l2, innerproduct
+	M                int    `json:"m"`                           // HNSW graph degree
+	EfConstruction   int    `json:"ef_construction"`             // HNSW build-time candidate list size
+	Shards           int    `json:"number_of_shards"`            // Primary shards
+	Replicas         int    `json:"number_of_replicas"`          // Replica shards per primary
+	Mode             string `json:"mode,omitempty"`              // "", in_memory or on_disk
+	CompressionLevel string `json:"compression_level,omitempty"` // e.g. 2x, 4x, 8x, 16x, 32x
+	Encoder          string `json:"encoder,omitempty"`           // "" or sq (scalar-quantized byte/fp16 vectors)
+}
+
+// DefaultKNNIndexProfile returns the profile that matches the historical hard-coded mapping
+func DefaultKNNIndexProfile() KNNIndexProfile {
+	return KNNIndexProfile{
+		Engine:         KNNEngineLucene,
+		SpaceType:      "cosinesimil",
+		M:              16,
+		EfConstruction: 256,
+		Shards:         1,
+		Replicas:       0,
+	}
+}
+
+// NewKNNIndexProfileFromConfig builds the profile from the OPENSEARCH_KNN_* and shard settings,
+// falling back to the defaults for empty values
+func NewKNNIndexProfileFromConfig(cfg *appconfig.Config) KNNIndexProfile {
+	profile := DefaultKNNIndexProfile()
+	if cfg == nil {
+		return profile
+	}
+	if cfg.OpenSearchKNNEngine != "" {
+		profile.Engine = strings.ToLower(cfg.OpenSearchKNNEngine)
+	}
+	if cfg.OpenSearchKNNSpaceType != "" {
+		profile.SpaceType = cfg.OpenSearchKNNSpaceType
+	}
+	if cfg.OpenSearchKNNM > 0 {
+		profile.M = cfg.OpenSearchKNNM
+	}
+	if cfg.OpenSearchKNNEfConstruction > 0 {
+		profile.EfConstruction = cfg.OpenSearchKNNEfConstruction
+	}
+	if cfg.OpenSearchNumberOfShards > 0 {
+		profile.Shards = cfg.OpenSearchNumberOfShards
+	}
+	if cfg.OpenSearchNumberOfReplicas > 0 {
+		profile.Replicas = cfg.OpenSearchNumberOfReplicas
+	}
+	profile.Mode = strings.ToLower(cfg.OpenSearchKNNMode)
+	profile.CompressionLevel = strings.ToLower(cfg.OpenSearchKNNCompressionLevel)
+	profile.Encoder = strings.ToLower(cfg.OpenSearchKNNEncoder)
+	return profile
+}
+
+// Validate checks that the profile can be turned into a valid mapping
+func (p KNNIndexProfile) Validate() error {
+	switch p.Engine {
+	case KNNEngineLucene, KNNEngineFaiss, KNNEngineNmslib:
+	default:
+		return fmt.Errorf("invalid k-NN engine %q (must be lucene, faiss or nmslib)", p.Engine)
+	}
+	if p.SpaceType == "" {
+		return fmt.Errorf("k-NN space type is required")
+	}
+	if p.M <= 0 || p.EfConstruction <= 0 {
+		return fmt.Errorf("k-NN m and ef_construction must be positive")
+	}
+	if p.Shards <= 0 {
+		return fmt.Errorf("number of shards must be positive")
+	}
+	if p.Replicas < 0 {
+		return fmt.Errorf("number of replicas must not be negative")
+	}
+	switch p.Mode {
+	case "", "in_memory", "on_disk":
+	default:
+		return fmt.Errorf("invalid k-NN mode %q (must be in_memory or on_disk)", p.Mode)
+	}
+	switch p.CompressionLevel {
+	case "", "1x", "2x", "4x", "8x", "16x", "32x":
+	default:
+		return fmt.Errorf("invalid k-NN compression level %q (must be 1x, 2x, 4x, 8x, 16x or 32x)", p.CompressionLevel)
+	}
+	switch p.Encoder {
+	case "":
+	case "sq":
+		if p.Engine == KNNEngineNmslib {
+			return fmt.Errorf("the sq encoder requires the lucene or faiss engine")
+		}
+	default:
+		return fmt.Errorf("invalid k-NN encoder %q (must be empty or sq)", p.Encoder)
+	}
+	return nil
+}
+
+// IndexSettings returns the shard and k-NN settings of the "index" block
+func (p KNNIndexProfile) IndexSettings() map[string]interface{} {
+	return map[string]interface{}{
+		"knn":                true,
+		"number_of_shards":   p.Shards,
+		"number_of_replicas": p.Replicas,
+	}
+}
+
+// EmbeddingMapping returns the knn_vector mapping of the embedding field
+func (p KNNIndexProfile) EmbeddingMapping(dimension int) map[string]interface{} {
+	parameters := map[string]interface{}{
+		"ef_construction": p.EfConstruction,
+		"m":               p.M,
+	}
+	if p.Encoder == "sq" {
+		encoder := map[string]interface{}{"name": "sq"}
+		if p.Engine == KNNEngineFaiss {
+			encoder["parameters"] = map[string]interface{}{"type": "fp16"}
+		}
+		parameters["encoder"] = encoder
+	}
+
+	mapping := map[string]interface{}{
+		"type":      "knn_vector",
+		"dimension": dimension,
+		"method": map[string]interface{}{
+			"engine":     p.Engine,
+			"space_type": p.SpaceType,
+			"name":       "hnsw",
+			"parameters": parameters,
+		},
+	}
+	if p.Mode != "" {
+		mapping["mode"] = p.Mode
+	}
+	if p.CompressionLevel != "" {
+		mapping["compression_level"] = p.CompressionLevel
+	}
+	return mapping
+}
+
+// liveKNNSettings is the part of a GET <index> response compared with a profile
+type liveKNNSettings struct {
+	Settings struct {
+		Index struct {
+			Shards   string `json:"number_of_shards"`
+			Replicas string `json:"number_of_replicas"`
+		} `json:"index"`
+	}
+	Mappings struct {
+		Properties map[string]struct {
+			Type             string `json:"type"`
+			Dimension        int    `json:"dimension"`
+			Mode             string `json:"mode"`
+			CompressionLevel string `json:"compression_level"`
+			Method           struct {
+				Engine     string `json:"engine"`
+				SpaceType  string `json:"space_type"`
+				Parameters struct {
+					M              int `json:"m"`
+					EfConstruction int `json:"ef_construction"`
+					Encoder        *struct {
+						Name string `json:"name"`
+					} `json:"encoder"`
+				} `json:"parameters"`
+			} `json:"method"`
+		} `json:"properties"`
+	}
+}
+
+// KNNProfileDrift compares the settings and mappings of a live index (as returned by
+// GET <index>) with the profile and the expected dimension of vectorField. It returns
+// one message per differing value; an empty result means the index matches the profile.
+func KNNProfileDrift(p KNNIndexProfile, vectorField string, dimension int, settings, mappings json.RawMessage) ([]string, error) {
+	var live liveKNNSettings
+	if len(settings) > 0 {
+		if err := json.Unmarshal(settings, &live.Settings); err != nil {
+			return nil, fmt.Errorf("failed to parse index settings: %w", err)
+		}
+	}
+	if len(mappings) > 0 {
+		if err := json.Unmarshal(mappings, &live.Mappings); err != nil {
+			return nil, fmt.Errorf("failed to parse index mappings: %w", err)
+		}
+	}
+
+	var drift []string
+	report := func(name string, want, got interface{}) {
+		if fmt.Sprint(want) != fmt.Sprint(got) {
+			drift = append(drift, fmt.Sprintf("%s: profile %v, index %v", name, want, got))
+		}
+	}
+
+	shards, _ := strconv.Atoi(live.Settings.Index.Shards)
+	replicas, _ := strconv.Atoi(live.Settings.Index.Replicas)
+	report("number_of_shards", p.Shards, shards)
+	report("number_of_replicas", p.Replicas, replicas)
+
+	field, ok := live.Mappings.Properties[vectorField]
+	if !ok || field.Type != "knn_vector" {
+		return append(drift, fmt.Sprintf("%s: knn_vector field is missing", vectorField)), nil
+	}
+	if dimension > 0 {
+		report("dimension", dimension, field.Dimension)
+	}
+	report("engine", p.Engine, field.Method.Engine)
+	report("space_type", p.SpaceType, field.Method.SpaceType)
+	report("m", p.M, field.Method.Parameters.M)
+	report("ef_construction", p.EfConstruction, field.Method.Parameters.EfConstruction)
+
+	// OpenSearch reports in_memory and 1x for fields created without mode or compression
+	report("mode", defaultString(p.Mode, "in_memory"), defaultString(field.Mode, "in_memory"))
+	report("compression_level", defaultString(p.CompressionLevel, "1x"), defaultString(field.CompressionLevel, "1x"))
+
+	encoder := ""
+	if field.Method.Parameters.Encoder != nil {
+		encoder = field.Method.Parameters.Encoder.Name
+	}
+	report("encoder", defaultString(p.Encoder, "none"), defaultString(encoder, "none"))
+	return drift, nil
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,101 @@
+package opensearch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+)
+
+func TestNewKNNIndexProfileFromConfig(t *testing.T) {
+	profile := NewKNNIndexProfileFromConfig(&appconfig.Config{
+		OpenSearchKNNEngine:           "FAISS",
+		OpenSearchKNNSpaceType:        "innerproduct",
+		OpenSearchKNNM:                32,
+		OpenSearchKNNEfConstruction:   512,
+		OpenSearchKNNMode:             "on_disk",
+		OpenSearchKNNCompressionLevel: "32x",
+		OpenSearchNumberOfShards:      3,
+		OpenSearchNumberOfReplicas:    2,
+	})
+	require.NoError(t, profile.Validate())
+	assert.Equal(t, KNNIndexProfile{
+		Engine:           KNNEngineFaiss,
+		SpaceType:        "innerproduct",
+		M:                32,
+		EfConstruction:   512,
+		Shards:           3,
+		Replicas:         2,
+		Mode:             "on_disk",
+		CompressionLevel: "32x",
+	}, profile)
+
+	assert.Equal(t, DefaultKNNIndexProfile(), NewKNNIndexProfileFromConfig(&appconfig.Config{}))
+}
+
+func TestKNNIndexProfile_Validate(t *testing.T) {
+	for name, mutate := range map[string]func(*KNNIndexProfile){
+		"engine":      func(p *KNNIndexProfile) { p.Engine = "annoy" },
+		"m":           func(p *KNNIndexProfile) { p.M = 0 },
+		"shards":      func(p *KNNIndexProfile) { p.Shards = 0 },
+		"replicas":    func(p *KNNIndexProfile) { p.Replicas = -1 },
+		"mode":        func(p *KNNIndexProfile) { p.Mode = "disk" },
+		"compression": func(p *KNNIndexProfile) { p.CompressionLevel = "3x" },
+		"encoder":     func(p *KNNIndexProfile) { p.Engine, p.Encoder = KNNEngineNmslib, "sq" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			profile := DefaultKNNIndexProfile()
+			mutate(&profile)
+			assert.Error(t, profile.Validate())
+		})
+	}
+}
+
+func TestKNNIndexProfile_EmbeddingMapping(t *testing.T) {
+	profile := DefaultKNNIndexProfile()
+	profile.Engine = KNNEngineFaiss
+	profile.Encoder = "sq"
+	profile.Mode = "on_disk"
+
+	mapping := profile.EmbeddingMapping(1024)
+	assert.Equal(t, 1024, mapping["dimension"])
+	assert.Equal(t, "on_disk", mapping["mode"])
+	assert.NotContains(t, mapping, "compression_level")
+
+	method := mapping["method"].(map[string]interface{})
+	assert.Equal(t, KNNEngineFaiss, method["engine"])
+	parameters := method["parameters"].(map[string]interface{})
+	assert.Equal(t, 16, parameters["m"])
+	assert.Equal(t, map[string]interface{}{
+		"name":       "sq",
+		"parameters": map[string]interface{}{"type": "fp16"},
+	}, parameters["encoder"])
+}
+
+func TestKNNProfileDrift(t *testing.T) {
+	settings := json.RawMessage(`{"index":{"number_of_shards":"1","number_of_replicas":"0","knn":"true"}}`)
+	mappings := json.RawMessage(`{"properties":{"embedding":{"type":"knn_vector","dimension":1024,
+		"method":{"engine":"lucene","space_type":"cosinesimil","name":"hnsw","parameters":{"ef_construction":256,"m":16}}}}}`)
+
+	drift, err := KNNProfileDrift(DefaultKNNIndexProfile(), "embedding", 1024, settings, mappings)
+	require.NoError(t, err)
+	assert.Empty(t, drift)
+
+	profile := DefaultKNNIndexProfile()
+	profile.Replicas = 1
+	profile.Engine = KNNEngineFaiss
+	drift, err = KNNProfileDrift(profile, "embedding", 768, settings, mappings)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"number_of_replicas: profile 1, index 0",
+		"dimension: profile 768, index 1024",
+		"engine: profile faiss, index lucene",
+	}, drift)
+
+	drift, err = KNNProfileDrift(DefaultKNNIndexProfile(), "embedding", 1024, settings, json.RawMessage(`{"properties":{}}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"embedding: knn_vector field is missing"}, drift)
+}
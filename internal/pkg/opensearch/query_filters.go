@@ -13,3 +13,54 @@ func applySecretExclusion(boolQuery map[string]interface{}, excludeSecret bool)
 
 	boolQuery["must_not"] = []map[string]interface{}{secretClause}
 }
+
+// applyTenantFilter restricts boolQuery to documents of the given tenants
+func applyTenantFilter(boolQuery map[string]interface{}, tenants []string) {
+	if len(tenants) == 0 || boolQuery == nil {
+		return
+	}
+
+	tenantClause := map[string]interface{}{
+		"terms": map[string]interface{}{
+			"tenant": tenants,
+		},
+	}
+
+	filters, _ := boolQuery["filter"].([]map[string]interface{})
+	boolQuery["filter"] = append(filters, tenantClause)
+}
+
+// applyGroupACL restricts boolQuery to documents without allowed_groups or shared with
+// one of groups. When enforced, callers without groups only see unrestricted documents.
+func applyGroupACL(boolQuery map[string]interface{}, groups []string, enforce bool) {
+	if !enforce || boolQuery == nil {
+		return
+	}
+
+	should := []map[string]interface{}{
+		{
+			"bool": map[string]interface{}{
+				"must_not": []map[string]interface{}{
+					{"exists": map[string]interface{}{"field": "allowed_groups"}},
+				},
+			},
+		},
+	}
+	if len(groups) > 0 {
+		should = append(should, map[string]interface{}{
+			"terms": map[string]interface{}{
+				"allowed_groups": groups,
+			},
+		})
+	}
+
+	aclClause := map[string]interface{}{
+		"bool": map[string]interface{}{
+			"should":               should,
+			"minimum_should_match": 1,
+		},
+	}
+
+	filters, _ := boolQuery["filter"].([]map[string]interface{})
+	boolQuery["filter"] = append(filters, aclClause)
+}
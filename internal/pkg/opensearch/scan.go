@@ -0,0 +1,62 @@
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ScannedDocument is a stored document returned by ScanDocuments
+type ScannedDocument struct {
+	ID     string
+	Source json.RawMessage
+}
+
+// ScanDocuments returns up to size documents of an index ordered by _id, starting after the
+// document ID given as after ("" starts from the beginning). Unlike the scroll API the
+// position is just the last ID, so a scan can be resumed by another process.
+func (c *Client) ScanDocuments(ctx context.Context, index, after string, size int) ([]ScannedDocument, error) {
+	return c.ScanMatchingDocuments(ctx, index, map[string]any{"match_all": map[string]any{}}, after, size)
+}
+
+// ScanMatchingDocuments is ScanDocuments restricted to the documents matching a query DSL clause
+func (c *Client) ScanMatchingDocuments(ctx context.Context, index string, match map[string]any, after string, size int) ([]ScannedDocument, error) {
+	if size <= 0 {
+		size = 100
+	}
+	query := map[string]any{
+		"size":  size,
+		"query": match,
+		"sort":  []any{map[string]any{"_id": "asc"}},
+	}
+	if after != "" {
+		query["search_after"] = []string{after}
+	}
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal scan query: %w", err)
+	}
+
+	var data struct {
+		Hits struct {
+			Hits []struct {
+				ID     string          `json:"_id"`
+				Source json.RawMessage `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	found, err := c.perform(ctx, rawRequest{method: http.MethodPost, path: "/" + index + "/_search", body: body}, &data)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("index %s not found", index)
+	}
+
+	docs := make([]ScannedDocument, 0, len(data.Hits.Hits))
+	for _, hit := range data.Hits.Hits {
+		docs = append(docs, ScannedDocument{ID: hit.ID, Source: hit.Source})
+	}
+	return docs, nil
+}
@@ -0,0 +1,115 @@
+package opensearch
+
+import "testing"
+
+func tenantTermsFromBool(t *testing.T, body map[string]interface{}) []string {
+	t.Helper()
+	querySection, ok := body["query"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("query section missing")
+	}
+	boolQuery, ok := querySection["bool"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("bool query missing")
+	}
+	return tenantTerms(t, boolQuery)
+}
+
+func tenantTerms(t *testing.T, boolQuery map[string]interface{}) []string {
+	t.Helper()
+	filters, ok := boolQuery["filter"].([]map[string]interface{})
+	if !ok {
+		t.Fatalf("expected filter clauses, got %#v", boolQuery["filter"])
+	}
+	for _, clause := range filters {
+		if terms, ok := clause["terms"].(map[string]interface{}); ok {
+			if tenants, ok := terms["tenant"].([]string); ok {
+				return tenants
+			}
+		}
+	}
+	t.Fatalf("tenant terms filter missing from %#v", filters)
+	return nil
+}
+
+// knnFilterBool returns the bool query applied inside the knn clause of a vector search
+func knnFilterBool(t *testing.T, body map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	querySection, ok := body["query"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("query section missing")
+	}
+	knnQuery, ok := querySection["knn"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a top-level knn query, got %#v", querySection)
+	}
+	vectorQuery, _ := knnQuery["embedding"].(map[string]interface{})
+	filter, ok := vectorQuery["filter"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("knn filter missing from %#v", vectorQuery)
+	}
+	boolQuery, ok := filter["bool"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("knn filter is not a bool query: %#v", filter)
+	}
+	return boolQuery
+}
+
+func TestBuildBM25SearchBodyAppliesTenantFilter(t *testing.T) {
+	client := &Client{}
+	body := client.buildBM25SearchBody(&BM25Query{
+		Query:   "budget",
+		Fields:  []string{"title"},
+		Size:    10,
+		Filters: map[string]string{"category": "finance"},
+		Tenants: []string{"finance", "hr"},
+	})
+
+	tenants := tenantTermsFromBool(t, body)
+	if len(tenants) != 2 || tenants[0] != "finance" || tenants[1] != "hr" {
+		t.Fatalf("unexpected tenants: %v", tenants)
+	}
+}
+
+func TestBuildVectorSearchBodyAppliesTenantFilter(t *testing.T) {
+	client := &Client{}
+	body := client.buildVectorSearchBody(&VectorQuery{
+		Vector:      []float64{0.1, 0.2},
+		VectorField: "embedding",
+		K:           5,
+		Size:        5,
+		Tenants:     []string{"hr"},
+	})
+
+	if tenants := tenantTerms(t, knnFilterBool(t, body)); len(tenants) != 1 || tenants[0] != "hr" {
+		t.Fatalf("unexpected tenants: %v", tenants)
+	}
+}
+
+func TestBuildVectorSearchBodyPostFiltersOnNmslib(t *testing.T) {
+	client := &Client{config: &Config{KNNEngine: KNNEngineNmslib}}
+	body := client.buildVectorSearchBody(&VectorQuery{
+		Vector:      []float64{0.1, 0.2},
+		VectorField: "embedding",
+		K:           5,
+		Size:        5,
+		Tenants:     []string{"hr"},
+	})
+
+	if tenants := tenantTermsFromBool(t, body); len(tenants) != 1 || tenants[0] != "hr" {
+		t.Fatalf("unexpected tenants: %v", tenants)
+	}
+}
+
+func TestBuildTermQueryBodyAppliesTenantFilter(t *testing.T) {
+	body := BuildTermQueryBody(&TermQuery{
+		Field:   "reference",
+		Values:  []string{"https://example.com"},
+		Size:    10,
+		Tenants: []string{"ops"},
+	})
+
+	if tenants := tenantTermsFromBool(t, body); len(tenants) != 1 || tenants[0] != "ops" {
+		t.Fatalf("unexpected tenants: %v", tenants)
+	}
+}
@@ -32,15 +32,18 @@ type VectorSearchResponse struct {
 }
 
 type VectorQuery struct {
-	Vector        []float64         `json:"vector"`
-	VectorField   string            `json:"vector_field"`
-	K             int               `json:"k"`
-	EfSearch      int               `json:"ef_search,omitempty"`
-	ExcludeSecret bool              `json:"exclude_secret,omitempty"`
-	Filters       map[string]string `json:"filters,omitempty"`
-	MinScore      float64           `json:"min_score,omitempty"`
-	Size          int               `json:"size,omitempty"`
-	From          int               `json:"from,omitempty"`
+	Vector          []float64         `json:"vector"`
+	VectorField     string            `json:"vector_field"`
+	K               int               `json:"k"`
+	EfSearch        int               `json:"ef_search,omitempty"`
+	ExcludeSecret   bool              `json:"exclude_secret,omitempty"`
+	Tenants         []string          `json:"tenants,omitempty"`
+	Groups          []string          `json:"groups,omitempty"`
+	EnforceGroupACL bool              `json:"enforce_group_acl,omitempty"`
+	Filters         map[string]string `json:"filters,omitempty"`
+	MinScore        float64           `json:"min_score,omitempty"`
+	Size            int               `json:"size,omitempty"`
+	From            int               `json:"from,omitempty"`
 }
 
 func (c *Client) SearchDenseVector(ctx context.Context, indexName string, query *VectorQuery) (*VectorSearchResponse, error) {
@@ -146,18 +149,18 @@ func (c *Client) SearchDenseVector(ctx context.Context, indexName string, query
 }
 
 func (c *Client) buildVectorSearchBody(query *VectorQuery) map[string]interface{} {
-	knnQuery := map[string]interface{}{
-		query.VectorField: map[string]interface{}{
-			"vector": query.Vector,
-			"k":      query.K,
-		},
+	vectorQuery := map[string]interface{}{
+		"vector": query.Vector,
+		"k":      query.K,
 	}
-
 	if query.EfSearch > 0 {
-		knnQuery[query.VectorField].(map[string]interface{})["method_parameters"] = map[string]interface{}{
+		vectorQuery["method_parameters"] = map[string]interface{}{
 			"ef_search": query.EfSearch,
 		}
 	}
+	knnQuery := map[string]interface{}{
+		query.VectorField: vectorQuery,
+	}
 
 	body := map[string]interface{}{
 		"size": query.Size,
@@ -171,6 +174,7 @@ func (c *Client) buildVectorSearchBody(query *VectorQuery) map[string]interface{
 		body["min_score"] = query.MinScore
 	}
 
+	restrictions := map[string]interface{}{}
 	if len(query.Filters) > 0 {
 		filters := make([]map[string]interface{}, 0, len(query.Filters))
 		for field, value := range query.Filters {
@@ -180,38 +184,26 @@ func (c *Client) buildVectorSearchBody(query *VectorQuery) map[string]interface{
 				},
 			})
 		}
-
-		queryClause := map[string]interface{}{
-			"bool": map[string]interface{}{
-				"must": []map[string]interface{}{
-					{"knn": knnQuery},
-				},
-				"filter": filters,
-			},
-		}
-		body["query"] = queryClause
-	}
-
-	if query.ExcludeSecret {
-		queryClause, ok := body["query"].(map[string]interface{})
-		if !ok {
-			queryClause = map[string]interface{}{}
-		}
-		boolQuery := queryClause["bool"]
-		if boolQuery == nil {
-			queryClause = map[string]interface{}{
-				"bool": map[string]interface{}{
-					"must": []map[string]interface{}{
-						{"knn": knnQuery},
-					},
-				},
+		restrictions["filter"] = filters
+	}
+	applySecretExclusion(restrictions, query.ExcludeSecret)
+	applyTenantFilter(restrictions, query.Tenants)
+	applyGroupACL(restrictions, query.Groups, query.EnforceGroupACL)
+
+	if len(restrictions) > 0 {
+		if c.filtersInsideKNN() {
+			// Filtering during the k-NN search keeps documents the caller cannot see
+			// from taking the k nearest neighbour slots
+			vectorQuery["filter"] = map[string]interface{}{
+				"bool": restrictions,
+			}
+		} else {
+			restrictions["must"] = []map[string]interface{}{
+				{"knn": knnQuery},
+			}
+			body["query"] = map[string]interface{}{
+				"bool": restrictions,
 			}
-			body["query"] = queryClause
-			boolQuery = queryClause["bool"]
-		}
-
-		if boolClause, ok := boolQuery.(map[string]interface{}); ok {
-			applySecretExclusion(boolClause, true)
 		}
 	}
 	body["_source"] = map[string]interface{}{
@@ -221,6 +213,12 @@ func (c *Client) buildVectorSearchBody(query *VectorQuery) map[string]interface{
 	return body
 }
 
+// filtersInsideKNN reports whether restrictions can be applied inside the knn clause.
+// The nmslib engine does not support k-NN filters, so its results are filtered afterwards.
+func (c *Client) filtersInsideKNN() bool {
+	return c.config == nil || !strings.EqualFold(c.config.KNNEngine, KNNEngineNmslib)
+}
+
 func (c *Client) CreateVectorIndex(ctx context.Context, indexName string, dimension int, engine string, spaceType string) error {
 	if err := c.WaitForRateLimit(ctx); err != nil {
 		return fmt.Errorf("rate limit error: %w", err)
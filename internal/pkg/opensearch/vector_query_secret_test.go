@@ -14,15 +14,7 @@ func TestBuildVectorSearchBodyAppliesSecretExclusion(t *testing.T) {
 	}
 
 	body := client.buildVectorSearchBody(query)
-	querySection, ok := body["query"].(map[string]interface{})
-	if !ok {
-		t.Fatalf("query section missing")
-	}
-
-	boolQuery, ok := querySection["bool"].(map[string]interface{})
-	if !ok {
-		t.Fatalf("bool query missing")
-	}
+	boolQuery := knnFilterBool(t, body)
 
 	mustNot, ok := boolQuery["must_not"].([]map[string]interface{})
 	if !ok {
@@ -52,4 +44,8 @@ func TestBuildVectorSearchBodyDoesNotAddSecretClauseWhenDisabled(t *testing.T) {
 	if _, ok := querySection["bool"]; ok {
 		t.Fatalf("did not expect bool query when secret exclusion is disabled")
 	}
+	knnQuery, _ := querySection["knn"].(map[string]interface{})
+	if vectorQuery, _ := knnQuery["embedding"].(map[string]interface{}); vectorQuery["filter"] != nil {
+		t.Fatalf("did not expect a knn filter when secret exclusion is disabled")
+	}
 }
@@ -53,6 +53,8 @@ type ChatOptions struct {
 	ExportEvalPath string
 	MCPConfigPath  string
 	MCPClient      mcpclient.RetryClient
+	EfSearch       int    // HNSW ef_search; 0 falls back to OPENSEARCH_KNN_EF_SEARCH
+	IndexName      string // Index or comma-separated index list; empty uses OPENSEARCH_INDEX
 }
 
 // ChatResult holds the result of a single GenerateChatResponse call.
@@ -88,7 +90,8 @@ func RunChat(cmd *cobra.Command, opts ChatOptions) error {
 	}
 
 	chatClient := bedrock.NewBedrockClient(bedrockConfig, cfg.ChatModel)
-	embeddingClient, err := embedding.NewEmbeddingClient(cfg)
+	activeCfg := opensearch.ResolveActiveEmbeddingConfig(context.Background(), cfg, tenantIndexName(cfg, chatIndexName(cfg, opts), opts.IndexName != ""))
+	embeddingClient, err := embedding.NewEmbeddingClient(activeCfg)
 	if err != nil {
 		return fmt.Errorf("failed to create embedding client: %w", err)
 	}
@@ -206,7 +209,7 @@ func startChatLoop(chatClient ChatResponder, embeddingClient embedding.Embedding
 				VectorWeight:       opts.VectorWeight,
 				FusionMethod:       "weighted_sum",
 				TopK:               opts.ContextSize,
-				IndexName:          getIndexNameForChat(cfg, "hybrid"),
+				IndexName:          chatIndexName(cfg, opts),
 				UseJapaneseNLP:     opts.UseJapaneseNLP,
 				ChatModel:          cfg.ChatModel,
 				EmbeddingModel:     chatEmbModelName,
@@ -284,14 +287,18 @@ func GenerateChatResponse(userInput string, history []bedrock.ChatMessage, chatC
 		}
 
 		searchRequest := &search.SearchRequest{
-			Query:          userInput,
-			IndexName:      getIndexNameForChat(cfg, "hybrid"),
-			ContextSize:    opts.ContextSize,
-			BM25Weight:     opts.BM25Weight,
-			VectorWeight:   opts.VectorWeight,
-			UseJapaneseNLP: opts.UseJapaneseNLP,
-			ExcludeSecret:  true,
-			TimeoutSeconds: 30,
+			Query:           userInput,
+			IndexName:       tenantIndexName(cfg, chatIndexName(cfg, opts), opts.IndexName != ""),
+			ContextSize:     opts.ContextSize,
+			BM25Weight:      opts.BM25Weight,
+			VectorWeight:    opts.VectorWeight,
+			UseJapaneseNLP:  opts.UseJapaneseNLP,
+			ExcludeSecret:   true,
+			TimeoutSeconds:  30,
+			EfSearch:        opts.EfSearch,
+			Tenants:         cliTenants(cfg),
+			Groups:          cfg.LocalGroups(),
+			EnforceGroupACL: true,
 		}
 
 		searchResponse, err := searchService.Search(ctx, searchRequest)
@@ -448,6 +455,14 @@ func validateOpenSearch(ctx context.Context, cfg *appconfig.Config, embeddingCli
 	return nil
 }
 
+// chatIndexName returns --index-name when set, otherwise the configured chat index
+func chatIndexName(cfg *appconfig.Config, opts ChatOptions) string {
+	if opts.IndexName != "" {
+		return opts.IndexName
+	}
+	return getIndexNameForChat(cfg, "hybrid")
+}
+
 // getIndexNameForChat returns the index name for chat queries based on search mode.
 func getIndexNameForChat(cfg *appconfig.Config, searchMode string) string {
 	switch searchMode {
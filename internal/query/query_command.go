@@ -99,6 +99,7 @@ type QueryOptions struct {
 	ExportEvalPath string
 	MCPConfigPath  string
 	MCPResults     *mcpclient.QueryResult
+	EfSearch       int // HNSW ef_search; 0 falls back to OPENSEARCH_KNN_EF_SEARCH
 }
 
 // RunQuery is the exported entry point called from cmd/query.go.
@@ -141,7 +142,9 @@ func RunQuery(cmd *cobra.Command, opts QueryOptions) error {
 		return runSlackOnlySearch(ctx, cfg, bedrockConfig, opts, mcpManager, mcpRetryPlanner)
 	}
 
-	embeddingClient, err := embedding.NewEmbeddingClient(cfg)
+	// Queries are embedded with the model the index was built with, which follows model migrations
+	activeCfg := opensearch.ResolveActiveEmbeddingConfig(ctx, cfg, tenantIndexName(cfg, getIndexName(cfg, opts), opts.IndexName != ""))
+	embeddingClient, err := embedding.NewEmbeddingClient(activeCfg)
 	if err != nil {
 		return fmt.Errorf("failed to create embedding client: %w", err)
 	}
@@ -286,15 +289,19 @@ func attemptOpenSearchHybrid(ctx context.Context, cfg *appconfig.Config, embeddi
 	hybridEngine := NewHybridEngine(osClient, embeddingClient)
 
 	hybridQuery := &opensearch.HybridQuery{
-		Query:          opts.QueryText,
-		IndexName:      getIndexName(cfg, opts),
-		Size:           opts.TopK,
-		BM25Weight:     opts.BM25Weight,
-		VectorWeight:   opts.VectorWeight,
-		FusionMethod:   getFusionMethod(opts),
-		UseJapaneseNLP: opts.UseJapaneseNLP,
-		TimeoutSeconds: opts.Timeout,
-		ExcludeSecret:  true,
+		Query:           opts.QueryText,
+		IndexName:       tenantIndexName(cfg, getIndexName(cfg, opts), opts.IndexName != ""),
+		Size:            opts.TopK,
+		BM25Weight:      opts.BM25Weight,
+		VectorWeight:    opts.VectorWeight,
+		FusionMethod:    getFusionMethod(opts),
+		UseJapaneseNLP:  opts.UseJapaneseNLP,
+		TimeoutSeconds:  opts.Timeout,
+		ExcludeSecret:   true,
+		EfSearch:        getEfSearch(cfg, opts),
+		Tenants:         cliTenants(cfg),
+		Groups:          cfg.LocalGroups(),
+		EnforceGroupACL: true,
 	}
 
 	if opts.FilterQuery != "" {
@@ -306,7 +313,32 @@ func attemptOpenSearchHybrid(ctx context.Context, cfg *appconfig.Config, embeddi
 	}
 
 	log.Println("Executing OpenSearch hybrid search...")
-	return hybridEngine.Search(ctx, hybridQuery)
+	return hybridEngine.SearchIndexes(ctx, hybridQuery)
+}
+
+// getEfSearch returns the --ef-search value, or OPENSEARCH_KNN_EF_SEARCH when unset
+func getEfSearch(cfg *appconfig.Config, opts QueryOptions) int {
+	if opts.EfSearch > 0 {
+		return opts.EfSearch
+	}
+	return cfg.OpenSearchKNNEfSearch
+}
+
+// cliTenants returns the tenant CLI searches are scoped to via TENANT_ID, or nil when unscoped
+func cliTenants(cfg *appconfig.Config) []string {
+	if cfg.TenantID == "" {
+		return nil
+	}
+	return []string{cfg.TenantID}
+}
+
+// tenantIndexName maps the configured index to the CLI tenant's index when per-tenant
+// indexes are enabled. Explicitly requested indexes are used as given.
+func tenantIndexName(cfg *appconfig.Config, indexName string, explicit bool) string {
+	if explicit {
+		return indexName
+	}
+	return cfg.TenantIndexSpec(indexName, cliTenants(cfg))
 }
 
 func getIndexName(cfg *appconfig.Config, opts QueryOptions) string {
@@ -491,6 +523,9 @@ func PrintHybridResults(result *opensearch.HybridSearchResult, searchType string
 				fmt.Printf(" (Vector: %.4f)", doc.VectorScore)
 			}
 			fmt.Printf(" [%s]\n", doc.SearchType)
+			if doc.IndexTarget != "" {
+				fmt.Printf("     Index: %s\n", doc.Index)
+			}
 
 			if doc.Source != nil {
 				var source map[string]interface{}
@@ -48,6 +48,10 @@ type SearchRequest struct {
 	Filters           map[string]string `json:"filters,omitempty"`
 	EnableSlackSearch bool              `json:"enable_slack_search"`
 	SlackChannels     []string          `json:"slack_channels,omitempty"`
+	EfSearch          int               `json:"ef_search,omitempty"`
+	Tenants           []string          `json:"tenants,omitempty"`
+	Groups            []string          `json:"groups,omitempty"`
+	EnforceGroupACL   bool              `json:"enforce_group_acl,omitempty"`
 }
 
 // SearchResponse represents the search response with context and references
@@ -176,19 +180,26 @@ func (s *HybridSearchService) Search(ctx context.Context, request *SearchRequest
 	if request.TimeoutSeconds <= 0 {
 		request.TimeoutSeconds = 30
 	}
+	if request.EfSearch <= 0 {
+		request.EfSearch = s.config.OpenSearchKNNEfSearch
+	}
 
 	// Build hybrid query
 	hybridQuery := &opensearch.HybridQuery{
-		Query:          request.Query,
-		IndexName:      request.IndexName,
-		Size:           request.ContextSize,
-		BM25Weight:     request.BM25Weight,
-		VectorWeight:   request.VectorWeight,
-		FusionMethod:   opensearch.FusionMethodWeightedSum,
-		UseJapaneseNLP: request.UseJapaneseNLP,
-		TimeoutSeconds: request.TimeoutSeconds,
-		ExcludeSecret:  request.ExcludeSecret,
-		Filters:        request.Filters,
+		Query:           request.Query,
+		IndexName:       request.IndexName,
+		Size:            request.ContextSize,
+		BM25Weight:      request.BM25Weight,
+		VectorWeight:    request.VectorWeight,
+		FusionMethod:    opensearch.FusionMethodWeightedSum,
+		UseJapaneseNLP:  request.UseJapaneseNLP,
+		TimeoutSeconds:  request.TimeoutSeconds,
+		ExcludeSecret:   request.ExcludeSecret,
+		Filters:         request.Filters,
+		EfSearch:        request.EfSearch,
+		Tenants:         request.Tenants,
+		Groups:          request.Groups,
+		EnforceGroupACL: request.EnforceGroupACL,
 	}
 
 	span.SetAttributes(
@@ -212,7 +223,7 @@ func (s *HybridSearchService) Search(ctx context.Context, request *SearchRequest
 		s.logger.Printf("Query contains explicit Slack search enable directive; enabling Slack search")
 		request.EnableSlackSearch = true
 	default:
-		if !request.EnableSlackSearch && queryMentionsSlack(request.Query) {
+		if !request.EnableSlackSearch && s.slackService != nil && queryMentionsSlack(request.Query) {
 			s.logger.Printf("Query contains 'Slack'; forcing Slack search enablement")
 			request.EnableSlackSearch = true
 		}
@@ -240,7 +251,7 @@ func (s *HybridSearchService) Search(ctx context.Context, request *SearchRequest
 
 	group.Go(func() error {
 		defer close(docReady)
-		result, err := s.hybridEngine.Search(groupCtx, hybridQuery)
+		result, err := s.hybridEngine.SearchIndexes(groupCtx, hybridQuery)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, "hybrid_search_failed")
@@ -266,6 +277,9 @@ func (s *HybridSearchService) Search(ctx context.Context, request *SearchRequest
 			}
 			if content, ok := source["content"].(string); ok && content != "" {
 				contextText := buildContextText(source, content)
+				if doc.IndexTarget != "" {
+					contextText = fmt.Sprintf("インデックス: %s\n", doc.Index) + contextText
+				}
 				resp.ContextParts = append(resp.ContextParts, contextText)
 			}
 			var title, reference string
@@ -377,13 +391,14 @@ func (s *HybridSearchService) Search(ctx context.Context, request *SearchRequest
 // SearchWithDefaults performs search using configuration defaults
 func (s *HybridSearchService) SearchWithDefaults(ctx context.Context, query string, indexName string) (*SearchResponse, error) {
 	request := &SearchRequest{
-		Query:          query,
-		IndexName:      indexName,
-		ContextSize:    10, // Default context size
-		BM25Weight:     0.5,
-		VectorWeight:   0.5,
-		UseJapaneseNLP: true,
-		TimeoutSeconds: 30,
+		Query:           query,
+		IndexName:       indexName,
+		ContextSize:     10, // Default context size
+		BM25Weight:      0.5,
+		VectorWeight:    0.5,
+		UseJapaneseNLP:  true,
+		TimeoutSeconds:  30,
+		EnforceGroupACL: true,
 	}
 
 	return s.Search(ctx, request)
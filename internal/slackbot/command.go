@@ -151,6 +151,9 @@ func RunSlackBot(ctx context.Context, opts SlackBotOptions) error {
 		hybridAdapter := NewHybridSearchAdapter(cfg, scfg.MaxResults, convSearcher, &awsCfg)
 		hybridAdapter.SetSlackClient(client) // Enable Slack URL message fetching
 		hybridAdapter.SetMCPClient(mcpManager)
+		if cfg.ACLSlackUserGroups {
+			hybridAdapter.SetUserGroupResolver(newUserGroupResolver(client, 0))
+		}
 		adapter = hybridAdapter
 	}
 
@@ -43,6 +43,7 @@ type HybridSearchAdapter struct {
 	slackSearch slackConvSearcher
 	slackClient *slack.Client
 	mcpClient   *mcpclient.Manager
+	userGroups  *userGroupResolver
 
 	awsCfgMu   sync.RWMutex
 	awsCfg     *aws.Config
@@ -66,6 +67,11 @@ func (h *HybridSearchAdapter) SetSlackClient(client *slack.Client) {
 	h.slackClient = client
 }
 
+// SetUserGroupResolver enables matching documents' allowed_groups against Slack user groups
+func (h *HybridSearchAdapter) SetUserGroupResolver(resolver *userGroupResolver) {
+	h.userGroups = resolver
+}
+
 func (h *HybridSearchAdapter) SetMCPClient(client *mcpclient.Manager) {
 	h.mcpClient = client
 }
@@ -109,9 +115,39 @@ func (h *HybridSearchAdapter) shouldExcludeSecret(opts SearchOptions) bool {
 	return !checker.CanAccessSecret(false, opts.UserID)
 }
 
+// searchTenants resolves the tenants a channel may search via TENANT_SLACK_CHANNELS
+func (h *HybridSearchAdapter) searchTenants(opts SearchOptions) ([]string, error) {
+	return h.cfg.ResolveSearchTenants(h.cfg.TenantsForSlackChannel(opts.ChannelID))
+}
+
+// searchGroups resolves the Slack user groups of the requesting user. Lookup failures
+// yield no groups so that only documents without allowed_groups are searched.
+func (h *HybridSearchAdapter) searchGroups(ctx context.Context, opts SearchOptions) []string {
+	if h.userGroups == nil {
+		return nil
+	}
+	groups, err := h.userGroups.GroupsForUser(ctx, opts.UserID)
+	if err != nil {
+		log.Printf("user group lookup failed for %s: %v", opts.UserID, err)
+		return nil
+	}
+	return groups
+}
+
 func (h *HybridSearchAdapter) Search(ctx context.Context, query string, opts SearchOptions) *SearchResult {
 	start := time.Now()
 
+	tenants, err := h.searchTenants(opts)
+	if err != nil {
+		log.Printf("tenant resolution failed for channel %s: %v", opts.ChannelID, err)
+		return &SearchResult{
+			Items:     nil,
+			Total:     0,
+			Elapsed:   time.Since(start),
+			ChatModel: h.cfg.ChatModel,
+		}
+	}
+
 	// Fetch messages from Slack URLs in the query (if any)
 	var slackURLContext string
 	if h.slackClient != nil {
@@ -132,9 +168,12 @@ func (h *HybridSearchAdapter) Search(ctx context.Context, query string, opts Sea
 			ChatModel: h.cfg.ChatModel,
 		}
 	}
-	embedClient, err := embedding.NewEmbeddingClient(h.cfg)
+	chatClient := bedrock.GetSharedBedrockClient(awsCfg, h.cfg.ChatModel)
+
+	// OpenSearch client
+	osCfg, err := opensearch.NewConfigFromTypes(h.cfg)
 	if err != nil {
-		log.Printf("failed to create embedding client: %v", err)
+		log.Printf("opensearch config error: %v", err)
 		return &SearchResult{
 			Items:     nil,
 			Total:     0,
@@ -142,12 +181,9 @@ func (h *HybridSearchAdapter) Search(ctx context.Context, query string, opts Sea
 			ChatModel: h.cfg.ChatModel,
 		}
 	}
-	chatClient := bedrock.GetSharedBedrockClient(awsCfg, h.cfg.ChatModel)
-
-	// OpenSearch client
-	osCfg, err := opensearch.NewConfigFromTypes(h.cfg)
+	osClient, err := opensearch.NewClient(osCfg)
 	if err != nil {
-		log.Printf("opensearch config error: %v", err)
+		log.Printf("opensearch client error: %v", err)
 		return &SearchResult{
 			Items:     nil,
 			Total:     0,
@@ -155,9 +191,11 @@ func (h *HybridSearchAdapter) Search(ctx context.Context, query string, opts Sea
 			ChatModel: h.cfg.ChatModel,
 		}
 	}
-	osClient, err := opensearch.NewClient(osCfg)
+
+	// Embed with the model the index serves, which follows model migration cutovers
+	embedClient, err := embedding.NewEmbeddingClient(osClient.ActiveEmbeddingConfig(ctx, h.cfg, h.cfg.OpenSearchIndex))
 	if err != nil {
-		log.Printf("opensearch client error: %v", err)
+		log.Printf("failed to create embedding client: %v", err)
 		return &SearchResult{
 			Items:     nil,
 			Total:     0,
@@ -168,16 +206,19 @@ func (h *HybridSearchAdapter) Search(ctx context.Context, query string, opts Sea
 
 	engine := opensearch.NewHybridSearchEngine(osClient, embedClient)
 	NotifyProgress(ctx, "ドキュメントを検索中...")
-	res, err := engine.Search(ctx, &opensearch.HybridQuery{
-		Query:          query,
-		IndexName:      h.cfg.OpenSearchIndex,
-		Size:           h.maxResults,
-		BM25Weight:     0.5,
-		VectorWeight:   0.5,
-		FusionMethod:   opensearch.FusionMethodRRF,
-		UseJapaneseNLP: true,
-		TimeoutSeconds: 10,
-		ExcludeSecret:  h.shouldExcludeSecret(opts),
+	res, err := engine.SearchIndexes(ctx, &opensearch.HybridQuery{
+		Query:           query,
+		IndexName:       h.cfg.TenantIndexSpec(h.cfg.OpenSearchIndex, tenants),
+		Size:            h.maxResults,
+		BM25Weight:      0.5,
+		VectorWeight:    0.5,
+		FusionMethod:    opensearch.FusionMethodRRF,
+		UseJapaneseNLP:  true,
+		TimeoutSeconds:  10,
+		ExcludeSecret:   h.shouldExcludeSecret(opts),
+		Tenants:         tenants,
+		Groups:          h.searchGroups(ctx, opts),
+		EnforceGroupACL: true,
 	})
 	if err != nil || res == nil || res.FusionResult == nil {
 		log.Printf("hybrid search failed: %v", err)
@@ -1,9 +1,12 @@
 package slackbot
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
 )
 
 func TestHybridSearchAdapter_shouldExcludeSecret_AllowsAllowlistedUser(t *testing.T) {
@@ -51,3 +54,18 @@ func TestHybridSearchAdapter_shouldExcludeSecret_DeniesWhenConfigMissing(t *test
 		t.Fatalf("expected missing slack-secret.yaml to deny secret docs")
 	}
 }
+
+func TestHybridSearchAdapter_searchTenants_UsesChannelMapping(t *testing.T) {
+	adapter := &HybridSearchAdapter{cfg: &appconfig.Config{
+		TenantIsolation:     true,
+		TenantSlackChannels: map[string][]string{"C0123": {"finance"}},
+	}}
+
+	tenants, err := adapter.searchTenants(SearchOptions{ChannelID: "C0123"})
+	if err != nil || len(tenants) != 1 || tenants[0] != "finance" {
+		t.Fatalf("expected finance tenant, got %v (err=%v)", tenants, err)
+	}
+	if _, err := adapter.searchTenants(SearchOptions{ChannelID: "C9999"}); !errors.Is(err, appconfig.ErrNoTenantAccess) {
+		t.Fatalf("expected unmapped channel to be denied, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	"github.com/slack-go/slack"
+)
+
+// userGroupLister is the subset of the Slack client used to read user group membership.
+type userGroupLister interface {
+	GetUserGroupsContext(ctx context.Context, options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error)
+}
+
+// userGroupResolver maps Slack users to the handles of the user groups they belong to.
+// Membership is fetched with usergroups.list (usergroups:read scope) and cached for ttl.
+type userGroupResolver struct {
+	lister userGroupLister
+	ttl    time.Duration
+
+	mu        sync.Mutex
+	members   map[string][]string
+	fetchedAt time.Time
+}
+
+// newUserGroupResolver creates a resolver; ttl defaults to five minutes.
+func newUserGroupResolver(lister userGroupLister, ttl time.Duration) *userGroupResolver {
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	return &userGroupResolver{lister: lister, ttl: ttl}
+}
+
+// GroupsForUser returns the normalized handles of userID's user groups.
+func (r *userGroupResolver) GroupsForUser(ctx context.Context, userID string) ([]string, error) {
+	if r == nil || userID == "" {
+		return nil, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.members == nil || time.Since(r.fetchedAt) >= r.ttl {
+		groups, err := r.lister.GetUserGroupsContext(ctx, slack.GetUserGroupsOptionIncludeUsers(true))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Slack user groups: %w", err)
+		}
+		members := make(map[string][]string)
+		for _, group := range groups {
+			if group.Handle == "" || group.DateDelete != 0 {
+				continue
+			}
+			for _, user := range group.Users {
+				members[user] = append(members[user], group.Handle)
+			}
+		}
+		r.members = members
+		r.fetchedAt = time.Now()
+	}
+	return appconfig.NormalizeGroups(r.members[userID]), nil
+}
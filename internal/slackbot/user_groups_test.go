@@ -0,0 +1,53 @@
+package slackbot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+type stubUserGroupLister struct {
+	groups []slack.UserGroup
+	err    error
+	calls  int
+}
+
+func (s *stubUserGroupLister) GetUserGroupsContext(ctx context.Context, options ...slack.GetUserGroupsOption) ([]slack.UserGroup, error) {
+	s.calls++
+	return s.groups, s.err
+}
+
+func TestUserGroupResolver_GroupsForUser(t *testing.T) {
+	lister := &stubUserGroupLister{groups: []slack.UserGroup{
+		{Handle: "SRE", Users: []string{"U1", "U2"}},
+		{Handle: "security", Users: []string{"U1"}},
+		{Handle: "retired", Users: []string{"U1"}, DateDelete: 1},
+	}}
+	resolver := newUserGroupResolver(lister, time.Minute)
+
+	groups, err := resolver.GroupsForUser(context.Background(), "U1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(groups) != 2 || groups[0] != "security" || groups[1] != "sre" {
+		t.Fatalf("unexpected groups: %v", groups)
+	}
+	if groups, _ := resolver.GroupsForUser(context.Background(), "U3"); groups != nil {
+		t.Fatalf("expected no groups for a non-member, got %v", groups)
+	}
+	if lister.calls != 1 {
+		t.Fatalf("expected membership to be cached, got %d calls", lister.calls)
+	}
+}
+
+func TestHybridSearchAdapter_searchGroups_FailsClosed(t *testing.T) {
+	adapter := &HybridSearchAdapter{}
+	adapter.SetUserGroupResolver(newUserGroupResolver(&stubUserGroupLister{err: errors.New("missing_scope")}, time.Minute))
+
+	if groups := adapter.searchGroups(context.Background(), SearchOptions{UserID: "U1"}); groups != nil {
+		t.Fatalf("expected no groups when the lookup fails, got %v", groups)
+	}
+}
@@ -75,10 +75,29 @@ func (s *Server) handleAPIErrors(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	errors := s.state.GetRecentErrors()
+	errors := s.recentErrors(r.Context())
 	s.writeJSON(w, errors)
 }
 
+func (s *Server) handleAPIConsistency(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.consistency == nil {
+		http.Error(w, "Consistency reports are not available", http.StatusNotFound)
+		return
+	}
+
+	report, err := s.consistency.LatestConsistencyReport(r.Context())
+	if err != nil {
+		s.logger.Printf("Failed to read consistency report: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	s.writeJSON(w, report)
+}
+
 func (s *Server) writeJSON(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(data); err != nil {
@@ -3,6 +3,9 @@ package webui
 import domain "github.com/ca-srg/ragent/internal/pkg/domain"
 
 type Dependencies struct {
-	FileScanner domain.FileScanner
-	Vectorizer  domain.Vectorizer
+	FileScanner   domain.FileScanner
+	Vectorizer    domain.Vectorizer
+	FailureLedger domain.FailureLedger // optional; recent errors fall back to the in-memory state
+
+	ConsistencyReports domain.ConsistencyReports // optional; the consistency card is hidden without it
 }
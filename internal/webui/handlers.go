@@ -44,8 +44,9 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 	data := &DashboardData{
 		ActivePage:   "dashboard",
 		State:        state,
-		RecentErrors: s.state.GetRecentErrors(),
+		RecentErrors: s.recentErrors(r.Context()),
 		LastRun:      s.state.GetLastRun(),
+		Consistency:  s.consistencyView(r.Context()),
 	}
 
 	if err := s.templates.Render(w, "index.html", data); err != nil {
@@ -149,13 +150,81 @@ func (s *Server) handlePartialFileList(w http.ResponseWriter, r *http.Request) {
 
 // handlePartialErrorList handles the error list partial for HTMX
 func (s *Server) handlePartialErrorList(w http.ResponseWriter, r *http.Request) {
-	errors := s.state.GetRecentErrors()
+	errors := s.recentErrors(r.Context())
 	if err := s.templates.Render(w, "error_list.html", errors); err != nil {
 		s.logger.Printf("Failed to render error list partial: %v", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 	}
 }
 
+// recentErrors returns recent errors from the failure ledger when one is configured,
+// so failures of CLI runs are shown as well; otherwise errors recorded in memory are returned
+func (s *Server) recentErrors(ctx context.Context) []ErrorInfo {
+	if s.ledger == nil {
+		return s.state.GetRecentErrors()
+	}
+
+	failures, err := s.ledger.ListRecentFailures(ctx, maxErrorsSize)
+	if err != nil {
+		s.logger.Printf("Failed to read failure ledger: %v", err)
+		return s.state.GetRecentErrors()
+	}
+
+	errors := make([]ErrorInfo, 0, len(failures))
+	for _, f := range failures {
+		errors = append(errors, ErrorInfo{
+			Timestamp: f.Timestamp,
+			FilePath:  f.FilePath,
+			ErrorType: f.Type,
+			Message:   f.Message,
+			Retryable: f.Retryable,
+			Backend:   f.Backend,
+			Attempts:  f.RetryCount + 1,
+		})
+	}
+	return errors
+}
+
+// handlePartialConsistency handles the consistency report partial for HTMX
+func (s *Server) handlePartialConsistency(w http.ResponseWriter, r *http.Request) {
+	view := s.consistencyView(r.Context())
+	if view == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if err := s.templates.Render(w, "consistency.html", view); err != nil {
+		s.logger.Printf("Failed to render consistency partial: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// consistencyView returns the latest consistency report for display,
+// or nil when no report source is configured
+func (s *Server) consistencyView(ctx context.Context) *ConsistencyView {
+	if s.consistency == nil {
+		return nil
+	}
+
+	view := &ConsistencyView{}
+	report, err := s.consistency.LatestConsistencyReport(ctx)
+	if err != nil {
+		s.logger.Printf("Failed to read consistency report: %v", err)
+		return view
+	}
+	if report == nil {
+		return view
+	}
+
+	view.Report = report
+	view.Counts = report.CountByKind()
+	view.Issues = report.Issues
+	if len(view.Issues) > maxConsistencyIssues {
+		view.Hidden = len(view.Issues) - maxConsistencyIssues
+		view.Issues = view.Issues[:maxConsistencyIssues]
+	}
+	return view
+}
+
 // getFileList returns the list of files, optionally filtered by search query
 func (s *Server) getFileList(searchQuery string) ([]FileListItem, error) {
 	files, err := s.fileScanner.ScanDirectory(s.config.Directory)
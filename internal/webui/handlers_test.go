@@ -1,7 +1,9 @@
 package webui
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
@@ -11,6 +13,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	domain "github.com/ca-srg/ragent/internal/pkg/domain"
 )
 
 type testServer struct {
@@ -230,3 +235,112 @@ func TestContainsIgnoreCase(t *testing.T) {
 		})
 	}
 }
+
+type fakeFailureLedger struct {
+	failures []domain.ProcessingError
+	err      error
+}
+
+func (l *fakeFailureLedger) ListRecentFailures(ctx context.Context, limit int) ([]domain.ProcessingError, error) {
+	return l.failures, l.err
+}
+
+func TestRecentErrorsFromFailureLedger(t *testing.T) {
+	ledger := &fakeFailureLedger{failures: []domain.ProcessingError{
+		{
+			Type:       appconfig.ErrorTypeRateLimit,
+			Message:    "too many requests",
+			FilePath:   "csv://data/rows.csv/row3",
+			Timestamp:  time.Now(),
+			Retryable:  true,
+			RetryCount: 2,
+			Backend:    "OpenSearch",
+		},
+	}}
+
+	srv, err := NewServer(DefaultServerConfig(), &Dependencies{
+		FileScanner:   &mockFileScanner{},
+		Vectorizer:    &mockVectorizer{},
+		FailureLedger: ledger,
+	}, log.New(io.Discard, "", 0))
+	require.NoError(t, err)
+	srv.state.AddError(ErrorInfo{FilePath: "in-memory.md", Message: "ignored"})
+
+	w := httptest.NewRecorder()
+	srv.handleAPIErrors(w, httptest.NewRequest(http.MethodGet, "/api/errors", nil))
+
+	var result []ErrorInfo
+	require.NoError(t, json.NewDecoder(w.Result().Body).Decode(&result))
+	require.Len(t, result, 1)
+	assert.Equal(t, "csv://data/rows.csv/row3", result[0].FilePath)
+	assert.Equal(t, appconfig.ErrorTypeRateLimit, result[0].ErrorType)
+	assert.Equal(t, "OpenSearch", result[0].Backend)
+	assert.Equal(t, 3, result[0].Attempts)
+
+	// The in-memory errors are used when the ledger cannot be read
+	ledger.err = errors.New("database is locked")
+	w = httptest.NewRecorder()
+	srv.handleAPIErrors(w, httptest.NewRequest(http.MethodGet, "/api/errors", nil))
+
+	result = nil
+	require.NoError(t, json.NewDecoder(w.Result().Body).Decode(&result))
+	require.Len(t, result, 1)
+	assert.Equal(t, "in-memory.md", result[0].FilePath)
+}
+
+type fakeConsistencyReports struct {
+	report *domain.ConsistencyReport
+}
+
+func (f *fakeConsistencyReports) LatestConsistencyReport(ctx context.Context) (*domain.ConsistencyReport, error) {
+	return f.report, nil
+}
+
+func TestConsistencyReportOnDashboard(t *testing.T) {
+	reports := &fakeConsistencyReports{}
+	srv, err := NewServer(DefaultServerConfig(), &Dependencies{
+		FileScanner:        &mockFileScanner{},
+		Vectorizer:         &mockVectorizer{},
+		ConsistencyReports: reports,
+	}, log.New(io.Discard, "", 0))
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	srv.handlePartialConsistency(w, httptest.NewRequest(http.MethodGet, "/partials/consistency", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "まだ実行されていません")
+
+	issues := make([]domain.ConsistencyIssue, 0, maxConsistencyIssues+3)
+	for i := 0; i < maxConsistencyIssues+3; i++ {
+		issues = append(issues, domain.ConsistencyIssue{Kind: domain.IssueMissingInOpenSearch, Backend: "vector_store", ID: "doc_chunk_" + string(rune('a'+i))})
+	}
+	reports.report = &domain.ConsistencyReport{CheckedAt: time.Now(), Index: "docs", VectorCount: 10, Issues: issues}
+
+	w = httptest.NewRecorder()
+	srv.handlePartialConsistency(w, httptest.NewRequest(http.MethodGet, "/partials/consistency", nil))
+	body := w.Body.String()
+	assert.Contains(t, body, "OpenSearchに未登録")
+	assert.Contains(t, body, "doc_chunk_a")
+	assert.NotContains(t, body, "doc_chunk_"+string(rune('a'+maxConsistencyIssues)))
+	assert.Contains(t, body, "他 3 件")
+
+	w = httptest.NewRecorder()
+	srv.handleAPIConsistency(w, httptest.NewRequest(http.MethodGet, "/api/consistency", nil))
+	var report domain.ConsistencyReport
+	require.NoError(t, json.NewDecoder(w.Result().Body).Decode(&report))
+	assert.Equal(t, "docs", report.Index)
+	assert.Len(t, report.Issues, maxConsistencyIssues+3)
+}
+
+func TestConsistencyReportDisabled(t *testing.T) {
+	srv, err := NewServer(DefaultServerConfig(), &Dependencies{
+		FileScanner: &mockFileScanner{},
+		Vectorizer:  &mockVectorizer{},
+	}, log.New(io.Discard, "", 0))
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	srv.handleAPIConsistency(w, httptest.NewRequest(http.MethodGet, "/api/consistency", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Nil(t, srv.consistencyView(context.Background()))
+}
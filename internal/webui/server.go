@@ -47,6 +47,8 @@ type Server struct {
 	sseManager   *SSEManager
 	vectorizer   domain.Vectorizer
 	fileScanner  domain.FileScanner
+	ledger       domain.FailureLedger
+	consistency  domain.ConsistencyReports
 	ipcClient    *ipc.Client
 	logger       *log.Logger
 	cancelFunc   context.CancelFunc
@@ -97,6 +99,8 @@ func NewServer(serverConfig *ServerConfig, deps *Dependencies, logger *log.Logge
 		sseManager:  sseManager,
 		vectorizer:  deps.Vectorizer,
 		fileScanner: deps.FileScanner,
+		ledger:      deps.FailureLedger,
+		consistency: deps.ConsistencyReports,
 		ipcClient:   ipcClient,
 		logger:      logger,
 	}
@@ -202,6 +206,7 @@ func (s *Server) setupRoutes() *http.ServeMux {
 	mux.HandleFunc("/api/files", s.handleAPIFiles)
 	mux.HandleFunc("/api/history", s.handleAPIHistory)
 	mux.HandleFunc("/api/errors", s.handleAPIErrors)
+	mux.HandleFunc("/api/consistency", s.handleAPIConsistency)
 
 	// SSE endpoints
 	mux.HandleFunc("/sse/events", s.handleSSEEvents)
@@ -210,6 +215,7 @@ func (s *Server) setupRoutes() *http.ServeMux {
 	mux.HandleFunc("/partials/progress", s.handlePartialProgress)
 	mux.HandleFunc("/partials/file-list", s.handlePartialFileList)
 	mux.HandleFunc("/partials/error-list", s.handlePartialErrorList)
+	mux.HandleFunc("/partials/consistency", s.handlePartialConsistency)
 
 	return mux
 }
@@ -11,6 +11,8 @@ import (
 const (
 	maxHistorySize = 100
 	maxErrorsSize  = 50
+
+	maxConsistencyIssues = 20
 )
 
 // VectorizeState manages the current state of vectorization
@@ -10,6 +10,8 @@ import (
 	"io/fs"
 	"path/filepath"
 	"time"
+
+	domain "github.com/ca-srg/ragent/internal/pkg/domain"
 )
 
 //go:embed templates/*.html templates/partials/*.html
@@ -61,6 +63,7 @@ func NewTemplateManagerWithBasePath(basePath string) (*TemplateManager, error) {
 		"formatTime":     formatTime,
 		"formatPercent":  formatPercent,
 		"statusClass":    statusClass,
+		"issueKindLabel": issueKindLabel,
 		"sub":            func(a, b int) int { return a - b },
 		"add":            func(a, b int) int { return a + b },
 		"basePath":       func() string { return basePath },
@@ -126,6 +129,24 @@ func (tm *TemplateManager) Render(w io.Writer, name string, data interface{}) er
 	return err
 }
 
+// issueKindLabel returns the display label of a consistency issue kind
+func issueKindLabel(kind string) string {
+	switch kind {
+	case domain.IssueMissingInOpenSearch:
+		return "OpenSearchに未登録"
+	case domain.IssueMissingInVectorStore:
+		return "ベクトルストアに未登録"
+	case domain.IssueMissingChunk:
+		return "チャンク欠落"
+	case domain.IssueDimensionMismatch:
+		return "次元不一致"
+	case domain.IssueStaleHashRecord:
+		return "古いハッシュレコード"
+	default:
+		return kind
+	}
+}
+
 // formatSize formats file size for display
 func formatSize(size int64) string {
 	const (
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	appconfig "github.com/ca-srg/ragent/internal/pkg/config"
+	domain "github.com/ca-srg/ragent/internal/pkg/domain"
 )
 
 // VectorizeStatus represents the current state of vectorization
@@ -62,6 +63,8 @@ type ErrorInfo struct {
 	ErrorType appconfig.ErrorType `json:"error_type"`
 	Message   string              `json:"message"`
 	Retryable bool                `json:"retryable"`
+	Backend   string              `json:"backend,omitempty"`
+	Attempts  int                 `json:"attempts,omitempty"`
 }
 
 // SSEEvent represents a Server-Sent Event
@@ -94,6 +97,15 @@ type DashboardData struct {
 	State        *VectorizeProgressEvent
 	RecentErrors []ErrorInfo
 	LastRun      *RunInfo
+	Consistency  *ConsistencyView // nil when no consistency reports are available
+}
+
+// ConsistencyView summarizes the latest `verify` report for the dashboard
+type ConsistencyView struct {
+	Report *domain.ConsistencyReport // nil until the first check has run
+	Counts map[string]int
+	Issues []domain.ConsistencyIssue // the first maxConsistencyIssues issues
+	Hidden int                       // issues not included in Issues
 }
 
 // FilesPageData represents data for the files page